@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -40,9 +42,21 @@ func main() {
 	logger.Init(logger.Config{Level: logLevel})
 	defer logger.Sync()
 
+	if opts.json {
+		// stdout 只输出 JSON，提示信息等人类可读内容改写到 stderr
+		jsonMode, jsonOut = true, os.Stdout
+		os.Stdout = os.Stderr
+	}
+
 	dataDir := getDataDir()
 	apiURL := getAPIURL(provider)
 
+	accounts = music.NewAccountStore(dataDir)
+	account = opts.account
+	if account == "" {
+		account = accounts.Active(provider)
+	}
+
 	switch command {
 	case "login":
 		if provider == "qq" {
@@ -58,23 +72,43 @@ func main() {
 		}
 	case "status":
 		if provider == "qq" {
-			doQQStatus(apiURL, dataDir)
+			doQQStatus(apiURL)
 		} else {
-			doNeteaseStatus(apiURL, dataDir)
+			doNeteaseStatus(apiURL)
 		}
 	case "logout":
-		doLogout(provider, dataDir)
+		doLogout(provider)
+	case "accounts":
+		doListAccounts(provider)
+	case "switch":
+		if len(opts.args) == 0 {
+			fmt.Fprintln(os.Stderr, "用法: pibuddy-music [provider] switch <账号名>")
+			os.Exit(1)
+		}
+		doSwitchAccount(provider, opts.args[0])
 	default:
 		printUsage()
 		os.Exit(1)
 	}
 }
 
+// accounts 和 account 为本次操作的账号存储和账号名（--account 指定，默认为当前账号）。
+// jsonMode 为 true 时（--json）结果以 JSON 写入 jsonOut，供脚本和管理后台读取。
+var (
+	accounts *music.AccountStore
+	account  string
+	jsonMode bool
+	jsonOut  io.Writer
+)
+
 type cmdOptions struct {
 	webMode bool
 	port    string
 	cookie  string
+	account string
+	json    bool
 	verbose bool
+	args    []string // 命令之后的位置参数
 }
 
 func parseArgs() (string, string, cmdOptions) {
@@ -92,6 +126,8 @@ func parseArgs() (string, string, cmdOptions) {
 			opts.webMode = true
 		case "-v", "--verbose":
 			opts.verbose = true
+		case "--json":
+			opts.json = true
 		case "--port":
 			if i+1 < len(os.Args) {
 				i++
@@ -102,6 +138,11 @@ func parseArgs() (string, string, cmdOptions) {
 				i++
 				opts.cookie = os.Args[i]
 			}
+		case "--account":
+			if i+1 < len(os.Args) {
+				i++
+				opts.account = os.Args[i]
+			}
 		default:
 			positional = append(positional, os.Args[i])
 		}
@@ -116,10 +157,12 @@ func parseArgs() (string, string, cmdOptions) {
 		if len(positional) < 2 {
 			return arg1, "", opts
 		}
+		opts.args = positional[2:]
 		return arg1, positional[1], opts
 	}
 
 	// 默认 qq
+	opts.args = positional[1:]
 	return "qq", arg1, opts
 }
 
@@ -137,11 +180,15 @@ func printUsage() {
 	fmt.Println("  login    登录")
 	fmt.Println("  status   查看登录状态")
 	fmt.Println("  logout   退出登录")
+	fmt.Println("  accounts 列出已登录的账号")
+	fmt.Println("  switch   切换当前使用的账号")
 	fmt.Println("")
 	fmt.Println("选项:")
 	fmt.Println("  --web     QQ 登录时启动 Web 服务器展示二维码，方便手机扫码")
 	fmt.Println("  --port    Web 服务器端口 (默认: 8099)")
 	fmt.Println("  --cookie  直接导入浏览器 cookie 字符串 (格式: name1=value1; name2=value2)")
+	fmt.Println("  --account 指定账号名 (默认: 当前账号)，用于多账号登录、查看状态和退出")
+	fmt.Println("  --json    以 JSON 输出 status、accounts 和 login 结果（提示信息输出到 stderr）")
 	fmt.Println("")
 	fmt.Println("示例:")
 	fmt.Println("  pibuddy-music login              # 登录 QQ 音乐（终端扫码）")
@@ -149,6 +196,9 @@ func printUsage() {
 	fmt.Println("  pibuddy-music login --cookie '...'  # 导入浏览器 cookie")
 	fmt.Println("  pibuddy-music status             # 查看 QQ 音乐登录状态")
 	fmt.Println("  pibuddy-music netease login      # 登录网易云音乐")
+	fmt.Println("  pibuddy-music login --account kid   # 登录另一个 QQ 音乐账号")
+	fmt.Println("  pibuddy-music switch kid         # 切换到账号 kid")
+	fmt.Println("  pibuddy-music status --json      # 以 JSON 输出登录状态")
 	fmt.Println("")
 	fmt.Println("环境变量:")
 	fmt.Println("  PIBUDDY_MUSIC_API_URL    API 地址 (网易云默认: http://localhost:3000)")
@@ -186,15 +236,6 @@ func getAPIURL(provider string) string {
 	}
 }
 
-func cookieFileName(provider string) string {
-	switch provider {
-	case "qq":
-		return "qq_cookie.json"
-	default:
-		return "netease_cookie.json"
-	}
-}
-
 // ============================================================
 // QQ 音乐登录（终端扫码）
 // ============================================================
@@ -206,8 +247,8 @@ func doQQLogin(apiURL, dataDir string) {
 	}
 
 	// 检查现有登录状态
-	cookiePath := filepath.Join(dataDir, "qq_cookie.json")
-	if data, err := loadCookieData(cookiePath); err == nil && data.LoggedIn {
+	cookiePath := accounts.CookiePath("qq", account)
+	if data, err := accounts.Load("qq", account); err == nil && data.LoggedIn {
 		fmt.Printf("当前已登录: %s\n", data.User)
 		fmt.Print("是否重新登录? (y/N): ")
 		reader := bufio.NewReader(os.Stdin)
@@ -301,14 +342,14 @@ func doQQLogin(apiURL, dataDir string) {
 				}
 
 				// 保存 cookie
-				data := cookieData{
+				data := music.CookieData{
 					Cookies:   result.Cookies,
 					LoggedIn:  true,
 					User:      uin,
 					UpdatedAt: time.Now(),
 				}
 
-				if err := saveCookieData(cookiePath, &data); err != nil {
+				if err := accounts.Save("qq", account, &data); err != nil {
 					fmt.Fprintf(os.Stderr, "保存 cookie 失败: %v\n", err)
 					os.Exit(1)
 				}
@@ -316,16 +357,19 @@ func doQQLogin(apiURL, dataDir string) {
 				fmt.Println()
 				fmt.Printf("✓ 登录成功！QQ 号: %s\n", uin)
 				fmt.Printf("✓ Cookie 已保存到: %s (%d 个)\n", cookiePath, len(result.Cookies))
+				checkActiveAccount("qq")
 
 				// 同步到 QQMusicApi
+				var syncErr error
 				if apiURL != "" {
 					fmt.Printf("  正在同步到 QQMusicApi (%s)...", apiURL)
-					if err := music.SetQQMusicAPICookie(apiURL, result.Cookies); err != nil {
-						fmt.Printf(" 跳过 (%v)\n", err)
+					if syncErr = music.SetQQMusicAPICookie(apiURL, result.Cookies); syncErr != nil {
+						fmt.Printf(" 跳过 (%v)\n", syncErr)
 					} else {
 						fmt.Println(" 完成")
 					}
 				}
+				reportLogin("qq", &data, apiURL, syncErr)
 
 				// 清理二维码
 				os.Remove(qrPath)
@@ -350,7 +394,7 @@ func doQQLoginWithCookie(apiURL, dataDir, cookieStr string) {
 		os.Exit(1)
 	}
 
-	cookiePath := filepath.Join(dataDir, "qq_cookie.json")
+	cookiePath := accounts.CookiePath("qq", account)
 
 	fmt.Println("============================================")
 	fmt.Println("QQ 音乐 Cookie 导入")
@@ -405,20 +449,21 @@ func doQQLoginWithCookie(apiURL, dataDir, cookieStr string) {
 	}
 
 	// 保存 cookie
-	data := cookieData{
+	data := music.CookieData{
 		Cookies:   cookies,
 		LoggedIn:  true,
 		User:      uin,
 		UpdatedAt: time.Now(),
 	}
 
-	if err := saveCookieData(cookiePath, &data); err != nil {
+	if err := accounts.Save("qq", account, &data); err != nil {
 		fmt.Fprintf(os.Stderr, "保存 cookie 失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("✓ 导入成功！QQ 号: %s\n", uin)
 	fmt.Printf("✓ Cookie 已保存到: %s (%d 个)\n", cookiePath, len(cookies))
+	checkActiveAccount("qq")
 
 	// 检查关键字段
 	fmt.Println()
@@ -437,15 +482,17 @@ func doQQLoginWithCookie(apiURL, dataDir, cookieStr string) {
 	}
 
 	// 同步到 QQMusicApi
+	var syncErr error
 	if apiURL != "" {
 		fmt.Println()
 		fmt.Printf("正在同步到 QQMusicApi (%s)...", apiURL)
-		if err := music.SetQQMusicAPICookie(apiURL, cookies); err != nil {
-			fmt.Printf(" 失败: %v\n", err)
+		if syncErr = music.SetQQMusicAPICookie(apiURL, cookies); syncErr != nil {
+			fmt.Printf(" 失败: %v\n", syncErr)
 		} else {
 			fmt.Println(" 完成")
 		}
 	}
+	reportLogin("qq", &data, apiURL, syncErr)
 }
 
 // ============================================================
@@ -458,8 +505,6 @@ func doQQLoginWeb(apiURL, dataDir, port string) {
 		os.Exit(1)
 	}
 
-	cookiePath := filepath.Join(dataDir, "qq_cookie.json")
-
 	// 登录状态管理
 	var (
 		mu         sync.Mutex
@@ -653,25 +698,29 @@ func doQQLoginWeb(apiURL, dataDir, port string) {
 					uin = "unknown"
 				}
 
-				data := cookieData{
+				data := music.CookieData{
 					Cookies:   result.Cookies,
 					LoggedIn:  true,
 					User:      uin,
 					UpdatedAt: time.Now(),
 				}
 
-				if err := saveCookieData(cookiePath, &data); err != nil {
+				if err := accounts.Save("qq", account, &data); err != nil {
 					fmt.Fprintf(os.Stderr, "保存 cookie 失败: %v\n", err)
+				} else {
+					checkActiveAccount("qq")
 				}
 
 				// 同步到 QQMusicApi
+				var syncErr error
 				if apiURL != "" {
-					if err := music.SetQQMusicAPICookie(apiURL, result.Cookies); err != nil {
-						fmt.Printf("  同步到 QQMusicApi 跳过: %v\n", err)
+					if syncErr = music.SetQQMusicAPICookie(apiURL, result.Cookies); syncErr != nil {
+						fmt.Printf("  同步到 QQMusicApi 跳过: %v\n", syncErr)
 					} else {
 						fmt.Println("  ✓ 已同步到 QQMusicApi")
 					}
 				}
+				reportLogin("qq", &data, apiURL, syncErr)
 
 				mu.Lock()
 				statusText = "success:" + uin
@@ -894,9 +943,23 @@ evtSource.onerror = function() {
 </body>
 </html>`
 
-func doQQStatus(apiURL, dataDir string) {
-	cookiePath := filepath.Join(dataDir, "qq_cookie.json")
-	data, err := loadCookieData(cookiePath)
+// qqKeyCookies QQ 音乐播放必需的 cookie。
+var qqKeyCookies = []string{"uin", "qm_keyst", "qqmusic_key"}
+
+func doQQStatus(apiURL string) {
+	data, err := accounts.Load("qq", account)
+
+	if jsonMode {
+		report := newStatusReport("qq", data, err)
+		if data != nil {
+			report.MissingCookies = missingCookies(data, qqKeyCookies)
+			if apiURL != "" {
+				report.API = checkQQCookieAPI(apiURL, data.Cookies)
+			}
+		}
+		writeStatusReport(report)
+		return
+	}
 
 	fmt.Println("============================================")
 	fmt.Println("QQ 音乐登录状态")
@@ -909,6 +972,8 @@ func doQQStatus(apiURL, dataDir string) {
 			fmt.Println()
 			fmt.Println("运行以下命令登录:")
 			fmt.Println("  pibuddy-music qq login")
+		} else if errors.Is(err, music.ErrCookieCorrupted) {
+			fmt.Printf("✗ cookie 文件已损坏（%v），请重新登录: pibuddy-music qq login\n", err)
 		} else {
 			fmt.Fprintf(os.Stderr, "读取 cookie 文件失败: %v\n", err)
 		}
@@ -918,28 +983,21 @@ func doQQStatus(apiURL, dataDir string) {
 	fmt.Printf("QQ 号: %s\n", data.User)
 	fmt.Printf("更新时间: %s\n", data.UpdatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Cookie 数量: %d\n", len(data.Cookies))
+	printCookieMeta("qq", data)
 	fmt.Println()
 
 	// 检查关键 cookie 是否存在
-	hasCookies := map[string]bool{"uin": false, "qm_keyst": false, "qqmusic_key": false}
-	for _, c := range data.Cookies {
-		if _, ok := hasCookies[c.Name]; ok {
-			hasCookies[c.Name] = true
-		}
-	}
-
-	allPresent := true
-	for name, present := range hasCookies {
-		if present {
+	missing := missingCookies(data, qqKeyCookies)
+	for _, name := range qqKeyCookies {
+		if data.Has(name) {
 			fmt.Printf("  ✓ %s\n", name)
 		} else {
 			fmt.Printf("  ✗ %s (缺失)\n", name)
-			allPresent = false
 		}
 	}
 
 	fmt.Println()
-	if allPresent {
+	if len(missing) == 0 {
 		fmt.Println("✓ 关键 cookie 完整")
 	} else {
 		fmt.Println("⚠ 部分关键 cookie 缺失，可能需要重新登录")
@@ -950,22 +1008,36 @@ func doQQStatus(apiURL, dataDir string) {
 	if apiURL != "" {
 		fmt.Println()
 		fmt.Printf("正在通过 QQMusicApi (%s) 验证...\n", apiURL)
-		cookieStr := cookieString(data.Cookies)
-		req, err := http.NewRequest("GET", strings.TrimSuffix(apiURL, "/")+"/user/cookie", nil)
-		if err == nil {
-			req.Header.Set("Cookie", cookieStr)
-			client := &http.Client{Timeout: 10 * time.Second}
-			resp, err := client.Do(req)
-			if err == nil {
-				defer resp.Body.Close()
-				fmt.Printf("  API 响应: %d\n", resp.StatusCode)
-			} else {
-				fmt.Printf("  API 连接失败: %v\n", err)
-			}
+		check := checkQQCookieAPI(apiURL, data.Cookies)
+		if check.Error != "" {
+			fmt.Printf("  API 连接失败: %s\n", check.Error)
+		} else {
+			fmt.Printf("  API 响应: %d\n", check.StatusCode)
 		}
 	}
 }
 
+// checkQQCookieAPI 通过 QQMusicApi 的 /user/cookie 接口验证 cookie。
+func checkQQCookieAPI(apiURL string, cookies []http.Cookie) *apiCheck {
+	check := &apiCheck{URL: apiURL}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(apiURL, "/")+"/user/cookie", nil)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	req.Header.Set("Cookie", cookieString(cookies))
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	resp.Body.Close()
+	check.StatusCode = resp.StatusCode
+	check.OK = resp.StatusCode == http.StatusOK
+	return check
+}
+
 // ============================================================
 // 网易云音乐登录（原有逻辑）
 // ============================================================
@@ -982,20 +1054,13 @@ type loginStatus struct {
 	} `json:"profile"`
 }
 
-type cookieData struct {
-	Cookies   []http.Cookie `json:"cookies"`
-	LoggedIn  bool          `json:"logged_in"`
-	User      string        `json:"user"`
-	UpdatedAt time.Time     `json:"updated_at"`
-}
-
 func doNeteaseLogin(apiURL, dataDir string) {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "创建数据目录失败: %v\n", err)
 		os.Exit(1)
 	}
 
-	cookiePath := filepath.Join(dataDir, "netease_cookie.json")
+	cookiePath := accounts.CookiePath("netease", account)
 
 	// 检查当前登录状态
 	if status := checkLoginStatus(apiURL, nil); status != nil && status.Code == 200 {
@@ -1035,28 +1100,51 @@ func doNeteaseLogin(apiURL, dataDir string) {
 	}
 
 	// 保存 cookie
-	data := cookieData{
+	data := music.CookieData{
 		Cookies:   cookies,
 		LoggedIn:  true,
 		User:      getDisplayName(status),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := saveCookieData(cookiePath, &data); err != nil {
+	if err := accounts.Save("netease", account, &data); err != nil {
 		fmt.Fprintf(os.Stderr, "保存 cookie 失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	fmt.Printf("✓ 登录成功！用户: %s\n", data.User)
 	fmt.Printf("✓ Cookie 已保存到: %s\n", cookiePath)
+	checkActiveAccount("netease")
+	reportLogin("netease", &data, "", nil)
 }
 
-func doNeteaseStatus(apiURL, dataDir string) {
-	cookiePath := filepath.Join(dataDir, "netease_cookie.json")
-	data, err := loadCookieData(cookiePath)
+func doNeteaseStatus(apiURL string) {
+	data, err := accounts.Load("netease", account)
+
+	if jsonMode {
+		report := newStatusReport("netease", data, err)
+		if data != nil {
+			status := checkLoginStatus(apiURL, data.Cookies)
+			report.API = &apiCheck{URL: apiURL}
+			if status != nil {
+				report.API.StatusCode = status.Code
+				report.API.OK = status.Code == 200
+				if report.API.OK {
+					report.API.User = getDisplayName(status)
+				}
+			} else {
+				report.API.Error = "API 无响应"
+			}
+		}
+		writeStatusReport(report)
+		return
+	}
+
 	if err != nil {
 		if os.IsNotExist(err) {
 			fmt.Println("状态: 未登录（无 cookie 文件）")
+		} else if errors.Is(err, music.ErrCookieCorrupted) {
+			fmt.Printf("✗ cookie 文件已损坏（%v），请重新登录: pibuddy-music netease login\n", err)
 		} else {
 			fmt.Fprintf(os.Stderr, "读取 cookie 文件失败: %v\n", err)
 		}
@@ -1074,6 +1162,7 @@ func doNeteaseStatus(apiURL, dataDir string) {
 		fmt.Printf("本地记录: %s\n", data.User)
 		fmt.Printf("更新时间: %s\n", data.UpdatedAt.Format("2006-01-02 15:04:05"))
 	}
+	printCookieMeta("netease", data)
 
 	fmt.Println()
 
@@ -1092,9 +1181,8 @@ func doNeteaseStatus(apiURL, dataDir string) {
 // 公共工具函数
 // ============================================================
 
-func doLogout(provider, dataDir string) {
-	cookiePath := filepath.Join(dataDir, cookieFileName(provider))
-	if err := os.Remove(cookiePath); err != nil {
+func doLogout(provider string) {
+	if err := accounts.Remove(provider, account); err != nil {
 		if os.IsNotExist(err) {
 			fmt.Println("已处于未登录状态")
 		} else {
@@ -1102,8 +1190,246 @@ func doLogout(provider, dataDir string) {
 			os.Exit(1)
 		}
 	} else {
-		fmt.Println("✓ 已退出登录")
+		fmt.Printf("✓ 账号 %s 已退出登录\n", account)
+	}
+}
+
+func doListAccounts(provider string) {
+	infos := accounts.List(provider)
+	if jsonMode {
+		writeAccountsJSON(infos)
+		return
+	}
+	if len(infos) == 0 {
+		fmt.Println("没有已登录的账号")
+		return
+	}
+
+	for _, info := range infos {
+		mark := " "
+		if info.Active {
+			mark = "*"
+		}
+		if info.Err != nil {
+			fmt.Printf("%s %-12s ✗ %v\n", mark, info.Name, info.Err)
+			continue
+		}
+		fmt.Printf("%s %-12s %-14s 更新于 %s  %s\n", mark, info.Name, info.User,
+			info.UpdatedAt.Format("2006-01-02 15:04"), expiryText(info.ExpiresAt))
+	}
+	fmt.Println()
+	fmt.Println("* 为当前账号，切换: pibuddy-music " + provider + " switch <账号名>")
+}
+
+func doSwitchAccount(provider, name string) {
+	if err := accounts.Switch(provider, name); err != nil {
+		fmt.Fprintf(os.Stderr, "切换失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ 已切换到账号 %s（运行中的 PiBuddy 会在 1 分钟内生效）\n", name)
+}
+
+// checkActiveAccount 登录后检查当前账号：当前账号尚未登录时自动切换到刚登录的账号，否则提示如何切换。
+func checkActiveAccount(provider string) {
+	active := accounts.Active(provider)
+	if active == account {
+		return
+	}
+	if _, err := os.Stat(accounts.CookiePath(provider, active)); err != nil {
+		if err := accounts.Switch(provider, account); err == nil {
+			fmt.Printf("✓ 已设为当前账号: %s\n", account)
+		}
+		return
+	}
+	fmt.Printf("提示: 当前使用的账号是 %s，切换到新账号: pibuddy-music %s switch %s\n", active, provider, account)
+}
+
+// printCookieMeta 输出账号、过期时间、完整性和文件权限等元信息。
+func printCookieMeta(provider string, data *music.CookieData) {
+	fmt.Printf("账号: %s\n", account)
+	fmt.Printf("预计过期: %s\n", expiryText(data.ExpiresAt))
+	if data.Checksum != "" {
+		fmt.Println("完整性: ✓ 校验通过")
+	} else {
+		fmt.Println("完整性: - 旧版文件，无校验信息（重新登录后生成）")
+	}
+	if info, err := os.Stat(accounts.CookiePath(provider, account)); err == nil {
+		fmt.Printf("文件权限: %v\n", info.Mode().Perm())
+	}
+}
+
+// expiryText 格式化预计过期时间。
+func expiryText(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return "未知"
+	}
+	remaining := time.Until(expiresAt)
+	if remaining <= 0 {
+		return fmt.Sprintf("%s（已过期）", expiresAt.Format("2006-01-02 15:04"))
+	}
+	return fmt.Sprintf("%s（剩余 %s）", expiresAt.Format("2006-01-02 15:04"), remaining.Round(time.Hour))
+}
+
+// statusReport 登录状态（status --json 输出）。
+type statusReport struct {
+	Provider       string     `json:"provider"`
+	Account        string     `json:"account"`
+	LoggedIn       bool       `json:"logged_in"`
+	User           string     `json:"user,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Expired        bool       `json:"expired"`
+	Integrity      string     `json:"integrity"` // ok / legacy（旧版文件无校验信息）/ corrupted / missing
+	CookieCount    int        `json:"cookie_count"`
+	MissingCookies []string   `json:"missing_cookies,omitempty"`
+	API            *apiCheck  `json:"api,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// apiCheck 通过音乐 API 服务验证 cookie 的结果。
+type apiCheck struct {
+	URL        string `json:"url"`
+	OK         bool   `json:"ok"`
+	StatusCode int    `json:"status_code,omitempty"`
+	User       string `json:"user,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// loginReport 登录结果（login --json 输出）。
+type loginReport struct {
+	Provider       string     `json:"provider"`
+	Account        string     `json:"account"`
+	Success        bool       `json:"success"`
+	User           string     `json:"user"`
+	CookieCount    int        `json:"cookie_count"`
+	CookiePath     string     `json:"cookie_path"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	MissingCookies []string   `json:"missing_cookies,omitempty"`
+	Active         bool       `json:"active"` // 是否为当前使用的账号
+	APISynced      *bool      `json:"api_synced,omitempty"`
+	APISyncError   string     `json:"api_sync_error,omitempty"`
+}
+
+// accountReport 账号列表项（accounts --json 输出）。
+type accountReport struct {
+	Name      string     `json:"name"`
+	User      string     `json:"user,omitempty"`
+	Active    bool       `json:"active"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// newStatusReport 根据 cookie 读取结果生成状态报告的公共部分。
+func newStatusReport(provider string, data *music.CookieData, err error) *statusReport {
+	report := &statusReport{Provider: provider, Account: account}
+	switch {
+	case err == nil:
+		report.LoggedIn = data.LoggedIn || len(data.Cookies) > 0
+		report.User = data.User
+		report.UpdatedAt = timePtr(data.UpdatedAt)
+		report.ExpiresAt = timePtr(data.ExpiresAt)
+		report.Expired = data.Expired()
+		report.CookieCount = len(data.Cookies)
+		report.Integrity = "ok"
+		if data.Checksum == "" {
+			report.Integrity = "legacy"
+		}
+	case os.IsNotExist(err):
+		report.Integrity = "missing"
+	case errors.Is(err, music.ErrCookieCorrupted):
+		report.Integrity = "corrupted"
+		report.Error = err.Error()
+	default:
+		report.Integrity = "missing"
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// writeStatusReport 输出状态报告，未登录时以退出码 1 结束。
+func writeStatusReport(report *statusReport) {
+	writeJSON(report)
+	if !report.LoggedIn {
+		os.Exit(1)
+	}
+}
+
+// reportLogin 在 --json 模式下输出登录结果。
+func reportLogin(provider string, data *music.CookieData, apiURL string, syncErr error) {
+	if !jsonMode {
+		return
 	}
+	report := loginReport{
+		Provider:    provider,
+		Account:     account,
+		Success:     true,
+		User:        data.User,
+		CookieCount: len(data.Cookies),
+		CookiePath:  accounts.CookiePath(provider, account),
+		ExpiresAt:   timePtr(data.ExpiresAt),
+		Active:      accounts.Active(provider) == account,
+	}
+	if provider == "qq" {
+		report.MissingCookies = missingCookies(data, qqKeyCookies)
+	}
+	if apiURL != "" {
+		synced := syncErr == nil
+		report.APISynced = &synced
+		if syncErr != nil {
+			report.APISyncError = syncErr.Error()
+		}
+	}
+	writeJSON(report)
+}
+
+// writeAccountsJSON 以 JSON 输出账号列表。
+func writeAccountsJSON(infos []music.AccountInfo) {
+	reports := make([]accountReport, 0, len(infos))
+	for _, info := range infos {
+		r := accountReport{
+			Name:      info.Name,
+			User:      info.User,
+			Active:    info.Active,
+			UpdatedAt: timePtr(info.UpdatedAt),
+			ExpiresAt: timePtr(info.ExpiresAt),
+			Expired:   !info.ExpiresAt.IsZero() && time.Now().After(info.ExpiresAt),
+		}
+		if info.Err != nil {
+			r.Error = info.Err.Error()
+		}
+		reports = append(reports, r)
+	}
+	writeJSON(reports)
+}
+
+// missingCookies 返回缺失的关键 cookie 名称。
+func missingCookies(data *music.CookieData, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !data.Has(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func writeJSON(v interface{}) {
+	enc := json.NewEncoder(jsonOut)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "输出 JSON 失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// timePtr 零值时间返回 nil，使 JSON 中省略该字段。
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
 
 func checkLoginStatus(apiURL string, cookies []http.Cookie) *loginStatus {
@@ -1157,26 +1483,6 @@ func fetchCookies(apiURL string) []http.Cookie {
 	return allCookies
 }
 
-func saveCookieData(path string, data *cookieData) error {
-	content, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, content, 0600)
-}
-
-func loadCookieData(path string) (*cookieData, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var data cookieData
-	if err := json.Unmarshal(content, &data); err != nil {
-		return nil, err
-	}
-	return &data, nil
-}
-
 func getDisplayName(status *loginStatus) string {
 	if status.Profile.NickName != "" {
 		return status.Profile.NickName
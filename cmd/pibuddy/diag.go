@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/diag"
+)
+
+// usage 子命令用法。
+const usage = `用法:
+  pibuddy [-config 配置文件] diag bundle [-upload]
+  pibuddy [-config 配置文件] export favorites|history|cache [-format m3u|csv] [-user 用户名]
+  pibuddy [-config 配置文件] import [-format yaml|csv] 文件`
+
+// runCommand 执行子命令：diag bundle、export、import。
+func runCommand(cfg *config.Config, args []string) error {
+	if len(args) >= 1 && args[0] == "export" {
+		return runExport(cfg, args[1:])
+	}
+	if len(args) >= 1 && args[0] == "import" {
+		return runImport(cfg, args[1:])
+	}
+	if len(args) < 2 || args[0] != "diag" || args[1] != "bundle" {
+		return fmt.Errorf("未知命令: %v\n%s", args, usage)
+	}
+
+	fs := flag.NewFlagSet("diag bundle", flag.ExitOnError)
+	upload := fs.Bool("upload", false, "生成后上传到 diag.upload_url")
+	fs.Parse(args[2:])
+
+	// 数据库打不开时仍然生成诊断包，只是没有数据库统计
+	db, err := database.Open("")
+	if err != nil {
+		fmt.Printf("打开数据库失败，诊断包中不含数据库统计: %v\n", err)
+		db = nil
+	} else {
+		defer db.Close()
+	}
+
+	path, err := diag.Bundle(cfg, db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("诊断包已生成: %s\n", path)
+
+	if *upload {
+		resp, err := diag.Upload(context.Background(), cfg.Diag, path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("诊断包已上传 %s\n", resp)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// runExport 把收藏、播放历史或缓存索引导出到 {data_dir}/exports/，如 pibuddy export history -format csv。
+func runExport(cfg *config.Config, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("缺少要导出的内容\n%s", usage)
+	}
+	kind := args[0]
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", music.FormatM3U, "导出格式：m3u 或 csv")
+	user := fs.String("user", "", "只导出该用户的收藏，默认导出所有用户")
+	fs.Parse(args[1:])
+
+	exporter := &music.Exporter{Favorites: music.NewFavoritesStore(cfg.Tools.DataDir)}
+	history, err := music.NewHistoryStore(cfg.Tools.DataDir)
+	if err != nil {
+		return fmt.Errorf("打开播放历史失败: %w", err)
+	}
+	exporter.History = history
+
+	// 缓存索引在数据库中，打不开时 M3U 不指向本地缓存文件
+	db, err := database.Open("")
+	if err != nil {
+		if kind == music.ExportCache {
+			return fmt.Errorf("打开数据库失败: %w", err)
+		}
+		fmt.Printf("打开数据库失败，M3U 中不包含本地缓存文件: %v\n", err)
+	} else {
+		defer db.Close()
+		cache, err := audio.NewMusicCache(db, cfg.Tools.Music.CacheDir, cfg.Tools.Music.CacheMaxSize)
+		if err != nil {
+			return fmt.Errorf("打开音乐缓存失败: %w", err)
+		}
+		exporter.Cache = cache
+	}
+
+	path, err := exporter.ExportFile(cfg.Tools.DataDir, kind, *format, *user)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已导出: %s\n", path)
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// runImport 从 YAML 或 CSV 文件批量导入闹钟、备忘和纪念日，如 pibuddy import reminders.yaml。
+// 闹钟和备忘保存在 JSON 文件中，PiBuddy 运行时请改用管理 API POST /api/import，避免被运行中的进程覆盖。
+func runImport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "文件格式：yaml 或 csv，默认按扩展名判断")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		return fmt.Errorf("缺少要导入的文件\n%s", usage)
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = tools.ImportYAML
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			*format = tools.ImportCSV
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开导入文件失败: %w", err)
+	}
+	defer f.Close()
+	batch, err := tools.ParseImport(f, *format)
+	if err != nil {
+		return err
+	}
+
+	alarms, err := tools.NewAlarmStore(cfg.Tools.DataDir)
+	if err != nil {
+		return fmt.Errorf("打开闹钟存储失败: %w", err)
+	}
+	memos, err := tools.NewMemoStore(cfg.Tools.DataDir)
+	if err != nil {
+		return fmt.Errorf("打开备忘录存储失败: %w", err)
+	}
+	memos.SetAlarmStore(alarms)
+
+	result := tools.ImportReminders(batch, alarms, memos, time.Now())
+	for _, s := range result.Skipped {
+		fmt.Printf("跳过 %s\n", s)
+	}
+	fmt.Println(result)
+	return nil
+}
@@ -23,6 +23,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 子命令（如 pibuddy diag bundle）执行完直接退出，不启动语音助手
+	if args := flag.Args(); len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := logger.Init(logger.Config{
 		Level:      cfg.Log.Level,
 		File:       cfg.Log.File,
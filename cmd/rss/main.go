@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/rss"
+)
+
+func main() {
+	configPath := flag.String("config", "configs/pibuddy.yaml", "配置文件路径")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := rss.NewFeedStore(cfg.Tools.DataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化订阅源存储失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		cmdList(store)
+	case "import":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "用法: pibuddy-rss import <OPML文件>")
+			os.Exit(1)
+		}
+		cmdImport(store, args[1])
+	case "export":
+		path := ""
+		if len(args) >= 2 {
+			path = args[1]
+		}
+		cmdExport(store, path)
+	case "set":
+		if len(args) < 3 {
+			fmt.Fprintln(os.Stderr, "用法: pibuddy-rss set <订阅源> max_items=3 summary_len=80 muted=true")
+			os.Exit(1)
+		}
+		cmdSet(store, args[1], args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n", args[0])
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "PiBuddy RSS 订阅管理工具")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "用法: pibuddy-rss [-config <path>] <command> [args]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "命令:")
+	fmt.Fprintln(os.Stderr, "  list                      列出所有订阅源及播报设置")
+	fmt.Fprintln(os.Stderr, "  import <OPML文件>          从 OPML 批量导入订阅源")
+	fmt.Fprintln(os.Stderr, "  export [OPML文件]          导出订阅源为 OPML（不指定文件则输出到标准输出）")
+	fmt.Fprintln(os.Stderr, "  set <订阅源> <key=value>... 设置播报方式: max_items、summary_len、muted")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "注意: PiBuddy 运行中修改订阅后需重启生效。")
+}
+
+func cmdList(store *rss.FeedStore) {
+	feeds := store.List()
+	if len(feeds) == 0 {
+		fmt.Println("当前没有任何 RSS 订阅")
+		return
+	}
+
+	fmt.Printf("共 %d 个订阅源:\n", len(feeds))
+	for _, f := range feeds {
+		fmt.Printf("  %-24s %s\n", f.ID, f.Name)
+		fmt.Printf("  %-24s %s\n", "", f.URL)
+
+		var settings []string
+		if f.MaxItems > 0 {
+			settings = append(settings, fmt.Sprintf("max_items=%d", f.MaxItems))
+		}
+		if f.SummaryLen > 0 {
+			settings = append(settings, fmt.Sprintf("summary_len=%d", f.SummaryLen))
+		}
+		if f.Muted {
+			settings = append(settings, "muted")
+		}
+		if len(settings) > 0 {
+			fmt.Printf("  %-24s [%s]\n", "", strings.Join(settings, " "))
+		}
+	}
+}
+
+func cmdImport(store *rss.FeedStore, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	result, err := store.ImportOPML(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导入失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, name := range result.Added {
+		fmt.Printf("  + %s\n", name)
+	}
+	for _, name := range result.Skipped {
+		fmt.Printf("  = %s（已存在）\n", name)
+	}
+	fmt.Printf("导入完成: 新增 %d 个，跳过 %d 个\n", len(result.Added), len(result.Skipped))
+}
+
+func cmdExport(store *rss.FeedStore, path string) {
+	if path == "" {
+		if err := store.ExportOPML(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "导出失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := store.ExportOPML(f); err != nil {
+		fmt.Fprintf(os.Stderr, "导出失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("已导出 %d 个订阅源到 %s\n", len(store.List()), path)
+}
+
+func cmdSet(store *rss.FeedStore, feed string, kvs []string) {
+	var settings rss.FeedSettings
+	for _, kv := range kvs {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			fmt.Fprintf(os.Stderr, "参数格式应为 key=value: %s\n", kv)
+			os.Exit(1)
+		}
+		switch key {
+		case "max_items", "summary_len":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s 必须是整数: %s\n", key, value)
+				os.Exit(1)
+			}
+			if key == "max_items" {
+				settings.MaxItems = &n
+			} else {
+				settings.SummaryLen = &n
+			}
+		case "muted":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "muted 必须是 true 或 false: %s\n", value)
+				os.Exit(1)
+			}
+			settings.Muted = &b
+		default:
+			fmt.Fprintf(os.Stderr, "未知设置项: %s\n", key)
+			os.Exit(1)
+		}
+	}
+
+	f, err := store.UpdateSettings(feed, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "更新失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("已更新 %s: max_items=%d summary_len=%d muted=%v\n", f.Name, f.MaxItems, f.SummaryLen, f.Muted)
+}
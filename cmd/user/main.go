@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/audio"
@@ -76,6 +77,29 @@ func main() {
 			os.Exit(1)
 		}
 		cmdGetPrefs(mgr, args[1])
+	case "identify":
+		seconds := 3
+		if len(args) >= 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				fmt.Fprintln(os.Stderr, "用法: pibuddy-user identify [录音秒数]")
+				os.Exit(1)
+			}
+			seconds = n
+		}
+		cmdIdentify(mgr, cfg, time.Duration(seconds)*time.Second)
+	case "export":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "用法: pibuddy-user export <文件>")
+			os.Exit(1)
+		}
+		cmdExport(mgr, args[1])
+	case "import":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "用法: pibuddy-user import <文件>")
+			os.Exit(1)
+		}
+		cmdImport(mgr, args[1])
 	default:
 		fmt.Fprintf(os.Stderr, "未知命令: %s\n", args[0])
 		printUsage()
@@ -95,6 +119,9 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "  set-owner <用户名>     设置用户为主人")
 	fmt.Fprintln(os.Stderr, "  set-prefs <用户名> <JSON>  设置用户偏好")
 	fmt.Fprintln(os.Stderr, "  get-prefs <用户名>     获取用户偏好")
+	fmt.Fprintln(os.Stderr, "  identify [秒数]        录一段语音（默认 3 秒），显示识别结果和各用户的相似度")
+	fmt.Fprintln(os.Stderr, "  export <文件>          导出所有用户的声纹和偏好（迁移到新设备）")
+	fmt.Fprintln(os.Stderr, "  import <文件>          导入 export 导出的用户，同名用户会被替换")
 }
 
 func cmdRegister(mgr *voiceprint.Manager, cfg *config.Config, name string) {
@@ -243,4 +270,113 @@ func cmdGetPrefs(mgr *voiceprint.Manager, name string) {
 	if p.Extra != "" {
 		fmt.Printf("  额外信息: %s\n", p.Extra)
 	}
+	if p.HomeCity != "" {
+		fmt.Printf("  所在城市: %s\n", p.HomeCity)
+	}
+	if p.Birthday != "" {
+		fmt.Printf("  生日: %s\n", p.Birthday)
+	}
+	if p.NoCelebration {
+		fmt.Println("  不需要生日祝福")
+	}
+}
+
+func cmdIdentify(mgr *voiceprint.Manager, cfg *config.Config, duration time.Duration) {
+	if mgr.NumSpeakers() == 0 {
+		fmt.Fprintln(os.Stderr, "当前没有已注册的声纹用户，请先运行 register。")
+		os.Exit(1)
+	}
+
+	capture, err := audio.NewCapture(cfg.Audio.SampleRate, cfg.Audio.Channels, cfg.Audio.FrameSize, cfg.Audio.MicGain)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化麦克风失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer capture.Close()
+
+	if err := capture.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "启动麦克风失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print("按回车开始录制...")
+	fmt.Scanln()
+	fmt.Printf("  录制中（%v），请说话...\n", duration)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	recorded := capture.RecordFor(ctx)
+	cancel()
+
+	if len(recorded) < cfg.Audio.SampleRate {
+		fmt.Fprintln(os.Stderr, "录制数据不足，请重试。")
+		os.Exit(1)
+	}
+
+	candidates, err := mgr.Score(recorded)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "识别失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	threshold := mgr.Threshold()
+	fmt.Printf("相似度（估算，阈值 %.2f）:\n", threshold)
+	for _, c := range candidates {
+		mark := "  "
+		if c.Score >= threshold {
+			mark = "✓ "
+		}
+		fmt.Printf("  %s%-10s %.2f\n", mark, c.Name, c.Score)
+	}
+
+	if len(candidates) > 0 && candidates[0].Score >= threshold {
+		fmt.Printf("识别结果: %s\n", candidates[0].Name)
+		if len(candidates) > 1 && candidates[0].Score-candidates[1].Score < 0.05 {
+			fmt.Printf("注意: 与 %s 的相似度很接近，可能误识别，建议重新注册或提高阈值。\n", candidates[1].Name)
+		}
+	} else {
+		fmt.Println("识别结果: 未识别（低于阈值）")
+	}
+}
+
+func cmdExport(mgr *voiceprint.Manager, path string) {
+	data, err := mgr.Export()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导出失败: %v\n", err)
+		os.Exit(1)
+	}
+	if len(data.Users) == 0 {
+		fmt.Println("当前没有已注册的声纹用户。")
+		return
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "序列化失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "写入文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("已导出 %d 个用户到 %s\n", len(data.Users), path)
+}
+
+func cmdImport(mgr *voiceprint.Manager, path string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	var data voiceprint.ExportData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		fmt.Fprintf(os.Stderr, "文件格式错误: %v\n", err)
+		os.Exit(1)
+	}
+
+	n, err := mgr.Import(&data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导入失败（已导入 %d 个）: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Printf("已导入 %d 个用户。如果 pibuddy 正在运行，重启后生效。\n", n)
 }
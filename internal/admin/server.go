@@ -0,0 +1,156 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// Server 是管理 API 的 HTTP 服务，供局域网内的其他设备查询和控制 PiBuddy。
+// 各模块通过 Handle 注册自己的接口，统一进行鉴权。
+type Server struct {
+	listen string
+	token  string
+	mux    *http.ServeMux
+	server *http.Server
+
+	mu     sync.Mutex
+	routes []string
+}
+
+// NewServer 创建管理 API 服务。
+func NewServer(cfg config.AdminConfig) *Server {
+	s := &Server{
+		listen: cfg.Listen,
+		token:  cfg.Token,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /api", s.handleIndex)
+	return s
+}
+
+// Handle 注册接口。pattern 使用 Go 1.22 路由语法，如 "GET /api/scheduler/jobs"。
+// 配置了 token 时，所有接口都需要鉴权。
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.routes = append(s.routes, pattern)
+	s.mu.Unlock()
+	s.mux.HandleFunc(pattern, s.auth(handler))
+}
+
+// HandleAuthRequired 注册必须鉴权的接口，用于修改数据、让音箱说话或包含密码等敏感信息的接口。
+// 没有配置 token 时这类接口不可用，直接返回 403，避免局域网内任何人都能调用。
+func (s *Server) HandleAuthRequired(pattern string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.routes = append(s.routes, pattern)
+	s.mu.Unlock()
+	s.mux.HandleFunc(pattern, s.requireToken(s.auth(handler)))
+}
+
+// HandlePublic 注册不需要鉴权的接口，只用于 /healthz 这类给监控程序使用、不含敏感信息的接口。
+func (s *Server) HandlePublic(pattern string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.routes = append(s.routes, pattern)
+	s.mu.Unlock()
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Start 开始监听，服务在后台 goroutine 中运行。
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("管理 API 监听 %s 失败: %w", s.listen, err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("[admin] 管理 API 异常退出: %v", err)
+		}
+	}()
+
+	if s.token == "" {
+		logger.Warnf("[admin] 管理 API 已启动 (%s)，未配置 token，只读接口任何人都可以访问，修改类和敏感接口已禁用", s.listen)
+	} else {
+		logger.Infof("[admin] 管理 API 已启动 (%s)", s.listen)
+	}
+	return nil
+}
+
+// Close 关闭服务。
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// requireToken 没有配置 token 时拒绝请求。
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			WriteError(w, http.StatusForbidden, "该接口需要先配置 admin.token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// auth 校验请求 token，支持 Authorization: Bearer <token> 或 ?token=<token>。
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = r.URL.Query().Get("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				WriteError(w, http.StatusUnauthorized, "未授权")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handleIndex 列出所有已注册的接口。
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	routes := make([]string, len(s.routes))
+	copy(routes, s.routes)
+	s.mu.Unlock()
+	sort.Strings(routes)
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"routes":  routes,
+	})
+}
+
+// WriteJSON 以 JSON 格式写入响应。
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debugf("[admin] 写入响应失败: %v", err)
+	}
+}
+
+// WriteError 写入错误响应。
+func WriteError(w http.ResponseWriter, status int, msg string) {
+	WriteJSON(w, status, map[string]interface{}{
+		"success": false,
+		"error":   msg,
+	})
+}
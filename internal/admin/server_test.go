@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+func TestServerAuth(t *testing.T) {
+	s := NewServer(config.AdminConfig{Listen: ":0", Token: "secret"})
+	s.Handle("GET /api/ping", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{"无 token", "", "", http.StatusUnauthorized},
+		{"错误 token", "Bearer wrong", "", http.StatusUnauthorized},
+		{"Bearer token", "Bearer secret", "", http.StatusOK},
+		{"查询参数 token", "", "?token=secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/ping"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerNoToken(t *testing.T) {
+	s := NewServer(config.AdminConfig{Listen: ":0"})
+	s.Handle("GET /api/ping", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	})
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if body := rec.Body.String(); body == "" || !strings.Contains(body, "GET /api/ping") {
+		t.Errorf("index 未列出已注册接口: %s", body)
+	}
+}
+
+func TestServerHandlePublic(t *testing.T) {
+	s := NewServer(config.AdminConfig{Listen: ":0", Token: "secret"})
+	s.HandlePublic("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	})
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("public route status = %d, want 200 without token", rec.Code)
+	}
+}
+
+func TestServerHandleAuthRequired(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	}
+
+	open := NewServer(config.AdminConfig{Listen: ":0"})
+	open.HandleAuthRequired("POST /api/announce", ok)
+	rec := httptest.NewRecorder()
+	open.mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/announce", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("no token: status = %d, want 403", rec.Code)
+	}
+
+	s := NewServer(config.AdminConfig{Listen: ":0", Token: "secret"})
+	s.HandleAuthRequired("POST /api/announce", ok)
+	rec = httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/announce", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: status = %d, want 401", rec.Code)
+	}
+	req := httptest.NewRequest("POST", "/api/announce", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", rec.Code)
+	}
+}
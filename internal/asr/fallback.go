@@ -28,6 +28,26 @@ type FallbackEngine struct {
 
 	// 端点触发标记：IsEndpoint() 触发后设置，GetResult() 读取后清除
 	endpointTriggered bool
+
+	// 固定使用本地引擎的截止时间（如当天云端识别额度用完），期间不调用在线引擎
+	pinnedUntil time.Time
+
+	// 自动切换记录（供诊断）与回调
+	switches   int
+	lastSwitch time.Time
+	lastReason string
+	onSwitch   func(from, to EngineType, reason string)
+}
+
+// FallbackStatus 兜底引擎的当前状态，供管理 API 诊断。
+type FallbackStatus struct {
+	Current    EngineType   `json:"current"`
+	Engines    []EngineType `json:"engines"` // 按优先级排列
+	Degraded   bool         `json:"degraded"`
+	Pinned     bool         `json:"pinned"`
+	Switches   int          `json:"switches"` // 启动以来自动切换的次数
+	LastSwitch time.Time    `json:"last_switch,omitempty"`
+	LastReason string       `json:"last_reason,omitempty"`
 }
 
 // FallbackConfig 兜底引擎配置
@@ -82,8 +102,33 @@ func NewFallbackEngine(cfg FallbackConfig) *FallbackEngine {
 	return e
 }
 
+// SetOnSwitch 设置引擎自动切换（降级或恢复）后的回调，在锁外调用。
+// PinLocal 主动固定本地引擎时不触发。
+func (e *FallbackEngine) SetOnSwitch(fn func(from, to EngineType, reason string)) {
+	e.mu.Lock()
+	e.onSwitch = fn
+	e.mu.Unlock()
+}
+
+// recordSwitch 记录一次自动切换，返回需要在释放锁后执行的回调。调用方需持有锁。
+func (e *FallbackEngine) recordSwitch(from, to EngineType, reason string) func() {
+	e.switches++
+	e.lastSwitch = time.Now()
+	e.lastReason = reason
+	if fn := e.onSwitch; fn != nil {
+		return func() { fn(from, to, reason) }
+	}
+	return nil
+}
+
 // switchToNext 切换到下一个可用引擎
 func (e *FallbackEngine) switchToNext(currentIdx int, reason string) bool {
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -111,6 +156,7 @@ func (e *FallbackEngine) switchToNext(currentIdx int, reason string) bool {
 		oldType := e.engineType[e.currentIdx]
 		e.currentIdx = i
 		logEngineSwitch(oldType, engineType, reason)
+		notify = e.recordSwitch(oldType, engineType, reason)
 		return true
 	}
 
@@ -120,11 +166,22 @@ func (e *FallbackEngine) switchToNext(currentIdx int, reason string) bool {
 
 // tryRecover 尝试恢复到优先级更高的引擎
 func (e *FallbackEngine) tryRecover() {
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	now := time.Now()
 
+	// 固定使用本地引擎期间不恢复
+	if now.Before(e.pinnedUntil) {
+		return
+	}
+
 	// 检查是否需要尝试恢复
 	if now.Sub(e.lastRecoveryTry) < e.recoveryInterval {
 		return
@@ -150,6 +207,7 @@ func (e *FallbackEngine) tryRecover() {
 				oldType := e.engineType[e.currentIdx]
 				e.currentIdx = i
 				logger.Infof("[asr] 引擎已恢复: %s -> %s", oldType, engineType)
+				notify = e.recordSwitch(oldType, engineType, "引擎已恢复")
 				return
 			}
 		}
@@ -250,8 +308,14 @@ func (e *FallbackEngine) IsEndpoint() bool {
 		// 设置端点触发标记
 		e.mu.Lock()
 		e.endpointTriggered = true
+		pinned := time.Now().Before(e.pinnedUntil)
 		e.mu.Unlock()
 
+		// 固定使用本地引擎时不调用在线引擎
+		if pinned {
+			return true
+		}
+
 		// 通知所有批处理引擎：端点已触发，启动异步识别
 		for _, engine := range e.engines {
 			if be, ok := engine.(BatchEngine); ok {
@@ -283,6 +347,15 @@ func (e *FallbackEngine) Cancel() {
 	}
 }
 
+// SetHotwords 实现 HotwordEngine 接口，把热词设置到所有支持热词的引擎。
+func (e *FallbackEngine) SetHotwords(words []string) {
+	for _, engine := range e.engines {
+		if h, ok := engine.(HotwordEngine); ok {
+			h.SetHotwords(words)
+		}
+	}
+}
+
 // Close 实现 Engine 接口。
 func (e *FallbackEngine) Close() {
 	for _, engine := range e.engines {
@@ -303,6 +376,40 @@ func (e *FallbackEngine) CurrentType() EngineType {
 	return e.engineType[e.currentIdx]
 }
 
+// PinLocal 在 until 之前固定使用本地端点检测引擎（sherpa），不再调用在线引擎，
+// 到期后按正常的恢复机制切回在线引擎。
+func (e *FallbackEngine) PinLocal(until time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pinnedUntil = until
+	if e.currentIdx != e.endpointDetectorIdx {
+		logEngineSwitch(e.engineType[e.currentIdx], e.engineType[e.endpointDetectorIdx], "固定使用本地引擎")
+		e.currentIdx = e.endpointDetectorIdx
+	}
+}
+
+// Pinned 返回是否正固定使用本地引擎。
+func (e *FallbackEngine) Pinned() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return time.Now().Before(e.pinnedUntil)
+}
+
+// Status 返回当前引擎和自动切换记录。
+func (e *FallbackEngine) Status() FallbackStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return FallbackStatus{
+		Current:    e.engineType[e.currentIdx],
+		Engines:    append([]EngineType(nil), e.engineType...),
+		Degraded:   e.currentIdx > 0,
+		Pinned:     time.Now().Before(e.pinnedUntil),
+		Switches:   e.switches,
+		LastSwitch: e.lastSwitch,
+		LastReason: e.lastReason,
+	}
+}
+
 // IsDegraded 返回是否处于降级状态（使用非首选引擎）。
 func (e *FallbackEngine) IsDegraded() bool {
 	e.mu.RLock()
@@ -0,0 +1,58 @@
+package asr
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// HotwordEngine 是支持热词的引擎接口（可选实现）。
+// 热词（歌名、家人名字、设备名等）在识别时获得更高的权重，更容易识别准确。
+// SetHotwords 可以随时调用，新的热词从下一句开始生效。
+type HotwordEngine interface {
+	Engine
+	SetHotwords(words []string)
+}
+
+const (
+	// MaxHotwords 腾讯云临时热词表最多 128 个词，sherpa 也沿用这个上限
+	MaxHotwords = 128
+	// maxHotwordRunes 单个热词最多 10 个汉字（腾讯云限制）
+	maxHotwordRunes = 10
+	// tencentHotwordWeight 腾讯云热词权重，取值 1-11
+	tencentHotwordWeight = 10
+)
+
+// NormalizeHotwords 去掉空白、重复和过长的热词，最多保留 MaxHotwords 个，保持原有顺序（靠前的优先）。
+func NormalizeHotwords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	var out []string
+	for _, w := range words {
+		w = strings.Join(strings.Fields(w), " ")
+		// 腾讯云热词表用 | 和 , 分隔
+		if w == "" || strings.ContainsAny(w, "|,") {
+			continue
+		}
+		if n := utf8.RuneCountInString(w); n < 2 || n > maxHotwordRunes {
+			continue
+		}
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+		if len(out) == MaxHotwords {
+			break
+		}
+	}
+	return out
+}
+
+// tencentHotwordList 把热词转换为腾讯云临时热词表格式："词|权重,词|权重"。
+func tencentHotwordList(words []string) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = fmt.Sprintf("%s|%d", w, tencentHotwordWeight)
+	}
+	return strings.Join(parts, ",")
+}
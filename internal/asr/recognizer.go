@@ -2,7 +2,9 @@ package asr
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
@@ -16,6 +18,11 @@ type SherpaEngine struct {
 	recognizer *sherpa.OnlineRecognizer
 	stream     *sherpa.OnlineStream
 	mu         sync.Mutex // 保护 stream 的并发访问
+
+	config    sherpa.OnlineRecognizerConfig // 不含热词的原始配置，更新热词时据此重建识别器
+	modelPath string
+	pending   *sherpa.OnlineRecognizer // 使用新热词重建的识别器，下次 Reset 时替换
+	hotwordMu sync.Mutex               // 串行化热词更新
 }
 
 // 确保实现 Engine 接口
@@ -25,6 +32,13 @@ var _ Engine = (*SherpaEngine)(nil)
 // Deprecated: 使用 SherpaEngine 代替。
 type Recognizer = SherpaEngine
 
+// sherpa 端点检测规则的默认值（秒）。
+const (
+	DefaultRule1MinTrailingSilence = 2.4  // 还没识别出文字时，静音多久算结束
+	DefaultRule2MinTrailingSilence = 1.2  // 识别出文字后，静音多久算结束
+	DefaultRule3MinUtteranceLength = 20.0 // 一句话最长多久
+)
+
 // NewSherpaEngine 创建 sherpa-onnx 流式语音识别器。
 // modelPath: 包含 Zipformer 模型文件（encoder、decoder、joiner ONNX 文件和 tokens.txt）的目录
 // numThreads: 推理引擎使用的 CPU 线程数
@@ -58,17 +72,17 @@ func NewSherpaEngine(modelPath string, numThreads int, rule1MinTrailingSilence,
 	if rule1MinTrailingSilence > 0 {
 		config.Rule1MinTrailingSilence = float32(rule1MinTrailingSilence)
 	} else {
-		config.Rule1MinTrailingSilence = 2.4
+		config.Rule1MinTrailingSilence = DefaultRule1MinTrailingSilence
 	}
 	if rule2MinTrailingSilence > 0 {
 		config.Rule2MinTrailingSilence = float32(rule2MinTrailingSilence)
 	} else {
-		config.Rule2MinTrailingSilence = 1.2
+		config.Rule2MinTrailingSilence = DefaultRule2MinTrailingSilence
 	}
 	if rule3MinUtteranceLength > 0 {
 		config.Rule3MinUtteranceLength = float32(rule3MinUtteranceLength)
 	} else {
-		config.Rule3MinUtteranceLength = 20.0
+		config.Rule3MinUtteranceLength = DefaultRule3MinUtteranceLength
 	}
 
 	recognizer := sherpa.NewOnlineRecognizer(&config)
@@ -87,9 +101,64 @@ func NewSherpaEngine(modelPath string, numThreads int, rule1MinTrailingSilence,
 	return &SherpaEngine{
 		recognizer: recognizer,
 		stream:     stream,
+		config:     config,
+		modelPath:  modelPath,
 	}, nil
 }
 
+// sherpaHotwordsScore 热词加分，越大越容易识别成热词，也越容易误识别。
+const sherpaHotwordsScore = 1.5
+
+// SetHotwords 实现 HotwordEngine 接口。热词需要 modified_beam_search 解码，
+// 设置后在后台重建识别器，当前这句话识别完、下次 Reset 时生效；words 为空时恢复为不带热词的贪心解码。
+func (e *SherpaEngine) SetHotwords(words []string) {
+	e.hotwordMu.Lock()
+	defer e.hotwordMu.Unlock()
+
+	config := e.config
+	words = NormalizeHotwords(words)
+	if len(words) > 0 {
+		path := filepath.Join(os.TempDir(), "pibuddy_hotwords.txt")
+		// 中英双语模型的英文 token 为大写
+		if err := os.WriteFile(path, []byte(strings.ToUpper(strings.Join(words, "\n"))+"\n"), 0644); err != nil {
+			logger.Warnf("[asr] 写入热词文件失败: %v", err)
+			return
+		}
+		config.DecodingMethod = "modified_beam_search"
+		config.MaxActivePaths = 4
+		config.HotwordsFile = path
+		config.HotwordsScore = sherpaHotwordsScore
+		config.ModelConfig.ModelingUnit = "cjkchar"
+		if bpe := filepath.Join(e.modelPath, "bpe.vocab"); fileExists(bpe) {
+			config.ModelConfig.ModelingUnit = "cjkchar+bpe"
+			config.ModelConfig.BpeVocab = bpe
+		}
+	}
+
+	recognizer := sherpa.NewOnlineRecognizer(&config)
+	if recognizer == nil {
+		logger.Warnf("[asr] 使用热词重建 Sherpa 识别器失败，继续使用原识别器")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.recognizer == nil { // 引擎已关闭
+		sherpa.DeleteOnlineRecognizer(recognizer)
+		return
+	}
+	if e.pending != nil {
+		sherpa.DeleteOnlineRecognizer(e.pending)
+	}
+	e.pending = recognizer
+	logger.Infof("[asr] Sherpa 热词已更新: %d 个，下一句生效", len(words))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // Feed 将音频样本送入识别流，并立即解码一帧。
 // 样本应为 16kHz float32 格式。
 // 注意：Feed 后立即调用 Decode，减少 circular buffer 积压，避免 Overflow 警告。
@@ -145,6 +214,11 @@ func (e *SherpaEngine) Reset() {
 
 	if e.recognizer != nil && e.stream != nil {
 		sherpa.DeleteOnlineStream(e.stream)
+		// 热词更新后在两句话之间替换识别器
+		if e.pending != nil {
+			sherpa.DeleteOnlineRecognizer(e.recognizer)
+			e.recognizer, e.pending = e.pending, nil
+		}
 		e.stream = sherpa.NewOnlineStream(e.recognizer)
 	}
 }
@@ -168,6 +242,10 @@ func (e *SherpaEngine) Close() {
 		sherpa.DeleteOnlineRecognizer(e.recognizer)
 		e.recognizer = nil
 	}
+	if e.pending != nil {
+		sherpa.DeleteOnlineRecognizer(e.pending)
+		e.pending = nil
+	}
 	logger.Info("[asr] Sherpa 引擎已关闭")
 }
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 	asr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/asr/v20190614"
@@ -37,6 +38,8 @@ type TencentFlashEngine struct {
 	asyncRunning bool   // 是否正在异步识别中
 	asyncErr     error  // 异步识别错误
 
+	hotwordList string // 临时热词表，为空时不使用热词
+
 	// 状态
 	status      EngineStatus
 	lastError   error
@@ -70,6 +73,8 @@ func NewTencentFlashEngine(cfg TencentFlashConfig) (*TencentFlashEngine, error)
 	if err != nil {
 		return nil, fmt.Errorf("创建腾讯云 ASR 客户端失败: %w", err)
 	}
+	// 与其他外部 API 共享按 host 的限流和退避
+	client.WithHttpTransport(ratelimit.NewTransport(nil))
 
 	e := &TencentFlashEngine{
 		client:     client,
@@ -138,8 +143,9 @@ func (e *TencentFlashEngine) GetResult() string {
 
 		// 启动异步识别
 		e.asyncRunning = true
+		hotwordList := e.hotwordList
 		go func() {
-			result, err := e.recognize(audioData)
+			result, err := e.recognize(audioData, hotwordList)
 
 			e.mu.Lock()
 			defer e.mu.Unlock()
@@ -167,6 +173,14 @@ func (e *TencentFlashEngine) TriggerRecognize() {
 	e.pendingRecognize = true
 }
 
+// SetHotwords 实现 HotwordEngine 接口，作为临时热词表随每次识别请求发送。
+func (e *TencentFlashEngine) SetHotwords(words []string) {
+	list := tencentHotwordList(NormalizeHotwords(words))
+	e.mu.Lock()
+	e.hotwordList = list
+	e.mu.Unlock()
+}
+
 // IsEndpoint 实现 Engine 接口。
 // 一句话识别引擎本身不做端点检测，由 VAD 或调用者决定。
 // 这里始终返回 false，由 pipeline 的 VAD 决定何时识别。
@@ -210,7 +224,7 @@ func (e *TencentFlashEngine) Status() EngineStatus {
 }
 
 // recognize 调用腾讯云一句话识别 API。
-func (e *TencentFlashEngine) recognize(audioData []byte) (string, error) {
+func (e *TencentFlashEngine) recognize(audioData []byte, hotwordList string) (string, error) {
 	// 计算音频时长（秒）
 	audioDuration := float64(len(audioData)/2) / float64(e.sampleRate)
 
@@ -222,6 +236,9 @@ func (e *TencentFlashEngine) recognize(audioData []byte) (string, error) {
 	req.VoiceFormat = common.StringPtr("pcm") // PCM 格式
 	req.Data = common.StringPtr(base64.StdEncoding.EncodeToString(audioData))
 	req.DataLen = common.Int64Ptr(int64(len(audioData)))
+	if hotwordList != "" {
+		req.HotwordList = common.StringPtr(hotwordList)
+	}
 
 	resp, err := e.client.SentenceRecognition(req)
 	if err != nil {
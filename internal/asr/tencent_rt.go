@@ -59,6 +59,7 @@ type TencentRTEngine struct {
 	connMu      sync.Mutex
 	currentText strings.Builder
 	engineModel string // 引擎模型类型，如 16k_zh
+	hotwordList string // 临时热词表，为空时不使用热词
 }
 
 // TencentRTConfig 腾讯云实时语音识别配置
@@ -158,8 +159,9 @@ func (e *TencentRTEngine) GetResult() string {
 
 		// 启动异步识别
 		e.asyncRunning = true
+		hotwordList := e.hotwordList
 		go func() {
-			result, err := e.recognize(ctx, audioData)
+			result, err := e.recognize(ctx, audioData, hotwordList)
 
 			e.mu.Lock()
 			defer e.mu.Unlock()
@@ -188,6 +190,14 @@ func (e *TencentRTEngine) TriggerRecognize() {
 	e.pendingRecognize = true
 }
 
+// SetHotwords 实现 HotwordEngine 接口，作为临时热词表在每次建立连接时发送。
+func (e *TencentRTEngine) SetHotwords(words []string) {
+	list := tencentHotwordList(NormalizeHotwords(words))
+	e.mu.Lock()
+	e.hotwordList = list
+	e.mu.Unlock()
+}
+
 // IsEndpoint 实现 Engine 接口。
 // 实时语音识别引擎本身不做端点检测。
 func (e *TencentRTEngine) IsEndpoint() bool {
@@ -250,9 +260,9 @@ func (e *TencentRTEngine) Status() EngineStatus {
 
 // recognize 使用 WebSocket 进行实时语音识别。
 // 注意：此方法在 goroutine 中调用，不阻塞主循环。
-func (e *TencentRTEngine) recognize(ctx context.Context, audioData []byte) (string, error) {
+func (e *TencentRTEngine) recognize(ctx context.Context, audioData []byte, hotwordList string) (string, error) {
 	// 构建 WebSocket URL
-	wsURL, err := e.buildWebSocketURL()
+	wsURL, err := e.buildWebSocketURL(hotwordList)
 	if err != nil {
 		return "", err
 	}
@@ -368,7 +378,8 @@ func (e *TencentRTEngine) recognize(ctx context.Context, audioData []byte) (stri
 
 // buildWebSocketURL 构建 WebSocket 连接 URL。
 // 参考文档：https://cloud.tencent.com/document/product/1093/48982
-func (e *TencentRTEngine) buildWebSocketURL() (string, error) {
+// hotwordList 不为空时作为临时热词表（hotword_list）发送。
+func (e *TencentRTEngine) buildWebSocketURL(hotwordList string) (string, error) {
 	host := "asr.cloud.tencent.com"
 	path := fmt.Sprintf("/asr/v2/%s", e.appID)
 
@@ -385,6 +396,9 @@ func (e *TencentRTEngine) buildWebSocketURL() (string, error) {
 		"voice_format":      "1", // PCM
 		"needvad":           "1", // 启用 VAD
 	}
+	if hotwordList != "" {
+		params["hotword_list"] = hotwordList
+	}
 
 	// 1. 按字典序排列参数，构建签名原文
 	keys := make([]string, 0, len(params))
@@ -393,9 +407,11 @@ func (e *TencentRTEngine) buildWebSocketURL() (string, error) {
 	}
 	sort.Strings(keys)
 
-	var sortedParams []string
+	// 签名使用原始参数值，URL 中的参数值需要编码（热词含中文）
+	var sortedParams, encodedParams []string
 	for _, k := range keys {
 		sortedParams = append(sortedParams, fmt.Sprintf("%s=%s", k, params[k]))
+		encodedParams = append(encodedParams, fmt.Sprintf("%s=%s", k, url.QueryEscape(params[k])))
 	}
 	queryStr := strings.Join(sortedParams, "&")
 
@@ -409,7 +425,7 @@ func (e *TencentRTEngine) buildWebSocketURL() (string, error) {
 	encodedSignature := url.QueryEscape(signature)
 
 	// 4. 构建完整 URL
-	wsURL := fmt.Sprintf("wss://%s%s?%s&signature=%s", host, path, queryStr, encodedSignature)
+	wsURL := fmt.Sprintf("wss://%s%s?%s&signature=%s", host, path, strings.Join(encodedParams, "&"), encodedSignature)
 	return wsURL, nil
 }
 
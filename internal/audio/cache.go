@@ -1,8 +1,11 @@
 package audio
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -35,6 +38,19 @@ type MusicCache struct {
 	db       *database.DB
 	cacheDir string
 	maxSize  int64 // 最大缓存大小（字节），0 表示禁用缓存
+
+	tempCleaned   int   // 启动以来清理的残留临时文件数
+	tempReclaimed int64 // 清理残留临时文件回收的空间（字节）
+}
+
+// CacheStats 缓存统计信息。
+type CacheStats struct {
+	Count         int   // 已登记的歌曲数
+	Size          int64 // 已登记歌曲的总大小（字节）
+	TempFiles     int   // 缓存目录中的临时文件数（包括正在下载的）
+	TempSize      int64 // 临时文件总大小（字节）
+	TempCleaned   int   // 启动以来清理的残留临时文件数
+	TempReclaimed int64 // 清理残留临时文件回收的空间（字节）
 }
 
 // NewMusicCache 创建音乐缓存管理器。
@@ -59,6 +75,8 @@ func NewMusicCache(db *database.DB, cacheDir string, maxSizeMB int64) (*MusicCac
 
 	// 校验索引：移除本地文件不存在的条目
 	mc.validateIndex()
+	// 清理上次运行下载中断留下的临时文件
+	mc.CleanTemp(StaleFileAge)
 
 	logger.Infof("[cache] 音乐缓存已初始化: 目录 %s, 最大 %dMB", cacheDir, maxSizeMB)
 	return mc, nil
@@ -327,10 +345,89 @@ func (mc *MusicCache) DeleteByKey(cacheKey string) bool {
 	return affected > 0
 }
 
-// Stats 返回缓存统计信息。
-func (mc *MusicCache) Stats() (count int, totalSize int64) {
-	mc.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(size), 0) FROM music_cache").Scan(&count, &totalSize)
-	return
+// Stats 返回缓存统计信息，包括目录中的临时文件和已清理的残留临时文件。
+func (mc *MusicCache) Stats() CacheStats {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	var st CacheStats
+	mc.db.QueryRow("SELECT COUNT(*), COALESCE(SUM(size), 0) FROM music_cache").Scan(&st.Count, &st.Size)
+	st.TempCleaned, st.TempReclaimed = mc.tempCleaned, mc.tempReclaimed
+	entries, err := os.ReadDir(mc.cacheDir)
+	if err != nil {
+		return st
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			st.TempFiles++
+			st.TempSize += info.Size()
+		}
+	}
+	return st
+}
+
+// AddTags 为歌曲添加心情/风格标签，已有的标签忽略。source 记录标签来源（search/metadata）。
+// 歌曲还没缓存完也可以先打标签，缓存完成后即可按标签搜到。
+func (mc *MusicCache) AddTags(cacheKey string, tags []string, source string) {
+	for _, tag := range tags {
+		if _, err := mc.db.Exec(`INSERT OR IGNORE INTO music_tags (cache_key, tag, source) VALUES (?, ?, ?)`,
+			cacheKey, tag, source); err != nil {
+			logger.Debugf("[cache] 保存标签失败: %v", err)
+		}
+	}
+}
+
+// Tags 返回歌曲的所有标签。
+func (mc *MusicCache) Tags(cacheKey string) []string {
+	rows, err := mc.db.Query(`SELECT tag FROM music_tags WHERE cache_key = ? ORDER BY created_at`, cacheKey)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err == nil {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// SearchByTag 返回带有指定标签且本地文件存在的缓存歌曲，常听的排在前面。
+func (mc *MusicCache) SearchByTag(tag string) []CacheEntry {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	rows, err := mc.db.Query(`
+		SELECT c.id, c.name, c.artist, c.album, c.provider, c.provider_id, c.duration, c.size, c.play_count, c.cached_at, c.last_played
+		FROM music_cache c JOIN music_tags t ON t.cache_key = c.cache_key
+		WHERE t.tag = ?
+		ORDER BY c.play_count DESC, c.last_played DESC
+	`, tag)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var entries []CacheEntry
+	for rows.Next() {
+		var entry CacheEntry
+		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Artist, &entry.Album, &entry.Provider,
+			&entry.ProviderID, &entry.Duration, &entry.Size, &entry.PlayCount, &entry.CachedAt, &entry.LastPlayed); err != nil {
+			continue
+		}
+		cacheKey := fmt.Sprintf("%s_%d", entry.Provider, entry.ProviderID)
+		if _, err := os.Stat(mc.FilePath(cacheKey)); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
 }
 
 // validateIndex 校验索引，移除本地文件不存在的条目。
@@ -405,3 +502,165 @@ func (mc *MusicCache) evictLocked() {
 		logger.Infof("[cache] LRU 淘汰: %s - %s (%s)", name, artist, cacheKey)
 	}
 }
+
+// StaleFileAge 缓存目录中超过这个时间仍未登记的文件（下载中断的临时文件、未登记的歌曲）视为残留。
+const StaleFileAge = time.Hour
+
+// CleanTemp 删除超过 maxAge 没有更新的临时文件（下载中断后留下的 .tmp），返回清理的文件数和回收的字节数。
+// 正在下载的临时文件会持续写入，不会被误删。
+func (mc *MusicCache) CleanTemp(maxAge time.Duration) (int, int64) {
+	if !mc.Enabled() {
+		return 0, 0
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.cleanTempLocked(maxAge)
+}
+
+// cleanTempLocked 同 CleanTemp，调用方需持有 mu。
+func (mc *MusicCache) cleanTempLocked(maxAge time.Duration) (int, int64) {
+	entries, err := os.ReadDir(mc.cacheDir)
+	if err != nil {
+		return 0, 0
+	}
+	files, reclaimed := 0, int64(0)
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if os.Remove(filepath.Join(mc.cacheDir, name)) == nil {
+			files++
+			reclaimed += info.Size()
+		}
+	}
+	if files > 0 {
+		mc.tempCleaned += files
+		mc.tempReclaimed += reclaimed
+		logger.Infof("[cache] 清理 %d 个残留临时文件，回收 %.2f MB", files, float64(reclaimed)/1024/1024)
+	}
+	return files, reclaimed
+}
+
+// Verify 校验缓存完整性：移除文件缺失、为空或大小与索引不符的条目，清理残留的临时文件和未登记的歌曲文件。
+// ctx 取消时立即返回，下次再继续。返回移除的条目和文件数。
+func (mc *MusicCache) Verify(ctx context.Context) int {
+	if !mc.Enabled() {
+		return 0
+	}
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	rows, err := mc.db.QueryContext(ctx, "SELECT cache_key, size FROM music_cache")
+	if err != nil {
+		return 0
+	}
+	indexed := make(map[string]int64)
+	for rows.Next() {
+		var cacheKey string
+		var size int64
+		if err := rows.Scan(&cacheKey, &size); err == nil {
+			indexed[cacheKey] = size
+		}
+	}
+	rows.Close()
+
+	removed := 0
+	for cacheKey, size := range indexed {
+		if ctx.Err() != nil {
+			return removed
+		}
+		info, err := os.Stat(mc.FilePath(cacheKey))
+		if err == nil && info.Size() > 0 && (size == 0 || info.Size() == size) {
+			continue
+		}
+		os.Remove(mc.FilePath(cacheKey))
+		mc.db.Exec("DELETE FROM music_cache WHERE cache_key = ?", cacheKey)
+		logger.Infof("[cache] 校验：移除损坏的缓存 %s", cacheKey)
+		removed++
+	}
+
+	if ctx.Err() != nil {
+		return removed
+	}
+	n, _ := mc.cleanTempLocked(StaleFileAge)
+	removed += n
+
+	entries, err := os.ReadDir(mc.cacheDir)
+	if err != nil {
+		return removed
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return removed
+		}
+		name := e.Name()
+		if !strings.HasSuffix(name, ".mp3") {
+			continue
+		}
+		if _, ok := indexed[strings.TrimSuffix(name, ".mp3")]; ok {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < StaleFileAge {
+			continue
+		}
+		if os.Remove(filepath.Join(mc.cacheDir, name)) == nil {
+			logger.Debugf("[cache] 校验：清理残留文件 %s", name)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Has 判断歌曲是否已缓存（不更新播放次数）。
+func (mc *MusicCache) Has(cacheKey string) bool {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	var n int
+	if err := mc.db.QueryRow("SELECT COUNT(*) FROM music_cache WHERE cache_key = ?", cacheKey).Scan(&n); err != nil || n == 0 {
+		return false
+	}
+	_, err := os.Stat(mc.FilePath(cacheKey))
+	return err == nil
+}
+
+// Download 下载歌曲到缓存并登记索引，用于空闲时预下载。ctx 取消时放弃下载并删除临时文件。
+func (mc *MusicCache) Download(ctx context.Context, cacheKey, url string, entry CacheEntry) error {
+	if !mc.Enabled() {
+		return fmt.Errorf("缓存未启用")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Referer", "https://y.qq.com/")
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("下载失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("下载失败: HTTP %d", resp.StatusCode)
+	}
+
+	cw, err := newCacheFileWriter(mc.TempFilePath(cacheKey))
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	if _, err := io.Copy(cw.file, resp.Body); err != nil {
+		cw.Abort()
+		return fmt.Errorf("下载失败: %w", err)
+	}
+	if err := cw.Commit(mc.FilePath(cacheKey)); err != nil {
+		return fmt.Errorf("保存缓存文件失败: %w", err)
+	}
+	return mc.Store(cacheKey, entry)
+}
@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func TestMusicCache_CleanTemp(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * StaleFileAge)
+	writeFile := func(name string, size int, modTime time.Time) {
+		t.Helper()
+		path := filepath.Join(cacheDir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("qq_1.mp3.tmp", 1000, old)
+	writeFile("qq_2.mp3.tmp", 300, time.Now()) // 正在下载
+
+	// 启动时清理上次运行留下的临时文件
+	cache, err := NewMusicCache(db, cacheDir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "qq_1.mp3.tmp")); !os.IsNotExist(err) {
+		t.Error("stale tmp file should be removed at startup")
+	}
+	st := cache.Stats()
+	if st.TempFiles != 1 || st.TempSize != 300 || st.TempCleaned != 1 || st.TempReclaimed != 1000 {
+		t.Errorf("Stats() = %+v, want 1 pending tmp file of 300 bytes and 1000 bytes reclaimed", st)
+	}
+
+	// 定期清理时累计回收的空间
+	writeFile("qq_3.mp3.tmp", 500, old)
+	if files, reclaimed := cache.CleanTemp(StaleFileAge); files != 1 || reclaimed != 500 {
+		t.Errorf("CleanTemp() = %d, %d, want 1, 500", files, reclaimed)
+	}
+	if st := cache.Stats(); st.TempCleaned != 2 || st.TempReclaimed != 1500 || st.TempFiles != 1 {
+		t.Errorf("Stats() = %+v, want 2 files and 1500 bytes reclaimed", st)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/iabetor/pibuddy/internal/logger"
 	"sync"
+	"time"
 
 	"github.com/gen2brain/malgo"
 )
@@ -21,8 +22,15 @@ type Capture struct {
 	out        chan []float32
 	mu         sync.Mutex
 	running    bool
+
+	// 预录缓冲：保存最近送出的音频，唤醒后补给 ASR，避免唤醒检测延迟吃掉开头的字
+	recentMu sync.Mutex
+	recent   *sampleRing
 }
 
+// preRollCapacity 预录缓冲区保存的最长音频。
+const preRollCapacity = 2 * time.Second
+
 // NewCapture 创建一个新的音频采集实例。
 // sampleRate: 采样率，语音处理通常用 16000
 // channels: 声道数，通常为 1（单声道）
@@ -48,6 +56,7 @@ func NewCapture(sampleRate, channels, frameSize int, micGain float32) (*Capture,
 		frameSize:  uint32(frameSize),
 		micGain:    micGain,
 		out:        make(chan []float32, 64),
+		recent:     newSampleRing(int(preRollCapacity.Seconds() * float64(sampleRate))),
 	}, nil
 }
 
@@ -91,10 +100,14 @@ func (c *Capture) Start() error {
 				}
 			}
 			// 非阻塞发送 —— 如果消费端跟不上就丢帧
+			// 与预录缓冲在同一把锁内写入，保证 TakeRecent 取到的数据和 channel 中的帧不重复
+			c.recentMu.Lock()
 			select {
 			case c.out <- samples:
+				c.recent.Write(samples)
 			default:
 			}
+			c.recentMu.Unlock()
 		},
 	}
 
@@ -146,6 +159,30 @@ func (c *Capture) Drain() int {
 	}
 }
 
+// TakeRecent 取出预录音频：消费端当前位置之前 d 时长的音频，加上 channel 中尚未读取的帧。
+// 尚未读取的帧会从 channel 中移除，调用方应把返回的音频当作后续输入的开头，
+// 用于唤醒后把唤醒词前后的音频补给 ASR。
+func (c *Capture) TakeRecent(d time.Duration) []float32 {
+	c.recentMu.Lock()
+	defer c.recentMu.Unlock()
+
+	pending := 0
+drain:
+	for {
+		select {
+		case frame, ok := <-c.out:
+			if !ok {
+				break drain
+			}
+			pending += len(frame)
+		default:
+			break drain
+		}
+	}
+	n := int(d.Seconds()*float64(c.sampleRate)) + pending
+	return c.recent.Last(n)
+}
+
 // Close 释放所有资源。
 func (c *Capture) Close() {
 	c.Stop()
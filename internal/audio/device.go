@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gen2brain/malgo"
+)
+
+// outputDevice 播放设备选择，Player 和 StreamPlayer 共用。未设置时使用系统默认设备。
+type outputDevice struct {
+	mu   sync.Mutex
+	id   *malgo.DeviceID
+	name string
+}
+
+// set 按名称选择播放设备（不区分大小写的部分匹配，如 "USB" 匹配 "USB Audio DAC"），name 为空恢复默认设备。
+func (o *outputDevice) set(ctx *malgo.AllocatedContext, name string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if name == "" {
+		o.id, o.name = nil, ""
+		return nil
+	}
+
+	infos, err := ctx.Context.Devices(malgo.Playback)
+	if err != nil {
+		return fmt.Errorf("枚举播放设备失败: %w", err)
+	}
+	var names []string
+	for i := range infos {
+		deviceName := infos[i].Name()
+		if strings.Contains(strings.ToLower(deviceName), strings.ToLower(name)) {
+			id := infos[i].ID
+			o.id, o.name = &id, deviceName
+			return nil
+		}
+		names = append(names, deviceName)
+	}
+	return fmt.Errorf("未找到播放设备 %q，可用设备: %s", name, strings.Join(names, "、"))
+}
+
+// apply 把选中的设备写入播放配置。
+func (o *outputDevice) apply(cfg *malgo.DeviceConfig) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.id != nil {
+		cfg.Playback.DeviceID = o.id.Pointer()
+	}
+}
+
+// Name 返回选中的设备名称，使用默认设备时为空。
+func (o *outputDevice) Name() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.name
+}
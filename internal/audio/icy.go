@@ -0,0 +1,175 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// icyReader 去掉 ICY（SHOUTcast/Icecast）直播流中穿插的元数据，只返回音频数据。
+// 服务端每隔 metaint 字节音频插入一个长度字节 n，随后是 n*16 字节的元数据，
+// 如 StreamTitle='歌手 - 歌名';，不足部分用 0 填充。n 为 0 表示这次没有元数据。
+type icyReader struct {
+	r         io.Reader
+	metaint   int
+	remaining int // 距离下一段元数据的音频字节数
+	title     string
+	onTitle   func(string)
+}
+
+func newICYReader(r io.Reader, metaint int, onTitle func(string)) *icyReader {
+	return &icyReader{r: r, metaint: metaint, remaining: metaint, onTitle: onTitle}
+}
+
+// Read 实现 io.Reader，只返回音频数据。
+func (ir *icyReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if ir.remaining == 0 {
+		if err := ir.readMeta(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) > ir.remaining {
+		p = p[:ir.remaining]
+	}
+	n, err := ir.r.Read(p)
+	ir.remaining -= n
+	return n, err
+}
+
+// readMeta 读取一段元数据，节目/歌曲名变化时回调 onTitle。
+func (ir *icyReader) readMeta() error {
+	var size [1]byte
+	if _, err := io.ReadFull(ir.r, size[:]); err != nil {
+		return err
+	}
+	ir.remaining = ir.metaint
+	if size[0] == 0 {
+		return nil
+	}
+	meta := make([]byte, int(size[0])*16)
+	if _, err := io.ReadFull(ir.r, meta); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	title, ok := parseStreamTitle(string(bytes.TrimRight(meta, "\x00")))
+	if ok && title != ir.title {
+		ir.title = title
+		if ir.onTitle != nil {
+			ir.onTitle(title)
+		}
+	}
+	return nil
+}
+
+// parseStreamTitle 从 ICY 元数据中取出 StreamTitle，没有该字段时 ok 为 false。
+// 标题本身可能带单引号，以 "';" 作为结束。
+func parseStreamTitle(meta string) (title string, ok bool) {
+	const key = "StreamTitle='"
+	start := strings.Index(meta, key)
+	if start < 0 {
+		return "", false
+	}
+	rest := meta[start+len(key):]
+	end := strings.Index(rest, "';")
+	if end < 0 {
+		end = strings.LastIndex(rest, "'")
+	}
+	if end < 0 {
+		end = len(rest)
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(rest[:end], "")), true
+}
+
+// isMP3Stream 判断直播流的 Content-Type 是否可以用 MP3 解码器播放，没有声明类型时按 MP3 尝试。
+func isMP3Stream(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "audio/mpeg", "audio/mp3", "audio/mpeg3", "audio/x-mpeg", "application/octet-stream":
+		return true
+	}
+	return false
+}
+
+// playLive 播放网络电台等直播流：边收边解码，不缓存、不写临时文件、不支持跳转，
+// 服务端结束推流时正常返回，ctx 取消或 Stop 时返回 ctx 的错误。
+func (sp *StreamPlayer) playLive(ctx context.Context, url string, onTitle func(string)) error {
+	sp.mu.Lock()
+	if sp.closed {
+		sp.mu.Unlock()
+		return fmt.Errorf("播放器已关闭")
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	sp.cancel = cancel
+	sp.mu.Unlock()
+
+	defer func() {
+		sp.mu.Lock()
+		sp.cancel = nil
+		sp.mu.Unlock()
+		cancel()
+	}()
+
+	waitStart := time.Now()
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Icy-MetaData", "1")
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		if streamCtx.Err() != nil {
+			return streamCtx.Err()
+		}
+		return fmt.Errorf("连接电台失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("电台返回错误状态码: %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !isMP3Stream(ct) {
+		return fmt.Errorf("不支持的电台音频格式: %s（只支持 MP3 直播流）", ct)
+	}
+
+	var body io.Reader = resp.Body
+	if metaint, err := strconv.Atoi(resp.Header.Get("Icy-Metaint")); err == nil && metaint > 0 {
+		body = newICYReader(resp.Body, metaint, onTitle)
+	}
+	name := resp.Header.Get("Icy-Name")
+	if name == "" {
+		name = url
+	}
+	logger.Infof("[audio] 开始播放直播流: %s", name)
+
+	// 不能把 io.Seeker 交给解码器：go-mp3 会为了计算总长度一直读到流的末尾
+	decoder, err := mp3.NewDecoder(bufio.NewReaderSize(body, 64<<10))
+	if err != nil {
+		if streamCtx.Err() != nil {
+			return streamCtx.Err()
+		}
+		return fmt.Errorf("创建 MP3 解码器失败: %w", err)
+	}
+	return sp.playDecoder(streamCtx, decoder, waitStart)
+}
@@ -0,0 +1,80 @@
+package audio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// icyStream 构造每 metaint 字节音频穿插一段元数据的直播流，titles 为空串时该段不带元数据。
+func icyStream(audio []byte, metaint int, titles []string) []byte {
+	var buf bytes.Buffer
+	for i := 0; len(audio) > 0; i++ {
+		n := min(metaint, len(audio))
+		buf.Write(audio[:n])
+		audio = audio[n:]
+		if n < metaint {
+			break
+		}
+		meta := ""
+		if i < len(titles) && titles[i] != "" {
+			meta = "StreamTitle='" + titles[i] + "';StreamUrl='';"
+		}
+		blocks := (len(meta) + 15) / 16
+		buf.WriteByte(byte(blocks))
+		buf.WriteString(meta)
+		buf.Write(make([]byte, blocks*16-len(meta)))
+	}
+	return buf.Bytes()
+}
+
+func TestICYReader(t *testing.T) {
+	audio := testSong(10000)
+	stream := icyStream(audio, 1000, []string{"新闻联播", "", "新闻联播", "Rock'n'Roll - 猫王"})
+
+	var titles []string
+	r := newICYReader(bytes.NewReader(stream), 1000, func(title string) { titles = append(titles, title) })
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(got, audio) {
+		t.Fatalf("audio data mismatch: got %d bytes, want %d", len(got), len(audio))
+	}
+	if len(titles) != 2 || titles[0] != "新闻联播" || titles[1] != "Rock'n'Roll - 猫王" {
+		t.Errorf("titles = %q, want only the changes", titles)
+	}
+}
+
+func TestParseStreamTitle(t *testing.T) {
+	tests := []struct {
+		meta  string
+		title string
+		ok    bool
+	}{
+		{"StreamTitle='周杰伦 - 晴天';StreamUrl='';", "周杰伦 - 晴天", true},
+		{"StreamTitle='';", "", true},
+		{"StreamTitle='没有结束符'", "没有结束符", true},
+		{"StreamUrl='http://example.com';", "", false},
+	}
+	for _, tt := range tests {
+		title, ok := parseStreamTitle(tt.meta)
+		if title != tt.title || ok != tt.ok {
+			t.Errorf("parseStreamTitle(%q) = %q, %v, want %q, %v", tt.meta, title, ok, tt.title, tt.ok)
+		}
+	}
+}
+
+func TestIsMP3Stream(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"":                              true,
+		"audio/mpeg":                    true,
+		"audio/mpeg; charset=utf-8":     true,
+		"audio/aacp":                    false,
+		"application/vnd.apple.mpegurl": false,
+	} {
+		if got := isMP3Stream(ct); got != want {
+			t.Errorf("isMP3Stream(%q) = %v, want %v", ct, got, want)
+		}
+	}
+}
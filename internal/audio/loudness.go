@@ -0,0 +1,217 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// 响度测量按 ITU-R BS.1770：K 计权（高架 + 高通两级双二阶滤波）后按 400ms 块计算均方，
+// 经过绝对门限（-70 LUFS）和相对门限（-10 LU）得到整体响度。单声道下 LUFS = -0.691 + 10·log10(均方)。
+
+const (
+	loudnessAbsGate = -70.0 // 绝对门限（LUFS）
+	loudnessRelGate = -10.0 // 相对门限（LU）
+	loudnessBlock   = 0.4   // 测量块长度（秒）
+)
+
+// biquad 双二阶 IIR 滤波器（直接 II 型转置）。
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	z1, z2             float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.z1
+	f.z1 = f.b1*x - f.a1*y + f.z2
+	f.z2 = f.b2*x - f.a2*y
+	return y
+}
+
+// kWeighting 返回指定采样率下的 K 计权滤波器（系数按 BS.1770 的模拟原型换算）。
+func kWeighting(sampleRate int) [2]biquad {
+	fs := float64(sampleRate)
+
+	// 第一级：约 +4dB 的高架滤波，模拟头部声学效应
+	gain, q, fc := 3.999843853973347, 0.7071752369554196, 1681.974450955533
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * fc / fs
+	alpha := math.Sin(w0) / (2 * q)
+	cosw := math.Cos(w0)
+	sqrtA := math.Sqrt(a)
+	a0 := (a + 1) - (a-1)*cosw + 2*sqrtA*alpha
+	shelf := biquad{
+		b0: a * ((a + 1) + (a-1)*cosw + 2*sqrtA*alpha) / a0,
+		b1: -2 * a * ((a - 1) + (a+1)*cosw) / a0,
+		b2: a * ((a + 1) + (a-1)*cosw - 2*sqrtA*alpha) / a0,
+		a1: 2 * ((a - 1) - (a+1)*cosw) / a0,
+		a2: ((a + 1) - (a-1)*cosw - 2*sqrtA*alpha) / a0,
+	}
+
+	// 第二级：约 38Hz 的高通滤波（RLB 计权）
+	q, fc = 0.5003270373238773, 38.13547087602444
+	w0 = 2 * math.Pi * fc / fs
+	alpha = math.Sin(w0) / (2 * q)
+	cosw = math.Cos(w0)
+	a0 = 1 + alpha
+	highpass := biquad{
+		b0: (1 + cosw) / 2 / a0,
+		b1: -(1 + cosw) / a0,
+		b2: (1 + cosw) / 2 / a0,
+		a1: -2 * cosw / a0,
+		a2: (1 - alpha) / a0,
+	}
+	return [2]biquad{shelf, highpass}
+}
+
+func msToLUFS(ms float64) float64 {
+	if ms <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(ms)
+}
+
+func lufsToMS(lufs float64) float64 {
+	return math.Pow(10, (lufs+0.691)/10)
+}
+
+// Loudness 计算单声道音频的整体响度（LUFS），静音时返回 -Inf。
+// 短于一个测量块的音频按整段计算。
+func Loudness(samples []float32, sampleRate int) float64 {
+	if len(samples) == 0 || sampleRate <= 0 {
+		return math.Inf(-1)
+	}
+	filters := kWeighting(sampleRate)
+	weighted := make([]float64, len(samples))
+	for i, s := range samples {
+		x := filters[0].process(float64(s))
+		x = filters[1].process(x)
+		weighted[i] = x * x
+	}
+
+	// 400ms 块，75% 重叠
+	blockLen := int(loudnessBlock * float64(sampleRate))
+	step := blockLen / 4
+	if len(weighted) < blockLen {
+		var sum float64
+		for _, v := range weighted {
+			sum += v
+		}
+		return msToLUFS(sum / float64(len(weighted)))
+	}
+	var blocks []float64
+	for start := 0; start+blockLen <= len(weighted); start += step {
+		var sum float64
+		for _, v := range weighted[start : start+blockLen] {
+			sum += v
+		}
+		if ms := sum / float64(blockLen); msToLUFS(ms) > loudnessAbsGate {
+			blocks = append(blocks, ms)
+		}
+	}
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	// 相对门限：去掉比绝对门限后的平均响度低 10 LU 以上的块（句间停顿等）
+	var sum float64
+	for _, ms := range blocks {
+		sum += ms
+	}
+	relGate := lufsToMS(msToLUFS(sum/float64(len(blocks))) + loudnessRelGate)
+	var gated float64
+	var n int
+	for _, ms := range blocks {
+		if ms > relGate {
+			gated += ms
+			n++
+		}
+	}
+	if n == 0 {
+		return msToLUFS(sum / float64(len(blocks)))
+	}
+	return msToLUFS(gated / float64(n))
+}
+
+// NormalizeLoudness 把音频调整到目标响度（LUFS），原地修改并返回实际增益（dB）。
+// 增益限制在 ±maxGainDB 之内，且不让峰值超过 0.99，避免削波。静音时不做处理。
+func NormalizeLoudness(samples []float32, sampleRate int, targetLUFS, maxGainDB float64) float64 {
+	current := Loudness(samples, sampleRate)
+	if math.IsInf(current, -1) {
+		return 0
+	}
+	gainDB := math.Max(-maxGainDB, math.Min(maxGainDB, targetLUFS-current))
+
+	var peak float64
+	for _, s := range samples {
+		peak = math.Max(peak, math.Abs(float64(s)))
+	}
+	if peak > 0 {
+		gainDB = math.Min(gainDB, 20*math.Log10(0.99/peak))
+	}
+
+	gain := float32(math.Pow(10, gainDB/20))
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return gainDB
+}
+
+// loudnessMeter 持续测量正在播放的音乐响度（K 计权后的均方按约 3 秒做指数平均），
+// 跳过静音块，音乐停止后保留最近一次的测量结果。
+type loudnessMeter struct {
+	mu         sync.Mutex
+	sampleRate int
+	filters    [2]biquad
+	ms         float64
+	valid      bool
+}
+
+const (
+	loudnessMeterWindow  = 3.0   // 平均的时间常数（秒）
+	loudnessMeterSilence = -50.0 // 低于此响度的块视为静音（曲间空白、暂停后的余音），不计入
+)
+
+// start 开始测量新的一段音频，采样率变化时重建滤波器。
+func (m *loudnessMeter) start(sampleRate int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sampleRate != m.sampleRate {
+		m.sampleRate = sampleRate
+		m.filters = kWeighting(sampleRate)
+	}
+}
+
+// feedPCM 测量一块 S16 PCM 数据（在音频回调中调用，按增益缩放之前的数据）。
+func (m *loudnessMeter) feedPCM(pcm []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sampleRate <= 0 || len(pcm) < 2 {
+		return
+	}
+	var sum float64
+	n := len(pcm) / 2
+	for i := 0; i+1 < len(pcm); i += 2 {
+		x := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768
+		x = m.filters[0].process(x)
+		x = m.filters[1].process(x)
+		sum += x * x
+	}
+	ms := sum / float64(n)
+	if msToLUFS(ms) <= loudnessMeterSilence {
+		return
+	}
+	if !m.valid {
+		m.ms, m.valid = ms, true
+		return
+	}
+	weight := math.Min(1, float64(n)/float64(m.sampleRate)/loudnessMeterWindow)
+	m.ms += (ms - m.ms) * weight
+}
+
+// loudness 返回最近的音乐响度（LUFS），还没有播放过音乐时 ok 为 false。
+func (m *loudnessMeter) loudness() (lufs float64, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return msToLUFS(m.ms), m.valid
+}
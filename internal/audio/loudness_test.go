@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func sine(freq, amplitude float64, sampleRate int, seconds float64) []float32 {
+	n := int(seconds * float64(sampleRate))
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	return samples
+}
+
+func TestLoudness_Sine(t *testing.T) {
+	// BS.1770：单声道 1kHz、0 dBFS 的正弦波响度为 -3.01 LUFS，因此峰值 -20 dBFS 约为 -23 LUFS
+	for _, rate := range []int{16000, 44100, 48000} {
+		got := Loudness(sine(1000, 0.1, rate, 3), rate)
+		if math.Abs(got-(-23)) > 0.5 {
+			t.Errorf("rate %d: loudness = %.2f, want about -23", rate, got)
+		}
+	}
+}
+
+func TestLoudness_Silence(t *testing.T) {
+	if got := Loudness(make([]float32, 16000), 16000); !math.IsInf(got, -1) {
+		t.Errorf("silence loudness = %v", got)
+	}
+	if got := Loudness(nil, 16000); !math.IsInf(got, -1) {
+		t.Errorf("empty loudness = %v", got)
+	}
+}
+
+func TestLoudness_GatesPauses(t *testing.T) {
+	// 语音中间的停顿不应拉低响度
+	speech := sine(1000, 0.1, 16000, 2)
+	withPause := append(append(append([]float32{}, speech...), make([]float32, 32000)...), speech...)
+	a, b := Loudness(speech, 16000), Loudness(withPause, 16000)
+	if math.Abs(a-b) > 0.5 {
+		t.Errorf("loudness with pause = %.2f, without = %.2f", b, a)
+	}
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	samples := sine(1000, 0.1, 16000, 2)
+	gain := NormalizeLoudness(samples, 16000, -16, 12)
+	if got := Loudness(samples, 16000); math.Abs(got-(-16)) > 0.5 {
+		t.Errorf("normalized loudness = %.2f (gain %.2f)", got, gain)
+	}
+
+	// 增益受最大调整幅度限制
+	quiet := sine(1000, 0.001, 16000, 2)
+	if gain := NormalizeLoudness(quiet, 16000, -16, 12); gain != 12 {
+		t.Errorf("gain = %.2f, want capped at 12", gain)
+	}
+
+	// 不能削波
+	loud := sine(1000, 0.9, 16000, 2)
+	NormalizeLoudness(loud, 16000, 0, 20)
+	for _, s := range loud {
+		if math.Abs(float64(s)) > 0.99+1e-6 {
+			t.Fatalf("sample %v exceeds peak limit", s)
+		}
+	}
+}
+
+func TestLoudnessMeter(t *testing.T) {
+	var m loudnessMeter
+	if _, ok := m.loudness(); ok {
+		t.Fatal("meter should be invalid before any audio")
+	}
+	m.start(16000)
+	pcm := Float32ToBytes(sine(1000, 0.1, 16000, 6))
+	for i := 0; i < len(pcm); i += 4096 * 2 {
+		end := i + 4096*2
+		if end > len(pcm) {
+			end = len(pcm)
+		}
+		m.feedPCM(pcm[i:end])
+	}
+	// 静音块不影响结果
+	m.feedPCM(make([]byte, 8192))
+	got, ok := m.loudness()
+	if !ok || math.Abs(got-(-23)) > 0.5 {
+		t.Errorf("meter loudness = %.2f, %v", got, ok)
+	}
+}
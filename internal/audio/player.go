@@ -15,6 +15,7 @@ type Player struct {
 	channels uint32
 	mu       sync.Mutex
 	closed   bool
+	output   outputDevice
 }
 
 // NewPlayer 创建一个新的音频播放实例。
@@ -32,7 +33,17 @@ func NewPlayer(channels int) (*Player, error) {
 	}, nil
 }
 
-// Play 通过默认扬声器播放 float32 音频样本。
+// SetDevice 选择播放设备（按名称部分匹配），name 为空使用系统默认设备。
+func (p *Player) SetDevice(name string) error {
+	return p.output.set(p.ctx, name)
+}
+
+// DeviceName 返回选中的播放设备名称，使用默认设备时为空。
+func (p *Player) DeviceName() string {
+	return p.output.Name()
+}
+
+// Play 通过扬声器（默认或 SetDevice 选择的设备）播放 float32 音频样本。
 // sampleRate 参数指定音频数据的采样率，播放设备将按此采样率播放。
 // 阻塞直到播放完成或 ctx 被取消。
 func (p *Player) Play(ctx context.Context, samples []float32, sampleRate int) error {
@@ -61,6 +72,7 @@ func (p *Player) Play(ctx context.Context, samples []float32, sampleRate int) er
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
 	deviceConfig.Playback.Format = malgo.FormatS16
 	deviceConfig.Playback.Channels = p.channels
+	p.output.apply(&deviceConfig)
 	deviceConfig.SampleRate = uint32(sampleRate) // 使用音频实际采样率
 	deviceConfig.PeriodSizeInFrames = 4096       // 较大缓冲区，防止 CPU 繁忙时 underrun 导致卡顿
 	deviceConfig.Periods = 3
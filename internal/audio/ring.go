@@ -0,0 +1,58 @@
+package audio
+
+// sampleRing 定长环形缓冲区，保存最近的音频采样。不是并发安全的，由调用方加锁。
+type sampleRing struct {
+	buf    []float32
+	pos    int
+	filled bool
+}
+
+func newSampleRing(size int) *sampleRing {
+	return &sampleRing{buf: make([]float32, size)}
+}
+
+// Write 写入采样，超出容量时覆盖最旧的数据。
+func (r *sampleRing) Write(samples []float32) {
+	if len(r.buf) == 0 {
+		return
+	}
+	for _, s := range samples {
+		r.buf[r.pos] = s
+		r.pos++
+		if r.pos == len(r.buf) {
+			r.pos = 0
+			r.filled = true
+		}
+	}
+}
+
+// Len 返回已保存的采样数。
+func (r *sampleRing) Len() int {
+	if r.filled {
+		return len(r.buf)
+	}
+	return r.pos
+}
+
+// Last 按时间顺序返回最近 n 个采样（不足 n 个时返回全部）。
+func (r *sampleRing) Last(n int) []float32 {
+	if n > r.Len() {
+		n = r.Len()
+	}
+	if n <= 0 {
+		return nil
+	}
+	out := make([]float32, 0, n)
+	start := r.pos - n
+	if start < 0 {
+		out = append(out, r.buf[len(r.buf)+start:]...)
+		start = 0
+	}
+	return append(out, r.buf[start:r.pos]...)
+}
+
+// Reset 清空缓冲区。
+func (r *sampleRing) Reset() {
+	r.pos = 0
+	r.filled = false
+}
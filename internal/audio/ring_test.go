@@ -0,0 +1,35 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSampleRing(t *testing.T) {
+	r := newSampleRing(5)
+	if got := r.Last(3); got != nil {
+		t.Errorf("空缓冲区应返回 nil，实际 %v", got)
+	}
+
+	r.Write([]float32{1, 2, 3})
+	if got := r.Last(10); !reflect.DeepEqual(got, []float32{1, 2, 3}) {
+		t.Errorf("Last(10) = %v, want [1 2 3]", got)
+	}
+
+	// 写满后覆盖最旧的数据
+	r.Write([]float32{4, 5, 6, 7})
+	if r.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", r.Len())
+	}
+	if got := r.Last(5); !reflect.DeepEqual(got, []float32{3, 4, 5, 6, 7}) {
+		t.Errorf("Last(5) = %v, want [3 4 5 6 7]", got)
+	}
+	if got := r.Last(2); !reflect.DeepEqual(got, []float32{6, 7}) {
+		t.Errorf("Last(2) = %v, want [6 7]", got)
+	}
+
+	r.Reset()
+	if r.Len() != 0 || r.Last(1) != nil {
+		t.Error("Reset 后应为空")
+	}
+}
@@ -2,14 +2,17 @@ package audio
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"net"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gen2brain/malgo"
@@ -20,6 +23,11 @@ import (
 type PlayOptions struct {
 	CacheKey string      // 缓存标识，如 "qq_12345678"
 	Cache    *MusicCache // 缓存管理器（nil 则不缓存）
+
+	// Live 为网络电台等不会结束的直播流：不缓存、不写临时文件，请求 ICY 元数据，
+	// 电台推送的节目/歌曲名变化时回调 OnMetadata（可为 nil）。
+	Live       bool
+	OnMetadata func(title string)
 }
 
 // StreamPlayer 支持从 HTTP URL 流式播放 MP3 音频。
@@ -29,6 +37,9 @@ type StreamPlayer struct {
 	mu       sync.Mutex
 	cancel   context.CancelFunc
 	closed   bool
+	gain     atomic.Int32 // 音量百分比，默认 100，报时等播报时临时压低
+	output   outputDevice
+	meter    loudnessMeter // 音乐响度（音量缩放前），用于让语音播报和音乐响度一致
 }
 
 // NewStreamPlayer 创建流式播放器。
@@ -39,16 +50,60 @@ func NewStreamPlayer(channels int) (*StreamPlayer, error) {
 		return nil, fmt.Errorf("初始化播放上下文失败: %w", err)
 	}
 
-	return &StreamPlayer{
+	sp := &StreamPlayer{
 		ctx:      ctx,
 		channels: uint32(channels),
-	}, nil
+	}
+	sp.gain.Store(100)
+	return sp, nil
+}
+
+// SetDevice 选择播放设备（按名称部分匹配），name 为空使用系统默认设备。
+func (sp *StreamPlayer) SetDevice(name string) error {
+	return sp.output.set(sp.ctx, name)
+}
+
+// DeviceName 返回选中的播放设备名称，使用默认设备时为空。
+func (sp *StreamPlayer) DeviceName() string {
+	return sp.output.Name()
+}
+
+// SetGain 设置音乐音量百分比（0-100），对正在播放的音乐立即生效，不影响系统音量和语音播报。
+func (sp *StreamPlayer) SetGain(percent int) {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	sp.gain.Store(int32(percent))
+}
+
+// Loudness 返回最近播放的音乐响度（LUFS，按原始音量计算，不含 SetGain 的缩放），
+// 还没有播放过音乐时 ok 为 false。
+func (sp *StreamPlayer) Loudness() (lufs float64, ok bool) {
+	return sp.meter.loudness()
+}
+
+// applyGain 按当前音量百分比缩放 S16 PCM 数据。
+func (sp *StreamPlayer) applyGain(pcm []byte) {
+	gain := sp.gain.Load()
+	if gain >= 100 {
+		return
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := int32(int16(binary.LittleEndian.Uint16(pcm[i:]))) * gain / 100
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(v)))
+	}
 }
 
 // Play 从 URL 流式下载并播放 MP3 音频。
 // 使用边下载边播放的流式架构，减少首次播放延迟。
 // opts 为可选的缓存选项，nil 时行为与不缓存一致。
 func (sp *StreamPlayer) Play(ctx context.Context, url string, opts *PlayOptions) error {
+	if opts != nil && opts.Live {
+		return sp.playLive(ctx, url, opts.OnMetadata)
+	}
+
 	// 如果有缓存选项且缓存命中，直接从本地文件播放
 	if opts != nil && opts.Cache != nil && opts.Cache.Enabled() && opts.CacheKey != "" {
 		if cachedPath, ok := opts.Cache.Lookup(opts.CacheKey); ok {
@@ -76,26 +131,32 @@ func (sp *StreamPlayer) Play(ctx context.Context, url string, opts *PlayOptions)
 		sp.mu.Unlock()
 	}()
 
-	// 创建流式缓冲，边下载边解码
-	sb := newStreamingBuffer()
-
-	// 后台下载 goroutine：将数据流式写入 streamingBuffer
-	// 同时 tee 写入本地缓存文件
-	var cacheWriter *cacheFileWriter
+	// 下载的数据同时写入临时文件：开启缓存时写入缓存的临时文件，下载完成后提交为缓存；
+	// 未开启缓存时写入系统临时文件，下载结束后删除。streamingBuffer 窗口外的数据从这个文件读取。
+	var tmpPath, cacheCommitPath string
 	if opts != nil && opts.Cache != nil && opts.Cache.Enabled() && opts.CacheKey != "" {
-		cw, err := newCacheFileWriter(opts.Cache.TempFilePath(opts.CacheKey))
-		if err != nil {
-			logger.Warnf("[audio] 创建缓存文件失败（将跳过缓存）: %v", err)
-		} else {
-			cacheWriter = cw
+		tmpPath = opts.Cache.TempFilePath(opts.CacheKey)
+		cacheCommitPath = opts.Cache.FilePath(opts.CacheKey)
+	}
+	cacheWriter, err := newCacheFileWriter(tmpPath)
+	if err != nil {
+		logger.Warnf("[audio] 创建临时文件失败（将跳过缓存，全部缓冲在内存中）: %v", err)
+		cacheWriter, cacheCommitPath = nil, ""
+	}
+	var spill *os.File
+	if cacheWriter != nil {
+		if spill, err = os.Open(cacheWriter.path); err != nil {
+			logger.Warnf("[audio] 打开临时文件失败（将全部缓冲在内存中）: %v", err)
+			spill = nil
 		}
 	}
 
-	// 下载完成后立即 commit 缓存文件（不必等播放结束），这样即使播放被打断也能保留缓存
-	var cacheCommitPath string
-	if cacheWriter != nil && opts != nil && opts.Cache != nil && opts.CacheKey != "" {
-		cacheCommitPath = opts.Cache.FilePath(opts.CacheKey)
-	}
+	// 创建流式缓冲，边下载边解码
+	sb := newStreamingBuffer(spill)
+	defer sb.Close()
+
+	// 后台下载 goroutine：将数据写入临时文件和 streamingBuffer，下载完成后立即 commit 缓存文件
+	// （不必等播放结束），这样即使播放被打断也能保留缓存
 	go sp.streamDownload(streamCtx, url, sb, cacheWriter, cacheCommitPath)
 
 	// 等待至少 32KB 数据到达再初始化解码器（MP3 帧头 + 几帧数据）
@@ -125,6 +186,11 @@ func (sp *StreamPlayer) Play(ctx context.Context, url string, opts *PlayOptions)
 		return fmt.Errorf("创建 MP3 解码器失败: %w", err)
 	}
 
+	return sp.playDecoder(streamCtx, decoder, waitStart)
+}
+
+// playDecoder 边解码边播放，直到解码结束、出错或 ctx 取消。waitStart 为开始请求的时间，用于记录首次出声的延迟。
+func (sp *StreamPlayer) playDecoder(streamCtx context.Context, decoder *mp3.Decoder, waitStart time.Time) error {
 	sampleRate := decoder.SampleRate()
 	logger.Debugf("[audio] 流式播放: 采样率 %d Hz", sampleRate)
 
@@ -226,7 +292,9 @@ preBufferLoop:
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
 	deviceConfig.Playback.Format = malgo.FormatS16
 	deviceConfig.Playback.Channels = sp.channels
+	sp.output.apply(&deviceConfig)
 	deviceConfig.SampleRate = uint32(sampleRate)
+	sp.meter.start(sampleRate)
 	deviceConfig.PeriodSizeInFrames = 4096 // 更大的缓冲区
 	deviceConfig.Periods = 4
 
@@ -263,6 +331,8 @@ preBufferLoop:
 				pos = end
 				writePos += copied
 			}
+			sp.meter.feedPCM(outputSamples[:totalBytes])
+			sp.applyGain(outputSamples[:totalBytes])
 		},
 	}
 
@@ -348,6 +418,10 @@ func isNetworkError(err error) bool {
 		strings.Contains(err.Error(), "broken pipe")
 }
 
+// downloadClient 下载音乐使用的 HTTP 客户端，与其他外部 API 共享按 host 的限流。
+// 不设置超时，长歌曲的下载时间不可预知，由 ctx 控制取消。
+var downloadClient = &http.Client{Transport: ratelimit.NewTransport(nil)}
+
 // streamDownload 流式下载音频数据到 streamingBuffer，支持网络中断后断点续传。
 // 如果 cw 不为 nil，同时将数据写入缓存文件。
 // 下载成功完成后，如果 commitPath 非空则自动 commit 缓存文件。
@@ -389,16 +463,17 @@ func (sp *StreamPlayer) streamDownload(ctx context.Context, url string, sb *stre
 			logger.Debugf("[audio] 断点续传: 从 %d 字节处继续下载 (第 %d 次重试)", downloaded, attempt)
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := downloadClient.Do(req)
 		if err != nil {
 			if ctx.Err() != nil {
 				sb.Finish(ctx.Err())
 				return
 			}
 			if attempt < maxRetries {
-				logger.Debugf("[audio] 下载失败，%d 秒后重试: %v", attempt+1, err)
+				delay := ratelimit.Backoff(attempt)
+				logger.Debugf("[audio] 下载失败，%s 后重试: %v", delay.Round(time.Millisecond), err)
 				select {
-				case <-time.After(time.Duration(attempt+1) * time.Second):
+				case <-time.After(delay):
 				case <-ctx.Done():
 					sb.Finish(ctx.Err())
 					return
@@ -414,7 +489,7 @@ func (sp *StreamPlayer) streamDownload(ctx context.Context, url string, sb *stre
 			if attempt < maxRetries {
 				logger.Debugf("[audio] 下载返回状态码 %d，重试中", resp.StatusCode)
 				select {
-				case <-time.After(time.Duration(attempt+1) * time.Second):
+				case <-time.After(ratelimit.Backoff(attempt)):
 				case <-ctx.Done():
 					sb.Finish(ctx.Err())
 					return
@@ -440,11 +515,16 @@ func (sp *StreamPlayer) streamDownload(ctx context.Context, url string, sb *stre
 				if n > 0 {
 					chunk := make([]byte, n)
 					copy(chunk, buf[:n])
-					sb.Append(chunk)
-					// 同时写入缓存文件
+					// 先写入临时文件，streamingBuffer 窗口外的数据从文件读取
 					if cw != nil {
-						cw.Write(chunk)
+						if werr := cw.Write(chunk); werr != nil {
+							logger.Warnf("[audio] 写入临时文件失败（将跳过缓存，后续数据缓冲在内存中）: %v", werr)
+							sb.StopSpill()
+							cw.Abort()
+							cw = nil
+						}
 					}
+					sb.Append(chunk)
 				}
 				if err != nil {
 					if err == io.EOF {
@@ -469,9 +549,10 @@ func (sp *StreamPlayer) streamDownload(ctx context.Context, url string, sb *stre
 		}
 
 		if isNetworkError(readErr) && attempt < maxRetries {
-			logger.Debugf("[audio] 读取中断(%d 字节已下载)，%d 秒后重试: %v", sb.Len(), attempt+1, readErr)
+			delay := ratelimit.Backoff(attempt)
+			logger.Debugf("[audio] 读取中断(%d 字节已下载)，%s 后重试: %v", sb.Len(), delay.Round(time.Millisecond), readErr)
 			select {
-			case <-time.After(time.Duration(attempt+1) * time.Second):
+			case <-time.After(delay):
 			case <-ctx.Done():
 				sb.Finish(ctx.Err())
 				return
@@ -498,33 +579,86 @@ func (sp *StreamPlayer) streamDownload(ctx context.Context, url string, sb *stre
 	}
 }
 
+// streamWindow streamingBuffer 在内存中保留的最大数据量，超出部分从临时文件读取。
+const streamWindow = 1 << 20
+
 // streamingBuffer 是一个边下载边可读的 io.ReadSeeker 实现。
 // HTTP 下载 goroutine 通过 Append 写入数据，Finish 标记下载完成。
 // go-mp3 解码器通过 Read/Seek 接口消费数据。
 // 当 Read 到达缓冲末尾但下载未完成时，会阻塞等待更多数据。
+//
+// 下载的数据同时写入临时文件（开启缓存时就是缓存的临时文件），内存中只保留从读取位置开始的
+// 一个窗口（最多 streamWindow 字节），窗口外的数据从临时文件读取，长的无损音乐也不会占满内存。
+// 没有临时文件时全部保存在内存中；写临时文件失败后，之后的数据也改为保存在内存中。
 type streamingBuffer struct {
 	mu       sync.Mutex
 	cond     *sync.Cond
-	data     []byte
-	pos      int
-	finished bool // 下载完成标记
-	err      error // 下载出错
+	data     []byte // 内存窗口，对应 [base, base+len(data))
+	base     int64
+	pos      int64
+	total    int64    // 已下载的字节数
+	spill    *os.File // 临时文件的只读句柄
+	spilling bool     // 新数据是否仍在写入临时文件
+	fileEnd  int64    // 临时文件中可读的数据长度
+	finished bool     // 下载完成标记
+	err      error    // 下载出错
 }
 
-func newStreamingBuffer() *streamingBuffer {
-	sb := &streamingBuffer{}
+// newStreamingBuffer 创建流式缓冲。spill 为下载数据同时写入的临时文件的只读句柄，可为 nil。
+func newStreamingBuffer(spill *os.File) *streamingBuffer {
+	sb := &streamingBuffer{spill: spill, spilling: spill != nil}
 	sb.cond = sync.NewCond(&sb.mu)
 	return sb
 }
 
-// Append 由下载 goroutine 调用，追加数据到缓冲。
+// Append 由下载 goroutine 调用，追加数据到缓冲。写入临时文件时，调用前数据应已写入。
 func (sb *streamingBuffer) Append(chunk []byte) {
 	sb.mu.Lock()
-	sb.data = append(sb.data, chunk...)
+	contiguous := sb.base+int64(len(sb.data)) == sb.total
+	sb.total += int64(len(chunk))
+	if sb.spilling {
+		sb.fileEnd = sb.total
+		sb.trimLocked()
+	}
+	// 窗口已满时只保存在文件里，读到时再载入
+	if contiguous && (!sb.spilling || len(sb.data)+len(chunk) <= streamWindow) {
+		sb.data = append(sb.data, chunk...)
+	}
 	sb.mu.Unlock()
 	sb.cond.Broadcast()
 }
 
+// StopSpill 写临时文件失败时调用：已写入的部分仍从文件读取，之后的数据保存在内存中。
+func (sb *streamingBuffer) StopSpill() {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if !sb.spilling {
+		return
+	}
+	sb.spilling = false
+
+	// 内存窗口补齐到已下载的末尾，之后的 Append 直接接在后面
+	if end := sb.base + int64(len(sb.data)); end < sb.total {
+		start := min(max(sb.pos, 0), sb.total)
+		buf := make([]byte, sb.total-start)
+		if _, err := sb.spill.ReadAt(buf, start); err != nil {
+			sb.finished, sb.err = true, fmt.Errorf("读取临时文件失败: %w", err)
+			sb.cond.Broadcast()
+			return
+		}
+		sb.data, sb.base = buf, start
+	}
+}
+
+// trimLocked 丢弃内存窗口中已读过、且临时文件中有备份的数据。
+func (sb *streamingBuffer) trimLocked() {
+	keep := min(sb.pos, sb.fileEnd, sb.base+int64(len(sb.data)))
+	if drop := keep - sb.base; drop > 0 {
+		sb.data = sb.data[:copy(sb.data, sb.data[drop:])]
+		sb.base = keep
+	}
+}
+
 // Finish 标记下载完成（正常或出错）。
 func (sb *streamingBuffer) Finish(err error) {
 	sb.mu.Lock()
@@ -534,11 +668,22 @@ func (sb *streamingBuffer) Finish(err error) {
 	sb.cond.Broadcast()
 }
 
-// Len 返回当前已缓冲的数据长度。
+// Len 返回当前已下载的数据长度。
 func (sb *streamingBuffer) Len() int {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
-	return len(sb.data)
+	return int(sb.total)
+}
+
+// Close 关闭临时文件句柄，播放结束后调用。
+func (sb *streamingBuffer) Close() {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.spill != nil {
+		sb.spill.Close()
+		sb.spill = nil
+	}
+	sb.spilling = false
 }
 
 // Read 实现 io.Reader。读到缓冲末尾时，如果下载未完成则阻塞等待。
@@ -547,10 +692,31 @@ func (sb *streamingBuffer) Read(p []byte) (int, error) {
 	defer sb.mu.Unlock()
 
 	for {
-		// 有数据可读
-		if sb.pos < len(sb.data) {
-			n := copy(p, sb.data[sb.pos:])
-			sb.pos += n
+		// 内存窗口中有数据
+		if off := sb.pos - sb.base; off >= 0 && off < int64(len(sb.data)) {
+			n := copy(p, sb.data[off:])
+			sb.pos += int64(n)
+			return n, nil
+		}
+
+		// 已下载但不在内存窗口中：从临时文件读取
+		if sb.pos < sb.total {
+			if sb.spill == nil {
+				return 0, os.ErrClosed
+			}
+			if sb.spilling {
+				// 把窗口移到当前位置
+				if err := sb.loadLocked(); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			// 已停止写临时文件，内存中保存着之后的全部数据，这里直接读文件
+			n, err := sb.spill.ReadAt(p[:min(int64(len(p)), sb.fileEnd-sb.pos)], sb.pos)
+			sb.pos += int64(n)
+			if err != nil && err != io.EOF {
+				return n, fmt.Errorf("读取临时文件失败: %w", err)
+			}
 			return n, nil
 		}
 
@@ -567,6 +733,22 @@ func (sb *streamingBuffer) Read(p []byte) (int, error) {
 	}
 }
 
+// loadLocked 从临时文件载入以 pos 开始的窗口，复用窗口的内存。
+func (sb *streamingBuffer) loadLocked() error {
+	n := min(sb.fileEnd-sb.pos, streamWindow)
+	if int64(cap(sb.data)) < n {
+		sb.data = make([]byte, n)
+	}
+	sb.data = sb.data[:n]
+	if _, err := sb.spill.ReadAt(sb.data, sb.pos); err != nil {
+		sb.data = sb.data[:0]
+		sb.base = sb.total
+		return fmt.Errorf("读取临时文件失败: %w", err)
+	}
+	sb.base = sb.pos
+	return nil
+}
+
 // Seek 实现 io.Seeker。支持 go-mp3 解码器初始化时的 seek 操作。
 func (sb *streamingBuffer) Seek(offset int64, whence int) (int64, error) {
 	sb.mu.Lock()
@@ -577,7 +759,7 @@ func (sb *streamingBuffer) Seek(offset int64, whence int) (int64, error) {
 	case io.SeekStart:
 		newPos = offset
 	case io.SeekCurrent:
-		newPos = int64(sb.pos) + offset
+		newPos = sb.pos + offset
 	case io.SeekEnd:
 		// go-mp3 在初始化时用 SeekEnd 探测文件长度。
 		// 如果下载还没完成，需要等待足够长或者返回当前已有长度。
@@ -585,18 +767,18 @@ func (sb *streamingBuffer) Seek(offset int64, whence int) (int64, error) {
 		// 所以返回当前长度即可。
 		if !sb.finished {
 			// 等到有足够数据（至少 16KB，足够 MP3 初始化）
-			for len(sb.data) < 16384 && !sb.finished {
+			for sb.total < 16384 && !sb.finished {
 				sb.cond.Wait()
 			}
 		}
-		newPos = int64(len(sb.data)) + offset
+		newPos = sb.total + offset
 	default:
 		return 0, fmt.Errorf("invalid whence: %d", whence)
 	}
 	if newPos < 0 {
 		return 0, fmt.Errorf("negative position")
 	}
-	sb.pos = int(newPos)
+	sb.pos = newPos
 	return newPos, nil
 }
 
@@ -720,7 +902,9 @@ preBufferFileLoop:
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
 	deviceConfig.Playback.Format = malgo.FormatS16
 	deviceConfig.Playback.Channels = sp.channels
+	sp.output.apply(&deviceConfig)
 	deviceConfig.SampleRate = uint32(sampleRate)
+	sp.meter.start(sampleRate)
 	deviceConfig.PeriodSizeInFrames = 4096
 	deviceConfig.Periods = 4
 
@@ -754,6 +938,8 @@ preBufferFileLoop:
 				pos = end
 				writePos += copied
 			}
+			sp.meter.feedPCM(outputSamples[:totalBytes])
+			sp.applyGain(outputSamples[:totalBytes])
 		},
 	}
 
@@ -938,7 +1124,9 @@ preBufferFileLoop:
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Playback)
 	deviceConfig.Playback.Format = malgo.FormatS16
 	deviceConfig.Playback.Channels = sp.channels
+	sp.output.apply(&deviceConfig)
 	deviceConfig.SampleRate = uint32(sampleRate)
+	sp.meter.start(sampleRate)
 	deviceConfig.PeriodSizeInFrames = 4096
 	deviceConfig.Periods = 4
 
@@ -972,6 +1160,8 @@ preBufferFileLoop:
 				pos = end
 				writePos += copied
 			}
+			sp.meter.feedPCM(outputSamples[:totalBytes])
+			sp.applyGain(outputSamples[:totalBytes])
 		},
 	}
 
@@ -998,25 +1188,34 @@ preBufferFileLoop:
 	}
 }
 
-// cacheFileWriter 用于将下载的音频数据异步写入缓存文件。
+// cacheFileWriter 用于将下载的音频数据写入缓存临时文件。
 type cacheFileWriter struct {
 	file *os.File
 	path string
 }
 
+// newCacheFileWriter 创建临时文件，tmpPath 为空时在系统临时目录中创建。
 func newCacheFileWriter(tmpPath string) (*cacheFileWriter, error) {
-	f, err := os.Create(tmpPath)
+	var f *os.File
+	var err error
+	if tmpPath == "" {
+		f, err = os.CreateTemp("", "pibuddy_stream_*.tmp")
+	} else {
+		f, err = os.Create(tmpPath)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &cacheFileWriter{file: f, path: tmpPath}, nil
+	return &cacheFileWriter{file: f, path: f.Name()}, nil
 }
 
 // Write 写入数据到临时文件。
-func (cw *cacheFileWriter) Write(data []byte) {
-	if cw.file != nil {
-		cw.file.Write(data)
+func (cw *cacheFileWriter) Write(data []byte) error {
+	if cw.file == nil {
+		return nil
 	}
+	_, err := cw.file.Write(data)
+	return err
 }
 
 // Commit 关闭临时文件并原子 rename 到最终路径。
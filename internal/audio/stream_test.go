@@ -1,6 +1,10 @@
 package audio
 
 import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -214,3 +218,139 @@ func bytesToFloat32(data []byte) []float32 {
 
 	return samples
 }
+
+func TestStreamPlayer_ApplyGain(t *testing.T) {
+	sp := &StreamPlayer{}
+	sp.SetGain(100)
+	pcm := Int16ToBytes([]int16{1000, -2000, 32767})
+	sp.applyGain(pcm)
+	if got := BytesToInt16(pcm); got[0] != 1000 || got[1] != -2000 {
+		t.Errorf("音量 100%% 时不应改变数据: %v", got)
+	}
+
+	sp.SetGain(30)
+	sp.applyGain(pcm)
+	if got := BytesToInt16(pcm); got[0] != 300 || got[1] != -600 || got[2] != 9830 {
+		t.Errorf("压低到 30%% 后 = %v", got)
+	}
+}
+
+// spillBuffer 创建写入临时文件的 streamingBuffer，返回写入函数（先写文件再 Append，与下载时一致）。
+func spillBuffer(t *testing.T) (*streamingBuffer, func([]byte) error) {
+	t.Helper()
+	cw, err := newCacheFileWriter(filepath.Join(t.TempDir(), "song.tmp"))
+	if err != nil {
+		t.Fatalf("创建临时文件失败: %v", err)
+	}
+	t.Cleanup(cw.Abort)
+	spill, err := os.Open(cw.path)
+	if err != nil {
+		t.Fatalf("打开临时文件失败: %v", err)
+	}
+	sb := newStreamingBuffer(spill)
+	t.Cleanup(sb.Close)
+	return sb, func(chunk []byte) error {
+		if err := cw.Write(chunk); err != nil {
+			return err
+		}
+		sb.Append(chunk)
+		return nil
+	}
+}
+
+func testSong(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7 % 251)
+	}
+	return data
+}
+
+func TestStreamingBuffer_BoundedWindow(t *testing.T) {
+	sb, write := spillBuffer(t)
+	song := testSong(3*streamWindow + 12345)
+	for off := 0; off < len(song); off += 32768 {
+		if err := write(song[off:min(off+32768, len(song))]); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+		if len(sb.data) > streamWindow {
+			t.Fatalf("下载时内存窗口 %d 字节，超过上限", len(sb.data))
+		}
+	}
+	sb.Finish(nil)
+
+	var got bytes.Buffer
+	buf := make([]byte, 4000)
+	for {
+		n, err := sb.Read(buf)
+		got.Write(buf[:n])
+		if len(sb.data) > streamWindow {
+			t.Fatalf("读取时内存窗口 %d 字节，超过上限", len(sb.data))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+	}
+	if !bytes.Equal(got.Bytes(), song) {
+		t.Fatal("读出的数据与写入的不一致")
+	}
+
+	// 回到开头（go-mp3 初始化时会这样做）
+	if _, err := sb.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	head := make([]byte, 100)
+	if _, err := io.ReadFull(sb, head); err != nil || !bytes.Equal(head, song[:100]) {
+		t.Fatalf("回到开头后读取错误: %v", err)
+	}
+}
+
+func TestStreamingBuffer_StopSpill(t *testing.T) {
+	sb, write := spillBuffer(t)
+	song := testSong(2*streamWindow + 500)
+	half := len(song) / 2
+	if err := write(song[:half]); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if _, err := io.ReadFull(sb, make([]byte, 1000)); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	// 写临时文件失败后，之后的数据保存在内存中
+	sb.StopSpill()
+	sb.Append(song[half:])
+	sb.Finish(nil)
+
+	rest, err := io.ReadAll(sb)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(rest, song[1000:]) {
+		t.Fatal("读出的数据与写入的不一致")
+	}
+	if _, err := sb.Seek(10, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	head := make([]byte, 100)
+	if _, err := io.ReadFull(sb, head); err != nil || !bytes.Equal(head, song[10:110]) {
+		t.Fatalf("读取已写入文件的部分错误: %v", err)
+	}
+}
+
+func TestStreamingBuffer_NoSpill(t *testing.T) {
+	sb := newStreamingBuffer(nil)
+	song := testSong(100000)
+	go func() {
+		for off := 0; off < len(song); off += 30000 {
+			sb.Append(song[off:min(off+30000, len(song))])
+		}
+		sb.Finish(nil)
+	}()
+	got, err := io.ReadAll(sb)
+	if err != nil || !bytes.Equal(got, song) {
+		t.Fatalf("ReadAll = %d 字节, err = %v", len(got), err)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -20,6 +21,136 @@ type Config struct {
 	Log            LogConfig      `yaml:"log"`
 	Dialog         DialogConfig     `yaml:"dialog"`
 	Voiceprint     VoiceprintConfig `yaml:"voiceprint"`
+	Admin          AdminConfig      `yaml:"admin"`
+	SoundEvents    SoundEventsConfig `yaml:"sound_events"`
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"` // 外部 API 限流与退避
+	Diag      DiagConfig      `yaml:"diag"`       // 诊断包
+	Guest     GuestConfig     `yaml:"guest"`      // 访客模式
+
+	Maintenance MaintenanceConfig `yaml:"maintenance"` // 空闲时后台维护
+	Presence    PresenceConfig    `yaml:"presence"`    // 家人在家检测
+	Privacy     PrivacyConfig     `yaml:"privacy"`     // 发给云端大模型前的脱敏
+}
+
+// PrivacyConfig 隐私过滤：用户说的话和工具结果发给云端大模型之前，按规则隐去手机号、门锁密码、家庭住址等，
+// 对话历史在本地保留原文。local_only_tools 中的工具结果不会发给云端，直接朗读给用户。本地模型不受影响。
+type PrivacyConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Builtin        []string      `yaml:"builtin"`          // 启用的内置规则 phone/id_card/email，默认全部
+	Rules          []PrivacyRule `yaml:"rules"`            // 自定义规则
+	LocalOnlyTools []string      `yaml:"local_only_tools"` // 结果不能离开设备的工具
+}
+
+// PrivacyRule 一条自定义脱敏规则，regex 和 text 二选一。
+type PrivacyRule struct {
+	Name    string `yaml:"name"`    // 规则名称，用于审计日志，如"门锁密码"
+	Regex   string `yaml:"regex"`   // 正则表达式，如 "密码[是为：:]?\\s*\\d{4,8}"
+	Text    string `yaml:"text"`    // 字面文本，如家庭住址"幸福小区3栋2单元"
+	Replace string `yaml:"replace"` // 替换成的内容，默认 "[规则名称]"
+}
+
+// PresenceConfig 家人在家检测：通过 Home Assistant 的 device_tracker/person 实体或 ping 家人手机判断谁在家。
+// 没人在家时不做主动播报（闹钟等到期提醒除外），第一个人回家时打招呼，大模型可以回答"妈妈回来了吗"。
+// members 为空时不启用。
+type PresenceConfig struct {
+	Members   []PresenceMember `yaml:"members"`
+	Interval  int              `yaml:"interval"`   // 轮询间隔（秒），默认 60
+	AwayAfter int              `yaml:"away_after"` // ping 不通多久（秒）才算离家，手机休眠时 Wi-Fi 会断开，默认 900
+	Greeting  string           `yaml:"greeting"`   // 没人在家时有人回来的问候语，%s 为名字，默认"%s，欢迎回家"，"-" 表示不问候
+}
+
+// PresenceMember 一位家庭成员，entity 和 host 二选一。
+type PresenceMember struct {
+	Name   string `yaml:"name"`   // 称呼，如"妈妈"
+	Entity string `yaml:"entity"` // HA 实体，如 person.mom、device_tracker.mom_phone，状态为 home 时在家
+	Host   string `yaml:"host"`   // 手机的 IP 或主机名，ping 得通时在家（手机需固定 IP）
+}
+
+// MaintenanceConfig 空闲时的后台维护：校验音乐缓存、预下载收藏歌曲、刷新 RSS 缓存、压缩数据库。
+// 只在设备空闲（没有对话、没有播放）时运行，一唤醒立即让出。
+type MaintenanceConfig struct {
+	Enabled           bool `yaml:"enabled"`
+	Interval          int  `yaml:"interval"`           // 运行间隔（分钟），默认 60
+	PrefetchFavorites int  `yaml:"prefetch_favorites"` // 每位用户预下载收藏中前几首未缓存的歌，默认 5，负数不预下载
+}
+
+// GuestConfig 访客（演示）模式：给客人展示时不写入家里的数据（播放历史、备忘、收藏、使用统计等），
+// 只开放查询类和播放类工具，每次对话结束后清空对话上下文。主人说"开启访客模式"或启动时开启。
+type GuestConfig struct {
+	Enabled      bool     `yaml:"enabled"`       // 启动时即进入访客模式
+	AllowedTools []string `yaml:"allowed_tools"` // 访客模式下可用的工具，为空时使用内置列表（天气、新闻、听歌、讲故事、学习等）
+}
+
+// DiagConfig 诊断包配置。诊断包（pibuddy diag bundle 或主人说"生成诊断包"）包含最近的日志、
+// 隐去密钥的配置、版本信息和数据库统计，报告问题时附上即可。
+type DiagConfig struct {
+	OutputDir   string `yaml:"output_dir"`   // 诊断包保存目录，默认 {DataDir}/diag
+	UploadURL   string `yaml:"upload_url"`   // 配置后生成的诊断包 POST 到该地址（Content-Type: application/zip）
+	UploadToken string `yaml:"upload_token"` // 上传时的 Bearer 令牌，可为空
+}
+
+// RateLimitConfig 外部 API 限流配置。大模型、音乐、天气、腾讯云等 HTTP 客户端按 host 共享请求预算，
+// 服务端返回 429/503 时带抖动地指数退避，避免重试时频繁请求非官方音乐 API 被封。
+type RateLimitConfig struct {
+	Default     RateBudget            `yaml:"default"`      // 每个 host 的默认预算
+	Music       RateBudget            `yaml:"music"`        // 音乐 API 的预算，应用到 tools.music 配置的 API 地址
+	Hosts       map[string]RateBudget `yaml:"hosts"`        // 按 host 单独配置，如 "api.deepseek.com"
+	MaxRetries  int                   `yaml:"max_retries"`  // 429/503 时最多重试几次，默认 2，负数表示不重试
+	BackoffBase float64               `yaml:"backoff_base"` // 第一次退避时长（秒），默认 1
+	BackoffMax  float64               `yaml:"backoff_max"`  // 退避时长上限（秒），默认 30
+}
+
+// RateBudget 单个 host 的请求预算。
+type RateBudget struct {
+	Rate  float64 `yaml:"rate"`  // 每秒平均请求数，负数表示不限流
+	Burst int     `yaml:"burst"` // 允许的突发请求数
+}
+
+// SoundEventsConfig 声音事件检测配置。
+// 空闲时用音频标注模型（sherpa-onnx audio tagging，AudioSet 527 类）分析麦克风声音，
+// 检测到宝宝哭声、玻璃破碎、烟雾报警器等事件时语音播报并可回调 Webhook。
+type SoundEventsConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	ModelPath  string  `yaml:"model_path"`   // 模型文件（zipformer 或 CED）
+	LabelsPath string  `yaml:"labels_path"`  // 类别标签文件 class_labels_indices.csv
+	NumThreads int     `yaml:"num_threads"`  // 默认 1
+	Interval   float64 `yaml:"interval"`     // 分析间隔（秒），默认 2
+	Window     float64 `yaml:"window"`       // 每次分析的音频长度（秒），默认 2
+	MinLevelDB float64 `yaml:"min_level_db"` // 音频电平低于该值（dBFS）时跳过分析，节省 CPU，默认 -50
+	Webhook    string  `yaml:"webhook"`      // 检测到事件时 POST JSON 的地址，为空则只播报
+	// Events 要检测的事件，为空时使用内置的宝宝哭声、玻璃破碎、烟雾报警
+	Events []SoundEventRule `yaml:"events"`
+}
+
+// SoundEventRule 一类声音事件。
+type SoundEventRule struct {
+	Name      string   `yaml:"name"`      // 事件名，如"宝宝哭声"
+	Labels    []string `yaml:"labels"`    // 匹配的 AudioSet 标签（包含即匹配，忽略大小写），如 "Baby cry"
+	Threshold float32  `yaml:"threshold"` // 置信度阈值，默认 0.3
+	Cooldown  int      `yaml:"cooldown"`  // 同一事件两次通知的最小间隔（秒），默认 300
+	Announce  string   `yaml:"announce"`  // 播报内容，为空时为"注意，检测到<事件名>"；设为 "-" 不播报
+}
+
+// AdminConfig 管理 API 配置。
+type AdminConfig struct {
+	Enabled  bool           `yaml:"enabled"`
+	Listen   string         `yaml:"listen"`   // 监听地址，默认 ":8090"
+	Token    string         `yaml:"token"`    // 访问令牌，为空则不鉴权
+	Announce AnnounceConfig `yaml:"announce"` // 远程播报接口 POST /api/announce
+}
+
+// AnnounceConfig 远程播报：门铃、NAS、3D 打印机等家里的其他服务通过管理 API 让 PiBuddy 播报通知。
+type AnnounceConfig struct {
+	QuietHours QuietHoursConfig `yaml:"quiet_hours"` // 免打扰时段，只播报 urgent 优先级的通知
+	MaxAge     int              `yaml:"max_age"`     // 排队超过多久（秒）还没播出就丢弃，默认 300
+}
+
+// WakeConfirmConfig 二次确认唤醒配置。
+type WakeConfirmConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	Window    int  `yaml:"window"`     // 提示音后等待说话的时间（毫秒），默认 1500
+	MusicOnly bool `yaml:"music_only"` // 只在播放音乐时二次确认，空闲时照常唤醒
 }
 
 // DialogConfig 对话配置。
@@ -33,10 +164,38 @@ type DialogConfig struct {
 	// 为空则不播放回复语，直接进入监听状态。
 	WakeReply string `yaml:"wake_reply"`
 
+	// WakeReplies 唤醒回复语的变体，每次随机选一条，为空时使用 wake_reply。
+	// 含 {name} 的变体只在最近识别到说话人时使用（替换为昵称或用户名），此时优先使用。
+	// wake_reply 为空时都不播放；用语音修改唤醒回复语后只使用新的回复语。
+	WakeReplies []string `yaml:"wake_replies"`
+
+	// TimedWakeReplies 按时段的唤醒回复语（如早上说"早上好"），当前时间落在某个时段内时代替 wake_replies。
+	TimedWakeReplies []TimedWakeReply `yaml:"timed_wake_replies"`
+
+	// EnglishWakeReply 上一位说话人偏好英语（声纹用户偏好 language: en）时的唤醒回复语，默认 "I'm here"。
+	// wake_reply 为空时同样不播放。
+	EnglishWakeReply string `yaml:"english_wake_reply"`
+
 	// InterruptReply 播放被打断时的回复语。
 	// 在播放中检测到唤醒词打断时播放，为空则不播放直接进入监听。
 	InterruptReply string `yaml:"interrupt_reply"`
 
+	// FastInterrupt 快速打断：打断时用短提示音代替打断回复语，
+	// "下一首"、"大声点"、"暂停"等即时指令不经过大模型直接执行，执行后只响提示音。
+	FastInterrupt bool `yaml:"fast_interrupt"`
+
+	// WakeConfirm 嘈杂环境下的二次确认唤醒：检测到唤醒词后只响一声很短的提示音，
+	// 提示音后一段时间内听到说话才真正唤醒，否则悄悄回到原来的状态，误唤醒不再打断音乐。
+	WakeConfirm WakeConfirmConfig `yaml:"wake_confirm"`
+
+	// BufferReply 等大模型生成完整回复后再朗读。
+	// 默认关闭：第一句话生成完就开始朗读，其余部分边生成边朗读，缩短等待时间。
+	BufferReply bool `yaml:"buffer_reply"`
+
+	// PrefetchTools 允许预取的工具，如 ["get_weather"]。问题明显需要这些工具时（如含"天气"），
+	// 在等待大模型的同时先调用工具，大模型发起相同调用时直接使用结果。为空则不预取。
+	PrefetchTools []string `yaml:"prefetch_tools"`
+
 	// ToolReply 工具调用时的等待提示语。
 	// 在执行工具（如查天气、播放音乐）前播放，为空则不播放。
 	ToolReply string `yaml:"tool_reply"`
@@ -44,6 +203,28 @@ type DialogConfig struct {
 	// ListenDelay 播放回复语后延迟进入监听的时间（毫秒）。
 	// 给用户一点反应时间再开始监听，默认 500ms。
 	ListenDelay int `yaml:"listen_delay"`
+
+	// DictationTimeout 听写模式下的静默超时（秒）。
+	// 听写时停顿超过此时间自动结束并保存记录，默认 120 秒。
+	DictationTimeout int `yaml:"dictation_timeout"`
+
+	// OpenMicMinutes 连续聊天模式（免唤醒词）默认持续时间（分钟），默认 10 分钟。
+	// OpenMicMaxMinutes 最长可开启的时间（分钟），默认 60 分钟。
+	OpenMicMinutes    int `yaml:"open_mic_minutes"`
+	OpenMicMaxMinutes int `yaml:"open_mic_max_minutes"`
+
+	// MaxReplySeconds 一次回复最长朗读多少秒（按字数估算），0 表示不限制。
+	// 超出时：等完整回复再朗读（buffer_reply）时让大模型压缩成简短版本；
+	// 边生成边朗读时读到上限后停下，问用户"需要我详细说吗？"。讲故事等直接朗读的内容不受限制。
+	MaxReplySeconds int `yaml:"max_reply_seconds"`
+
+	// ProfileLatency 记录每次对话各阶段的耗时（唤醒→识别结束、识别→大模型首字、工具、语音合成、开始播放），
+	// 写入日志并可在管理 API /api/diagnostics/latency 查看，用于发现版本间的延迟变化。
+	ProfileLatency bool `yaml:"profile_latency"`
+
+	// AnnounceGap 后台播报（闹钟、倒计时、健康提醒、整点报时等）排队依次播放，两条之间至少间隔多少毫秒，
+	// 默认 1500，负数表示不间隔。
+	AnnounceGap int `yaml:"announce_gap"`
 }
 
 // VoiceprintConfig 声纹识别配置。
@@ -54,6 +235,17 @@ type VoiceprintConfig struct {
 	NumThreads int     `yaml:"num_threads"`
 	BufferSecs float32 `yaml:"buffer_secs"`
 	OwnerName  string  `yaml:"owner_name"` // 主人姓名
+
+	Challenge ChallengeConfig `yaml:"challenge"` // 高风险操作执行前复核声纹
+}
+
+// ChallengeConfig 高风险操作的声纹复核：执行前让主人再说一句话，按更严格的阈值重新验证，
+// 而不是只信任唤醒时的识别结果（可能是别人唤醒后录音回放，或唤醒后换了人说话）。
+type ChallengeConfig struct {
+	Tools     []string `yaml:"tools"`     // 需要复核的工具（如 ezviz_open_door），为空不复核
+	Threshold float32  `yaml:"threshold"` // 复核阈值，默认比识别阈值高 0.15
+	Seconds   float64  `yaml:"seconds"`   // 录音时长（秒），默认 2
+	Prompt    string   `yaml:"prompt"`    // 录音前的提示语
 }
 
 // AudioConfig 音频采集/播放配置。
@@ -62,13 +254,32 @@ type AudioConfig struct {
 	Channels   int     `yaml:"channels"`
 	FrameSize  int     `yaml:"frame_size"`
 	MicGain    float32 `yaml:"mic_gain"` // 麦克风软件增益倍数，默认 1.0
+	PreRoll    int     `yaml:"pre_roll"` // 唤醒后补给 ASR 的唤醒前音频（毫秒），默认 500，负数禁用
+
+	// Outputs 按内容选择播放设备，为空使用系统默认设备
+	Outputs AudioOutputsConfig `yaml:"outputs"`
+}
+
+// AudioOutputsConfig 按内容类型选择播放设备，设备名按部分匹配（不区分大小写）。
+type AudioOutputsConfig struct {
+	TTS   string `yaml:"tts"`   // 语音回复、提示音
+	Music string `yaml:"music"` // 音乐、电台等流媒体
 }
 
 // WakeConfig 唤醒词检测配置。
 type WakeConfig struct {
-	ModelPath    string  `yaml:"model_path"`
-	KeywordsFile string  `yaml:"keywords_file"`
-	Threshold    float32 `yaml:"threshold"`
+	ModelPath    string          `yaml:"model_path"`
+	KeywordsFile string          `yaml:"keywords_file"`
+	Threshold    float32         `yaml:"threshold"`
+	NearField    NearFieldConfig `yaml:"near_field"`
+}
+
+// NearFieldConfig 近场唤醒门控配置。
+// 根据唤醒词音频的电平、与背景的对比度和高低频能量比估计说话人距离，忽略电视等远处声源引起的误唤醒。
+type NearFieldConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	Threshold  float64 `yaml:"threshold"`    // 综合得分阈值（0-1，越高越严格），默认 0.5
+	MinLevelDB float64 `yaml:"min_level_db"` // 唤醒词语音最低电平（dBFS），默认 -40
 }
 
 // VADConfig 语音活动检测配置。
@@ -99,10 +310,26 @@ type ASRConfig struct {
 	Rule2MinTrailingSilence float64 `yaml:"rule2_min_trailing_silence"` // 尾部静音阈值（秒）
 	Rule3MinUtteranceLength float64 `yaml:"rule3_min_utterance_length"` // 最小语音长度（秒）
 
+	// DailyBudget 每日云端识别秒数上限，用完后当天剩余时间只用 sherpa，0 表示不限制
+	DailyBudget int `yaml:"daily_budget"`
+
+	// AnnounceFallback 云端识别不可用、自动切换到离线识别时，下一次对话先语音提示一次
+	AnnounceFallback bool `yaml:"announce_fallback"`
+
+	// Hotwords 根据收藏、缓存的歌曲、家人名字、智能家居设备名和备忘自动生成热词
+	Hotwords ASRHotwordsConfig `yaml:"hotwords"`
+
 	// 腾讯云配置（可复用 TTS 的密钥）
 	Tencent ASRTencentConfig `yaml:"tencent"`
 }
 
+// ASRHotwordsConfig 动态热词配置。数据有变化时自动更新 sherpa 热词和腾讯云临时热词表。
+type ASRHotwordsConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Extra    []string `yaml:"extra"`    // 额外的固定热词，优先于自动生成的热词
+	Interval int      `yaml:"interval"` // 检查数据变化的间隔（秒），默认 300
+}
+
 // ASRTencentConfig 腾讯云 ASR 配置。
 type ASRTencentConfig struct {
 	SecretID  string `yaml:"secret_id"`
@@ -133,17 +360,55 @@ type LLMConfig struct {
 	SystemPrompt string `yaml:"system_prompt"`
 	MaxHistory   int    `yaml:"max_history"`
 	MaxTokens    int    `yaml:"max_tokens"`
+
+	// Local 本地小模型（llama.cpp server 等 OpenAI 兼容接口），所有云端模型都不可用时用于闲聊。
+	// 报时、切歌、调音量等不需要大模型，降级时在本地直接处理。
+	Local LLMModelConfig `yaml:"local"`
+
+	// Personas 可用语音切换的人设（"切换到助教模式"），每个人设有自己的 system prompt、发音人和回复详略。
+	Personas []PersonaConfig `yaml:"personas"`
+}
+
+// PersonaConfig 一个人设：替换默认的 system prompt，可同时更换发音人和回复详略。
+type PersonaConfig struct {
+	Name         string   `yaml:"name"`          // 人设名称，如"助教模式"
+	Aliases      []string `yaml:"aliases"`       // 其他叫法，如["助教", "老师"]
+	SystemPrompt string   `yaml:"system_prompt"` // 该人设的 system prompt，为空时沿用 llm.system_prompt
+	Voice        string   `yaml:"voice"`         // 发音人（Edge 为发音人名称，腾讯云为音色编号），为空不换
+	Verbosity    string   `yaml:"verbosity"`     // 回复详略 brief/normal/detailed，为空沿用当前设置
 }
 
 // TTSConfig 语音合成配置。
 type TTSConfig struct {
-	Engine   string        `yaml:"engine"`
-	Fallback string        `yaml:"fallback"` // 回退引擎，当主引擎失败时使用（如 "piper"、"say"）
-	Edge     EdgeConfig    `yaml:"edge"`
-	Piper    PiperConfig   `yaml:"piper"`
-	Say      SayConfig     `yaml:"say"`
-	Sherpa   SherpaConfig  `yaml:"sherpa"`
-	Tencent  TencentConfig `yaml:"tencent"`
+	Engine   string            `yaml:"engine"`
+	Fallback string            `yaml:"fallback"` // 回退引擎，当主引擎失败时使用（如 "piper"、"say"）
+	Edge     EdgeConfig        `yaml:"edge"`
+	Piper    PiperConfig       `yaml:"piper"`
+	Say      SayConfig         `yaml:"say"`
+	Sherpa   SherpaConfig      `yaml:"sherpa"`
+	Tencent  TencentConfig     `yaml:"tencent"`
+	Loudness TTSLoudnessConfig `yaml:"loudness"`
+
+	Selection TTSSelectionConfig `yaml:"selection"` // 按健康度自动选择引擎
+}
+
+// TTSSelectionConfig 按健康度自动选择 TTS 引擎：记录每个引擎最近的成功率和合成延迟，
+// 每次合成优先使用当前最健康的引擎，代替固定的 engine + fallback（如腾讯云某地域出问题时自动换用其他引擎）。
+type TTSSelectionConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Engines []string `yaml:"engines"` // 参与选择的引擎，按偏好排序，默认 [engine, fallback]
+	Pin     string   `yaml:"pin"`     // 固定使用的引擎，只在它失败时才换用其他引擎，为空自动选择
+}
+
+// TTSLoudnessConfig 语音播报响度匹配配置。
+// 不同 TTS 引擎输出的音量差别较大（如腾讯云明显比音乐轻），开启后把每段语音调整到统一响度，
+// 并可跟随最近播放的音乐响度，避免语音和音乐之间反复调音量。
+type TTSLoudnessConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	TargetLUFS    float64 `yaml:"target_lufs"`     // 目标响度（LUFS），默认 -16
+	MatchMusic    bool    `yaml:"match_music"`     // 是否跟随音乐响度（播放过音乐后生效）
+	MusicOffsetDB float64 `yaml:"music_offset_db"` // 相对音乐响度的偏移（dB），正数表示语音比音乐响
+	MaxGainDB     float64 `yaml:"max_gain_db"`     // 最大调整幅度（dB），默认 12
 }
 
 // TencentConfig 腾讯云 TTS 配置。
@@ -157,7 +422,8 @@ type TencentConfig struct {
 
 // EdgeConfig Edge TTS 配置。
 type EdgeConfig struct {
-	Voice string `yaml:"voice"`
+	Voice        string `yaml:"voice"`
+	EnglishVoice string `yaml:"english_voice"` // 偏好英语的声纹用户说话时使用的发音人，默认 en-US-AriaNeural
 }
 
 // PiperConfig Piper TTS 配置。
@@ -183,8 +449,10 @@ type SayConfig struct {
 // ToolsConfig 工具配置。
 type ToolsConfig struct {
 	DataDir       string              `yaml:"data_dir"`
+	Timeout       int                 `yaml:"timeout"` // 单个工具执行超时（秒），默认 30；被打断时工具会立即取消
 	Weather       WeatherConfig       `yaml:"weather"`
 	Music         MusicConfig         `yaml:"music"`
+	Radio         RadioConfig         `yaml:"radio"`
 	RSS           RSSConfig           `yaml:"rss"`
 	Timer         TimerConfig         `yaml:"timer"`
 	Volume        VolumeConfig        `yaml:"volume"`
@@ -194,13 +462,98 @@ type ToolsConfig struct {
 	Ezviz         EzvizConfig         `yaml:"ezviz"`
 	Learning      LearningConfig      `yaml:"learning"`
 	Story         StoryConfig         `yaml:"story"`
+	Usage         UsageConfig         `yaml:"usage"`
+	Chime         ChimeConfig         `yaml:"chime"`
+	Alarm         AlarmConfig         `yaml:"alarm"`
+	GuestWiFi     GuestWiFiConfig     `yaml:"guest_wifi"`
+	Celebration   CelebrationConfig   `yaml:"celebration"`
+	Study         StudyConfig         `yaml:"study"`
+	Groups        ToolGroupsConfig    `yaml:"groups"`
+	Mock          []string            `yaml:"mock"` // 模拟模式的工具（如 ezviz_open_door），不真正执行、直接返回成功，用于演示；环境变量 PIBUDDY_MOCK_TOOLS（逗号分隔）追加
+}
+
+// ToolGroupsConfig 按意图分组暴露工具：工具定义很长，每次都全部发给大模型会拖慢请求。
+// 开启后按用户说的话匹配分组关键词，只发送相关分组的工具；没有匹配的分组时仍发送全部工具。
+type ToolGroupsConfig struct {
+	Enabled bool              `yaml:"enabled"`
+	Always  []string          `yaml:"always"` // 始终发送的工具，默认 get_datetime、go_to_sleep、stop_music、set_volume
+	Custom  []ToolGroupConfig `yaml:"custom"` // 自定义分组，与内置分组同名时覆盖内置分组
+}
+
+// ToolGroupConfig 一个工具分组。
+type ToolGroupConfig struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords"` // 用户的话包含任一关键词时发送该组工具
+	Tools    []string `yaml:"tools"`
+}
+
+// StudyConfig 学习时间：家长为孩子（声纹用户）开启后，时间内孩子点歌、听故事、玩游戏会被温和地拒绝，
+// 查字典、学英语等学习工具照常可用，结束时响铃并表扬。
+type StudyConfig struct {
+	Minutes int    `yaml:"minutes"` // 未说明时长时的默认学习时间（分钟），默认 30
+	Praise  string `yaml:"praise"`  // 结束时的表扬，{name} 替换为昵称或用户名，默认"{name}，学习时间结束啦，你真棒！起来活动一下吧"
+}
+
+// CelebrationConfig 生日祝福：声纹用户偏好中设置了 birthday 时，当天第一次对话先送上祝福。
+// 用户可在偏好中设置 no_celebration 关闭。
+type CelebrationConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Greeting string `yaml:"greeting"` // 祝福语，{name} 替换为昵称或用户名，默认"{name}，祝你生日快乐！"
+	Track    string `yaml:"track"`    // 祝福前播放的本地 MP3（如生日歌），为空不播放
+}
+
+// GuestWiFiConfig 访客 Wi-Fi 信息，SSID 为空时不启用。
+// 语音询问时播报名称和密码；启用管理 API 时可打开 /wifi 页面扫码加入。
+type GuestWiFiConfig struct {
+	SSID     string `yaml:"ssid"`
+	Password string `yaml:"password"`
+	Security string `yaml:"security"` // WPA（包括 WPA2/WPA3）、WEP 或 nopass（开放网络），默认 WPA
+	Hidden   bool   `yaml:"hidden"`   // 是否为隐藏网络
+}
+
+// AlarmConfig 闹钟配置。
+type AlarmConfig struct {
+	Gentle    GentleWakeConfig `yaml:"gentle"`    // 渐进唤醒（设置闹钟时说"温柔地叫我"）
+	Challenge AlarmQuizConfig  `yaml:"challenge"` // 答题关闹钟（设置闹钟时说"答对题才能关"）
+}
+
+// AlarmQuizConfig 答题关闹钟：闹钟响起时出一道口算或常识题，答对才关闭，答错或没回答时过一会儿再响。
+type AlarmQuizConfig struct {
+	Kind       string `yaml:"kind"`        // 题目类型：math（口算）、trivia（常识）或 mixed，默认 math
+	Snooze     int    `yaml:"snooze"`      // 答错或没回答时多久后再响（秒），默认 300
+	MaxSnoozes int    `yaml:"max_snoozes"` // 最多再响几次，默认 6
+}
+
+// GentleWakeConfig 渐进唤醒：闹钟到点后灯光逐渐调亮、音乐逐渐变响，结束后再语音提醒。
+// 灯光和音乐都不可用时直接语音提醒。
+type GentleWakeConfig struct {
+	Minutes int    `yaml:"minutes"` // 渐亮、渐强的时长（分钟），默认 10
+	Light   string `yaml:"light"`   // Home Assistant 灯光实体，如 light.bedroom，为空不控制灯光
+	Music   string `yaml:"music"`   // 播放的歌曲或歌单关键词，为空时按 mood 选歌
+	Mood    string `yaml:"mood"`    // 按心情选歌，默认"轻松"
+}
+
+// ChimeConfig 整点报时配置。
+type ChimeConfig struct {
+	// Schedule 报时时间（cron 表达式，如 "0 8-21 * * *" 为每天 8 点到 21 点整点），为空则不报时。
+	Schedule   string           `yaml:"schedule"`
+	Style      string           `yaml:"style"`       // chime（钟声，几点敲几下）、speak（"现在是下午三点"）或 both，默认 speak
+	DuckVolume int              `yaml:"duck_volume"` // 正在听音乐时把音乐压低到原音量的百分比，默认 30
+	QuietHours QuietHoursConfig `yaml:"quiet_hours"` // 免打扰时段，不报时
+}
+
+// UsageConfig 使用统计配置。唤醒、提问、工具调用和听音乐时长始终按天记录。
+type UsageConfig struct {
+	// Recap 晚间语音小结的时间（cron 表达式，如 "0 21 * * *"），为空则不播报。
+	Recap string `yaml:"recap"`
 }
 
 // LearningConfig 学习工具配置。
 type LearningConfig struct {
-	Enabled bool            `yaml:"enabled"`
-	English EnglishConfig   `yaml:"english"`
-	Poetry  PoetryAPIConfig `yaml:"poetry"`
+	Enabled    bool             `yaml:"enabled"`
+	English    EnglishConfig    `yaml:"english"`
+	Poetry     PoetryAPIConfig  `yaml:"poetry"`
+	Dictionary DictionaryConfig `yaml:"dictionary"`
 }
 
 // StoryConfig 故事功能配置。
@@ -224,6 +577,13 @@ type EnglishConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
+// DictionaryConfig 查字典配置，数据文件需自行下载。
+type DictionaryConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	CEDICT  string `yaml:"cedict"` // CC-CEDICT 词典文件，默认 {DataDir}/dict/cedict_ts.u8
+	Xinhua  string `yaml:"xinhua"` // 新华字典 word.json，默认 {DataDir}/dict/word.json
+}
+
 // PoetryAPIConfig 古诗词配置。
 type PoetryAPIConfig struct {
 	Enabled bool   `yaml:"enabled"`
@@ -254,9 +614,31 @@ type QuietHoursConfig struct {
 
 // HomeAssistantConfig Home Assistant 配置。
 type HomeAssistantConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	URL     string `yaml:"url"`
-	Token   string `yaml:"token"`
+	Enabled bool         `yaml:"enabled"`
+	URL     string       `yaml:"url"`
+	Token   string       `yaml:"token"`
+	Sync    HASyncConfig `yaml:"sync"` // 闹钟、备忘录与 HA 日历、待办的双向同步
+
+	RainAlert RainAlertConfig `yaml:"rain_alert"` // 出门前下雨提醒
+}
+
+// RainAlertConfig 出门前提醒：早上门磁（HA 实体）打开时，如果未来一段时间内会下雨，主动提醒带伞。
+// 需要同时配置天气 API，door_entity 为空时不启用。
+type RainAlertConfig struct {
+	DoorEntity string `yaml:"door_entity"` // 门磁实体，如 binary_sensor.front_door，状态变为 on/open 时视为开门
+	City       string `yaml:"city"`        // 查询的城市，默认 tools.weather.default_city
+	Start      string `yaml:"start"`       // 提醒时段开始，默认 "06:00"
+	End        string `yaml:"end"`         // 提醒时段结束，默认 "10:00"
+	Within     int    `yaml:"within"`      // 多少分钟内会下雨才提醒，默认 120
+	Interval   int    `yaml:"interval"`    // 门磁状态轮询间隔（秒），默认 10
+	Message    string `yaml:"message"`     // 提醒语，默认"要下雨了，记得带伞"
+}
+
+// HASyncConfig 与 Home Assistant 日历、待办列表的同步配置，两个实体都为空时不同步。
+type HASyncConfig struct {
+	TodoEntity     string `yaml:"todo_entity"`     // 备忘录同步到的待办列表，如 todo.pibuddy
+	CalendarEntity string `yaml:"calendar_entity"` // 闹钟同步到的日历，如 calendar.family
+	Interval       int    `yaml:"interval"`        // 同步间隔（秒），默认 300
 }
 
 // TranslateConfig 翻译配置。
@@ -267,14 +649,20 @@ type TranslateConfig struct {
 	Region    string `yaml:"region"`
 }
 
-// TimerConfig 倒计时配置。
+// TimerConfig 倒计时配置。Repeat 等到期播报设置同时用于闹钟。
 type TimerConfig struct {
-	MaxConcurrent int `yaml:"max_concurrent"` // 最大同时运行的倒计时数，默认 5
+	MaxConcurrent  int `yaml:"max_concurrent"`  // 最大同时运行的倒计时数，默认 5
+	Repeat         int `yaml:"repeat"`          // 到期后最多播报次数（用户回应后停止），默认 5，1 表示只播报一次
+	RepeatInterval int `yaml:"repeat_interval"` // 重复播报间隔（秒），默认 30
+	VolumeStep     int `yaml:"volume_step"`     // 每次重复提高的音量，默认 10，负数表示不提高
+	MaxVolume      int `yaml:"max_volume"`      // 提高音量的上限，默认 90
 }
 
 // VolumeConfig 音量控制配置。
 type VolumeConfig struct {
-	Step int `yaml:"step"` // 相对调节步长，默认 10
+	Step           int `yaml:"step"`            // 相对调节步长，默认 10
+	AnnounceVolume int `yaml:"announce_volume"` // 整点报时、通知等后台播报的临时音量，播完恢复，0 表示不调整
+	AlarmVolume    int `yaml:"alarm_volume"`    // 闹钟、倒计时到期提醒的临时音量，重复播报时在此基础上逐渐提高，0 表示不调整
 }
 
 // RSSConfig RSS 订阅功能配置。
@@ -291,11 +679,47 @@ type MusicConfig struct {
 	CacheDir     string `yaml:"cache_dir"`       // 缓存目录，默认 {DataDir}/music_cache
 	CacheMaxSize int64  `yaml:"cache_max_size"`  // 缓存最大大小（MB），默认 500，0 表示禁用缓存
 	Netease      struct {
-		APIURL string `yaml:"api_url"` // 网易云 API 地址
+		APIURL string            `yaml:"api_url"` // 网易云 API 地址
+		Server MusicServerConfig `yaml:"server"`  // API 服务托管配置
 	} `yaml:"netease"`
 	QQ struct {
-		APIURL string `yaml:"api_url"` // QQ 音乐 API 地址
+		APIURL string            `yaml:"api_url"` // QQ 音乐 API 地址
+		Direct bool              `yaml:"direct"`  // 使用内置客户端直连 QQ 音乐网页接口，无需部署 QQMusicApi
+		Server MusicServerConfig `yaml:"server"`  // API 服务托管配置
 	} `yaml:"qq"`
+	HealthInterval int            `yaml:"health_interval"` // API 服务健康检查间隔（秒），默认 120
+	SleepAid       SleepAidConfig `yaml:"sleep_aid"`       // 睡前模式（听着音乐入睡）
+}
+
+// RadioConfig 网络电台：配置好的 MP3 直播流，说"放新闻台"即可收听，不依赖音乐服务。
+// 支持 ICY 元数据的电台可以回答"现在在放什么"。
+type RadioConfig struct {
+	Stations []RadioStationConfig `yaml:"stations"`
+}
+
+// RadioStationConfig 一个电台。
+type RadioStationConfig struct {
+	Name    string   `yaml:"name"`
+	URL     string   `yaml:"url"`     // MP3 直播流地址（不支持 AAC、HLS）
+	Aliases []string `yaml:"aliases"` // 其他叫法，如 "新闻台"
+}
+
+// SleepAidConfig 睡前模式配置：音乐音量在设定时长内逐渐降低，结束后停止播放。
+// 期间唤醒词需通过更严格的近场判定，减少音乐本身引起的误唤醒。
+type SleepAidConfig struct {
+	Minutes       int     `yaml:"minutes"`        // 默认渐弱时长（分钟），默认 30
+	WakeThreshold float64 `yaml:"wake_threshold"` // 睡前模式下唤醒词的近场得分阈值（0-1），默认 0.65
+}
+
+// MusicServerConfig 音乐 API 服务托管配置。
+// 开启 managed 后，PiBuddy 会在服务不可用时自动启动（或重启）它。
+type MusicServerConfig struct {
+	Managed         bool     `yaml:"managed"`          // 是否由 PiBuddy 启动并守护 API 服务
+	Command         string   `yaml:"command"`          // 启动命令，如 "node app.js"
+	Dir             string   `yaml:"dir"`              // 工作目录
+	Env             []string `yaml:"env"`              // 额外环境变量，格式 KEY=VALUE
+	DockerContainer string   `yaml:"docker_container"` // Docker 容器名，设置后使用 docker start/restart 管理
+	StartTimeout    int      `yaml:"start_timeout"`    // 等待服务就绪的超时（秒），默认 30
 }
 
 // WeatherConfig 和风天气配置。
@@ -306,6 +730,20 @@ type WeatherConfig struct {
 	CredentialID   string `yaml:"credential_id"`
 	ProjectID      string `yaml:"project_id"`
 	PrivateKeyPath string `yaml:"private_key_path"`
+	// 缓存有效期（分钟），0 使用默认值（实时 10 分钟、预报 60 分钟），负数禁用缓存
+	NowCacheTTL      int `yaml:"now_cache_ttl"`
+	ForecastCacheTTL int `yaml:"forecast_cache_ttl"`
+	// CityAliases 自定义城市别称，如 老家: 襄阳（内置"帝都""魔都"等常见别称）
+	CityAliases map[string]string `yaml:"city_aliases"`
+	// DefaultCity 设备所在城市，用户说"这里""我家"且说话人偏好中没有所在城市时使用
+	DefaultCity string `yaml:"default_city"`
+}
+
+// TimedWakeReply 一个时段的唤醒回复语。
+type TimedWakeReply struct {
+	Start   string   `yaml:"start"` // 开始时间，如 "05:00"
+	End     string   `yaml:"end"`   // 结束时间，如 "10:00"，早于开始时间表示跨天
+	Replies []string `yaml:"replies"`
 }
 
 // LogConfig 日志配置。
@@ -341,6 +779,12 @@ func Load(path string) (*Config, error) {
 
 // setDefaults 为未设置的配置项填充默认值。
 func setDefaults(cfg *Config) {
+	// 演示时不改配置文件，用环境变量临时把工具切换为模拟模式
+	for _, name := range strings.Split(os.Getenv("PIBUDDY_MOCK_TOOLS"), ",") {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(cfg.Tools.Mock, name) {
+			cfg.Tools.Mock = append(cfg.Tools.Mock, name)
+		}
+	}
 	if cfg.Audio.SampleRate == 0 {
 		cfg.Audio.SampleRate = 16000
 	}
@@ -350,9 +794,18 @@ func setDefaults(cfg *Config) {
 	if cfg.Audio.FrameSize == 0 {
 		cfg.Audio.FrameSize = 512
 	}
+	if cfg.Audio.PreRoll == 0 {
+		cfg.Audio.PreRoll = 500
+	}
 	if cfg.Wake.Threshold == 0 {
 		cfg.Wake.Threshold = 0.5
 	}
+	if cfg.Wake.NearField.Threshold == 0 {
+		cfg.Wake.NearField.Threshold = 0.5
+	}
+	if cfg.Wake.NearField.MinLevelDB == 0 {
+		cfg.Wake.NearField.MinLevelDB = -40
+	}
 	if cfg.VAD.Threshold == 0 {
 		cfg.VAD.Threshold = 0.5
 	}
@@ -362,6 +815,9 @@ func setDefaults(cfg *Config) {
 	if cfg.ASR.NumThreads == 0 {
 		cfg.ASR.NumThreads = 2
 	}
+	if cfg.ASR.Hotwords.Interval <= 0 {
+		cfg.ASR.Hotwords.Interval = 300
+	}
 	// ASR 多引擎优先级默认值
 	if len(cfg.ASR.Priority) == 0 {
 		// 兼容旧配置：从 provider + fallback 构建优先级列表
@@ -411,9 +867,18 @@ func setDefaults(cfg *Config) {
 	if cfg.TTS.Engine == "" {
 		cfg.TTS.Engine = "tencent"
 	}
+	if cfg.TTS.Loudness.TargetLUFS == 0 {
+		cfg.TTS.Loudness.TargetLUFS = -16
+	}
+	if cfg.TTS.Loudness.MaxGainDB <= 0 {
+		cfg.TTS.Loudness.MaxGainDB = 12
+	}
 	if cfg.TTS.Edge.Voice == "" {
 		cfg.TTS.Edge.Voice = "zh-CN-XiaoxiaoNeural"
 	}
+	if cfg.TTS.Edge.EnglishVoice == "" {
+		cfg.TTS.Edge.EnglishVoice = "en-US-AriaNeural"
+	}
 	if cfg.Log.Level == "" {
 		cfg.Log.Level = "info"
 	}
@@ -429,9 +894,39 @@ func setDefaults(cfg *Config) {
 	if cfg.Dialog.ContinuousTimeout == 0 {
 		cfg.Dialog.ContinuousTimeout = 8 // 默认 8 秒
 	}
+	if cfg.Dialog.WakeConfirm.Window <= 0 {
+		cfg.Dialog.WakeConfirm.Window = 1500
+	}
 	if cfg.Dialog.ListenDelay == 0 {
 		cfg.Dialog.ListenDelay = 500 // 默认 500ms
 	}
+	if cfg.Dialog.AnnounceGap == 0 {
+		cfg.Dialog.AnnounceGap = 1500
+	}
+	if cfg.Dialog.EnglishWakeReply == "" {
+		cfg.Dialog.EnglishWakeReply = "I'm here"
+	}
+	if cfg.SoundEvents.NumThreads == 0 {
+		cfg.SoundEvents.NumThreads = 1
+	}
+	if cfg.SoundEvents.Interval == 0 {
+		cfg.SoundEvents.Interval = 2
+	}
+	if cfg.SoundEvents.Window == 0 {
+		cfg.SoundEvents.Window = 2
+	}
+	if cfg.SoundEvents.MinLevelDB == 0 {
+		cfg.SoundEvents.MinLevelDB = -50
+	}
+	if cfg.Dialog.DictationTimeout == 0 {
+		cfg.Dialog.DictationTimeout = 120 // 默认 120 秒
+	}
+	if cfg.Dialog.OpenMicMinutes == 0 {
+		cfg.Dialog.OpenMicMinutes = 10
+	}
+	if cfg.Dialog.OpenMicMaxMinutes == 0 {
+		cfg.Dialog.OpenMicMaxMinutes = 60
+	}
 
 	if cfg.Voiceprint.Threshold == 0 {
 		cfg.Voiceprint.Threshold = 0.6
@@ -442,7 +937,55 @@ func setDefaults(cfg *Config) {
 	if cfg.Voiceprint.BufferSecs == 0 {
 		cfg.Voiceprint.BufferSecs = 3.0
 	}
+	if ch := &cfg.Voiceprint.Challenge; len(ch.Tools) > 0 {
+		if ch.Threshold == 0 {
+			ch.Threshold = min(cfg.Voiceprint.Threshold+0.15, 0.95)
+		}
+		if ch.Seconds == 0 {
+			ch.Seconds = 2
+		}
+		if ch.Prompt == "" {
+			ch.Prompt = "这个操作需要验证身份，请在提示音后说一句话"
+		}
+	}
+
+	if sel := &cfg.TTS.Selection; sel.Enabled && len(sel.Engines) == 0 {
+		sel.Engines = []string{cfg.TTS.Engine}
+		if cfg.TTS.Fallback != "" && cfg.TTS.Fallback != cfg.TTS.Engine {
+			sel.Engines = append(sel.Engines, cfg.TTS.Fallback)
+		}
+	}
+
+	if cfg.Maintenance.Interval == 0 {
+		cfg.Maintenance.Interval = 60
+	}
+	if cfg.Maintenance.PrefetchFavorites == 0 {
+		cfg.Maintenance.PrefetchFavorites = 5
+	}
+	if cfg.Presence.Interval == 0 {
+		cfg.Presence.Interval = 60
+	}
+	if cfg.Presence.AwayAfter == 0 {
+		cfg.Presence.AwayAfter = 900
+	}
+	if cfg.Presence.Greeting == "" {
+		cfg.Presence.Greeting = "%s，欢迎回家"
+	}
+	if cfg.Privacy.Builtin == nil {
+		cfg.Privacy.Builtin = []string{"phone", "id_card", "email"}
+	}
+
+	if cfg.Admin.Listen == "" {
+		cfg.Admin.Listen = ":8090"
+	}
+	if cfg.Admin.Announce.MaxAge == 0 {
+		cfg.Admin.Announce.MaxAge = 300
+	}
+	cfg.Admin.Token = strings.TrimSpace(cfg.Admin.Token)
 
+	if cfg.Tools.Timeout == 0 {
+		cfg.Tools.Timeout = 30
+	}
 	if cfg.Tools.DataDir == "" {
 		home, _ := os.UserHomeDir()
 		if home != "" {
@@ -467,20 +1010,147 @@ func setDefaults(cfg *Config) {
 			cfg.Tools.Music.CacheDir = home + cfg.Tools.Music.CacheDir[1:]
 		}
 	}
+	// 诊断包目录默认值
+	if cfg.Diag.OutputDir == "" {
+		cfg.Diag.OutputDir = cfg.Tools.DataDir + "/diag"
+	}
+	// 字典数据默认值
+	dict := &cfg.Tools.Learning.Dictionary
+	if dict.CEDICT == "" {
+		dict.CEDICT = cfg.Tools.DataDir + "/dict/cedict_ts.u8"
+	} else if strings.HasPrefix(dict.CEDICT, "~/") {
+		home, _ := os.UserHomeDir()
+		if home != "" {
+			dict.CEDICT = home + dict.CEDICT[1:]
+		}
+	}
+	if dict.Xinhua == "" {
+		dict.Xinhua = cfg.Tools.DataDir + "/dict/word.json"
+	} else if strings.HasPrefix(dict.Xinhua, "~/") {
+		home, _ := os.UserHomeDir()
+		if home != "" {
+			dict.Xinhua = home + dict.Xinhua[1:]
+		}
+	}
 	if cfg.Tools.Music.CacheMaxSize == 0 {
 		cfg.Tools.Music.CacheMaxSize = 500 // 默认 500MB
 	}
+	if cfg.Tools.HomeAssistant.Sync.Interval == 0 {
+		cfg.Tools.HomeAssistant.Sync.Interval = 300 // 默认 5 分钟
+	}
+	if ra := &cfg.Tools.HomeAssistant.RainAlert; ra.DoorEntity != "" {
+		if ra.City == "" {
+			ra.City = cfg.Tools.Weather.DefaultCity
+		}
+		if ra.Start == "" {
+			ra.Start = "06:00"
+		}
+		if ra.End == "" {
+			ra.End = "10:00"
+		}
+		if ra.Within == 0 {
+			ra.Within = 120
+		}
+		if ra.Interval == 0 {
+			ra.Interval = 10
+		}
+		if ra.Message == "" {
+			ra.Message = "要下雨了，记得带伞"
+		}
+	}
+	if cfg.Tools.Music.HealthInterval == 0 {
+		cfg.Tools.Music.HealthInterval = 120 // 默认 2 分钟
+	}
+	if cfg.Tools.Music.SleepAid.Minutes == 0 {
+		cfg.Tools.Music.SleepAid.Minutes = 30
+	}
+	if cfg.Tools.Music.SleepAid.WakeThreshold == 0 {
+		cfg.Tools.Music.SleepAid.WakeThreshold = 0.65
+	}
 
 	// 倒计时默认值
 	if cfg.Tools.Timer.MaxConcurrent == 0 {
 		cfg.Tools.Timer.MaxConcurrent = 5
 	}
+	if cfg.Tools.Timer.Repeat == 0 {
+		cfg.Tools.Timer.Repeat = 5
+	}
+	if cfg.Tools.Timer.RepeatInterval == 0 {
+		cfg.Tools.Timer.RepeatInterval = 30
+	}
+	if cfg.Tools.Timer.VolumeStep == 0 {
+		cfg.Tools.Timer.VolumeStep = 10
+	}
+	if cfg.Tools.Timer.MaxVolume == 0 {
+		cfg.Tools.Timer.MaxVolume = 90
+	}
 
 	// 音量控制默认值
 	if cfg.Tools.Volume.Step == 0 {
 		cfg.Tools.Volume.Step = 10
 	}
 
+	// 限流默认值
+	if cfg.RateLimit.Default.Rate == 0 {
+		cfg.RateLimit.Default = RateBudget{Rate: 10, Burst: 20}
+	}
+	if cfg.RateLimit.Music.Rate == 0 {
+		cfg.RateLimit.Music = RateBudget{Rate: 2, Burst: 5}
+	}
+	if cfg.RateLimit.MaxRetries == 0 {
+		cfg.RateLimit.MaxRetries = 2
+	}
+	if cfg.RateLimit.BackoffBase == 0 {
+		cfg.RateLimit.BackoffBase = 1
+	}
+	if cfg.RateLimit.BackoffMax == 0 {
+		cfg.RateLimit.BackoffMax = 30
+	}
+
+	// 整点报时默认值
+	if cfg.Tools.Chime.Style == "" {
+		cfg.Tools.Chime.Style = "speak"
+	}
+	if cfg.Tools.Chime.DuckVolume == 0 {
+		cfg.Tools.Chime.DuckVolume = 30
+	}
+
+	// 学习时间默认值
+	if cfg.Tools.Study.Minutes == 0 {
+		cfg.Tools.Study.Minutes = 30
+	}
+	if cfg.Tools.Study.Praise == "" {
+		cfg.Tools.Study.Praise = "{name}，学习时间结束啦，你真棒！起来活动一下吧"
+	}
+
+	// 生日祝福默认值
+	if cfg.Tools.Celebration.Greeting == "" {
+		cfg.Tools.Celebration.Greeting = "{name}，祝你生日快乐！"
+	}
+	if strings.HasPrefix(cfg.Tools.Celebration.Track, "~/") {
+		home, _ := os.UserHomeDir()
+		if home != "" {
+			cfg.Tools.Celebration.Track = home + cfg.Tools.Celebration.Track[1:]
+		}
+	}
+
+	// 渐进唤醒默认值
+	if cfg.Tools.Alarm.Gentle.Minutes == 0 {
+		cfg.Tools.Alarm.Gentle.Minutes = 10
+	}
+	if cfg.Tools.Alarm.Gentle.Music == "" && cfg.Tools.Alarm.Gentle.Mood == "" {
+		cfg.Tools.Alarm.Gentle.Mood = "轻松"
+	}
+	if cfg.Tools.Alarm.Challenge.Kind == "" {
+		cfg.Tools.Alarm.Challenge.Kind = "math"
+	}
+	if cfg.Tools.Alarm.Challenge.Snooze == 0 {
+		cfg.Tools.Alarm.Challenge.Snooze = 300
+	}
+	if cfg.Tools.Alarm.Challenge.MaxSnoozes == 0 {
+		cfg.Tools.Alarm.Challenge.MaxSnoozes = 6
+	}
+
 	// 故事功能默认值
 	if cfg.Tools.Story.API.BaseURL == "" {
 		cfg.Tools.Story.API.BaseURL = "https://www.mxnzp.com"
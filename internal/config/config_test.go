@@ -177,3 +177,20 @@ func TestSetDefaults_TrimsAPIKey(t *testing.T) {
 		t.Errorf("expected trimmed API key, got %q", cfg.LLM.APIKey)
 	}
 }
+
+func TestSetDefaults_MockToolsFromEnv(t *testing.T) {
+	t.Setenv("PIBUDDY_MOCK_TOOLS", "ezviz_open_door, ha_control_device,")
+	cfg := &Config{}
+	cfg.Tools.Mock = []string{"ha_control_device"}
+	setDefaults(cfg)
+
+	want := []string{"ha_control_device", "ezviz_open_door"}
+	if len(cfg.Tools.Mock) != len(want) {
+		t.Fatalf("Tools.Mock = %v, want %v", cfg.Tools.Mock, want)
+	}
+	for i := range want {
+		if cfg.Tools.Mock[i] != want[i] {
+			t.Errorf("Tools.Mock = %v, want %v", cfg.Tools.Mock, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -96,6 +97,14 @@ func (db *DB) Migrate() error {
 			cached_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			last_played DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+		// 音乐心情/风格标签表（如"轻松"、"助眠"），一首歌可有多个标签
+		`CREATE TABLE IF NOT EXISTS music_tags (
+			cache_key TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			source TEXT DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(cache_key, tag)
+		)`,
 		// 音乐收藏表
 		`CREATE TABLE IF NOT EXISTS music_favorites (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -116,12 +125,34 @@ func (db *DB) Migrate() error {
 			count INTEGER DEFAULT 0,
 			UNIQUE(engine, date)
 		)`,
+		// 每日使用统计（唤醒、提问、工具调用次数、听音乐秒数等）
+		`CREATE TABLE IF NOT EXISTS usage_stats (
+			date TEXT NOT NULL,
+			metric TEXT NOT NULL,
+			count INTEGER DEFAULT 0,
+			PRIMARY KEY(date, metric)
+		)`,
 		// 系统配置表
 		`CREATE TABLE IF NOT EXISTS system_config (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+		// 设备级设置表（音量、播放模式等跨重启保留的状态）
+		`CREATE TABLE IF NOT EXISTS device_settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// 城市查询缓存（天气 Geo API 结果）
+		`CREATE TABLE IF NOT EXISTS city_cache (
+			query TEXT PRIMARY KEY,
+			location_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			latitude TEXT DEFAULT '',
+			longitude TEXT DEFAULT '',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
 		// 故事表
 		`CREATE TABLE IF NOT EXISTS stories (
 			id TEXT PRIMARY KEY,
@@ -136,6 +167,17 @@ func (db *DB) Migrate() error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+		// 连载故事进度（每个孩子一份，记录人物和上一章梗概）
+		`CREATE TABLE IF NOT EXISTS story_series (
+			user TEXT NOT NULL,
+			title TEXT NOT NULL,
+			characters TEXT DEFAULT '',
+			summary TEXT DEFAULT '',
+			chapter INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(user, title)
+		)`,
 	}
 
 	for _, m := range migrations {
@@ -150,6 +192,7 @@ func (db *DB) Migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_music_cache_artist ON music_cache(artist)`,
 		`CREATE INDEX IF NOT EXISTS idx_music_cache_last_played ON music_cache(last_played)`,
 		`CREATE INDEX IF NOT EXISTS idx_music_favorites_name ON music_favorites(name)`,
+		`CREATE INDEX IF NOT EXISTS idx_music_tags_tag ON music_tags(tag)`,
 	}
 
 	for _, idx := range indexes {
@@ -207,6 +250,14 @@ func (db *DB) InitStories(sqlPath string) error {
 	return nil
 }
 
+// Vacuum 压缩数据库文件，回收删除数据后留下的空间。会锁住整个数据库，应在空闲时调用。
+func (db *DB) Vacuum(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("压缩数据库失败: %w", err)
+	}
+	return nil
+}
+
 // Close 关闭数据库连接。
 func (db *DB) Close() error {
 	if db.DB != nil {
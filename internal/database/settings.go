@@ -0,0 +1,150 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 设备级设置的键名。
+const (
+	SettingVolume     = "volume"      // 音量 0-100
+	SettingPlayMode   = "play_mode"   // 音乐播放模式 sequence/loop/single
+	SettingVerbosity  = "verbosity"   // 回复详略 brief/normal/detailed
+	SettingSpeechRate = "speech_rate" // 语速倍率，1.0 为配置的默认语速
+	SettingLLMModel   = "llm_model"   // 上次使用的大模型名称
+	SettingPersona    = "persona"     // 当前人设名称，空为默认人设
+
+	// 通过语音修改的对话设置（manage_settings），覆盖配置文件中的值
+	SettingContinuousTimeout = "continuous_timeout" // 连续对话超时（秒）
+	SettingListenDelay       = "listen_delay"       // 回复后延迟进入监听的时间（毫秒）
+	SettingWakeReply         = "wake_reply"         // 唤醒回复语，空字符串表示不播放
+
+	// SettingBirthdayCelebrated 前缀，加上 ":用户名" 保存最近一次送上生日祝福的年份
+	SettingBirthdayCelebrated = "birthday_celebrated"
+
+	// SettingASRCloudUsage 当天云端语音识别用量，格式 "2006-01-02 秒数"
+	SettingASRCloudUsage = "asr_cloud_usage"
+)
+
+// Settings 设备级设置存储（device_settings 表），保存需要跨重启保留的运行状态。
+// 值统一以文本保存，由类型化的 Get/Set 方法负责转换。
+type Settings struct {
+	db *DB
+}
+
+// NewSettings 创建设置存储，数据库需已完成迁移。
+func NewSettings(db *DB) *Settings {
+	return &Settings{db: db}
+}
+
+// lookup 读取原始值，不存在时 ok 为 false。
+func (s *Settings) lookup(key string) (string, bool) {
+	if s == nil || s.db == nil {
+		return "", false
+	}
+	var value string
+	err := s.db.QueryRow("SELECT value FROM device_settings WHERE key = ?", key).Scan(&value)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("[database] 读取设置 %s 失败: %v", key, err)
+		}
+		return "", false
+	}
+	return value, true
+}
+
+// SetString 保存文本设置。
+func (s *Settings) SetString(key, value string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("设置存储未初始化")
+	}
+	_, err := s.db.Exec(`INSERT INTO device_settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP`, key, value)
+	if err != nil {
+		return fmt.Errorf("保存设置 %s 失败: %w", key, err)
+	}
+	return nil
+}
+
+// GetString 读取文本设置，不存在时返回 def。
+func (s *Settings) GetString(key, def string) string {
+	if v, ok := s.lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// SetInt 保存整数设置。
+func (s *Settings) SetInt(key string, value int) error {
+	return s.SetString(key, strconv.Itoa(value))
+}
+
+// GetInt 读取整数设置，不存在或格式错误时返回 def。
+func (s *Settings) GetInt(key string, def int) int {
+	v, ok := s.lookup(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// SetFloat 保存浮点设置。
+func (s *Settings) SetFloat(key string, value float64) error {
+	return s.SetString(key, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// GetFloat 读取浮点设置，不存在或格式错误时返回 def。
+func (s *Settings) GetFloat(key string, def float64) float64 {
+	v, ok := s.lookup(key)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// SetBool 保存布尔设置。
+func (s *Settings) SetBool(key string, value bool) error {
+	return s.SetString(key, strconv.FormatBool(value))
+}
+
+// GetBool 读取布尔设置，不存在或格式错误时返回 def。
+func (s *Settings) GetBool(key string, def bool) bool {
+	v, ok := s.lookup(key)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// Has 判断设置是否已保存过。
+func (s *Settings) Has(key string) bool {
+	_, ok := s.lookup(key)
+	return ok
+}
+
+// Delete 删除设置，恢复为配置文件中的默认值。
+func (s *Settings) Delete(key string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("设置存储未初始化")
+	}
+	if _, err := s.db.Exec("DELETE FROM device_settings WHERE key = ?", key); err != nil {
+		return fmt.Errorf("删除设置 %s 失败: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return db
+}
+
+func TestSettings_TypedValues(t *testing.T) {
+	s := NewSettings(newTestDB(t))
+
+	if got := s.GetInt(SettingVolume, 50); got != 50 {
+		t.Errorf("未保存时应返回默认值，实际 %d", got)
+	}
+	if s.Has(SettingVolume) {
+		t.Error("未保存时 Has 应为 false")
+	}
+
+	if err := s.SetInt(SettingVolume, 35); err != nil {
+		t.Fatalf("SetInt 失败: %v", err)
+	}
+	if err := s.SetString(SettingPlayMode, "loop"); err != nil {
+		t.Fatalf("SetString 失败: %v", err)
+	}
+	if err := s.SetFloat(SettingSpeechRate, 1.2); err != nil {
+		t.Fatalf("SetFloat 失败: %v", err)
+	}
+	if err := s.SetBool("test_flag", true); err != nil {
+		t.Fatalf("SetBool 失败: %v", err)
+	}
+
+	if got := s.GetInt(SettingVolume, 50); got != 35 {
+		t.Errorf("GetInt = %d, want 35", got)
+	}
+	if got := s.GetString(SettingPlayMode, "sequence"); got != "loop" {
+		t.Errorf("GetString = %s, want loop", got)
+	}
+	if got := s.GetFloat(SettingSpeechRate, 1); got != 1.2 {
+		t.Errorf("GetFloat = %v, want 1.2", got)
+	}
+	if !s.GetBool("test_flag", false) {
+		t.Error("GetBool 应为 true")
+	}
+
+	// 覆盖写入
+	if err := s.SetInt(SettingVolume, 80); err != nil {
+		t.Fatalf("SetInt 失败: %v", err)
+	}
+	if got := s.GetInt(SettingVolume, 50); got != 80 {
+		t.Errorf("覆盖后 GetInt = %d, want 80", got)
+	}
+
+	// 类型不匹配时返回默认值
+	if got := s.GetInt(SettingPlayMode, 7); got != 7 {
+		t.Errorf("格式错误时应返回默认值，实际 %d", got)
+	}
+
+	if err := s.Delete(SettingVolume); err != nil {
+		t.Fatalf("Delete 失败: %v", err)
+	}
+	if s.Has(SettingVolume) {
+		t.Error("删除后 Has 应为 false")
+	}
+}
+
+func TestSettings_Persisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if err := NewSettings(db).SetString(SettingVerbosity, "brief"); err != nil {
+		t.Fatalf("SetString 失败: %v", err)
+	}
+	db.Close()
+
+	// 重新打开（模拟重启）
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("重新打开数据库失败: %v", err)
+	}
+	defer db.Close()
+	if got := NewSettings(db).GetString(SettingVerbosity, "normal"); got != "brief" {
+		t.Errorf("重启后 GetString = %s, want brief", got)
+	}
+}
+
+func TestSettings_Nil(t *testing.T) {
+	var s *Settings
+	if got := s.GetInt(SettingVolume, 42); got != 42 {
+		t.Errorf("nil 存储应返回默认值，实际 %d", got)
+	}
+	if err := s.SetInt(SettingVolume, 1); err == nil {
+		t.Error("nil 存储写入应报错")
+	}
+}
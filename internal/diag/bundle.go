@@ -0,0 +1,325 @@
+// Package diag 生成诊断包：最近的日志、隐去密钥的配置、版本信息和数据库统计打包成 zip，
+// 报告问题时附上，也可以上传到配置的地址。
+package diag
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+const (
+	maxLogFiles = 3       // 最多打包几个日志文件（当前日志和最近的轮转日志）
+	maxLogBytes = 5 << 20 // 每个日志文件最多保留末尾 5MB
+)
+
+// UploadTimeout 上传诊断包的超时时间。
+const UploadTimeout = 2 * time.Minute
+
+// redacted 替换密钥的占位符。
+const redacted = "******"
+
+// Bundle 生成诊断包，返回 zip 文件路径。db 为 nil 时不包含数据库统计。
+func Bundle(cfg *config.Config, db *database.DB) (string, error) {
+	if err := os.MkdirAll(cfg.Diag.OutputDir, 0755); err != nil {
+		return "", fmt.Errorf("创建诊断包目录失败: %w", err)
+	}
+	path := filepath.Join(cfg.Diag.OutputDir, "pibuddy-diag-"+time.Now().Format("20060102-150405")+".zip")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("创建诊断包失败: %w", err)
+	}
+
+	zw := zip.NewWriter(f)
+	werr := writeBundle(zw, cfg, db)
+	if err := zw.Close(); werr == nil {
+		werr = err
+	}
+	if err := f.Close(); werr == nil {
+		werr = err
+	}
+	if werr != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("生成诊断包失败: %w", werr)
+	}
+	return path, nil
+}
+
+func writeBundle(zw *zip.Writer, cfg *config.Config, db *database.DB) error {
+	if err := writeFile(zw, "version.txt", []byte(versionInfo())); err != nil {
+		return err
+	}
+
+	conf, err := redactedConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(zw, "config.yaml", conf); err != nil {
+		return err
+	}
+
+	if db != nil {
+		stats, err := json.MarshalIndent(dbStats(db), "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(zw, "db_stats.json", stats); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range logFiles(cfg.Log.File) {
+		data, err := readTail(name, maxLogBytes)
+		if err != nil {
+			continue
+		}
+		if err := writeFile(zw, "logs/"+filepath.Base(name), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// versionInfo 程序版本（构建时的 git 提交）、Go 版本和运行平台。
+func versionInfo() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "生成时间: %s\n", time.Now().Format(time.RFC3339))
+	if host, err := os.Hostname(); err == nil {
+		fmt.Fprintf(&b, "主机名: %s\n", host)
+	}
+	fmt.Fprintf(&b, "平台: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Go 版本: %s\n", runtime.Version())
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "模块: %s %s\n", info.Main.Path, info.Main.Version)
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision", "vcs.time", "vcs.modified":
+				fmt.Fprintf(&b, "%s: %s\n", s.Key, s.Value)
+			}
+		}
+	}
+	return b.String()
+}
+
+// redactedConfig 生效的配置（已展开环境变量、填充默认值），密钥、令牌、密码替换为占位符。
+func redactedConfig(cfg *config.Config) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("导出配置失败: %w", err)
+	}
+	redactNode(&node)
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("导出配置失败: %w", err)
+	}
+	return data, nil
+}
+
+// redactNode 递归替换敏感字段的值。
+func redactNode(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Value != "" && isSecretKey(key.Value) {
+				redactScalar(value)
+				continue
+			}
+			if key.Value == "privacy" {
+				redactPrivacyRules(value)
+			}
+			redactNode(value)
+		}
+		return
+	}
+	for _, c := range n.Content {
+		redactNode(c)
+	}
+}
+
+// privacyRuleFields 脱敏规则中包含住址、门锁密码等原文的字段。
+var privacyRuleFields = map[string]bool{"regex": true, "text": true, "replace": true}
+
+// redactPrivacyRules 隐去 privacy.rules 中的原文，只保留规则名称。
+func redactPrivacyRules(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Value != "" && privacyRuleFields[key.Value] {
+				redactScalar(value)
+				continue
+			}
+			redactPrivacyRules(value)
+		}
+		return
+	}
+	for _, c := range n.Content {
+		redactPrivacyRules(c)
+	}
+}
+
+func redactScalar(n *yaml.Node) {
+	n.Value = redacted
+	n.Tag = "!!str"
+	n.Style = 0
+}
+
+// isSecretKey 判断配置项是否是密钥（api_key、secret_key、token、password 等）。
+// 不能简单按包含 "key" 判断，否则 keywords_file 这类路径也会被隐去。
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "key", "token", "password", "secret", "secret_id", "cookie", "credential_id":
+		return true
+	}
+	for _, suffix := range []string{"_key", "_secret", "_token", "_password", "_cookie"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// TableStat 数据表行数。
+type TableStat struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// dbStats 数据库文件大小和各表行数。
+func dbStats(db *database.DB) map[string]interface{} {
+	stats := map[string]interface{}{"path": db.Path()}
+	if info, err := os.Stat(db.Path()); err == nil {
+		stats["size_bytes"] = info.Size()
+	}
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		stats["error"] = err.Error()
+		return stats
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+
+	var list []TableStat
+	for _, name := range tables {
+		var n int64
+		if err := db.QueryRow(`SELECT COUNT(*) FROM "` + strings.ReplaceAll(name, `"`, `""`) + `"`).Scan(&n); err != nil {
+			continue
+		}
+		list = append(list, TableStat{Table: name, Rows: n})
+	}
+	stats["tables"] = list
+	return stats
+}
+
+// logFiles 当前日志文件和同目录下最近的轮转日志（如 pibuddy-2026-01-02T15-04-05.000.log），新的在前。
+func logFiles(file string) []string {
+	if file == "" {
+		return nil
+	}
+	files := []string{file}
+	ext := filepath.Ext(file)
+	prefix := strings.TrimSuffix(filepath.Base(file), ext) + "-"
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if err != nil {
+		return files
+	}
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasPrefix(name, prefix) && (strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz")) {
+			backups = append(backups, filepath.Join(filepath.Dir(file), name))
+		}
+	}
+	// 轮转日志文件名中的时间戳按字典序即按时间排序
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	files = append(files, backups...)
+	if len(files) > maxLogFiles {
+		files = files[:maxLogFiles]
+	}
+	return files
+}
+
+// readTail 读取文件末尾最多 limit 字节。
+func readTail(name string, limit int64) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > limit && !strings.HasSuffix(name, ".gz") {
+		if _, err := f.Seek(info.Size()-limit, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(f)
+}
+
+// Upload 把诊断包 POST 到 cfg.UploadURL，返回服务端的响应内容。
+func Upload(ctx context.Context, cfg config.DiagConfig, path string) (string, error) {
+	if cfg.UploadURL == "" {
+		return "", fmt.Errorf("未配置诊断包上传地址（diag.upload_url）")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取诊断包失败: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, UploadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("创建上传请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filepath.Base(path)))
+	if cfg.UploadToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.UploadToken)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("上传诊断包失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("上传诊断包失败: HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return strings.TrimSpace(string(body)), nil
+}
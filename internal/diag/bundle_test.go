@@ -0,0 +1,131 @@
+package diag
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	for _, key := range []string{"api_key", "secret_key", "secret_id", "app_secret", "token", "upload_token", "password"} {
+		if !isSecretKey(key) {
+			t.Errorf("%s 应被隐去", key)
+		}
+	}
+	for _, key := range []string{"keywords_file", "max_tokens", "tokens_path", "model", "private_key_path"} {
+		if isSecretKey(key) {
+			t.Errorf("%s 不应被隐去", key)
+		}
+	}
+}
+
+func TestRedactedConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.APIKey = "sk-secret-value"
+	cfg.LLM.Model = "deepseek-chat"
+	cfg.Admin.Token = "admin-token-value"
+	data, err := redactedConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if strings.Contains(out, "sk-secret-value") || strings.Contains(out, "admin-token-value") {
+		t.Errorf("密钥未隐去:\n%s", out)
+	}
+	if !strings.Contains(out, "deepseek-chat") || !strings.Contains(out, redacted) {
+		t.Errorf("普通配置应保留:\n%s", out)
+	}
+}
+
+func TestRedactedConfigPrivacyRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Privacy.Rules = []config.PrivacyRule{
+		{Name: "家庭住址", Text: "幸福小区3栋2单元"},
+		{Name: "门锁密码", Regex: `门锁密码[是为：:]?\s*\d{4,8}`, Replace: "门锁密码123456"},
+	}
+	data, err := redactedConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, secret := range []string{"幸福小区", "门锁密码[", "123456"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("脱敏规则原文 %q 未隐去:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "家庭住址") {
+		t.Errorf("规则名称应保留:\n%s", out)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "pibuddy.log")
+	os.WriteFile(logFile, []byte("current log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pibuddy-2026-01-01T00-00-00.000.log"), []byte("old log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "other.log"), []byte("unrelated\n"), 0644)
+
+	cfg := &config.Config{}
+	cfg.Log.File = logFile
+	cfg.Diag.OutputDir = filepath.Join(dir, "diag")
+	path, err := Bundle(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{"version.txt", "config.yaml", "logs/pibuddy.log", "logs/pibuddy-2026-01-01T00-00-00.000.log"} {
+		if !names[want] {
+			t.Errorf("诊断包缺少 %s: %v", want, names)
+		}
+	}
+	if names["logs/other.log"] {
+		t.Error("不应包含无关的日志文件")
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "a.log")
+	os.WriteFile(name, []byte("0123456789"), 0644)
+	data, err := readTail(name, 4)
+	if err != nil || string(data) != "6789" {
+		t.Errorf("readTail = %q, %v", data, err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var gotAuth, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Write([]byte("ticket-42"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "diag.zip")
+	os.WriteFile(path, []byte("zipdata"), 0644)
+	resp, err := Upload(context.Background(), config.DiagConfig{UploadURL: srv.URL, UploadToken: "tok"}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "ticket-42" || gotAuth != "Bearer tok" || gotBody != "zipdata" {
+		t.Errorf("resp=%q auth=%q body=%q", resp, gotAuth, gotBody)
+	}
+}
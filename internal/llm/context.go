@@ -1,7 +1,10 @@
 package llm
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/logger"
@@ -13,6 +16,24 @@ type UserPreferences interface {
 	IsOwner() bool
 }
 
+// 回复详略程度，作为设备设置持久化。
+const (
+	VerbosityBrief    = "brief"
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+)
+
+// verbosityPrompts 各详略程度追加到 system prompt 的指令，normal 不追加。
+var verbosityPrompts = map[string]string{
+	VerbosityBrief:    "\n回复要求: 尽量简短，一两句话说清楚，不要展开。",
+	VerbosityDetailed: "\n回复要求: 可以说得详细一些，给出完整的解释和必要的细节。",
+}
+
+// ValidVerbosity 判断详略程度是否有效。
+func ValidVerbosity(v string) bool {
+	return v == VerbosityBrief || v == VerbosityNormal || v == VerbosityDetailed
+}
+
 // ContextManager 使用滑动窗口维护对话历史，
 // 在保持近期上下文的同时限制内存使用。
 type ContextManager struct {
@@ -21,6 +42,80 @@ type ContextManager struct {
 	messages       []Message
 	currentSpeaker string
 	speakerInfo    UserPreferences // 当前说话人信息
+
+	styleMu          sync.Mutex // 工具执行时会修改详略和人设，与构建消息的对话线程并发
+	verbosity        string     // 回复详略程度
+	personaPrompt    string     // 当前人设的 system prompt，为空时使用 systemPrompt
+	personaVerbosity string     // 当前人设的回复详略，为空时使用 verbosity
+
+	musicMu   sync.Mutex
+	lastMusic MusicSlots // 最近播放的歌曲（播放线程写入）
+
+	presenceMu sync.Mutex
+	presence   []MemberPresence // 家人在家情况（在家检测任务写入）
+}
+
+// MemberPresence 一位家人是否在家，用于回答"妈妈回来了吗"。
+type MemberPresence struct {
+	Name  string
+	Home  bool
+	Since time.Time // 最近一次到家或出门的时间，零值表示启动后还没有变化过
+}
+
+// SetPresence 更新家人在家情况，为空时不注入 system prompt。
+func (cm *ContextManager) SetPresence(members []MemberPresence) {
+	cm.presenceMu.Lock()
+	cm.presence = append([]MemberPresence(nil), members...)
+	cm.presenceMu.Unlock()
+}
+
+// presenceInfo 把家人在家情况整理成 system prompt 中的一行。
+func (cm *ContextManager) presenceInfo(now time.Time) string {
+	cm.presenceMu.Lock()
+	defer cm.presenceMu.Unlock()
+	if len(cm.presence) == 0 {
+		return ""
+	}
+	parts := make([]string, len(cm.presence))
+	for i, m := range cm.presence {
+		status := "不在家"
+		if m.Home {
+			status = "在家"
+		}
+		if !m.Since.IsZero() {
+			layout := "15:04"
+			if m.Since.Format("2006-01-02") != now.Format("2006-01-02") {
+				layout = "01月02日 15:04"
+			}
+			action := "出门"
+			if m.Home {
+				action = "到家"
+			}
+			status += fmt.Sprintf("（%s%s）", m.Since.Format(layout), action)
+		}
+		parts[i] = m.Name + status
+	}
+	return "\n家人在家情况: " + strings.Join(parts, "、")
+}
+
+// MusicSlots 最近播放的歌曲，用于理解"换成现场版"、"放她别的歌"这类追问。
+type MusicSlots struct {
+	Song   string
+	Artist string
+}
+
+// SetLastMusic 记录最近开始播放的歌曲。
+func (cm *ContextManager) SetLastMusic(song, artist string) {
+	cm.musicMu.Lock()
+	cm.lastMusic = MusicSlots{Song: song, Artist: artist}
+	cm.musicMu.Unlock()
+}
+
+// LastMusic 返回最近播放的歌曲，没有播放过时字段为空。
+func (cm *ContextManager) LastMusic() MusicSlots {
+	cm.musicMu.Lock()
+	defer cm.musicMu.Unlock()
+	return cm.lastMusic
 }
 
 // NewContextManager 创建对话上下文管理器。
@@ -40,11 +135,96 @@ func (cm *ContextManager) SetCurrentSpeaker(name string, info UserPreferences) {
 	cm.speakerInfo = info
 }
 
+// SetVerbosity 设置回复详略程度，无效值按 normal 处理。
+// 用户明确调整详略时以用户为准，覆盖当前人设的详略。
+func (cm *ContextManager) SetVerbosity(v string) {
+	if !ValidVerbosity(v) {
+		v = VerbosityNormal
+	}
+	cm.styleMu.Lock()
+	cm.verbosity = v
+	cm.personaVerbosity = ""
+	cm.styleMu.Unlock()
+}
+
+// Verbosity 返回当前回复详略程度。
+func (cm *ContextManager) Verbosity() string {
+	cm.styleMu.Lock()
+	defer cm.styleMu.Unlock()
+	if cm.personaVerbosity != "" {
+		return cm.personaVerbosity
+	}
+	if cm.verbosity == "" {
+		return VerbosityNormal
+	}
+	return cm.verbosity
+}
+
+// SetPersona 切换人设：prompt 替换默认的 system prompt，verbosity 覆盖回复详略。
+// 两者为空（或详略无效）时沿用默认值，传两个空字符串即恢复默认人设。
+func (cm *ContextManager) SetPersona(prompt, verbosity string) {
+	if !ValidVerbosity(verbosity) {
+		verbosity = ""
+	}
+	cm.styleMu.Lock()
+	cm.personaPrompt = strings.TrimSpace(prompt)
+	cm.personaVerbosity = verbosity
+	cm.styleMu.Unlock()
+}
+
 // GetCurrentSpeaker 获取当前说话人姓名。
 func (cm *ContextManager) GetCurrentSpeaker() string {
 	return cm.currentSpeaker
 }
 
+// SpeakerHomeCity 返回当前说话人偏好中设置的所在城市，未识别或未设置时返回空。
+func (cm *ContextManager) SpeakerHomeCity() string {
+	if cm.speakerInfo == nil {
+		return ""
+	}
+	var prefs speakerPreferences
+	if err := json.Unmarshal([]byte(cm.speakerInfo.GetPreferences()), &prefs); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(prefs.HomeCity)
+}
+
+// 回复语言，空为默认的中文。
+const LanguageEnglish = "en"
+
+// NormalizeLanguage 把偏好中的语言（"en"、"English"、"英语"等）规范为 LanguageEnglish，其他返回空。
+func NormalizeLanguage(lang string) string {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "en", "en-us", "en-gb", "english", "英语", "英文":
+		return LanguageEnglish
+	}
+	return ""
+}
+
+// SpeakerLanguage 返回当前说话人偏好的回复语言，未识别或未设置时返回空（中文）。
+func (cm *ContextManager) SpeakerLanguage() string {
+	if cm.speakerInfo == nil {
+		return ""
+	}
+	var prefs speakerPreferences
+	if err := json.Unmarshal([]byte(cm.speakerInfo.GetPreferences()), &prefs); err != nil {
+		return ""
+	}
+	return NormalizeLanguage(prefs.Language)
+}
+
+// SpeakerPersona 返回当前说话人偏好的默认人设名称，未识别或未设置时返回空。
+func (cm *ContextManager) SpeakerPersona() string {
+	if cm.speakerInfo == nil {
+		return ""
+	}
+	var prefs speakerPreferences
+	if err := json.Unmarshal([]byte(cm.speakerInfo.GetPreferences()), &prefs); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(prefs.Persona)
+}
+
 // Add 添加一条消息到对话历史。
 // 当消息数超过 maxHistory*2 时，自动截掉最早的消息只保留最近的部分。
 func (cm *ContextManager) Add(role, content string) {
@@ -107,23 +287,89 @@ func (cm *ContextManager) Messages() []Message {
 	var userInfo string
 	if cm.currentSpeaker != "" {
 		userInfo = fmt.Sprintf("\n当前对话用户: %s", cm.currentSpeaker)
-		if cm.speakerInfo != nil && cm.speakerInfo.GetPreferences() != "" {
-			userInfo += fmt.Sprintf("\n用户偏好: %s", cm.speakerInfo.GetPreferences())
+		if cm.speakerInfo != nil {
+			userInfo += formatPreferences(cm.speakerInfo.GetPreferences())
 		}
 	}
 
+	// 最近播放的歌曲，方便理解"这首歌叫什么"、"换成现场版"
+	var musicInfo string
+	if last := cm.LastMusic(); last.Song != "" {
+		musicInfo = fmt.Sprintf("\n最近播放: %s《%s》", last.Artist, last.Song)
+	}
+
+	presenceInfo := cm.presenceInfo(now)
+
 	// 清理消息序列，确保格式正确
 	messages := cm.cleanMessageSequence(cm.messages)
 
+	systemPrompt := cm.systemPrompt
+	cm.styleMu.Lock()
+	if cm.personaPrompt != "" {
+		systemPrompt = cm.personaPrompt
+	}
+	cm.styleMu.Unlock()
+
 	msgs := make([]Message, 0, 1+len(messages))
 	msgs = append(msgs, Message{
 		Role:    "system",
-		Content: cm.systemPrompt + timeInfo + userInfo,
+		Content: systemPrompt + verbosityPrompts[cm.Verbosity()] + timeInfo + userInfo + musicInfo + presenceInfo,
 	})
 	msgs = append(msgs, messages...)
 	return msgs
 }
 
+// speakerPreferences 对应 voiceprint.UserPreferences 的 JSON 结构。
+// llm 包不能依赖 voiceprint，这里单独定义一份用于解析。
+type speakerPreferences struct {
+	Style     string   `json:"style"`
+	Interests []string `json:"interests"`
+	Nickname  string   `json:"nickname"`
+	Extra     string   `json:"extra"`
+	HomeCity  string   `json:"home_city"`
+	Language  string   `json:"language"`
+	Persona   string   `json:"persona"`
+}
+
+// formatPreferences 将用户偏好 JSON 转换为 system prompt 中的明确指令。
+// 解析失败时退回原样附加，保证偏好信息不会丢失。
+func formatPreferences(prefsJSON string) string {
+	prefsJSON = strings.TrimSpace(prefsJSON)
+	if prefsJSON == "" {
+		return ""
+	}
+
+	var prefs speakerPreferences
+	if err := json.Unmarshal([]byte(prefsJSON), &prefs); err != nil {
+		logger.Debugf("[context] 解析用户偏好失败，按原文注入: %v", err)
+		return fmt.Sprintf("\n用户偏好: %s", prefsJSON)
+	}
+
+	var lines []string
+	if prefs.Nickname != "" {
+		lines = append(lines, fmt.Sprintf("- 称呼: 请称呼用户为\"%s\"", prefs.Nickname))
+	}
+	if prefs.Style != "" {
+		lines = append(lines, fmt.Sprintf("- 回复风格: %s（请严格按照该风格组织回复）", prefs.Style))
+	}
+	if len(prefs.Interests) > 0 {
+		lines = append(lines, fmt.Sprintf("- 兴趣爱好: %s（合适时可结合相关话题）", strings.Join(prefs.Interests, "、")))
+	}
+	if prefs.Extra != "" {
+		lines = append(lines, fmt.Sprintf("- 补充说明: %s", prefs.Extra))
+	}
+	if prefs.HomeCity != "" {
+		lines = append(lines, fmt.Sprintf("- 所在城市: %s", prefs.HomeCity))
+	}
+	if NormalizeLanguage(prefs.Language) == LanguageEnglish {
+		lines = append(lines, "- 回复语言: 请始终用英语回复（即使用户说中文），不要夹杂中文")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n用户偏好:\n" + strings.Join(lines, "\n")
+}
+
 // cleanMessageSequence 清理消息序列。
 // 正常的工具调用流程中，消息会以 tool 结尾（assistant(tool_calls) + tool(result)），
 // 这是正确的序列，LLM 需要看到 tool 结果才能生成回复，必须保留！
@@ -3,6 +3,7 @@ package llm
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestContextManager_AddAndMessages(t *testing.T) {
@@ -141,6 +142,106 @@ func TestContextManager_SetCurrentSpeaker(t *testing.T) {
 	}
 }
 
+func TestContextManager_PreferencesInjected(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	cm.SetCurrentSpeaker("小明", &mockUserPreferences{
+		prefs: `{"style":"简洁直接","interests":["编程","音乐"],"nickname":"程序员","extra":"喜欢用技术解决问题"}`,
+	})
+
+	content := cm.Messages()[0].Content
+	for _, want := range []string{
+		"请称呼用户为\"程序员\"",
+		"回复风格: 简洁直接",
+		"兴趣爱好: 编程、音乐",
+		"补充说明: 喜欢用技术解决问题",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("system prompt should contain %q, got %q", want, content)
+		}
+	}
+	// 结构化注入后不应再出现原始 JSON
+	if strings.Contains(content, `"style"`) {
+		t.Errorf("system prompt should not contain raw JSON, got %q", content)
+	}
+}
+
+func TestContextManager_PreferencesDifferPerSpeaker(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+
+	cm.SetCurrentSpeaker("小明", &mockUserPreferences{prefs: `{"style":"简洁直接"}`})
+	concise := cm.Messages()[0].Content
+
+	cm.SetCurrentSpeaker("小红", &mockUserPreferences{prefs: `{"style":"活泼幽默","nickname":"红红"}`})
+	playful := cm.Messages()[0].Content
+
+	if concise == playful {
+		t.Fatal("different preferences should produce different system prompts")
+	}
+	if !strings.Contains(concise, "简洁直接") || strings.Contains(concise, "活泼幽默") {
+		t.Errorf("unexpected prompt for 小明: %q", concise)
+	}
+	if !strings.Contains(playful, "活泼幽默") || !strings.Contains(playful, "红红") {
+		t.Errorf("unexpected prompt for 小红: %q", playful)
+	}
+}
+
+func TestFormatPreferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"empty object", "{}", ""},
+		{"invalid json", "喜欢简短回答", "\n用户偏好: 喜欢简短回答"},
+		{"style only", `{"style":"简洁"}`, "\n用户偏好:\n- 回复风格: 简洁（请严格按照该风格组织回复）"},
+		{"home city", `{"home_city":"杭州"}`, "\n用户偏好:\n- 所在城市: 杭州"},
+		{"english", `{"language":"English"}`, "\n用户偏好:\n- 回复语言: 请始终用英语回复（即使用户说中文），不要夹杂中文"},
+		{"chinese", `{"language":"zh"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPreferences(tt.input); got != tt.want {
+				t.Errorf("formatPreferences(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextManager_SpeakerHomeCity(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	if got := cm.SpeakerHomeCity(); got != "" {
+		t.Errorf("no speaker should have no home city, got %q", got)
+	}
+
+	cm.SetCurrentSpeaker("小明", &mockUserPreferences{prefs: `{"home_city":"杭州"}`})
+	if got := cm.SpeakerHomeCity(); got != "杭州" {
+		t.Errorf("SpeakerHomeCity() = %q, want 杭州", got)
+	}
+
+	cm.SetCurrentSpeaker("小红", &mockUserPreferences{prefs: `{"style":"简洁"}`})
+	if got := cm.SpeakerHomeCity(); got != "" {
+		t.Errorf("speaker without home city should return empty, got %q", got)
+	}
+}
+
+func TestContextManager_SpeakerLanguage(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	if got := cm.SpeakerLanguage(); got != "" {
+		t.Errorf("no speaker should have no language, got %q", got)
+	}
+
+	cm.SetCurrentSpeaker("Tom", &mockUserPreferences{prefs: `{"language":"英语"}`})
+	if got := cm.SpeakerLanguage(); got != LanguageEnglish {
+		t.Errorf("SpeakerLanguage() = %q, want %q", got, LanguageEnglish)
+	}
+
+	cm.SetCurrentSpeaker("小红", &mockUserPreferences{prefs: `{"language":"中文"}`})
+	if got := cm.SpeakerLanguage(); got != "" {
+		t.Errorf("chinese speaker should return empty, got %q", got)
+	}
+}
+
 // mockUserPreferences 用于测试
 type mockUserPreferences struct {
 	prefs   string
@@ -154,3 +255,102 @@ func (m *mockUserPreferences) GetPreferences() string {
 func (m *mockUserPreferences) IsOwner() bool {
 	return m.isOwner
 }
+
+func TestContextManager_Verbosity(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	if cm.Verbosity() != VerbosityNormal {
+		t.Errorf("default verbosity should be normal, got %s", cm.Verbosity())
+	}
+	if content := cm.Messages()[0].Content; strings.Contains(content, "回复要求") {
+		t.Errorf("normal verbosity should not add instructions, got %q", content)
+	}
+
+	cm.SetVerbosity(VerbosityBrief)
+	if content := cm.Messages()[0].Content; !strings.Contains(content, "尽量简短") {
+		t.Errorf("brief verbosity should be injected, got %q", content)
+	}
+
+	cm.SetVerbosity("unknown")
+	if cm.Verbosity() != VerbosityNormal {
+		t.Errorf("invalid verbosity should fall back to normal, got %s", cm.Verbosity())
+	}
+}
+
+// 工具调用修改详略与对话线程构建消息并发进行，需在 -race 下无数据竞争。
+func TestContextManager_VerbosityConcurrent(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			cm.SetVerbosity(VerbosityBrief)
+			cm.SetPersona("你是段子手", VerbosityDetailed)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = cm.Messages()
+		_ = cm.Verbosity()
+	}
+	<-done
+}
+
+func TestContextManager_Persona(t *testing.T) {
+	cm := NewContextManager("你是小派", 5)
+	cm.SetVerbosity(VerbosityBrief)
+
+	cm.SetPersona("你是耐心的助教", VerbosityDetailed)
+	content := cm.Messages()[0].Content
+	if !strings.HasPrefix(content, "你是耐心的助教") || strings.Contains(content, "你是小派") {
+		t.Errorf("persona prompt should replace the system prompt, got %q", content)
+	}
+	if cm.Verbosity() != VerbosityDetailed || !strings.Contains(content, "详细") {
+		t.Errorf("persona verbosity should apply, got %s", cm.Verbosity())
+	}
+
+	// 人设没有指定详略时沿用设备设置
+	cm.SetPersona("你是段子手", "")
+	if cm.Verbosity() != VerbosityBrief {
+		t.Errorf("verbosity = %s, want brief", cm.Verbosity())
+	}
+
+	// 用户明确调整详略时覆盖人设
+	cm.SetPersona("你是耐心的助教", VerbosityDetailed)
+	cm.SetVerbosity(VerbosityNormal)
+	if cm.Verbosity() != VerbosityNormal {
+		t.Errorf("explicit verbosity should override persona, got %s", cm.Verbosity())
+	}
+
+	cm.SetPersona("", "")
+	if content := cm.Messages()[0].Content; !strings.HasPrefix(content, "你是小派") {
+		t.Errorf("empty persona should restore the system prompt, got %q", content)
+	}
+}
+
+func TestContextManager_SpeakerPersona(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	if got := cm.SpeakerPersona(); got != "" {
+		t.Errorf("no speaker should have no persona, got %q", got)
+	}
+	cm.SetCurrentSpeaker("小明", &mockUserPreferences{prefs: `{"persona":"助教模式"}`})
+	if got := cm.SpeakerPersona(); got != "助教模式" {
+		t.Errorf("SpeakerPersona() = %q, want 助教模式", got)
+	}
+}
+
+func TestContextManager_Presence(t *testing.T) {
+	cm := NewContextManager("sys", 5)
+	if content := cm.Messages()[0].Content; strings.Contains(content, "家人在家情况") {
+		t.Errorf("presence should not be injected before it is known, got %q", content)
+	}
+
+	arrived := time.Now()
+	cm.SetPresence([]MemberPresence{
+		{Name: "妈妈", Home: true, Since: arrived},
+		{Name: "爸爸", Home: false},
+	})
+	content := cm.Messages()[0].Content
+	want := "家人在家情况: 妈妈在家（" + arrived.Format("15:04") + "到家）、爸爸不在家"
+	if !strings.Contains(content, want) {
+		t.Errorf("system prompt should contain %q, got %q", want, content)
+	}
+}
@@ -29,6 +29,8 @@ type MultiProvider struct {
 	entries []providerEntry
 	current int // 当前活跃索引
 	mu      sync.RWMutex
+
+	onSwitch func(name string) // 切换模型后的回调（用于持久化）
 }
 
 // NewMultiProvider 根据模型配置列表创建 MultiProvider。
@@ -62,6 +64,26 @@ func (m *MultiProvider) CurrentName() string {
 	return m.entries[m.current].name
 }
 
+// Select 切换到指定名称的模型，名称不存在时返回 false。
+func (m *MultiProvider) Select(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, e := range m.entries {
+		if e.name == name {
+			m.current = i
+			return true
+		}
+	}
+	return false
+}
+
+// SetOnSwitch 设置自动降级切换模型后的回调。
+func (m *MultiProvider) SetOnSwitch(fn func(name string)) {
+	m.mu.Lock()
+	m.onSwitch = fn
+	m.mu.Unlock()
+}
+
 // ChatStream 实现 Provider 接口，自动降级。
 func (m *MultiProvider) ChatStream(ctx context.Context, messages []Message) (<-chan string, error) {
 	textCh, resultCh, err := m.ChatStreamWithTools(ctx, messages, nil)
@@ -97,8 +119,12 @@ func (m *MultiProvider) ChatStreamWithTools(ctx context.Context, messages []Mess
 			if idx != startIdx {
 				m.mu.Lock()
 				m.current = idx
+				onSwitch := m.onSwitch
 				m.mu.Unlock()
 				logger.Infof("[llm] 切换到模型 [%s]", entry.name)
+				if onSwitch != nil {
+					onSwitch(entry.name)
+				}
 			}
 			return textCh, resultCh, nil
 		}
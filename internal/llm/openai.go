@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"net/http"
 	"strings"
 	"time"
@@ -29,7 +30,8 @@ func NewOpenAIProvider(apiURL, apiKey, model string) *OpenAIProvider {
 		apiKey: apiKey,
 		model:  model,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
 	}
 }
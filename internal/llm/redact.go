@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// RedactRule 一条脱敏规则：匹配 Pattern 的内容替换为 Replace。
+type RedactRule struct {
+	Name    string // 用于审计日志，如"手机号"
+	Pattern *regexp.Regexp
+	Replace string
+}
+
+// builtinRedactRules 内置的脱敏规则，按名称启用。
+var builtinRedactRules = map[string]RedactRule{
+	"phone": {
+		Name:    "手机号",
+		Pattern: regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d[- ]?\d{4}[- ]?\d{4}`),
+		Replace: "[手机号]",
+	},
+	"id_card": {
+		Name:    "身份证号",
+		Pattern: regexp.MustCompile(`[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`),
+		Replace: "[身份证号]",
+	},
+	"email": {
+		Name:    "邮箱",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replace: "[邮箱]",
+	},
+}
+
+// BuiltinRedactRule 返回内置的脱敏规则（phone、id_card、email）。
+func BuiltinRedactRule(name string) (RedactRule, bool) {
+	rule, ok := builtinRedactRules[name]
+	return rule, ok
+}
+
+// BuiltinRedactRuleNames 返回所有内置规则的名称。
+func BuiltinRedactRuleNames() []string {
+	names := make([]string, 0, len(builtinRedactRules))
+	for name := range builtinRedactRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Redactor 按规则隐去文本中的敏感信息（手机号、门锁密码、家庭住址等）。
+type Redactor struct {
+	rules []RedactRule
+}
+
+// NewRedactor 创建脱敏器，规则按顺序应用。
+func NewRedactor(rules []RedactRule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+// Redact 隐去文本中的敏感信息，返回处理后的文本和各规则的命中次数。
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	var hits map[string]int
+	for _, rule := range r.rules {
+		n := len(rule.Pattern.FindAllStringIndex(text, -1))
+		if n == 0 {
+			continue
+		}
+		text = rule.Pattern.ReplaceAllLiteralString(text, rule.Replace)
+		if hits == nil {
+			hits = make(map[string]int)
+		}
+		hits[rule.Name] += n
+	}
+	return text, hits
+}
+
+// redactMessages 返回隐去敏感信息后的消息副本，不修改原消息（对话历史保留原文，只在本地）。
+// localOnly 中的工具结果不能离开设备，整条替换为占位说明。
+func (r *Redactor) redactMessages(messages []Message, localOnly map[string]bool) ([]Message, map[string]int) {
+	out := make([]Message, len(messages))
+	total := make(map[string]int)
+	for i, m := range messages {
+		if m.Role == "tool" && localOnly[m.Name] {
+			m.Content = localOnlyPlaceholder
+			total["仅限本地的工具结果"]++
+		} else if m.Content != "" {
+			var hits map[string]int
+			m.Content, hits = r.Redact(m.Content)
+			for name, n := range hits {
+				total[name] += n
+			}
+		}
+		out[i] = m
+	}
+	return out, total
+}
+
+// localOnlyPlaceholder 仅限本地的工具结果发给云端模型时的占位内容。
+const localOnlyPlaceholder = `{"success":true,"message":"该结果仅限在本设备使用，已直接告诉用户，不要复述或猜测具体内容"}`
+
+// RedactingProvider 在消息发给云端大模型之前隐去敏感信息，并把审计结果写入日志。
+// 只应包装云端模型，本地模型不需要脱敏。
+type RedactingProvider struct {
+	inner     Provider
+	redactor  *Redactor
+	localOnly map[string]bool
+}
+
+// NewRedactingProvider 包装云端模型。localOnly 为结果不能离开设备的工具名称。
+func NewRedactingProvider(inner Provider, redactor *Redactor, localOnly []string) *RedactingProvider {
+	set := make(map[string]bool, len(localOnly))
+	for _, name := range localOnly {
+		set[name] = true
+	}
+	return &RedactingProvider{inner: inner, redactor: redactor, localOnly: set}
+}
+
+// ChatStream 脱敏后调用被包装的模型。
+func (p *RedactingProvider) ChatStream(ctx context.Context, messages []Message) (<-chan string, error) {
+	return p.inner.ChatStream(ctx, p.redact(messages))
+}
+
+// ChatStreamWithTools 脱敏后调用被包装的模型。
+func (p *RedactingProvider) ChatStreamWithTools(ctx context.Context, messages []Message, tools []ToolDefinition) (<-chan string, <-chan *StreamResult, error) {
+	return p.inner.ChatStreamWithTools(ctx, p.redact(messages), tools)
+}
+
+func (p *RedactingProvider) redact(messages []Message) []Message {
+	out, hits := p.redactor.redactMessages(messages, p.localOnly)
+	if len(hits) > 0 {
+		logger.Infof("[llm] 发送前已隐去敏感信息: %s", formatRedactHits(hits))
+	}
+	return out
+}
+
+// formatRedactHits 把命中次数整理成"手机号×1、门锁密码×2"。
+func formatRedactHits(hits map[string]int) string {
+	names := make([]string, 0, len(hits))
+	for name := range hits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s×%d", name, hits[name])
+	}
+	return strings.Join(parts, "、")
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Builtin(t *testing.T) {
+	var rules []RedactRule
+	for _, name := range BuiltinRedactRuleNames() {
+		rule, _ := BuiltinRedactRule(name)
+		rules = append(rules, rule)
+	}
+	r := NewRedactor(rules)
+
+	got, hits := r.Redact("我的手机是 138-1234-5678，邮箱 tom@example.com，身份证 110101199003071234")
+	want := "我的手机是 [手机号]，邮箱 [邮箱]，身份证 [身份证号]"
+	if got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+	if hits["手机号"] != 1 || hits["邮箱"] != 1 || hits["身份证号"] != 1 {
+		t.Errorf("hits = %v", hits)
+	}
+	if got, hits := r.Redact("明天 8 点叫我起床"); got != "明天 8 点叫我起床" || hits != nil {
+		t.Errorf("plain text should be untouched: %q %v", got, hits)
+	}
+}
+
+// recordingProvider 记录发送的消息。
+type recordingProvider struct {
+	sent []Message
+}
+
+func (p *recordingProvider) ChatStream(ctx context.Context, messages []Message) (<-chan string, error) {
+	p.sent = messages
+	ch := make(chan string)
+	close(ch)
+	return ch, nil
+}
+
+func (p *recordingProvider) ChatStreamWithTools(ctx context.Context, messages []Message, tools []ToolDefinition) (<-chan string, <-chan *StreamResult, error) {
+	p.sent = messages
+	ch := make(chan string)
+	close(ch)
+	return ch, nil, nil
+}
+
+func TestRedactingProvider(t *testing.T) {
+	inner := &recordingProvider{}
+	pin := RedactRule{Name: "门锁密码", Pattern: regexp.MustCompile(`密码是\d{4,8}`), Replace: "[门锁密码]"}
+	p := NewRedactingProvider(inner, NewRedactor([]RedactRule{pin}), []string{"get_guest_wifi"})
+
+	messages := []Message{
+		{Role: "user", Content: "门锁密码是123456，帮我记一下"},
+		{Role: "tool", Name: "get_guest_wifi", Content: `{"password":"secret"}`},
+		{Role: "tool", Name: "get_weather", Content: "晴"},
+	}
+	if _, _, err := p.ChatStreamWithTools(context.Background(), messages, nil); err != nil {
+		t.Fatal(err)
+	}
+	if inner.sent[0].Content != "门锁[门锁密码]，帮我记一下" {
+		t.Errorf("user text = %q", inner.sent[0].Content)
+	}
+	if strings.Contains(inner.sent[1].Content, "secret") {
+		t.Errorf("local-only tool result leaked: %q", inner.sent[1].Content)
+	}
+	if inner.sent[2].Content != "晴" {
+		t.Errorf("other tool result = %q", inner.sent[2].Content)
+	}
+	// 本地的对话历史保留原文
+	if messages[0].Content != "门锁密码是123456，帮我记一下" {
+		t.Error("original messages should not be modified")
+	}
+}
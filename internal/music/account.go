@@ -0,0 +1,337 @@
+package music
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// DefaultAccount 默认账号名，对应旧版的 qq_cookie.json / netease_cookie.json。
+const DefaultAccount = "default"
+
+// neteaseCookieMaxAge 网易云 cookie 的预计有效期（经验值）。
+const neteaseCookieMaxAge = 30 * 24 * time.Hour
+
+// ErrCookieCorrupted cookie 文件校验失败（被截断或手动改坏）。
+var ErrCookieCorrupted = errors.New("cookie 文件校验失败")
+
+// accountGeneration 账号切换或重新登录时递增，音乐客户端据此丢弃缓存的 cookie。
+var accountGeneration atomic.Int64
+
+// CookieData 登录 cookie 文件内容，由 pibuddy-music 写入，音乐客户端读取。
+type CookieData struct {
+	Cookies   []http.Cookie `json:"cookies"`
+	LoggedIn  bool          `json:"logged_in"`
+	User      string        `json:"user"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	ExpiresAt time.Time     `json:"expires_at,omitempty"` // 预计过期时间
+	Checksum  string        `json:"checksum,omitempty"`   // cookie 内容的 SHA-256，旧版文件没有该字段
+}
+
+// checksum 计算 cookie 名称和值的摘要。
+func (d *CookieData) checksum() string {
+	h := sha256.New()
+	for _, c := range d.Cookies {
+		fmt.Fprintf(h, "%s=%s\n", c.Name, c.Value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify 校验 cookie 完整性。没有校验信息的旧版文件视为通过。
+func (d *CookieData) Verify() error {
+	if len(d.Cookies) == 0 {
+		return fmt.Errorf("%w: 没有 cookie", ErrCookieCorrupted)
+	}
+	if d.Checksum != "" && d.Checksum != d.checksum() {
+		return fmt.Errorf("%w: 摘要不匹配", ErrCookieCorrupted)
+	}
+	return nil
+}
+
+// Has 判断是否包含指定名称的非空 cookie。
+func (d *CookieData) Has(name string) bool {
+	for _, c := range d.Cookies {
+		if c.Name == name && c.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired 判断 cookie 是否已超过预计过期时间。
+func (d *CookieData) Expired() bool {
+	return !d.ExpiresAt.IsZero() && time.Now().After(d.ExpiresAt)
+}
+
+// estimateExpiry 估算过期时间：优先取 cookie 自带的最早过期时间，否则按平台经验值推算。
+func estimateExpiry(provider string, d *CookieData) time.Time {
+	var earliest time.Time
+	for _, c := range d.Cookies {
+		if c.Expires.IsZero() || c.Expires.Before(d.UpdatedAt) {
+			continue
+		}
+		if earliest.IsZero() || c.Expires.Before(earliest) {
+			earliest = c.Expires
+		}
+	}
+	if !earliest.IsZero() {
+		return earliest
+	}
+	if provider == "qq" {
+		return d.UpdatedAt.Add(cookieMaxAge)
+	}
+	return d.UpdatedAt.Add(neteaseCookieMaxAge)
+}
+
+// AccountInfo 账号概要。
+type AccountInfo struct {
+	Name      string
+	User      string
+	UpdatedAt time.Time
+	ExpiresAt time.Time
+	Active    bool
+	Err       error // 读取或校验失败的原因
+}
+
+// AccountStore 管理各音乐平台的多个登录账号。
+// 每个账号一个 cookie 文件（0600 权限），当前使用的账号记录在 music_accounts.json。
+// 每次读取都访问磁盘，pibuddy-music 在其他进程中切换账号也能生效。
+type AccountStore struct {
+	mu       sync.Mutex
+	dataDir  string
+	filePath string
+}
+
+// NewAccountStore 创建账号存储。
+func NewAccountStore(dataDir string) *AccountStore {
+	if dataDir == "" {
+		dataDir = getDefaultDataDir()
+	}
+	return &AccountStore{
+		dataDir:  dataDir,
+		filePath: filepath.Join(dataDir, "music_accounts.json"),
+	}
+}
+
+// validateAccountName 检查账号名，避免路径穿越。
+func validateAccountName(name string) error {
+	if name == "" || len(name) > 32 || strings.ContainsAny(name, `/\. `) {
+		return fmt.Errorf("账号名无效: %q（不能为空，不能包含 / \\ . 和空格）", name)
+	}
+	return nil
+}
+
+// CookiePath 返回账号的 cookie 文件路径。默认账号沿用旧文件名，保持兼容。
+func (s *AccountStore) CookiePath(provider, name string) string {
+	if name == "" || name == DefaultAccount {
+		return filepath.Join(s.dataDir, cookieFileName(provider))
+	}
+	return filepath.Join(s.dataDir, fmt.Sprintf("%s_cookie_%s.json", provider, name))
+}
+
+// cookieFileName 返回默认账号的 cookie 文件名。
+func cookieFileName(provider string) string {
+	if provider == "qq" {
+		return "qq_cookie.json"
+	}
+	return "netease_cookie.json"
+}
+
+// loadActiveMap 读取各平台当前账号，调用方需持有锁。
+func (s *AccountStore) loadActiveMap() map[string]string {
+	active := make(map[string]string)
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return active
+	}
+	if err := json.Unmarshal(data, &active); err != nil {
+		logger.Warnf("[music] 解析账号配置失败: %v", err)
+	}
+	return active
+}
+
+// saveActiveMap 保存各平台当前账号，调用方需持有锁。
+func (s *AccountStore) saveActiveMap(active map[string]string) error {
+	data, err := json.MarshalIndent(active, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.filePath, data, 0600)
+}
+
+// Active 返回平台当前使用的账号名。
+func (s *AccountStore) Active(provider string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name := s.loadActiveMap()[provider]; name != "" {
+		return name
+	}
+	return DefaultAccount
+}
+
+// Switch 切换平台当前使用的账号，账号必须已登录。
+func (s *AccountStore) Switch(provider, name string) error {
+	if name != DefaultAccount {
+		if err := validateAccountName(name); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(s.CookiePath(provider, name)); err != nil {
+		return fmt.Errorf("账号 %s 尚未登录", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.loadActiveMap()
+	active[provider] = name
+	if err := s.saveActiveMap(active); err != nil {
+		return fmt.Errorf("保存账号配置失败: %w", err)
+	}
+	accountGeneration.Add(1)
+	logger.Infof("[music] %s 已切换到账号 %s", provider, name)
+	return nil
+}
+
+// Save 保存账号 cookie：补全校验信息和预计过期时间，先写临时文件再重命名，权限 0600。
+func (s *AccountStore) Save(provider, name string, data *CookieData) error {
+	if name == "" {
+		name = DefaultAccount
+	}
+	if name != DefaultAccount {
+		if err := validateAccountName(name); err != nil {
+			return err
+		}
+	}
+	if data.UpdatedAt.IsZero() {
+		data.UpdatedAt = time.Now()
+	}
+	data.ExpiresAt = estimateExpiry(provider, data)
+	data.Checksum = data.checksum()
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return err
+	}
+
+	path := s.CookiePath(provider, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// 覆盖已有文件时 WriteFile 不会修改权限
+	if err := os.Chmod(path, 0600); err != nil {
+		return err
+	}
+	accountGeneration.Add(1)
+	return nil
+}
+
+// Load 读取并校验账号 cookie。文件权限过宽时自动收紧为 0600。
+func (s *AccountStore) Load(provider, name string) (*CookieData, error) {
+	path := s.CookiePath(provider, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		logger.Warnf("[music] cookie 文件 %s 权限过宽 (%v)，已改为 0600", path, info.Mode().Perm())
+		_ = os.Chmod(path, 0600)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data CookieData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCookieCorrupted, err)
+	}
+	if err := data.Verify(); err != nil {
+		return nil, err
+	}
+	if data.ExpiresAt.IsZero() && !data.UpdatedAt.IsZero() {
+		data.ExpiresAt = estimateExpiry(provider, &data)
+	}
+	return &data, nil
+}
+
+// LoadActive 读取平台当前账号的 cookie，同时返回账号名。
+func (s *AccountStore) LoadActive(provider string) (*CookieData, string, error) {
+	name := s.Active(provider)
+	data, err := s.Load(provider, name)
+	return data, name, err
+}
+
+// Remove 删除账号。删除当前账号时切回默认账号。
+func (s *AccountStore) Remove(provider, name string) error {
+	if err := os.Remove(s.CookiePath(provider, name)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.loadActiveMap()
+	if active[provider] == name {
+		delete(active, provider)
+		if err := s.saveActiveMap(active); err != nil {
+			return err
+		}
+	}
+	accountGeneration.Add(1)
+	return nil
+}
+
+// List 列出平台的所有账号，默认账号排在最前。
+func (s *AccountStore) List(provider string) []AccountInfo {
+	active := s.Active(provider)
+
+	var names []string
+	if _, err := os.Stat(s.CookiePath(provider, DefaultAccount)); err == nil {
+		names = append(names, DefaultAccount)
+	}
+	prefix := provider + "_cookie_"
+	matches, _ := filepath.Glob(filepath.Join(s.dataDir, prefix+"*.json"))
+	sort.Strings(matches)
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), prefix), ".json")
+		if validateAccountName(name) == nil {
+			names = append(names, name)
+		}
+	}
+
+	infos := make([]AccountInfo, 0, len(names))
+	for _, name := range names {
+		info := AccountInfo{Name: name, Active: name == active}
+		if data, err := s.Load(provider, name); err != nil {
+			info.Err = err
+		} else {
+			info.User = data.User
+			info.UpdatedAt = data.UpdatedAt
+			info.ExpiresAt = data.ExpiresAt
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
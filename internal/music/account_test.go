@@ -0,0 +1,157 @@
+package music
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func testCookies() *CookieData {
+	return &CookieData{
+		Cookies:  []http.Cookie{{Name: "uin", Value: "12345"}, {Name: "qqmusic_key", Value: "key"}},
+		LoggedIn: true,
+		User:     "12345",
+	}
+}
+
+func TestAccountStoreSaveLoad(t *testing.T) {
+	store := NewAccountStore(t.TempDir())
+
+	if err := store.Save("qq", "", testCookies()); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+
+	info, err := os.Stat(store.CookiePath("qq", DefaultAccount))
+	if err != nil {
+		t.Fatalf("默认账号应使用旧文件名: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("文件权限 = %v, want 0600", perm)
+	}
+
+	data, err := store.Load("qq", DefaultAccount)
+	if err != nil {
+		t.Fatalf("Load 失败: %v", err)
+	}
+	if data.Checksum == "" || data.User != "12345" || len(data.Cookies) != 2 {
+		t.Errorf("读取内容不正确: %+v", data)
+	}
+	if want := data.UpdatedAt.Add(cookieMaxAge); !data.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", data.ExpiresAt, want)
+	}
+}
+
+func TestAccountStoreExpiryFromCookie(t *testing.T) {
+	store := NewAccountStore(t.TempDir())
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	data := testCookies()
+	data.Cookies[1].Expires = expires
+
+	if err := store.Save("netease", "", data); err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if !data.ExpiresAt.Equal(expires) {
+		t.Errorf("应使用 cookie 自带的过期时间: %v, want %v", data.ExpiresAt, expires)
+	}
+}
+
+func TestAccountStoreCorrupted(t *testing.T) {
+	store := NewAccountStore(t.TempDir())
+	if err := store.Save("qq", "", testCookies()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟写入中断导致文件被截断
+	path := store.CookiePath("qq", DefaultAccount)
+	content, _ := os.ReadFile(path)
+	content = []byte(string(content[:len(content)/2]))
+	os.WriteFile(path, content, 0644)
+
+	if _, err := store.Load("qq", DefaultAccount); !errors.Is(err, ErrCookieCorrupted) {
+		t.Errorf("截断的文件应返回 ErrCookieCorrupted: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("权限过宽的文件应被收紧为 0600: %v", info.Mode().Perm())
+	}
+
+	data := testCookies()
+	data.Checksum = "bad"
+	if err := data.Verify(); !errors.Is(err, ErrCookieCorrupted) {
+		t.Errorf("摘要不匹配应返回 ErrCookieCorrupted: %v", err)
+	}
+
+	legacy := testCookies() // 旧版文件没有摘要
+	if err := legacy.Verify(); err != nil {
+		t.Errorf("旧版文件应视为通过: %v", err)
+	}
+}
+
+func TestAccountStoreSwitch(t *testing.T) {
+	store := NewAccountStore(t.TempDir())
+
+	if got := store.Active("qq"); got != DefaultAccount {
+		t.Errorf("默认账号 = %s, want %s", got, DefaultAccount)
+	}
+	if err := store.Switch("qq", "kid"); err == nil {
+		t.Error("切换到未登录的账号应报错")
+	}
+	if err := store.Save("qq", "../evil", testCookies()); err == nil {
+		t.Error("非法账号名应报错")
+	}
+
+	store.Save("qq", "", testCookies())
+	kid := testCookies()
+	kid.User = "67890"
+	if err := store.Save("qq", "kid", kid); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := accountGeneration.Load()
+	if err := store.Switch("qq", "kid"); err != nil {
+		t.Fatalf("Switch 失败: %v", err)
+	}
+	if accountGeneration.Load() == gen {
+		t.Error("切换账号后应递增账号版本")
+	}
+
+	data, name, err := store.LoadActive("qq")
+	if err != nil || name != "kid" || data.User != "67890" {
+		t.Errorf("LoadActive = %v, %s, %v", data, name, err)
+	}
+	if store.Active("netease") != DefaultAccount {
+		t.Error("不同平台的当前账号应互不影响")
+	}
+
+	infos := store.List("qq")
+	if len(infos) != 2 || infos[0].Name != DefaultAccount || infos[1].Name != "kid" || !infos[1].Active {
+		t.Errorf("List = %+v", infos)
+	}
+
+	if err := store.Remove("qq", "kid"); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Active("qq"); got != DefaultAccount {
+		t.Errorf("删除当前账号后应切回默认账号: %s", got)
+	}
+}
+
+func TestQQClientUsesActiveAccount(t *testing.T) {
+	dir := t.TempDir()
+	store := NewAccountStore(dir)
+	store.Save("qq", "", testCookies())
+	kid := testCookies()
+	kid.Cookies[0].Value = "67890"
+	store.Save("qq", "kid", kid)
+
+	client := NewQQMusicClientWithDataDir("", dir)
+	if h := client.cookieHeader(); h != "uin=12345; qqmusic_key=key" {
+		t.Errorf("cookieHeader = %q", h)
+	}
+
+	store.Switch("qq", "kid")
+	if h := client.cookieHeader(); h != "uin=67890; qqmusic_key=key" {
+		t.Errorf("切换账号后应立即使用新 cookie: %q", h)
+	}
+}
@@ -0,0 +1,240 @@
+package music
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+)
+
+// 可导出的数据（收藏、播放历史、缓存索引）和格式。
+const (
+	ExportFavorites = "favorites"
+	ExportHistory   = "history"
+	ExportCache     = "cache"
+
+	FormatM3U = "m3u"
+	FormatCSV = "csv"
+)
+
+// Exporter 把收藏、播放历史和缓存索引导出为 M3U 播放列表或 CSV 表格。
+// M3U 中的歌曲优先指向本地缓存文件，收藏的歌曲没有缓存时指向音乐平台的网页；CSV 包含全部字段。
+type Exporter struct {
+	Favorites *FavoritesStore   // 为 nil 时不能导出收藏
+	History   *HistoryStore     // 为 nil 时不能导出播放历史
+	Cache     *audio.MusicCache // 为 nil 时 M3U 不指向本地文件，也不能导出缓存索引
+}
+
+// exportTrack 导出的一首歌。
+type exportTrack struct {
+	title    string // 歌手 - 歌名
+	duration int64  // 秒，未知时为 -1
+	location string // 本地文件路径或网页地址，为空时不写入 M3U
+	row      []string
+}
+
+// Export 把 kind 按 format 写入 w。user 只对收藏有效，为空时导出所有用户的收藏。
+func (e *Exporter) Export(w io.Writer, kind, format, user string) error {
+	header, tracks, err := e.tracks(kind, user)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatM3U:
+		return writeM3U(w, tracks)
+	case FormatCSV:
+		return writeCSV(w, header, tracks)
+	default:
+		return fmt.Errorf("不支持的导出格式 %s，可选: m3u、csv", format)
+	}
+}
+
+// ExportFile 导出到 {dataDir}/exports/ 下的文件，返回文件路径。
+func (e *Exporter) ExportFile(dataDir, kind, format, user string) (string, error) {
+	dir := filepath.Join(dataDir, "exports")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建导出目录失败: %w", err)
+	}
+	name := kind
+	if user != "" && kind == ExportFavorites {
+		name += "-" + user
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("创建导出文件失败: %w", err)
+	}
+	if err := e.Export(f, kind, format, user); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("写入导出文件失败: %w", err)
+	}
+	return path, nil
+}
+
+// tracks 读取要导出的歌曲和 CSV 表头。
+func (e *Exporter) tracks(kind, user string) ([]string, []exportTrack, error) {
+	switch kind {
+	case ExportFavorites:
+		if e.Favorites == nil {
+			return nil, nil, fmt.Errorf("音乐收藏未启用")
+		}
+		files := e.cachedFiles()
+		users := []string{user}
+		if user == "" {
+			users = e.Favorites.Users()
+		}
+		var tracks []exportTrack
+		for _, u := range users {
+			songs, err := e.Favorites.List(u)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, s := range songs {
+				location := files.lookup(fmt.Sprintf("%s_%d", s.Provider, s.ID), s.Name, s.Artist)
+				if location == "" {
+					location = s.webURL()
+				}
+				tracks = append(tracks, exportTrack{
+					title:    trackTitle(s.Name, s.Artist),
+					duration: -1,
+					location: location,
+					row:      []string{u, s.Name, s.Artist, s.Album, s.Provider, strconv.FormatInt(s.ID, 10), s.AddedAt},
+				})
+			}
+		}
+		return []string{"user", "name", "artist", "album", "provider", "id", "added_at"}, tracks, nil
+
+	case ExportHistory:
+		if e.History == nil {
+			return nil, nil, fmt.Errorf("播放历史未启用")
+		}
+		files := e.cachedFiles()
+		var tracks []exportTrack
+		for _, h := range e.History.List(0) {
+			tracks = append(tracks, exportTrack{
+				title:    trackTitle(h.Name, h.Artist),
+				duration: -1,
+				location: files.lookup("", h.Name, h.Artist),
+				row:      []string{h.Name, h.Artist, h.Album, strconv.Itoa(h.PlayCount), h.PlayedAt, strconv.FormatInt(h.ID, 10)},
+			})
+		}
+		return []string{"name", "artist", "album", "play_count", "played_at", "id"}, tracks, nil
+
+	case ExportCache:
+		if e.Cache == nil {
+			return nil, nil, fmt.Errorf("音乐缓存未启用")
+		}
+		var tracks []exportTrack
+		for _, c := range e.Cache.List() {
+			path := e.Cache.FilePath(fmt.Sprintf("%s_%d", c.Provider, c.ProviderID))
+			duration := c.Duration
+			if duration <= 0 {
+				duration = -1
+			}
+			tracks = append(tracks, exportTrack{
+				title:    trackTitle(c.Name, c.Artist),
+				duration: duration,
+				location: path,
+				row: []string{c.Name, c.Artist, c.Album, c.Provider, strconv.FormatInt(c.ProviderID, 10),
+					strconv.FormatInt(c.Duration, 10), strconv.FormatInt(c.Size, 10), strconv.FormatInt(c.PlayCount, 10),
+					c.CachedAt, c.LastPlayed, path},
+			})
+		}
+		return []string{"name", "artist", "album", "provider", "provider_id", "duration", "size", "play_count", "cached_at", "last_played", "file"}, tracks, nil
+
+	default:
+		return nil, nil, fmt.Errorf("不支持导出 %s，可选: favorites、history、cache", kind)
+	}
+}
+
+// cachedFileIndex 本地缓存文件索引，按缓存键和"歌名|歌手"查找。
+type cachedFileIndex struct {
+	byKey  map[string]string
+	byName map[string]string
+}
+
+// cachedFiles 列出本地存在的缓存文件。
+func (e *Exporter) cachedFiles() cachedFileIndex {
+	idx := cachedFileIndex{byKey: make(map[string]string), byName: make(map[string]string)}
+	if e.Cache == nil {
+		return idx
+	}
+	for _, c := range e.Cache.List() {
+		key := fmt.Sprintf("%s_%d", c.Provider, c.ProviderID)
+		path := e.Cache.FilePath(key)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		idx.byKey[key] = path
+		if name := c.Name + "|" + c.Artist; idx.byName[name] == "" {
+			idx.byName[name] = path
+		}
+	}
+	return idx
+}
+
+func (idx cachedFileIndex) lookup(key, name, artist string) string {
+	if path, ok := idx.byKey[key]; ok {
+		return path
+	}
+	return idx.byName[name+"|"+artist]
+}
+
+// webURL 收藏歌曲在音乐平台的网页地址，无法确定时返回空。
+func (f FavoriteSong) webURL() string {
+	switch f.Provider {
+	case "netease":
+		return fmt.Sprintf("https://music.163.com/#/song?id=%d", f.ID)
+	case "qq":
+		if f.MID != "" {
+			return "https://y.qq.com/n/ryqq/songDetail/" + f.MID
+		}
+	}
+	return ""
+}
+
+func trackTitle(name, artist string) string {
+	if artist == "" {
+		return name
+	}
+	return artist + " - " + name
+}
+
+// writeM3U 写入扩展 M3U 播放列表，没有本地文件或网页地址的歌曲跳过。
+func writeM3U(w io.Writer, tracks []exportTrack) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, t := range tracks {
+		if t.location == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", t.duration, t.title, t.location)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCSV 写入带 UTF-8 BOM 的 CSV，Excel 打开中文不乱码。
+func writeCSV(w io.Writer, header []string, tracks []exportTrack) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, t := range tracks {
+		cw.Write(t.row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
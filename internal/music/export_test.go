@@ -0,0 +1,79 @@
+package music
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExporter_Favorites(t *testing.T) {
+	dir := t.TempDir()
+	favorites := NewFavoritesStore(dir)
+	favorites.Add("小明", FavoriteSong{ID: 186016, Name: "晴天", Artist: "周杰伦", Album: "叶惠美", Provider: "netease"})
+	favorites.Add("小明", FavoriteSong{ID: 1, MID: "003OUlho2HcRHC", Name: "稻香", Artist: "周杰伦", Provider: "qq"})
+	favorites.Add("妈妈", FavoriteSong{ID: 2, Name: "小城故事", Artist: "邓丽君", Provider: "qq"})
+	e := &Exporter{Favorites: favorites}
+
+	var m3u bytes.Buffer
+	if err := e.Export(&m3u, ExportFavorites, FormatM3U, "小明"); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	want := "#EXTM3U\n" +
+		"#EXTINF:-1,周杰伦 - 晴天\nhttps://music.163.com/#/song?id=186016\n" +
+		"#EXTINF:-1,周杰伦 - 稻香\nhttps://y.qq.com/n/ryqq/songDetail/003OUlho2HcRHC\n"
+	if m3u.String() != want {
+		t.Errorf("M3U =\n%s\n期望\n%s", m3u.String(), want)
+	}
+
+	// 不指定用户时导出所有用户，没有网页地址的歌曲只出现在 CSV 中
+	var csv bytes.Buffer
+	if err := e.Export(&csv, ExportFavorites, FormatCSV, ""); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	out := strings.TrimPrefix(csv.String(), "\ufeff")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || lines[0] != "user,name,artist,album,provider,id,added_at" {
+		t.Fatalf("CSV =\n%s", out)
+	}
+	if !strings.Contains(out, "妈妈,小城故事,邓丽君,,qq,2,") {
+		t.Errorf("CSV 缺少妈妈的收藏:\n%s", out)
+	}
+}
+
+func TestExporter_HistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	history, err := NewHistoryStore(dir)
+	if err != nil {
+		t.Fatalf("创建播放历史失败: %v", err)
+	}
+	history.Add(Song{ID: 1, Name: "晴天", Artist: "周杰伦"})
+	history.Add(Song{ID: 1, Name: "晴天", Artist: "周杰伦"})
+	e := &Exporter{History: history}
+
+	path, err := e.ExportFile(dir, ExportHistory, FormatCSV, "")
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, "exports") || !strings.HasSuffix(path, ".csv") {
+		t.Errorf("导出路径 = %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "晴天,周杰伦,,2,") {
+		t.Errorf("CSV =\n%s", data)
+	}
+
+	if _, err := e.ExportFile(dir, ExportCache, FormatCSV, ""); err == nil {
+		t.Error("缓存未启用时应返回错误")
+	}
+	if _, err := e.ExportFile(dir, ExportHistory, "xspf", ""); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "exports")); len(entries) != 1 {
+		t.Errorf("失败的导出不应留下文件，实际 %d 个", len(entries))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,6 +22,21 @@ type FavoriteSong struct {
 	AddedAt  string `json:"added_at"`
 }
 
+// Song 转换为播放用的歌曲信息。
+func (f FavoriteSong) Song() Song {
+	extra := make(map[string]interface{})
+	if f.MID != "" {
+		extra["mid"] = f.MID
+	}
+	if f.MediaMID != "" {
+		extra["media_mid"] = f.MediaMID
+	}
+	if f.Provider != "" {
+		extra["provider"] = f.Provider
+	}
+	return Song{ID: f.ID, Name: f.Name, Artist: f.Artist, Album: f.Album, Extra: extra}
+}
+
 // FavoritesList 用户收藏列表。
 type FavoritesList struct {
 	UserName  string         `json:"user_name"`
@@ -122,6 +138,24 @@ func (s *FavoritesStore) Clear(userName string) error {
 	return s.save(list)
 }
 
+// Users 返回有收藏列表的用户名。
+func (s *FavoritesStore) Users() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.dataDir, "favorites"))
+	if err != nil {
+		return nil
+	}
+	var users []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			users = append(users, name)
+		}
+	}
+	return users
+}
+
 // load 加载用户收藏列表。
 func (s *FavoritesStore) load(userName string) (*FavoritesList, error) {
 	filePath := s.getFilePath(userName)
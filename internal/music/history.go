@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,6 +26,7 @@ type HistoryStore struct {
 	filePath string
 	entries  []HistoryEntry
 	maxSize  int // 最大历史记录数
+	paused   atomic.Bool
 }
 
 // NewHistoryStore 创建播放历史存储。
@@ -63,8 +65,16 @@ func (s *HistoryStore) save() error {
 	return os.WriteFile(s.filePath, data, 0644)
 }
 
-// Add 添加或更新播放记录。
+// SetPaused 暂停或恢复记录播放历史（访客模式下不记录）。
+func (s *HistoryStore) SetPaused(paused bool) {
+	s.paused.Store(paused)
+}
+
+// Add 添加或更新播放记录，暂停记录时忽略。
 func (s *HistoryStore) Add(song Song) error {
+	if s.paused.Load() {
+		return nil
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
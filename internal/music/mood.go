@@ -0,0 +1,127 @@
+package music
+
+import (
+	"strings"
+)
+
+// Mood 心情/风格标签。用户说"放点轻松的歌"时按标签选歌，而不是搜索歌名里带"轻松"的歌。
+type Mood struct {
+	Tag      string   // 标签名，存入 music_tags 表
+	Synonyms []string // 用户的说法
+	Queries  []string // 在音乐平台上搜索时使用的关键词（歌单、风格词），依次搜索合并结果
+}
+
+// Moods 支持的心情和风格标签。
+var Moods = []Mood{
+	{Tag: "轻松", Synonyms: []string{"轻松", "放松", "舒缓", "休闲", "治愈", "柔和", "舒服"}, Queries: []string{"轻松治愈", "舒缓 轻音乐", "小清新"}},
+	{Tag: "欢快", Synonyms: []string{"欢快", "开心", "快乐", "愉快", "动感", "有活力", "嗨", "热闹", "喜庆"}, Queries: []string{"欢快", "快乐 流行", "元气"}},
+	{Tag: "伤感", Synonyms: []string{"伤感", "悲伤", "难过", "忧伤", "emo", "失恋"}, Queries: []string{"伤感情歌", "失恋"}},
+	{Tag: "浪漫", Synonyms: []string{"浪漫", "甜蜜", "恋爱"}, Queries: []string{"甜蜜情歌", "浪漫"}},
+	{Tag: "助眠", Synonyms: []string{"助眠", "催眠", "睡前", "睡觉", "入睡", "摇篮曲"}, Queries: []string{"助眠 轻音乐", "睡前 钢琴曲", "摇篮曲"}},
+	{Tag: "专注", Synonyms: []string{"专注", "学习", "工作", "看书", "写作业"}, Queries: []string{"专注 纯音乐", "学习 白噪音"}},
+	{Tag: "运动", Synonyms: []string{"运动", "健身", "跑步", "燃"}, Queries: []string{"运动 健身", "跑步 节奏"}},
+	{Tag: "摇滚", Synonyms: []string{"摇滚", "rock"}, Queries: []string{"摇滚"}},
+	{Tag: "民谣", Synonyms: []string{"民谣"}, Queries: []string{"民谣"}},
+	{Tag: "古风", Synonyms: []string{"古风", "国风", "中国风"}, Queries: []string{"古风", "中国风"}},
+	{Tag: "纯音乐", Synonyms: []string{"纯音乐", "钢琴曲", "轻音乐", "器乐"}, Queries: []string{"纯音乐", "钢琴曲"}},
+	{Tag: "儿歌", Synonyms: []string{"儿歌", "童谣", "儿童歌曲"}, Queries: []string{"儿歌"}},
+	{Tag: "爵士", Synonyms: []string{"爵士", "jazz"}, Queries: []string{"爵士"}},
+	{Tag: "说唱", Synonyms: []string{"说唱", "rap", "嘻哈"}, Queries: []string{"说唱"}},
+	{Tag: "老歌", Synonyms: []string{"经典老歌", "老歌", "怀旧"}, Queries: []string{"经典老歌", "怀旧金曲"}},
+}
+
+// moodCues 紧跟在心情词后面、表明用户是按心情选歌的说法。
+// 没有这些说法时不当作心情，避免把《安静》《快乐崇拜》这类歌名误判为心情。
+var moodCues = []string{"的歌", "的音乐", "的曲子", "一点的", "点的", "歌曲", "音乐", "情歌", "歌", "乐"}
+
+// moodLeads 出现在心情词前面、表明用户是按心情选歌的说法，如"来点开心的"。
+var moodLeads = []string{"放点", "来点", "放些", "来些", "放一些", "来一些", "适合"}
+
+// moodFillers 心情说法前后常见的虚词，去掉后剩下的才是歌手等真正的搜索词。
+var moodFillers = []string{"放点", "来点", "放一些", "来一些", "放首", "来首", "播放", "适合", "一点", "一些", "一首", "的"}
+
+// FindMood 按标签名或说法查找心情，如 "轻松"、"放松"、"睡前"。
+func FindMood(name string) (Mood, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return Mood{}, false
+	}
+	for _, mood := range Moods {
+		if mood.Tag == name {
+			return mood, true
+		}
+		for _, syn := range mood.Synonyms {
+			if syn == name {
+				return mood, true
+			}
+		}
+	}
+	return Mood{}, false
+}
+
+// ParseMood 识别关键词中的心情/风格说法，返回标签和去掉心情说法后剩下的关键词（如歌手名）。
+// "轻松的歌" → 轻松, ""；"周杰伦的伤感情歌" → 伤感, "周杰伦"；"安静" → 不是心情。
+func ParseMood(keyword string) (Mood, string, bool) {
+	lower := strings.ToLower(keyword)
+	for _, mood := range Moods {
+		for _, syn := range mood.Synonyms {
+			i := strings.Index(lower, syn)
+			if i < 0 {
+				continue
+			}
+			before, after := lower[:i], lower[i+len(syn):]
+			cue, ok := "", false
+			for _, c := range moodCues {
+				if strings.HasPrefix(after, c) {
+					cue, ok = c, true
+					break
+				}
+			}
+			// "纯音乐"、"儿歌"这类说法本身就是风格
+			ok = ok || strings.HasSuffix(syn, "音乐") || strings.HasSuffix(syn, "歌") ||
+				strings.HasSuffix(syn, "曲") || strings.HasSuffix(syn, "谣")
+			for _, lead := range moodLeads {
+				ok = ok || strings.HasSuffix(strings.TrimSpace(before), lead)
+			}
+			if !ok {
+				continue
+			}
+			rest := before + " " + after[len(cue):]
+			for _, filler := range moodFillers {
+				rest = strings.ReplaceAll(rest, filler, " ")
+			}
+			return mood, strings.Join(strings.Fields(rest), " "), true
+		}
+	}
+	return Mood{}, keyword, false
+}
+
+// classifyHints 根据歌名、专辑名中的字样推断风格（本地分类，不调用接口）。
+var classifyHints = []struct {
+	tag   string
+	hints []string
+}{
+	{"纯音乐", []string{"纯音乐", "钢琴", "piano", "伴奏", "instrumental", "轻音乐", "八音盒"}},
+	{"助眠", []string{"助眠", "催眠", "摇篮曲", "lullaby", "白噪音", "雨声"}},
+	{"儿歌", []string{"儿歌", "童谣", "宝宝"}},
+	{"摇滚", []string{"摇滚", "rock"}},
+	{"爵士", []string{"爵士", "jazz"}},
+	{"古风", []string{"古风", "国风"}},
+	{"欢快", []string{"dj", "remix", "劲爆"}},
+	{"伤感", []string{"伤感", "眼泪", "分手"}},
+}
+
+// ClassifySong 根据歌曲元数据推断风格标签，推断不出时返回空。
+func ClassifySong(song Song) []string {
+	text := strings.ToLower(song.Name + " " + song.Album)
+	var tags []string
+	for _, h := range classifyHints {
+		for _, hint := range h.hints {
+			if strings.Contains(text, hint) {
+				tags = append(tags, h.tag)
+				break
+			}
+		}
+	}
+	return tags
+}
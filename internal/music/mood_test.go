@@ -0,0 +1,50 @@
+package music
+
+import "testing"
+
+func TestParseMood(t *testing.T) {
+	tests := []struct {
+		keyword string
+		tag     string
+		rest    string
+	}{
+		{"轻松的歌", "轻松", ""},
+		{"放点轻松的音乐", "轻松", ""},
+		{"来点开心的", "欢快", ""},
+		{"适合睡觉的歌", "助眠", ""},
+		{"周杰伦的伤感情歌", "伤感", "周杰伦"},
+		{"纯音乐", "纯音乐", ""},
+		{"经典老歌", "老歌", ""},
+		{"摇滚乐", "摇滚", ""},
+		{"安静", "", "安静"},       // 周杰伦的歌名
+		{"快乐崇拜", "", "快乐崇拜"},   // 歌名里带心情词
+		{"周杰伦晴天", "", "周杰伦晴天"}, // 普通点歌
+	}
+	for _, tt := range tests {
+		mood, rest, ok := ParseMood(tt.keyword)
+		if ok != (tt.tag != "") || mood.Tag != tt.tag || rest != tt.rest {
+			t.Errorf("ParseMood(%q) = (%q, %q, %v), want (%q, %q)", tt.keyword, mood.Tag, rest, ok, tt.tag, tt.rest)
+		}
+	}
+}
+
+func TestFindMood(t *testing.T) {
+	for name, want := range map[string]string{"轻松": "轻松", "放松": "轻松", "睡前": "助眠", "Jazz": "爵士"} {
+		if mood, ok := FindMood(name); !ok || mood.Tag != want {
+			t.Errorf("FindMood(%q) = %q, %v, want %q", name, mood.Tag, ok, want)
+		}
+	}
+	if _, ok := FindMood("周杰伦"); ok {
+		t.Error("歌手名不应识别为心情")
+	}
+}
+
+func TestClassifySong(t *testing.T) {
+	tags := ClassifySong(Song{Name: "River Flows in You (Piano)", Album: "钢琴曲精选"})
+	if len(tags) != 1 || tags[0] != "纯音乐" {
+		t.Errorf("钢琴曲应归为纯音乐，实际 %v", tags)
+	}
+	if tags := ClassifySong(Song{Name: "晴天", Album: "叶惠美"}); len(tags) != 0 {
+		t.Errorf("没有明显特征时不应打标签，实际 %v", tags)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // NeteaseClient 是网易云音乐 API 客户端。
@@ -23,6 +25,7 @@ type NeteaseClient struct {
 	cookieMu   sync.RWMutex
 	cookies    []http.Cookie
 	cookieTime time.Time
+	cookieGen  int64 // 读取 cookie 时的账号版本
 }
 
 // NewNeteaseClient 创建网易云音乐客户端。
@@ -42,7 +45,8 @@ func NewNeteaseClientWithDataDir(baseURL, dataDir string) *NeteaseClient {
 		baseURL: baseURL,
 		dataDir: dataDir,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
 	}
 }
@@ -63,17 +67,12 @@ func getDefaultDataDir() string {
 	return dataDir
 }
 
-// cookieFile 保存的 cookie 数据
-type cookieFile struct {
-	Cookies   []http.Cookie `json:"cookies"`
-	UpdatedAt time.Time     `json:"updated_at,omitempty"`
-}
-
-// loadCookies 加载 cookie（带缓存，每分钟最多读取一次文件）
+// loadCookies 加载当前账号的 cookie（带缓存，每分钟最多读取一次文件，切换账号后立即重新读取）
 func (c *NeteaseClient) loadCookies() []http.Cookie {
+	gen := accountGeneration.Load()
 	c.cookieMu.RLock()
 	// 缓存 1 分钟内有效
-	if len(c.cookies) > 0 && time.Since(c.cookieTime) < time.Minute {
+	if len(c.cookies) > 0 && c.cookieGen == gen && time.Since(c.cookieTime) < time.Minute {
 		cookies := c.cookies
 		c.cookieMu.RUnlock()
 		return cookies
@@ -85,23 +84,22 @@ func (c *NeteaseClient) loadCookies() []http.Cookie {
 	defer c.cookieMu.Unlock()
 
 	// 双重检查
-	if len(c.cookies) > 0 && time.Since(c.cookieTime) < time.Minute {
+	if len(c.cookies) > 0 && c.cookieGen == gen && time.Since(c.cookieTime) < time.Minute {
 		return c.cookies
 	}
 
-	path := filepath.Join(c.dataDir, "netease_cookie.json")
-	content, err := os.ReadFile(path)
+	data, account, err := NewAccountStore(c.dataDir).LoadActive(c.ProviderName())
 	if err != nil {
-		return nil
-	}
-
-	var data cookieFile
-	if err := json.Unmarshal(content, &data); err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("[netease] 读取账号 %s 的 cookie 失败: %v", account, err)
+		}
+		c.cookies = nil
 		return nil
 	}
 
 	c.cookies = data.Cookies
 	c.cookieTime = time.Now()
+	c.cookieGen = gen
 	return c.cookies
 }
 
@@ -211,6 +209,44 @@ func (c *NeteaseClient) Search(ctx context.Context, keyword string, limit int) (
 	return songs, nil
 }
 
+// Suggest 实现 Suggester 接口：调用搜索联想接口，返回联想到的歌曲。
+func (c *NeteaseClient) Suggest(ctx context.Context, keyword string) ([]Song, error) {
+	u := fmt.Sprintf("%s/search/suggest?keywords=%s", c.baseURL, url.QueryEscape(keyword))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("搜索联想请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("搜索联想返回错误状态码: %d", resp.StatusCode)
+	}
+
+	var suggestResp searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&suggestResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if suggestResp.Code != 200 {
+		return nil, fmt.Errorf("搜索联想失败，错误码: %d", suggestResp.Code)
+	}
+
+	songs := make([]Song, 0, len(suggestResp.Result.Songs))
+	for _, s := range suggestResp.Result.Songs {
+		artist := ""
+		if len(s.Artists) > 0 {
+			artist = s.Artists[0].Name
+		}
+		songs = append(songs, Song{ID: s.ID, Name: s.Name, Artist: artist, Album: s.Album.Name})
+	}
+	return songs, nil
+}
+
 // GetSongURL 获取歌曲播放地址。
 // 返回 URL 和是否为试听版。
 func (c *NeteaseClient) GetSongURL(ctx context.Context, songID int64) (string, error) {
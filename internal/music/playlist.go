@@ -30,6 +30,20 @@ func (m PlayMode) String() string {
 	}
 }
 
+// ParsePlayMode 解析播放模式名称（sequence/loop/single）。
+func ParsePlayMode(name string) (PlayMode, bool) {
+	switch name {
+	case "sequence":
+		return PlayModeSequence, true
+	case "loop":
+		return PlayModeLoop, true
+	case "single":
+		return PlayModeSingle, true
+	default:
+		return PlayModeSequence, false
+	}
+}
+
 // PlaylistItem 播放列表中的一项，包含歌曲信息和播放 URL。
 type PlaylistItem struct {
 	Song     Song
@@ -278,19 +292,24 @@ func (pl *Playlist) nextIndex() int {
 
 // resolveURL 为歌曲获取播放 URL（此方法不加锁，调用方应在无锁状态下调用）。
 func (pl *Playlist) resolveURL(ctx context.Context, song Song) (string, error) {
-	if pl.provider == nil {
+	return ResolveSongURL(ctx, pl.provider, song)
+}
+
+// ResolveSongURL 获取歌曲播放 URL，QQ 音乐优先使用 MID 接口。
+func ResolveSongURL(ctx context.Context, provider Provider, song Song) (string, error) {
+	if provider == nil {
 		return "", fmt.Errorf("provider not set")
 	}
 
 	// 优先使用 QQ Provider 的 MID 接口
-	if qqProvider, ok := pl.provider.(QQProvider); ok {
+	if qqProvider, ok := provider.(QQProvider); ok {
 		mid := song.GetMID()
 		if mid != "" {
 			return qqProvider.GetSongURLWithMID(ctx, song.ID, mid)
 		}
 	}
 
-	return pl.provider.GetSongURL(ctx, song.ID)
+	return provider.GetSongURL(ctx, song.ID)
 }
 
 // Info 返回播放列表的摘要信息。
@@ -28,3 +28,13 @@ type QQProvider interface {
 	Provider
 	GetSongURLWithMID(ctx context.Context, songID int64, songMID string) (string, error)
 }
+
+// Suggester 可选接口，返回搜索联想结果（歌名 + 歌手），用于纠正语音识别错误的歌名。
+type Suggester interface {
+	Suggest(ctx context.Context, keyword string) ([]Song, error)
+}
+
+// LyricProvider 可选接口，获取歌曲的 LRC 格式歌词。
+type LyricProvider interface {
+	GetLyric(ctx context.Context, song Song) (string, error)
+}
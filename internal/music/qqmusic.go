@@ -8,13 +8,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // cookieMaxAge 是 QQ 音乐 cookie 的最大有效期（经验值，通常 3 天左右会过期）。
@@ -27,11 +27,12 @@ type QQMusicClient struct {
 	httpClient *http.Client
 	dataDir    string
 
-	cookieMu       sync.RWMutex
-	cookies        []http.Cookie
-	cookieTime     time.Time
-	cookieFileTime time.Time // cookie 文件中的 updated_at
-	cookieWarned   bool      // 是否已经发过过期警告（避免重复刷屏）
+	cookieMu        sync.RWMutex
+	cookies         []http.Cookie
+	cookieTime      time.Time
+	cookieGen       int64     // 读取 cookie 时的账号版本
+	cookieExpiresAt time.Time // cookie 预计过期时间
+	cookieWarned    bool      // 是否已经发过过期警告（避免重复刷屏）
 }
 
 // NewQQMusicClient 创建 QQ 音乐客户端。
@@ -48,7 +49,8 @@ func NewQQMusicClientWithDataDir(baseURL, dataDir string) *QQMusicClient {
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		dataDir: dataDir,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
 	}
 }
@@ -56,11 +58,12 @@ func NewQQMusicClientWithDataDir(baseURL, dataDir string) *QQMusicClient {
 // ProviderName 返回提供者名称。
 func (c *QQMusicClient) ProviderName() string { return "qq" }
 
-// loadCookies 加载 QQ 音乐 cookie（带缓存，每分钟最多读取一次文件）。
-// 会检测 cookie 年龄，超过 cookieMaxAge 时打印警告日志。
+// loadCookies 加载当前账号的 QQ 音乐 cookie（带缓存，每分钟最多读取一次文件，切换账号后立即重新读取）。
+// 会校验 cookie 完整性，并在超过预计过期时间时打印警告日志。
 func (c *QQMusicClient) loadCookies() []http.Cookie {
+	gen := accountGeneration.Load()
 	c.cookieMu.RLock()
-	if len(c.cookies) > 0 && time.Since(c.cookieTime) < time.Minute {
+	if len(c.cookies) > 0 && c.cookieGen == gen && time.Since(c.cookieTime) < time.Minute {
 		cookies := c.cookies
 		c.cookieMu.RUnlock()
 		return cookies
@@ -71,39 +74,38 @@ func (c *QQMusicClient) loadCookies() []http.Cookie {
 	defer c.cookieMu.Unlock()
 
 	// 双重检查
-	if len(c.cookies) > 0 && time.Since(c.cookieTime) < time.Minute {
+	if len(c.cookies) > 0 && c.cookieGen == gen && time.Since(c.cookieTime) < time.Minute {
 		return c.cookies
 	}
 
-	path := filepath.Join(c.dataDir, "qq_cookie.json")
-	content, err := os.ReadFile(path)
+	store := NewAccountStore(c.dataDir)
+	data, account, err := store.LoadActive("qq")
 	if err != nil {
+		c.cookies = nil
 		if !c.cookieWarned {
-			logger.Warnf("[qqmusic] 未找到 cookie 文件 %s，请先运行 pibuddy-music qq login 登录", path)
+			if os.IsNotExist(err) {
+				logger.Warnf("[qqmusic] 未找到 cookie 文件 %s，请先运行 pibuddy-music qq login 登录", store.CookiePath("qq", account))
+			} else {
+				logger.Warnf("[qqmusic] 读取账号 %s 的 cookie 失败: %v，请运行 pibuddy-music qq login 重新登录", account, err)
+			}
 			c.cookieWarned = true
 		}
 		return nil
 	}
 
-	var data cookieFile
-	if err := json.Unmarshal(content, &data); err != nil {
-		logger.Warnf("[qqmusic] 解析 cookie 文件失败: %v", err)
-		return nil
-	}
-
 	c.cookies = data.Cookies
 	c.cookieTime = time.Now()
-	c.cookieFileTime = data.UpdatedAt
+	c.cookieGen = gen
+	c.cookieExpiresAt = data.ExpiresAt
 
-	// 检测 cookie 年龄
-	if !data.UpdatedAt.IsZero() && time.Since(data.UpdatedAt) > cookieMaxAge {
-		age := time.Since(data.UpdatedAt).Round(time.Hour)
+	if data.Expired() {
 		if !c.cookieWarned {
-			logger.Warnf("[qqmusic] QQ 音乐 cookie 已使用 %v，可能已过期，请运行 pibuddy-music qq login --web 重新登录", age)
+			age := time.Since(data.UpdatedAt).Round(time.Hour)
+			logger.Warnf("[qqmusic] 账号 %s 的 cookie 已使用 %v，可能已过期，请运行 pibuddy-music qq login --web 重新登录", account, age)
 			c.cookieWarned = true
 		}
 	} else {
-		// cookie 被更新了（比如重新登录），重置警告标记
+		// cookie 被更新了（比如重新登录或切换账号），重置警告标记
 		c.cookieWarned = false
 	}
 
@@ -139,7 +141,7 @@ func (c *QQMusicClient) cookieExpiredHint() string {
 	if len(c.cookies) == 0 {
 		return "（未登录，请运行 pibuddy-music qq login --web 登录）"
 	}
-	if !c.cookieFileTime.IsZero() && time.Since(c.cookieFileTime) > cookieMaxAge {
+	if !c.cookieExpiresAt.IsZero() && time.Now().After(c.cookieExpiresAt) {
 		return "（cookie 可能已过期，请运行 pibuddy-music qq login --web 重新登录）"
 	}
 	return ""
@@ -235,6 +237,57 @@ func (c *QQMusicClient) Search(ctx context.Context, keyword string, limit int) (
 	return songs, nil
 }
 
+// qqQuickSearchResult 快速搜索（联想）结果。
+type qqQuickSearchResult struct {
+	Result int `json:"result"`
+	Data   struct {
+		Song struct {
+			ItemList []struct {
+				ID     string `json:"id"`
+				MID    string `json:"mid"`
+				Name   string `json:"name"`
+				Singer string `json:"singer"`
+			} `json:"itemlist"`
+		} `json:"song"`
+	} `json:"data"`
+}
+
+// Suggest 实现 Suggester 接口：调用快速搜索接口，返回联想到的歌曲。
+func (c *QQMusicClient) Suggest(ctx context.Context, keyword string) ([]Song, error) {
+	apiURL := fmt.Sprintf("%s/search/quick?key=%s", c.baseURL, url.QueryEscape(keyword))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 QQ 音乐 API 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result qqQuickSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Result != 100 {
+		return nil, fmt.Errorf("QQ 音乐 API 返回错误: result=%d", result.Result)
+	}
+
+	songs := make([]Song, 0, len(result.Data.Song.ItemList))
+	for _, item := range result.Data.Song.ItemList {
+		id, _ := strconv.ParseInt(item.ID, 10, 64)
+		songs = append(songs, Song{
+			ID:     id,
+			Name:   item.Name,
+			Artist: item.Singer,
+			Extra:  map[string]interface{}{"mid": item.MID},
+		})
+	}
+	return songs, nil
+}
+
 // GetSongURL 实现 Provider 接口：获取歌曲播放地址。
 func (c *QQMusicClient) GetSongURL(ctx context.Context, songID int64) (string, error) {
 	// QQMusicApi 获取歌曲 URL 接口，id 参数传 songmid
@@ -0,0 +1,366 @@
+package music
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
+)
+
+const (
+	qqWebAPIURL      = "https://u.y.qq.com/cgi-bin/musics.fcg"
+	qqWebSmartboxURL = "https://c.y.qq.com/splcloud/fcgi-bin/smartbox_new.fcg"
+	qqWebUserAgent   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// QQWebClient 内置的轻量 QQ 音乐客户端，直接调用 QQ 音乐网页接口（带 sign 签名），
+// 无需部署 QQMusicApi node 服务。登录 cookie 与 QQMusicClient 共用 qq_cookie.json。
+type QQWebClient struct {
+	apiURL      string
+	smartboxURL string
+	httpClient  *http.Client
+	guid        string
+
+	// session 复用 QQMusicClient 的 cookie 加载与过期检测
+	session *QQMusicClient
+}
+
+// NewQQWebClient 创建内置 QQ 音乐客户端。dataDir 为 cookie 所在的数据目录。
+func NewQQWebClient(dataDir string) *QQWebClient {
+	return &QQWebClient{
+		apiURL:      qqWebAPIURL,
+		smartboxURL: qqWebSmartboxURL,
+		httpClient: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
+		},
+		guid:    strconv.FormatInt(1000000000+rand.Int63n(9000000000), 10),
+		session: NewQQMusicClientWithDataDir("", dataDir),
+	}
+}
+
+// ProviderName 返回提供者名称。与 QQMusicClient 相同，收藏和历史记录可以互通。
+func (c *QQWebClient) ProviderName() string { return "qq" }
+
+var (
+	qqSignPart1Indexes = []int{23, 14, 6, 36, 16, 7, 19}
+	qqSignPart2Indexes = []int{16, 1, 32, 12, 19, 27, 8, 5}
+	qqSignScramble     = []byte{89, 39, 179, 150, 218, 82, 58, 252, 177, 52, 186, 123, 120, 64, 242, 133, 143, 161, 121, 179}
+	qqSignB64Stripper  = strings.NewReplacer("/", "", "+", "", "=", "")
+)
+
+// qqSign 计算 musics.fcg 请求的 sign 参数（zzb 签名）。
+// 对请求体做 SHA1，按固定下标取十六进制字符，中间段为摘要与固定序列异或后的 base64。
+func qqSign(body []byte) string {
+	sum := sha1.Sum(body)
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var sb strings.Builder
+	sb.WriteString("zzb")
+	for _, i := range qqSignPart1Indexes {
+		sb.WriteByte(h[i])
+	}
+
+	scrambled := make([]byte, len(qqSignScramble))
+	for i, v := range qqSignScramble {
+		scrambled[i] = v ^ sum[i]
+	}
+	sb.WriteString(qqSignB64Stripper.Replace(base64.StdEncoding.EncodeToString(scrambled)))
+
+	for _, i := range qqSignPart2Indexes {
+		sb.WriteByte(h[i])
+	}
+	return strings.ToLower(sb.String())
+}
+
+// loginInfo 从 cookie 中取出 uin 和 musickey，未登录时返回空字符串。
+func (c *QQWebClient) loginInfo() (uin, key string) {
+	for _, cookie := range c.session.loadCookies() {
+		switch cookie.Name {
+		case "uin":
+			uin = strings.TrimLeft(strings.TrimLeft(cookie.Value, "o"), "0")
+		case "qqmusic_key", "qm_keyst":
+			if key == "" {
+				key = cookie.Value
+			}
+		}
+	}
+	return uin, key
+}
+
+// call 调用 musics.fcg 的单个模块方法，将 data 字段解析到 out。
+func (c *QQWebClient) call(ctx context.Context, module, method string, param map[string]interface{}, out interface{}) error {
+	uin, key := c.loginInfo()
+	comm := map[string]interface{}{
+		"ct":     24,
+		"cv":     0,
+		"format": "json",
+		"uin":    uin,
+	}
+	if key != "" {
+		comm["authst"] = key
+		comm["tmeLoginType"] = 2
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"comm": comm,
+		"req_0": map[string]interface{}{
+			"module": module,
+			"method": method,
+			"param":  param,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s?_=%d&sign=%s", c.apiURL, time.Now().UnixMilli(), qqSign(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Referer", "https://y.qq.com/")
+	req.Header.Set("User-Agent", qqWebUserAgent)
+	if cookie := c.session.cookieHeader(); cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 QQ 音乐失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var result struct {
+		Code int `json:"code"`
+		Req  struct {
+			Code int             `json:"code"`
+			Data json.RawMessage `json:"data"`
+		} `json:"req_0"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != 0 || result.Req.Code != 0 {
+		return fmt.Errorf("QQ 音乐接口 %s.%s 返回错误: code=%d/%d%s",
+			module, method, result.Code, result.Req.Code, c.session.cookieExpiredHint())
+	}
+	if err := json.Unmarshal(result.Req.Data, out); err != nil {
+		return fmt.Errorf("解析 %s.%s 数据失败: %w", module, method, err)
+	}
+	return nil
+}
+
+// qqWebTrack 网页接口返回的歌曲信息。
+type qqWebTrack struct {
+	ID     int64  `json:"id"`
+	MID    string `json:"mid"`
+	Name   string `json:"name"`
+	Singer []struct {
+		Name string `json:"name"`
+	} `json:"singer"`
+	Album struct {
+		Name string `json:"name"`
+	} `json:"album"`
+	File struct {
+		MediaMID string `json:"media_mid"`
+	} `json:"file"`
+}
+
+// toSong 转换为统一的 Song 结构。
+func (t qqWebTrack) toSong() Song {
+	var artists []string
+	for _, s := range t.Singer {
+		artists = append(artists, s.Name)
+	}
+	mediaMid := t.File.MediaMID
+	if mediaMid == "" {
+		mediaMid = t.MID
+	}
+	return Song{
+		ID:     t.ID,
+		Name:   t.Name,
+		Artist: strings.Join(artists, "/"),
+		Album:  t.Album.Name,
+		Extra: map[string]interface{}{
+			"mid":       t.MID,
+			"media_mid": mediaMid,
+		},
+	}
+}
+
+// Search 实现 Provider 接口：根据关键词搜索歌曲。
+func (c *QQWebClient) Search(ctx context.Context, keyword string, limit int) ([]Song, error) {
+	var data struct {
+		Body struct {
+			Song struct {
+				List []qqWebTrack `json:"list"`
+			} `json:"song"`
+		} `json:"body"`
+	}
+	err := c.call(ctx, "music.search.SearchCgiService", "DoSearchForQQMusicDesktop", map[string]interface{}{
+		"query":        keyword,
+		"search_type":  0,
+		"num_per_page": limit,
+		"page_num":     1,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make([]Song, 0, len(data.Body.Song.List))
+	for _, t := range data.Body.Song.List {
+		songs = append(songs, t.toSong())
+	}
+	logger.Debugf("[qqmusic] 内置客户端搜索 '%s' 返回 %d 首歌曲", keyword, len(songs))
+	return songs, nil
+}
+
+// Suggest 实现 Suggester 接口：调用 smartbox 联想接口。
+func (c *QQWebClient) Suggest(ctx context.Context, keyword string) ([]Song, error) {
+	apiURL := fmt.Sprintf("%s?format=json&key=%s", c.smartboxURL, url.QueryEscape(keyword))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Referer", "https://y.qq.com/")
+	req.Header.Set("User-Agent", qqWebUserAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 QQ 音乐失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// smartbox 与 QQMusicApi /search/quick 的 data 结构相同，仅外层用 code 表示状态
+	var result struct {
+		Code int `json:"code"`
+		qqQuickSearchResult
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("QQ 音乐联想接口返回错误: code=%d", result.Code)
+	}
+
+	songs := make([]Song, 0, len(result.Data.Song.ItemList))
+	for _, item := range result.Data.Song.ItemList {
+		id, _ := strconv.ParseInt(item.ID, 10, 64)
+		songs = append(songs, Song{
+			ID:     id,
+			Name:   item.Name,
+			Artist: item.Singer,
+			Extra:  map[string]interface{}{"mid": item.MID},
+		})
+	}
+	return songs, nil
+}
+
+// songDetail 根据歌曲 ID 查询 mid 和 media_mid。
+func (c *QQWebClient) songDetail(ctx context.Context, songID int64) (qqWebTrack, error) {
+	var data struct {
+		TrackInfo qqWebTrack `json:"track_info"`
+	}
+	err := c.call(ctx, "music.pf_song_detail_svr", "get_song_detail_yqq", map[string]interface{}{
+		"song_id": songID,
+	}, &data)
+	if err != nil {
+		return qqWebTrack{}, err
+	}
+	if data.TrackInfo.MID == "" {
+		return qqWebTrack{}, fmt.Errorf("未找到歌曲 %d", songID)
+	}
+	return data.TrackInfo, nil
+}
+
+// GetSongURL 实现 Provider 接口：先查询歌曲 mid，再获取播放地址。
+func (c *QQWebClient) GetSongURL(ctx context.Context, songID int64) (string, error) {
+	track, err := c.songDetail(ctx, songID)
+	if err != nil {
+		return "", err
+	}
+	mediaMid := track.File.MediaMID
+	if mediaMid == "" {
+		mediaMid = track.MID
+	}
+	return c.songURL(ctx, track.MID, mediaMid)
+}
+
+// GetSongURLWithMID 实现 QQProvider 接口：使用 songMID 获取播放地址。
+func (c *QQWebClient) GetSongURLWithMID(ctx context.Context, songID int64, songMID string) (string, error) {
+	return c.songURL(ctx, songMID, songMID)
+}
+
+// songURL 调用 vkey 接口获取 128k MP3 播放地址。
+func (c *QQWebClient) songURL(ctx context.Context, songMID, mediaMID string) (string, error) {
+	uin, _ := c.loginInfo()
+	var data struct {
+		Sip        []string `json:"sip"`
+		MidURLInfo []struct {
+			PURL string `json:"purl"`
+		} `json:"midurlinfo"`
+	}
+	err := c.call(ctx, "vkey.GetVkeyServer", "CgiGetVkey", map[string]interface{}{
+		"filename":  []string{"M500" + songMID + mediaMID + ".mp3"},
+		"guid":      c.guid,
+		"songmid":   []string{songMID},
+		"songtype":  []int{0},
+		"uin":       uin,
+		"loginflag": 1,
+		"platform":  "20",
+	}, &data)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data.MidURLInfo) == 0 || data.MidURLInfo[0].PURL == "" {
+		return "", fmt.Errorf("无法获取歌曲播放地址，可能是 VIP 歌曲%s", c.session.cookieExpiredHint())
+	}
+	sip := "https://ws.stream.qqmusic.qq.com/"
+	if len(data.Sip) > 0 && data.Sip[0] != "" {
+		sip = data.Sip[0]
+	}
+	return sip + data.MidURLInfo[0].PURL, nil
+}
+
+// GetLyric 实现 LyricProvider 接口：获取 LRC 格式歌词。
+func (c *QQWebClient) GetLyric(ctx context.Context, song Song) (string, error) {
+	var data struct {
+		Lyric string `json:"lyric"`
+	}
+	err := c.call(ctx, "music.musichallSong.PlayLyricInfo", "GetPlayLyricInfo", map[string]interface{}{
+		"songMID": song.GetMID(),
+		"songID":  song.ID,
+	}, &data)
+	if err != nil {
+		return "", err
+	}
+	if data.Lyric == "" {
+		return "", fmt.Errorf("未找到歌词")
+	}
+
+	lyric, err := base64.StdEncoding.DecodeString(data.Lyric)
+	if err != nil {
+		return "", fmt.Errorf("解码歌词失败: %w", err)
+	}
+	return string(lyric), nil
+}
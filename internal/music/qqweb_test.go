@@ -0,0 +1,161 @@
+package music
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQQSign(t *testing.T) {
+	body := []byte(`{"comm":{"ct":24},"req_0":{"module":"m","method":"f","param":{}}}`)
+	sign := qqSign(body)
+
+	if !strings.HasPrefix(sign, "zzb") {
+		t.Errorf("sign 应以 zzb 开头: %s", sign)
+	}
+	if sign != strings.ToLower(sign) {
+		t.Errorf("sign 应为小写: %s", sign)
+	}
+	if strings.ContainsAny(sign, "/+=") {
+		t.Errorf("sign 不应包含 base64 特殊字符: %s", sign)
+	}
+	if qqSign(body) != sign {
+		t.Error("相同请求体的 sign 应一致")
+	}
+	if qqSign([]byte(`{}`)) == sign {
+		t.Error("不同请求体的 sign 应不同")
+	}
+}
+
+// newQQWebTestServer 模拟 musics.fcg，按 module 返回 data，并校验 sign。
+func newQQWebTestServer(t *testing.T, data map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.URL.Query().Get("sign"), qqSign(body); got != want {
+			t.Errorf("sign = %s, want %s", got, want)
+		}
+
+		var req struct {
+			Req struct {
+				Module string `json:"module"`
+			} `json:"req_0"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("解析请求失败: %v", err)
+		}
+		d, ok := data[req.Req.Module]
+		if !ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "req_0": map[string]interface{}{"code": 2000}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":  0,
+			"req_0": map[string]interface{}{"code": 0, "data": d},
+		})
+	}))
+}
+
+func newTestQQWebClient(t *testing.T, apiURL string) *QQWebClient {
+	c := NewQQWebClient(t.TempDir())
+	c.apiURL = apiURL
+	return c
+}
+
+func TestQQWebSearch(t *testing.T) {
+	srv := newQQWebTestServer(t, map[string]interface{}{
+		"music.search.SearchCgiService": map[string]interface{}{
+			"body": map[string]interface{}{
+				"song": map[string]interface{}{
+					"list": []map[string]interface{}{{
+						"id":     102065756,
+						"mid":    "003OUlho2HcRHC",
+						"name":   "晴天",
+						"singer": []map[string]string{{"name": "周杰伦"}},
+						"album":  map[string]string{"name": "叶惠美"},
+						"file":   map[string]string{"media_mid": "0039MnYb0qxYhV"},
+					}},
+				},
+			},
+		},
+	})
+	defer srv.Close()
+
+	songs, err := newTestQQWebClient(t, srv.URL).Search(context.Background(), "晴天", 5)
+	if err != nil {
+		t.Fatalf("Search 失败: %v", err)
+	}
+	if len(songs) != 1 {
+		t.Fatalf("期望 1 首歌曲，实际 %d", len(songs))
+	}
+	s := songs[0]
+	if s.ID != 102065756 || s.Name != "晴天" || s.Artist != "周杰伦" || s.Album != "叶惠美" {
+		t.Errorf("歌曲信息不正确: %+v", s)
+	}
+	if s.GetMID() != "003OUlho2HcRHC" || s.GetMediaMID() != "0039MnYb0qxYhV" {
+		t.Errorf("mid 不正确: %+v", s.Extra)
+	}
+}
+
+func TestQQWebSongURL(t *testing.T) {
+	srv := newQQWebTestServer(t, map[string]interface{}{
+		"vkey.GetVkeyServer": map[string]interface{}{
+			"sip":        []string{"http://ws.example.com/"},
+			"midurlinfo": []map[string]string{{"purl": "M500abc.mp3?vkey=x"}},
+		},
+	})
+	defer srv.Close()
+
+	u, err := newTestQQWebClient(t, srv.URL).GetSongURLWithMID(context.Background(), 1, "abc")
+	if err != nil {
+		t.Fatalf("GetSongURLWithMID 失败: %v", err)
+	}
+	if u != "http://ws.example.com/M500abc.mp3?vkey=x" {
+		t.Errorf("播放地址不正确: %s", u)
+	}
+}
+
+func TestQQWebSongURLNoRights(t *testing.T) {
+	srv := newQQWebTestServer(t, map[string]interface{}{
+		"vkey.GetVkeyServer": map[string]interface{}{
+			"midurlinfo": []map[string]string{{"purl": ""}},
+		},
+	})
+	defer srv.Close()
+
+	_, err := newTestQQWebClient(t, srv.URL).GetSongURLWithMID(context.Background(), 1, "abc")
+	if err == nil || !strings.Contains(err.Error(), "VIP") {
+		t.Errorf("无播放权限时应提示 VIP: %v", err)
+	}
+}
+
+func TestQQWebLyric(t *testing.T) {
+	lrc := "[00:01.00]故事的小黄花"
+	srv := newQQWebTestServer(t, map[string]interface{}{
+		"music.musichallSong.PlayLyricInfo": map[string]string{
+			"lyric": base64.StdEncoding.EncodeToString([]byte(lrc)),
+		},
+	})
+	defer srv.Close()
+
+	got, err := newTestQQWebClient(t, srv.URL).GetLyric(context.Background(), Song{ID: 1, Extra: map[string]interface{}{"mid": "abc"}})
+	if err != nil {
+		t.Fatalf("GetLyric 失败: %v", err)
+	}
+	if got != lrc {
+		t.Errorf("歌词 = %q, want %q", got, lrc)
+	}
+}
+
+func TestQQWebAPIError(t *testing.T) {
+	srv := newQQWebTestServer(t, nil)
+	defer srv.Close()
+
+	if _, err := newTestQQWebClient(t, srv.URL).Search(context.Background(), "晴天", 5); err == nil {
+		t.Error("接口返回错误码时应报错")
+	}
+}
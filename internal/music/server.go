@@ -0,0 +1,268 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+const (
+	healthCheckTimeout  = 3 * time.Second
+	defaultStartTimeout = 30 * time.Second
+	restartBackoff      = 5 * time.Second
+)
+
+// HealthChecker 可选接口，检查音乐 API 服务是否可用。
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// checkAPIHealth 请求 API 根路径，只要服务有 HTTP 响应（非 5xx）即视为可用。
+func checkAPIHealth(ctx context.Context, client *http.Client, baseURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/", nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("音乐 API 服务 %s 无法连接: %w", baseURL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("音乐 API 服务 %s 异常: HTTP %d", baseURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// HealthCheck 实现 HealthChecker 接口。
+func (c *NeteaseClient) HealthCheck(ctx context.Context) error {
+	return checkAPIHealth(ctx, c.httpClient, c.baseURL)
+}
+
+// HealthCheck 实现 HealthChecker 接口。
+func (c *QQMusicClient) HealthCheck(ctx context.Context) error {
+	return checkAPIHealth(ctx, c.httpClient, c.baseURL)
+}
+
+// ServerOptions 托管音乐 API 服务的启动参数。
+type ServerOptions struct {
+	Managed         bool          // 是否由 PiBuddy 启动并守护服务
+	Command         string        // 启动命令，如 "node app.js"
+	Dir             string        // 工作目录
+	Env             []string      // 额外环境变量 KEY=VALUE
+	DockerContainer string        // Docker 容器名，设置后使用 docker start/restart 管理
+	StartTimeout    time.Duration // 等待服务就绪的超时，默认 30 秒
+}
+
+// APIServer 检查音乐 API 服务（NeteaseCloudMusicApi / QQMusicApi）的健康状态，
+// 在托管模式下负责启动、守护和重启服务进程或 Docker 容器。
+type APIServer struct {
+	name    string
+	checker HealthChecker
+	opts    ServerOptions
+
+	startMu  sync.Mutex // 串行化启动流程，避免并发调用互相杀掉刚启动的进程
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	exited   chan struct{} // 当前进程退出时关闭
+	stopping bool
+	lastErr  error
+}
+
+// NewAPIServer 创建音乐 API 服务管理器。name 用于日志和提示，如 "QQ 音乐"。
+func NewAPIServer(name string, checker HealthChecker, opts ServerOptions) *APIServer {
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = defaultStartTimeout
+	}
+	return &APIServer{name: name, checker: checker, opts: opts}
+}
+
+// Managed 返回是否为托管模式。
+func (s *APIServer) Managed() bool {
+	return s.opts.Managed && (s.opts.Command != "" || s.opts.DockerContainer != "")
+}
+
+// Check 执行一次健康检查。
+func (s *APIServer) Check(ctx context.Context) error {
+	err := s.checker.HealthCheck(ctx)
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	return err
+}
+
+// LastError 返回最近一次健康检查的错误，nil 表示服务正常。
+func (s *APIServer) LastError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// EnsureRunning 确保服务可用：健康检查失败时，托管模式下尝试（重新）启动并等待就绪。
+// 非托管模式下返回带操作提示的错误。
+func (s *APIServer) EnsureRunning(ctx context.Context) error {
+	err := s.Check(ctx)
+	if err == nil {
+		return nil
+	}
+	if !s.Managed() {
+		return fmt.Errorf("%s服务未运行，请先启动音乐 API 服务: %w", s.name, err)
+	}
+
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+	// 等锁期间其他调用方可能已把服务拉起
+	if err = s.Check(ctx); err == nil {
+		return nil
+	}
+
+	logger.Warnf("[music] %s API 服务不可用，尝试启动: %v", s.name, err)
+	if err := s.start(ctx); err != nil {
+		return fmt.Errorf("启动%s服务失败: %w", s.name, err)
+	}
+	return s.waitReady(ctx)
+}
+
+// Stop 停止托管的服务进程（Docker 容器保持运行，避免影响其他用途）。
+func (s *APIServer) Stop() {
+	s.mu.Lock()
+	s.stopping = true
+	cmd := s.cmd
+	exited := s.exited
+	s.mu.Unlock()
+
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	logger.Infof("[music] 正在停止 %s API 服务 (pid %d)", s.name, cmd.Process.Pid)
+	_ = cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+	}
+}
+
+// start 启动服务：Docker 容器执行 docker start（已运行则 restart），否则启动子进程。
+func (s *APIServer) start(ctx context.Context) error {
+	if s.opts.DockerContainer != "" {
+		action := "start"
+		if out, err := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", s.opts.DockerContainer).Output(); err == nil &&
+			strings.TrimSpace(string(out)) == "true" {
+			action = "restart" // 容器在运行但服务无响应
+		}
+		out, err := exec.CommandContext(ctx, "docker", action, s.opts.DockerContainer).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("docker %s %s: %v %s", action, s.opts.DockerContainer, err, strings.TrimSpace(string(out)))
+		}
+		logger.Infof("[music] 已执行 docker %s %s", action, s.opts.DockerContainer)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cmd != nil {
+		// 进程仍在运行但服务无响应，先结束它，由守护协程重新拉起
+		select {
+		case <-s.exited:
+		default:
+			logger.Warnf("[music] %s API 进程无响应，强制重启", s.name)
+			_ = s.cmd.Process.Kill()
+			return nil
+		}
+	}
+	return s.spawnLocked()
+}
+
+// spawnLocked 启动子进程并开启守护协程，调用方需持有锁。
+func (s *APIServer) spawnLocked() error {
+	fields := strings.Fields(s.opts.Command)
+	if len(fields) == 0 {
+		return fmt.Errorf("未配置启动命令")
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Dir = s.opts.Dir
+	cmd.Env = append(os.Environ(), s.opts.Env...)
+	cmd.Stdout = outputLogger{}
+	cmd.Stderr = outputLogger{}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	s.cmd = cmd
+	s.exited = make(chan struct{})
+	s.stopping = false
+	logger.Infof("[music] 已启动 %s API 服务 (pid %d): %s", s.name, cmd.Process.Pid, s.opts.Command)
+
+	go s.supervise(cmd, s.exited)
+	return nil
+}
+
+// supervise 等待进程退出，非主动停止时延迟后自动重启。
+func (s *APIServer) supervise(cmd *exec.Cmd, exited chan struct{}) {
+	err := cmd.Wait()
+	close(exited)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		logger.Infof("[music] %s API 服务已停止", s.name)
+		return
+	}
+	logger.Warnf("[music] %s API 服务意外退出: %v，%s 后重启", s.name, err, restartBackoff)
+
+	time.AfterFunc(restartBackoff, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stopping || s.cmd != cmd {
+			return
+		}
+		if err := s.spawnLocked(); err != nil {
+			logger.Errorf("[music] 重启 %s API 服务失败: %v", s.name, err)
+		}
+	})
+}
+
+// outputLogger 将服务输出转发到 debug 日志。
+type outputLogger struct{}
+
+func (outputLogger) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line != "" {
+			logger.Debugf("[music-api] %s", line)
+		}
+	}
+	return len(p), nil
+}
+
+// waitReady 轮询健康检查直到服务就绪或超时。
+func (s *APIServer) waitReady(ctx context.Context) error {
+	deadline := time.Now().Add(s.opts.StartTimeout)
+	for {
+		if err := s.Check(ctx); err == nil {
+			logger.Infof("[music] %s API 服务已就绪", s.name)
+			return nil
+		} else if time.Now().After(deadline) {
+			return fmt.Errorf("%s服务在 %s 内未就绪: %w", s.name, s.opts.StartTimeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
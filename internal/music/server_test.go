@@ -0,0 +1,144 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHealthCheck(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound) // 根路径 404 也说明服务在运行
+	}))
+	defer ok.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	if err := NewNeteaseClient(ok.URL).HealthCheck(context.Background()); err != nil {
+		t.Errorf("服务正常时不应报错: %v", err)
+	}
+	if err := NewQQMusicClient(broken.URL).HealthCheck(context.Background()); err == nil {
+		t.Error("5xx 应视为异常")
+	}
+
+	// 关闭后无法连接
+	url := ok.URL
+	ok.Close()
+	if err := NewNeteaseClient(url).HealthCheck(context.Background()); err == nil {
+		t.Error("服务关闭后应报错")
+	}
+}
+
+func TestAPIServerEnsureRunningUnmanaged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	client := NewQQMusicClient(url)
+	server := NewAPIServer("QQ 音乐", client, ServerOptions{})
+
+	if err := server.EnsureRunning(context.Background()); err != nil {
+		t.Fatalf("服务正常时不应报错: %v", err)
+	}
+	if server.LastError() != nil {
+		t.Error("LastError 应为 nil")
+	}
+
+	srv.Close()
+	err := server.EnsureRunning(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "QQ 音乐服务未运行") {
+		t.Errorf("非托管模式应返回提示: %v", err)
+	}
+	if server.LastError() == nil {
+		t.Error("LastError 应记录失败")
+	}
+}
+
+func TestAPIServerManaged(t *testing.T) {
+	tests := []struct {
+		opts ServerOptions
+		want bool
+	}{
+		{ServerOptions{}, false},
+		{ServerOptions{Managed: true}, false}, // 未配置启动方式
+		{ServerOptions{Managed: true, Command: "node app.js"}, true},
+		{ServerOptions{Managed: true, DockerContainer: "qqmusic"}, true},
+		{ServerOptions{Command: "node app.js"}, false},
+	}
+	for _, tt := range tests {
+		s := NewAPIServer("test", NewNeteaseClient(""), tt.opts)
+		if got := s.Managed(); got != tt.want {
+			t.Errorf("Managed(%+v) = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+// procChecker 在托管进程存在时视为健康；未启动时阻塞到 n 个调用方都检查失败，
+// 确保并发调用同时进入启动流程。
+type procChecker struct {
+	server *APIServer
+	n      int
+
+	mu      sync.Mutex
+	failed  int
+	arrived chan struct{}
+}
+
+func (c *procChecker) HealthCheck(ctx context.Context) error {
+	c.server.mu.Lock()
+	started := c.server.cmd != nil
+	c.server.mu.Unlock()
+	if started {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.failed++
+	if c.failed == c.n {
+		close(c.arrived)
+	}
+	c.mu.Unlock()
+	select {
+	case <-c.arrived:
+	case <-time.After(time.Second):
+	}
+	return errors.New("未启动")
+}
+
+func TestAPIServerEnsureRunningConcurrent(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("需要 sleep 命令")
+	}
+	const callers = 4
+	checker := &procChecker{n: callers, arrived: make(chan struct{})}
+	server := NewAPIServer("test", checker, ServerOptions{Managed: true, Command: "sleep 30"})
+	checker.server = server
+	defer server.Stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.EnsureRunning(context.Background()); err != nil {
+				t.Errorf("EnsureRunning: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server.mu.Lock()
+	exited := server.exited
+	server.mu.Unlock()
+	select {
+	case <-exited:
+		t.Fatal("并发启动不应杀掉已启动的进程")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,230 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 答题关闹钟：闹钟响起时出一道口算或常识题，用户答对才关闭；答错或一直没回答时，
+// 过 snooze 秒后换一道题再响，最多再响 max_snoozes 次。闹钟响着时用户说的第一句话都当作答案。
+
+// alarmQuestion 一道关闹钟的题目。
+type alarmQuestion struct {
+	text    string
+	number  int      // 数字答案，answers 为空时使用
+	answers []string // 文字答案，包含其中之一即算答对
+}
+
+// alarmTrivia 常识题。
+var alarmTrivia = []alarmQuestion{
+	{text: "一个星期有几天？", number: 7},
+	{text: "一年有几个月？", number: 12},
+	{text: "一天有多少个小时？", number: 24},
+	{text: "一个小时有多少分钟？", number: 60},
+	{text: "蜘蛛有几条腿？", number: 8},
+	{text: "彩虹有几种颜色？", number: 7},
+	{text: "中国的首都是哪个城市？", answers: []string{"北京"}},
+	{text: "太阳从哪个方向升起？", answers: []string{"东"}},
+}
+
+// newAlarmQuestion 按题目类型（math、trivia、mixed）出一道题。
+func newAlarmQuestion(kind string, rng *rand.Rand) alarmQuestion {
+	if kind == "trivia" || (kind == "mixed" && rng.Intn(2) == 0) {
+		return alarmTrivia[rng.Intn(len(alarmTrivia))]
+	}
+	if rng.Intn(2) == 0 {
+		a, b := 11+rng.Intn(39), 11+rng.Intn(39)
+		return alarmQuestion{text: fmt.Sprintf("%d 加 %d 等于几？", a, b), number: a + b}
+	}
+	a, b := 3+rng.Intn(7), 3+rng.Intn(7)
+	return alarmQuestion{text: fmt.Sprintf("%d 乘 %d 等于几？", a, b), number: a * b}
+}
+
+// correct 判断回答是否正确。
+func (q alarmQuestion) correct(reply string) bool {
+	if len(q.answers) > 0 {
+		for _, a := range q.answers {
+			if strings.Contains(reply, a) {
+				return true
+			}
+		}
+		return false
+	}
+	n, ok := parseSpokenNumber(reply)
+	return ok && n == q.number
+}
+
+// answer 正确答案的说法。
+func (q alarmQuestion) answer() string {
+	if len(q.answers) > 0 {
+		return q.answers[0]
+	}
+	return strconv.Itoa(q.number)
+}
+
+// spokenDigits 中文数字。
+var spokenDigits = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '幺': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// parseSpokenNumber 取出回答中的第一个数（0-999），支持阿拉伯数字和"七十一"、"一百零五"这样的中文读法。
+func parseSpokenNumber(text string) (int, bool) {
+	runes := []rune(text)
+	for i, r := range runes {
+		if r >= '0' && r <= '9' {
+			j := i
+			for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(string(runes[i:j]))
+			return n, err == nil
+		}
+		if _, ok := spokenDigits[r]; ok || r == '十' || r == '百' {
+			return parseChineseNumber(runes[i:])
+		}
+	}
+	return 0, false
+}
+
+// parseChineseNumber 解析开头的中文数字，遇到其他字符时停止。
+func parseChineseNumber(runes []rune) (int, bool) {
+	total, digit, seen := 0, -1, false
+	for _, r := range runes {
+		switch {
+		case r == '百':
+			if digit < 0 {
+				digit = 1
+			}
+			total += digit * 100
+			digit = -1
+		case r == '十':
+			if digit < 0 {
+				digit = 1
+			}
+			total += digit * 10
+			digit = -1
+		default:
+			d, ok := spokenDigits[r]
+			if !ok {
+				if digit > 0 {
+					total += digit
+				}
+				return total, seen
+			}
+			digit = d
+		}
+		seen = true
+	}
+	if digit > 0 {
+		total += digit
+	}
+	return total, seen
+}
+
+// alarmQuiz 一个需要答题才能关掉的闹钟。
+type alarmQuiz struct {
+	message  string
+	question alarmQuestion
+	snoozes  int
+	timer    *time.Timer
+}
+
+// ringAlarm 闹钟到期：需要答题的闹钟出题，其他闹钟直接播报。
+func (p *Pipeline) ringAlarm(a tools.AlarmEntry) {
+	if !a.Challenge {
+		p.announceReminder(fmt.Sprintf("闹钟提醒: %s", a.Message))
+		return
+	}
+	p.alarmQuizMu.Lock()
+	if p.alarmQuiz != nil {
+		// 已经有闹钟在等待答题，合并提醒内容
+		p.alarmQuiz.message += "；" + a.Message
+		p.alarmQuizMu.Unlock()
+		return
+	}
+	quiz := &alarmQuiz{message: a.Message}
+	p.alarmQuiz = quiz
+	p.alarmQuizMu.Unlock()
+	p.ringAlarmQuiz(quiz)
+}
+
+// ringAlarmQuiz 换一道题响铃，并在 snooze 后检查是否已答对。
+func (p *Pipeline) ringAlarmQuiz(quiz *alarmQuiz) {
+	cfg := p.cfg.Tools.Alarm.Challenge
+	p.alarmQuizMu.Lock()
+	quiz.question = newAlarmQuestion(cfg.Kind, rand.New(rand.NewSource(time.Now().UnixNano())))
+	text := fmt.Sprintf("闹钟提醒: %s。答对这道题才能关掉闹钟：%s", quiz.message, quiz.question.text)
+	p.resetAlarmQuizTimer(quiz)
+	p.alarmQuizMu.Unlock()
+
+	logger.Infof("[pipeline] 答题闹钟响起（第 %d 次）: %s", quiz.snoozes+1, quiz.question.text)
+	p.announceReminder(text)
+}
+
+// resetAlarmQuizTimer 重新开始 snooze 计时，调用方需持有 alarmQuizMu。
+func (p *Pipeline) resetAlarmQuizTimer(quiz *alarmQuiz) {
+	if quiz.timer != nil {
+		quiz.timer.Stop()
+	}
+	quiz.timer = time.AfterFunc(time.Duration(p.cfg.Tools.Alarm.Challenge.Snooze)*time.Second, func() {
+		p.snoozeAlarmQuiz(quiz)
+	})
+}
+
+// snoozeAlarmQuiz snooze 到时仍未答对，再响一次。
+func (p *Pipeline) snoozeAlarmQuiz(quiz *alarmQuiz) {
+	p.alarmQuizMu.Lock()
+	if p.alarmQuiz != quiz {
+		p.alarmQuizMu.Unlock()
+		return
+	}
+	if quiz.snoozes >= p.cfg.Tools.Alarm.Challenge.MaxSnoozes {
+		p.alarmQuiz = nil
+		p.alarmQuizMu.Unlock()
+		logger.Info("[pipeline] 答题闹钟一直没有答对，已达到最多再响次数")
+		return
+	}
+	quiz.snoozes++
+	p.alarmQuizMu.Unlock()
+	p.ringAlarmQuiz(quiz)
+}
+
+// answerAlarmQuiz 闹钟等待答题时，把用户说的话当作答案：答对关闭闹钟，答错 snooze 后再响。
+// 没有等待答题的闹钟时返回 false。
+func (p *Pipeline) answerAlarmQuiz(ctx context.Context, text string) bool {
+	p.alarmQuizMu.Lock()
+	quiz := p.alarmQuiz
+	if quiz == nil {
+		p.alarmQuizMu.Unlock()
+		return false
+	}
+	var reply string
+	if quiz.question.correct(text) {
+		quiz.timer.Stop()
+		p.alarmQuiz = nil
+		reply = "答对了，闹钟已关闭。"
+		logger.Infof("[pipeline] 答题闹钟答对了: %s", text)
+	} else {
+		p.resetAlarmQuizTimer(quiz)
+		reply = fmt.Sprintf("不对哦，答案是%s。%d 分钟后闹钟再响。", quiz.question.answer(), (p.cfg.Tools.Alarm.Challenge.Snooze+59)/60)
+		logger.Infof("[pipeline] 答题闹钟答错了: %s", text)
+	}
+	p.alarmQuizMu.Unlock()
+
+	p.ackReminder()
+	p.stopContinuousTimer()
+	p.state.SetState(StateSpeaking)
+	go func() {
+		p.speakText(ctx, reply)
+		p.state.ForceIdle()
+	}()
+	return true
+}
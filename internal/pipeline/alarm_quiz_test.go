@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParseSpokenNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+		ok   bool
+	}{
+		{"56", 56, true},
+		{"答案是 72 吧", 72, true},
+		{"七", 7, true},
+		{"十二", 12, true},
+		{"二十四", 24, true},
+		{"是六十三", 63, true},
+		{"一百", 100, true},
+		{"一百零五", 105, true},
+		{"两百二十", 220, true},
+		{"不知道", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSpokenNumber(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseSpokenNumber(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAlarmQuestion(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		q := newAlarmQuestion("math", rng)
+		if len(q.answers) > 0 || q.number <= 0 {
+			t.Fatalf("math question %q should have a numeric answer", q.text)
+		}
+		if !q.correct(q.answer()) {
+			t.Errorf("%q: answer %s should be correct", q.text, q.answer())
+		}
+		if q.correct("不知道") {
+			t.Errorf("%q: no number should be wrong", q.text)
+		}
+	}
+
+	capital := alarmQuestion{text: "中国的首都是哪个城市？", answers: []string{"北京"}}
+	if !capital.correct("是北京") || capital.correct("上海") {
+		t.Error("text answers should match by substring")
+	}
+	week := alarmQuestion{text: "一个星期有几天？", number: 7}
+	if !week.correct("七天") || week.correct("八天") {
+		t.Error("numeric answers should accept Chinese numerals")
+	}
+}
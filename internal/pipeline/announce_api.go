@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 远程播报：门铃、NAS 告警、3D 打印机等家里的其他服务通过 POST /api/announce 让 PiBuddy 播报一段文字。
+// 通知和闹钟、整点报时一样经过播报队列：对话中等对话结束再播，按优先级排队；免打扰时段只播 urgent。
+
+// maxAnnounceRunes 一条远程播报最多多少字。
+const maxAnnounceRunes = 500
+
+// announceRequest POST /api/announce 的请求体。
+type announceRequest struct {
+	Text     string `json:"text"`
+	Priority string `json:"priority,omitempty"` // low、normal（默认）、urgent
+	Voice    string `json:"voice,omitempty"`    // 临时使用的发音人，TTS 引擎不支持更换时忽略
+	Source   string `json:"source,omitempty"`   // 来源（如 doorbell），用于日志
+}
+
+// parseAnnouncePriority 把请求中的优先级转换为播报队列的优先级。
+func parseAnnouncePriority(s string) (speechPriority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return speechLow, nil
+	case "", "normal":
+		return speechNormal, nil
+	case "urgent", "high":
+		return speechUrgent, nil
+	default:
+		return 0, fmt.Errorf("优先级 %q 无效，可选: low、normal、urgent", s)
+	}
+}
+
+// handleAnnounce 远程播报：请求体为 {"text":"有人按门铃","priority":"urgent","voice":"","source":"doorbell"}。
+// 通知加入播报队列后立即返回 202；免打扰时段内的非 urgent 通知不播报，返回 200 和跳过原因。
+// 接口通过 HandleAuthRequired 注册，没有配置 admin.token 时不可用。
+func (p *Pipeline) handleAnnounce(w http.ResponseWriter, r *http.Request) {
+	var req announceRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
+		admin.WriteError(w, http.StatusBadRequest, fmt.Sprintf("请求格式错误: %v", err))
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		admin.WriteError(w, http.StatusBadRequest, "text 不能为空")
+		return
+	}
+	if utf8.RuneCountInString(req.Text) > maxAnnounceRunes {
+		admin.WriteError(w, http.StatusBadRequest, fmt.Sprintf("text 不能超过 %d 字", maxAnnounceRunes))
+		return
+	}
+	priority, err := parseAnnouncePriority(req.Priority)
+	if err != nil {
+		admin.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	source := req.Source
+	if source == "" {
+		source = "远程播报"
+	}
+
+	cfg := p.cfg.Admin.Announce
+	if priority < speechUrgent && tools.InQuietHours(time.Now(), cfg.QuietHours.Start, cfg.QuietHours.End) {
+		logger.Infof("[pipeline] 免打扰时段，跳过 %s 的播报: %s", source, req.Text)
+		admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"queued":  false,
+			"message": "免打扰时段，只播报 urgent 优先级的通知",
+		})
+		return
+	}
+
+	logger.Infof("[pipeline] 收到 %s 的播报请求: %s", source, req.Text)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.MaxAge)*time.Second)
+		defer cancel()
+		spoken := p.announceWith(ctx, source, priority, func(ctx context.Context) {
+			if req.Voice != "" {
+				restore := p.useVoice(req.Voice)
+				defer restore()
+			}
+			p.speakText(ctx, req.Text)
+		})
+		if !spoken {
+			logger.Infof("[pipeline] %s 的播报没有播出: %s", source, req.Text)
+		}
+	}()
+	admin.WriteJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"queued":  true,
+	})
+}
+
+// useVoice 临时换成 voice 发音人，返回恢复为当前人设和回复语言发音人的函数。
+// 回复语言配置了专门的发音人（如英语）时会盖过 voice，因此播报期间临时切回默认语言。
+func (p *Pipeline) useVoice(voice string) (restore func()) {
+	p.setTTSLanguage("")
+	p.setTTSVoice(voice)
+	return func() {
+		pc, _ := p.findPersona(p.currentPersona())
+		p.setTTSVoice(pc.Voice)
+		p.setTTSLanguage(p.replyLanguage())
+	}
+}
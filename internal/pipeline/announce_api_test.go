@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+func TestHandleAnnounceValidation(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{}}
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"text":"   "}`, http.StatusBadRequest},
+		{`{"text":"有人按门铃","priority":"asap"}`, http.StatusBadRequest},
+		{`{"text":"` + strings.Repeat("长", maxAnnounceRunes+1) + `"}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		p.handleAnnounce(rec, httptest.NewRequest("POST", "/api/announce", strings.NewReader(tt.body)))
+		if rec.Code != tt.want {
+			t.Errorf("body %.40q: status = %d, want %d", tt.body, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleAnnounceQuietHours(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Admin.Announce.QuietHours = config.QuietHoursConfig{Start: "00:00", End: "24:00"}
+	p := &Pipeline{cfg: cfg}
+
+	rec := httptest.NewRecorder()
+	p.handleAnnounce(rec, httptest.NewRequest("POST", "/api/announce", strings.NewReader(`{"text":"打印完成","source":"printer"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body struct {
+		Success bool `json:"success"`
+		Queued  bool `json:"queued"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if !body.Success || body.Queued {
+		t.Errorf("normal notifications should be skipped during quiet hours, got %+v", body)
+	}
+}
+
+func TestParseAnnouncePriority(t *testing.T) {
+	for s, want := range map[string]speechPriority{"": speechNormal, "low": speechLow, "Urgent": speechUrgent, "high": speechUrgent} {
+		if got, err := parseAnnouncePriority(s); err != nil || got != want {
+			t.Errorf("parseAnnouncePriority(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+}
+
+// voiceEngine 记录发音人和语言切换的假 TTS 引擎。
+type voiceEngine struct {
+	tts.Engine
+	voice, lang string
+}
+
+func (e *voiceEngine) SetVoice(voice string)   { e.voice = voice }
+func (e *voiceEngine) SetLanguage(lang string) { e.lang = lang }
+
+func TestUseVoiceOverridesLanguageVoice(t *testing.T) {
+	engine := &voiceEngine{}
+	p := &Pipeline{cfg: &config.Config{}, ttsEngine: engine}
+	p.setReplyLanguage("en")
+
+	restore := p.useVoice("zh-CN-YunxiNeural")
+	if engine.voice != "zh-CN-YunxiNeural" || engine.lang != "" {
+		t.Errorf("announce voice should override the language voice, got voice=%q lang=%q", engine.voice, engine.lang)
+	}
+	restore()
+	if engine.voice != "" || engine.lang != "en" {
+		t.Errorf("restore should bring back the persona voice and reply language, got voice=%q lang=%q", engine.voice, engine.lang)
+	}
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// asrBudgetSaveStep 用量每增加这么多秒保存一次，避免每帧都写数据库。
+const asrBudgetSaveStep = 10
+
+// asrBudget 每日云端语音识别用量（秒），保存在设备设置中，重启后继续累计。
+type asrBudget struct {
+	limit    float64
+	settings *database.Settings
+
+	mu    sync.Mutex
+	day   string
+	used  float64
+	saved float64
+}
+
+// newASRBudget 创建用量统计，读取今天已用的秒数。
+func newASRBudget(limit int, settings *database.Settings, now time.Time) *asrBudget {
+	b := &asrBudget{limit: float64(limit), settings: settings, day: now.Format("2006-01-02")}
+	// 保存格式："2006-01-02 秒数"
+	var day string
+	var used float64
+	if _, err := fmt.Sscanf(settings.GetString(database.SettingASRCloudUsage, ""), "%s %f", &day, &used); err == nil && day == b.day {
+		b.used, b.saved = used, used
+	}
+	return b
+}
+
+// exhausted 今天的额度是否已经用完。
+func (b *asrBudget) exhausted(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover(now)
+	return b.used >= b.limit
+}
+
+// add 累计用量，本次调用使额度用完时返回 true（每天只返回一次）。
+func (b *asrBudget) add(now time.Time, seconds float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rollover(now)
+	wasExhausted := b.used >= b.limit
+	b.used += seconds
+	exhausted := b.used >= b.limit
+	if b.used-b.saved >= asrBudgetSaveStep || exhausted != wasExhausted {
+		b.save()
+	}
+	return exhausted && !wasExhausted
+}
+
+// rollover 跨天时清零，调用方需持有锁。
+func (b *asrBudget) rollover(now time.Time) {
+	if day := now.Format("2006-01-02"); day != b.day {
+		b.day, b.used, b.saved = day, 0, 0
+	}
+}
+
+// save 保存用量，调用方需持有锁。
+func (b *asrBudget) save() {
+	b.saved = b.used
+	if err := b.settings.SetString(database.SettingASRCloudUsage, fmt.Sprintf("%s %.1f", b.day, b.used)); err != nil {
+		logger.Warnf("[pipeline] 保存云端识别用量失败: %v", err)
+	}
+}
+
+// nextMidnight now 之后的第一个零点。
+func nextMidnight(now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+}
+
+// initASRBudget 配置了每日云端识别额度时开始统计，今天的额度已用完则直接固定使用本地识别。
+func (p *Pipeline) initASRBudget() {
+	fallback, ok := p.recognizer.(*asr.FallbackEngine)
+	if p.cfg.ASR.DailyBudget <= 0 || !ok {
+		return
+	}
+	now := time.Now()
+	p.asrBudget = newASRBudget(p.cfg.ASR.DailyBudget, p.settings, now)
+	if p.asrBudget.exhausted(now) {
+		fallback.PinLocal(nextMidnight(now))
+		logger.Infof("[pipeline] 今天的云端语音识别额度（%d 秒）已用完，使用本地识别", p.cfg.ASR.DailyBudget)
+	}
+}
+
+// chargeASRBudget 统计送入在线引擎的音频时长，额度用完后当天剩余时间固定使用本地识别，
+// 避免一直停在聆听状态等异常情况产生意外费用。
+func (p *Pipeline) chargeASRBudget(samples int) {
+	if p.asrBudget == nil {
+		return
+	}
+	fallback, ok := p.recognizer.(*asr.FallbackEngine)
+	if !ok || fallback.Pinned() {
+		return
+	}
+	now := time.Now()
+	if p.asrBudget.add(now, float64(samples)/float64(p.cfg.Audio.SampleRate)) {
+		fallback.PinLocal(nextMidnight(now))
+		p.asrBudgetNotice.Store(true)
+		logger.Warnf("[pipeline] 今天的云端语音识别额度（%d 秒）已用完，今天剩余时间使用本地识别", p.cfg.ASR.DailyBudget)
+	}
+}
+
+// announceASRBudget 额度用完后的下一次对话先告诉用户改用了本地识别，只说一次。
+func (p *Pipeline) announceASRBudget(ctx context.Context) {
+	if !p.asrBudgetNotice.CompareAndSwap(true, false) {
+		return
+	}
+	text := "今天的云端语音识别用量已达上限，今天剩下的时间改用本地识别，可能会听得不太准。"
+	p.state.Transition(StateSpeaking)
+	p.speakText(ctx, text)
+	p.state.SetState(StateProcessing)
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func newTestSettings(t *testing.T) *database.Settings {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return database.NewSettings(db)
+}
+
+func TestASRBudget(t *testing.T) {
+	settings := newTestSettings(t)
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.Local)
+
+	b := newASRBudget(60, settings, now)
+	if b.add(now, 30) || b.exhausted(now) {
+		t.Fatal("budget should not be exhausted after 30s")
+	}
+	if !b.add(now, 35) {
+		t.Fatal("add should report exhaustion once")
+	}
+	if b.add(now, 5) {
+		t.Error("exhaustion should only be reported once a day")
+	}
+
+	// 重启后继续累计
+	if !newASRBudget(60, settings, now).exhausted(now) {
+		t.Error("usage should survive restart")
+	}
+
+	// 第二天清零
+	tomorrow := now.Add(6 * time.Hour)
+	if b.exhausted(tomorrow) || newASRBudget(60, settings, tomorrow).exhausted(tomorrow) {
+		t.Error("budget should reset the next day")
+	}
+}
+
+func TestNextMidnight(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 59, 0, 0, time.Local)
+	if got := nextMidnight(now); !got.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("nextMidnight = %v", got)
+	}
+}
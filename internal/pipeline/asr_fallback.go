@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// initASRFallbackNotice 监听兜底引擎的自动切换：记录日志和使用统计，
+// 开启 asr.announce_fallback 时在降级后的下一次对话提示一次，恢复云端识别后取消提示。
+func (p *Pipeline) initASRFallbackNotice() {
+	fallback, ok := p.recognizer.(*asr.FallbackEngine)
+	if !ok {
+		return
+	}
+	fallback.SetOnSwitch(func(from, to asr.EngineType, reason string) {
+		if from.IsOnline() && !to.IsOnline() {
+			logger.Warnf("[pipeline] 云端语音识别不可用（%s），已切换到离线识别 %s", reason, to)
+			p.usage.Add(tools.UsageASRFallback, 1)
+			if p.cfg.ASR.AnnounceFallback {
+				p.asrFallbackNotice.Store(true)
+			}
+			return
+		}
+		if to.IsOnline() && !from.IsOnline() {
+			logger.Infof("[pipeline] 云端语音识别已恢复: %s", to)
+			p.asrFallbackNotice.Store(false)
+		}
+	})
+}
+
+// announceASRFallback 云端识别降级后的下一次对话先告诉用户，只说一次。
+func (p *Pipeline) announceASRFallback(ctx context.Context) {
+	if !p.asrFallbackNotice.CompareAndSwap(true, false) {
+		return
+	}
+	p.state.Transition(StateSpeaking)
+	p.speakText(ctx, "云端识别暂不可用，已切换到离线识别，可能会听得不太准。")
+	p.state.SetState(StateProcessing)
+}
+
+// handleASRStatus 返回当前使用的语音识别引擎和自动切换记录。
+func (p *Pipeline) handleASRStatus(w http.ResponseWriter, r *http.Request) {
+	fallback, ok := p.recognizer.(*asr.FallbackEngine)
+	if !ok {
+		admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"current": p.recognizer.Name(),
+		})
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"status":  fallback.Status(),
+	})
+}
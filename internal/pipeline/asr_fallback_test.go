@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+// fakeASR 测试用本地识别引擎（相当于 sherpa），endpoint 控制是否检测到端点。
+type fakeASR struct {
+	endpoint bool
+	result   string
+}
+
+func (f *fakeASR) Feed(samples []float32) {}
+func (f *fakeASR) GetResult() string      { return f.result }
+func (f *fakeASR) IsEndpoint() bool       { return f.endpoint }
+func (f *fakeASR) Reset()                 {}
+func (f *fakeASR) Close()                 {}
+func (f *fakeASR) Name() string           { return "fake" }
+
+// fakeOnlineASR 测试用在线批处理引擎，status 控制是否可用。
+type fakeOnlineASR struct {
+	fakeASR
+	status asr.EngineStatus
+}
+
+func (f *fakeOnlineASR) Status() asr.EngineStatus { return f.status }
+func (f *fakeOnlineASR) TriggerRecognize()        {}
+
+func TestASRFallbackNotice(t *testing.T) {
+	online := &fakeOnlineASR{status: asr.StatusAvailable}
+	local := &fakeASR{endpoint: true, result: "你好"}
+	engine := asr.NewFallbackEngine(asr.FallbackConfig{
+		Engines:          []asr.Engine{online, local},
+		EngineTypes:      []asr.EngineType{asr.EngineTencentFlash, asr.EngineSherpa},
+		RecoveryInterval: time.Nanosecond,
+	})
+	p := &Pipeline{
+		cfg:        &config.Config{ASR: config.ASRConfig{AnnounceFallback: true}},
+		recognizer: engine,
+	}
+	p.initASRFallbackNotice()
+
+	// 在线引擎识别时不可用：自动切换到本地引擎并记下提示
+	online.status = asr.StatusUnavailable
+	engine.IsEndpoint()
+	if got := engine.GetResult(); got != "你好" {
+		t.Fatalf("GetResult = %q", got)
+	}
+	if !p.asrFallbackNotice.Load() {
+		t.Fatal("fallback to local should set notice")
+	}
+	status := engine.Status()
+	if status.Current != asr.EngineSherpa || !status.Degraded || status.Switches != 1 {
+		t.Errorf("status = %+v", status)
+	}
+
+	// 恢复云端识别后取消提示
+	online.status = asr.StatusAvailable
+	time.Sleep(time.Millisecond)
+	engine.Feed(nil)
+	if p.asrFallbackNotice.Load() {
+		t.Error("recovery should clear notice")
+	}
+	if engine.CurrentType() != asr.EngineTencentFlash {
+		t.Errorf("current = %s", engine.CurrentType())
+	}
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+// isBirthday 判断 birthday（MM-DD 或 YYYY-MM-DD）是否为 now 这一天。
+// 2 月 29 日出生的人在平年的 2 月 28 日过生日。
+func isBirthday(birthday string, now time.Time) bool {
+	birthday = strings.TrimSpace(birthday)
+	if len(birthday) == len("2006-01-02") {
+		birthday = birthday[5:]
+	}
+	t, err := time.Parse("2006-01-02", "2000-"+birthday)
+	if err != nil {
+		return false
+	}
+	month, day := t.Month(), t.Day()
+	if month == time.February && day == 29 && !isLeapYear(now.Year()) {
+		day = 28
+	}
+	return now.Month() == month && now.Day() == day
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// birthdayPreferences 返回用户的偏好，今天是其生日且没有关闭祝福时 ok 为 true。
+func (p *Pipeline) birthdayPreferences(name string, now time.Time) (prefs voiceprint.UserPreferences, ok bool) {
+	user, err := p.voiceprintMgr.GetUser(name)
+	if err != nil || user.Preferences == "" {
+		return prefs, false
+	}
+	if err := json.Unmarshal([]byte(user.Preferences), &prefs); err != nil {
+		return prefs, false
+	}
+	return prefs, !prefs.NoCelebration && isBirthday(prefs.Birthday, now)
+}
+
+// celebrateBirthday 当前说话人今天过生日、且今年还没祝福过时，先播放祝福曲目再送上祝福，
+// 祝福语加入对话上下文，之后再回答用户的问题。
+func (p *Pipeline) celebrateBirthday(ctx context.Context) {
+	cfg := p.cfg.Tools.Celebration
+	if !cfg.Enabled || p.voiceprintMgr == nil {
+		return
+	}
+	name := p.contextManager.GetCurrentSpeaker()
+	if name == "" {
+		return
+	}
+	now := time.Now()
+	prefs, ok := p.birthdayPreferences(name, now)
+	if !ok {
+		return
+	}
+	key := database.SettingBirthdayCelebrated + ":" + name
+	if p.settings.GetInt(key, 0) == now.Year() {
+		return
+	}
+	if err := p.settings.SetInt(key, now.Year()); err != nil {
+		logger.Warnf("[pipeline] 保存生日祝福记录失败: %v", err)
+	}
+
+	nickname := prefs.Nickname
+	if nickname == "" {
+		nickname = name
+	}
+	greeting := strings.ReplaceAll(cfg.Greeting, "{name}", nickname)
+	logger.Infof("[pipeline] 今天是 %s 的生日: %s", name, greeting)
+
+	p.state.Transition(StateSpeaking)
+	if cfg.Track != "" {
+		if _, err := os.Stat(cfg.Track); err != nil {
+			logger.Warnf("[pipeline] 生日祝福曲目不可用: %v", err)
+		} else {
+			p.playClip(ctx, cfg.Track)
+		}
+	}
+	if ctx.Err() == nil && !p.interrupted.Load() {
+		p.speakText(ctx, greeting)
+		p.contextManager.Add("assistant", greeting)
+	}
+	p.state.SetState(StateProcessing)
+}
+
+// playClip 播放一段本地 MP3，可以像语音播报一样被唤醒打断。
+func (p *Pipeline) playClip(ctx context.Context, path string) {
+	clipCtx, cancel := context.WithCancel(ctx)
+	p.speakMu.Lock()
+	p.cancelSpeak = cancel
+	p.speakMu.Unlock()
+
+	defer func() {
+		cancel()
+		p.speakMu.Lock()
+		p.cancelSpeak = nil
+		p.speakMu.Unlock()
+	}()
+
+	if err := p.playback.PlayClip(clipCtx, path); err != nil && clipCtx.Err() == nil {
+		logger.Warnf("[pipeline] 播放 %s 失败: %v", path, err)
+	}
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsBirthday(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.Local) }
+	tests := []struct {
+		birthday string
+		now      time.Time
+		want     bool
+	}{
+		{"05-20", day(2026, 5, 20), true},
+		{"1990-05-20", day(2026, 5, 20), true},
+		{"05-20", day(2026, 5, 21), false},
+		{"02-29", day(2026, 2, 28), true}, // 平年在 2 月 28 日过
+		{"02-29", day(2028, 2, 28), false},
+		{"02-29", day(2028, 2, 29), true},
+		{"", day(2026, 5, 20), false},
+		{"五月二十", day(2026, 5, 20), false},
+	}
+	for _, tt := range tests {
+		if got := isBirthday(tt.birthday, tt.now); got != tt.want {
+			t.Errorf("isBirthday(%q, %s) = %v, want %v", tt.birthday, tt.now.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// challengeRequired 判断工具调用执行前是否需要复核声纹（voiceprint.challenge.tools）。
+// 参数中 confirm 为 false 的调用不会真正执行操作（如开锁前的确认），不需要复核。
+func (p *Pipeline) challengeRequired(name, args string) bool {
+	if !slices.Contains(p.cfg.Voiceprint.Challenge.Tools, name) {
+		return false
+	}
+	var a struct {
+		Confirm *bool `json:"confirm"`
+	}
+	if json.Unmarshal([]byte(args), &a) == nil && a.Confirm != nil && !*a.Confirm {
+		return false
+	}
+	return true
+}
+
+// verifyOwnerVoice 提示主人说一句话，录音后按复核阈值验证是否是主人本人。
+// 未启用声纹或没有主人时无法验证，一律拒绝。返回是否通过和拒绝原因。
+func (p *Pipeline) verifyOwnerVoice(ctx context.Context) (bool, string) {
+	cfg := p.cfg.Voiceprint.Challenge
+	if p.voiceprintMgr == nil {
+		return false, "没有启用声纹识别，无法验证身份"
+	}
+	owner, err := p.voiceprintMgr.GetOwner()
+	if err != nil || owner == nil {
+		return false, "还没有设置主人声纹，无法验证身份"
+	}
+
+	p.state.Transition(StateSpeaking)
+	p.speakText(ctx, cfg.Prompt)
+	p.state.SetState(StateProcessing)
+	if ctx.Err() != nil || p.interrupted.Load() {
+		return false, "已取消"
+	}
+
+	// 复用远程注册的录音会话：录音期间麦克风帧不做唤醒检测
+	session := &enrollSession{frames: make(chan []float32, 256)}
+	p.challengeMu.Lock()
+	p.challenge = session
+	p.challengeMu.Unlock()
+	defer func() {
+		p.challengeMu.Lock()
+		p.challenge = nil
+		p.challengeMu.Unlock()
+	}()
+
+	p.playCue(ctx)
+	recorded := session.record(ctx, time.Duration(cfg.Seconds*float64(time.Second)))
+	if len(recorded) < p.cfg.Audio.SampleRate/2 {
+		return false, "没有听清，请重新操作"
+	}
+
+	ok, score, err := p.voiceprintMgr.Verify(owner.Name, recorded, cfg.Threshold)
+	if err != nil {
+		logger.Warnf("[pipeline] 声纹复核失败: %v", err)
+		return false, "声纹验证出错，请稍后再试"
+	}
+	logger.Infof("[pipeline] 声纹复核 %s: 通过=%v (估算相似度: ~%.2f, 阈值: %.2f)", owner.Name, ok, score, cfg.Threshold)
+	if !ok {
+		return false, "声纹验证未通过，只有主人本人可以执行这个操作"
+	}
+	return true, ""
+}
+
+// activeChallenge 返回正在录音的声纹复核，没有时返回 nil。
+func (p *Pipeline) activeChallenge() *enrollSession {
+	p.challengeMu.Lock()
+	defer p.challengeMu.Unlock()
+	return p.challenge
+}
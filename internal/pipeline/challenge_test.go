@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+func TestChallengeRequired(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Voiceprint.Challenge.Tools = []string{"ezviz_open_door"}
+	p := &Pipeline{cfg: cfg}
+
+	tests := []struct {
+		name, args string
+		want       bool
+	}{
+		{"ezviz_open_door", `{"confirm":true}`, true},
+		{"ezviz_open_door", `{}`, true},
+		{"ezviz_open_door", `{"confirm":false}`, false},
+		{"ha_control_device", `{"entity_id":"light.living"}`, false},
+	}
+	for _, tt := range tests {
+		if got := p.challengeRequired(tt.name, tt.args); got != tt.want {
+			t.Errorf("challengeRequired(%s, %s) = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyOwnerVoice_NoVoiceprint(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{}}
+	if ok, reason := p.verifyOwnerVoice(context.Background()); ok || reason == "" {
+		t.Errorf("verifyOwnerVoice without voiceprint = %v, %q, want refused with a reason", ok, reason)
+	}
+}
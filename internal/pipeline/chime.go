@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// chimeDigits 报时用的中文数字。
+var chimeDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九", "十"}
+
+// chineseNumber 把 0-59 转成中文读法，如 15 →"十五"、30 →"三十"。
+func chineseNumber(n int) string {
+	switch {
+	case n <= 10:
+		return chimeDigits[n]
+	case n < 20:
+		return "十" + chimeDigits[n-10]
+	case n%10 == 0:
+		return chimeDigits[n/10] + "十"
+	}
+	return chimeDigits[n/10] + "十" + chimeDigits[n%10]
+}
+
+// chimeHour 12 小时制的点数，0 点和 12 点都算 12 下。
+func chimeHour(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 {
+		return h
+	}
+	return 12
+}
+
+// chimeText 报时的说法，如"现在是下午三点"、"现在是上午九点半"、"现在是晚上八点零五分"。
+func chimeText(t time.Time) string {
+	var period string
+	switch h := t.Hour(); {
+	case h == 0:
+		period = "半夜"
+	case h < 5:
+		period = "凌晨"
+	case h < 9:
+		period = "早上"
+	case h < 12:
+		period = "上午"
+	case h < 13:
+		period = "中午"
+	case h < 18:
+		period = "下午"
+	default:
+		period = "晚上"
+	}
+
+	hour := chineseNumber(chimeHour(t))
+	if chimeHour(t) == 2 {
+		hour = "两"
+	}
+	text := "现在是" + period + hour + "点"
+	switch m := t.Minute(); {
+	case m == 0:
+	case m == 30:
+		text += "半"
+	case m < 10:
+		text += "零" + chineseNumber(m) + "分"
+	default:
+		text += chineseNumber(m) + "分"
+	}
+	return text
+}
+
+// chimeSampleRate 钟声的采样率。
+const chimeSampleRate = 16000
+
+// chimeSamples 钟声：敲 strikes 下，每下是带泛音、逐渐衰减的钟鸣，间隔 1.5 秒，最后一下余音更长。
+func chimeSamples(strikes int) []float32 {
+	const (
+		interval = 1.5 // 秒
+		tail     = 2.5 // 最后一下的余音（秒）
+		freq     = 523.25
+	)
+	partials := []struct{ ratio, amp float64 }{{1, 0.5}, {2, 0.2}, {2.76, 0.12}, {5.4, 0.05}}
+
+	total := int(chimeSampleRate * (interval*float64(strikes-1) + tail))
+	out := make([]float32, total)
+	for s := 0; s < strikes; s++ {
+		start := int(chimeSampleRate * interval * float64(s))
+		for i := start; i < total; i++ {
+			t := float64(i-start) / chimeSampleRate
+			env := math.Exp(-2.5 * t)
+			if i-start < 80 {
+				env *= float64(i-start) / 80 // 起音淡入，避免爆音
+			}
+			var v float64
+			for _, p := range partials {
+				v += p.amp * math.Exp(-p.ratio*0.6*t) * math.Sin(2*math.Pi*freq*p.ratio*t)
+			}
+			out[i] += float32(0.6 * env * v)
+		}
+	}
+	return out
+}
+
+// announceChime 整点报时。免打扰时段和睡前模式不报时；正在听音乐时把音乐压低而不是停止，报完恢复。
+// 对话中的报时由调度器推迟到对话结束后。
+func (p *Pipeline) announceChime(ctx context.Context) {
+	cfg := p.cfg.Tools.Chime
+	now := time.Now()
+	if tools.InQuietHours(now, cfg.QuietHours.Start, cfg.QuietHours.End) {
+		logger.Debug("[pipeline] 免打扰时段，跳过整点报时")
+		return
+	}
+	if p.sleepAidGate() != nil {
+		logger.Debug("[pipeline] 睡前模式中，跳过整点报时")
+		return
+	}
+
+	text := chimeText(now)
+	p.announceWith(ctx, "整点报时", speechNormal, func(ctx context.Context) {
+		if p.playback.Playing() {
+			p.playback.Duck(cfg.DuckVolume)
+			defer p.playback.Duck(100)
+		}
+		logger.Infof("[pipeline] 整点报时: %s", text)
+		if cfg.Style == "chime" || cfg.Style == "both" {
+			p.playSamples(ctx, chimeSamples(chimeHour(now)), chimeSampleRate)
+		}
+		if cfg.Style != "chime" && ctx.Err() == nil {
+			p.speakText(ctx, text)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChimeText(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 16, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{at(15, 0), "现在是下午三点"},
+		{at(14, 0), "现在是下午两点"},
+		{at(9, 30), "现在是上午九点半"},
+		{at(20, 5), "现在是晚上八点零五分"},
+		{at(12, 0), "现在是中午十二点"},
+		{at(0, 0), "现在是半夜十二点"},
+		{at(7, 45), "现在是早上七点四十五分"},
+		{at(22, 10), "现在是晚上十点十分"},
+	}
+	for _, tt := range tests {
+		if got := chimeText(tt.t); got != tt.want {
+			t.Errorf("chimeText(%s) = %q, want %q", tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestChimeSamples(t *testing.T) {
+	samples := chimeSamples(3)
+	if want := int(chimeSampleRate * (1.5*2 + 2.5)); len(samples) != want {
+		t.Fatalf("len = %d, want %d", len(samples), want)
+	}
+	for i, v := range samples {
+		if v > 1 || v < -1 {
+			t.Fatalf("samples[%d] = %f 超出范围", i, v)
+		}
+	}
+}
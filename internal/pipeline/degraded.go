@@ -0,0 +1,188 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// llmRetryInterval 主模型请求失败后，这段时间内的对话直接走降级模式，避免每句话都等网络超时。
+const llmRetryInterval = time.Minute
+
+// localLLMHistory 发给本地小模型的最近消息条数（小模型上下文短，也不支持工具调用）。
+const localLLMHistory = 6
+
+// llmDown 主模型是否处于不可用状态。
+func (p *Pipeline) llmDown() bool {
+	return time.Now().UnixNano() < p.llmDownUntil.Load()
+}
+
+// markLLMDown 记录主模型请求失败，一段时间后再重试。
+func (p *Pipeline) markLLMDown() {
+	p.llmDownUntil.Store(time.Now().Add(llmRetryInterval).UnixNano())
+	logger.Warnf("[pipeline] 大模型不可用，%v 内使用降级模式", llmRetryInterval)
+}
+
+// answerDegraded 主模型不可用时的降级回复：切歌、调音量等即时指令和报时在本地直接处理，
+// 闲聊交给本地小模型（配置了 llm.local 时），都不行时用固定回复说明情况。
+// ctx 用于播放音乐等在本次对话结束后仍要继续的操作，queryCtx 用于朗读和本地模型请求。
+func (p *Pipeline) answerDegraded(ctx, queryCtx context.Context, query string) {
+	if cmd, ok := matchFastCommand(query, p.cfg.Tools.Volume.Step, p.wakeWords); ok {
+		logger.Infof("[pipeline] 降级模式: 本地执行即时指令")
+		p.contextManager.Add("assistant", "好的")
+		// 在本次对话中同步执行，不再开新的 goroutine 与下一轮对话抢跑；
+		// 无法直接完成时只说明大模型不可用，不再重新进入 processQuery
+		p.execFastCommand(ctx, cmd, false, func() { p.speakLLMUnavailable(ctx) })
+		return
+	}
+
+	reply, ok := localAnswer(query, time.Now())
+	if ok {
+		logger.Infof("[pipeline] 降级模式: 本地回答")
+	} else if p.localLLM != nil {
+		reply = p.askLocalLLM(queryCtx)
+	}
+	if reply == "" {
+		reply = cannedReply(query)
+	}
+	if p.interrupted.Load() {
+		return
+	}
+
+	p.contextManager.Add("assistant", reply)
+	logger.Infof("[小派] %s", reply)
+	p.state.Transition(StateSpeaking)
+	p.speakText(queryCtx, reply)
+	if !p.interrupted.Load() {
+		p.enterContinuousMode()
+	}
+}
+
+// fastCommandFallback 即时指令无法直接完成时（如没有播放列表）交给大模型处理；
+// 大模型不可用时直接说明，避免降级模式下反复重试。
+func (p *Pipeline) fastCommandFallback(ctx context.Context, query string) {
+	if !p.llmDown() {
+		p.processQuery(ctx, query)
+		return
+	}
+	p.speakLLMUnavailable(ctx)
+}
+
+// speakLLMUnavailable 说明大模型不可用、这个请求暂时做不了。
+func (p *Pipeline) speakLLMUnavailable(ctx context.Context) {
+	p.state.Transition(StateSpeaking)
+	p.speakText(ctx, p.localize("现在连不上大模型，这个暂时做不了，等网络恢复后再试吧"))
+	p.state.ForceIdle()
+}
+
+// askLocalLLM 用本地小模型生成闲聊回复，失败时返回空字符串。
+// 只发送系统提示和最近几条文本消息，去掉工具调用相关的消息。
+func (p *Pipeline) askLocalLLM(ctx context.Context) string {
+	all := p.contextManager.Messages()
+	var history []llm.Message
+	for _, msg := range all[1:] {
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" && len(msg.ToolCalls) == 0 {
+			history = append(history, llm.Message{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	if len(history) > localLLMHistory {
+		history = history[len(history)-localLLMHistory:]
+	}
+	messages := append([]llm.Message{{
+		Role:    "system",
+		Content: all[0].Content + "\n\n现在网络不好，你无法查询天气、新闻、播放音乐等，请用一两句话简短回答。",
+	}}, history...)
+
+	textCh, err := p.localLLM.ChatStream(ctx, messages)
+	if err != nil {
+		logger.Warnf("[pipeline] 本地模型请求失败: %v", err)
+		return ""
+	}
+	var reply strings.Builder
+	for chunk := range textCh {
+		reply.WriteString(chunk)
+	}
+	logger.Infof("[pipeline] 降级模式: 本地模型回复 (%d 字符)", reply.Len())
+	return strings.TrimSpace(reply.String())
+}
+
+// localAnswer 不需要大模型就能回答的问题（问现在的时间、日期）。
+// "明天几点叫我"这类涉及闹钟、提醒的请求不在本地回答。
+func localAnswer(query string, now time.Time) (string, bool) {
+	if containsAny(query, "明天", "后天", "闹钟", "提醒", "叫我", "倒计时") {
+		return "", false
+	}
+	weekdays := []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+	switch {
+	case containsAny(query, "几点", "什么时间", "现在时间", "几点钟"):
+		return "现在是" + spokenClock(now), true
+	case containsAny(query, "星期几", "周几", "礼拜几"):
+		return fmt.Sprintf("今天是%s", weekdays[now.Weekday()]), true
+	case containsAny(query, "几号", "几月几", "什么日子", "今天日期", "今天的日期"):
+		return fmt.Sprintf("今天是%d月%d日，%s", now.Month(), now.Day(), weekdays[now.Weekday()]), true
+	}
+	return "", false
+}
+
+// spokenClock 把时间转成口语说法，如"下午3点05分"。
+func spokenClock(t time.Time) string {
+	h := t.Hour()
+	var period string
+	switch {
+	case h < 6:
+		period = "凌晨"
+	case h < 9:
+		period = "早上"
+	case h < 12:
+		period = "上午"
+	case h < 13:
+		period = "中午"
+	case h < 18:
+		period = "下午"
+	default:
+		period = "晚上"
+	}
+	if h > 12 {
+		h -= 12
+	}
+	if t.Minute() == 0 {
+		return fmt.Sprintf("%s%d点整", period, h)
+	}
+	return fmt.Sprintf("%s%d点%02d分", period, h, t.Minute())
+}
+
+// cannedReplies 本地小模型也不可用时的固定回复，按关键词匹配。
+var cannedReplies = []struct {
+	keywords []string
+	reply    string
+}{
+	{[]string{"你好", "在吗", "在不在", "嗨"}, "我在的，不过现在网络不太好，只能报时、切歌和调音量。"},
+	{[]string{"早上好", "早安"}, "早上好！现在网络不太好，有些功能暂时用不了。"},
+	{[]string{"晚安"}, "晚安，做个好梦。"},
+	{[]string{"谢谢", "多谢"}, "不客气。"},
+	{[]string{"再见", "拜拜"}, "再见。"},
+}
+
+// cannedReply 返回固定回复，没有匹配时说明当前只能做什么。
+func cannedReply(query string) string {
+	for _, c := range cannedReplies {
+		if containsAny(query, c.keywords...) {
+			return c.reply
+		}
+	}
+	return "现在连不上大模型，我只能报时、切歌和调音量，其他问题等网络恢复后再问我吧。"
+}
+
+// containsAny 判断文本是否包含任一关键词。
+func containsAny(text string, keywords ...string) bool {
+	for _, kw := range keywords {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalAnswer(t *testing.T) {
+	now := time.Date(2026, 3, 5, 15, 7, 0, 0, time.Local) // 星期四
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"现在几点了", "现在是下午3点07分", true},
+		{"今天星期几", "今天是星期四", true},
+		{"今天几号", "今天是3月5日，星期四", true},
+		{"明天早上七点叫我", "", false},
+		{"几点的闹钟", "", false},
+		{"讲个笑话", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := localAnswer(tt.query, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("localAnswer(%q) = %q, %v; want %q, %v", tt.query, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSpokenClock(t *testing.T) {
+	tests := map[int]string{
+		0:  "凌晨0点整",
+		7:  "早上7点整",
+		12: "中午12点整",
+		20: "晚上8点整",
+	}
+	for h, want := range tests {
+		if got := spokenClock(time.Date(2026, 1, 1, h, 0, 0, 0, time.Local)); got != want {
+			t.Errorf("spokenClock(%d) = %q, want %q", h, got, want)
+		}
+	}
+}
+
+func TestCannedReply(t *testing.T) {
+	if got := cannedReply("谢谢你"); got != "不客气。" {
+		t.Errorf("cannedReply = %q", got)
+	}
+	if got := cannedReply("给我讲讲量子力学"); got == "" {
+		t.Error("cannedReply should have a default reply")
+	}
+}
+
+func TestLLMDown(t *testing.T) {
+	p := &Pipeline{}
+	if p.llmDown() {
+		t.Fatal("llm should not be down initially")
+	}
+	p.markLLMDown()
+	if !p.llmDown() {
+		t.Error("llm should be down after failure")
+	}
+	p.llmDownUntil.Store(time.Now().Add(-time.Second).UnixNano())
+	if p.llmDown() {
+		t.Error("llm should be retried after interval")
+	}
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/iabetor/pibuddy/internal/diag"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// createDiagBundle 生成诊断包，配置了 diag.upload_url 时同时上传。上传失败不影响本地文件。
+func (p *Pipeline) createDiagBundle(ctx context.Context) (string, bool, error) {
+	path, err := diag.Bundle(p.cfg, p.db)
+	if err != nil {
+		return "", false, err
+	}
+	logger.Infof("[pipeline] 诊断包已生成: %s", path)
+	if p.cfg.Diag.UploadURL == "" {
+		return path, false, nil
+	}
+	if _, err := diag.Upload(ctx, p.cfg.Diag, path); err != nil {
+		logger.Warnf("[pipeline] %v", err)
+		return path, false, nil
+	}
+	logger.Infof("[pipeline] 诊断包已上传")
+	return path, true, nil
+}
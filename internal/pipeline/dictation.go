@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// dictationEndPhrases 结束听写的说法，出现在一句话末尾即结束。
+var dictationEndPhrases = []string{"结束记录", "停止记录", "记录结束", "结束听写", "停止听写", "不用记了"}
+
+// dictationSummaryPrompt 听写结束后生成摘要的提示词。
+const dictationSummaryPrompt = "下面是一段语音转写的记录，可能有错别字。请整理出要点（3-8 条，每条一行，以\"- \"开头），" +
+	"如有待办事项单独列出。只输出要点，不要寒暄。"
+
+// dictationSession 一次听写（长时记录）会话。
+type dictationSession struct {
+	ctx       context.Context
+	title     string
+	summarize bool
+	started   time.Time
+	parts     []string
+}
+
+// splitDictationEnd 判断一句话是否以结束说法收尾，返回结束说法之前的内容。
+// 结束说法前的唤醒词（如"小派，结束记录"）一并去掉。
+func splitDictationEnd(text string, wakeWords []string) (string, bool) {
+	trimmed := strings.TrimRight(text, "，。！？、,.!? ")
+	for _, phrase := range dictationEndPhrases {
+		if strings.HasSuffix(trimmed, phrase) {
+			rest := strings.TrimSuffix(trimmed, phrase)
+			rest = strings.TrimRight(rest, "，。！？、,.!? ")
+			for _, w := range wakeWords {
+				if strings.HasSuffix(rest, w) {
+					rest = strings.TrimSuffix(rest, w)
+					break
+				}
+			}
+			return strings.TrimRight(rest, "，。！？、,.!? "), true
+		}
+	}
+	return text, false
+}
+
+// dictationActive 是否处于听写模式。
+func (p *Pipeline) dictationActive() bool {
+	p.dictationMu.Lock()
+	defer p.dictationMu.Unlock()
+	return p.dictation != nil
+}
+
+// startDictation 进入听写模式：播报提示后持续监听，识别结果只记录不交给大模型。
+func (p *Pipeline) startDictation(ctx context.Context, result tools.DictationResult) {
+	p.dictationMu.Lock()
+	p.dictation = &dictationSession{
+		ctx:       ctx,
+		title:     result.Title,
+		summarize: result.Summarize,
+		started:   time.Now(),
+	}
+	p.dictationMu.Unlock()
+	logger.Infof("[pipeline] 进入听写模式 (title=%q, summarize=%v)", result.Title, result.Summarize)
+
+	p.state.Transition(StateSpeaking)
+	p.speakText(ctx, "好的，开始记录，说完了告诉我结束记录")
+	if p.interrupted.Load() {
+		return
+	}
+	p.enterContinuousMode()
+}
+
+// handleDictationText 处理听写模式下的一句识别结果。
+func (p *Pipeline) handleDictationText(text string) {
+	content, end := splitDictationEnd(text, p.wakeWords)
+
+	p.dictationMu.Lock()
+	session := p.dictation
+	if session != nil && content != "" {
+		session.parts = append(session.parts, content)
+	}
+	p.dictationMu.Unlock()
+	if session == nil {
+		return
+	}
+	if content != "" {
+		logger.Infof("[pipeline] 听写: %s", content)
+	}
+
+	if end {
+		p.stopContinuousTimer()
+		p.state.SetState(StateProcessing)
+		go p.finishDictation()
+	}
+}
+
+// finishDictation 结束听写：保存全文（可选生成摘要），播报保存位置。
+// 用户说"结束记录"或静默超时时调用。
+func (p *Pipeline) finishDictation() {
+	p.dictationMu.Lock()
+	session := p.dictation
+	p.dictation = nil
+	p.dictationMu.Unlock()
+	if session == nil {
+		return
+	}
+	ctx := session.ctx
+
+	transcript := strings.Join(session.parts, "\n")
+	logger.Infof("[pipeline] 听写结束，共 %d 句", len(session.parts))
+	if transcript == "" {
+		p.state.SetState(StateSpeaking)
+		p.speakText(ctx, "没有记到内容，记录已取消")
+		p.enterContinuousMode()
+		return
+	}
+
+	var summary string
+	if session.summarize {
+		summary = p.summarizeDictation(ctx, transcript)
+	}
+
+	reply := "记录保存失败了"
+	if p.dictationStore != nil {
+		path, err := p.dictationStore.Save(session.title, transcript, summary, session.started, time.Now())
+		if err != nil {
+			logger.Errorf("[pipeline] %v", err)
+		} else {
+			logger.Infof("[pipeline] 听写记录已保存: %s", path)
+			reply = fmt.Sprintf("记好了，共 %d 字，保存在 dictations 目录下的 %s", len([]rune(transcript)), filepath.Base(path))
+			if summary != "" {
+				reply += "，要点也整理好了"
+			}
+		}
+	}
+
+	p.state.SetState(StateSpeaking)
+	p.speakText(ctx, reply)
+	if !p.interrupted.Load() {
+		p.enterContinuousMode()
+	}
+}
+
+// summarizeDictation 调用大模型整理听写要点，失败时返回空（只保存原文）。
+func (p *Pipeline) summarizeDictation(ctx context.Context, transcript string) string {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	textCh, err := p.llmProvider.ChatStream(ctx, []llm.Message{
+		{Role: "system", Content: dictationSummaryPrompt},
+		{Role: "user", Content: transcript},
+	})
+	if err != nil {
+		logger.Warnf("[pipeline] 听写摘要生成失败: %v", err)
+		return ""
+	}
+	var sb strings.Builder
+	for chunk := range textCh {
+		sb.WriteString(chunk)
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,24 @@
+package pipeline
+
+import "testing"
+
+func TestSplitDictationEnd(t *testing.T) {
+	tests := []struct {
+		text    string
+		content string
+		end     bool
+	}{
+		{"第一项是预算", "第一项是预算", false},
+		{"结束记录", "", true},
+		{"结束记录。", "", true},
+		{"今天就到这里，结束记录", "今天就到这里", true},
+		{"小派结束记录", "", true},
+		{"结束记录之后要做什么", "结束记录之后要做什么", false},
+	}
+	for _, tt := range tests {
+		content, end := splitDictationEnd(tt.text, defaultWakeWords)
+		if content != tt.content || end != tt.end {
+			t.Errorf("splitDictationEnd(%q) = (%q, %v), want (%q, %v)", tt.text, content, end, tt.content, tt.end)
+		}
+	}
+}
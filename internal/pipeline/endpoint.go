@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+// 按说话人断句：声纹用户偏好中设置了 endpoint 时，识别出说话人后不再使用识别引擎自带的端点检测，
+// 而是按该用户的规则判断一句话是否说完：说话爱停顿的用户放宽静音阈值，不会说到一半被截断；
+// 语速快的用户缩短阈值，回应更及时。规则与 sherpa 的端点规则相同，对腾讯云识别同样有效。
+
+// endpointRules 断句规则（秒）。
+type endpointRules struct {
+	rule1 float64 // 还没识别出文字时，静音多久算结束
+	rule2 float64 // 识别出文字后，静音多久算结束
+	rule3 float64 // 一句话最长多久
+}
+
+// speakerEndpointRules 用户的断句规则，未设置的字段使用全局配置。用户没有设置断句偏好时 ok 为 false。
+func (p *Pipeline) speakerEndpointRules(prefs *voiceprint.EndpointPreferences) (rules endpointRules, ok bool) {
+	if prefs == nil {
+		return rules, false
+	}
+	rules = endpointRules{
+		rule1: firstPositive(prefs.Rule1MinTrailingSilence, p.cfg.ASR.Rule1MinTrailingSilence, asr.DefaultRule1MinTrailingSilence),
+		rule2: firstPositive(prefs.Rule2MinTrailingSilence, float64(prefs.MinSilenceMs)/1000, p.cfg.ASR.Rule2MinTrailingSilence, asr.DefaultRule2MinTrailingSilence),
+		rule3: firstPositive(prefs.Rule3MinUtteranceLength, p.cfg.ASR.Rule3MinUtteranceLength, asr.DefaultRule3MinUtteranceLength),
+	}
+	return rules, true
+}
+
+func firstPositive(values ...float64) float64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// endpointer 按说话人规则断句的状态：以识别文字最后一次变化的位置作为尾部静音的起点。
+type endpointer struct {
+	mu     sync.Mutex
+	rules  *endpointRules // 当前说话人的规则，为 nil 时使用识别引擎的端点检测
+	total  int            // 这句话已送入的样本数
+	silent int            // 识别文字最后一次变化后送入的样本数
+	text   string
+}
+
+// setRules 设置当前说话人的规则，rules 为 nil 时恢复使用识别引擎的端点检测。
+func (e *endpointer) setRules(rules *endpointRules) {
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+}
+
+// reset 开始新的一句话。
+func (e *endpointer) reset() {
+	e.mu.Lock()
+	e.total, e.silent, e.text = 0, 0, ""
+	e.mu.Unlock()
+}
+
+// feed 记录送入的 n 个样本和当前的识别文字。
+func (e *endpointer) feed(n int, text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.total += n
+	if text != e.text {
+		e.text = text
+		e.silent = 0
+		return
+	}
+	e.silent += n
+}
+
+// reached 按当前说话人的规则判断这句话是否说完，没有规则时 ok 为 false。
+func (e *endpointer) reached(sampleRate int) (done, ok bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rules == nil {
+		return false, false
+	}
+	seconds := func(n int) float64 { return float64(n) / float64(sampleRate) }
+	switch {
+	case seconds(e.total) >= e.rules.rule3:
+		return true, true
+	case e.text == "":
+		return seconds(e.silent) >= e.rules.rule1, true
+	default:
+		return seconds(e.silent) >= e.rules.rule2, true
+	}
+}
+
+// isEndpoint 判断这句话是否说完：当前说话人设置了断句规则时按其规则判断，否则使用识别引擎的端点检测。
+func (p *Pipeline) isEndpoint() bool {
+	if done, ok := p.endpoint.reached(p.cfg.Audio.SampleRate); ok {
+		return done
+	}
+	return p.recognizer.IsEndpoint()
+}
+
+// resetRecognizer 开始新的一句话：重置识别引擎和断句状态。
+func (p *Pipeline) resetRecognizer() {
+	p.recognizer.Reset()
+	p.endpoint.reset()
+}
+
+// applySpeakerEndpoint 识别出说话人后切换到其断句规则，没有设置时使用识别引擎的端点检测。
+// 规则一直沿用到下次识别出说话人，连续对话中同一个人接着说时不用重新识别。
+func (p *Pipeline) applySpeakerEndpoint(user *voiceprint.User) {
+	var prefs voiceprint.UserPreferences
+	if user != nil && user.Preferences != "" {
+		_ = json.Unmarshal([]byte(user.Preferences), &prefs)
+	}
+	rules, ok := p.speakerEndpointRules(prefs.Endpoint)
+	if !ok {
+		p.endpoint.setRules(nil)
+		return
+	}
+	logger.Infof("[pipeline] 使用 %s 的断句设置: 静音 %.1fs/%.1fs，最长 %.0fs", user.Name, rules.rule1, rules.rule2, rules.rule3)
+	p.endpoint.setRules(&rules)
+}
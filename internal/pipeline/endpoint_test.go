@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+func TestSpeakerEndpointRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ASR.Rule1MinTrailingSilence = 3.0
+	p := &Pipeline{cfg: cfg}
+
+	if _, ok := p.speakerEndpointRules(nil); ok {
+		t.Error("user without endpoint preferences should use the engine's endpointing")
+	}
+	rules, _ := p.speakerEndpointRules(&voiceprint.EndpointPreferences{MinSilenceMs: 2000})
+	if rules != (endpointRules{rule1: 3.0, rule2: 2.0, rule3: 20.0}) {
+		t.Errorf("rules = %+v", rules)
+	}
+	rules, _ = p.speakerEndpointRules(&voiceprint.EndpointPreferences{MinSilenceMs: 2000, Rule2MinTrailingSilence: 0.6})
+	if rules.rule2 != 0.6 {
+		t.Errorf("rule2 should take precedence over min_silence_ms, got %v", rules.rule2)
+	}
+}
+
+func TestEndpointer(t *testing.T) {
+	const sampleRate = 16000
+	frame := sampleRate / 10 // 100ms
+	var e endpointer
+	if _, ok := e.reached(sampleRate); ok {
+		t.Fatal("no rules should defer to the engine")
+	}
+
+	e.setRules(&endpointRules{rule1: 2.4, rule2: 0.5, rule3: 20})
+	e.feed(frame, "打开")
+	e.feed(frame, "打开客厅")
+	// 说完后停顿 400ms 还不算结束
+	for i := 0; i < 4; i++ {
+		e.feed(frame, "打开客厅")
+	}
+	if done, _ := e.reached(sampleRate); done {
+		t.Error("400ms of silence should not end the utterance")
+	}
+	e.feed(frame, "打开客厅")
+	if done, _ := e.reached(sampleRate); !done {
+		t.Error("500ms of silence should end the utterance")
+	}
+
+	// 新的一句话重新计时，还没识别出文字时使用 rule1
+	e.reset()
+	for i := 0; i < 10; i++ {
+		e.feed(frame, "")
+	}
+	if done, _ := e.reached(sampleRate); done {
+		t.Error("1s of silence without text should not end the utterance with rule1=2.4")
+	}
+
+	e.setRules(nil)
+	if _, ok := e.reached(sampleRate); ok {
+		t.Error("cleared rules should defer to the engine")
+	}
+}
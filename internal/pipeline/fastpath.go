@@ -0,0 +1,186 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// fastCommand 无需大模型理解、可直接执行的即时指令（下一首、调音量等）。
+type fastCommand struct {
+	tool   string // 要调用的工具，为空表示无需调用（如暂停：打断时音乐已停止）
+	args   string // 工具参数（JSON）
+	resume bool   // 执行后恢复被打断的音乐
+}
+
+const (
+	fastNext = iota
+	fastLouder
+	fastQuieter
+	fastPause
+	fastStop
+)
+
+// fastPhrases 即时指令的说法，整句匹配（去掉标点和语气词后），避免误判"下一首放周杰伦的"这类复杂请求。
+var fastPhrases = map[string]int{
+	"下一首": fastNext, "下一曲": fastNext, "切歌": fastNext, "换一首": fastNext, "换首歌": fastNext, "换一首歌": fastNext, "跳过": fastNext, "跳过这首": fastNext,
+	"大声点": fastLouder, "大声一点": fastLouder, "大点声": fastLouder, "声音大点": fastLouder, "声音大一点": fastLouder, "调大音量": fastLouder, "音量调大": fastLouder, "音量大点": fastLouder, "音量大一点": fastLouder,
+	"小声点": fastQuieter, "小声一点": fastQuieter, "小点声": fastQuieter, "声音小点": fastQuieter, "声音小一点": fastQuieter, "调小音量": fastQuieter, "音量调小": fastQuieter, "音量小点": fastQuieter, "音量小一点": fastQuieter,
+	"暂停": fastPause, "暂停播放": fastPause, "暂停一下": fastPause, "先暂停": fastPause,
+	"停止播放": fastStop, "别放了": fastStop, "不听了": fastStop, "关掉音乐": fastStop, "停止音乐": fastStop,
+}
+
+// matchFastCommand 判断识别文本是否为即时指令。volumeStep 为音量相对调节步长，
+// wakeWords 为句首可能出现的唤醒词。
+func matchFastCommand(text string, volumeStep int, wakeWords []string) (fastCommand, bool) {
+	text = strings.TrimFunc(text, func(r rune) bool {
+		return strings.ContainsRune("，。！？、,.!? ", r)
+	})
+	// 去掉句首称呼和句尾语气词
+	text = trimWakeWord(text, wakeWords)
+	for _, suffix := range []string{"吧", "啊", "呀", "哦", "了"} {
+		if t := strings.TrimSuffix(text, suffix); t != text {
+			if _, ok := fastPhrases[t]; ok {
+				text = t
+				break
+			}
+		}
+	}
+	kind, ok := fastPhrases[text]
+	if !ok {
+		return fastCommand{}, false
+	}
+
+	switch kind {
+	case fastNext:
+		return fastCommand{tool: "next_music"}, true
+	case fastLouder:
+		return fastCommand{tool: "set_volume", args: fmt.Sprintf(`{"volume":%d,"relative":true}`, volumeStep), resume: true}, true
+	case fastQuieter:
+		return fastCommand{tool: "set_volume", args: fmt.Sprintf(`{"volume":%d,"relative":true}`, -volumeStep), resume: true}, true
+	case fastStop:
+		return fastCommand{tool: "stop_music"}, true
+	default:
+		return fastCommand{}, true
+	}
+}
+
+// runFastCommand 直接执行即时指令，用提示音代替大模型回复。
+// 执行失败或结果需要解释时（如没有播放列表）交给 processQuery 走正常流程。
+func (p *Pipeline) runFastCommand(ctx context.Context, query string, cmd fastCommand) {
+	p.interrupted.Store(false)
+	resume := p.interruptedMusic.Swap(false) && cmd.resume
+	p.execFastCommand(ctx, cmd, resume, func() { p.fastCommandFallback(ctx, query) })
+}
+
+// execFastCommand 执行即时指令，resume 为 true 时执行后接着播放被打断的音乐；
+// 无法直接完成时调用 fallback。
+func (p *Pipeline) execFastCommand(ctx context.Context, cmd fastCommand, resume bool, fallback func()) {
+	// 学习时间内需要知道是不是孩子在说话
+	if p.studyChild() != "" {
+		p.voiceprintWg.Wait()
+		if reminder, blocked := p.studyBlocked(cmd.tool); blocked {
+			logger.Infof("[pipeline] 学习时间，拒绝即时指令: %s", cmd.tool)
+			p.speakText(ctx, reminder)
+			p.state.ForceIdle()
+			return
+		}
+		if _, blocked := p.studyBlocked("resume_music"); blocked {
+			resume = false
+		}
+	}
+
+	if cmd.tool != "" {
+		logger.Infof("[pipeline] 即时指令: %s(%s)", cmd.tool, cmd.args)
+		args := cmd.args
+		if args == "" {
+			args = "{}"
+		}
+		result, err := p.toolRegistry.Execute(ctx, cmd.tool, json.RawMessage(args))
+		if err != nil {
+			logger.Warnf("[pipeline] 即时指令执行失败: %v", err)
+			fallback()
+			return
+		}
+
+		if cmd.tool == "next_music" {
+			var musicResult tools.MusicResult
+			if json.Unmarshal([]byte(result), &musicResult) != nil || !musicResult.Success ||
+				(musicResult.URL == "" && musicResult.CacheKey == "") {
+				fallback()
+				return
+			}
+			p.startPlayback(ctx, &playbackRequest{music: musicResult})
+			return
+		}
+	}
+
+	p.state.SetState(StateSpeaking)
+	p.playCue(ctx)
+	if p.interrupted.Load() {
+		return
+	}
+
+	// 调完音量接着放刚才被打断的歌或电台
+	if resume && p.playback.ResumeLive(ctx) {
+		return
+	}
+	if resume {
+		result, err := p.toolRegistry.Execute(ctx, "resume_music", json.RawMessage("{}"))
+		if err == nil {
+			var musicResult tools.MusicResult
+			if json.Unmarshal([]byte(result), &musicResult) == nil && musicResult.Success {
+				p.startPlayback(ctx, &playbackRequest{music: musicResult})
+				return
+			}
+		}
+	}
+
+	if cmd.tool == "" || cmd.tool == "stop_music" {
+		// 暂停、停止后不再等待追问
+		p.stopContinuousTimer()
+		p.state.ForceIdle()
+		return
+	}
+	p.enterContinuousMode()
+}
+
+// cueSamples 提示音：两个短促的上行音（约 0.2 秒）。
+var cueSamples = func() []float32 {
+	const sampleRate = 16000
+	tones := []struct {
+		freq float64
+		ms   int
+	}{{880, 80}, {0, 30}, {1320, 90}}
+
+	var out []float32
+	for _, t := range tones {
+		n := sampleRate * t.ms / 1000
+		fade := n / 8
+		for i := 0; i < n; i++ {
+			if t.freq == 0 {
+				out = append(out, 0)
+				continue
+			}
+			amp := 0.3
+			// 首尾淡入淡出，避免爆音
+			if i < fade {
+				amp *= float64(i) / float64(fade)
+			} else if i > n-fade {
+				amp *= float64(n-i) / float64(fade)
+			}
+			out = append(out, float32(amp*math.Sin(2*math.Pi*t.freq*float64(i)/sampleRate)))
+		}
+	}
+	return out
+}()
+
+// playCue 播放提示音。
+func (p *Pipeline) playCue(ctx context.Context) {
+	p.playSamples(ctx, cueSamples, 16000)
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import "testing"
+
+func TestMatchFastCommand(t *testing.T) {
+	tests := []struct {
+		text   string
+		ok     bool
+		tool   string
+		args   string
+		resume bool
+	}{
+		{"下一首", true, "next_music", "", false},
+		{"下一首吧。", true, "next_music", "", false},
+		{"小派切歌", true, "next_music", "", false},
+		{"大声点", true, "set_volume", `{"volume":10,"relative":true}`, true},
+		{"声音小一点吧", true, "set_volume", `{"volume":-10,"relative":true}`, true},
+		{"暂停", true, "", "", false},
+		{"别放了", true, "stop_music", "", false},
+		{"下一首放周杰伦的", false, "", "", false},
+		{"今天天气怎么样", false, "", "", false},
+		{"", false, "", "", false},
+	}
+	for _, tt := range tests {
+		cmd, ok := matchFastCommand(tt.text, 10, defaultWakeWords)
+		if ok != tt.ok {
+			t.Errorf("matchFastCommand(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			continue
+		}
+		if cmd.tool != tt.tool || cmd.args != tt.args || cmd.resume != tt.resume {
+			t.Errorf("matchFastCommand(%q) = %+v, want tool=%s args=%s resume=%v", tt.text, cmd, tt.tool, tt.args, tt.resume)
+		}
+	}
+}
+
+func TestCueSamples(t *testing.T) {
+	if n := len(cueSamples); n < 16000/10 || n > 16000/2 {
+		t.Errorf("提示音长度应在 0.1~0.5 秒之间，实际 %d 个样本", n)
+	}
+	for i, s := range cueSamples {
+		if s > 1 || s < -1 {
+			t.Fatalf("样本 %d 超出范围: %f", i, s)
+		}
+	}
+	if cueSamples[0] != 0 {
+		t.Errorf("提示音应从 0 开始淡入，实际 %f", cueSamples[0])
+	}
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// gentleWakeStep 渐进唤醒调整灯光亮度和音乐音量的间隔。
+const gentleWakeStep = 30 * time.Second
+
+// gentleWakeMinLevel 渐进唤醒开始时的灯光亮度和音乐音量（百分比）。
+const gentleWakeMinLevel = 5
+
+// gentleWakeSession 一次渐进唤醒：灯光和音乐在设定时长内逐渐调到最大，结束后语音提醒。
+type gentleWakeSession struct {
+	cancel context.CancelFunc
+	light  bool // 是否在控制灯光
+	music  bool // 是否在渐强音乐
+}
+
+// gentleWakeLevel 计算渐进过程中的亮度/音量百分比：从 gentleWakeMinLevel 线性升到 100。
+func gentleWakeLevel(elapsed, total time.Duration) int {
+	if total <= 0 || elapsed >= total {
+		return 100
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return gentleWakeMinLevel + int(float64(100-gentleWakeMinLevel)*float64(elapsed)/float64(total))
+}
+
+// startGentleWake 渐进唤醒闹钟到期：打开灯光并开始播放音乐，之后逐渐调亮、调响，
+// 结束时再语音提醒。灯光和音乐都不可用时直接语音提醒。
+func (p *Pipeline) startGentleWake(ctx context.Context, a tools.AlarmEntry) {
+	p.stopGentleWake()
+	cfg := p.cfg.Tools.Alarm.Gentle
+	logger.Infof("[pipeline] 渐进唤醒闹钟到期: %s", a.Message)
+
+	session := &gentleWakeSession{
+		light: p.setWakeLight(ctx, gentleWakeMinLevel),
+		music: p.startWakeMusic(ctx),
+	}
+	if !session.light && !session.music {
+		logger.Warn("[pipeline] 渐进唤醒的灯光和音乐都不可用，直接语音提醒")
+		p.ringAlarm(a)
+		return
+	}
+
+	rampCtx, cancel := context.WithCancel(context.Background())
+	session.cancel = cancel
+	p.gentleWakeMu.Lock()
+	p.gentleWake = session
+	p.gentleWakeMu.Unlock()
+
+	total := time.Duration(cfg.Minutes) * time.Minute
+	logger.Infof("[pipeline] 开始渐进唤醒（灯光: %v, 音乐: %v），%d 分钟后语音提醒", session.light, session.music, cfg.Minutes)
+	go p.runGentleWake(rampCtx, session, total, a)
+}
+
+// runGentleWake 逐步调亮灯光、调大音乐，到时间后恢复音乐音量并语音提醒。
+func (p *Pipeline) runGentleWake(ctx context.Context, session *gentleWakeSession, total time.Duration, a tools.AlarmEntry) {
+	ticker := time.NewTicker(gentleWakeStep)
+	defer ticker.Stop()
+	started := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		elapsed := time.Since(started)
+		level := gentleWakeLevel(elapsed, total)
+		if session.light {
+			p.setWakeLight(ctx, level)
+		}
+		if session.music {
+			p.playback.Duck(level)
+		}
+		if elapsed >= total {
+			break
+		}
+	}
+
+	p.gentleWakeMu.Lock()
+	current := p.gentleWake == session
+	if current {
+		p.gentleWake = nil
+	}
+	p.gentleWakeMu.Unlock()
+	if !current {
+		return
+	}
+	p.ringAlarm(a)
+}
+
+// stopGentleWake 停止渐进唤醒（用户唤醒时调用）：灯光保持当前亮度，音乐音量恢复正常。
+func (p *Pipeline) stopGentleWake() {
+	p.gentleWakeMu.Lock()
+	session := p.gentleWake
+	p.gentleWake = nil
+	p.gentleWakeMu.Unlock()
+	if session == nil {
+		return
+	}
+
+	session.cancel()
+	if session.music {
+		p.playback.Duck(100)
+	}
+	logger.Info("[pipeline] 已停止渐进唤醒")
+}
+
+// setWakeLight 把配置的灯光调到 percent 亮度，未配置或 Home Assistant 不可用时返回 false。
+func (p *Pipeline) setWakeLight(ctx context.Context, percent int) bool {
+	light := p.cfg.Tools.Alarm.Gentle.Light
+	if p.haClient == nil || light == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	err := p.haClient.CallService(ctx, "light", "turn_on", map[string]interface{}{
+		"entity_id":      light,
+		"brightness_pct": percent,
+	})
+	if err != nil {
+		logger.Warnf("[pipeline] 渐进唤醒调节灯光失败: %v", err)
+		return false
+	}
+	return true
+}
+
+// startWakeMusic 以很小的音量开始播放配置的音乐，音乐不可用时返回 false。
+func (p *Pipeline) startWakeMusic(ctx context.Context) bool {
+	if _, ok := p.toolRegistry.Get("play_music"); !ok {
+		return false
+	}
+	cfg := p.cfg.Tools.Alarm.Gentle
+	args, _ := json.Marshal(map[string]string{"keyword": cfg.Music, "mood": cfg.Mood})
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	result, err := p.toolRegistry.Execute(ctx, "play_music", args)
+	if err != nil {
+		logger.Warnf("[pipeline] 渐进唤醒播放音乐失败: %v", err)
+		return false
+	}
+	req := newPlaybackRequest("play_music", result)
+	if req == nil {
+		logger.Warnf("[pipeline] 渐进唤醒没有可播放的音乐: %s", result)
+		return false
+	}
+	p.playback.Duck(gentleWakeMinLevel)
+	p.startPlayback(context.Background(), req)
+	return true
+}
@@ -0,0 +1,27 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGentleWakeLevel(t *testing.T) {
+	total := 10 * time.Minute
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, gentleWakeMinLevel},
+		{5 * time.Minute, 52},
+		{10 * time.Minute, 100},
+		{11 * time.Minute, 100},
+	}
+	for _, tt := range tests {
+		if got := gentleWakeLevel(tt.elapsed, total); got != tt.want {
+			t.Errorf("gentleWakeLevel(%v) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+	if got := gentleWakeLevel(time.Minute, 0); got != 100 {
+		t.Errorf("时长为 0 时应直接为 100, got %d", got)
+	}
+}
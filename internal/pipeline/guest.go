@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// defaultGuestTools 访客模式下默认开放的工具：只读的查询、听歌、讲故事和学习类工具，
+// 不写入任何存储，也不涉及家电、门锁和个人数据。
+var defaultGuestTools = []string{
+	"get_datetime", "get_weather", "get_air_quality", "get_news", "navigate_news", "get_stock", "get_lunar_date",
+	"calculate", "convert_cooking_unit", "translate",
+	"search_music", "play_music", "next_music", "stop_music", "resume_music", "set_play_mode", "set_volume", "get_volume",
+	"play_radio_station", "get_now_playing",
+	"tell_story", "continue_story",
+	"english_word", "english_daily", "english_quiz", "lookup_hanzi", "pinyin_query", "poetry_daily", "poetry_search", "poetry_game",
+	"get_guest_wifi", "go_to_sleep",
+	"set_guest_mode", // 退出访客模式（注册了声纹时只有主人可以）
+}
+
+// initGuestTools 访客模式下可用的工具集合。
+func (p *Pipeline) initGuestTools() {
+	names := p.cfg.Guest.AllowedTools
+	if len(names) == 0 {
+		names = defaultGuestTools
+	}
+	p.guestTools = make(map[string]bool, len(names)+1)
+	for _, name := range names {
+		p.guestTools[name] = true
+	}
+	p.guestTools["set_guest_mode"] = true
+}
+
+// startGuestMode 进入访客模式：暂停播放历史和使用统计，清空对话上下文。
+func (p *Pipeline) startGuestMode() {
+	p.guestMode.Store(true)
+	p.pauseGuestStores(true)
+	p.contextManager.Clear()
+	logger.Info("[pipeline] 已开启访客模式")
+}
+
+// stopGuestMode 退出访客模式，未开启时返回 false。
+func (p *Pipeline) stopGuestMode() bool {
+	if !p.guestMode.Swap(false) {
+		return false
+	}
+	p.pauseGuestStores(false)
+	p.contextManager.Clear()
+	logger.Info("[pipeline] 已退出访客模式")
+	return true
+}
+
+func (p *Pipeline) pauseGuestStores(paused bool) {
+	if p.musicHistory != nil {
+		p.musicHistory.SetPaused(paused)
+	}
+	p.usage.SetPaused(paused)
+}
+
+// guestBlocked 访客模式下工具是否不可用。
+func (p *Pipeline) guestBlocked(tool string) bool {
+	return p.guestMode.Load() && !p.guestTools[tool]
+}
+
+// clearGuestContext 访客模式下一次对话结束（或新的对话开始）时清空对话上下文，
+// 上一位客人说过的话不会带到下一次对话。
+func (p *Pipeline) clearGuestContext() {
+	if p.guestMode.Load() {
+		p.contextManager.Clear()
+		logger.Debug("[pipeline] 访客模式，已清空对话上下文")
+	}
+}
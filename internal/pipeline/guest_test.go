@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+func TestGuestMode(t *testing.T) {
+	history, err := music.NewHistoryStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{cfg: &config.Config{}, contextManager: llm.NewContextManager("", 10), musicHistory: history}
+	p.initGuestTools()
+
+	if p.guestBlocked("add_memo") || p.stopGuestMode() {
+		t.Fatal("guest mode should start disabled")
+	}
+
+	p.contextManager.Add("user", "我家门锁密码是多少")
+	p.startGuestMode()
+	if len(p.contextManager.Messages()) != 1 {
+		t.Error("entering guest mode should clear the conversation")
+	}
+	for _, name := range []string{"add_memo", "add_favorite", "ha_control_device", "ezviz_open_door", "manage_settings", "list_memos"} {
+		if !p.guestBlocked(name) {
+			t.Errorf("%s should be blocked in guest mode", name)
+		}
+	}
+	for _, name := range []string{"get_weather", "play_music", "tell_story", "set_guest_mode"} {
+		if p.guestBlocked(name) {
+			t.Errorf("%s should be available in guest mode", name)
+		}
+	}
+
+	history.Add(music.Song{ID: 1, Name: "晴天"})
+	if len(history.List(10)) != 0 {
+		t.Error("music history should not be written in guest mode")
+	}
+
+	p.contextManager.Add("user", "讲个故事")
+	p.clearGuestContext()
+	if len(p.contextManager.Messages()) != 1 {
+		t.Error("guest context should be cleared after each conversation")
+	}
+
+	if !p.stopGuestMode() || p.guestBlocked("add_memo") {
+		t.Error("stop should leave guest mode")
+	}
+	history.Add(music.Song{ID: 1, Name: "晴天"})
+	if len(history.List(10)) != 1 {
+		t.Error("music history should be written again after guest mode")
+	}
+}
+
+func TestGuestModeCustomTools(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{Guest: config.GuestConfig{AllowedTools: []string{"get_weather"}}}, contextManager: llm.NewContextManager("", 10)}
+	p.initGuestTools()
+	p.startGuestMode()
+	if p.guestBlocked("get_weather") || !p.guestBlocked("play_music") {
+		t.Error("allowed_tools should replace the default list")
+	}
+	if p.guestBlocked("set_guest_mode") {
+		t.Error("guest mode should always be possible to leave")
+	}
+}
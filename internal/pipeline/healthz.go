@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 健康检查：管理 API /healthz 和语音查询"你自己状态怎么样"共用同一份报告。
+
+const (
+	healthFrameTimeout = 5 * time.Second // 超过这个时间没有收到麦克风音频视为音频设备异常
+	healthDialTimeout  = 3 * time.Second
+)
+
+// healthReport 检查音频设备、模型、数据库和网络，附带系统状态。
+func (p *Pipeline) healthReport(ctx context.Context) tools.HealthReport {
+	checks := []tools.ComponentCheck{
+		p.checkAudioHealth(),
+		p.checkModelHealth(),
+	}
+	if p.db != nil {
+		checks = append(checks, p.checkDatabaseHealth(ctx))
+	}
+	if host := p.networkProbeHost(); host != "" {
+		checks = append(checks, checkNetworkHealth(ctx, host))
+	}
+
+	report := tools.HealthReport{
+		Healthy: true,
+		Checks:  checks,
+		System:  tools.NewSystemStatusTool().Snapshot(),
+	}
+	if !p.startedAt.IsZero() {
+		report.Uptime = tools.FormatUptime(time.Since(p.startedAt).Seconds())
+	}
+	for _, c := range checks {
+		report.Healthy = report.Healthy && c.OK
+	}
+	return report
+}
+
+// checkAudioHealth 麦克风是否持续送来音频、播放器是否就绪。
+func (p *Pipeline) checkAudioHealth() tools.ComponentCheck {
+	c := tools.ComponentCheck{Name: "audio", Label: "音频设备"}
+	switch last := p.lastFrameAt.Load(); {
+	case p.capture == nil || p.player == nil:
+		c.Detail = "音频设备未初始化"
+	case last == 0:
+		c.Detail = "麦克风还没有开始采集"
+	case time.Since(time.Unix(0, last)) > healthFrameTimeout:
+		c.Detail = fmt.Sprintf("麦克风已经 %.0f 秒没有声音输入", time.Since(time.Unix(0, last)).Seconds())
+	default:
+		c.OK = true
+	}
+	return c
+}
+
+// checkModelHealth 唤醒词、VAD、语音识别和语音合成是否都已加载。
+func (p *Pipeline) checkModelHealth() tools.ComponentCheck {
+	c := tools.ComponentCheck{Name: "models", Label: "语音模型"}
+	switch {
+	case p.wakeDetector == nil:
+		c.Detail = "唤醒词模型未加载"
+	case p.vadDetector == nil:
+		c.Detail = "语音检测模型未加载"
+	case p.recognizer == nil:
+		c.Detail = "语音识别未加载"
+	case p.ttsEngine == nil:
+		c.Detail = "语音合成未加载"
+	default:
+		c.OK = true
+		if fallback, ok := p.recognizer.(*asr.FallbackEngine); ok && fallback.IsDegraded() {
+			c.Detail = "云端识别不可用，正在使用离线识别"
+		}
+	}
+	return c
+}
+
+// checkDatabaseHealth 数据库能否访问、数据目录能否写入。
+func (p *Pipeline) checkDatabaseHealth(ctx context.Context) tools.ComponentCheck {
+	c := tools.ComponentCheck{Name: "database", Label: "数据库"}
+	if err := p.db.PingContext(ctx); err != nil {
+		c.Detail = fmt.Sprintf("无法访问: %v", err)
+		return c
+	}
+	f, err := os.CreateTemp(filepath.Dir(p.db.Path()), ".healthz-*")
+	if err != nil {
+		c.Detail = "数据目录无法写入"
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	c.OK = true
+	return c
+}
+
+// networkProbeHost 检查网络时连接的地址：主模型的 API 地址，没有配置云端模型时不检查。
+func (p *Pipeline) networkProbeHost() string {
+	apiURL := p.cfg.LLM.APIURL
+	if len(p.cfg.LLM.Models) > 0 {
+		apiURL = p.cfg.LLM.Models[0].APIURL
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// checkNetworkHealth 能否连上云端服务。
+func checkNetworkHealth(ctx context.Context, host string) tools.ComponentCheck {
+	c := tools.ComponentCheck{Name: "network", Label: "网络"}
+	ctx, cancel := context.WithTimeout(ctx, healthDialTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", host)
+	if err != nil {
+		c.Detail = "无法连接云端服务"
+		return c
+	}
+	conn.Close()
+	c.OK = true
+	return c
+}
+
+// handleHealthz 健康检查接口，不需要鉴权，供监控程序使用：全部正常返回 200，否则返回 503。
+func (p *Pipeline) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	report := p.healthReport(r.Context())
+	status := http.StatusOK
+	if !report.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	admin.WriteJSON(w, status, map[string]interface{}{
+		"success": report.Healthy,
+		"health":  report,
+	})
+}
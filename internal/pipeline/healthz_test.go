@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+func TestHandleHealthz(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{}}
+	rec := httptest.NewRecorder()
+	p.handleHealthz(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 without audio devices and models", rec.Code)
+	}
+
+	var body struct {
+		Success bool               `json:"success"`
+		Health  tools.HealthReport `json:"health"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Success || body.Health.Healthy || len(body.Health.Checks) != 2 {
+		t.Fatalf("unexpected report: %+v", body)
+	}
+	if c := body.Health.Checks[0]; c.Name != "audio" || c.OK || c.Detail == "" {
+		t.Errorf("audio check = %+v, want failure with detail", c)
+	}
+}
+
+func TestNetworkProbeHost(t *testing.T) {
+	tests := []struct {
+		cfg  config.LLMConfig
+		want string
+	}{
+		{config.LLMConfig{Models: []config.LLMModelConfig{{APIURL: "https://api.hunyuan.cloud.tencent.com/v1"}}}, "api.hunyuan.cloud.tencent.com:443"},
+		{config.LLMConfig{APIURL: "http://192.168.1.10:8080/v1"}, "192.168.1.10:8080"},
+		{config.LLMConfig{}, ""},
+	}
+	for _, tt := range tests {
+		p := &Pipeline{cfg: &config.Config{LLM: tt.cfg}}
+		if got := p.networkProbeHost(); got != tt.want {
+			t.Errorf("networkProbeHost() = %q, want %q", got, tt.want)
+		}
+	}
+}
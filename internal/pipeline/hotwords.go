@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 动态热词（asr.hotwords）：定期从用户自己的数据中收集歌名、歌手、家人名字、设备名和备忘里的词语，
+// 有变化时设置到 ASR 引擎（sherpa 热词、腾讯云临时热词表），不需要手动维护热词表。
+
+// hotwordDeviceDomains 取设备名作为热词的 Home Assistant 实体类型（可以用语音控制的设备）。
+var hotwordDeviceDomains = map[string]bool{
+	"light": true, "switch": true, "fan": true, "climate": true, "cover": true,
+	"lock": true, "media_player": true, "scene": true, "vacuum": true,
+}
+
+// collectHotwords 收集热词，按优先级排列：热词数量有上限，靠前的优先保留。
+func (p *Pipeline) collectHotwords(ctx context.Context) []string {
+	words := append([]string(nil), p.cfg.ASR.Hotwords.Extra...)
+
+	// 家人名字和昵称
+	if p.voiceprintMgr != nil {
+		if users, err := p.voiceprintMgr.ListUsers(); err == nil {
+			for _, u := range users {
+				words = append(words, u.Name, p.userNickname(u.Name))
+			}
+		}
+	}
+
+	// 智能家居设备名
+	if p.haClient != nil {
+		if states, err := p.haClient.GetStates(ctx); err != nil {
+			logger.Debugf("[pipeline] 获取设备列表失败，本次热词不含设备名: %v", err)
+		} else {
+			for _, s := range states {
+				domain, _, _ := strings.Cut(s.EntityID, ".")
+				if name, ok := s.Attributes["friendly_name"].(string); ok && hotwordDeviceDomains[domain] {
+					words = append(words, name)
+				}
+			}
+		}
+	}
+
+	// 收藏的歌曲和歌手
+	if p.favoritesStore != nil {
+		for _, user := range p.favoritesStore.Users() {
+			songs, err := p.favoritesStore.List(user)
+			if err != nil {
+				continue
+			}
+			for _, s := range songs {
+				words = append(words, s.Name, s.Artist)
+			}
+		}
+	}
+
+	// 备忘里的词语
+	if p.memoStore != nil {
+		for _, m := range p.memoStore.List() {
+			words = append(words, memoPhrases(m.Content)...)
+		}
+	}
+
+	// 最近播放的缓存歌曲
+	if p.musicCache != nil && p.musicCache.Enabled() {
+		for _, c := range p.musicCache.List() {
+			words = append(words, c.Name, c.Artist)
+		}
+	}
+
+	return asr.NormalizeHotwords(words)
+}
+
+// memoPhrases 按标点和空白把备忘内容切成短语，过长的短语由 NormalizeHotwords 过滤。
+func memoPhrases(content string) []string {
+	return strings.FieldsFunc(content, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// refreshHotwords 重新收集热词，有变化时设置到 ASR 引擎。
+func (p *Pipeline) refreshHotwords(ctx context.Context) {
+	engine, ok := p.recognizer.(asr.HotwordEngine)
+	if !ok {
+		return
+	}
+	p.hotwordsMu.Lock()
+	defer p.hotwordsMu.Unlock()
+
+	words := p.collectHotwords(ctx)
+	key := strings.Join(words, "\n")
+	if key == p.hotwordsKey {
+		return
+	}
+	p.hotwordsKey = key
+	engine.SetHotwords(words)
+	logger.Infof("[pipeline] ASR 热词已更新: %d 个", len(words))
+}
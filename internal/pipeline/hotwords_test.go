@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/asr"
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/music"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// hotwordRecognizer 记录设置的热词。
+type hotwordRecognizer struct {
+	fakeASR
+	sets  int
+	words []string
+}
+
+func (r *hotwordRecognizer) SetHotwords(words []string) {
+	r.sets++
+	r.words = words
+}
+
+func TestRefreshHotwords(t *testing.T) {
+	dir := t.TempDir()
+	favorites := music.NewFavoritesStore(dir)
+	for _, s := range []music.FavoriteSong{
+		{ID: 1, Name: "晴天", Artist: "周杰伦", Provider: "qq"},
+		{ID: 2, Name: "稻香", Artist: "周杰伦", Provider: "qq"},
+	} {
+		if err := favorites.Add("小明", s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	memos, err := tools.NewMemoStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := memos.Add(tools.MemoEntry{ID: "1", Content: "给花浇水，周五交水电费"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.ASR.Hotwords.Extra = []string{"小派", "小派", "x"}
+	rec := &hotwordRecognizer{}
+	engine := asr.NewFallbackEngine(asr.FallbackConfig{
+		Engines:     []asr.Engine{rec},
+		EngineTypes: []asr.EngineType{asr.EngineSherpa},
+	})
+	p := &Pipeline{cfg: cfg, recognizer: engine, favoritesStore: favorites, memoStore: memos}
+
+	p.refreshHotwords(context.Background())
+	want := []string{"小派", "晴天", "周杰伦", "稻香", "给花浇水", "周五交水电费"}
+	if !slices.Equal(rec.words, want) {
+		t.Errorf("hotwords = %v, want %v", rec.words, want)
+	}
+
+	// 数据没有变化时不重复设置
+	p.refreshHotwords(context.Background())
+	if rec.sets != 1 {
+		t.Errorf("SetHotwords called %d times, want 1", rec.sets)
+	}
+
+	if err := favorites.Add("小红", music.FavoriteSong{ID: 3, Name: "小幸运", Artist: "田馥甄", Provider: "qq"}); err != nil {
+		t.Fatal(err)
+	}
+	p.refreshHotwords(context.Background())
+	if rec.sets != 2 || !slices.Contains(rec.words, "小幸运") {
+		t.Errorf("new favorites should update hotwords, got %v", rec.words)
+	}
+}
@@ -0,0 +1,296 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/scheduler"
+	"github.com/iabetor/pibuddy/internal/sound"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// initScheduler 创建调度器并注册所有后台定时任务。
+// 新的轮询类功能应在这里注册任务，而不是自己创建 ticker。
+func (p *Pipeline) initScheduler() error {
+	p.scheduler = scheduler.New()
+	p.scheduler.SetBusyFunc(p.isConversationActive)
+
+	// 闹钟检查：每 30 秒
+	if err := p.scheduler.Add(scheduler.Job{
+		Name:           "alarm_checker",
+		Schedule:       scheduler.Every(30 * time.Second),
+		PauseWhileBusy: true,
+		Run:            p.checkAlarms,
+	}); err != nil {
+		return err
+	}
+
+	// 健康提醒检查：每分钟，加少量抖动避免与闹钟同时播报
+	if p.healthStore != nil {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:           "health_reminder",
+			Schedule:       scheduler.Every(time.Minute),
+			Jitter:         5 * time.Second,
+			PauseWhileBusy: true,
+			Run:            p.checkHealthReminders,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 音乐 API 服务健康检查：服务挂掉时及时发现，托管模式下自动重启
+	if p.musicServer != nil {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:     "music_api_health",
+			Schedule: scheduler.Every(time.Duration(p.cfg.Tools.Music.HealthInterval) * time.Second),
+			Jitter:   10 * time.Second,
+			Run:      p.checkMusicServer,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Home Assistant 日历、待办同步
+	if p.haSync != nil {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:     "ha_sync",
+			Schedule: scheduler.Every(time.Duration(p.cfg.Tools.HomeAssistant.Sync.Interval) * time.Second),
+			Jitter:   10 * time.Second,
+			Run: func(ctx context.Context) {
+				if err := p.haSync.Sync(ctx); err != nil {
+					logger.Warnf("[pipeline] %v", err)
+				}
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 出门前下雨提醒：轮询门磁
+	if ra := p.cfg.Tools.HomeAssistant.RainAlert; ra.DoorEntity != "" && p.haClient != nil {
+		if p.weatherTool == nil || ra.City == "" {
+			logger.Warn("[pipeline] 出门前提醒需要配置天气 API 和城市（tools.weather.default_city），已跳过")
+		} else if err := p.scheduler.Add(scheduler.Job{
+			Name:           "rain_alert",
+			Schedule:       scheduler.Every(time.Duration(ra.Interval) * time.Second),
+			PauseWhileBusy: true,
+			Run:            p.checkDoorForRain,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 家人在家检测
+	p.initPresence()
+	if p.presence != nil {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:     "presence",
+			Schedule: scheduler.Every(time.Duration(p.cfg.Presence.Interval) * time.Second),
+			Jitter:   5 * time.Second,
+			Run:      p.checkPresence,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 空闲时后台维护：校验缓存、预下载收藏、刷新 RSS、压缩数据库
+	if p.cfg.Maintenance.Enabled {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:           "idle_maintenance",
+			Schedule:       scheduler.Every(time.Duration(p.cfg.Maintenance.Interval) * time.Minute),
+			Jitter:         time.Minute,
+			PauseWhileBusy: true,
+			Run:            p.runMaintenance,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 清理下载中断留下的音乐缓存临时文件：不依赖空闲维护，一直在放歌时也能清理
+	if p.musicCache != nil && p.musicCache.Enabled() {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:     "music_cache_temp",
+			Schedule: scheduler.Every(audio.StaleFileAge),
+			Jitter:   time.Minute,
+			Run: func(ctx context.Context) {
+				p.musicCache.CleanTemp(audio.StaleFileAge)
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 动态热词：用户数据有变化时更新 ASR 热词
+	if p.cfg.ASR.Hotwords.Enabled {
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:     "asr_hotwords",
+			Schedule: scheduler.Every(time.Duration(p.cfg.ASR.Hotwords.Interval) * time.Second),
+			Jitter:   10 * time.Second,
+			Run:      p.refreshHotwords,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 晚间语音小结（可选）
+	if spec := p.cfg.Tools.Usage.Recap; spec != "" {
+		sched, err := scheduler.Parse(spec)
+		if err != nil {
+			return fmt.Errorf("解析 tools.usage.recap 失败: %w", err)
+		}
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:           "daily_recap",
+			Schedule:       sched,
+			PauseWhileBusy: true,
+			Run:            p.speakDailyRecap,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 整点报时（可选）
+	if spec := p.cfg.Tools.Chime.Schedule; spec != "" {
+		sched, err := scheduler.Parse(spec)
+		if err != nil {
+			return fmt.Errorf("解析 tools.chime.schedule 失败: %w", err)
+		}
+		if err := p.scheduler.Add(scheduler.Job{
+			Name:           "hourly_chime",
+			Schedule:       sched,
+			PauseWhileBusy: true,
+			Run:            p.announceChime,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if p.adminServer != nil {
+		p.adminServer.Handle("GET /api/scheduler/jobs", p.handleSchedulerJobs)
+	}
+	return nil
+}
+
+// isConversationActive 判断是否正在对话（聆听、处理中或 TTS 播报）。
+// 音乐播放虽然也处于 Speaking 状态，但不算对话，定时播报可以打断音乐。
+func (p *Pipeline) isConversationActive() bool {
+	switch p.state.Current() {
+	case StateListening, StateProcessing:
+		return true
+	}
+	if p.activeEnrollment() != nil {
+		return true
+	}
+	p.speakMu.Lock()
+	defer p.speakMu.Unlock()
+	return p.cancelSpeak != nil
+}
+
+// checkAlarms 检查到期闹钟，到期时播报（未回应时重复播报）。
+func (p *Pipeline) checkAlarms(ctx context.Context) {
+	dueAlarms := p.alarmStore.PopDueAlarms()
+	for _, a := range dueAlarms {
+		if a.Context != "" {
+			// 跟进提醒：播报对话总结，用户接着说话时总结会放入对话上下文
+			logger.Infof("[pipeline] 跟进提醒到期: %s", a.Message)
+			text := tools.FollowUpAnnouncement(a)
+			p.setFollowUp(text)
+			p.announceReminder(text)
+			continue
+		}
+		if a.Gentle {
+			p.startGentleWake(ctx, a)
+			continue
+		}
+		logger.Infof("[pipeline] 闹钟到期: %s", a.Message)
+		p.ringAlarm(a)
+	}
+}
+
+// checkHealthReminders 检查并播报到期的健康提醒。
+func (p *Pipeline) checkHealthReminders(ctx context.Context) {
+	reminders := p.healthStore.CheckAndTrigger()
+	for _, r := range reminders {
+		logger.Infof("[pipeline] 健康提醒: %s", r.Message)
+		p.announce(ctx, speechNormal, r.Message)
+	}
+}
+
+// onSoundEvent 检测到声音事件时回调 Webhook 并语音播报。
+// 空闲时才分析环境声音，播报前再确认一次没有进入对话。
+func (p *Pipeline) onSoundEvent(ctx context.Context, ev sound.Event) {
+	if url := p.cfg.SoundEvents.Webhook; url != "" {
+		go func() {
+			if err := sound.PostWebhook(ctx, url, ev); err != nil {
+				logger.Warnf("[pipeline] 声音事件通知失败: %v", err)
+			}
+		}()
+	}
+	if ev.Announce == "" || p.state.Current() != StateIdle {
+		return
+	}
+	p.announce(ctx, speechNormal, ev.Announce)
+}
+
+// checkMusicServer 检查音乐 API 服务，状态变化时记录日志；托管模式下自动（重新）启动。
+func (p *Pipeline) checkMusicServer(ctx context.Context) {
+	wasDown := p.musicServer.LastError() != nil
+	if err := p.musicServer.EnsureRunning(ctx); err != nil {
+		if !wasDown {
+			logger.Warnf("[pipeline] %v", err)
+		}
+		return
+	}
+	if wasDown {
+		logger.Info("[pipeline] 音乐 API 服务已恢复")
+	}
+}
+
+// handleToolFailures 返回最近识别出的工具故障（音乐服务未启动、登录过期等）。
+func (p *Pipeline) handleToolFailures(w http.ResponseWriter, r *http.Request) {
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"failures": p.toolFailures.Recent(),
+	})
+}
+
+// toolCatalog 返回已注册工具的目录，并标出只有主人可用的工具。
+func (p *Pipeline) toolCatalog() []tools.CatalogEntry {
+	entries := p.toolRegistry.Catalog()
+	for i := range entries {
+		entries[i].OwnerOnly = p.ownerRequired(entries[i].Name)
+	}
+	return entries
+}
+
+// handleToolCatalog 返回所有已注册工具的名称、描述和参数 JSON Schema，用于生成能力说明、核对提示词中的工具名。
+func (p *Pipeline) handleToolCatalog(w http.ResponseWriter, r *http.Request) {
+	entries := p.toolCatalog()
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"count":   len(entries),
+		"tools":   entries,
+	})
+}
+
+// handleToolOpenAPI 以 OpenAPI 3 格式返回工具目录。
+func (p *Pipeline) handleToolOpenAPI(w http.ResponseWriter, r *http.Request) {
+	version := "dev"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	admin.WriteJSON(w, http.StatusOK, tools.CatalogOpenAPI(p.toolCatalog(), "PiBuddy 工具", version))
+}
+
+// handleSchedulerJobs 返回所有定时任务的运行状态。
+func (p *Pipeline) handleSchedulerJobs(w http.ResponseWriter, r *http.Request) {
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"jobs":    p.scheduler.Jobs(),
+	})
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+// 回复语言跟随最近识别到的声纹用户的偏好（language: en）：大模型的回复要求由 system prompt 中的用户偏好带上，
+// 这里负责切换 TTS 发音人和固定话术。未识别出说话人或偏好中文时回到默认的中文。
+
+// englishPhrases 固定话术的英文版本，按中文原文查找。
+var englishPhrases = map[string]string{
+	"大模型余额不足，请充值后再试":             "The language model account is out of credit. Please top it up and try again.",
+	"网络连接失败，请检查网络设置":             "The network connection failed. Please check the network settings.",
+	"现在连不上大模型，这个暂时做不了，等网络恢复后再试吧": "I can't reach the language model right now, so I can't do that. Please try again once the network is back.",
+	"这次没能得出结果，换个说法再问我一次吧":        "I couldn't work that out this time. Please try asking in a different way.",
+}
+
+// newEdgeEngine 创建 Edge TTS 引擎，并配置英语发音人。
+func newEdgeEngine(cfg config.EdgeConfig) *tts.EdgeEngine {
+	engine := tts.NewEdgeEngine(cfg.Voice)
+	engine.SetLanguageVoice(llm.LanguageEnglish, cfg.EnglishVoice)
+	return engine
+}
+
+// replyLanguage 当前的回复语言，空为中文。
+func (p *Pipeline) replyLanguage() string {
+	p.langMu.Lock()
+	defer p.langMu.Unlock()
+	return p.replyLang
+}
+
+// setReplyLanguage 切换回复语言，同时切换支持的 TTS 引擎的发音人。
+func (p *Pipeline) setReplyLanguage(lang string) {
+	p.langMu.Lock()
+	changed := p.replyLang != lang
+	p.replyLang = lang
+	p.langMu.Unlock()
+	if !changed {
+		return
+	}
+	p.setTTSLanguage(lang)
+	if lang == "" {
+		logger.Infof("[pipeline] 回复语言切换为中文")
+	} else {
+		logger.Infof("[pipeline] 回复语言切换为 %s", lang)
+	}
+}
+
+// setTTSLanguage 切换主备 TTS 引擎到该语言的发音人，不支持的引擎忽略。
+func (p *Pipeline) setTTSLanguage(lang string) {
+	for _, engine := range []tts.Engine{p.ttsEngine, p.fallbackTtsEngine} {
+		if vs, ok := engine.(tts.VoiceSwitchable); ok {
+			vs.SetLanguage(lang)
+		}
+	}
+}
+
+// localize 按当前回复语言返回固定话术，没有对应译文时原样返回。
+func (p *Pipeline) localize(text string) string {
+	if p.replyLanguage() == llm.LanguageEnglish {
+		if en, ok := englishPhrases[text]; ok {
+			return en
+		}
+	}
+	return text
+}
+
+// localizedWakeReply 唤醒回复语。唤醒时还不知道是谁，沿用上一位说话人的语言；wake_reply 为空时不播放。
+// 中文回复从配置的变体中选一条（见 chooseWakeReply）。
+func (p *Pipeline) localizedWakeReply() string {
+	reply := p.wakeReply()
+	if reply != "" && p.replyLanguage() == llm.LanguageEnglish {
+		return p.cfg.Dialog.EnglishWakeReply
+	}
+	if p.settings.Has(database.SettingWakeReply) {
+		// 用语音设置过唤醒回复语时只使用设置的回复语，配置文件中的变体不再生效
+		return p.settings.GetString(database.SettingWakeReply, reply)
+	}
+	p.dialogMu.RLock()
+	variants, timed := p.cfg.Dialog.WakeReplies, p.cfg.Dialog.TimedWakeReplies
+	p.dialogMu.RUnlock()
+	if len(variants) == 0 && len(timed) == 0 {
+		return reply
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return chooseWakeReply(reply, variants, timed, time.Now(), p.recentWakeSpeaker(), rng)
+}
@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+func TestLocalize(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{}}
+	p.cfg.Dialog.WakeReply = "我在"
+	p.cfg.Dialog.EnglishWakeReply = "I'm here"
+
+	const phrase = "网络连接失败，请检查网络设置"
+	if got := p.localize(phrase); got != phrase {
+		t.Errorf("chinese localize = %q", got)
+	}
+	if got := p.localizedWakeReply(); got != "我在" {
+		t.Errorf("chinese wake reply = %q", got)
+	}
+
+	p.setReplyLanguage(llm.LanguageEnglish)
+	if got := p.localize(phrase); got != englishPhrases[phrase] {
+		t.Errorf("english localize = %q", got)
+	}
+	if got := p.localize("没有译文的话"); got != "没有译文的话" {
+		t.Errorf("untranslated phrase = %q", got)
+	}
+	if got := p.localizedWakeReply(); got != "I'm here" {
+		t.Errorf("english wake reply = %q", got)
+	}
+
+	// 关闭唤醒回复语时英语也不播放
+	p.cfg.Dialog.WakeReply = ""
+	if got := p.localizedWakeReply(); got != "" {
+		t.Errorf("disabled wake reply = %q", got)
+	}
+
+	p.setReplyLanguage("")
+	if got := p.localize(phrase); got != phrase {
+		t.Errorf("back to chinese = %q", got)
+	}
+}
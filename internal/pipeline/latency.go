@@ -0,0 +1,273 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// latencyHistory 保留最近多少次对话的耗时分解。
+const latencyHistory = 50
+
+// LatencyBreakdown 一次对话各阶段的耗时（毫秒）。
+type LatencyBreakdown struct {
+	Time            time.Time `json:"time"`
+	Query           string    `json:"query"`
+	WakeToASREnd    int64     `json:"wake_to_asr_end_ms"`    // 唤醒 → 识别结束（连续对话中没有唤醒，为 0）
+	ASRToFirstToken int64     `json:"asr_to_first_token_ms"` // 识别结束 → 大模型第一个字
+	Tools           int64     `json:"tools_ms"`              // 工具调用总耗时
+	TTSSynth        int64     `json:"tts_synth_ms"`          // 第一段语音合成耗时
+	ASRToPlayback   int64     `json:"asr_to_playback_ms"`    // 识别结束 → 开始播放回复
+}
+
+// String 日志中的耗时分解。
+func (b LatencyBreakdown) String() string {
+	var parts []string
+	if b.WakeToASREnd > 0 {
+		parts = append(parts, fmt.Sprintf("唤醒→识别结束 %dms", b.WakeToASREnd))
+	}
+	parts = append(parts,
+		fmt.Sprintf("识别→首字 %dms", b.ASRToFirstToken),
+		fmt.Sprintf("工具 %dms", b.Tools),
+		fmt.Sprintf("合成 %dms", b.TTSSynth),
+		fmt.Sprintf("识别→开始播放 %dms", b.ASRToPlayback),
+	)
+	return strings.Join(parts, "，")
+}
+
+// latencyProfiler 记录每次对话从唤醒到开始播放回复的各阶段耗时（dialog.profile_latency），
+// 写入日志并在管理 API 中汇总，便于比较不同版本的延迟变化。未开启时为 nil，所有方法都是空操作。
+type latencyProfiler struct {
+	mu           sync.Mutex
+	wakeAt       time.Time
+	asrEndAt     time.Time
+	firstTokenAt time.Time
+	playbackAt   time.Time
+	tools        time.Duration
+	synth        time.Duration
+	query        string
+	recent       []LatencyBreakdown
+}
+
+// markWake 检测到唤醒词。
+func (l *latencyProfiler) markWake() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.wakeAt = time.Now()
+}
+
+// markASREnd 识别出完整的一句话，开始计算这次对话的耗时。
+func (l *latencyProfiler) markASREnd(query string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 上一句没有走完整流程（如即时指令），它的唤醒时间不属于这一句
+	if !l.asrEndAt.IsZero() {
+		l.wakeAt = time.Time{}
+	}
+	l.asrEndAt = time.Now()
+	l.firstTokenAt, l.playbackAt = time.Time{}, time.Time{}
+	l.tools, l.synth = 0, 0
+	l.query = query
+}
+
+// markFirstToken 大模型返回第一段内容（文本或工具调用）。
+func (l *latencyProfiler) markFirstToken() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.asrEndAt.IsZero() && l.firstTokenAt.IsZero() {
+		l.firstTokenAt = time.Now()
+	}
+}
+
+// addTool 累加工具调用耗时。
+func (l *latencyProfiler) addTool(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.asrEndAt.IsZero() {
+		l.tools += d
+	}
+}
+
+// addSynth 记录识别结束后第一段语音的合成耗时。
+func (l *latencyProfiler) addSynth(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.asrEndAt.IsZero() && l.synth == 0 {
+		l.synth = d
+	}
+}
+
+// markPlayback 识别结束后第一次开始播放语音。
+func (l *latencyProfiler) markPlayback() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.asrEndAt.IsZero() && l.playbackAt.IsZero() {
+		l.playbackAt = time.Now()
+	}
+}
+
+// finish 对话结束，输出耗时分解。
+func (l *latencyProfiler) finish() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if l.asrEndAt.IsZero() {
+		l.mu.Unlock()
+		return
+	}
+	b := LatencyBreakdown{
+		Time:     l.asrEndAt,
+		Query:    l.query,
+		Tools:    l.tools.Milliseconds(),
+		TTSSynth: l.synth.Milliseconds(),
+	}
+	if !l.wakeAt.IsZero() {
+		b.WakeToASREnd = l.asrEndAt.Sub(l.wakeAt).Milliseconds()
+	}
+	if !l.firstTokenAt.IsZero() {
+		b.ASRToFirstToken = l.firstTokenAt.Sub(l.asrEndAt).Milliseconds()
+	}
+	if !l.playbackAt.IsZero() {
+		b.ASRToPlayback = l.playbackAt.Sub(l.asrEndAt).Milliseconds()
+	}
+	l.recent = append(l.recent, b)
+	if len(l.recent) > latencyHistory {
+		l.recent = l.recent[len(l.recent)-latencyHistory:]
+	}
+	l.wakeAt, l.asrEndAt = time.Time{}, time.Time{}
+	l.mu.Unlock()
+
+	logger.Infof("[pipeline] 耗时分解: %s", b)
+}
+
+// Recent 返回最近的耗时分解（从旧到新）。
+func (l *latencyProfiler) Recent() []LatencyBreakdown {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LatencyBreakdown(nil), l.recent...)
+}
+
+// averageLatency 各阶段的平均耗时，只统计有该阶段的对话。
+func averageLatency(list []LatencyBreakdown) map[string]int64 {
+	sums := make(map[string]int64)
+	counts := make(map[string]int64)
+	add := func(name string, v int64) {
+		if v > 0 {
+			sums[name] += v
+			counts[name]++
+		}
+	}
+	for _, b := range list {
+		add("wake_to_asr_end_ms", b.WakeToASREnd)
+		add("asr_to_first_token_ms", b.ASRToFirstToken)
+		add("tools_ms", b.Tools)
+		add("tts_synth_ms", b.TTSSynth)
+		add("asr_to_playback_ms", b.ASRToPlayback)
+	}
+	avg := make(map[string]int64, len(sums))
+	for name, sum := range sums {
+		avg[name] = sum / counts[name]
+	}
+	return avg
+}
+
+// handleLatency 返回最近对话的耗时分解和各阶段平均值。
+func (p *Pipeline) handleLatency(w http.ResponseWriter, r *http.Request) {
+	if p.latency == nil {
+		admin.WriteError(w, http.StatusNotFound, "未开启耗时分析（dialog.profile_latency）")
+		return
+	}
+	recent := p.latency.Recent()
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"count":   len(recent),
+		"average": averageLatency(recent),
+		"recent":  recent,
+	})
+}
+
+// handleLatencyDryRun 不经过麦克风和扬声器空跑一次：把请求中的文本交给大模型（不带工具、不写入对话上下文），
+// 再合成回复的第一段，返回各阶段耗时。用于发版前后对比大模型和语音合成的延迟。
+func (p *Pipeline) handleLatencyDryRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err == nil {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil || strings.TrimSpace(req.Text) == "" {
+		admin.WriteError(w, http.StatusBadRequest, "请求体应为 {\"text\": \"问题\"}")
+		return
+	}
+
+	ctx := r.Context()
+	messages := p.contextManager.Messages()
+	start := time.Now()
+	textCh, err := p.llmProvider.ChatStream(ctx, []llm.Message{messages[0], {Role: "user", Content: req.Text}})
+	if err != nil {
+		admin.WriteError(w, http.StatusBadGateway, fmt.Sprintf("大模型请求失败: %v", err))
+		return
+	}
+	var firstToken time.Duration
+	var reply strings.Builder
+	for chunk := range textCh {
+		if firstToken == 0 {
+			firstToken = time.Since(start)
+		}
+		reply.WriteString(chunk)
+	}
+	llmTotal := time.Since(start)
+
+	// 与正式朗读一样按段合成，只合成第一段
+	var first string
+	if chunks := mergeSentences(strings.TrimSpace(reply.String()), streamMaxRunes); len(chunks) > 0 {
+		first = chunks[0]
+	}
+	var synth time.Duration
+	var synthErr string
+	if first != "" {
+		synthStart := time.Now()
+		if _, _, err := p.ttsEngine.Synthesize(ctx, first); err != nil {
+			synthErr = err.Error()
+		}
+		synth = time.Since(synthStart)
+	}
+
+	logger.Infof("[pipeline] 空跑耗时: 首字 %dms，大模型完成 %dms，合成 %dms", firstToken.Milliseconds(), llmTotal.Milliseconds(), synth.Milliseconds())
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":        true,
+		"reply":          reply.String(),
+		"first_token_ms": firstToken.Milliseconds(),
+		"llm_total_ms":   llmTotal.Milliseconds(),
+		"tts_synth_ms":   synth.Milliseconds(),
+		"tts_error":      synthErr,
+	})
+}
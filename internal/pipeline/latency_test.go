@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyProfiler(t *testing.T) {
+	l := &latencyProfiler{}
+	// 唤醒回复语在识别结束前合成，不计入
+	l.markWake()
+	l.addSynth(time.Second)
+	l.markPlayback()
+	time.Sleep(5 * time.Millisecond)
+	l.markASREnd("今天天气怎么样")
+	l.markFirstToken()
+	l.addTool(30 * time.Millisecond)
+	l.addTool(20 * time.Millisecond)
+	l.addSynth(40 * time.Millisecond)
+	l.addSynth(time.Second) // 只记录第一段
+	l.markPlayback()
+	l.finish()
+
+	recent := l.Recent()
+	if len(recent) != 1 {
+		t.Fatalf("recent = %d, want 1", len(recent))
+	}
+	b := recent[0]
+	if b.Query != "今天天气怎么样" || b.WakeToASREnd < 5 || b.Tools != 50 || b.TTSSynth != 40 {
+		t.Errorf("breakdown = %+v", b)
+	}
+
+	// 连续对话：没有唤醒
+	l.markASREnd("明天呢")
+	l.finish()
+	if b := l.Recent()[1]; b.WakeToASREnd != 0 {
+		t.Errorf("连续对话不应有唤醒耗时: %+v", b)
+	}
+
+	// 没有识别结果时 finish 不记录
+	l.finish()
+	if len(l.Recent()) != 2 {
+		t.Errorf("recent = %d, want 2", len(l.Recent()))
+	}
+}
+
+func TestLatencyProfiler_Nil(t *testing.T) {
+	var l *latencyProfiler
+	l.markWake()
+	l.markASREnd("你好")
+	l.markFirstToken()
+	l.addTool(time.Second)
+	l.addSynth(time.Second)
+	l.markPlayback()
+	l.finish()
+}
+
+func TestAverageLatency(t *testing.T) {
+	avg := averageLatency([]LatencyBreakdown{
+		{ASRToFirstToken: 100, Tools: 0},
+		{ASRToFirstToken: 300, Tools: 50},
+	})
+	if avg["asr_to_first_token_ms"] != 200 || avg["tools_ms"] != 50 {
+		t.Errorf("average = %v", avg)
+	}
+	if _, ok := avg["wake_to_asr_end_ms"]; ok {
+		t.Error("没有数据的阶段不应出现")
+	}
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// speechTargetLUFS 语音播报的目标响度：开启跟随音乐且播放过音乐时取音乐响度加偏移，否则取配置值。
+func (p *Pipeline) speechTargetLUFS() float64 {
+	cfg := p.cfg.TTS.Loudness
+	if cfg.MatchMusic && p.musicPlayer != nil {
+		if music, ok := p.musicPlayer.Loudness(); ok {
+			return music + cfg.MusicOffsetDB
+		}
+	}
+	return cfg.TargetLUFS
+}
+
+// normalizeSpeech 把合成的语音调整到目标响度（原地修改），未开启响度匹配时不处理。
+// 提示音、报时等音效不经过这里，保持原始音量。
+func (p *Pipeline) normalizeSpeech(samples []float32, sampleRate int) {
+	if !p.cfg.TTS.Loudness.Enabled {
+		return
+	}
+	target := p.speechTargetLUFS()
+	gain := audio.NormalizeLoudness(samples, sampleRate, target, p.cfg.TTS.Loudness.MaxGainDB)
+	logger.Debugf("[pipeline] 语音响度调整 %+.1f dB（目标 %.1f LUFS）", gain, target)
+}
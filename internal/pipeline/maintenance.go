@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/music"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+const (
+	maintenanceCheckInterval = 200 * time.Millisecond // 维护期间检查设备是否仍然空闲的间隔
+	vacuumInterval           = 24 * time.Hour         // 数据库压缩间隔
+)
+
+// deviceIdle 判断设备是否完全空闲：没有对话、没有播放音乐。
+func (p *Pipeline) deviceIdle() bool {
+	if p.state.Current() != StateIdle || p.isConversationActive() {
+		return false
+	}
+	return p.playback == nil || !p.playback.Playing()
+}
+
+// runMaintenance 空闲时的后台维护：校验音乐缓存、刷新 RSS 缓存、预下载收藏、压缩数据库。
+// 维护期间一旦唤醒（或开始播放）立即取消，剩下的工作留到下次。
+func (p *Pipeline) runMaintenance(ctx context.Context) {
+	if !p.deviceIdle() {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(maintenanceCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !p.deviceIdle() {
+					logger.Debug("[pipeline] 设备不再空闲，后台维护让出")
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	if p.musicCache != nil {
+		if n := p.musicCache.Verify(ctx); n > 0 {
+			logger.Infof("[pipeline] 后台维护：清理 %d 个损坏或残留的缓存文件", n)
+		}
+	}
+	if p.rssFetcher != nil && ctx.Err() == nil {
+		if n := p.rssFetcher.Refresh(ctx); n > 0 {
+			logger.Infof("[pipeline] 后台维护：刷新 %d 个订阅源", n)
+		}
+	}
+	if n := p.prefetchFavorites(ctx); n > 0 {
+		logger.Infof("[pipeline] 后台维护：预下载 %d 首收藏歌曲", n)
+	}
+	if p.db != nil && ctx.Err() == nil && time.Since(p.lastVacuum) >= vacuumInterval {
+		if err := p.db.Vacuum(ctx); err != nil {
+			logger.Warnf("[pipeline] 后台维护：%v", err)
+		} else {
+			p.lastVacuum = time.Now()
+			logger.Info("[pipeline] 后台维护：数据库已压缩")
+		}
+	}
+}
+
+// prefetchFavorites 为每位用户下载收藏列表中前几首还没缓存的歌，播放收藏时直接从本地播放。
+func (p *Pipeline) prefetchFavorites(ctx context.Context) int {
+	limit := p.cfg.Maintenance.PrefetchFavorites
+	if limit <= 0 || p.favoritesStore == nil || p.musicProvider == nil || p.musicCache == nil || !p.musicCache.Enabled() {
+		return 0
+	}
+	downloaded := 0
+	for _, user := range p.favoritesStore.Users() {
+		songs, err := p.favoritesStore.List(user)
+		if err != nil {
+			continue
+		}
+		pending := 0
+		for _, s := range songs {
+			if ctx.Err() != nil || pending >= limit {
+				break
+			}
+			key := tools.FavoriteCacheKey(p.musicProvider, s)
+			if key == "" || p.musicCache.Has(key) {
+				continue
+			}
+			pending++
+			url, err := music.ResolveSongURL(ctx, p.musicProvider, s.Song())
+			if err != nil || url == "" {
+				logger.Debugf("[pipeline] 预下载 %s - %s 失败: 获取播放地址失败 %v", s.Name, s.Artist, err)
+				continue
+			}
+			entry := audio.CacheEntry{ID: s.ID, Name: s.Name, Artist: s.Artist, Album: s.Album, Provider: p.musicProvider.ProviderName(), ProviderID: s.ID}
+			if err := p.musicCache.Download(ctx, key, url, entry); err != nil {
+				logger.Debugf("[pipeline] 预下载 %s - %s 失败: %v", s.Name, s.Artist, err)
+				continue
+			}
+			downloaded++
+		}
+	}
+	return downloaded
+}
@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// songProvider 把歌曲 ID 映射为测试服务器上的下载地址。
+type songProvider struct{ url string }
+
+func (s *songProvider) Search(ctx context.Context, keyword string, limit int) ([]music.Song, error) {
+	return nil, nil
+}
+
+func (s *songProvider) GetSongURL(ctx context.Context, songID int64) (string, error) {
+	return fmt.Sprintf("%s/%d.mp3", s.url, songID), nil
+}
+
+func (s *songProvider) ProviderName() string { return "qq" }
+
+func TestPrefetchFavoritesAndVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ID3 fake mp3 data"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	db, err := database.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := audio.NewMusicCache(db, filepath.Join(dir, "cache"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	favorites := music.NewFavoritesStore(dir)
+	for _, s := range []music.FavoriteSong{
+		{ID: 1, Name: "晴天", Provider: "qq"},
+		{ID: 2, Name: "稻香", Provider: "qq"},
+		{ID: 3, Name: "七里香", Provider: "qq"},
+		{ID: 4, Name: "网易云的歌", Provider: "netease"},
+	} {
+		if err := favorites.Add("小明", s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Maintenance.PrefetchFavorites = 2
+	p := &Pipeline{cfg: cfg, favoritesStore: favorites, musicCache: cache, musicProvider: &songProvider{url: server.URL}}
+
+	if n := p.prefetchFavorites(context.Background()); n != 2 {
+		t.Fatalf("prefetched %d songs, want 2", n)
+	}
+	if !cache.Has("qq_1") || !cache.Has("qq_2") || cache.Has("qq_3") {
+		t.Error("should prefetch the first 2 uncached favorites")
+	}
+	if n := p.prefetchFavorites(context.Background()); n != 1 || !cache.Has("qq_3") {
+		t.Errorf("second run prefetched %d songs, want the remaining one", n)
+	}
+	if cache.Has("qq_4") || cache.Has("netease_4") {
+		t.Error("favorites from another provider should be skipped")
+	}
+
+	// 文件被截断时校验会移除该条目
+	if err := os.WriteFile(cache.FilePath("qq_2"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n := cache.Verify(context.Background()); n != 1 {
+		t.Errorf("Verify removed %d entries, want 1", n)
+	}
+	if cache.Has("qq_2") || !cache.Has("qq_1") {
+		t.Error("Verify should only remove the corrupted entry")
+	}
+}
+
+func TestRunMaintenance_SkipsWhenBusy(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Maintenance.PrefetchFavorites = 5
+	p := &Pipeline{cfg: cfg, state: NewStateMachine()}
+	p.state.Transition(StateListening)
+	if p.deviceIdle() {
+		t.Fatal("device should not be idle while listening")
+	}
+	p.runMaintenance(context.Background()) // 不应访问未初始化的缓存和数据库
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+// terminalTools 执行成功后会结束本次对话的工具：直接朗读故事、进入听写、休息。
+var terminalTools = map[string]bool{
+	"tell_story":      true,
+	"start_dictation": true,
+	"go_to_sleep":     true,
+}
+
+// toolCallRank 工具调用的执行顺序：普通工具、播放音乐、会结束对话的工具。
+func toolCallRank(name string) int {
+	switch {
+	case terminalTools[name]:
+		return 2
+	case playbackTools[name]:
+		return 1
+	}
+	return 0
+}
+
+// orderToolCalls 把播放音乐和会结束对话的工具调用排到最后，其余保持原顺序。
+// 这样"讲个故事然后关灯"会先关灯，而不是讲完故事后就不再执行后面的请求。
+func orderToolCalls(calls []llm.ToolCall) []llm.ToolCall {
+	ordered := make([]llm.ToolCall, len(calls))
+	copy(ordered, calls)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return toolCallRank(ordered[i].Function.Name) < toolCallRank(ordered[j].Function.Name)
+	})
+	return ordered
+}
+
+// terminalNote 会结束对话的工具在多个请求中先不执行时，交给大模型的工具结果。
+func terminalNote(action string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"success": true,
+		"message": action + "。请用一句话简短回复其他请求的结果，不要再调用工具",
+	})
+	return string(data)
+}
+
+// skippedToolResult 同一轮中第二个会结束对话的工具调用不再执行。
+const skippedToolResult = `{"success":false,"message":"一次只能进行一项播放，已忽略"}`
+
+// finishTerminal 处理会结束对话的工具调用。
+// 单个请求时沿用原有做法：移除本轮的工具调用消息后直接执行 action。
+// 一句话里有多个请求（本轮还有其他工具调用，或之前几轮已执行过工具）时，
+// 保留完整的工具调用记录，让大模型先简短确认其他请求的结果并朗读，再执行 action。
+// rest 为本轮排在它后面、尚未执行的工具调用。
+func (p *Pipeline) finishTerminal(ctx context.Context, tc llm.ToolCall, rest []llm.ToolCall, multi bool, roundMessages int, action string, run func()) {
+	if !multi {
+		p.contextManager.RemoveLastMessages(1 + roundMessages)
+		run()
+		return
+	}
+
+	logger.Infof("[pipeline] 多个请求：先确认已完成的操作，再%s", action)
+	p.contextManager.AddMessage(llm.Message{
+		Role:       "tool",
+		Content:    terminalNote("回复后" + action),
+		ToolCallID: tc.ID,
+		Name:       tc.Function.Name,
+	})
+	for _, other := range rest {
+		p.contextManager.AddMessage(llm.Message{
+			Role:       "tool",
+			Content:    skippedToolResult,
+			ToolCallID: other.ID,
+			Name:       other.Function.Name,
+		})
+	}
+	p.confirmActions(ctx)
+	if !p.interrupted.Load() {
+		run()
+	}
+}
+
+// confirmActions 不带工具调用大模型，朗读对已完成操作的简短确认。
+func (p *Pipeline) confirmActions(ctx context.Context) {
+	textCh, err := p.llmProvider.ChatStream(ctx, p.contextManager.Messages())
+	if err != nil {
+		logger.Warnf("[pipeline] 生成确认回复失败: %v", err)
+		return
+	}
+	var reply strings.Builder
+	for chunk := range textCh {
+		reply.WriteString(chunk)
+	}
+	text := strings.TrimSpace(reply.String())
+	if text == "" || p.interrupted.Load() {
+		return
+	}
+	p.contextManager.Add("assistant", text)
+	p.state.Transition(StateSpeaking)
+	logger.Infof("[小派] %s", text)
+	p.speakText(ctx, tts.PreprocessText(text))
+}
+
+// toolFailureResult 多个请求中某个工具出现常见故障时交给大模型的结果，带上具体提示，
+// 由大模型在回答其他请求时一并说明。
+func toolFailureResult(f tools.ToolFailure) string {
+	content, _ := json.Marshal(map[string]interface{}{"success": false, "message": f.Hint})
+	return string(content)
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// silentProvider 不返回任何文本的 LLM。
+type silentProvider struct{}
+
+func (silentProvider) ChatStream(ctx context.Context, messages []llm.Message) (<-chan string, error) {
+	ch := make(chan string)
+	close(ch)
+	return ch, nil
+}
+
+func (silentProvider) ChatStreamWithTools(ctx context.Context, messages []llm.Message, tools []llm.ToolDefinition) (<-chan string, <-chan *llm.StreamResult, error) {
+	ch := make(chan string)
+	close(ch)
+	res := make(chan *llm.StreamResult, 1)
+	res <- nil
+	return ch, res, nil
+}
+
+func toolCall(id, name string) llm.ToolCall {
+	return llm.ToolCall{ID: id, Type: "function", Function: llm.FunctionCall{Name: name, Arguments: "{}"}}
+}
+
+func TestOrderToolCalls(t *testing.T) {
+	calls := []llm.ToolCall{
+		toolCall("1", "play_music"),
+		toolCall("2", "ha_control_device"),
+		toolCall("3", "tell_story"),
+		toolCall("4", "get_weather"),
+	}
+	got := orderToolCalls(calls)
+	want := []string{"2", "4", "1", "3"}
+	for i, tc := range got {
+		if tc.ID != want[i] {
+			t.Fatalf("顺序 = %v, want %v", got, want)
+		}
+	}
+	if calls[0].ID != "1" {
+		t.Error("不应修改原切片")
+	}
+}
+
+func TestFinishTerminal(t *testing.T) {
+	calls := []llm.ToolCall{toolCall("1", "ha_control_device"), toolCall("2", "play_music"), toolCall("3", "tell_story")}
+
+	// 单个请求：移除工具调用消息后直接执行
+	p := &Pipeline{contextManager: llm.NewContextManager("", 10), llmProvider: silentProvider{}}
+	p.contextManager.Add("user", "放首歌")
+	p.contextManager.AddMessage(llm.Message{Role: "assistant", ToolCalls: calls[1:2]})
+	ran := false
+	p.finishTerminal(context.Background(), calls[1], nil, false, 0, "播放", func() { ran = true })
+	if !ran {
+		t.Error("应执行动作")
+	}
+	if msgs := p.contextManager.Messages(); msgs[len(msgs)-1].Role != "user" {
+		t.Errorf("单个请求应移除工具调用消息, got %+v", msgs[len(msgs)-1])
+	}
+
+	// 多个请求：保留完整的工具调用记录，未执行的调用补上结果
+	p = &Pipeline{contextManager: llm.NewContextManager("", 10), llmProvider: silentProvider{}}
+	p.contextManager.Add("user", "关灯然后放点歌")
+	p.contextManager.AddMessage(llm.Message{Role: "assistant", ToolCalls: calls})
+	p.contextManager.AddMessage(llm.Message{Role: "tool", Content: `{"success":true}`, ToolCallID: "1"})
+	ran = false
+	p.finishTerminal(context.Background(), calls[1], calls[2:], true, 1, "播放", func() { ran = true })
+	if !ran {
+		t.Error("应执行动作")
+	}
+	answered := map[string]bool{}
+	for _, m := range p.contextManager.Messages() {
+		if m.Role == "tool" {
+			answered[m.ToolCallID] = true
+		}
+	}
+	if len(answered) != 3 {
+		t.Errorf("每个工具调用都应有结果, got %v", answered)
+	}
+}
+
+func TestToolFailureResult(t *testing.T) {
+	failure, ok := tools.MapToolError("play_music", "", errors.New("dial tcp 127.0.0.1:3000: connect: connection refused"))
+	if !ok {
+		t.Fatal("音乐服务未启动应映射为常见故障")
+	}
+	var result struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(toolFailureResult(failure)), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.Message != failure.Hint {
+		t.Errorf("结果应带上失败提示, got %+v", result)
+	}
+}
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// handleMusicExport 导出收藏、播放历史或缓存索引：GET /api/music/export/{kind}?format=csv&user=小明，
+// kind 为 favorites、history、cache，format 为 m3u（默认）或 csv，响应为可下载的文件。
+func (p *Pipeline) handleMusicExport(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = music.FormatM3U
+	}
+	user := r.URL.Query().Get("user")
+
+	exporter := &music.Exporter{Favorites: p.favoritesStore, History: p.musicHistory, Cache: p.musicCache}
+	var buf bytes.Buffer
+	if err := exporter.Export(&buf, kind, format, user); err != nil {
+		admin.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == music.FormatM3U {
+		contentType = "audio/x-mpegurl; charset=utf-8"
+	}
+	name := fmt.Sprintf("%s-%s.%s", kind, time.Now().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(name))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// openMicPreRoll 连续聊天模式下检测到说话时补给 ASR 的音频，VAD 判定为说话前的开头不会丢。
+const openMicPreRoll = 800 * time.Millisecond
+
+// openMicSession 一次连续聊天模式（免唤醒词），到期自动退出。
+type openMicSession struct {
+	timer *time.Timer
+}
+
+// openMicActive 是否处于连续聊天模式。
+func (p *Pipeline) openMicActive() bool {
+	p.openMicMu.Lock()
+	defer p.openMicMu.Unlock()
+	return p.openMic != nil
+}
+
+// startOpenMic 开启连续聊天模式：空闲时检测到说话就直接识别，不需要唤醒词。已开启时重新计时。
+func (p *Pipeline) startOpenMic(d time.Duration) {
+	p.openMicMu.Lock()
+	defer p.openMicMu.Unlock()
+	if p.openMic != nil {
+		p.openMic.timer.Stop()
+	}
+	session := &openMicSession{}
+	session.timer = time.AfterFunc(d, func() { p.expireOpenMic(session) })
+	p.openMic = session
+	logger.Infof("[pipeline] 进入连续聊天模式，%s 后自动退出", d)
+}
+
+// stopOpenMic 退出连续聊天模式，未开启时返回 false。
+func (p *Pipeline) stopOpenMic() bool {
+	p.openMicMu.Lock()
+	defer p.openMicMu.Unlock()
+	if p.openMic == nil {
+		return false
+	}
+	p.openMic.timer.Stop()
+	p.openMic = nil
+	logger.Info("[pipeline] 已退出连续聊天模式")
+	return true
+}
+
+// expireOpenMic 连续聊天模式到期：等当前对话结束后播报已退出。
+func (p *Pipeline) expireOpenMic(session *openMicSession) {
+	p.openMicMu.Lock()
+	if p.openMic != session {
+		p.openMicMu.Unlock()
+		return
+	}
+	p.openMic = nil
+	p.openMicMu.Unlock()
+	logger.Info("[pipeline] 连续聊天模式已到期")
+
+	p.announce(context.Background(), speechLow, "连续聊天模式结束了，需要时再叫我的名字")
+}
+
+// detectOpenMicSpeech 连续聊天模式下空闲时用 VAD 检测说话，检测到后直接进入监听。
+func (p *Pipeline) detectOpenMicSpeech(frame []float32) {
+	if !p.openMicActive() || p.playback.Playing() {
+		return
+	}
+	p.vadDetector.Feed(frame)
+	if !p.vadDetector.IsSpeech() {
+		return
+	}
+	logger.Info("[pipeline] 连续聊天模式：检测到说话")
+
+	p.vadDetector.Reset()
+	p.resetRecognizer()
+	if p.voiceprintMgr != nil && p.voiceprintMgr.NumSpeakers() > 0 {
+		p.voiceprintBufMu.Lock()
+		p.voiceprintBuf = make([]float32, 0, p.voiceprintBufSize)
+		p.voiceprintBufMu.Unlock()
+	}
+	p.state.Transition(StateListening)
+
+	// 补上 VAD 判定前的开头
+	if samples := p.capture.TakeRecent(openMicPreRoll); len(samples) > 0 {
+		p.vadDetector.Feed(samples)
+		p.recognizer.Feed(samples)
+		p.chargeASRBudget(len(samples))
+	}
+	if p.continuousTimeout() > 0 {
+		p.startContinuousTimer()
+	}
+}
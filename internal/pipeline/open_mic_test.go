@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpenMicStartStop(t *testing.T) {
+	p := &Pipeline{}
+	if p.openMicActive() || p.stopOpenMic() {
+		t.Fatal("open mic should start inactive")
+	}
+
+	p.startOpenMic(time.Hour)
+	first := p.openMic
+	p.startOpenMic(time.Hour) // 重新计时
+	if !p.openMicActive() || p.openMic == first {
+		t.Fatal("restart should replace the session")
+	}
+
+	// 被替换的会话到期时不影响当前会话
+	p.expireOpenMic(first)
+	if !p.openMicActive() {
+		t.Error("stale expiry should be ignored")
+	}
+
+	if !p.stopOpenMic() || p.openMicActive() {
+		t.Error("stop should end open mic")
+	}
+}
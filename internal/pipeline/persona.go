@@ -0,0 +1,123 @@
+package pipeline
+
+import (
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+// 人设（llm.personas）：每个人设替换 system prompt，并可更换发音人和回复详略。
+// 设备设置中保存用语音切换的人设；声纹用户偏好中设置了 persona 时，该用户说话时换成其默认人设，
+// 对话结束后回到设备设置中的人设。
+
+// personas 配置的人设列表，供切换工具匹配名称。
+func (p *Pipeline) personas() []tools.Persona {
+	personas := make([]tools.Persona, len(p.cfg.LLM.Personas))
+	for i, pc := range p.cfg.LLM.Personas {
+		personas[i] = tools.Persona{Name: pc.Name, Aliases: pc.Aliases}
+	}
+	return personas
+}
+
+// findPersona 按名称或别名查找人设配置。
+func (p *Pipeline) findPersona(name string) (config.PersonaConfig, bool) {
+	persona, ok := tools.MatchPersona(p.personas(), name)
+	if !ok {
+		return config.PersonaConfig{}, false
+	}
+	for _, pc := range p.cfg.LLM.Personas {
+		if pc.Name == persona.Name {
+			return pc, true
+		}
+	}
+	return config.PersonaConfig{}, false
+}
+
+// currentPersona 当前生效的人设名称，默认人设为空。
+func (p *Pipeline) currentPersona() string {
+	p.personaMu.Lock()
+	defer p.personaMu.Unlock()
+	return p.persona
+}
+
+// switchPersona 用语音切换人设（name 为空恢复默认），本次对话内不再跟随说话人的默认人设。
+func (p *Pipeline) switchPersona(name string) {
+	p.personaMu.Lock()
+	p.personaSwitched = true
+	p.personaMu.Unlock()
+	p.applyPersona(name)
+}
+
+// applyPersona 切换到人设：替换 system prompt、回复详略和支持的 TTS 引擎的发音人。
+// 名称为空或找不到时恢复默认人设。
+func (p *Pipeline) applyPersona(name string) {
+	pc, ok := p.findPersona(name)
+	if !ok && name != "" {
+		logger.Warnf("[pipeline] 没有找到人设 %q，使用默认人设", name)
+	}
+
+	p.personaMu.Lock()
+	changed := p.persona != pc.Name
+	p.persona = pc.Name
+	p.personaMu.Unlock()
+	if !changed {
+		return
+	}
+
+	p.contextManager.SetPersona(pc.SystemPrompt, pc.Verbosity)
+	p.setTTSVoice(pc.Voice)
+	if pc.Name == "" {
+		logger.Infof("[pipeline] 已恢复默认人设")
+	} else {
+		logger.Infof("[pipeline] 人设切换为 %s", pc.Name)
+	}
+}
+
+// setTTSVoice 切换主备 TTS 引擎的发音人，voice 为空时恢复配置的默认发音人。不支持更换发音人的引擎忽略。
+func (p *Pipeline) setTTSVoice(voice string) {
+	for _, engine := range []tts.Engine{p.ttsEngine, p.fallbackTtsEngine} {
+		if vs, ok := engine.(tts.VoiceSelectable); ok {
+			vs.SetVoice(voice)
+		}
+	}
+}
+
+// applySpeakerPersona 识别出说话人后切换到其默认人设，没有设置时使用设备设置中的人设。
+// 本次对话中已用语音切换过人设时保持不变。
+func (p *Pipeline) applySpeakerPersona() {
+	if len(p.cfg.LLM.Personas) == 0 {
+		return
+	}
+	p.personaMu.Lock()
+	switched := p.personaSwitched
+	p.personaMu.Unlock()
+	if switched {
+		return
+	}
+	name := p.contextManager.SpeakerPersona()
+	if name == "" {
+		name = p.devicePersona()
+	}
+	p.applyPersona(name)
+}
+
+// resetSessionPersona 对话结束时回到设备设置中的人设，下次对话重新跟随说话人。
+func (p *Pipeline) resetSessionPersona() {
+	if len(p.cfg.LLM.Personas) == 0 {
+		return
+	}
+	p.personaMu.Lock()
+	p.personaSwitched = false
+	p.personaMu.Unlock()
+	p.applyPersona(p.devicePersona())
+}
+
+// devicePersona 设备设置中保存的人设名称。
+func (p *Pipeline) devicePersona() string {
+	if p.settings == nil {
+		return ""
+	}
+	return p.settings.GetString(database.SettingPersona, "")
+}
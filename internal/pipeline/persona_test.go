@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+func TestPersona_SpeakerDefaultAndSwitch(t *testing.T) {
+	p := &Pipeline{
+		cfg: &config.Config{LLM: config.LLMConfig{Personas: []config.PersonaConfig{
+			{Name: "助教模式", SystemPrompt: "你是耐心的助教", Verbosity: llm.VerbosityDetailed},
+			{Name: "段子手模式", Aliases: []string{"段子手"}, SystemPrompt: "你是段子手"},
+		}}},
+		contextManager: llm.NewContextManager("你是小派", 5),
+	}
+	systemPrompt := func() string { return p.contextManager.Messages()[0].Content }
+
+	// 说话人偏好中的默认人设
+	p.contextManager.SetCurrentSpeaker("小明", &voiceprint.User{Preferences: `{"persona":"助教"}`})
+	p.applySpeakerPersona()
+	if p.currentPersona() != "助教模式" || !strings.HasPrefix(systemPrompt(), "你是耐心的助教") {
+		t.Fatalf("speaker persona not applied: %q", p.currentPersona())
+	}
+	if p.contextManager.Verbosity() != llm.VerbosityDetailed {
+		t.Errorf("persona verbosity = %s", p.contextManager.Verbosity())
+	}
+
+	// 对话中用语音切换后，不再跟随说话人
+	p.switchPersona("段子手模式")
+	p.applySpeakerPersona()
+	if p.currentPersona() != "段子手模式" || !strings.HasPrefix(systemPrompt(), "你是段子手") {
+		t.Errorf("switched persona should stick for the session, got %q", p.currentPersona())
+	}
+
+	// 对话结束后回到设备设置（未保存时为默认人设）
+	p.resetSessionPersona()
+	if p.currentPersona() != "" || !strings.HasPrefix(systemPrompt(), "你是小派") {
+		t.Errorf("persona should reset after the session, got %q", p.currentPersona())
+	}
+}
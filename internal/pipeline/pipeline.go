@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
+	"github.com/iabetor/pibuddy/internal/admin"
 	"github.com/iabetor/pibuddy/internal/asr"
 	"github.com/iabetor/pibuddy/internal/audio"
 	"github.com/iabetor/pibuddy/internal/config"
@@ -19,6 +20,8 @@ import (
 	"github.com/iabetor/pibuddy/internal/logger"
 	"github.com/iabetor/pibuddy/internal/music"
 	"github.com/iabetor/pibuddy/internal/rss"
+	"github.com/iabetor/pibuddy/internal/scheduler"
+	"github.com/iabetor/pibuddy/internal/sound"
 	"github.com/iabetor/pibuddy/internal/tools"
 	"github.com/iabetor/pibuddy/internal/tts"
 	"github.com/iabetor/pibuddy/internal/vad"
@@ -28,27 +31,44 @@ import (
 
 // Pipeline 是主编排器，将所有组件串联在一起。
 type Pipeline struct {
-	cfg *config.Config
-	db  *database.DB // 统一数据库
+	cfg      *config.Config
+	db       *database.DB       // 统一数据库
+	settings *database.Settings // 设备设置（音量、播放模式等跨重启保留）
+	dialogMu sync.RWMutex       // 保护可用语音修改的对话设置（cfg.Dialog 的连续对话超时、聆听延迟、唤醒回复语）
 
 	capture *audio.Capture
 	player  *audio.Player
 
+	startedAt   time.Time
+	lastFrameAt atomic.Int64 // 最近一次收到麦克风音频的时间（UnixNano），用于健康检查
+
 	wakeDetector *wake.Detector
+	wakeWords    []string            // 识别结果中可能残留的唤醒词，长的在前
+	nearField    *wake.NearFieldGate // 近场门控，未启用时为 nil
 	vadDetector  *vad.Detector
 	recognizer   asr.Engine // ASR 引擎（支持多引擎兜底）
 
 	llmProvider    llm.Provider
+	localLLM       llm.Provider // 本地小模型（llama.cpp 等），主模型不可用时用于闲聊，未配置时为 nil
 	contextManager *llm.ContextManager
+	llmDownUntil   atomic.Int64 // 主模型请求失败后，在此时间（UnixNano）之前直接走降级模式
+
+	latency *latencyProfiler // 各阶段耗时分析（dialog.profile_latency），未开启时为 nil
 
 	ttsEngine         tts.Engine
 	fallbackTtsEngine tts.Engine // 回退 TTS 引擎（网络失败时使用）
 
+	ttsSelector *tts.Selector // 开启 tts.selection 时按健康度选择引擎，同时也是 ttsEngine
+
 	toolRegistry *tools.Registry
+	toolFailures *tools.ToolFailureLog // 最近的工具失败，供诊断
 	alarmStore   *tools.AlarmStore
 	timerStore   *tools.TimerStore
-	volumeCtrl   tools.VolumeController
+	volumeCtrl   *tools.VolumeStack
 	healthStore  *tools.HealthStore
+	usage        *tools.UsageStats // 每日使用统计
+	haSync       *tools.HASync     // Home Assistant 日历、待办同步
+	haClient     *tools.HomeAssistantClient
 
 	state *StateMachine
 
@@ -60,14 +80,9 @@ type Pipeline struct {
 	cancelQuery context.CancelFunc
 	queryMu     sync.Mutex
 
-	// 流式播放器（音乐）
-	streamPlayer *audio.StreamPlayer
-
-	// 音乐缓存
-	musicCache *audio.MusicCache
-
-	// 音乐播放列表
-	playlist *music.Playlist
+	// 音乐播放（播放列表、暂停与恢复、缓存索引）
+	playback    *PlaybackManager
+	musicPlayer *audio.StreamPlayer // 音乐播放器，读取音乐响度供语音播报匹配
 
 	// 连续对话超时
 	continuousTimer *time.Timer
@@ -84,6 +99,9 @@ type Pipeline struct {
 	// 打断标志（跨 goroutine 通信，通知 processQuery 退出）
 	interrupted atomic.Bool
 
+	// 最近一次打断停掉了音乐（即时指令执行后据此恢复）
+	interruptedMusic atomic.Bool
+
 	// 声纹识别
 	voiceprintMgr     *voiceprint.Manager
 	voiceprintBuf     []float32
@@ -91,30 +109,143 @@ type Pipeline struct {
 	voiceprintBufSize int            // 目标缓冲大小 = BufferSecs * SampleRate
 	voiceprintWg      sync.WaitGroup // 等待声纹识别完成
 
-	// 暂停的音乐存储（用于恢复播放）
-	pausedStore *music.PausedMusicStore
-
-	// 音乐播放时间跟踪
-	musicPlayStart    time.Time // 当前歌曲播放开始时间
-	musicPlayStartMu  sync.Mutex
-	currentCacheKey   string // 当前歌曲的缓存 key
-
 	// 收藏存储
 	favoritesStore *music.FavoritesStore
 
+	// 等待二次确认的唤醒（dialog.wake_confirm），只在音频处理 goroutine 中访问
+	pendingWake *wakeConfirm
+
+	// 备忘录存储，用于生成动态热词
+	memoStore *tools.MemoStore
+	// 上次设置到 ASR 引擎的热词，没有变化时不重复设置
+	hotwordsKey string
+	hotwordsMu  sync.Mutex
+
+	// 空闲时后台维护用到的缓存和音乐源
+	musicCache    *audio.MusicCache
+	musicProvider music.Provider
+	rssFetcher    *rss.Fetcher
+	lastVacuum    time.Time
+
+	// 音乐 API 服务健康检查/托管
+	musicServer *music.APIServer
+
 	// ASR 中间结果去重（只在变化时打印日志）
 	lastASRText string
+
+	// 按说话人断句（声纹用户偏好中的 endpoint）
+	endpoint endpointer
+
+	// 每日云端语音识别额度（可选）：用完后当天固定使用本地识别，下次对话时提示一次
+	asrBudget       *asrBudget
+	asrBudgetNotice atomic.Bool
+
+	// 云端识别自动降级到离线识别后，下一次对话提示一次（asr.announce_fallback）
+	asrFallbackNotice atomic.Bool
+
+	// 听写模式：dictation 非空时识别结果只记录、不交给大模型
+	dictation      *dictationSession
+	dictationMu    sync.Mutex
+	dictationStore *tools.DictationStore
+
+	// 本次对话的草稿（逐项口述的清单等），对话结束时清空
+	scratchpad *tools.Scratchpad
+
+	// 后台播报队列：闹钟、倒计时、健康提醒、整点报时等依次播报，不互相盖过
+	speechQueue *speechQueue
+
+	// 连续聊天模式：openMic 非空时空闲状态下检测到说话就直接识别，不需要唤醒词
+	openMic   *openMicSession
+	openMicMu sync.Mutex
+
+	// 睡前模式：sleepAid 非空时音乐音量逐渐降低，唤醒需通过更严格的近场判定
+	sleepAid      *sleepAidSession
+	sleepAidMu    sync.Mutex
+	sleepAidEnded atomic.Bool // 渐弱结束主动停止了音乐，播放结束后直接回到空闲
+
+	// 回复语言：跟随最近识别到的说话人偏好，空为中文；wakeSpeaker 为最近识别到的说话人，唤醒回复语可带上昵称
+	replyLang     string
+	wakeSpeaker   string
+	wakeSpeakerAt time.Time
+	langMu        sync.Mutex
+
+	// 人设：设备设置中的人设，识别出的说话人有默认人设时临时换成该人设，
+	// 对话中用语音切换后本次对话内不再跟随说话人
+	persona         string
+	personaSwitched bool
+	personaMu       sync.Mutex
+
+	// 出门前提醒：门磁上次的状态和今天是否已提醒
+	weatherTool   *tools.WeatherTool
+	rainAlertDoor string
+	rainAlertDate string
+
+	// 家人在家检测，未配置时为 nil
+	presence *presenceTracker
+
+	// 隐私过滤：结果不能发给云端大模型的工具，未启用时为 nil
+	localOnlyTools map[string]bool
+
+	// 访客模式：不写入家庭数据，只开放 guestTools 中的工具，每次对话后清空上下文
+	guestMode    atomic.Bool
+	guestTools   map[string]bool
+	musicHistory *music.HistoryStore
+
+	// 学习时间：study 非空时该孩子（声纹）不能使用娱乐类工具
+	study   *studySession
+	studyMu sync.Mutex
+
+	// 渐进唤醒闹钟：灯光逐渐调亮、音乐逐渐变响，用户唤醒后停止
+	gentleWake   *gentleWakeSession
+	gentleWakeMu sync.Mutex
+
+	// 答题关闹钟：alarmQuiz 非空时闹钟在等待用户答题
+	alarmQuiz   *alarmQuiz
+	alarmQuizMu sync.Mutex
+
+	// 到期提醒（倒计时、闹钟）：未回应时重复播报
+	reminder        *reminderSession
+	reminderMu      sync.Mutex
+	reminderAckedAt time.Time // 用户最近一次回应提醒的时间
+	followUp        string    // 最近播报的跟进提醒，用户接着说话时放入对话上下文
+	followUpAt      time.Time
+
+	// 通过管理 API 发起的远程声纹注册（保留最近一次，供查询进度）
+	enroll   *enrollSession
+	enrollMu sync.Mutex
+
+	// 高风险操作执行前的声纹复核录音
+	challenge   *enrollSession
+	challengeMu sync.Mutex
+
+	// 声音事件检测（可选）：空闲时分析环境声音
+	soundTagger  *sound.SherpaTagger
+	soundMonitor *sound.Monitor
+
+	// 后台定时任务调度器
+	scheduler *scheduler.Scheduler
+
+	// 管理 API（可选）
+	adminServer *admin.Server
 }
 
 // New 根据配置创建并初始化完整的 Pipeline。
 func New(cfg *config.Config) (*Pipeline, error) {
 	p := &Pipeline{
-		cfg:   cfg,
-		state: NewStateMachine(),
+		cfg:          cfg,
+		state:        NewStateMachine(),
+		toolFailures: tools.NewToolFailureLog(),
+		startedAt:    time.Now(),
+	}
+	if cfg.Dialog.ProfileLatency {
+		p.latency = &latencyProfiler{}
 	}
 
 	var err error
 
+	// 外部 API 共享的限流和退避
+	configureRateLimit(cfg)
+
 	// 初始化统一数据库
 	p.db, err = database.Open("")
 	if err != nil {
@@ -124,6 +255,8 @@ func New(cfg *config.Config) (*Pipeline, error) {
 		p.Close()
 		return nil, fmt.Errorf("数据库迁移失败: %w", err)
 	}
+	p.settings = database.NewSettings(p.db)
+	p.loadDialogSettings()
 
 	// 初始化内置故事
 	if err := p.db.InitStories(""); err != nil {
@@ -142,6 +275,11 @@ func New(cfg *config.Config) (*Pipeline, error) {
 		p.capture.Close()
 		return nil, fmt.Errorf("初始化音频播放失败: %w", err)
 	}
+	if err := p.player.SetDevice(cfg.Audio.Outputs.TTS); err != nil {
+		logger.Warnf("[pipeline] 语音播放设备设置失败，使用默认设备: %v", err)
+	} else if name := p.player.DeviceName(); name != "" {
+		logger.Infof("[pipeline] 语音播放设备: %s", name)
+	}
 
 	// 唤醒词检测器
 	p.wakeDetector, err = wake.NewDetector(cfg.Wake.ModelPath, cfg.Wake.KeywordsFile, cfg.Wake.Threshold)
@@ -149,6 +287,25 @@ func New(cfg *config.Config) (*Pipeline, error) {
 		p.Close()
 		return nil, fmt.Errorf("初始化唤醒词检测器失败: %w", err)
 	}
+	p.wakeWords = loadWakeWords(cfg.Wake.KeywordsFile)
+	if cfg.Wake.NearField.Enabled {
+		p.nearField = wake.NewNearFieldGate(cfg.Wake.NearField.Threshold, cfg.Wake.NearField.MinLevelDB)
+		logger.Infof("[pipeline] 近场唤醒门控已启用 (threshold=%.2f, min_level=%.0fdB)",
+			cfg.Wake.NearField.Threshold, cfg.Wake.NearField.MinLevelDB)
+	}
+
+	// 声音事件检测（可选，失败不阻止启动）
+	if cfg.SoundEvents.Enabled {
+		tagger, tagErr := sound.NewSherpaTagger(cfg.SoundEvents.ModelPath, cfg.SoundEvents.LabelsPath,
+			cfg.SoundEvents.NumThreads, cfg.Audio.SampleRate)
+		if tagErr != nil {
+			logger.Warnf("[pipeline] 声音事件检测初始化失败（已禁用）: %v", tagErr)
+		} else {
+			p.soundTagger = tagger
+			p.soundMonitor = sound.NewMonitor(tagger, cfg.SoundEvents, cfg.Audio.SampleRate)
+			logger.Infof("[pipeline] 声音事件检测已启用 (interval=%.1fs)", cfg.SoundEvents.Interval)
+		}
+	}
 
 	// 语音活动检测器
 	p.vadDetector, err = vad.NewDetector(cfg.VAD.ModelPath, cfg.VAD.Threshold, cfg.VAD.MinSilenceMs)
@@ -163,6 +320,8 @@ func New(cfg *config.Config) (*Pipeline, error) {
 		p.Close()
 		return nil, fmt.Errorf("初始化 ASR 失败: %w", err)
 	}
+	p.initASRBudget()
+	p.initASRFallbackNotice()
 
 	// 大模型提供者（支持多模型自动降级）
 	if len(cfg.LLM.Models) > 1 {
@@ -180,6 +339,15 @@ func New(cfg *config.Config) (*Pipeline, error) {
 			p.Close()
 			return nil, fmt.Errorf("初始化多 LLM 失败: %w", err)
 		}
+		// 沿用上次自动降级后使用的模型，避免每次重启都先请求已经不可用的模型
+		if name := p.settings.GetString(database.SettingLLMModel, ""); name != "" && multiProvider.Select(name) {
+			logger.Infof("[pipeline] 使用上次的大模型: %s", name)
+		}
+		multiProvider.SetOnSwitch(func(name string) {
+			if err := p.settings.SetString(database.SettingLLMModel, name); err != nil {
+				logger.Warnf("[pipeline] 保存大模型选择失败: %v", err)
+			}
+		})
 		p.llmProvider = multiProvider
 	} else if len(cfg.LLM.Models) == 1 {
 		m := cfg.LLM.Models[0]
@@ -187,77 +355,47 @@ func New(cfg *config.Config) (*Pipeline, error) {
 	} else {
 		p.llmProvider = llm.NewOpenAIProvider(cfg.LLM.APIURL, cfg.LLM.APIKey, cfg.LLM.Model)
 	}
+	if local := cfg.LLM.Local; local.APIURL != "" {
+		p.localLLM = llm.NewOpenAIProvider(local.APIURL, local.APIKey, local.Model)
+		logger.Infof("[pipeline] 本地降级模型: %s", local.APIURL)
+	}
 	p.contextManager = llm.NewContextManager(cfg.LLM.SystemPrompt, cfg.LLM.MaxHistory)
+	p.contextManager.SetVerbosity(p.settings.GetString(database.SettingVerbosity, llm.VerbosityNormal))
 
 	// TTS 引擎
-	switch cfg.TTS.Engine {
-	case "tencent":
-		p.ttsEngine, err = tts.NewTencentEngine(tts.TencentConfig{
-			SecretID:  cfg.TTS.Tencent.SecretID,
-			SecretKey: cfg.TTS.Tencent.SecretKey,
-			VoiceType: cfg.TTS.Tencent.VoiceType,
-			Region:    cfg.TTS.Tencent.Region,
-			Speed:     cfg.TTS.Tencent.Speed,
-		})
+	if cfg.TTS.Selection.Enabled {
+		// 按健康度在多个引擎之间自动选择，不再单独使用回退引擎
+		p.ttsSelector, err = newTTSSelector(cfg.TTS)
 		if err != nil {
 			p.Close()
-			return nil, fmt.Errorf("初始化腾讯云 TTS 失败: %w", err)
-		}
-	case "edge":
-		p.ttsEngine = tts.NewEdgeEngine(cfg.TTS.Edge.Voice)
-	case "sherpa":
-		p.ttsEngine, err = tts.NewSherpaEngine(tts.SherpaConfig{
-			ModelPath:   cfg.TTS.Sherpa.ModelPath,
-			TokensPath:  cfg.TTS.Sherpa.TokensPath,
-			DataDir:     cfg.TTS.Sherpa.DataDir,
-			NoiseScale:  cfg.TTS.Sherpa.NoiseScale,
-			LengthScale: cfg.TTS.Sherpa.LengthScale,
-			Speed:       cfg.TTS.Sherpa.Speed,
-		})
+			return nil, err
+		}
+		p.ttsEngine = p.ttsSelector
+	} else {
+		p.ttsEngine, err = newTTSEngine(cfg.TTS, cfg.TTS.Engine)
 		if err != nil {
 			p.Close()
-			return nil, fmt.Errorf("初始化 Sherpa TTS 失败: %w", err)
+			return nil, err
 		}
-	case "piper":
-		p.ttsEngine = tts.NewPiperEngine(cfg.TTS.Piper.ModelPath)
-	case "say":
-		p.ttsEngine = tts.NewSayEngine(cfg.TTS.Say.Voice)
-	default:
-		p.Close()
-		return nil, fmt.Errorf("未知的 TTS 引擎: %s", cfg.TTS.Engine)
-	}
 
-	// 初始化备用 TTS 引擎（网络失败时使用）
-	if cfg.TTS.Fallback != "" && cfg.TTS.Fallback != cfg.TTS.Engine {
-		switch cfg.TTS.Fallback {
-		case "piper":
-			if cfg.TTS.Piper.ModelPath != "" {
-				p.fallbackTtsEngine = tts.NewPiperEngine(cfg.TTS.Piper.ModelPath)
-				logger.Info("[pipeline] 已启用 TTS 回退引擎: piper")
-			}
-		case "edge":
-			p.fallbackTtsEngine = tts.NewEdgeEngine(cfg.TTS.Edge.Voice)
-			logger.Info("[pipeline] 已启用 TTS 回退引擎: edge")
-		case "sherpa":
-			p.fallbackTtsEngine, err = tts.NewSherpaEngine(tts.SherpaConfig{
-				ModelPath:   cfg.TTS.Sherpa.ModelPath,
-				TokensPath:  cfg.TTS.Sherpa.TokensPath,
-				DataDir:     cfg.TTS.Sherpa.DataDir,
-				NoiseScale:  cfg.TTS.Sherpa.NoiseScale,
-				LengthScale: cfg.TTS.Sherpa.LengthScale,
-				Speed:       cfg.TTS.Sherpa.Speed,
-			})
-			if err == nil {
-				logger.Info("[pipeline] 已启用 TTS 回退引擎: sherpa")
+		// 初始化备用 TTS 引擎（网络失败时使用）
+		if cfg.TTS.Fallback != "" && cfg.TTS.Fallback != cfg.TTS.Engine {
+			if engine, err := newTTSEngine(cfg.TTS, cfg.TTS.Fallback); err != nil {
+				logger.Warnf("[pipeline] TTS 回退引擎不可用: %v", err)
+			} else {
+				p.fallbackTtsEngine = engine
+				logger.Infof("[pipeline] 已启用 TTS 回退引擎: %s", cfg.TTS.Fallback)
 			}
-		case "say":
-			p.fallbackTtsEngine = tts.NewSayEngine(cfg.TTS.Say.Voice)
-			logger.Info("[pipeline] 已启用 TTS 回退引擎: say (macOS)")
-		default:
-			logger.Warnf("[pipeline] 未知的 TTS 回退引擎: %s", cfg.TTS.Fallback)
 		}
 	}
 
+	if rate := p.settings.GetFloat(database.SettingSpeechRate, 1); rate != 1 {
+		p.setSpeechRate(rate)
+	}
+	if name := p.settings.GetString(database.SettingPersona, ""); name != "" {
+		p.applyPersona(name)
+	}
+
 	// 初始化声纹识别（可选，失败不阻止启动）— 必须在 initTools 之前，工具注册需要 voiceprintMgr
 	logger.Debugf("[pipeline] 声纹配置: enabled=%v, model=%s", cfg.Voiceprint.Enabled, cfg.Voiceprint.ModelPath)
 	if cfg.Voiceprint.Enabled && cfg.Voiceprint.ModelPath != "" {
@@ -286,13 +424,56 @@ func New(cfg *config.Config) (*Pipeline, error) {
 		p.Close()
 		return nil, fmt.Errorf("初始化流式播放器失败: %w", err)
 	}
-	p.streamPlayer = streamPlayer
+	if err := streamPlayer.SetDevice(cfg.Audio.Outputs.Music); err != nil {
+		logger.Warnf("[pipeline] 音乐播放设备设置失败，使用默认设备: %v", err)
+	} else if name := streamPlayer.DeviceName(); name != "" {
+		logger.Infof("[pipeline] 音乐播放设备: %s", name)
+	}
+	p.musicPlayer = streamPlayer
+	p.playback = NewPlaybackManager(streamPlayer)
+	p.playback.OnEvent(p.onPlaybackEvent)
 
 	// 初始化工具（需要 voiceprintMgr 已就绪）
 	if err := p.initTools(cfg); err != nil {
 		p.Close()
 		return nil, fmt.Errorf("初始化工具失败: %w", err)
 	}
+	if len(cfg.Tools.Mock) > 0 {
+		p.toolRegistry.SetMock(cfg.Tools.Mock)
+	}
+	if err := p.initPrivacy(); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("初始化隐私过滤失败: %w", err)
+	}
+
+	// 管理 API（可选）
+	if cfg.Admin.Enabled {
+		p.adminServer = admin.NewServer(cfg.Admin)
+		p.adminServer.HandlePublic("GET /healthz", p.handleHealthz)
+		p.adminServer.Handle("GET /api/diagnostics/tool-failures", p.handleToolFailures)
+		p.adminServer.Handle("GET /api/tools", p.handleToolCatalog)
+		p.adminServer.Handle("GET /api/tools/openapi.json", p.handleToolOpenAPI)
+		p.adminServer.Handle("GET /api/diagnostics/asr", p.handleASRStatus)
+		p.adminServer.Handle("GET /api/diagnostics/tts", p.handleTTSStatus)
+		p.adminServer.HandleAuthRequired("PUT /api/tts/pin", p.handleTTSPin)
+		p.adminServer.Handle("GET /api/diagnostics/latency", p.handleLatency)
+		p.adminServer.HandleAuthRequired("POST /api/diagnostics/latency/dry-run", p.handleLatencyDryRun)
+		p.adminServer.Handle("GET /api/music/export/{kind}", p.handleMusicExport)
+		p.adminServer.HandleAuthRequired("POST /api/import", p.handleImport)
+		p.adminServer.HandleAuthRequired("POST /api/announce", p.handleAnnounce)
+		if p.voiceprintMgr != nil {
+			p.registerUserRoutes()
+		}
+		if cfg.Tools.GuestWiFi.SSID != "" {
+			p.registerWiFiRoutes()
+		}
+	}
+
+	// 注册后台定时任务（需要工具存储已就绪）
+	if err := p.initScheduler(); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("初始化定时任务失败: %w", err)
+	}
 
 	logger.Info("[pipeline] 所有组件初始化完成")
 	return p, nil
@@ -301,12 +482,29 @@ func New(cfg *config.Config) (*Pipeline, error) {
 // initTools 注册所有可用工具。
 func (p *Pipeline) initTools(cfg *config.Config) error {
 	p.toolRegistry = tools.NewRegistry()
+	p.toolRegistry.SetTimeout(time.Duration(cfg.Tools.Timeout) * time.Second)
+	if cfg.Tools.Groups.Enabled {
+		custom := make([]tools.ToolGroup, 0, len(cfg.Tools.Groups.Custom))
+		for _, g := range cfg.Tools.Groups.Custom {
+			custom = append(custom, tools.ToolGroup{Name: g.Name, Keywords: g.Keywords, Tools: g.Tools})
+		}
+		p.toolRegistry.SetGroups(custom, cfg.Tools.Groups.Always)
+	}
 
 	// 本地工具
 	p.toolRegistry.Register(tools.NewDateTimeTool())
 	p.toolRegistry.Register(tools.NewCalculatorTool())
 	p.toolRegistry.Register(tools.NewLunarDateTool())
 
+	// 访客 Wi-Fi
+	if cfg.Tools.GuestWiFi.SSID != "" {
+		var pageURL string
+		if cfg.Admin.Enabled {
+			pageURL = adminPageURL(cfg.Admin.Listen, "/wifi")
+		}
+		p.toolRegistry.Register(tools.NewGuestWiFiTool(p.guestWiFiConfig(), pageURL))
+	}
+
 	// 天气工具
 	if cfg.Tools.Weather.CredentialID != "" || cfg.Tools.Weather.APIKey != "" {
 		weatherTool := tools.NewWeatherTool(tools.WeatherConfig{
@@ -315,8 +513,15 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 			CredentialID:   cfg.Tools.Weather.CredentialID,
 			ProjectID:      cfg.Tools.Weather.ProjectID,
 			PrivateKeyPath: cfg.Tools.Weather.PrivateKeyPath,
+			NowTTL:         time.Duration(cfg.Tools.Weather.NowCacheTTL) * time.Minute,
+			ForecastTTL:    time.Duration(cfg.Tools.Weather.ForecastCacheTTL) * time.Minute,
+			DB:             p.db,
+			CityAliases:    cfg.Tools.Weather.CityAliases,
+			HomeCity:       p.contextManager.SpeakerHomeCity,
+			DefaultCity:    cfg.Tools.Weather.DefaultCity,
 		})
 		p.toolRegistry.Register(weatherTool)
+		p.weatherTool = weatherTool
 		// 空气质量工具（复用天气工具的认证）
 		p.toolRegistry.Register(tools.NewAirQualityTool(weatherTool))
 	}
@@ -330,32 +535,55 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 	p.toolRegistry.Register(tools.NewSetAlarmTool(p.alarmStore))
 	p.toolRegistry.Register(tools.NewListAlarmsTool(p.alarmStore))
 	p.toolRegistry.Register(tools.NewDeleteAlarmTool(p.alarmStore))
+	p.toolRegistry.Register(tools.NewAddFollowUpTool(p.alarmStore))
 
 	// 备忘录工具
 	memoStore, err := tools.NewMemoStore(cfg.Tools.DataDir)
 	if err != nil {
 		return fmt.Errorf("初始化备忘录存储失败: %w", err)
 	}
+	memoStore.SetAlarmStore(p.alarmStore)
+	p.memoStore = memoStore
 	p.toolRegistry.Register(tools.NewAddMemoTool(memoStore))
 	p.toolRegistry.Register(tools.NewListMemosTool(memoStore))
 	p.toolRegistry.Register(tools.NewDeleteMemoTool(memoStore))
+	p.scratchpad = tools.NewScratchpad()
+	p.toolRegistry.Register(tools.NewScratchpadTool(p.scratchpad, memoStore))
 
-	// 新闻和股票
-	p.toolRegistry.Register(tools.NewNewsTool())
+	// 听写工具（会议记录、灵感速记）
+	p.dictationStore, err = tools.NewDictationStore(cfg.Tools.DataDir)
+	if err != nil {
+		logger.Warnf("[pipeline] 初始化听写存储失败: %v", err)
+	} else {
+		p.toolRegistry.Register(tools.NewStartDictationTool())
+	}
+
+	// 新闻和股票（新闻会话由热点新闻和 RSS 共用，支持"下一条"、"详细说说这条"）
+	newsSession := tools.NewNewsSession()
+	p.toolRegistry.Register(tools.NewNewsTool(newsSession))
+	p.toolRegistry.Register(tools.NewNavigateNewsTool(newsSession))
 	p.toolRegistry.Register(tools.NewStockTool())
 
 	// 音乐工具
 	if cfg.Tools.Music.Enabled {
 		var musicProvider music.Provider
+		var serverCfg config.MusicServerConfig
+		var serverName string
 
 		// 根据 provider 配置选择音乐平台
 		switch cfg.Tools.Music.Provider {
 		case "qq":
+			if cfg.Tools.Music.QQ.Direct {
+				musicProvider = music.NewQQWebClient(cfg.Tools.DataDir)
+				logger.Infof("[pipeline] 使用 QQ 音乐 (内置客户端)")
+				break
+			}
 			apiURL := cfg.Tools.Music.QQ.APIURL
 			if apiURL == "" {
 				apiURL = "http://localhost:3300"
 			}
 			musicProvider = music.NewQQMusicClientWithDataDir(apiURL, cfg.Tools.DataDir)
+			serverCfg, serverName = cfg.Tools.Music.QQ.Server, "QQ 音乐"
 			logger.Infof("[pipeline] 使用 QQ 音乐 (API: %s)", apiURL)
 		default:
 			// 默认使用网易云音乐
@@ -367,14 +595,28 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 				apiURL = "http://localhost:3000"
 			}
 			musicProvider = music.NewNeteaseClientWithDataDir(apiURL, cfg.Tools.DataDir)
+			serverCfg, serverName = cfg.Tools.Music.Netease.Server, "网易云音乐"
 			logger.Infof("[pipeline] 使用网易云音乐 (API: %s)", apiURL)
 		}
 
+		// 音乐 API 服务健康检查（可选托管启动）
+		if checker, ok := musicProvider.(music.HealthChecker); ok {
+			p.musicServer = music.NewAPIServer(serverName, checker, music.ServerOptions{
+				Managed:         serverCfg.Managed,
+				Command:         serverCfg.Command,
+				Dir:             serverCfg.Dir,
+				Env:             serverCfg.Env,
+				DockerContainer: serverCfg.DockerContainer,
+				StartTimeout:    time.Duration(serverCfg.StartTimeout) * time.Second,
+			})
+		}
+
 		// 创建播放历史存储
 		musicHistory, err := music.NewHistoryStore(cfg.Tools.DataDir)
 		if err != nil {
 			logger.Warnf("[pipeline] 创建音乐历史存储失败: %v", err)
 		}
+		p.musicHistory = musicHistory
 
 		// 创建音乐缓存
 		var musicCache *audio.MusicCache
@@ -384,23 +626,29 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 		} else if musicCache.Enabled() {
 			logger.Infof("[pipeline] 音乐缓存已启用: %s (上限 %dMB)", cfg.Tools.Music.CacheDir, cfg.Tools.Music.CacheMaxSize)
 		}
-		p.musicCache = musicCache
+		p.musicCache, p.musicProvider = musicCache, musicProvider
 
 		// 创建播放列表
-		p.playlist = music.NewPlaylist(musicProvider, musicHistory)
+		playlist := music.NewPlaylist(musicProvider, musicHistory)
+		if mode, ok := music.ParsePlayMode(p.settings.GetString(database.SettingPlayMode, "")); ok {
+			playlist.SetMode(mode)
+		}
 
 		musicCfg := tools.MusicConfig{
 			Provider: musicProvider,
 			History:  musicHistory,
-			Playlist: p.playlist,
+			Playlist: playlist,
 			Cache:    musicCache,
+			Server:   p.musicServer,
+			Context:  p.contextManager,
 			Enabled:  true,
 		}
 		p.toolRegistry.Register(tools.NewSearchMusicTool(musicCfg))
 		p.toolRegistry.Register(tools.NewPlayMusicTool(musicCfg))
 		p.toolRegistry.Register(tools.NewListMusicHistoryTool(musicHistory))
-		p.toolRegistry.Register(tools.NewNextMusicTool(p.playlist))
-		p.toolRegistry.Register(tools.NewSetPlayModeTool(p.playlist))
+		p.toolRegistry.Register(tools.NewNextMusicTool(playlist))
+		p.toolRegistry.Register(tools.NewSetPlayModeTool(playlist, p.settings))
+		p.toolRegistry.Register(tools.NewMusicAccountTool(music.NewAccountStore(cfg.Tools.DataDir), musicProvider.ProviderName()))
 		if musicCache != nil && musicCache.Enabled() {
 			p.toolRegistry.Register(tools.NewListMusicCacheTool(musicCache))
 			p.toolRegistry.Register(tools.NewDeleteMusicCacheTool(musicCache))
@@ -412,8 +660,9 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 		// 收藏和恢复播放工具
 		favCfg := tools.FavoritesConfig{
 			Store:          p.favoritesStore,
-			Playlist:       p.playlist,
+			Playlist:       playlist,
 			ContextManager: p.contextManager,
+			Cache:          musicCache,
 		}
 		p.toolRegistry.Register(tools.NewAddFavoriteTool(favCfg))
 		p.toolRegistry.Register(tools.NewRemoveFavoriteTool(favCfg))
@@ -421,12 +670,30 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 		p.toolRegistry.Register(tools.NewPlayFavoritesTool(favCfg, musicProvider))
 
 		// 恢复播放工具
-		p.pausedStore = music.NewPausedMusicStore()
-		p.toolRegistry.Register(tools.NewResumeMusicTool(p.playlist, p.pausedStore, musicCache))
-		p.toolRegistry.Register(tools.NewStopMusicTool(p.playlist, p.pausedStore))
+		pausedStore := music.NewPausedMusicStore()
+		p.toolRegistry.Register(tools.NewResumeMusicTool(playlist, pausedStore, musicCache))
+		p.toolRegistry.Register(tools.NewStopMusicTool(playlist, pausedStore))
+		p.playback.Attach(playlist, pausedStore, musicCache)
 		logger.Info("[pipeline] 音乐收藏和恢复播放工具已启用")
 	}
 
+	// 网络电台（直播流由播放管理器播放，不依赖音乐服务）
+	var stations []tools.RadioStation
+	for _, st := range cfg.Tools.Radio.Stations {
+		if st.Name == "" || st.URL == "" {
+			logger.Warnf("[pipeline] 电台配置缺少名称或地址，已忽略: %+v", st)
+			continue
+		}
+		stations = append(stations, tools.RadioStation{Name: st.Name, URL: st.URL, Aliases: st.Aliases})
+	}
+	if len(stations) > 0 {
+		p.toolRegistry.Register(tools.NewPlayRadioStationTool(stations))
+		logger.Infof("[pipeline] 网络电台已启用: %d 个电台", len(stations))
+	}
+	if len(stations) > 0 || cfg.Tools.Music.Enabled {
+		p.toolRegistry.Register(tools.NewNowPlayingTool(p.nowPlaying))
+	}
+
 	// RSS 订阅工具
 	if cfg.Tools.RSS.Enabled {
 		feedStore, err := rss.NewFeedStore(cfg.Tools.DataDir)
@@ -434,10 +701,13 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 			logger.Warnf("[pipeline] 初始化 RSS 存储失败: %v", err)
 		} else {
 			fetcher := rss.NewFetcher(feedStore, cfg.Tools.DataDir, cfg.Tools.RSS.CacheTTL)
+			p.rssFetcher = fetcher
 			p.toolRegistry.Register(tools.NewAddRSSFeedTool(feedStore, fetcher))
 			p.toolRegistry.Register(tools.NewListRSSFeedsTool(feedStore))
 			p.toolRegistry.Register(tools.NewDeleteRSSFeedTool(feedStore))
-			p.toolRegistry.Register(tools.NewGetRSSNewsTool(feedStore, fetcher))
+			p.toolRegistry.Register(tools.NewGetRSSNewsTool(feedStore, fetcher, newsSession))
+			p.toolRegistry.Register(tools.NewRSSOPMLTool(feedStore, cfg.Tools.DataDir))
+			p.toolRegistry.Register(tools.NewRSSFeedSettingsTool(feedStore))
 			logger.Infof("[pipeline] RSS 订阅功能已启用")
 		}
 	}
@@ -463,13 +733,7 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 	p.timerStore, err = tools.NewTimerStore(cfg.Tools.DataDir, func(entry tools.TimerEntry) {
 		// 倒计时到期回调
 		logger.Infof("[pipeline] 倒计时到期: %s", entry.ID)
-		var msg string
-		if entry.Label != "" {
-			msg = fmt.Sprintf("%s提醒时间到了", entry.Label)
-		} else {
-			msg = "倒计时结束了"
-		}
-		p.speakText(context.Background(), msg)
+		p.announceReminder(tools.TimerAnnouncement(entry))
 	})
 	if err != nil {
 		return fmt.Errorf("初始化倒计时存储失败: %w", err)
@@ -477,21 +741,51 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 	p.toolRegistry.Register(tools.NewSetTimerTool(p.timerStore))
 	p.toolRegistry.Register(tools.NewListTimersTool(p.timerStore))
 	p.toolRegistry.Register(tools.NewCancelTimerTool(p.timerStore))
+	p.toolRegistry.Register(tools.NewCookingConvertTool(p.timerStore))
 
 	// 休息工具
 	p.toolRegistry.Register(tools.NewGoToSleepTool())
 
 	// 音量控制工具
-	p.volumeCtrl, err = tools.NewVolumeController()
-	if err != nil {
+	if volumeCtrl, err := tools.NewVolumeController(); err != nil {
 		logger.Warnf("[pipeline] 音量控制器初始化失败（已禁用）: %v", err)
 	} else {
+		p.volumeCtrl = tools.NewVolumeStack(volumeCtrl)
+		// 恢复上次设置的音量
+		if p.settings.Has(database.SettingVolume) {
+			vol := p.settings.GetInt(database.SettingVolume, 50)
+			if err := p.volumeCtrl.SetVolume(vol); err != nil {
+				logger.Warnf("[pipeline] 恢复音量失败: %v", err)
+			} else {
+				logger.Infof("[pipeline] 已恢复音量: %d", vol)
+			}
+		}
 		p.toolRegistry.Register(tools.NewSetVolumeTool(p.volumeCtrl, tools.VolumeConfig{
-			Step: cfg.Tools.Volume.Step,
+			Step:     cfg.Tools.Volume.Step,
+			Settings: p.settings,
 		}))
 		p.toolRegistry.Register(tools.NewGetVolumeTool(p.volumeCtrl))
 	}
 
+	// 回复风格（详略、语速）
+	var setSpeechRate func(float64)
+	if _, ok := p.ttsEngine.(tts.RateAdjustable); ok {
+		setSpeechRate = p.setSpeechRate
+	}
+	p.toolRegistry.Register(tools.NewReplyStyleTool(p.settings, p.contextManager.SetVerbosity, setSpeechRate))
+	// 人设切换（助教模式、段子手模式等）
+	if len(cfg.LLM.Personas) > 0 {
+		p.toolRegistry.Register(tools.NewSwitchPersonaTool(p.personas(), p.settings, p.currentPersona, p.switchPersona))
+	}
+	// 语音修改设置（连续对话超时、聆听延迟、唤醒回复语等）
+	p.toolRegistry.Register(tools.NewManageSettingsTool(p.settings, p.currentSetting, p.applySetting))
+
+	// 连续聊天模式（免唤醒词）
+	p.toolRegistry.Register(tools.NewOpenMicTool(cfg.Dialog.OpenMicMinutes, cfg.Dialog.OpenMicMaxMinutes, p.startOpenMic, p.stopOpenMic))
+
+	// 孩子学习时间（按声纹限制娱乐工具）
+	p.toolRegistry.Register(tools.NewStudyModeTool(cfg.Tools.Study.Minutes, p.startStudy, p.stopStudy, p.studyStatus))
+
 	// 翻译工具
 	if cfg.Tools.Translate.Enabled && cfg.Tools.Translate.SecretID != "" {
 		translateTool, err := tools.NewTranslateTool(
@@ -513,10 +807,16 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 			cfg.Tools.HomeAssistant.URL,
 			cfg.Tools.HomeAssistant.Token,
 		)
+		p.haClient = haClient
 		p.toolRegistry.Register(tools.NewHAListDevicesTool(haClient))
 		p.toolRegistry.Register(tools.NewHAGetDeviceStateTool(haClient))
 		p.toolRegistry.Register(tools.NewHAControlDeviceTool(haClient))
 		logger.Info("[pipeline] Home Assistant 智能家居工具已启用")
+
+		if sc := cfg.Tools.HomeAssistant.Sync; sc.TodoEntity != "" || sc.CalendarEntity != "" {
+			p.haSync = tools.NewHASync(haClient, p.alarmStore, memoStore, cfg.Tools.DataDir, sc.TodoEntity, sc.CalendarEntity)
+			logger.Infof("[pipeline] Home Assistant 同步已启用 (待办: %s, 日历: %s)", sc.TodoEntity, sc.CalendarEntity)
+		}
 	}
 
 	// 萤石门锁工具
@@ -530,6 +830,7 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 
 	// 系统状态工具
 	p.toolRegistry.Register(tools.NewSystemStatusTool())
+	p.toolRegistry.Register(tools.NewSelfStatusTool(p.healthReport))
 
 	// 健康提醒工具
 	if cfg.Tools.Health.Enabled {
@@ -553,6 +854,13 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 		// 拼音工具（本地库，无需配置）
 		p.toolRegistry.Register(tools.NewPinyinTool())
 
+		// 查字典工具（本地字典数据）
+		if cfg.Tools.Learning.Dictionary.Enabled {
+			dict := tools.NewHanziDict(cfg.Tools.Learning.Dictionary.CEDICT, cfg.Tools.Learning.Dictionary.Xinhua)
+			p.toolRegistry.Register(tools.NewHanziLookupTool(dict))
+			logger.Info("[pipeline] 查字典工具已启用")
+		}
+
 		// 英语学习工具
 		if cfg.Tools.Learning.English.Enabled {
 			p.toolRegistry.Register(tools.NewEnglishWordTool())
@@ -593,13 +901,30 @@ func (p *Pipeline) initTools(cfg *config.Config) error {
 		// 注册故事工具
 		if storyStore != nil {
 			p.toolRegistry.Register(tools.NewTellStoryTool(storyStore, storyAPI, cfg.Tools.Story.LLMFallback, cfg.Tools.Story.OutputMode))
-			p.toolRegistry.Register(tools.NewListStoriesTool(storyStore))
+			seriesStore := tools.NewStorySeriesStore(p.db)
+			p.toolRegistry.Register(tools.NewListStoriesTool(storyStore, seriesStore, p.contextManager))
 			p.toolRegistry.Register(tools.NewSaveStoryTool(storyStore))
-			p.toolRegistry.Register(tools.NewDeleteStoryTool(storyStore))
+			p.toolRegistry.Register(tools.NewDeleteStoryTool(storyStore, seriesStore, p.contextManager))
+			p.toolRegistry.Register(tools.NewContinueStoryTool(seriesStore, p.contextManager))
+			p.toolRegistry.Register(tools.NewSaveStoryChapterTool(seriesStore, p.contextManager))
 			logger.Infof("[pipeline] 故事工具已启用，本地库 %d 个故事", storyStore.Count())
 		}
 	}
 
+	// 使用统计
+	p.usage = tools.NewUsageStats(p.db)
+	p.toolRegistry.Register(tools.NewDailySummaryTool(p.usage))
+
+	// 诊断包（注册了声纹时仅主人可用）
+	p.toolRegistry.Register(tools.NewDiagBundleTool(p.createDiagBundle))
+
+	// 访客模式（注册了声纹时仅主人可以开关）
+	p.initGuestTools()
+	p.toolRegistry.Register(tools.NewGuestModeTool(p.startGuestMode, p.stopGuestMode))
+	if cfg.Guest.Enabled {
+		p.startGuestMode()
+	}
+
 	logger.Infof("[pipeline] 已注册 %d 个工具", p.toolRegistry.Count())
 	return nil
 }
@@ -610,12 +935,28 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		return fmt.Errorf("启动音频采集失败: %w", err)
 	}
 
-	// 启动闹钟检查 goroutine
-	go p.alarmChecker(ctx)
+	// 启动管理 API
+	if p.adminServer != nil {
+		if err := p.adminServer.Start(); err != nil {
+			logger.Warnf("[pipeline] 启动管理 API 失败: %v", err)
+		}
+	}
+
+	// 检查音乐 API 服务，托管模式下自动启动
+	if p.musicServer != nil {
+		go p.checkMusicServer(ctx)
+	}
+
+	// 启动定时任务调度（闹钟、健康提醒等）和后台播报队列
+	p.speechQueue = newSpeechQueue(time.Duration(max(p.cfg.Dialog.AnnounceGap, 0))*time.Millisecond, p.isConversationActive)
+	go p.speechQueue.Run(ctx)
+	go p.scheduler.Run(ctx)
+	if p.cfg.ASR.Hotwords.Enabled {
+		go p.refreshHotwords(ctx)
+	}
 
-	// 启动健康提醒检查 goroutine
-	if p.healthStore != nil {
-		go p.healthReminderChecker(ctx)
+	if p.soundMonitor != nil {
+		p.soundMonitor.SetHandler(func(ev sound.Event) { p.onSoundEvent(ctx, ev) })
 	}
 
 	logger.Info("[pipeline] 已启动 — 请说唤醒词开始对话！")
@@ -628,55 +969,27 @@ func (p *Pipeline) Run(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
+			p.lastFrameAt.Store(time.Now().UnixNano())
 			p.processFrame(ctx, frame)
 		}
 	}
 }
 
-// alarmChecker 每 30 秒检查一次到期闹钟，到期时 TTS 播报。
-func (p *Pipeline) alarmChecker(ctx context.Context) {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			dueAlarms := p.alarmStore.PopDueAlarms()
-			for _, a := range dueAlarms {
-				logger.Infof("[pipeline] 闹钟到期: %s", a.Message)
-				msg := fmt.Sprintf("闹钟提醒: %s", a.Message)
-				p.speakText(ctx, msg)
-			}
-		}
-	}
-}
-
-// healthReminderChecker 每分钟检查一次健康提醒。
-func (p *Pipeline) healthReminderChecker(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if p.healthStore == nil {
-				continue
-			}
-			reminders := p.healthStore.CheckAndTrigger()
-			for _, r := range reminders {
-				logger.Infof("[pipeline] 健康提醒: %s", r.Message)
-				p.speakText(ctx, r.Message)
-			}
-		}
-	}
-}
-
 // processFrame 根据当前状态将音频帧分发到对应的处理器。
 func (p *Pipeline) processFrame(ctx context.Context, frame []float32) {
+	// 远程注册声纹期间，麦克风帧只用于录制样本
+	if e := p.activeEnrollment(); e != nil {
+		e.feed(frame)
+		return
+	}
+	if c := p.activeChallenge(); c != nil {
+		c.feed(frame)
+		return
+	}
+	if p.pendingWake != nil {
+		p.handleWakeConfirm(ctx, frame)
+		return
+	}
 	switch p.state.Current() {
 	case StateIdle:
 		p.handleIdle(ctx, frame)
@@ -701,8 +1014,27 @@ func (p *Pipeline) handleIdle(ctx context.Context, frame []float32) {
 	}
 	p.wakeCooldownMu.Unlock()
 
+	if p.nearField != nil {
+		p.nearField.Feed(frame)
+	}
+	if p.soundMonitor != nil {
+		p.soundMonitor.Feed(frame)
+	}
+
 	if p.wakeDetector.Detect(frame) {
+		if !p.acceptNearField() {
+			p.wakeDetector.Reset()
+			return
+		}
+		if p.needWakeConfirm() {
+			p.startWakeConfirm(ctx)
+			return
+		}
 		logger.Info("[pipeline] 检测到唤醒词！")
+		p.usage.Add(tools.UsageWake, 1)
+		p.latency.markWake()
+		p.ackReminder()
+		p.clearGuestContext()
 
 		// 进入冷却期，防止重复检测
 		p.wakeCooldownMu.Lock()
@@ -711,7 +1043,10 @@ func (p *Pipeline) handleIdle(ctx context.Context, frame []float32) {
 
 		p.wakeDetector.Reset()
 		p.vadDetector.Reset()
-		p.recognizer.Reset()
+		p.resetRecognizer()
+		if p.soundMonitor != nil {
+			p.soundMonitor.Reset()
+		}
 
 		// 初始化声纹缓冲区（唤醒后开始收集音频）
 		if p.voiceprintMgr != nil && p.voiceprintMgr.NumSpeakers() > 0 {
@@ -721,20 +1056,59 @@ func (p *Pipeline) handleIdle(ctx context.Context, frame []float32) {
 		}
 
 		// 如果配置了唤醒回复语，先播放再进入监听
-		if p.cfg.Dialog.WakeReply != "" {
+		if p.wakeReply() != "" {
 			p.state.Transition(StateSpeaking)
 			go p.playWakeReply(ctx)
 		} else {
 			p.state.Transition(StateListening)
+			// 用户常常紧跟唤醒词就开始说话，补上唤醒检测延迟期间的音频
+			p.feedPreRoll()
 			// 启动连续对话超时计时器
-			if p.cfg.Dialog.ContinuousTimeout > 0 {
+			if p.continuousTimeout() > 0 {
 				p.startContinuousTimer()
-				logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.cfg.Dialog.ContinuousTimeout)
+				logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.continuousTimeout())
 			}
 			// 1秒后解除冷却期
 			time.AfterFunc(1*time.Second, p.clearWakeCooldown)
 		}
+		return
+	}
+
+	p.detectOpenMicSpeech(frame)
+}
+
+// feedPreRoll 把唤醒前后的预录音频送入 VAD/ASR。
+// 预录音频包含唤醒词的尾音，识别结果中残留的唤醒词由 trimWakeWord 去掉。
+func (p *Pipeline) feedPreRoll() {
+	if p.cfg.Audio.PreRoll <= 0 {
+		return
+	}
+	samples := p.capture.TakeRecent(time.Duration(p.cfg.Audio.PreRoll) * time.Millisecond)
+	if len(samples) == 0 {
+		return
+	}
+	logger.Debugf("[pipeline] 补入唤醒前音频 %dms", len(samples)*1000/p.cfg.Audio.SampleRate)
+	p.vadDetector.Feed(samples)
+	p.recognizer.Feed(samples)
+	p.chargeASRBudget(len(samples))
+}
+
+// acceptNearField 用近场门控检查刚检测到的唤醒词，未启用时直接通过。
+// 只在空闲唤醒时使用；播放期间的打断不做门控，避免 TTS 回声影响判断导致无法打断。
+func (p *Pipeline) acceptNearField() bool {
+	if p.nearField == nil {
+		return true
 	}
+	score, ok := p.nearField.Accept()
+	if !ok {
+		logger.Infof("[pipeline] 唤醒词疑似来自远处声源，已忽略 (得分 %.2f, 电平 %.1fdB, 对比度 %.1fdB, 高低频比 %.1fdB)",
+			score.Score, score.LevelDB, score.ContrastDB, score.TiltDB)
+		return false
+	}
+	logger.Debugf("[pipeline] 近场门控通过 (得分 %.2f, 电平 %.1fdB, 对比度 %.1fdB, 高低频比 %.1fdB)",
+		score.Score, score.LevelDB, score.ContrastDB, score.TiltDB)
+	p.nearField.Reset()
+	return true
 }
 
 // clearWakeCooldown 解除唤醒词冷却期。
@@ -746,7 +1120,24 @@ func (p *Pipeline) clearWakeCooldown() {
 
 // handleSpeakingInterrupt 在播放状态下检测唤醒词打断。
 func (p *Pipeline) handleSpeakingInterrupt(ctx context.Context, frame []float32) {
+	gate := p.sleepAidGate()
+	if gate != nil {
+		gate.Feed(frame)
+	}
 	if p.detectWakeWord(frame) {
+		if gate != nil {
+			// 睡前模式下音乐容易引起误唤醒，要求唤醒词明显来自近处
+			if score, ok := gate.Accept(); !ok {
+				logger.Infof("[pipeline] 睡前模式下唤醒词近场得分不足，已忽略 (得分 %.2f)", score.Score)
+				p.wakeDetector.Reset()
+				return
+			}
+		}
+		// 放音乐时先确认，误唤醒不打断音乐
+		if p.playback.Playing() && p.needWakeConfirm() {
+			p.startWakeConfirm(ctx)
+			return
+		}
 		logger.Info("[pipeline] 播放中检测到唤醒词，打断播放！")
 		p.performInterrupt(ctx)
 	}
@@ -772,8 +1163,37 @@ func (p *Pipeline) detectWakeWord(frame []float32) bool {
 	return p.wakeDetector.Detect(frame)
 }
 
+// cancelRunningQuery 取消正在进行的对话：大模型调用和正在执行的工具随 queryCtx 一起取消。
+func (p *Pipeline) cancelRunningQuery() {
+	p.queryMu.Lock()
+	if p.cancelQuery != nil {
+		p.cancelQuery()
+	}
+	p.queryMu.Unlock()
+}
+
+// stopCurrentTurn 打断当前回复：设置打断标志通知 processQuery goroutine 退出，
+// 取消正在进行的大模型和工具调用，停止所有播放。唤醒打断和确认唤醒后的打断共用。
+func (p *Pipeline) stopCurrentTurn() {
+	p.interrupted.Store(true)
+	p.interruptedMusic.Store(p.playback.Playing())
+
+	// 用户醒着，退出睡前模式并恢复音量
+	p.stopSleepAid()
+	p.stopGentleWake()
+
+	// 取消 LLM 和工具调用（如果正在进行）
+	p.cancelRunningQuery()
+
+	// 停止所有播放
+	p.interruptSpeak()
+}
+
 // performInterrupt 执行打断逻辑：停止播放、取消 LLM 调用、设置打断标志、播放回复、延迟后进入监听。
 func (p *Pipeline) performInterrupt(ctx context.Context) {
+	p.usage.Add(tools.UsageWake, 1)
+	p.latency.markWake()
+
 	// 进入冷却期
 	p.wakeCooldownMu.Lock()
 	p.wakeCooldown = true
@@ -781,35 +1201,30 @@ func (p *Pipeline) performInterrupt(ctx context.Context) {
 
 	p.wakeDetector.Reset()
 
-	// 设置打断标志，通知 processQuery goroutine 退出
-	p.interrupted.Store(true)
-
-	// 取消 LLM 调用（如果正在进行）
-	p.queryMu.Lock()
-	if p.cancelQuery != nil {
-		p.cancelQuery()
-	}
-	p.queryMu.Unlock()
-
-	// 停止所有播放
-	p.interruptSpeak()
+	p.ackReminder()
+	p.stopCurrentTurn()
 
 	// 立即清空麦克风缓冲（防止音乐残留）
 	p.capture.Drain()
 
 	// 重置 ASR/VAD
 	p.vadDetector.Reset()
-	p.recognizer.Reset()
+	p.resetRecognizer()
 
-	// 播放打断回复语（区别于唤醒回复语）
-	if p.cfg.Dialog.InterruptReply != "" {
-		logger.Debugf("[pipeline] 播放打断回复: %s", p.cfg.Dialog.InterruptReply)
-		p.speakText(ctx, p.cfg.Dialog.InterruptReply)
-	}
+	if p.cfg.Dialog.FastInterrupt {
+		// 快速打断：只响一声提示音就开始监听，省去回复语的合成和播放时间
+		p.playCue(ctx)
+	} else {
+		// 播放打断回复语（区别于唤醒回复语）
+		if p.cfg.Dialog.InterruptReply != "" {
+			logger.Debugf("[pipeline] 播放打断回复: %s", p.cfg.Dialog.InterruptReply)
+			p.speakText(ctx, p.cfg.Dialog.InterruptReply)
+		}
 
-	// 延迟后进入监听状态（给用户反应时间 + 让回声消散）
-	if p.cfg.Dialog.ListenDelay > 0 {
-		time.Sleep(time.Duration(p.cfg.Dialog.ListenDelay) * time.Millisecond)
+		// 延迟后进入监听状态（给用户反应时间 + 让回声消散）
+		if p.listenDelay() > 0 {
+			time.Sleep(p.listenDelay())
+		}
 	}
 	// 再次清空缓冲（播放"我在"期间的回声）
 	p.capture.Drain()
@@ -818,7 +1233,7 @@ func (p *Pipeline) performInterrupt(ctx context.Context) {
 	p.capture.Drain()
 	// 最后再重置一次 VAD/ASR，确保没有残留状态
 	p.vadDetector.Reset()
-	p.recognizer.Reset()
+	p.resetRecognizer()
 
 	// 缩短静默期，避免截断用户说话
 	p.echoSilenceMu.Lock()
@@ -828,7 +1243,7 @@ func (p *Pipeline) performInterrupt(ctx context.Context) {
 	p.state.SetState(StateListening)
 
 	// 启动连续对话超时计时器
-	if p.cfg.Dialog.ContinuousTimeout > 0 {
+	if p.continuousTimeout() > 0 {
 		p.startContinuousTimer()
 	}
 
@@ -838,24 +1253,25 @@ func (p *Pipeline) performInterrupt(ctx context.Context) {
 
 // playWakeReply 播放唤醒回复语，完成后进入监听状态。
 func (p *Pipeline) playWakeReply(ctx context.Context) {
-	logger.Debugf("[pipeline] 播放唤醒回复: %s", p.cfg.Dialog.WakeReply)
-	p.speakText(ctx, p.cfg.Dialog.WakeReply)
+	reply := p.localizedWakeReply()
+	logger.Debugf("[pipeline] 播放唤醒回复: %s", reply)
+	p.speakText(ctx, reply)
 
 	// 延迟后进入监听状态（给用户反应时间）
-	if p.cfg.Dialog.ListenDelay > 0 {
-		time.Sleep(time.Duration(p.cfg.Dialog.ListenDelay) * time.Millisecond)
+	if p.listenDelay() > 0 {
+		time.Sleep(p.listenDelay())
 	}
 	p.capture.Drain() // 清空回声残留
 
 	// 播放完成后进入监听状态
 	p.vadDetector.Reset()
-	p.recognizer.Reset()
+	p.resetRecognizer()
 	p.state.SetState(StateListening)
 
 	// 启动连续对话超时计时器
-	if p.cfg.Dialog.ContinuousTimeout > 0 {
+	if p.continuousTimeout() > 0 {
 		p.startContinuousTimer()
-		logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.cfg.Dialog.ContinuousTimeout)
+		logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.continuousTimeout())
 	}
 
 	// 解除冷却期（延迟一点，确保不会立即重复检测）
@@ -900,8 +1316,10 @@ func (p *Pipeline) handleListening(ctx context.Context, frame []float32) {
 
 	p.vadDetector.Feed(frame)
 	p.recognizer.Feed(frame)
+	p.chargeASRBudget(len(frame))
 
 	text := p.recognizer.GetResult()
+	p.endpoint.feed(len(frame), text)
 	if text != "" {
 		// 只在中间结果变化时打印日志，避免相同结果重复刷屏
 		if text != p.lastASRText {
@@ -912,9 +1330,9 @@ func (p *Pipeline) handleListening(ctx context.Context, frame []float32) {
 		p.resetContinuousTimer()
 	}
 
-	if p.recognizer.IsEndpoint() {
+	if p.isEndpoint() {
 		finalText := p.recognizer.GetResult()
-		p.recognizer.Reset()
+		p.resetRecognizer()
 		p.lastASRText = "" // 清除中间结果去重状态
 		p.vadDetector.Reset()
 
@@ -942,15 +1360,48 @@ func (p *Pipeline) handleListening(ctx context.Context, frame []float32) {
 		finalText = sanitizeASRText(finalText)
 		// 纠正常见的同音字错误
 		finalText = correctASRMistakes(finalText)
+		// 去掉预录音频带进来的唤醒词
+		finalText = trimWakeWord(finalText, p.wakeWords)
 		if finalText == "" {
 			return
 		}
 
+		// 听写模式：只记录，不交给大模型
+		if p.dictationActive() {
+			p.handleDictationText(finalText)
+			return
+		}
+
+		// 答题闹钟响着：用户说的话当作答案
+		if p.answerAlarmQuiz(ctx, finalText) {
+			return
+		}
+
+		// 到期提醒后用户说"知道了"：停止提醒，不交给大模型
+		p.ackReminder()
+		if p.takeReminderAck() && isReminderAck(finalText, p.wakeWords) {
+			logger.Infof("[pipeline] 确认收到提醒: %s", finalText)
+			p.stopContinuousTimer()
+			p.state.SetState(StateSpeaking)
+			go func() {
+				p.playCue(ctx)
+				p.state.ForceIdle()
+			}()
+			return
+		}
+
 		// 有有效文本，停止计时器，进入处理阶段
 		p.stopContinuousTimer()
 
 		logger.Infof("[pipeline] ASR 最终结果: %s", finalText)
+		p.latency.markASREnd(finalText)
 		p.state.SetState(StateProcessing)
+		if p.cfg.Dialog.FastInterrupt {
+			if cmd, ok := matchFastCommand(finalText, p.cfg.Tools.Volume.Step, p.wakeWords); ok {
+				go p.runFastCommand(ctx, finalText, cmd)
+				return
+			}
+		}
 		go p.processQuery(ctx, finalText)
 	}
 }
@@ -962,9 +1413,11 @@ func (p *Pipeline) handleListening(ctx context.Context, frame []float32) {
 func (p *Pipeline) processQuery(ctx context.Context, query string) {
 	// 等待声纹识别完成（如果正在进行）
 	p.voiceprintWg.Wait()
+	defer p.latency.finish()
 
 	// 重置打断标志
 	p.interrupted.Store(false)
+	p.interruptedMusic.Store(false)
 
 	// 创建可取消的 sub-context，打断时可立即停止 LLM 调用
 	queryCtx, cancelQuery := context.WithCancel(ctx)
@@ -978,11 +1431,29 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 		p.queryMu.Unlock()
 	}()
 
+	if text := p.takeFollowUp(); text != "" {
+		p.contextManager.Add("assistant", text)
+	}
+	// 云端识别额度刚用完、或云端识别不可用自动切换到离线识别时先提示一次
+	p.announceASRBudget(queryCtx)
+	p.announceASRFallback(queryCtx)
+	// 说话人今天过生日时，当天第一次对话先送上祝福
+	p.celebrateBirthday(queryCtx)
+	if p.interrupted.Load() {
+		return
+	}
 	p.contextManager.Add("user", query)
+	p.usage.Add(tools.UsageQuery, 1)
+
+	// 明显需要查询工具的问题（如天气）先并行调用工具，减少一轮等待
+	prefetch := p.startPrefetch(queryCtx, query)
 
-	toolDefs := p.toolRegistry.Definitions()
+	toolDefs := p.toolRegistry.DefinitionsFor(query)
 	maxRounds := 5 // 最多 5 轮 LLM 调用（工具调用可能多轮，最后需要一轮生成回复）
 	var lastHadToolCalls bool
+	toolMessages := 0 // 本次对话已添加的 tool 消息数，大于 0 说明一句话里有多个请求
+	var pendingPlay *playbackRequest
+	var guard toolLoopGuard
 
 	for round := 0; round < maxRounds; round++ {
 		// 检查打断
@@ -990,55 +1461,121 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 			return
 		}
 
+		// 主模型刚失败过：不再等待超时，直接降级处理
+		if round == 0 && p.llmDown() {
+			p.answerDegraded(ctx, queryCtx, query)
+			return
+		}
+
 		messages := p.contextManager.Messages()
+		// 最后一轮或出现重复调用：不再提供工具，让大模型根据已有结果直接回答
+		defs := toolDefs
+		if round > 0 && (round == maxRounds-1 || guard.looped) {
+			logger.Infof("[pipeline] 第 %d 轮不再提供工具，要求直接回答", round+1)
+			defs = nil
+			messages = finalRoundMessages(messages)
+		}
 
-		textCh, resultCh, err := p.llmProvider.ChatStreamWithTools(queryCtx, messages, toolDefs)
+		textCh, resultCh, err := p.llmProvider.ChatStreamWithTools(queryCtx, messages, defs)
 		if err != nil {
 			logger.Errorf("[pipeline] LLM 调用失败: %v", err)
+			if queryCtx.Err() != nil {
+				return
+			}
+			// 第一轮就失败（网络不通等）：本地能处理的问题照常回答
+			if round == 0 && !llm.IsInsufficientBalance(err) {
+				p.markLLMDown()
+				p.answerDegraded(ctx, queryCtx, query)
+				return
+			}
 			// 检查是否为余额不足错误
 			if llm.IsInsufficientBalance(err) {
 				p.state.SetState(StateSpeaking)
-				p.speakTextWithFallback(ctx, "大模型余额不足，请充值后再试")
+				p.speakTextWithFallback(ctx, p.localize("大模型余额不足，请充值后再试"))
 			} else if p.fallbackTtsEngine != nil {
 				// 使用备用 TTS 播放错误提示
 				p.state.SetState(StateSpeaking)
-				p.speakText(queryCtx, "网络连接失败，请检查网络设置")
+				p.speakText(queryCtx, p.localize("网络连接失败，请检查网络设置"))
 			}
 			p.state.ForceIdle()
 			return
 		}
 
-		// 先缓冲完整回复，等流结束后再决定处理方式
+		// 边接收边朗读：完整的句子先送 TTS（关闭 buffer_reply 时）；
+		// 否则先缓冲完整回复，等流结束后再决定处理方式
 		var fullReply strings.Builder
+		var speaker *streamSpeaker
+		if !p.cfg.Dialog.BufferReply {
+			speaker = p.newStreamSpeaker(queryCtx)
+		}
 
 		for chunk := range textCh {
 			if p.interrupted.Load() {
 				for range resultCh {
 				}
+				if speaker != nil {
+					speaker.Finish(false)
+				}
 				return
 			}
+			p.latency.markFirstToken()
 			fullReply.WriteString(chunk)
+			if speaker != nil {
+				speaker.Push(chunk)
+			}
 		}
 
 		// 获取最终结果（包含可能的 tool_calls）
 		result := <-resultCh
+		p.latency.markFirstToken()
 		if result == nil {
+			if speaker != nil {
+				speaker.Finish(true)
+			}
 			break
 		}
 
 		// 检查打断
 		if p.interrupted.Load() {
+			if speaker != nil {
+				speaker.Finish(false)
+			}
 			return
 		}
 
+		// 已经边生成边朗读：读完剩余部分
+		if speaker != nil && speaker.Started() {
+			if len(result.ToolCalls) == 0 {
+				speaker.Finish(true)
+				// 超出朗读上限时只记录实际读出的内容，用户说"要"时大模型才知道从哪里接着讲
+				if speaker.Truncated() {
+					p.contextManager.Add("assistant", speaker.Spoken())
+				} else {
+					p.contextManager.Add("assistant", fullReply.String())
+				}
+				logger.Infof("[pipeline] LLM 回复完成 (%d 字符，流式朗读)", fullReply.Len())
+				lastHadToolCalls = false
+				break
+			}
+			// 朗读开始后才出现工具调用，已读出的内容作为前言，剩余部分丢弃
+			speaker.Finish(false)
+			logger.Debugf("[pipeline] 流式朗读后出现工具调用，已朗读部分作为前言")
+		}
+
 		// 如果没有工具调用，合并短句后 TTS 播放
 		if len(result.ToolCalls) == 0 {
 			lastHadToolCalls = false
 			replyText := strings.TrimSpace(fullReply.String())
+			contextReply := fullReply.String()
 			if replyText != "" && !p.interrupted.Load() {
-				p.state.Transition(StateSpeaking)
 				// 先预处理文本（表格转口语等），再按句子分段，避免表格被逐行拆碎
 				replyText = tts.PreprocessText(replyText)
+				// 超出朗读上限：压缩后朗读，上下文里记录实际读出的内容
+				if limit := p.replyRuneLimit(); limit > 0 && utf8.RuneCountInString(replyText) > limit {
+					replyText = p.condenseReply(queryCtx, replyText)
+					contextReply = replyText
+				}
+				p.state.Transition(StateSpeaking)
 				// 合并短句为大段（每段最多 100 个字符），减少 TTS 次数
 				chunks := mergeSentences(replyText, 100)
 				for _, chunk := range chunks {
@@ -1047,8 +1584,14 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 						p.speakText(queryCtx, chunk)
 					}
 				}
+			} else if replyText == "" && len(guard.attempts) > 0 && !p.interrupted.Load() {
+				// 调用过工具却没有回答：说明尝试过什么，不要一声不吭
+				contextReply = p.toolLoopFallback(&guard)
+				logger.Warnf("[pipeline] 工具调用后大模型没有回答，朗读兜底回复: %s", contextReply)
+				p.state.Transition(StateSpeaking)
+				p.speakText(queryCtx, contextReply)
 			}
-			p.contextManager.Add("assistant", fullReply.String())
+			p.contextManager.Add("assistant", contextReply)
 			logger.Infof("[pipeline] LLM 回复完成 (%d 字符)", fullReply.Len())
 			break
 		}
@@ -1060,8 +1603,8 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 			logger.Debugf("[pipeline] 检测到工具调用，丢弃前言文本: %s", preamble)
 		}
 
-		// 播放工具等待提示
-		if p.cfg.Dialog.ToolReply != "" {
+		// 播放工具等待提示（已经朗读过前言时不再重复）
+		if p.cfg.Dialog.ToolReply != "" && (speaker == nil || !speaker.Started()) {
 			p.state.Transition(StateSpeaking)
 			p.speakText(queryCtx, p.cfg.Dialog.ToolReply)
 		}
@@ -1078,15 +1621,19 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 		}
 		p.contextManager.AddMessage(assistantMsg)
 
-		// 执行每个工具并将结果添加到上下文
-		for _, tc := range result.ToolCalls {
+		// 执行每个工具并将结果添加到上下文。会结束对话的工具（播放音乐等）排在最后，
+		// 一句话里的其他请求先执行完
+		calls := orderToolCalls(result.ToolCalls)
+		roundMessages := 0 // 本轮已添加的 tool 消息数
+		for i, tc := range calls {
 			// 检查打断
 			if p.interrupted.Load() {
 				return
 			}
+			multi := len(calls) > 1 || toolMessages > 0
 
-			// 权限检查：声纹相关工具只有主人可用
-			if isVoiceprintTool(tc.Function.Name) {
+			// 权限检查：声纹相关工具和部分设置只有主人可用
+			if p.ownerRequired(tc.Function.Name) {
 				speakerName := p.contextManager.GetCurrentSpeaker()
 				if !p.voiceprintMgr.IsOwner(speakerName) {
 					logger.Warnf("[pipeline] 非主人尝试调用 %s 工具: %s", tc.Function.Name, speakerName)
@@ -1096,47 +1643,125 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 						ToolCallID: tc.ID,
 						Name:       tc.Function.Name,
 					})
+					roundMessages++
+					toolMessages++
 					continue
 				}
 			}
 
-			logger.Infof("[pipeline] 调用工具: %s(%s)", tc.Function.Name, tc.Function.Arguments)
+			// 学习时间：孩子不能点歌、听故事、玩游戏
+			if reminder, blocked := p.studyBlocked(tc.Function.Name); blocked {
+				logger.Infof("[pipeline] 学习时间，拒绝 %s 调用 %s", p.contextManager.GetCurrentSpeaker(), tc.Function.Name)
+				content, _ := json.Marshal(map[string]interface{}{"success": false, "message": reminder})
+				p.contextManager.AddMessage(llm.Message{
+					Role:       "tool",
+					Content:    string(content),
+					ToolCallID: tc.ID,
+					Name:       tc.Function.Name,
+				})
+				roundMessages++
+				toolMessages++
+				continue
+			}
 
-			toolResult, err := p.toolRegistry.Execute(ctx, tc.Function.Name, json.RawMessage(tc.Function.Arguments))
-			if err != nil {
-				toolResult = fmt.Sprintf("工具执行失败: %v", err)
+			// 访客模式：只开放查询、听歌等不写入家庭数据的工具
+			if p.guestBlocked(tc.Function.Name) {
+				logger.Infof("[pipeline] 访客模式，拒绝调用 %s", tc.Function.Name)
+				p.contextManager.AddMessage(llm.Message{
+					Role:       "tool",
+					Content:    `{"success":false,"message":"访客模式下不能使用这个功能"}`,
+					ToolCallID: tc.ID,
+					Name:       tc.Function.Name,
+				})
+				roundMessages++
+				toolMessages++
+				continue
 			}
 
-			// 检查是否是需要跳过 LLM 的工具结果（这些情况不添加 tool 消息，直接处理）
-			// 检查是否是音乐播放结果
-			if tc.Function.Name == "play_music" || tc.Function.Name == "next_music" || tc.Function.Name == "resume_music" {
-				var musicResult tools.MusicResult
-				if jsonErr := json.Unmarshal([]byte(toolResult), &musicResult); jsonErr == nil {
-					if musicResult.Success && (musicResult.URL != "" || musicResult.CacheKey != "") {
-						// 播放音乐（移除已添加的 assistant(tool_calls) 消息，不添加 tool 消息）
-						p.contextManager.RemoveLastMessages(1)
-						logger.Infof("[pipeline] 开始播放音乐: %s - %s", musicResult.Artist, musicResult.SongName)
-						p.playMusicFromPosition(ctx, musicResult.URL, musicResult.CacheKey, musicResult.PositionSec)
-						// 音乐播放结束后继续
+			// 高风险操作：执行前重新录一段主人的声音，按更严格的阈值复核，不只信任唤醒时的识别
+			if p.challengeRequired(tc.Function.Name, tc.Function.Arguments) {
+				if ok, reason := p.verifyOwnerVoice(queryCtx); !ok {
+					if p.interrupted.Load() {
 						return
 					}
+					logger.Warnf("[pipeline] 声纹复核未通过，拒绝调用 %s: %s", tc.Function.Name, reason)
+					content, _ := json.Marshal(map[string]interface{}{"success": false, "message": reason})
+					p.contextManager.AddMessage(llm.Message{
+						Role:       "tool",
+						Content:    string(content),
+						ToolCallID: tc.ID,
+						Name:       tc.Function.Name,
+					})
+					roundMessages++
+					toolMessages++
+					continue
 				}
 			}
 
+			// 同一个工具又用同样的参数调用：不再执行，下一轮不带工具让大模型直接回答
+			if guard.repeated(tc.Function.Name, tc.Function.Arguments) {
+				logger.Warnf("[pipeline] 重复调用工具 %s(%s)，不再执行", tc.Function.Name, tc.Function.Arguments)
+				p.contextManager.AddMessage(llm.Message{
+					Role:       "tool",
+					Content:    repeatedToolResult,
+					ToolCallID: tc.ID,
+					Name:       tc.Function.Name,
+				})
+				roundMessages++
+				toolMessages++
+				continue
+			}
+
+			logger.Infof("[pipeline] 调用工具: %s(%s)", tc.Function.Name, tc.Function.Arguments)
+
+			var toolResult string
+			var err error
+			toolResult, err = p.runTool(queryCtx, prefetch, tc.Function.Name, tc.Function.Arguments)
+			p.usage.Add(tools.UsageToolMetric(tc.Function.Name), 1)
+
+			// 常见故障（音乐服务未启动、登录过期等）直接播报具体提示，避免大模型笼统地道歉
+			if failure, ok := tools.MapToolError(tc.Function.Name, toolResult, err); ok {
+				logger.Warnf("[pipeline] 工具 %s 失败 (%s): %s", failure.Tool, failure.Code, failure.Detail)
+				p.toolFailures.Add(failure)
+				if !multi {
+					p.contextManager.RemoveLastMessages(1 + roundMessages)
+					p.state.Transition(StateSpeaking)
+					p.speakText(queryCtx, failure.Hint)
+					if !p.interrupted.Load() {
+						p.enterContinuousMode()
+					}
+					return
+				}
+				// 一句话里有多个请求时，提示只作为这个调用的结果，其他请求照常执行
+				toolResult, err = toolFailureResult(failure), nil
+			}
+			if err != nil {
+				toolResult = fmt.Sprintf("工具执行失败: %v", err)
+			}
+
+			// 音乐播放结果：记下待播放的歌曲，继续执行其他请求，对话结束后再开始播放
+			if req := newPlaybackRequest(tc.Function.Name, toolResult); req != nil {
+				pendingPlay = req
+				toolResult = req.toolResult()
+			}
+
+			// 检查是否是需要跳过 LLM 的工具结果（这些情况不添加 tool 消息，直接处理）
+
 			// 检查是否是故事结果且需要跳过 LLM
 			if tc.Function.Name == "tell_story" {
 				var storyResult tools.StoryResult
 				if jsonErr := json.Unmarshal([]byte(toolResult), &storyResult); jsonErr == nil {
 					if storyResult.SkipLLM && storyResult.Success && storyResult.Content != "" {
-						// 直接送 TTS，跳过 LLM（移除已添加的 assistant(tool_calls) 消息，不添加 tool 消息）
-						p.contextManager.RemoveLastMessages(1)
-						logger.Infof("[pipeline] 直接朗读故事（跳过LLM）: %s", storyResult.Title)
-						p.state.Transition(StateSpeaking)
-						p.speakText(queryCtx, storyResult.Content) // 使用 queryCtx 以支持打断
-						// 播放完成后进入连续对话模式
-						if !p.interrupted.Load() {
-							p.enterContinuousMode()
-						}
+						// 直接送 TTS，跳过 LLM（单个请求时移除已添加的 assistant(tool_calls) 消息，不添加 tool 消息）
+						p.finishTerminal(queryCtx, tc, calls[i+1:], multi, roundMessages, "讲故事"+storyResult.Title, func() {
+							logger.Infof("[pipeline] 直接朗读故事（跳过LLM）: %s", storyResult.Title)
+							p.state.Transition(StateSpeaking)
+							p.speakText(queryCtx, storyResult.Content) // 使用 queryCtx 以支持打断
+							// 播放完成后进入连续对话模式
+							if !p.interrupted.Load() {
+								p.enterContinuousMode()
+							}
+						})
 						return
 					}
 				}
@@ -1151,18 +1776,45 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 				}
 				if jsonErr := json.Unmarshal([]byte(toolResult), &sleepResult); jsonErr == nil {
 					if sleepResult.Success && sleepResult.Action == "sleep" {
-						logger.Info("[pipeline] 用户说休息，停止监听")
-						// 停止连续对话计时器
-						p.stopContinuousTimer()
-						// 移除已添加的 assistant(tool_calls) 消息
-						p.contextManager.RemoveLastMessages(1)
-						// 直接回到空闲状态
-						p.state.ForceIdle()
+						p.finishTerminal(queryCtx, tc, calls[i+1:], multi, roundMessages, "休息", func() {
+							logger.Info("[pipeline] 用户说休息，停止监听")
+							// 停止连续对话计时器
+							p.stopContinuousTimer()
+							// 直接回到空闲状态
+							p.state.ForceIdle()
+						})
+						return
+					}
+				}
+			}
+
+			// 检查是否是开始听写
+			if tc.Function.Name == "start_dictation" {
+				var dictResult tools.DictationResult
+				if jsonErr := json.Unmarshal([]byte(toolResult), &dictResult); jsonErr == nil {
+					if dictResult.Success && dictResult.Action == "start" {
+						// 直接进入听写模式
+						p.finishTerminal(queryCtx, tc, calls[i+1:], multi, roundMessages, "开始听写", func() {
+							p.startDictation(ctx, dictResult)
+						})
 						return
 					}
 				}
 			}
 
+			// 隐私过滤：结果不能发给云端大模型，不经过大模型直接朗读
+			if text, ok := p.localOnlyReply(tc.Function.Name, toolResult); ok {
+				p.finishTerminal(queryCtx, tc, calls[i+1:], multi, roundMessages, "告诉用户查询结果", func() {
+					logger.Infof("[pipeline] %s 的结果仅限本地，直接朗读", tc.Function.Name)
+					p.state.Transition(StateSpeaking)
+					p.speakText(queryCtx, text)
+					if !p.interrupted.Load() {
+						p.enterContinuousMode()
+					}
+				})
+				return
+			}
+
 			// 其他情况：添加工具结果到上下文，让 LLM 生成回复
 			p.contextManager.AddMessage(llm.Message{
 				Role:       "tool",
@@ -1170,20 +1822,51 @@ func (p *Pipeline) processQuery(ctx context.Context, query string) {
 				ToolCallID: tc.ID,
 				Name:       tc.Function.Name,
 			})
+			roundMessages++
+			toolMessages++
+		}
+
+		// 只有一个播放请求：不用再等大模型回复，直接开始播放
+		// （移除本轮的 assistant(tool_calls) 和 tool 消息，与直接朗读故事等一致）
+		if pendingPlay != nil && toolMessages == 1 {
+			p.contextManager.RemoveLastMessages(2)
+			lastHadToolCalls = false
+			break
 		}
 		// 继续下一轮 LLM 调用
 	}
 
-	// 如果最后一轮仍有工具调用，说明达到最大轮数限制，可能未完成回复
+	if p.interrupted.Load() {
+		return
+	}
+	// 最后一轮仍有工具调用，说明达到最大轮数限制还没有回答：朗读尝试过的操作
 	if lastHadToolCalls {
-		logger.Warnf("[pipeline] 达到最大轮数 %d，可能未完成回复", maxRounds)
+		reply := p.toolLoopFallback(&guard)
+		logger.Warnf("[pipeline] 达到最大轮数 %d，未完成回复，朗读兜底回复: %s", maxRounds, reply)
+		p.contextManager.Add("assistant", reply)
+		p.state.Transition(StateSpeaking)
+		p.speakText(queryCtx, reply)
+		if p.interrupted.Load() {
+			return
+		}
+	}
+	// 有待播放的音乐时交给播放 goroutine，播放结束后再进入连续对话模式
+	if pendingPlay != nil {
+		p.startPlayback(ctx, pendingPlay)
+		return
 	}
-
 	// 回复完成后进入连续对话模式（等待用户继续说）
-	// 但如果已经被打断，则不进入
-	if !p.interrupted.Load() {
-		p.enterContinuousMode()
+	p.enterContinuousMode()
+}
+
+// setSpeechRate 调整主/备用 TTS 引擎的语速倍率（引擎不支持时忽略）。
+func (p *Pipeline) setSpeechRate(rate float64) {
+	for _, engine := range []tts.Engine{p.ttsEngine, p.fallbackTtsEngine} {
+		if ra, ok := engine.(tts.RateAdjustable); ok {
+			ra.SetSpeechRate(rate)
+		}
 	}
+	logger.Infof("[pipeline] 语速倍率: %.1f", rate)
 }
 
 // identifySpeaker 异步识别说话人并注入 LLM 上下文。
@@ -1198,17 +1881,23 @@ func (p *Pipeline) identifySpeaker(samples []float32) {
 	}
 	if name != "" {
 		logger.Debugf("[pipeline] 声纹识别结果: %s", name)
+		p.rememberWakeSpeaker(name)
 		// 获取用户信息（包含偏好）
 		user, err := p.voiceprintMgr.GetUser(name)
 		if err != nil {
 			logger.Warnf("[pipeline] 获取用户信息失败: %v", err)
 			p.contextManager.SetCurrentSpeaker(name, nil)
+			user = nil
 		} else {
 			p.contextManager.SetCurrentSpeaker(name, user)
 		}
+		p.applySpeakerEndpoint(user)
 	} else {
 		p.contextManager.SetCurrentSpeaker("", nil)
+		p.applySpeakerEndpoint(nil)
 	}
+	p.setReplyLanguage(p.contextManager.SpeakerLanguage())
+	p.applySpeakerPersona()
 }
 
 // enterContinuousMode 进入连续对话模式。
@@ -1222,27 +1911,39 @@ func (p *Pipeline) enterContinuousMode() {
 		p.voiceprintBufMu.Unlock()
 	}
 
-	if p.cfg.Dialog.ContinuousTimeout <= 0 {
+	if p.continuousTimeout() <= 0 && !p.dictationActive() {
 		// 连续对话模式禁用，直接回到空闲
+		p.clearScratchpad()
+		p.resetSessionPersona()
 		p.state.ForceIdle()
 		return
 	}
 
 	// 延迟 + 清空麦克风缓冲，防止扬声器回声被 ASR 识别
-	if p.cfg.Dialog.ListenDelay > 0 {
-		time.Sleep(time.Duration(p.cfg.Dialog.ListenDelay) * time.Millisecond)
+	if p.listenDelay() > 0 {
+		time.Sleep(p.listenDelay())
 	}
 	p.capture.Drain()
 
 	// 进入监听状态
 	p.vadDetector.Reset()
-	p.recognizer.Reset()
+	p.resetRecognizer()
 	p.state.ForceIdle() // 先重置
 	p.state.Transition(StateListening)
 
 	// 启动超时计时器
 	p.startContinuousTimer()
-	logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.cfg.Dialog.ContinuousTimeout)
+	logger.Infof("[pipeline] 进入连续对话模式，%d 秒内无输入将回到空闲", p.continuousTimeout())
+}
+
+// clearScratchpad 对话结束时清空草稿，没有保存到备忘录的内容不带到下一次对话。
+func (p *Pipeline) clearScratchpad() {
+	if p.scratchpad == nil {
+		return
+	}
+	if n := p.scratchpad.Clear(); n > 0 {
+		logger.Infof("[pipeline] 对话结束，已清空草稿（%d 项）", n)
+	}
 }
 
 // startContinuousTimer 启动连续对话超时计时器。
@@ -1255,10 +1956,25 @@ func (p *Pipeline) startContinuousTimer() {
 		p.continuousTimer.Stop()
 	}
 
+	// 听写模式下允许更长的停顿，超时后保存记录
+	timeout := time.Duration(p.continuousTimeout()) * time.Second
+	if p.dictationActive() {
+		timeout = time.Duration(p.cfg.Dialog.DictationTimeout) * time.Second
+	}
+
 	// 启动新计时器，超时后直接回到空闲
-	p.continuousTimer = time.AfterFunc(time.Duration(p.cfg.Dialog.ContinuousTimeout)*time.Second, func() {
+	p.continuousTimer = time.AfterFunc(timeout, func() {
 		if p.state.Current() == StateListening {
+			if p.dictationActive() {
+				logger.Info("[pipeline] 听写静默超时，结束记录")
+				p.state.SetState(StateProcessing)
+				p.finishDictation()
+				return
+			}
 			logger.Info("[pipeline] 连续对话超时，回到空闲状态")
+			p.clearGuestContext()
+			p.clearScratchpad()
+			p.resetSessionPersona()
 			// 取消正在进行的 ASR 请求
 			if canceler, ok := p.recognizer.(interface{ Cancel() }); ok {
 				logger.Debug("[pipeline] 调用 ASR Cancel()")
@@ -1388,13 +2104,16 @@ func (p *Pipeline) speakTextWithFallbackAndReturn(ctx context.Context, text stri
 	// 预处理文本：删除 Markdown 格式等不适合朗读的内容
 	text = tts.PreprocessText(text)
 	
+	synthStart := time.Now()
 	samples, sampleRate, err := p.ttsEngine.Synthesize(ctx, text)
+	p.latency.addSynth(time.Since(synthStart))
 	if err != nil {
 		logger.Errorf("[pipeline] TTS 合成失败: %v", err)
 		// 尝试使用备用引擎合成原文（分段场景下不播放错误提示）
 		if p.fallbackTtsEngine != nil {
 			if fbSamples, fbRate, fbErr := p.fallbackTtsEngine.Synthesize(ctx, text); fbErr == nil && len(fbSamples) > 0 {
 				logger.Info("[pipeline] 使用备用 TTS 引擎播放")
+				p.normalizeSpeech(fbSamples, fbRate)
 				p.playSamples(ctx, fbSamples, fbRate)
 				return nil
 			} else if fbErr != nil {
@@ -1409,6 +2128,7 @@ func (p *Pipeline) speakTextWithFallbackAndReturn(ctx context.Context, text stri
 		return fmt.Errorf("TTS 合成返回空音频")
 	}
 
+	p.normalizeSpeech(samples, sampleRate)
 	p.playSamples(ctx, samples, sampleRate)
 	return nil
 }
@@ -1427,6 +2147,7 @@ func (p *Pipeline) playSamples(ctx context.Context, samples []float32, sampleRat
 		p.speakMu.Unlock()
 	}()
 
+	p.latency.markPlayback()
 	if err := p.player.Play(speakCtx, samples, sampleRate); err != nil && err != context.Canceled {
 		logger.Errorf("[pipeline] 播放失败: %v", err)
 	}
@@ -1440,156 +2161,19 @@ func (p *Pipeline) interruptSpeak() {
 	}
 	p.speakMu.Unlock()
 
-	// 暂停音乐播放并保存状态
-	if p.streamPlayer != nil {
-		p.streamPlayer.Stop()
-	}
-
-	// 保存当前播放状态（用于恢复播放）
-	p.savePausedMusic()
-}
-
-// savePausedMusic 保存当前播放状态。
-func (p *Pipeline) savePausedMusic() {
-	if p.playlist == nil {
-		return
-	}
-
-	current := p.playlist.Current()
-	if current == nil {
-		return
+	// 暂停音乐播放并保存状态（用于恢复播放）
+	if p.playback != nil {
+		p.playback.Pause()
 	}
-
-	// 计算播放位置
-	p.musicPlayStartMu.Lock()
-	positionSec := time.Since(p.musicPlayStart).Seconds()
-	cacheKey := p.currentCacheKey
-	p.musicPlayStartMu.Unlock()
-
-	p.pausedStore.Save(
-		p.playlist.GetItems(),
-		p.playlist.CurrentIndex(),
-		p.playlist.Mode(),
-		current.Song.Name,
-		positionSec,
-		cacheKey,
-	)
-
-	logger.Infof("[pipeline] 已保存播放状态: %s (索引 %d/%d, 位置 %.1fs)",
-		current.Song.Name, p.playlist.CurrentIndex()+1, p.playlist.Len(), positionSec)
 }
 
-// playMusic 播放音乐，播放结束后自动播放列表中的下一首。
-func (p *Pipeline) playMusic(ctx context.Context, url string, cacheKey string) {
-	p.playMusicFromPosition(ctx, url, cacheKey, 0)
-}
-
-// playMusicFromPosition 从指定位置播放音乐，播放结束后自动播放列表中的下一首。
-// positionSec > 0 时，如果缓存存在则从指定位置开始播放。
-func (p *Pipeline) playMusicFromPosition(ctx context.Context, url string, cacheKey string, positionSec float64) {
-	// 确保状态为 Speaking
-	if p.state.Current() != StateSpeaking {
-		p.state.SetState(StateSpeaking)
-	}
-
-	// 记录播放开始时间和缓存 key（用于恢复播放）
-	// 如果从位置恢复，需要调整开始时间以反映实际播放位置
-	p.musicPlayStartMu.Lock()
-	if positionSec > 0 {
-		p.musicPlayStart = time.Now().Add(-time.Duration(positionSec * float64(time.Second)))
-	} else {
-		p.musicPlayStart = time.Now()
-	}
-	p.currentCacheKey = cacheKey
-	p.musicPlayStartMu.Unlock()
-
-	// 检查是否可以从缓存文件的位置播放
-	if positionSec > 0 && cacheKey != "" && p.musicCache != nil {
-		if cachedPath, ok := p.musicCache.Lookup(cacheKey); ok {
-			logger.Infof("[pipeline] 从 %.0f 秒处恢复播放 (缓存: %s)", positionSec, cacheKey)
-			actualPos, err := p.streamPlayer.PlayFromPosition(ctx, cachedPath, positionSec)
-			if err != nil {
-				logger.Warnf("[pipeline] 从位置播放失败，从头播放: %v", err)
-				// 失败时从头播放
-				positionSec = 0
-				p.musicPlayStartMu.Lock()
-				p.musicPlayStart = time.Now()
-				p.musicPlayStartMu.Unlock()
-				opts := &audio.PlayOptions{
-					CacheKey: cacheKey,
-					Cache:    p.musicCache,
-				}
-				if err := p.streamPlayer.Play(ctx, url, opts); err != nil {
-					if err != context.Canceled {
-						logger.Errorf("[pipeline] 音乐播放失败: %v", err)
-					}
-					p.enterContinuousMode()
-					return
-				}
-			} else {
-				logger.Infof("[pipeline] 实际从 %.0f 秒开始播放", actualPos)
-			}
-			// 播放完成，处理下一首
-			p.handleMusicCompletion(ctx, cacheKey)
-			return
-		}
-	}
-
-	// 构建播放选项
-	var opts *audio.PlayOptions
-	if cacheKey != "" && p.musicCache != nil {
-		opts = &audio.PlayOptions{
-			CacheKey: cacheKey,
-			Cache:    p.musicCache,
-		}
-	}
-
-	if err := p.streamPlayer.Play(ctx, url, opts); err != nil {
-		if err != context.Canceled {
-			logger.Errorf("[pipeline] 音乐播放失败: %v", err)
-		}
-		// 被打断或出错，不自动下一首
-		p.enterContinuousMode()
+// onMusicStopped 音乐被打断或播放出错后的处理。
+// 睡前模式渐弱结束时用户多半已经睡着，直接回到空闲，不进入连续对话。
+func (p *Pipeline) onMusicStopped() {
+	if p.sleepAidEnded.CompareAndSwap(true, false) {
+		p.state.ForceIdle()
 		return
 	}
-
-	// 播放完成，处理下一首
-	p.handleMusicCompletion(ctx, cacheKey)
-}
-
-// handleMusicCompletion 处理音乐播放完成后的逻辑（更新缓存索引、自动下一首）。
-func (p *Pipeline) handleMusicCompletion(ctx context.Context, cacheKey string) {
-	// 播放完成，更新缓存索引（如果走了网络下载路径）
-	if cacheKey != "" && p.musicCache != nil && p.musicCache.Enabled() {
-		// 检查缓存文件是否存在（下载完成后会 commit）
-		filePath := p.musicCache.FilePath(cacheKey)
-		if _, err := os.Stat(filePath); err == nil {
-			// 从 playlist 获取当前歌曲信息来更新索引
-			if item := p.playlist.Current(); item != nil {
-				p.musicCache.Store(cacheKey, audio.CacheEntry{
-					ID:       item.Song.ID,
-					Name:     item.Song.Name,
-					Artist:   item.Song.Artist,
-					Album:    item.Song.Album,
-					Provider: cacheKey[:strings.Index(cacheKey, "_")],
-				})
-			}
-		}
-	}
-
-	// 播放正常完成，尝试自动播放下一首
-	if p.playlist != nil && p.playlist.HasNext() {
-		nextURL, songName, artist, nextCacheKey, ok := p.playlist.Next(ctx)
-		if ok {
-			logger.Infof("[pipeline] 自动切换下一首: %s - %s", artist, songName)
-			// 递归播放下一首（仍在同一个 goroutine 中）
-			p.playMusic(ctx, nextURL, nextCacheKey)
-			return
-		}
-	}
-
-	// 列表播完或无下一首，进入连续对话模式
-	logger.Info("[pipeline] 播放列表结束")
 	p.enterContinuousMode()
 }
 
@@ -1598,7 +2182,14 @@ func (p *Pipeline) Close() {
 	logger.Info("[pipeline] 正在关闭...")
 
 	p.interruptSpeak()
+	p.stopSleepAid()
 
+	if p.adminServer != nil {
+		p.adminServer.Close()
+	}
+	if p.musicServer != nil {
+		p.musicServer.Stop()
+	}
 	if p.capture != nil {
 		p.capture.Close()
 	}
@@ -1617,6 +2208,9 @@ func (p *Pipeline) Close() {
 	if p.voiceprintMgr != nil {
 		p.voiceprintMgr.Close()
 	}
+	if p.soundTagger != nil {
+		p.soundTagger.Close()
+	}
 	if p.db != nil {
 		p.db.Close()
 	}
@@ -1625,6 +2219,19 @@ func (p *Pipeline) Close() {
 }
 
 // isVoiceprintTool 检查是否是声纹相关工具（仅主人可用）。
+// ownerRequired 判断工具是否只有主人可用。连续聊天模式、诊断包、修改设置、访客模式在注册了声纹用户时才限制，
+// 未启用声纹时无法区分说话人，所有人都可以开启。
+func (p *Pipeline) ownerRequired(name string) bool {
+	if isVoiceprintTool(name) {
+		return true
+	}
+	switch name {
+	case "set_open_mic", "create_diag_bundle", "manage_settings", "set_guest_mode":
+		return p.voiceprintMgr != nil && p.voiceprintMgr.NumSpeakers() > 0
+	}
+	return false
+}
+
 func isVoiceprintTool(name string) bool {
 	switch name {
 	case "register_voiceprint", "delete_voiceprint", "set_user_preferences":
@@ -1763,6 +2370,55 @@ func sanitizeASRText(text string) string {
 	return strings.TrimSpace(text)
 }
 
+// defaultWakeWords 读不到关键词文件时使用的唤醒词。
+var defaultWakeWords = []string{"你好小派", "小派"}
+
+// wakeGreetings 唤醒词开头的问候语。
+var wakeGreetings = []string{"你好", "嗨", "嘿"}
+
+// loadWakeWords 从关键词文件得到识别结果中可能残留的唤醒词。
+func loadWakeWords(keywordsFile string) []string {
+	keywords, err := wake.LoadKeywords(keywordsFile)
+	if err != nil || len(keywords) == 0 {
+		logger.Warnf("[pipeline] 未能从 %s 读取唤醒词，使用默认唤醒词: %v", keywordsFile, err)
+		return defaultWakeWords
+	}
+	return wakeWordsFrom(keywords)
+}
+
+// wakeWordsFrom 由唤醒词生成要去掉的称呼，长的在前。
+// "你好小派" 这类带问候语的唤醒词同时加入去掉问候语后的名字，用户平时常只叫名字。
+func wakeWordsFrom(keywords []string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	add := func(w string) {
+		if w != "" && !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	for _, kw := range keywords {
+		add(kw)
+		for _, greeting := range wakeGreetings {
+			if name := strings.TrimPrefix(kw, greeting); name != kw && utf8.RuneCountInString(name) >= 2 {
+				add(name)
+			}
+		}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	return words
+}
+
+// trimWakeWord 去掉识别结果开头残留的唤醒词。只有唤醒词时返回空字符串。
+func trimWakeWord(text string, wakeWords []string) string {
+	for _, w := range wakeWords {
+		if strings.HasPrefix(text, w) {
+			return strings.TrimLeft(strings.TrimPrefix(text, w), " 　,，.。!！")
+		}
+	}
+	return text
+}
+
 // correctASRMistakes 纠正 ASR 的常见同音字错误。
 // 主要针对歌曲名、人名、常用词等进行纠正。
 func correctASRMistakes(text string) string {
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// playbackTools 返回可播放音乐的工具。播放不再让 processQuery 提前返回，
+// 而是记下待播放的歌曲，等本次对话的其他请求和回复完成后交给播放 goroutine。
+var playbackTools = map[string]bool{
+	"play_music":         true,
+	"next_music":         true,
+	"resume_music":       true,
+	"play_radio_station": true,
+}
+
+// playbackRequest 等待对话结束后开始的播放。
+type playbackRequest struct {
+	music tools.MusicResult
+}
+
+// newPlaybackRequest 从音乐工具的结果中取出可播放的歌曲，结果不可播放时返回 nil。
+func newPlaybackRequest(tool, result string) *playbackRequest {
+	if !playbackTools[tool] {
+		return nil
+	}
+	var music tools.MusicResult
+	if err := json.Unmarshal([]byte(result), &music); err != nil {
+		return nil
+	}
+	if !music.Success || (music.URL == "" && music.CacheKey == "") {
+		return nil
+	}
+	return &playbackRequest{music: music}
+}
+
+// toolResult 交给大模型的工具结果：只说明即将播放哪首歌，不带播放地址。
+func (r *playbackRequest) toolResult() string {
+	message := fmt.Sprintf("回复后开始播放 %s - %s，不要再调用播放工具", r.music.Artist, r.music.SongName)
+	if r.music.Live {
+		message = fmt.Sprintf("回复后开始收听电台「%s」，不要再调用播放工具", r.music.SongName)
+	}
+	if r.music.Message != "" {
+		message = r.music.Message + "。" + message
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"success":       true,
+		"song_name":     r.music.SongName,
+		"artist":        r.music.Artist,
+		"playlist_size": r.music.PlaylistSize,
+		"message":       message,
+	})
+	return string(data)
+}
+
+// startPlayback 交给播放管理器在独立的 goroutine 中播放音乐，调用方（对话流程）可以正常结束。
+// 播放结束或被打断后由 onPlaybackEvent 进入连续对话模式或回到空闲。
+func (p *Pipeline) startPlayback(ctx context.Context, req *playbackRequest) {
+	m := req.music
+	logger.Infof("[pipeline] 开始播放音乐: %s - %s", m.Artist, m.SongName)
+	p.state.SetState(StateSpeaking)
+	if m.SleepAid {
+		p.startSleepAid(m.SleepMinutes)
+	}
+	p.playback.Play(ctx, PlayRequest{
+		URL:         m.URL,
+		CacheKey:    m.CacheKey,
+		PositionSec: m.PositionSec,
+		Song:        m.SongName,
+		Artist:      m.Artist,
+		Live:        m.Live,
+	})
+}
+
+// nowPlaying 正在播放的内容，供 get_now_playing 工具回答"现在在放什么"。
+// 唤醒时音乐已被暂停，这时回答唤醒前在放的内容。
+func (p *Pipeline) nowPlaying() tools.NowPlaying {
+	st := p.playback.LastPlayed()
+	return tools.NowPlaying{
+		Playing:     st.Playing || p.interruptedMusic.Load(),
+		Song:        st.Song,
+		Artist:      st.Artist,
+		Live:        st.Live,
+		StreamTitle: st.StreamTitle,
+	}
+}
+
+// onPlaybackEvent 处理播放管理器的事件：累计听音乐时长，播放结束或停止后切换对话状态。
+func (p *Pipeline) onPlaybackEvent(ev PlaybackEvent) {
+	p.usage.Add(tools.UsageMusicSeconds, int(ev.Listened.Seconds()))
+	switch ev.Type {
+	case PlaybackStarted:
+		// 记下正在播放的歌，"换成现场版"、"放她别的歌"等追问在本地补全
+		p.contextManager.SetLastMusic(ev.Song, ev.Artist)
+	case PlaybackFinished:
+		// 列表播完或无下一首，进入连续对话模式
+		logger.Info("[pipeline] 播放列表结束")
+		p.stopSleepAid()
+		p.enterContinuousMode()
+	case PlaybackStopped:
+		// 被打断或出错，不自动下一首
+		if ev.Err != nil {
+			logger.Errorf("[pipeline] 音乐播放失败: %v", ev.Err)
+		}
+		p.onMusicStopped()
+	}
+}
@@ -0,0 +1,524 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/music"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// audioPlayer 音乐播放器，即 audio.StreamPlayer，测试时可替换。
+type audioPlayer interface {
+	Play(ctx context.Context, url string, opts *audio.PlayOptions) error
+	PlayFromPosition(ctx context.Context, filePath string, positionSec float64) (float64, error)
+	Stop()
+	SetGain(percent int)
+}
+
+// PlaybackEventType 播放事件类型。
+type PlaybackEventType int
+
+const (
+	PlaybackStarted  PlaybackEventType = iota // 开始播放一首歌（包括自动切换下一首）
+	PlaybackFinished                          // 播放列表播完
+	PlaybackStopped                           // 被暂停、停止或播放出错
+)
+
+// PlaybackEvent 播放状态变化，由播放 goroutine 发出。
+type PlaybackEvent struct {
+	Type     PlaybackEventType
+	Song     string
+	Artist   string
+	Listened time.Duration // 上一段实际播放的时长（Started 事件为自动切歌前那首，首次播放为 0）
+	Err      error         // Stopped 事件：播放出错时非 nil，被暂停或停止时为 nil
+}
+
+// PlayRequest 要播放的歌曲。
+type PlayRequest struct {
+	URL         string
+	CacheKey    string
+	PositionSec float64 // 大于 0 且有缓存时从该位置开始播放
+	Song        string
+	Artist      string
+	Live        bool // 网络电台等直播流：不缓存、不能暂停后恢复，结束后不自动播放下一首
+}
+
+// PlaybackStatus 当前播放状态。
+type PlaybackStatus struct {
+	Playing     bool    `json:"playing"`
+	Song        string  `json:"song,omitempty"`
+	Artist      string  `json:"artist,omitempty"`
+	PositionSec float64 `json:"position_sec,omitempty"`
+	Index       int     `json:"index,omitempty"` // 在播放列表中的序号，从 1 开始
+	Total       int     `json:"total,omitempty"`
+	Mode        string  `json:"mode,omitempty"`
+	Live        bool    `json:"live,omitempty"`
+	StreamTitle string  `json:"stream_title,omitempty"` // 电台推送的当前节目/歌曲名
+}
+
+// playSession 一次 Play 调用启动的播放 goroutine。
+type playSession struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// PlaybackManager 管理音乐播放：播放列表、自动下一首、暂停与恢复、缓存索引。
+// 播放在独立的 goroutine 中进行，状态变化通过事件回调通知 Pipeline。
+type PlaybackManager struct {
+	player   audioPlayer
+	playlist *music.Playlist
+	paused   *music.PausedMusicStore
+	cache    *audio.MusicCache
+	onEvent  func(PlaybackEvent)
+
+	mu        sync.Mutex
+	session   *playSession // 当前播放会话，新的 Play 会让旧会话失效
+	playing   bool
+	current   PlayRequest
+	startedAt time.Time // 当前歌曲 0 秒处对应的时间，用于计算播放位置
+
+	streamTitle string       // 直播流的 ICY 元数据中当前的节目/歌曲名
+	pausedLive  *PlayRequest // 被暂停的直播流，ResumeLive 时重新连接
+}
+
+// NewPlaybackManager 创建播放管理器。
+func NewPlaybackManager(player audioPlayer) *PlaybackManager {
+	return &PlaybackManager{player: player}
+}
+
+// Attach 接入播放列表、暂停状态和音乐缓存（启用音乐工具时调用），均可为 nil。
+func (m *PlaybackManager) Attach(playlist *music.Playlist, paused *music.PausedMusicStore, cache *audio.MusicCache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.playlist = playlist
+	m.paused = paused
+	m.cache = cache
+}
+
+// OnEvent 设置播放事件回调，回调在播放 goroutine 中同步执行。
+func (m *PlaybackManager) OnEvent(fn func(PlaybackEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvent = fn
+}
+
+// Play 停止当前播放并开始播放 req，播放结束后自动播放列表中的下一首。立即返回。
+// 不能在事件回调中调用（回调运行在播放 goroutine 中，Play 会等待它退出）。
+func (m *PlaybackManager) Play(ctx context.Context, req PlayRequest) {
+	ctx, cancel := context.WithCancel(ctx)
+	session := &playSession{cancel: cancel, done: make(chan struct{})}
+	m.mu.Lock()
+	old := m.session
+	m.session = session
+	m.mu.Unlock()
+
+	if old != nil {
+		old.cancel()
+		m.player.Stop()
+		<-old.done
+	}
+
+	m.mu.Lock()
+	m.playing = true
+	m.setCurrentLocked(req)
+	m.pausedLive = nil
+	m.mu.Unlock()
+
+	m.emit(PlaybackEvent{Type: PlaybackStarted, Song: req.Song, Artist: req.Artist})
+	go m.run(ctx, session, req)
+}
+
+// Pause 暂停播放并保存播放列表和位置，之后可用 Resume 恢复。没有在播放时返回 false。
+// 直播流没有位置可言，暂停后用 ResumeLive 重新连接。
+func (m *PlaybackManager) Pause() bool {
+	m.mu.Lock()
+	playing := m.playing
+	if playing && m.current.Live {
+		// 暂停电台后"继续"应接着听电台，不能恢复之前暂停的歌
+		live := m.current
+		m.pausedLive = &live
+		if m.paused != nil {
+			m.paused.Clear()
+		}
+	} else if playing {
+		m.pausedLive = nil
+		m.savePausedLocked()
+	}
+	m.mu.Unlock()
+
+	m.stopSession()
+	return playing
+}
+
+// Stop 停止播放，不保存暂停状态。没有在播放时返回 false。
+func (m *PlaybackManager) Stop() bool {
+	m.mu.Lock()
+	playing := m.playing
+	m.mu.Unlock()
+
+	m.stopSession()
+	return playing
+}
+
+// stopSession 取消当前播放会话并停止播放器，会话随后发出 Stopped 事件。
+func (m *PlaybackManager) stopSession() {
+	m.mu.Lock()
+	session := m.session
+	m.mu.Unlock()
+	if session != nil {
+		session.cancel()
+	}
+	m.player.Stop()
+}
+
+// Resume 恢复最近一次暂停的播放（暂停不超过一分钟时从原位置继续）。
+func (m *PlaybackManager) Resume(ctx context.Context) error {
+	m.mu.Lock()
+	playlist, paused, cache := m.playlist, m.paused, m.cache
+	m.mu.Unlock()
+	if playlist == nil || paused == nil {
+		return fmt.Errorf("音乐功能未启用")
+	}
+
+	result := tools.RestorePausedMusic(playlist, paused, cache)
+	if !result.Success {
+		return errors.New(result.Error)
+	}
+	m.Play(ctx, PlayRequest{
+		URL:         result.URL,
+		CacheKey:    result.CacheKey,
+		PositionSec: result.PositionSec,
+		Song:        result.SongName,
+		Artist:      result.Artist,
+	})
+	return nil
+}
+
+// ResumeLive 重新连接最近一次被暂停的直播流，没有时返回 false。
+func (m *PlaybackManager) ResumeLive(ctx context.Context) bool {
+	m.mu.Lock()
+	req := m.pausedLive
+	m.mu.Unlock()
+	if req == nil {
+		return false
+	}
+	m.Play(ctx, *req)
+	return true
+}
+
+// Next 切换到播放列表中的下一首。
+func (m *PlaybackManager) Next(ctx context.Context) error {
+	req, ok := m.nextRequest(ctx)
+	if !ok {
+		return fmt.Errorf("播放列表中没有下一首")
+	}
+	m.Play(ctx, req)
+	return nil
+}
+
+// Seek 跳到当前歌曲的指定位置（秒），只有已缓存的歌曲支持。
+func (m *PlaybackManager) Seek(ctx context.Context, positionSec float64) error {
+	m.mu.Lock()
+	req, playing, cache := m.current, m.playing, m.cache
+	m.mu.Unlock()
+	if !playing {
+		return fmt.Errorf("当前没有在播放")
+	}
+	if req.CacheKey == "" || cache == nil {
+		return fmt.Errorf("当前歌曲未缓存，无法跳转")
+	}
+	if _, ok := cache.Lookup(req.CacheKey); !ok {
+		return fmt.Errorf("当前歌曲未缓存，无法跳转")
+	}
+	if positionSec < 0 {
+		positionSec = 0
+	}
+	req.PositionSec = positionSec
+	m.Play(ctx, req)
+	return nil
+}
+
+// Duck 临时把音乐压低到原音量的 percent%（报时等播报时），100 恢复原音量。
+// 只影响音乐，不改变系统音量，不打断播放。
+func (m *PlaybackManager) Duck(percent int) {
+	m.player.SetGain(percent)
+}
+
+// PlayClip 播放一段本地 MP3（如生日歌），播完或 ctx 取消后返回，不影响播放列表。
+// 正在播放音乐时返回错误。
+func (m *PlaybackManager) PlayClip(ctx context.Context, path string) error {
+	if m.Playing() {
+		return fmt.Errorf("正在播放音乐")
+	}
+	_, err := m.player.PlayFromPosition(ctx, path, 0)
+	return err
+}
+
+// Playing 返回是否正在播放音乐。
+func (m *PlaybackManager) Playing() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.playing
+}
+
+// Status 返回当前播放状态。
+func (m *PlaybackManager) Status() PlaybackStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := PlaybackStatus{Playing: m.playing}
+	if !m.playing {
+		return status
+	}
+	status.Song = m.current.Song
+	status.Artist = m.current.Artist
+	status.PositionSec = time.Since(m.startedAt).Seconds()
+	if m.current.Live {
+		status.Live = true
+		status.StreamTitle = m.streamTitle
+		return status
+	}
+	if m.playlist != nil {
+		status.Index = m.playlist.CurrentIndex() + 1
+		status.Total = m.playlist.Len()
+		status.Mode = m.playlist.Mode().String()
+	}
+	return status
+}
+
+// LastPlayed 返回正在播放或最近一次播放的歌曲/电台（暂停、播完后仍保留），从未播放时为空。
+func (m *PlaybackManager) LastPlayed() PlaybackStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return PlaybackStatus{
+		Playing:     m.playing,
+		Song:        m.current.Song,
+		Artist:      m.current.Artist,
+		Live:        m.current.Live,
+		StreamTitle: m.streamTitle,
+	}
+}
+
+// setCurrentLocked 记录当前歌曲和开始时间，从位置恢复时开始时间相应前移。
+func (m *PlaybackManager) setCurrentLocked(req PlayRequest) {
+	m.current = req
+	m.streamTitle = ""
+	m.startedAt = time.Now().Add(-time.Duration(req.PositionSec * float64(time.Second)))
+}
+
+// savePausedLocked 保存播放列表、当前索引和播放位置。
+func (m *PlaybackManager) savePausedLocked() {
+	if m.playlist == nil || m.paused == nil {
+		return
+	}
+	current := m.playlist.Current()
+	if current == nil {
+		return
+	}
+	positionSec := time.Since(m.startedAt).Seconds()
+	m.paused.Save(
+		m.playlist.GetItems(),
+		m.playlist.CurrentIndex(),
+		m.playlist.Mode(),
+		current.Song.Name,
+		positionSec,
+		m.current.CacheKey,
+	)
+	logger.Infof("[pipeline] 已保存播放状态: %s (索引 %d/%d, 位置 %.1fs)",
+		current.Song.Name, m.playlist.CurrentIndex()+1, m.playlist.Len(), positionSec)
+}
+
+// isCurrent 判断会话是否仍是当前播放会话。
+func (m *PlaybackManager) isCurrent(session *playSession) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.session == session
+}
+
+// run 播放 goroutine：依次播放当前歌曲和列表中的后续歌曲，直到列表结束、被停止或出错。
+// 被新的 Play 取代时直接退出，不发出事件。
+func (m *PlaybackManager) run(ctx context.Context, session *playSession, req PlayRequest) {
+	defer close(session.done)
+	defer session.cancel()
+	for {
+		listened, err := m.playOne(ctx, session, req)
+		if !m.isCurrent(session) {
+			return
+		}
+		if err != nil {
+			m.finish(session, PlaybackEvent{Type: PlaybackStopped, Song: req.Song, Artist: req.Artist, Listened: listened, Err: playError(err)})
+			return
+		}
+
+		// 直播流结束（电台停止推流）不接着放列表里的歌
+		if req.Live {
+			logger.Infof("[pipeline] 直播流已结束: %s", req.Song)
+			m.finish(session, PlaybackEvent{Type: PlaybackFinished, Song: req.Song, Artist: req.Artist, Listened: listened})
+			return
+		}
+
+		// 播放正常完成，更新缓存索引并尝试自动播放下一首
+		m.commitCache(req.CacheKey)
+		next, ok := m.nextRequest(ctx)
+		if ctx.Err() != nil {
+			m.finish(session, PlaybackEvent{Type: PlaybackStopped, Song: req.Song, Artist: req.Artist, Listened: listened})
+			return
+		}
+		if !ok {
+			m.finish(session, PlaybackEvent{Type: PlaybackFinished, Song: req.Song, Artist: req.Artist, Listened: listened})
+			return
+		}
+		logger.Infof("[pipeline] 自动切换下一首: %s - %s", next.Artist, next.Song)
+		m.mu.Lock()
+		current := m.session == session
+		if current {
+			m.setCurrentLocked(next)
+		}
+		m.mu.Unlock()
+		if !current {
+			return
+		}
+		m.emit(PlaybackEvent{Type: PlaybackStarted, Song: next.Song, Artist: next.Artist, Listened: listened})
+		req = next
+	}
+}
+
+// playError 被停止（ctx 取消）不算出错。
+func playError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// finish 播放会话结束：清除播放标记后发出事件。
+func (m *PlaybackManager) finish(session *playSession, ev PlaybackEvent) {
+	m.mu.Lock()
+	if m.session != session {
+		m.mu.Unlock()
+		return
+	}
+	m.playing = false
+	m.mu.Unlock()
+	m.emit(ev)
+}
+
+func (m *PlaybackManager) emit(ev PlaybackEvent) {
+	m.mu.Lock()
+	fn := m.onEvent
+	m.mu.Unlock()
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// playOne 播放一首歌，返回实际播放时长。
+// 指定了位置且有缓存时从该位置播放，失败则从头播放。
+func (m *PlaybackManager) playOne(ctx context.Context, session *playSession, req PlayRequest) (time.Duration, error) {
+	m.mu.Lock()
+	cache := m.cache
+	m.mu.Unlock()
+	started := time.Now()
+
+	if req.Live {
+		err := m.player.Play(ctx, req.URL, &audio.PlayOptions{
+			Live:       true,
+			OnMetadata: func(title string) { m.setStreamTitle(session, title) },
+		})
+		return time.Since(started), err
+	}
+
+	if req.PositionSec > 0 && req.CacheKey != "" && cache != nil {
+		if cachedPath, ok := cache.Lookup(req.CacheKey); ok {
+			logger.Infof("[pipeline] 从 %.0f 秒处恢复播放 (缓存: %s)", req.PositionSec, req.CacheKey)
+			actualPos, err := m.player.PlayFromPosition(ctx, cachedPath, req.PositionSec)
+			if err == nil {
+				logger.Infof("[pipeline] 实际从 %.0f 秒开始播放", actualPos)
+				return time.Since(started), nil
+			}
+			if errors.Is(err, context.Canceled) || !m.isCurrent(session) {
+				return time.Since(started), err
+			}
+			logger.Warnf("[pipeline] 从位置播放失败，从头播放: %v", err)
+			m.mu.Lock()
+			if m.session == session {
+				m.startedAt = time.Now()
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	var opts *audio.PlayOptions
+	if req.CacheKey != "" && cache != nil {
+		opts = &audio.PlayOptions{
+			CacheKey: req.CacheKey,
+			Cache:    cache,
+		}
+	}
+	err := m.player.Play(ctx, req.URL, opts)
+	return time.Since(started), err
+}
+
+// setStreamTitle 记录直播流推送的节目/歌曲名，会话已被取代时忽略。
+func (m *PlaybackManager) setStreamTitle(session *playSession, title string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.session != session {
+		return
+	}
+	if title != "" {
+		logger.Infof("[pipeline] 电台正在播放: %s", title)
+	}
+	m.streamTitle = title
+}
+
+// nextRequest 从播放列表取出下一首。
+func (m *PlaybackManager) nextRequest(ctx context.Context) (PlayRequest, bool) {
+	m.mu.Lock()
+	playlist := m.playlist
+	m.mu.Unlock()
+	if playlist == nil || !playlist.HasNext() {
+		return PlayRequest{}, false
+	}
+	url, song, artist, cacheKey, ok := playlist.Next(ctx)
+	if !ok {
+		return PlayRequest{}, false
+	}
+	return PlayRequest{URL: url, CacheKey: cacheKey, Song: song, Artist: artist}, true
+}
+
+// commitCache 歌曲从网络下载播放完成后，把缓存文件登记到缓存索引。
+func (m *PlaybackManager) commitCache(cacheKey string) {
+	m.mu.Lock()
+	cache, playlist := m.cache, m.playlist
+	m.mu.Unlock()
+	if cacheKey == "" || cache == nil || !cache.Enabled() || playlist == nil {
+		return
+	}
+	// 检查缓存文件是否存在（下载完成后会 commit）
+	if _, err := os.Stat(cache.FilePath(cacheKey)); err != nil {
+		return
+	}
+	item := playlist.Current()
+	if item == nil {
+		return
+	}
+	provider := cacheKey
+	if i := strings.Index(cacheKey, "_"); i >= 0 {
+		provider = cacheKey[:i]
+	}
+	cache.Store(cacheKey, audio.CacheEntry{
+		ID:       item.Song.ID,
+		Name:     item.Song.Name,
+		Artist:   item.Song.Artist,
+		Album:    item.Song.Album,
+		Provider: provider,
+	})
+}
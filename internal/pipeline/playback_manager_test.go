@@ -0,0 +1,241 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// fakePlayer 模拟播放器：Play 一直阻塞，直到 finish 指定结果或被 Stop。
+type fakePlayer struct {
+	mu      sync.Mutex
+	urls    []string
+	opts    *audio.PlayOptions // 最近一次 Play 的选项
+	stop    chan struct{}
+	results chan error
+}
+
+func newFakePlayer() *fakePlayer {
+	return &fakePlayer{stop: make(chan struct{}, 1), results: make(chan error)}
+}
+
+func (f *fakePlayer) Play(ctx context.Context, url string, opts *audio.PlayOptions) error {
+	f.mu.Lock()
+	f.urls = append(f.urls, url)
+	f.opts = opts
+	f.mu.Unlock()
+	// 上一次播放可能因 ctx 取消而返回，丢弃它没有消费的 Stop
+	select {
+	case <-f.stop:
+	default:
+	}
+	select {
+	case err := <-f.results:
+		return err
+	case <-f.stop:
+		return context.Canceled
+	case <-ctx.Done():
+		return context.Canceled
+	}
+}
+
+func (f *fakePlayer) PlayFromPosition(ctx context.Context, filePath string, positionSec float64) (float64, error) {
+	return 0, f.Play(ctx, filePath, nil)
+}
+
+func (f *fakePlayer) Stop() {
+	select {
+	case f.stop <- struct{}{}:
+	default:
+	}
+}
+
+func (f *fakePlayer) SetGain(percent int) {}
+
+func (f *fakePlayer) played() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.urls...)
+}
+
+// recordEvents 收集播放事件。
+func recordEvents(m *PlaybackManager) chan PlaybackEvent {
+	ch := make(chan PlaybackEvent, 16)
+	m.OnEvent(func(ev PlaybackEvent) { ch <- ev })
+	return ch
+}
+
+func waitEvent(t *testing.T, ch chan PlaybackEvent, want PlaybackEventType) PlaybackEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		if ev.Type != want {
+			t.Fatalf("事件 = %v (%s)，want %v", ev.Type, ev.Song, want)
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("等待事件 %v 超时", want)
+	}
+	return PlaybackEvent{}
+}
+
+func TestPlaybackManager_AutoNext(t *testing.T) {
+	player := newFakePlayer()
+	m := NewPlaybackManager(player)
+	events := recordEvents(m)
+
+	playlist := music.NewPlaylist(nil, nil)
+	playlist.ReplaceWithIndex([]music.PlaylistItem{
+		{Song: music.Song{Name: "一"}, URL: "u1"},
+		{Song: music.Song{Name: "二"}, URL: "u2"},
+	}, 0)
+	m.Attach(playlist, music.NewPausedMusicStore(), nil)
+
+	m.Play(context.Background(), PlayRequest{URL: "u1", Song: "一"})
+	waitEvent(t, events, PlaybackStarted)
+	if !m.Playing() {
+		t.Error("应处于播放状态")
+	}
+
+	player.results <- nil // 第一首播完
+	if ev := waitEvent(t, events, PlaybackStarted); ev.Song != "二" {
+		t.Errorf("应自动播放下一首, got %q", ev.Song)
+	}
+	if s := m.Status(); s.Song != "二" || s.Index != 2 || s.Total != 2 {
+		t.Errorf("status = %+v", s)
+	}
+
+	player.results <- nil // 第二首播完
+	waitEvent(t, events, PlaybackFinished)
+	if m.Playing() {
+		t.Error("列表播完后不应处于播放状态")
+	}
+	if got := player.played(); len(got) != 2 || got[1] != "u2" {
+		t.Errorf("played = %v", got)
+	}
+}
+
+func TestPlaybackManager_PauseResume(t *testing.T) {
+	player := newFakePlayer()
+	m := NewPlaybackManager(player)
+	events := recordEvents(m)
+
+	playlist := music.NewPlaylist(nil, nil)
+	playlist.ReplaceWithIndex([]music.PlaylistItem{{Song: music.Song{Name: "一"}, URL: "u1"}}, 0)
+	paused := music.NewPausedMusicStore()
+	m.Attach(playlist, paused, nil)
+
+	if err := m.Resume(context.Background()); err == nil {
+		t.Error("没有暂停的音乐时 Resume 应返回错误")
+	}
+
+	m.Play(context.Background(), PlayRequest{URL: "u1", Song: "一"})
+	waitEvent(t, events, PlaybackStarted)
+	if !m.Pause() {
+		t.Error("播放中 Pause 应返回 true")
+	}
+	if ev := waitEvent(t, events, PlaybackStopped); ev.Err != nil {
+		t.Errorf("暂停不应算出错: %v", ev.Err)
+	}
+	if !paused.HasPaused() {
+		t.Fatal("暂停应保存播放状态")
+	}
+
+	if err := m.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if ev := waitEvent(t, events, PlaybackStarted); ev.Song != "一" {
+		t.Errorf("恢复的歌曲 = %q", ev.Song)
+	}
+	m.Stop()
+	waitEvent(t, events, PlaybackStopped)
+	if m.Pause() {
+		t.Error("没有播放时 Pause 应返回 false")
+	}
+}
+
+func TestPlaybackManager_ReplaceAndError(t *testing.T) {
+	player := newFakePlayer()
+	m := NewPlaybackManager(player)
+	events := recordEvents(m)
+
+	m.Play(context.Background(), PlayRequest{URL: "u1", Song: "一"})
+	waitEvent(t, events, PlaybackStarted)
+
+	// 播放中切歌：旧的播放不发出停止事件
+	m.Play(context.Background(), PlayRequest{URL: "u2", Song: "二"})
+	if ev := waitEvent(t, events, PlaybackStarted); ev.Song != "二" {
+		t.Errorf("got %q", ev.Song)
+	}
+
+	select {
+	case player.results <- errors.New("网络错误"):
+	case <-time.After(time.Second):
+		t.Fatal("播放器没有在播放")
+	}
+	if ev := waitEvent(t, events, PlaybackStopped); ev.Err == nil {
+		t.Error("播放出错应带上错误")
+	}
+	if err := m.Seek(context.Background(), 30); err == nil {
+		t.Error("没有播放时 Seek 应返回错误")
+	}
+}
+
+func TestPlaybackManager_LiveStream(t *testing.T) {
+	player := newFakePlayer()
+	m := NewPlaybackManager(player)
+	events := recordEvents(m)
+
+	playlist := music.NewPlaylist(nil, nil)
+	playlist.ReplaceWithIndex([]music.PlaylistItem{
+		{Song: music.Song{Name: "一"}, URL: "u1"},
+		{Song: music.Song{Name: "二"}, URL: "u2"},
+	}, 0)
+	paused := music.NewPausedMusicStore()
+	m.Attach(playlist, paused, nil)
+
+	m.Play(context.Background(), PlayRequest{URL: "radio", Song: "中国之声", Live: true})
+	waitEvent(t, events, PlaybackStarted)
+	var opts *audio.PlayOptions
+	for deadline := time.Now().Add(time.Second); opts == nil && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		player.mu.Lock()
+		opts = player.opts
+		player.mu.Unlock()
+	}
+	if opts == nil || !opts.Live || opts.OnMetadata == nil {
+		t.Fatalf("直播流应以 Live 方式播放: %+v", opts)
+	}
+	opts.OnMetadata("新闻和报纸摘要")
+	if s := m.Status(); !s.Live || s.StreamTitle != "新闻和报纸摘要" || s.Total != 0 {
+		t.Errorf("status = %+v", s)
+	}
+
+	// 暂停电台不保存播放列表，恢复时重新连接电台
+	m.Pause()
+	waitEvent(t, events, PlaybackStopped)
+	if paused.HasPaused() {
+		t.Error("暂停电台不应保存播放列表")
+	}
+	if last := m.LastPlayed(); last.Song != "中国之声" || last.StreamTitle != "新闻和报纸摘要" {
+		t.Errorf("LastPlayed = %+v", last)
+	}
+	if !m.ResumeLive(context.Background()) {
+		t.Fatal("应能恢复电台")
+	}
+	waitEvent(t, events, PlaybackStarted)
+
+	// 电台停止推流：结束播放，不接着放列表里的歌
+	player.results <- nil
+	waitEvent(t, events, PlaybackFinished)
+	if got := player.played(); len(got) != 2 || got[1] != "radio" {
+		t.Errorf("played = %v", got)
+	}
+	if m.ResumeLive(context.Background()) {
+		t.Error("电台结束后不应再恢复")
+	}
+}
@@ -0,0 +1,41 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewPlaybackRequest(t *testing.T) {
+	result := `{"success":true,"song_name":"晴天","artist":"周杰伦","url":"http://example.com/a.mp3","playlist_size":3}`
+	req := newPlaybackRequest("play_music", result)
+	if req == nil {
+		t.Fatal("可播放的结果应返回播放请求")
+	}
+	if req.music.URL != "http://example.com/a.mp3" {
+		t.Errorf("URL = %q", req.music.URL)
+	}
+
+	// 交给大模型的结果不带播放地址
+	out := req.toolResult()
+	if strings.Contains(out, "example.com") {
+		t.Errorf("工具结果不应包含播放地址: %s", out)
+	}
+	var r struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(out), &r); err != nil || !r.Success || !strings.Contains(r.Message, "晴天") {
+		t.Errorf("toolResult = %s", out)
+	}
+
+	if newPlaybackRequest("play_music", `{"success":false,"error":"没找到"}`) != nil {
+		t.Error("失败的结果不应播放")
+	}
+	if newPlaybackRequest("play_music", `{"success":true,"message":"没有可播放的歌曲"}`) != nil {
+		t.Error("没有播放地址时不应播放")
+	}
+	if newPlaybackRequest("get_weather", result) != nil {
+		t.Error("非音乐工具不应播放")
+	}
+}
@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// prefetchRule 可预取的工具：问题中含关键词时，在等待大模型的同时先按最可能的参数调用工具。
+// 大模型随后发起参数相同的调用时直接使用预取结果，省掉一次工具调用的等待；用不上则丢弃。
+type prefetchRule struct {
+	tool     string
+	keywords []string
+	args     string // 预取时使用的参数（JSON），与工具描述中"没说城市时传 这里"等约定一致
+}
+
+// prefetchRules 内置的预取规则，实际启用哪些由 dialog.prefetch_tools 白名单决定。
+var prefetchRules = []prefetchRule{
+	{
+		tool:     "get_weather",
+		keywords: []string{"天气", "下雨", "下雪", "气温", "温度", "冷不冷", "热不热", "带伞", "穿什么"},
+		args:     `{"city":"这里"}`,
+	},
+	{
+		tool:     "get_air_quality",
+		keywords: []string{"空气", "雾霾", "pm2.5", "aqi"},
+		args:     `{"city":"这里"}`,
+	},
+	{
+		tool:     "get_news",
+		keywords: []string{"新闻", "头条", "热点", "大事"},
+		args:     `{}`,
+	},
+}
+
+// matchPrefetchRules 返回问题命中且在白名单中的预取规则。
+func matchPrefetchRules(query string, allow []string) []prefetchRule {
+	if len(allow) == 0 {
+		return nil
+	}
+	lower := strings.ToLower(query)
+	var matched []prefetchRule
+	for _, rule := range prefetchRules {
+		if !containsString(allow, rule.tool) {
+			continue
+		}
+		for _, kw := range rule.keywords {
+			if strings.Contains(lower, kw) {
+				matched = append(matched, rule)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeToolArgs 把工具参数解析为 map 并去掉零值字段，
+// 使 {"city":"这里"} 与 {"city":"这里","refresh":false} 视为相同参数。
+func normalizeToolArgs(args string) (map[string]interface{}, bool) {
+	m := map[string]interface{}{}
+	if strings.TrimSpace(args) != "" {
+		if err := json.Unmarshal([]byte(args), &m); err != nil {
+			return nil, false
+		}
+	}
+	for k, v := range m {
+		switch v {
+		case nil, "", false, float64(0):
+			delete(m, k)
+		}
+	}
+	return m, true
+}
+
+// prefetchCall 一次预取的工具调用。
+type prefetchCall struct {
+	tool   string
+	args   map[string]interface{}
+	done   chan struct{}
+	used   bool
+	result string
+	err    error
+}
+
+// toolPrefetch 一次对话中预取的工具调用。
+type toolPrefetch struct {
+	calls []*prefetchCall
+}
+
+// startPrefetch 问题明显需要某个工具时，与第一轮大模型调用并行地预先执行该工具。
+// ctx 结束（本次对话结束或被打断）时未完成的预取随之取消。没有可预取的工具时返回 nil。
+func (p *Pipeline) startPrefetch(ctx context.Context, query string) *toolPrefetch {
+	rules := matchPrefetchRules(query, p.cfg.Dialog.PrefetchTools)
+	if len(rules) == 0 {
+		return nil
+	}
+	f := &toolPrefetch{}
+	for _, rule := range rules {
+		args, ok := normalizeToolArgs(rule.args)
+		if !ok {
+			continue
+		}
+		call := &prefetchCall{tool: rule.tool, args: args, done: make(chan struct{})}
+		f.calls = append(f.calls, call)
+		logger.Debugf("[pipeline] 预取工具: %s(%s)", rule.tool, rule.args)
+		go func(args string) {
+			defer close(call.done)
+			call.result, call.err = p.toolRegistry.Execute(ctx, call.tool, json.RawMessage(args))
+		}(rule.args)
+	}
+	return f
+}
+
+// take 大模型发起的调用与某个预取完全相同时，等待并返回预取结果（每个预取只使用一次）。
+func (f *toolPrefetch) take(ctx context.Context, tool, args string) (*prefetchCall, bool) {
+	if f == nil {
+		return nil, false
+	}
+	want, ok := normalizeToolArgs(args)
+	if !ok {
+		return nil, false
+	}
+	for _, call := range f.calls {
+		if call.used || call.tool != tool || !reflect.DeepEqual(call.args, want) {
+			continue
+		}
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, false
+		}
+		// 预取被取消或失败时按正常流程重新调用
+		if call.err != nil {
+			return nil, false
+		}
+		call.used = true
+		logger.Infof("[pipeline] 使用预取的工具结果: %s", tool)
+		return call, true
+	}
+	if len(f.calls) > 0 {
+		logger.Debugf("[pipeline] 工具调用 %s(%s) 与预取不符，正常执行", tool, args)
+	}
+	return nil, false
+}
+
+// runTool 执行大模型发起的工具调用，与预取相同时直接使用预取结果。
+// ctx 应为本次对话的 queryCtx，被打断时正在执行的工具随之取消。
+func (p *Pipeline) runTool(ctx context.Context, prefetch *toolPrefetch, name, args string) (string, error) {
+	if call, ok := prefetch.take(ctx, name, args); ok {
+		return call.result, nil
+	}
+	start := time.Now()
+	result, err := p.toolRegistry.Execute(ctx, name, json.RawMessage(args))
+	p.latency.addTool(time.Since(start))
+	return result, err
+}
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+type countingTool struct {
+	calls atomic.Int32
+}
+
+func (t *countingTool) Name() string                { return "get_weather" }
+func (t *countingTool) Description() string         { return "" }
+func (t *countingTool) Parameters() json.RawMessage { return json.RawMessage(`{}`) }
+func (t *countingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	t.calls.Add(1)
+	return `{"city":"深圳","temp":25}`, nil
+}
+
+// blockingTool 一直阻塞到 ctx 取消。
+type blockingTool struct {
+	started chan struct{}
+}
+
+func (t *blockingTool) Name() string                { return "blocking" }
+func (t *blockingTool) Description() string         { return "" }
+func (t *blockingTool) Parameters() json.RawMessage { return json.RawMessage(`{}`) }
+func (t *blockingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	close(t.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestRunTool_InterruptCancelsTool(t *testing.T) {
+	tool := &blockingTool{started: make(chan struct{})}
+	reg := tools.NewRegistry()
+	reg.Register(tool)
+	p := &Pipeline{cfg: &config.Config{}, toolRegistry: reg}
+
+	queryCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p.queryMu.Lock()
+	p.cancelQuery = cancel
+	p.queryMu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.runTool(queryCtx, nil, "blocking", `{}`)
+		done <- err
+	}()
+
+	<-tool.started
+	p.cancelRunningQuery()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("被打断的工具调用应返回错误")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("打断后工具调用应立即结束")
+	}
+}
+
+func TestMatchPrefetchRules(t *testing.T) {
+	allow := []string{"get_weather"}
+	if got := matchPrefetchRules("今天天气怎么样", allow); len(got) != 1 || got[0].tool != "get_weather" {
+		t.Errorf("天气问题应命中 get_weather, got %v", got)
+	}
+	if got := matchPrefetchRules("今天空气怎么样", allow); len(got) != 0 {
+		t.Errorf("不在白名单中的工具不应预取, got %v", got)
+	}
+	if got := matchPrefetchRules("今天天气怎么样", nil); len(got) != 0 {
+		t.Errorf("白名单为空时不应预取, got %v", got)
+	}
+	if got := matchPrefetchRules("讲个笑话", allow); len(got) != 0 {
+		t.Errorf("无关问题不应预取, got %v", got)
+	}
+}
+
+func TestToolPrefetch_Take(t *testing.T) {
+	tool := &countingTool{}
+	reg := tools.NewRegistry()
+	reg.Register(tool)
+	p := &Pipeline{
+		cfg:          &config.Config{Dialog: config.DialogConfig{PrefetchTools: []string{"get_weather"}}},
+		toolRegistry: reg,
+	}
+
+	ctx := context.Background()
+	f := p.startPrefetch(ctx, "明天天气怎么样")
+	if f == nil {
+		t.Fatal("应启动预取")
+	}
+
+	if _, ok := f.take(ctx, "get_weather", `{"city":"北京"}`); ok {
+		t.Error("参数不同时不应使用预取结果")
+	}
+	call, ok := f.take(ctx, "get_weather", `{"city":"这里","refresh":false}`)
+	if !ok || call.result != `{"city":"深圳","temp":25}` {
+		t.Fatalf("参数相同时应使用预取结果, ok=%v", ok)
+	}
+	if _, ok := f.take(ctx, "get_weather", `{"city":"这里"}`); ok {
+		t.Error("预取结果只能使用一次")
+	}
+	if n := tool.calls.Load(); n != 1 {
+		t.Errorf("工具调用次数 = %d, want 1", n)
+	}
+
+	var none *toolPrefetch
+	if _, ok := none.take(ctx, "get_weather", `{}`); ok {
+		t.Error("nil 预取不应命中")
+	}
+}
@@ -0,0 +1,184 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 家人在家检测（presence）：定时查询 HA 的 person/device_tracker 实体或 ping 家人手机。
+// 没人在家时跳过主动播报（到期提醒除外），没人在家时有人回来就打招呼，在家情况注入大模型上下文。
+// 检测结果只在 presence 定时任务中写入，播报时读取，需要加锁。
+
+// presencePingTimeout 单次 ping 的超时时间。
+const presencePingTimeout = 3 * time.Second
+
+// memberPresence 一位家人的在家状态。
+type memberPresence struct {
+	name     string
+	debounce bool // ping 检测：手机休眠时会暂时断开 Wi-Fi，不通超过 awayAfter 才算离家
+	known    bool // 启动后是否已检测到过
+	home     bool
+	since    time.Time // 最近一次状态变化的时间
+	lastSeen time.Time // 最近一次检测到在家的时间
+}
+
+// presenceTracker 记录家人的在家状态。
+type presenceTracker struct {
+	mu        sync.Mutex
+	awayAfter time.Duration
+	members   []*memberPresence
+}
+
+func newPresenceTracker(awayAfter time.Duration) *presenceTracker {
+	return &presenceTracker{awayAfter: awayAfter}
+}
+
+// add 添加一位家人，debounce 为 true 时离家判定需要持续 awayAfter。
+func (t *presenceTracker) add(name string, debounce bool) {
+	t.members = append(t.members, &memberPresence{name: name, debounce: debounce})
+}
+
+// update 记录一次检测结果（name → 是否检测到在家），检测失败的成员不在 seen 中，保持原状态。
+// 返回从"没人在家"变为有人在家时回来的家人；启动后第一次检测不算回家。
+func (t *presenceTracker) update(seen map[string]bool, now time.Time) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	wasEmpty := t.emptyLocked()
+	var arrived []string
+	for _, m := range t.members {
+		ok, checked := seen[m.name]
+		if !checked {
+			continue
+		}
+		if ok {
+			m.lastSeen = now
+		}
+		home := ok
+		if !ok && m.debounce && m.home && now.Sub(m.lastSeen) < t.awayAfter {
+			home = true
+		}
+		if !m.known {
+			m.known, m.home = true, home
+			continue
+		}
+		if home == m.home {
+			continue
+		}
+		m.home, m.since = home, now
+		if home {
+			logger.Infof("[pipeline] %s 回家了", m.name)
+			arrived = append(arrived, m.name)
+		} else {
+			logger.Infof("[pipeline] %s 出门了", m.name)
+		}
+	}
+	if !wasEmpty {
+		return nil
+	}
+	return arrived
+}
+
+// empty 是否确定没人在家。还有家人没检测到过时不算没人。
+func (t *presenceTracker) empty() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.emptyLocked()
+}
+
+func (t *presenceTracker) emptyLocked() bool {
+	if len(t.members) == 0 {
+		return false
+	}
+	for _, m := range t.members {
+		if !m.known || m.home {
+			return false
+		}
+	}
+	return true
+}
+
+// snapshot 返回已检测到的家人的在家情况。
+func (t *presenceTracker) snapshot() []llm.MemberPresence {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var members []llm.MemberPresence
+	for _, m := range t.members {
+		if m.known {
+			members = append(members, llm.MemberPresence{Name: m.name, Home: m.home, Since: m.since})
+		}
+	}
+	return members
+}
+
+// initPresence 根据配置创建在家检测，没有配置家人时不启用。
+func (p *Pipeline) initPresence() {
+	cfg := p.cfg.Presence
+	if len(cfg.Members) == 0 {
+		return
+	}
+	tracker := newPresenceTracker(time.Duration(cfg.AwayAfter) * time.Second)
+	for _, m := range cfg.Members {
+		switch {
+		case m.Entity != "" && p.haClient == nil:
+			logger.Warnf("[pipeline] 在家检测: %s 使用 HA 实体，但 Home Assistant 未启用，已跳过", m.Name)
+		case m.Entity != "" || m.Host != "":
+			tracker.add(m.Name, m.Entity == "")
+		default:
+			logger.Warnf("[pipeline] 在家检测: %s 没有配置 entity 或 host，已跳过", m.Name)
+		}
+	}
+	if len(tracker.members) == 0 {
+		return
+	}
+	p.presence = tracker
+	logger.Infof("[pipeline] 在家检测已启用，%d 位家人", len(tracker.members))
+}
+
+// checkPresence 检测家人是否在家，有人回到空无一人的家时打招呼。
+func (p *Pipeline) checkPresence(ctx context.Context) {
+	seen := make(map[string]bool, len(p.cfg.Presence.Members))
+	for _, m := range p.cfg.Presence.Members {
+		switch {
+		case m.Entity != "" && p.haClient != nil:
+			state, err := p.haClient.GetState(ctx, m.Entity)
+			if err != nil {
+				logger.Debugf("[pipeline] 读取 %s 的在家状态失败: %v", m.Name, err)
+				continue
+			}
+			seen[m.Name] = state.State == "home" || state.State == "on"
+		case m.Entity == "" && m.Host != "":
+			seen[m.Name] = pingHost(ctx, m.Host)
+		}
+	}
+
+	arrived := p.presence.update(seen, time.Now())
+	p.contextManager.SetPresence(p.presence.snapshot())
+	if len(arrived) == 0 || p.cfg.Presence.Greeting == "-" {
+		return
+	}
+	greeting := p.cfg.Presence.Greeting
+	if strings.Contains(greeting, "%s") {
+		greeting = fmt.Sprintf(greeting, strings.Join(arrived, "、"))
+	}
+	p.announce(ctx, speechNormal, greeting)
+}
+
+// nobodyHome 在家检测确定没人在家时返回 true，未启用或还不确定时返回 false。
+func (p *Pipeline) nobodyHome() bool {
+	return p.presence != nil && p.presence.empty()
+}
+
+// pingHost 判断主机是否 ping 得通。
+func pingHost(ctx context.Context, host string) bool {
+	ctx, cancel := context.WithTimeout(ctx, presencePingTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "ping", "-c", "1", host).Run() == nil
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	tracker := newPresenceTracker(10 * time.Minute)
+	tracker.add("妈妈", false)
+	tracker.add("爸爸", true) // ping 手机
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.Local)
+
+	// 启动后第一次检测不算回家
+	if arrived := tracker.update(map[string]bool{"妈妈": true, "爸爸": true}, now); len(arrived) != 0 {
+		t.Errorf("first check should not greet: %v", arrived)
+	}
+
+	// 手机暂时 ping 不通，不到 awayAfter 仍算在家
+	now = now.Add(5 * time.Minute)
+	tracker.update(map[string]bool{"妈妈": false, "爸爸": false}, now)
+	if tracker.empty() {
+		t.Fatal("dad's phone dozing should not mark the house empty")
+	}
+	now = now.Add(6 * time.Minute)
+	tracker.update(map[string]bool{"妈妈": false, "爸爸": false}, now)
+	if !tracker.empty() {
+		t.Fatal("house should be empty once dad's phone is gone for awayAfter")
+	}
+
+	// 检测失败时保持原状态
+	tracker.update(map[string]bool{}, now.Add(time.Minute))
+	if !tracker.empty() {
+		t.Error("failed checks should keep the previous state")
+	}
+
+	now = now.Add(8 * time.Hour)
+	arrived := tracker.update(map[string]bool{"妈妈": true, "爸爸": false}, now)
+	if len(arrived) != 1 || arrived[0] != "妈妈" {
+		t.Errorf("arrived = %v, want [妈妈]", arrived)
+	}
+	// 已经有人在家，第二个人回来不再打招呼
+	if arrived := tracker.update(map[string]bool{"妈妈": true, "爸爸": true}, now.Add(time.Minute)); len(arrived) != 0 {
+		t.Errorf("second arrival should not greet: %v", arrived)
+	}
+
+	members := tracker.snapshot()
+	if len(members) != 2 || !members[0].Home || !members[0].Since.Equal(now) {
+		t.Errorf("snapshot = %+v", members)
+	}
+}
+
+func TestAnnounce_NobodyHome(t *testing.T) {
+	p := &Pipeline{presence: newPresenceTracker(0)}
+	p.presence.add("妈妈", false)
+	p.presence.update(map[string]bool{"妈妈": false}, time.Now())
+
+	spoken := false
+	speak := func(context.Context) { spoken = true }
+	if p.announceWith(context.Background(), "整点报时", speechNormal, speak) || spoken {
+		t.Error("routine announcements should be skipped when nobody is home")
+	}
+	if !p.announceWith(context.Background(), "闹钟", speechUrgent, speak) || !spoken {
+		t.Error("due reminders should still be announced")
+	}
+}
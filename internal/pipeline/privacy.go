@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 隐私过滤（privacy）：发给云端大模型的消息先按规则脱敏，对话历史在本地保留原文；
+// 结果不能离开设备的工具（local_only_tools 或实现了 tools.LocalOnlyTool）不经过大模型，直接朗读。
+
+// initPrivacy 启用隐私过滤时用脱敏层包装云端大模型，需要在工具注册之后调用。
+func (p *Pipeline) initPrivacy() error {
+	cfg := p.cfg.Privacy
+	if !cfg.Enabled {
+		return nil
+	}
+	rules, err := privacyRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	p.localOnlyTools = make(map[string]bool)
+	for _, name := range append(p.toolRegistry.LocalOnly(), cfg.LocalOnlyTools...) {
+		p.localOnlyTools[name] = true
+	}
+	localOnly := make([]string, 0, len(p.localOnlyTools))
+	for name := range p.localOnlyTools {
+		localOnly = append(localOnly, name)
+	}
+	p.llmProvider = llm.NewRedactingProvider(p.llmProvider, llm.NewRedactor(rules), localOnly)
+	logger.Infof("[pipeline] 隐私过滤已启用: %d 条脱敏规则，%d 个仅限本地的工具", len(rules), len(localOnly))
+	return nil
+}
+
+// privacyRules 把配置转换为脱敏规则：先应用自定义规则（如门锁密码），再应用内置规则。
+func privacyRules(cfg config.PrivacyConfig) ([]llm.RedactRule, error) {
+	var rules []llm.RedactRule
+	for _, r := range cfg.Rules {
+		name := r.Name
+		if name == "" {
+			name = "敏感信息"
+		}
+		replace := r.Replace
+		if replace == "" {
+			replace = "[" + name + "]"
+		}
+		var pattern *regexp.Regexp
+		switch {
+		case r.Regex != "":
+			var err error
+			if pattern, err = regexp.Compile(r.Regex); err != nil {
+				return nil, fmt.Errorf("隐私规则 %s 的正则无效: %w", name, err)
+			}
+		case r.Text != "":
+			pattern = regexp.MustCompile(regexp.QuoteMeta(r.Text))
+		default:
+			return nil, fmt.Errorf("隐私规则 %s 需要配置 regex 或 text", name)
+		}
+		rules = append(rules, llm.RedactRule{Name: name, Pattern: pattern, Replace: replace})
+	}
+	for _, name := range cfg.Builtin {
+		rule, ok := llm.BuiltinRedactRule(name)
+		if !ok {
+			return nil, fmt.Errorf("未知的内置隐私规则 %s，可选: %s", name, strings.Join(llm.BuiltinRedactRuleNames(), "、"))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// localOnlyReply 工具结果仅限本地时，返回直接朗读的文字。
+func (p *Pipeline) localOnlyReply(tool, result string) (string, bool) {
+	if !p.localOnlyTools[tool] {
+		return "", false
+	}
+	if t, ok := p.toolRegistry.Get(tool); ok {
+		if lo, ok := t.(tools.LocalOnlyTool); ok {
+			return lo.LocalReply(result), true
+		}
+	}
+	// 配置指定的工具：取结果中的 message，否则原样朗读
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal([]byte(result), &parsed) == nil && parsed.Message != "" {
+		return parsed.Message, true
+	}
+	return result, true
+}
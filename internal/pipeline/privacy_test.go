@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+func TestPrivacyRules(t *testing.T) {
+	rules, err := privacyRules(config.PrivacyConfig{
+		Builtin: []string{"phone"},
+		Rules: []config.PrivacyRule{
+			{Name: "家庭住址", Text: "幸福小区3栋2单元"},
+			{Name: "门锁密码", Regex: `密码[是为：:]?\s*\d{4,8}`, Replace: "[密码]"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 || rules[0].Replace != "[家庭住址]" || rules[2].Name != "手机号" {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	if _, err := privacyRules(config.PrivacyConfig{Builtin: []string{"address"}}); err == nil || !strings.Contains(err.Error(), "phone") {
+		t.Errorf("unknown builtin should list the options: %v", err)
+	}
+	if _, err := privacyRules(config.PrivacyConfig{Rules: []config.PrivacyRule{{Name: "坏规则", Regex: "("}}}); err == nil {
+		t.Error("invalid regex should fail")
+	}
+}
+
+func TestLocalOnlyReply(t *testing.T) {
+	p := &Pipeline{
+		toolRegistry:   tools.NewRegistry(),
+		localOnlyTools: map[string]bool{"get_guest_wifi": true, "ezviz_lock_status": true},
+	}
+	p.toolRegistry.Register(tools.NewGuestWiFiTool(tools.GuestWiFiConfig{SSID: "Guest", Password: "ab1"}, ""))
+
+	if text, ok := p.localOnlyReply("get_guest_wifi", `{"password":"ab1"}`); !ok || !strings.Contains(text, "小写A，小写B，1") {
+		t.Errorf("wifi reply = %q, %v", text, ok)
+	}
+	if text, ok := p.localOnlyReply("ezviz_lock_status", `{"success":true,"message":"门已反锁"}`); !ok || text != "门已反锁" {
+		t.Errorf("configured tool reply = %q, %v", text, ok)
+	}
+	if _, ok := p.localOnlyReply("get_weather", "晴"); ok {
+		t.Error("regular tools should go to the model")
+	}
+}
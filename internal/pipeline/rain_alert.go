@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 出门前提醒：早上门磁打开时查一下短时降水，会下雨就提醒带伞，每天最多提醒一次。
+// 状态只在 rain_alert 定时任务中读写。
+
+// checkDoorForRain 轮询门磁状态，检测到开门时检查是否要下雨。
+func (p *Pipeline) checkDoorForRain(ctx context.Context) {
+	ra := p.cfg.Tools.HomeAssistant.RainAlert
+	state, err := p.haClient.GetState(ctx, ra.DoorEntity)
+	if err != nil {
+		logger.Debugf("[pipeline] 读取门磁 %s 失败: %v", ra.DoorEntity, err)
+		return
+	}
+	opened := doorOpened(p.rainAlertDoor, state.State)
+	p.rainAlertDoor = state.State
+	if !opened {
+		return
+	}
+
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	if !inClockWindow(now, ra.Start, ra.End) || p.rainAlertDate == today {
+		return
+	}
+	forecast, err := p.weatherTool.RainWithin(ctx, ra.City, time.Duration(ra.Within)*time.Minute)
+	if err != nil {
+		logger.Warnf("[pipeline] 出门前提醒查询降水失败: %v", err)
+		return
+	}
+	if !forecast.Rain {
+		logger.Debugf("[pipeline] 检测到开门，%d 分钟内%s不会下雨", ra.Within, forecast.City)
+		return
+	}
+	p.rainAlertDate = today
+	logger.Infof("[pipeline] 检测到开门，%s %d 分钟后有降水，提醒带伞", forecast.City, forecast.Minutes)
+	p.announce(ctx, speechNormal, ra.Message)
+}
+
+// doorOpened 门磁从关闭变为打开（HA 中门磁为 on/open）。启动后的第一次读取不算开门。
+func doorOpened(prev, cur string) bool {
+	isOpen := func(s string) bool { return s == "on" || s == "open" }
+	return prev != "" && !isOpen(prev) && isOpen(cur)
+}
+
+// inClockWindow 判断时间是否在 start~end（"HH:MM"）之间。
+func inClockWindow(t time.Time, start, end string) bool {
+	clock := t.Format("15:04")
+	return clock >= start && clock < end
+}
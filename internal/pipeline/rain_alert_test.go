@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoorOpened(t *testing.T) {
+	tests := []struct {
+		prev, cur string
+		want      bool
+	}{
+		{"off", "on", true},
+		{"closed", "open", true},
+		{"", "on", false}, // 启动后第一次读取
+		{"on", "on", false},
+		{"on", "off", false},
+		{"unavailable", "off", false},
+	}
+	for _, tt := range tests {
+		if got := doorOpened(tt.prev, tt.cur); got != tt.want {
+			t.Errorf("doorOpened(%q, %q) = %v, want %v", tt.prev, tt.cur, got, tt.want)
+		}
+	}
+}
+
+func TestInClockWindow(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{5*time.Hour + 59*time.Minute, false},
+		{6 * time.Hour, true},
+		{8*time.Hour + 30*time.Minute, true},
+		{10 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := inClockWindow(day.Add(tt.at), "06:00", "10:00"); got != tt.want {
+			t.Errorf("inClockWindow(%s) = %v, want %v", day.Add(tt.at).Format("15:04"), got, tt.want)
+		}
+	}
+}
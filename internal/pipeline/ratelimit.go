@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
+)
+
+// qqWebHosts QQ 音乐内置客户端直连的网页接口。
+var qqWebHosts = []string{"u.y.qq.com", "c.y.qq.com"}
+
+// configureRateLimit 按配置设置所有外部 API 客户端共享的限流器。
+// 音乐 API（非官方接口，最容易被封）使用单独的预算，hosts 中的配置优先。
+func configureRateLimit(cfg *config.Config) {
+	rl := cfg.RateLimit
+	hosts := make(map[string]ratelimit.Budget)
+
+	musicURLs := []string{cfg.Tools.Music.APIURL, cfg.Tools.Music.Netease.APIURL, cfg.Tools.Music.QQ.APIURL,
+		"http://localhost:3000", "http://localhost:3300"}
+	for _, raw := range musicURLs {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			hosts[u.Host] = ratelimit.Budget(rl.Music)
+		}
+	}
+	if cfg.Tools.Music.QQ.Direct {
+		for _, h := range qqWebHosts {
+			hosts[h] = ratelimit.Budget(rl.Music)
+		}
+	}
+	for h, b := range rl.Hosts {
+		hosts[h] = ratelimit.Budget(b)
+	}
+
+	ratelimit.Configure(ratelimit.Config{
+		Default:     ratelimit.Budget(rl.Default),
+		Hosts:       hosts,
+		MaxRetries:  max(rl.MaxRetries, 0),
+		BackoffBase: time.Duration(rl.BackoffBase * float64(time.Second)),
+		BackoffMax:  time.Duration(rl.BackoffMax * float64(time.Second)),
+	})
+}
@@ -0,0 +1,213 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// reminderAckPhrases 用户确认收到提醒的说法，整句匹配（去掉标点和语气词后）。
+var reminderAckPhrases = map[string]bool{
+	"知道了": true, "知道": true, "收到": true, "好的": true, "好": true, "行": true, "嗯": true,
+	"关掉": true, "关了": true, "关掉吧": true, "别响了": true, "别说了": true, "停": true, "停止": true,
+	"起来了": true, "我起来了": true, "醒了": true, "我醒了": true, "马上": true, "马上去": true,
+}
+
+// reminderAckWindow 唤醒回应提醒后，在此时间内说"知道了"视为确认。
+const reminderAckWindow = 30 * time.Second
+
+// reminderSession 一次到期提醒：重复播报并逐渐调大音量，直到用户回应或达到最大次数。
+// 提醒期间又有提醒到期时合并到同一次播报中。
+type reminderSession struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	messages  []string
+	finishing bool // 最后一次播报已取走内容，之后到期的提醒不能再合并进来
+	volume    int  // 开始提醒前的音量，结束后恢复
+	hasVolume bool
+}
+
+// isReminderAck 判断识别文本是否为确认收到提醒，wakeWords 为句首可能出现的唤醒词。
+func isReminderAck(text string, wakeWords []string) bool {
+	isPunct := func(r rune) bool { return strings.ContainsRune("，。！？、,.!? ", r) }
+	text = strings.TrimFunc(trimWakeWord(strings.TrimFunc(text, isPunct), wakeWords), isPunct)
+	text = strings.TrimRight(text, "了吧啊呀哦啦")
+	return reminderAckPhrases[text] || reminderAckPhrases[text+"了"]
+}
+
+// reminderText 第 n 次（从 1 开始）播报的内容。
+func reminderText(messages []string, n int) string {
+	text := strings.Join(messages, "；")
+	if n > 1 {
+		text = "再提醒一次，" + text
+	}
+	return text
+}
+
+// announceReminder 播报到期提醒（倒计时、闹钟）。未回应时按配置重复播报并逐渐调大音量。
+func (p *Pipeline) announceReminder(message string) {
+	if session, started := p.joinReminder(message); started {
+		go p.runReminder(session.ctx, session)
+	}
+}
+
+// joinReminder 把到期提醒合并到正在进行的提醒中。没有提醒、提醒已被回应或已在做最后一次播报时
+// 开始新的提醒（started 为 true），否则这条提醒会随旧的提醒结束而丢失。
+func (p *Pipeline) joinReminder(message string) (session *reminderSession, started bool) {
+	p.reminderMu.Lock()
+	defer p.reminderMu.Unlock()
+	if s := p.reminder; s != nil && s.ctx.Err() == nil && !s.finishing {
+		s.messages = append(s.messages, message)
+		return s, false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	session = &reminderSession{ctx: ctx, cancel: cancel, messages: []string{message}}
+	p.reminder = session
+	return session, true
+}
+
+// runReminder 按间隔重复播报，直到被 ackReminder 取消或达到最大次数。
+func (p *Pipeline) runReminder(ctx context.Context, session *reminderSession) {
+	cfg := p.cfg.Tools.Timer
+	defer p.finishReminder(session)
+
+	for n := 1; n <= cfg.Repeat; n++ {
+		if n > 1 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(cfg.RepeatInterval) * time.Second):
+			}
+			p.raiseReminderVolume(session)
+		}
+
+		// 经播报队列播放：用户正在对话时等对话结束，与其他播报同时到期时优先播
+		var idle bool
+		p.announceAtVolume(ctx, "到期提醒", speechUrgent, p.reminderVolume(n), func(ctx context.Context) {
+			// 排队期间又有提醒到期时一起播报
+			p.reminderMu.Lock()
+			text := reminderText(session.messages, n)
+			session.finishing = n == cfg.Repeat
+			p.reminderMu.Unlock()
+			logger.Infof("[pipeline] 到期提醒（第 %d/%d 次）: %s", n, cfg.Repeat, text)
+
+			idle = p.state.Current() == StateIdle
+			p.speakText(ctx, text)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		// 播报后短暂监听，用户不用唤醒直接说"知道了"即可
+		if idle && n < cfg.Repeat && p.state.Current() == StateIdle {
+			p.enterContinuousMode()
+		}
+	}
+	logger.Info("[pipeline] 到期提醒无人回应，已达到最大播报次数")
+}
+
+// reminderVolume 第 n 次播报的临时音量：从 alarm_volume 开始每次提高 volume_step，不超过 max_volume。
+// 没有配置 alarm_volume 时返回 0，由 raiseReminderVolume 直接调大系统音量。
+func (p *Pipeline) reminderVolume(n int) int {
+	base := p.cfg.Tools.Volume.AlarmVolume
+	cfg := p.cfg.Tools.Timer
+	if base <= 0 || cfg.VolumeStep <= 0 || base >= cfg.MaxVolume {
+		return base
+	}
+	return min(base+(n-1)*cfg.VolumeStep, cfg.MaxVolume)
+}
+
+// raiseReminderVolume 重复播报时提高音量，首次调整前记下原音量。配置了 alarm_volume 时由 reminderVolume 处理。
+func (p *Pipeline) raiseReminderVolume(session *reminderSession) {
+	cfg := p.cfg.Tools.Timer
+	if p.volumeCtrl == nil || cfg.VolumeStep <= 0 || p.cfg.Tools.Volume.AlarmVolume > 0 {
+		return
+	}
+	vol, err := p.volumeCtrl.GetVolume()
+	if err != nil {
+		logger.Debugf("[pipeline] 获取音量失败: %v", err)
+		return
+	}
+	p.reminderMu.Lock()
+	if !session.hasVolume {
+		session.volume, session.hasVolume = vol, true
+	}
+	p.reminderMu.Unlock()
+
+	if vol >= cfg.MaxVolume {
+		return
+	}
+	vol += cfg.VolumeStep
+	if vol > cfg.MaxVolume {
+		vol = cfg.MaxVolume
+	}
+	if err := p.volumeCtrl.SetVolume(vol); err != nil {
+		logger.Debugf("[pipeline] 提高提醒音量失败: %v", err)
+	}
+}
+
+// finishReminder 结束提醒并恢复原音量。
+func (p *Pipeline) finishReminder(session *reminderSession) {
+	p.reminderMu.Lock()
+	if p.reminder == session {
+		p.reminder = nil
+	}
+	p.reminderMu.Unlock()
+
+	session.cancel()
+	if session.hasVolume {
+		if err := p.volumeCtrl.SetVolume(session.volume); err != nil {
+			logger.Warnf("[pipeline] 恢复音量失败: %v", err)
+		}
+	}
+}
+
+// ackReminder 用户有回应（唤醒或说话）时停止重复播报，返回是否有正在进行的提醒。
+func (p *Pipeline) ackReminder() bool {
+	p.reminderMu.Lock()
+	session := p.reminder
+	p.reminderMu.Unlock()
+	if session == nil {
+		return false
+	}
+	logger.Info("[pipeline] 用户已回应到期提醒，停止重复播报")
+	session.cancel()
+	p.reminderMu.Lock()
+	p.reminderAckedAt = time.Now()
+	p.reminderMu.Unlock()
+	return true
+}
+
+// takeReminderAck 用户是否刚回应过提醒（唤醒后的下一句"知道了"不交给大模型），读取后清除。
+func (p *Pipeline) takeReminderAck() bool {
+	p.reminderMu.Lock()
+	defer p.reminderMu.Unlock()
+	recent := !p.reminderAckedAt.IsZero() && time.Since(p.reminderAckedAt) < reminderAckWindow
+	p.reminderAckedAt = time.Time{}
+	return recent
+}
+
+// followUpWindow 跟进提醒播报后，在此时间内的提问会带上跟进的对话总结。
+const followUpWindow = 10 * time.Minute
+
+// setFollowUp 记下刚播报的跟进提醒，等用户接着说话时放入对话上下文。
+// 对话上下文只在处理提问时修改，这里不直接写入，避免与正在进行的对话并发修改。
+func (p *Pipeline) setFollowUp(text string) {
+	p.reminderMu.Lock()
+	defer p.reminderMu.Unlock()
+	p.followUp, p.followUpAt = text, time.Now()
+}
+
+// takeFollowUp 返回最近播报且尚未放入上下文的跟进提醒，读取后清除。
+func (p *Pipeline) takeFollowUp() string {
+	p.reminderMu.Lock()
+	defer p.reminderMu.Unlock()
+	text := p.followUp
+	recent := text != "" && time.Since(p.followUpAt) < followUpWindow
+	p.followUp = ""
+	if !recent {
+		return ""
+	}
+	return text
+}
@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// handleImport 批量导入闹钟、备忘和纪念日：POST /api/import?format=csv，请求体为导入文件，
+// format 为 yaml（默认）或 csv，格式见 tools.ImportBatch。
+func (p *Pipeline) handleImport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = tools.ImportYAML
+	}
+	batch, err := tools.ParseImport(io.LimitReader(r.Body, 1<<20), format)
+	if err != nil {
+		admin.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result := tools.ImportReminders(batch, p.alarmStore, p.memoStore, time.Now())
+	logger.Infof("[pipeline] 通过管理 API 批量导入: %s", result)
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": result.String(),
+		"result":  result,
+	})
+}
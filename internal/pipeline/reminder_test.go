@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+func TestIsReminderAck(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"知道了", true},
+		{"知道了。", true},
+		{"小派，关了吧", true},
+		{"好的", true},
+		{"我起来了", true},
+		{"别响了！", true},
+		{"再睡五分钟", false},
+		{"今天天气怎么样", false},
+		{"知道了帮我再定个十分钟的闹钟", false},
+	}
+	for _, tt := range tests {
+		if got := isReminderAck(tt.text, defaultWakeWords); got != tt.want {
+			t.Errorf("isReminderAck(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReminderText(t *testing.T) {
+	msgs := []string{"闹钟提醒: 起床", "倒计时结束了"}
+	if got := reminderText(msgs[:1], 1); got != "闹钟提醒: 起床" {
+		t.Errorf("第一次播报 = %q", got)
+	}
+	if got := reminderText(msgs, 2); got != "再提醒一次，闹钟提醒: 起床；倒计时结束了" {
+		t.Errorf("重复播报 = %q", got)
+	}
+}
+
+func TestJoinReminder(t *testing.T) {
+	p := &Pipeline{}
+	first, started := p.joinReminder("倒计时结束了")
+	if !started {
+		t.Fatal("没有提醒时应开始新的提醒")
+	}
+	if s, started := p.joinReminder("闹钟提醒: 起床"); started || s != first || len(first.messages) != 2 {
+		t.Fatalf("提醒进行中时应合并, started=%v messages=%v", started, first.messages)
+	}
+
+	// 用户已回应后再到期的提醒不能合并到已取消的提醒里
+	p.ackReminder()
+	second, started := p.joinReminder("吃药")
+	if !started || second == first {
+		t.Fatal("回应提醒后再到期的提醒应开始新的提醒")
+	}
+	if len(second.messages) != 1 || second.messages[0] != "吃药" {
+		t.Errorf("新的提醒只包含新消息, got %v", second.messages)
+	}
+
+	// 最后一次播报已取走内容时同样开始新的提醒
+	second.finishing = true
+	if third, started := p.joinReminder("关火"); !started || third == second {
+		t.Error("最后一次播报开始后再到期的提醒应开始新的提醒")
+	}
+}
+
+func TestTakeFollowUp(t *testing.T) {
+	p := &Pipeline{}
+	if got := p.takeFollowUp(); got != "" {
+		t.Errorf("没有跟进提醒时应返回空, got %q", got)
+	}
+	p.setFollowUp("跟进提醒: 该跟进装修报价了")
+	if got := p.takeFollowUp(); got != "跟进提醒: 该跟进装修报价了" {
+		t.Errorf("takeFollowUp = %q", got)
+	}
+	if got := p.takeFollowUp(); got != "" {
+		t.Errorf("读取后应清除, got %q", got)
+	}
+
+	p.setFollowUp("过期的提醒")
+	p.followUpAt = p.followUpAt.Add(-2 * followUpWindow)
+	if got := p.takeFollowUp(); got != "" {
+		t.Errorf("超过时间窗口不应返回, got %q", got)
+	}
+}
+
+func TestReminderVolume(t *testing.T) {
+	p := &Pipeline{cfg: &config.Config{}}
+	p.cfg.Tools.Timer.VolumeStep = 10
+	p.cfg.Tools.Timer.MaxVolume = 90
+	if v := p.reminderVolume(3); v != 0 {
+		t.Errorf("without alarm_volume got %d, want 0", v)
+	}
+
+	p.cfg.Tools.Volume.AlarmVolume = 60
+	for n, want := range map[int]int{1: 60, 2: 70, 4: 90, 6: 90} {
+		if v := p.reminderVolume(n); v != want {
+			t.Errorf("reminderVolume(%d) = %d, want %d", n, v, want)
+		}
+	}
+	p.cfg.Tools.Volume.AlarmVolume = 95
+	if v := p.reminderVolume(2); v != 95 {
+		t.Errorf("alarm_volume above max_volume should be kept, got %d", v)
+	}
+}
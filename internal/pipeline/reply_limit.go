@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// replyRunesPerSecond 估算朗读速度（字/秒），用于把最长朗读时间换算成字数。
+const replyRunesPerSecond = 4.5
+
+// replyOverflowAsk 回复超长被截断时的追问，用户回答"要"时大模型会接着详细说。
+const replyOverflowAsk = "需要我详细说吗？"
+
+// replyRuneLimit 一次回复最多朗读的字数，0 表示不限制。
+func (p *Pipeline) replyRuneLimit() int {
+	return int(float64(p.cfg.Dialog.MaxReplySeconds) * replyRunesPerSecond)
+}
+
+// truncateReply 在 limit 字以内的最后一个句末截断，并追问是否需要详细说。
+// 第一句就超长时按字数硬截断。
+func truncateReply(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	head := string(runes[:limit])
+	if end := lastSentenceEnd(head); end > 0 {
+		head = head[:end]
+	} else {
+		head += "……"
+	}
+	return strings.TrimSpace(head) + replyOverflowAsk
+}
+
+// condenseReply 完整回复超出朗读上限时，让大模型压缩成简短版本；失败或仍然超长时截断并追问。
+func (p *Pipeline) condenseReply(ctx context.Context, text string) string {
+	limit := p.replyRuneLimit()
+	logger.Infof("[pipeline] 回复过长（%d 字，上限 %d 字），压缩后朗读", utf8.RuneCountInString(text), limit)
+
+	textCh, err := p.llmProvider.ChatStream(ctx, []llm.Message{
+		{
+			Role: "system",
+			Content: fmt.Sprintf("把下面这段回答压缩成适合朗读的简短版本，不超过 %d 字，只保留最关键的信息，"+
+				"不要使用 Markdown、列表符号，直接输出压缩后的内容。", limit*2/3),
+		},
+		{Role: "user", Content: text},
+	})
+	if err != nil {
+		logger.Warnf("[pipeline] 压缩回复失败，截断朗读: %v", err)
+		return truncateReply(text, limit)
+	}
+	var condensed strings.Builder
+	for chunk := range textCh {
+		condensed.WriteString(chunk)
+	}
+	short := strings.TrimSpace(condensed.String())
+	if short == "" || ctx.Err() != nil {
+		return truncateReply(text, limit)
+	}
+	if utf8.RuneCountInString(short) > limit {
+		return truncateReply(short, limit)
+	}
+	return short + replyOverflowAsk
+}
@@ -1,6 +1,9 @@
 package pipeline
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestExtractSentence_ChinesePunctuation(t *testing.T) {
 	tests := []struct {
@@ -113,3 +116,35 @@ func TestExtractSentence_PunctuationOnly(t *testing.T) {
 		t.Errorf("remainder = %q, want empty", remainder)
 	}
 }
+
+func TestTrimWakeWord(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"小派今天天气怎么样", "今天天气怎么样"},
+		{"你好小派，放首歌", "放首歌"},
+		{"小派", ""},
+		{"今天小派去哪了", "今天小派去哪了"},
+		{"放首歌", "放首歌"},
+	}
+	for _, tt := range tests {
+		if got := trimWakeWord(tt.input, defaultWakeWords); got != tt.want {
+			t.Errorf("trimWakeWord(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestWakeWordsFrom(t *testing.T) {
+	words := wakeWordsFrom([]string{"小爱同学", "你好小派", "嘿派派"})
+	want := []string{"小爱同学", "你好小派", "嘿派派", "小派", "派派"}
+	if !reflect.DeepEqual(words, want) {
+		t.Fatalf("wakeWordsFrom = %v, want %v", words, want)
+	}
+	if got := trimWakeWord("派派，放首歌", words); got != "放首歌" {
+		t.Errorf("自定义唤醒词应被去掉: %q", got)
+	}
+	if got := trimWakeWord("小派放首歌", []string{"小爱同学"}); got != "小派放首歌" {
+		t.Errorf("未配置的唤醒词不应被去掉: %q", got)
+	}
+}
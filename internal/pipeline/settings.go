@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 对话设置可以用语音修改（manage_settings），p.cfg.Dialog 中这几项的读写都经过 dialogMu。
+
+// loadDialogSettings 用设备设置中保存的值覆盖配置文件。
+func (p *Pipeline) loadDialogSettings() {
+	p.dialogMu.Lock()
+	defer p.dialogMu.Unlock()
+	d := &p.cfg.Dialog
+	if p.settings.Has(database.SettingContinuousTimeout) {
+		d.ContinuousTimeout = p.settings.GetInt(database.SettingContinuousTimeout, d.ContinuousTimeout)
+	}
+	if p.settings.Has(database.SettingListenDelay) {
+		d.ListenDelay = p.settings.GetInt(database.SettingListenDelay, d.ListenDelay)
+	}
+	if p.settings.Has(database.SettingWakeReply) {
+		// 用语音设置过唤醒回复语时只使用设置的回复语，不再随机选择变体
+		d.WakeReply = p.settings.GetString(database.SettingWakeReply, d.WakeReply)
+		d.WakeReplies, d.TimedWakeReplies = nil, nil
+	}
+}
+
+// continuousTimeout 连续对话超时（秒），0 表示回复后不等待。
+func (p *Pipeline) continuousTimeout() int {
+	p.dialogMu.RLock()
+	defer p.dialogMu.RUnlock()
+	return p.cfg.Dialog.ContinuousTimeout
+}
+
+// listenDelay 回复后延迟进入监听的时间。
+func (p *Pipeline) listenDelay() time.Duration {
+	p.dialogMu.RLock()
+	defer p.dialogMu.RUnlock()
+	return time.Duration(p.cfg.Dialog.ListenDelay) * time.Millisecond
+}
+
+// wakeReply 唤醒回复语，为空不播放。
+func (p *Pipeline) wakeReply() string {
+	p.dialogMu.RLock()
+	defer p.dialogMu.RUnlock()
+	return p.cfg.Dialog.WakeReply
+}
+
+// currentSetting 返回 manage_settings 可修改的设置的当前值。
+func (p *Pipeline) currentSetting(name string) string {
+	switch name {
+	case tools.SettingNameContinuousTimeout:
+		return strconv.Itoa(p.continuousTimeout())
+	case tools.SettingNameListenDelay:
+		return strconv.FormatInt(p.listenDelay().Milliseconds(), 10)
+	case tools.SettingNameVerbosity:
+		return p.contextManager.Verbosity()
+	case tools.SettingNameWakeReply:
+		return p.wakeReply()
+	}
+	return ""
+}
+
+// applySetting 让 manage_settings 修改的设置立即生效，值已由工具校验。
+func (p *Pipeline) applySetting(name, value string) {
+	if name == tools.SettingNameVerbosity {
+		p.contextManager.SetVerbosity(value)
+		return
+	}
+	p.dialogMu.Lock()
+	defer p.dialogMu.Unlock()
+	switch name {
+	case tools.SettingNameContinuousTimeout:
+		p.cfg.Dialog.ContinuousTimeout, _ = strconv.Atoi(value)
+	case tools.SettingNameListenDelay:
+		p.cfg.Dialog.ListenDelay, _ = strconv.Atoi(value)
+	case tools.SettingNameWakeReply:
+		p.cfg.Dialog.WakeReply = value
+		p.cfg.Dialog.WakeReplies, p.cfg.Dialog.TimedWakeReplies = nil, nil
+	}
+}
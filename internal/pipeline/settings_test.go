@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+func TestLoadDialogSettings(t *testing.T) {
+	settings := newTestSettings(t)
+	settings.SetInt(database.SettingContinuousTimeout, 15)
+	settings.SetString(database.SettingWakeReply, "")
+
+	cfg := &config.Config{}
+	cfg.Dialog.ContinuousTimeout = 8
+	cfg.Dialog.ListenDelay = 300
+	cfg.Dialog.WakeReply = "我在"
+	p := &Pipeline{cfg: cfg, settings: settings}
+	p.loadDialogSettings()
+
+	if p.continuousTimeout() != 15 {
+		t.Errorf("continuousTimeout = %d, want 15", p.continuousTimeout())
+	}
+	if p.listenDelay() != 300*time.Millisecond {
+		t.Errorf("未保存的设置应使用配置文件的值: %v", p.listenDelay())
+	}
+	if p.wakeReply() != "" {
+		t.Errorf("保存为空的唤醒回复语应覆盖配置: %q", p.wakeReply())
+	}
+
+	p.applySetting(tools.SettingNameListenDelay, "800")
+	if p.listenDelay() != 800*time.Millisecond || p.currentSetting(tools.SettingNameListenDelay) != "800" {
+		t.Errorf("applySetting 后 listenDelay = %v", p.listenDelay())
+	}
+}
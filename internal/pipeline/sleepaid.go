@@ -0,0 +1,126 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/wake"
+)
+
+// sleepAidStep 睡前模式调整音量的间隔。
+const sleepAidStep = 10 * time.Second
+
+// sleepAidSession 睡前模式：音乐音量在设定时长内逐渐降低，结束后停止播放并恢复原音量。
+type sleepAidSession struct {
+	cancel    context.CancelFunc
+	gate      *wake.NearFieldGate // 更严格的近场门控，减少音乐引起的误唤醒
+	volume    int                 // 开始时的音量，结束后恢复
+	hasVolume bool
+}
+
+// sleepAidVolume 计算渐弱过程中的音量：从 start 线性降到 0。
+func sleepAidVolume(start int, elapsed, total time.Duration) int {
+	if total <= 0 || elapsed >= total {
+		return 0
+	}
+	return int(float64(start) * float64(total-elapsed) / float64(total))
+}
+
+// startSleepAid 进入睡前模式，minutes <= 0 时使用配置的默认时长。
+func (p *Pipeline) startSleepAid(minutes int) {
+	p.stopSleepAid()
+	p.sleepAidEnded.Store(false)
+	if minutes <= 0 {
+		minutes = p.cfg.Tools.Music.SleepAid.Minutes
+	}
+	total := time.Duration(minutes) * time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &sleepAidSession{
+		cancel: cancel,
+		gate:   wake.NewNearFieldGate(p.cfg.Tools.Music.SleepAid.WakeThreshold, p.cfg.Wake.NearField.MinLevelDB),
+	}
+	if p.volumeCtrl != nil {
+		if vol, err := p.volumeCtrl.GetVolume(); err == nil {
+			session.volume, session.hasVolume = vol, true
+		} else {
+			logger.Warnf("[pipeline] 获取音量失败，睡前模式只定时停止: %v", err)
+		}
+	}
+
+	p.sleepAidMu.Lock()
+	p.sleepAid = session
+	p.sleepAidMu.Unlock()
+
+	logger.Infof("[pipeline] 进入睡前模式，%d 分钟内音量逐渐降低后停止播放", minutes)
+	go p.runSleepAid(ctx, session, total)
+}
+
+// runSleepAid 逐步降低音量，到时间后停止播放、恢复原音量并回到空闲。
+func (p *Pipeline) runSleepAid(ctx context.Context, session *sleepAidSession, total time.Duration) {
+	ticker := time.NewTicker(sleepAidStep)
+	defer ticker.Stop()
+	started := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		elapsed := time.Since(started)
+		if elapsed >= total {
+			break
+		}
+		if session.hasVolume {
+			if err := p.volumeCtrl.SetVolume(sleepAidVolume(session.volume, elapsed, total)); err != nil {
+				logger.Debugf("[pipeline] 睡前模式调整音量失败: %v", err)
+			}
+		}
+	}
+
+	logger.Info("[pipeline] 睡前模式结束，停止播放")
+	if p.playback.Playing() {
+		p.sleepAidEnded.Store(true)
+		p.playback.Stop()
+	}
+	// 等播放真正停下再恢复音量，避免最后一小段音乐突然变响
+	time.Sleep(500 * time.Millisecond)
+
+	p.sleepAidMu.Lock()
+	current := p.sleepAid == session
+	p.sleepAidMu.Unlock()
+	if current {
+		p.stopSleepAid()
+	}
+}
+
+// stopSleepAid 退出睡前模式并恢复原音量（被唤醒、播放列表结束或渐弱结束时调用）。
+func (p *Pipeline) stopSleepAid() {
+	p.sleepAidMu.Lock()
+	session := p.sleepAid
+	p.sleepAid = nil
+	p.sleepAidMu.Unlock()
+	if session == nil {
+		return
+	}
+
+	session.cancel()
+	if session.hasVolume {
+		if err := p.volumeCtrl.SetVolume(session.volume); err != nil {
+			logger.Warnf("[pipeline] 恢复音量失败: %v", err)
+		}
+	}
+	logger.Info("[pipeline] 已退出睡前模式")
+}
+
+// sleepAidGate 返回睡前模式的近场门控，未处于睡前模式时返回 nil。
+func (p *Pipeline) sleepAidGate() *wake.NearFieldGate {
+	p.sleepAidMu.Lock()
+	defer p.sleepAidMu.Unlock()
+	if p.sleepAid == nil {
+		return nil
+	}
+	return p.sleepAid.gate
+}
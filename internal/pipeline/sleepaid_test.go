@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSleepAidVolume(t *testing.T) {
+	total := 30 * time.Minute
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 60},
+		{10 * time.Minute, 40},
+		{15 * time.Minute, 30},
+		{29 * time.Minute, 2},
+		{30 * time.Minute, 0},
+		{31 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		if got := sleepAidVolume(60, tt.elapsed, total); got != tt.want {
+			t.Errorf("sleepAidVolume(60, %v) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+}
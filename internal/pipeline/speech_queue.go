@@ -0,0 +1,195 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// speechPriority 后台播报的优先级，排队时优先级高的先播，相同优先级按先来后到。
+type speechPriority int
+
+const (
+	speechLow    speechPriority = iota // 今日小结、模式到期等提示
+	speechNormal                       // 健康提醒、整点报时、出门提醒、声音事件
+	speechUrgent                       // 闹钟、倒计时等到期提醒
+)
+
+// speechBusyPoll 对话进行中时，队列检查对话是否结束的间隔。
+const speechBusyPoll = 500 * time.Millisecond
+
+// speechItem 排队等待的一条播报。
+type speechItem struct {
+	name     string // 用于日志
+	priority speechPriority
+	seq      uint64
+	ctx      context.Context
+	speak    func(ctx context.Context)
+	done     chan struct{}
+}
+
+// speechQueue 串行化所有后台播报（闹钟、倒计时、健康提醒、整点报时等）：同一时间只播一条，
+// 对话进行中时等对话结束，两条播报之间至少间隔 gap，避免几个提醒同时到期时互相盖过或丢失。
+type speechQueue struct {
+	mu    sync.Mutex
+	items []*speechItem
+	seq   uint64
+	wake  chan struct{}
+	gap   time.Duration
+	busy  func() bool // 是否正在对话，为 nil 时不等待
+}
+
+func newSpeechQueue(gap time.Duration, busy func() bool) *speechQueue {
+	return &speechQueue{wake: make(chan struct{}, 1), gap: gap, busy: busy}
+}
+
+// Speak 把一条播报加入队列并等待播完。ctx 取消时（如用户已回应提醒）从队列中移除，返回 false。
+func (q *speechQueue) Speak(ctx context.Context, name string, priority speechPriority, speak func(ctx context.Context)) bool {
+	q.mu.Lock()
+	q.seq++
+	item := &speechItem{name: name, priority: priority, seq: q.seq, ctx: ctx, speak: speak, done: make(chan struct{})}
+	q.items = append(q.items, item)
+	pending := len(q.items)
+	q.mu.Unlock()
+	if pending > 1 {
+		logger.Debugf("[pipeline] 播报排队: %s（前面还有 %d 条）", name, pending-1)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-item.done:
+		return ctx.Err() == nil
+	case <-ctx.Done():
+		q.remove(item)
+		return false
+	}
+}
+
+// Run 依次播放队列中的播报，直到 ctx 取消。
+func (q *speechQueue) Run(ctx context.Context) {
+	for {
+		item := q.next(ctx)
+		if item == nil {
+			return
+		}
+		if item.ctx.Err() == nil {
+			item.speak(item.ctx)
+		}
+		close(item.done)
+
+		if q.gap > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.gap):
+			}
+		}
+	}
+}
+
+// next 等到队列非空且没有在对话时，取出优先级最高的一条。ctx 取消时返回 nil。
+func (q *speechQueue) next(ctx context.Context) *speechItem {
+	for {
+		if q.busy == nil || !q.busy() {
+			if item := q.pop(); item != nil {
+				return item
+			}
+		}
+		wait := time.After(speechBusyPoll)
+		if q.len() == 0 {
+			wait = nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.wake:
+		case <-wait:
+		}
+	}
+}
+
+// pop 取出优先级最高、最早加入的一条，已取消的播报直接丢弃。
+func (q *speechQueue) pop() *speechItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	best := -1
+	for i, item := range q.items {
+		if best < 0 || item.priority > q.items[best].priority ||
+			(item.priority == q.items[best].priority && item.seq < q.items[best].seq) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	item := q.items[best]
+	q.items = append(q.items[:best], q.items[best+1:]...)
+	return item
+}
+
+func (q *speechQueue) remove(item *speechItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, it := range q.items {
+		if it == item {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *speechQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// announce 后台播报一段文字：经过播报队列排队，播完（或被取消）后返回是否播出。
+func (p *Pipeline) announce(ctx context.Context, priority speechPriority, text string) bool {
+	return p.announceWith(ctx, text, priority, func(ctx context.Context) { p.speakText(ctx, text) })
+}
+
+// announceWith 后台播报的通用形式，speak 中可以先响提示音再说话（如整点报时）。
+// 没有播报队列时（测试中）直接播放。确定没人在家时只播到期提醒（speechUrgent）。
+// 配置了 tools.volume 的播报音量时临时调到该音量，播完恢复。
+func (p *Pipeline) announceWith(ctx context.Context, name string, priority speechPriority, speak func(ctx context.Context)) bool {
+	return p.announceAtVolume(ctx, name, priority, p.announceVolume(priority), speak)
+}
+
+// announceVolume 后台播报的临时音量：到期提醒用 alarm_volume，其他播报用 announce_volume，0 表示不调整。
+func (p *Pipeline) announceVolume(priority speechPriority) int {
+	if p.volumeCtrl == nil {
+		return 0
+	}
+	if priority == speechUrgent {
+		return p.cfg.Tools.Volume.AlarmVolume
+	}
+	return p.cfg.Tools.Volume.AnnounceVolume
+}
+
+// announceAtVolume 以指定的临时音量播报，volume 为 0 或没有音量控制器时不调整。
+func (p *Pipeline) announceAtVolume(ctx context.Context, name string, priority speechPriority, volume int, speak func(ctx context.Context)) bool {
+	if priority < speechUrgent && p.nobodyHome() {
+		logger.Infof("[pipeline] 没人在家，跳过播报: %s", name)
+		return false
+	}
+	if volume > 0 && p.volumeCtrl != nil {
+		inner := speak
+		speak = func(ctx context.Context) {
+			restore := p.volumeCtrl.SetTemporary(volume)
+			defer restore()
+			inner(ctx)
+		}
+	}
+	if p.speechQueue == nil {
+		speak(ctx)
+		return ctx.Err() == nil
+	}
+	return p.speechQueue.Speak(ctx, name, priority, speak)
+}
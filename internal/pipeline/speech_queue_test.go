@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpeechQueue_PriorityAndSerial(t *testing.T) {
+	var busy atomic.Bool
+	busy.Store(true) // 对话进行中，先排队
+	q := newSpeechQueue(10*time.Millisecond, busy.Load)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	var mu sync.Mutex
+	var order []string
+	speaking := 0
+	speak := func(name string) func(context.Context) {
+		return func(context.Context) {
+			mu.Lock()
+			speaking++
+			if speaking > 1 {
+				t.Errorf("%s 与其他播报同时进行", name)
+			}
+			order = append(order, name)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			speaking--
+			mu.Unlock()
+		}
+	}
+
+	var wg sync.WaitGroup
+	add := func(name string, priority speechPriority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !q.Speak(context.Background(), name, priority, speak(name)) {
+				t.Errorf("%s 应被播出", name)
+			}
+		}()
+		for !queued(q, name) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	add("小结", speechLow)
+	add("喝水", speechNormal)
+	add("闹钟", speechUrgent)
+	add("整点", speechNormal)
+
+	// 被取消的播报直接移出队列
+	cancelled, cancelItem := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancelItem()
+	}()
+	if q.Speak(cancelled, "已回应", speechUrgent, speak("已回应")) {
+		t.Error("取消的播报不应播出")
+	}
+
+	busy.Store(false)
+	wg.Wait()
+
+	want := []string{"闹钟", "喝水", "整点", "小结"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func queued(q *speechQueue, name string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range q.items {
+		if item.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnnounce_WithoutQueue(t *testing.T) {
+	p := &Pipeline{}
+	spoken := false
+	if !p.announceWith(context.Background(), "测试", speechNormal, func(context.Context) { spoken = true }) || !spoken {
+		t.Error("没有播报队列时应直接播放")
+	}
+}
@@ -0,0 +1,190 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+const (
+	streamFirstMinRunes = 6   // 第一段至少的字数，避免只读出"好。"、"1."这样的碎片
+	streamMergeRunes    = 50  // 之后的完整句子攒够这么多字再送 TTS，减少合成次数
+	streamMaxRunes      = 100 // 每段最多字数，与 mergeSentences 一致
+)
+
+// replySegmenter 把流式到达的回复切成可以立即朗读的片段。
+// 第一段在第一句话完整、且后面已有新内容时才输出：只有一句话的回复多半是工具调用前的前言，
+// 等流结束后再决定是否朗读。遇到 Markdown 表格或代码块时停止分段，等结束后统一处理。
+type replySegmenter struct {
+	pending string
+	emitted int
+	hold    bool
+}
+
+// Push 追加一段流式文本，返回可以朗读的片段。
+func (s *replySegmenter) Push(chunk string) []string {
+	s.pending += chunk
+	if s.hold {
+		return nil
+	}
+	if strings.Contains(s.pending, "|") || strings.Contains(s.pending, "```") {
+		s.hold = true
+		return nil
+	}
+
+	if s.emitted == 0 {
+		first, rest := "", s.pending
+		for utf8.RuneCountInString(strings.TrimSpace(first)) < streamFirstMinRunes {
+			sentence, remainder, found := extractSentence(rest)
+			if !found {
+				return nil
+			}
+			first, rest = first+sentence, remainder
+		}
+		if strings.TrimSpace(rest) == "" {
+			return nil
+		}
+		s.pending = rest
+		return s.emit(first)
+	}
+
+	end := lastSentenceEnd(s.pending)
+	if end <= 0 || utf8.RuneCountInString(s.pending[:end]) < streamMergeRunes {
+		return nil
+	}
+	complete := s.pending[:end]
+	s.pending = s.pending[end:]
+	return s.emit(complete)
+}
+
+// Flush 回复结束，返回剩余的全部片段。
+func (s *replySegmenter) Flush() []string {
+	rest := s.pending
+	s.pending = ""
+	return s.emit(rest)
+}
+
+// Emitted 是否已经输出过片段。
+func (s *replySegmenter) Emitted() bool {
+	return s.emitted > 0
+}
+
+func (s *replySegmenter) emit(text string) []string {
+	var out []string
+	for _, chunk := range mergeSentences(tts.PreprocessText(text), streamMaxRunes) {
+		if chunk = strings.TrimSpace(chunk); chunk != "" {
+			out = append(out, chunk)
+		}
+	}
+	s.emitted += len(out)
+	return out
+}
+
+// lastSentenceEnd 返回最后一个句末标点之后的字节位置，没有时返回 0。
+func lastSentenceEnd(text string) int {
+	end := 0
+	for i, r := range text {
+		switch r {
+		case '。', '！', '？', '；', '.', '!', '?', '\n':
+			end = i + utf8.RuneLen(r)
+		}
+	}
+	return end
+}
+
+// streamSpeaker 边接收大模型回复边朗读：片段送入后台 goroutine 依次合成播放，
+// 不阻塞继续读取回复。ctx 取消（打断）后剩余片段直接丢弃。
+// 设置了最长朗读时间时，读到上限后停止朗读，结束时问用户是否需要详细说。
+type streamSpeaker struct {
+	p         *Pipeline
+	ctx       context.Context
+	seg       replySegmenter
+	ch        chan string
+	done      chan struct{}
+	started   bool
+	limit     int             // 最多朗读的字数，0 表示不限制
+	spoken    strings.Builder // 已送去朗读的内容
+	truncated bool
+}
+
+func (p *Pipeline) newStreamSpeaker(ctx context.Context) *streamSpeaker {
+	return &streamSpeaker{p: p, ctx: ctx, limit: p.replyRuneLimit()}
+}
+
+// Push 追加一段流式文本，凑出完整句子时开始朗读。
+func (s *streamSpeaker) Push(chunk string) {
+	s.send(s.seg.Push(chunk))
+}
+
+// Started 是否已经开始朗读。
+func (s *streamSpeaker) Started() bool {
+	return s.started
+}
+
+// Truncated 回复是否因超出朗读上限被截断。
+func (s *streamSpeaker) Truncated() bool {
+	return s.truncated
+}
+
+// Spoken 实际朗读的内容（截断时包括追问），用于记入对话上下文。
+func (s *streamSpeaker) Spoken() string {
+	return s.spoken.String()
+}
+
+// Finish 结束朗读并等待播放完成。flush 为 false 时丢弃尚未朗读的内容（工具调用、打断）。
+func (s *streamSpeaker) Finish(flush bool) {
+	if flush {
+		s.send(s.seg.Flush())
+		if s.truncated {
+			s.enqueue(replyOverflowAsk)
+		}
+	}
+	if !s.started {
+		return
+	}
+	close(s.ch)
+	<-s.done
+}
+
+func (s *streamSpeaker) send(segments []string) {
+	for _, text := range segments {
+		if s.truncated {
+			return
+		}
+		// 超出朗读上限：后面的内容不再朗读（至少读完第一段）
+		if s.limit > 0 && s.spoken.Len() > 0 &&
+			utf8.RuneCountInString(s.spoken.String())+utf8.RuneCountInString(text) > s.limit {
+			logger.Infof("[pipeline] 回复超出朗读上限（%d 字），停止朗读", s.limit)
+			s.truncated = true
+			return
+		}
+		s.enqueue(text)
+	}
+}
+
+// enqueue 送一段文本去朗读，第一次调用时启动朗读 goroutine。
+func (s *streamSpeaker) enqueue(text string) {
+	s.spoken.WriteString(text)
+	if !s.started {
+		s.started = true
+		s.ch = make(chan string, 16)
+		s.done = make(chan struct{})
+		s.p.state.Transition(StateSpeaking)
+		go s.run()
+	}
+	s.ch <- text
+}
+
+func (s *streamSpeaker) run() {
+	defer close(s.done)
+	for text := range s.ch {
+		if s.p.interrupted.Load() || s.ctx.Err() != nil {
+			continue
+		}
+		logger.Infof("[小派] %s", text)
+		s.p.speakText(s.ctx, text)
+	}
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplySegmenter_FirstSentenceWaitsForMore(t *testing.T) {
+	var s replySegmenter
+	if got := s.Push("好的，我来帮你查一下。"); got != nil {
+		t.Fatalf("只有一句话时不应输出（可能是工具调用前言），got %v", got)
+	}
+	got := s.Push("今天")
+	if len(got) != 1 || got[0] != "好的，我来帮你查一下。" {
+		t.Fatalf("后面有新内容时应输出第一句，got %v", got)
+	}
+	if !s.Emitted() {
+		t.Error("Emitted() = false, want true")
+	}
+	rest := s.Flush()
+	if len(rest) != 1 || rest[0] != "今天" {
+		t.Errorf("Flush() = %v, want [今天]", rest)
+	}
+}
+
+func TestReplySegmenter_ShortFirstSentenceMerged(t *testing.T) {
+	var s replySegmenter
+	if got := s.Push("好。今天"); got != nil {
+		t.Fatalf("第一句太短时应等下一句，got %v", got)
+	}
+	got := s.Push("天气不错。明天")
+	if len(got) != 1 || got[0] != "好。今天天气不错。" {
+		t.Fatalf("got %v, want [好。今天天气不错。]", got)
+	}
+}
+
+func TestReplySegmenter_MergesLaterSentences(t *testing.T) {
+	var s replySegmenter
+	s.Push("今天天气晴朗。")
+	if got := s.Push("气温"); len(got) != 1 {
+		t.Fatalf("应输出第一句，got %v", got)
+	}
+	if got := s.Push("二十度。适合出门。"); got != nil {
+		t.Fatalf("后续短句应攒够字数再输出，got %v", got)
+	}
+	long := strings.Repeat("这是一个比较长的句子。", 6)
+	got := s.Push(long + "未完")
+	if len(got) == 0 {
+		t.Fatal("攒够字数后应输出")
+	}
+	joined := strings.Join(got, "")
+	if strings.Contains(joined, "未完") {
+		t.Errorf("不完整的句子不应输出: %v", got)
+	}
+	if !strings.HasPrefix(joined, "气温二十度。") {
+		t.Errorf("输出应从上次剩余处开始: %v", got)
+	}
+}
+
+func TestReplySegmenter_HoldsTables(t *testing.T) {
+	var s replySegmenter
+	s.Push("下面是对比：\n| 城市 | 温度 |\n")
+	if got := s.Push("|---|---|\n| 北京 | 20 |\n。更多内容"); got != nil {
+		t.Fatalf("含表格时不应分段输出，got %v", got)
+	}
+	if s.Emitted() {
+		t.Error("含表格时 Emitted() 应为 false")
+	}
+}
+
+func TestLastSentenceEnd(t *testing.T) {
+	if got := lastSentenceEnd("没有标点"); got != 0 {
+		t.Errorf("lastSentenceEnd = %d, want 0", got)
+	}
+	text := "第一句。第二句！未完"
+	if got := lastSentenceEnd(text); text[:got] != "第一句。第二句！" {
+		t.Errorf("lastSentenceEnd 截取 = %q", text[:got])
+	}
+}
+
+func TestTruncateReply(t *testing.T) {
+	if got := truncateReply("很短的回复。", 20); got != "很短的回复。" {
+		t.Errorf("未超长时不应截断: %q", got)
+	}
+	got := truncateReply("第一句话。第二句话。第三句话很长很长。", 12)
+	if got != "第一句话。第二句话。"+replyOverflowAsk {
+		t.Errorf("应在句末截断并追问: %q", got)
+	}
+	got = truncateReply("一句没有标点的很长很长的话", 5)
+	if got != "一句没有标……"+replyOverflowAsk {
+		t.Errorf("没有句末时应按字数截断: %q", got)
+	}
+}
+
+func TestStreamSpeaker_Limit(t *testing.T) {
+	// 已超出上限：后续片段丢弃，且不会启动朗读（spoken 非空时才截断）
+	s := &streamSpeaker{limit: 10}
+	s.spoken.WriteString("已经读了八个字。")
+	s.send([]string{"这一段会超出上限。", "这段也不读。"})
+	if !s.Truncated() {
+		t.Fatal("超出上限时应标记截断")
+	}
+	if s.Spoken() != "已经读了八个字。" {
+		t.Errorf("截断后不应再追加内容: %q", s.Spoken())
+	}
+}
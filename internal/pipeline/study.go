@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// studyBlockedTools 学习时间内孩子不能使用的工具（点歌、听故事、游戏）。
+// 停止音乐、查字典、学英语、计算器等不受限制。
+var studyBlockedTools = map[string]string{
+	"play_music":         "music",
+	"search_music":       "music",
+	"next_music":         "music",
+	"resume_music":       "music",
+	"play_favorites":     "music",
+	"play_radio_station": "music",
+	"tell_story":         "story",
+	"continue_story":     "story",
+	"poetry_game":        "game",
+}
+
+// studyReminders 拒绝时的提醒。
+var studyReminders = map[string]string{
+	"music": "现在是学习时间哦，等学完了再听歌吧，加油！",
+	"story": "现在是学习时间哦，学完了再听故事吧。",
+	"game":  "现在是学习时间哦，学完了再一起玩吧。不过查字典、学英语我随时可以帮你。",
+}
+
+// studySession 进行中的学习时间。
+type studySession struct {
+	child string
+	until time.Time
+	timer *time.Timer
+}
+
+// startStudy 为孩子（声纹用户）开启学习时间。孩子本人不能开启；已有学习时间时重新开始。
+func (p *Pipeline) startStudy(child string, d time.Duration) error {
+	if p.voiceprintMgr == nil || p.voiceprintMgr.NumSpeakers() == 0 {
+		return errors.New("需要先注册声纹，我才能分辨是不是孩子在说话")
+	}
+	if _, err := p.voiceprintMgr.GetUser(child); err != nil {
+		return fmt.Errorf("没有找到叫%s的声纹用户", child)
+	}
+	if p.contextManager.GetCurrentSpeaker() == child {
+		return errors.New("学习时间要请家长来开启哦")
+	}
+
+	p.studyMu.Lock()
+	if p.study != nil {
+		p.study.timer.Stop()
+	}
+	session := &studySession{child: child, until: time.Now().Add(d)}
+	session.timer = time.AfterFunc(d, func() { p.finishStudy(session) })
+	p.study = session
+	p.studyMu.Unlock()
+
+	logger.Infof("[pipeline] %s 的学习时间开始，%s 后结束", child, d)
+	return nil
+}
+
+// stopStudy 提前结束学习时间，孩子本人不能结束。
+func (p *Pipeline) stopStudy() (string, error) {
+	p.studyMu.Lock()
+	defer p.studyMu.Unlock()
+	if p.study == nil {
+		return "", errors.New("现在没有进行中的学习时间")
+	}
+	if p.contextManager.GetCurrentSpeaker() == p.study.child {
+		return "", errors.New("学习时间还没结束，要请家长来结束哦")
+	}
+	child := p.study.child
+	p.study.timer.Stop()
+	p.study = nil
+	logger.Infof("[pipeline] 提前结束 %s 的学习时间", child)
+	return child, nil
+}
+
+// studyStatus 进行中的学习时间和剩余时长。
+func (p *Pipeline) studyStatus() (string, time.Duration, bool) {
+	p.studyMu.Lock()
+	defer p.studyMu.Unlock()
+	if p.study == nil {
+		return "", 0, false
+	}
+	return p.study.child, time.Until(p.study.until), true
+}
+
+// studyChild 正在学习的孩子，没有学习时间时为空。
+func (p *Pipeline) studyChild() string {
+	p.studyMu.Lock()
+	defer p.studyMu.Unlock()
+	if p.study == nil {
+		return ""
+	}
+	return p.study.child
+}
+
+// studyBlocked 学习时间内当前说话人是孩子、且工具属于娱乐类时返回提醒。
+func (p *Pipeline) studyBlocked(tool string) (string, bool) {
+	kind, ok := studyBlockedTools[tool]
+	if !ok {
+		return "", false
+	}
+	child := p.studyChild()
+	if child == "" || p.contextManager.GetCurrentSpeaker() != child {
+		return "", false
+	}
+	return studyReminders[kind], true
+}
+
+// finishStudy 学习时间到：等当前对话结束后响铃并表扬。
+func (p *Pipeline) finishStudy(session *studySession) {
+	p.studyMu.Lock()
+	if p.study != session {
+		p.studyMu.Unlock()
+		return
+	}
+	p.study = nil
+	p.studyMu.Unlock()
+	logger.Infof("[pipeline] %s 的学习时间结束", session.child)
+
+	praise := strings.ReplaceAll(p.cfg.Tools.Study.Praise, "{name}", p.userNickname(session.child))
+	p.announceWith(context.Background(), "学习时间结束", speechNormal, func(ctx context.Context) {
+		p.playSamples(ctx, chimeSamples(3), chimeSampleRate)
+		p.speakText(ctx, praise)
+	})
+}
@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+func TestStudyBlocked(t *testing.T) {
+	p := &Pipeline{contextManager: llm.NewContextManager("", 10)}
+	p.contextManager.SetCurrentSpeaker("小明", nil)
+	if _, blocked := p.studyBlocked("play_music"); blocked {
+		t.Fatal("nothing should be blocked without a study session")
+	}
+
+	p.study = &studySession{child: "小明", until: time.Now().Add(time.Hour), timer: time.NewTimer(time.Hour)}
+	for _, name := range []string{"play_music", "next_music", "tell_story", "poetry_game"} {
+		if reminder, blocked := p.studyBlocked(name); !blocked || reminder == "" {
+			t.Errorf("%s should be blocked for the child", name)
+		}
+	}
+	for _, name := range []string{"lookup_hanzi", "english_word", "stop_music", "calculate"} {
+		if _, blocked := p.studyBlocked(name); blocked {
+			t.Errorf("%s should stay available", name)
+		}
+	}
+
+	// 孩子本人不能结束学习时间
+	if _, err := p.stopStudy(); err == nil {
+		t.Error("child should not be able to stop study mode")
+	}
+
+	// 其他人不受限制，可以结束
+	p.contextManager.SetCurrentSpeaker("爸爸", nil)
+	if _, blocked := p.studyBlocked("play_music"); blocked {
+		t.Error("other speakers should not be blocked")
+	}
+	if child, err := p.stopStudy(); err != nil || child != "小明" {
+		t.Errorf("stopStudy() = %q, %v", child, err)
+	}
+	if _, _, ok := p.studyStatus(); ok {
+		t.Error("study should be over after stop")
+	}
+}
+
+func TestFinishStudyIgnoresStaleSession(t *testing.T) {
+	current := &studySession{child: "小明", until: time.Now().Add(time.Hour), timer: time.NewTimer(time.Hour)}
+	p := &Pipeline{study: current}
+	p.finishStudy(&studySession{child: "小明"})
+	if p.study != current {
+		t.Error("stale finish should not end the current session")
+	}
+}
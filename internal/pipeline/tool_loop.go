@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+// 工具调用循环保护：大模型反复用同样的参数调用同一个工具、或工具轮数用完时，最后一轮不再提供工具，
+// 让大模型根据已有结果直接回答；仍然没有回答时朗读尝试过的操作，而不是一声不吭。
+
+// repeatedToolResult 重复调用时代替工具结果返回给大模型。
+const repeatedToolResult = `{"success":false,"message":"刚刚已经用同样的参数调用过这个工具，结果见前面的消息，不要再重复调用，请直接根据已有结果回答用户"}`
+
+// finalAnswerHint 最后一轮不带工具时追加的提示。
+const finalAnswerHint = "工具调用次数已用完，请不要再调用工具，直接根据上面已有的工具结果简短回答用户；没有得到需要的信息时如实说明尝试了什么、哪里没有成功。"
+
+// toolLoopGiveUp 大模型最终没有给出回答、又无法说明尝试过什么时的兜底回复。
+const toolLoopGiveUp = "这次没能得出结果，换个说法再问我一次吧"
+
+// maxAttemptLabels 兜底回复中最多列出几个尝试过的操作。
+const maxAttemptLabels = 3
+
+// toolLoopGuard 一次对话中的工具调用记录，只在 processQuery 的 goroutine 中访问。
+type toolLoopGuard struct {
+	seen     map[string]bool
+	attempts []string // 调用过的工具名，按首次调用的顺序
+	looped   bool     // 出现过重复调用
+}
+
+// repeated 记录一次工具调用，同一个工具以相同参数调用过时返回 true。
+func (g *toolLoopGuard) repeated(name, args string) bool {
+	if g.seen == nil {
+		g.seen = make(map[string]bool)
+	}
+	key := name + "|" + canonicalToolArgs(args)
+	if g.seen[key] {
+		g.looped = true
+		return true
+	}
+	g.seen[key] = true
+	for _, a := range g.attempts {
+		if a == name {
+			return false
+		}
+	}
+	g.attempts = append(g.attempts, name)
+	return false
+}
+
+// canonicalToolArgs 规范化参数 JSON（键排序、去掉空白），参数写法不同但内容相同时视为同一次调用。
+func canonicalToolArgs(args string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(args), &v); err != nil {
+		return strings.TrimSpace(args)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return strings.TrimSpace(args)
+	}
+	return string(data)
+}
+
+// finalRoundMessages 最后一轮（不带工具）发给大模型的消息：追加一条提示，不写入对话上下文。
+func finalRoundMessages(messages []llm.Message) []llm.Message {
+	return append(messages, llm.Message{Role: "system", Content: finalAnswerHint})
+}
+
+// toolLabel 工具的简短说明（描述的第一个分句），用于朗读尝试过的操作。
+func (p *Pipeline) toolLabel(name string) string {
+	t, ok := p.toolRegistry.Get(name)
+	if !ok {
+		return ""
+	}
+	desc := t.Description()
+	if i := strings.IndexAny(desc, "。，,：:（(；;"); i >= 0 {
+		desc = desc[:i]
+	}
+	return strings.TrimSpace(desc)
+}
+
+// toolLoopFallback 大模型最终没有给出回答时的兜底回复：说明尝试过哪些操作。
+func (p *Pipeline) toolLoopFallback(g *toolLoopGuard) string {
+	if p.replyLanguage() == llm.LanguageEnglish {
+		return p.localize(toolLoopGiveUp)
+	}
+	var labels []string
+	for _, name := range g.attempts {
+		if label := p.toolLabel(name); label != "" {
+			labels = append(labels, label)
+		}
+		if len(labels) == maxAttemptLabels {
+			break
+		}
+	}
+	if len(labels) == 0 {
+		return toolLoopGiveUp
+	}
+	return "我试着" + strings.Join(labels, "、") + "，但没能得出结果，换个说法再问我一次吧"
+}
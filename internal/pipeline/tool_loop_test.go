@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+func TestToolLoopGuard(t *testing.T) {
+	var g toolLoopGuard
+	if g.repeated("get_weather", `{"city": "杭州", "days": 3}`) {
+		t.Fatal("first call should not be a repeat")
+	}
+	if g.repeated("get_weather", `{"city":"北京"}`) {
+		t.Error("different arguments should not be a repeat")
+	}
+	if !g.repeated("get_weather", `{"days":3,"city":"杭州"}`) {
+		t.Error("same arguments in a different order should be a repeat")
+	}
+	if !g.looped {
+		t.Error("a repeat should mark the loop")
+	}
+	if len(g.attempts) != 1 || g.attempts[0] != "get_weather" {
+		t.Errorf("attempts = %v", g.attempts)
+	}
+}
+
+func TestToolLoopFallback(t *testing.T) {
+	p := &Pipeline{toolRegistry: tools.NewRegistry()}
+	p.toolRegistry.Register(tools.NewCalculatorTool())
+	p.toolRegistry.Register(tools.NewDateTimeTool())
+
+	var g toolLoopGuard
+	if got := p.toolLoopFallback(&g); got != toolLoopGiveUp {
+		t.Errorf("no attempts: got %q", got)
+	}
+	g.repeated("calculate", `{"expression":"1+1"}`)
+	g.repeated("unknown_tool", `{}`)
+	if got, want := p.toolLoopFallback(&g), "我试着计算数学表达式，但没能得出结果，换个说法再问我一次吧"; got != want {
+		t.Errorf("fallback = %q, want %q", got, want)
+	}
+}
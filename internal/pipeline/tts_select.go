@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tts"
+)
+
+// newTTSEngine 按名称创建 TTS 引擎（tencent、edge、sherpa、piper、say）。
+func newTTSEngine(cfg config.TTSConfig, name string) (tts.Engine, error) {
+	switch name {
+	case "tencent":
+		engine, err := tts.NewTencentEngine(tts.TencentConfig{
+			SecretID:  cfg.Tencent.SecretID,
+			SecretKey: cfg.Tencent.SecretKey,
+			VoiceType: cfg.Tencent.VoiceType,
+			Region:    cfg.Tencent.Region,
+			Speed:     cfg.Tencent.Speed,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("初始化腾讯云 TTS 失败: %w", err)
+		}
+		return engine, nil
+	case "edge":
+		return newEdgeEngine(cfg.Edge), nil
+	case "sherpa":
+		engine, err := tts.NewSherpaEngine(tts.SherpaConfig{
+			ModelPath:   cfg.Sherpa.ModelPath,
+			TokensPath:  cfg.Sherpa.TokensPath,
+			DataDir:     cfg.Sherpa.DataDir,
+			NoiseScale:  cfg.Sherpa.NoiseScale,
+			LengthScale: cfg.Sherpa.LengthScale,
+			Speed:       cfg.Sherpa.Speed,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("初始化 Sherpa TTS 失败: %w", err)
+		}
+		return engine, nil
+	case "piper":
+		if cfg.Piper.ModelPath == "" {
+			return nil, fmt.Errorf("未配置 Piper 模型路径")
+		}
+		return tts.NewPiperEngine(cfg.Piper.ModelPath), nil
+	case "say":
+		return tts.NewSayEngine(cfg.Say.Voice), nil
+	default:
+		return nil, fmt.Errorf("未知的 TTS 引擎: %s", name)
+	}
+}
+
+// newTTSSelector 创建按健康度自动选择的 TTS 引擎，初始化失败的引擎跳过，一个都不可用时返回错误。
+func newTTSSelector(cfg config.TTSConfig) (*tts.Selector, error) {
+	var engines []tts.NamedEngine
+	for _, name := range cfg.Selection.Engines {
+		engine, err := newTTSEngine(cfg, name)
+		if err != nil {
+			logger.Warnf("[pipeline] TTS 引擎 %s 不可用，不参与选择: %v", name, err)
+			continue
+		}
+		engines = append(engines, tts.NamedEngine{Name: name, Engine: engine})
+	}
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("tts.selection 中没有可用的 TTS 引擎")
+	}
+	selector := tts.NewSelector(engines, cfg.Selection.Pin)
+	logger.Infof("[pipeline] TTS 按健康度自动选择: %v (固定: %q)", cfg.Selection.Engines, selector.Pinned())
+	return selector, nil
+}
+
+// handleTTSStatus 返回各 TTS 引擎的成功率、延迟和当前首选引擎。
+func (p *Pipeline) handleTTSStatus(w http.ResponseWriter, r *http.Request) {
+	if p.ttsSelector == nil {
+		admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"current": p.cfg.TTS.Engine,
+		})
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"current": p.ttsSelector.Current(),
+		"pinned":  p.ttsSelector.Pinned(),
+		"engines": p.ttsSelector.Stats(),
+	})
+}
+
+// handleTTSPin 固定使用某个 TTS 引擎，engine 为空时恢复自动选择。重启后恢复为配置文件的 tts.selection.pin。
+func (p *Pipeline) handleTTSPin(w http.ResponseWriter, r *http.Request) {
+	if p.ttsSelector == nil {
+		admin.WriteError(w, http.StatusConflict, "未开启 tts.selection")
+		return
+	}
+	var req struct {
+		Engine string `json:"engine"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		admin.WriteError(w, http.StatusBadRequest, "请求格式错误")
+		return
+	}
+	if err := p.ttsSelector.Pin(req.Engine); err != nil {
+		admin.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	logger.Infof("[pipeline] TTS 固定引擎: %q", req.Engine)
+	p.handleTTSStatus(w, r)
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// speakDailyRecap 晚间语音小结，如"今天你听了47分钟音乐，问了6次天气"。
+// 只在空闲时播报，正在听音乐时不打断；当天没有使用记录时不播报。
+func (p *Pipeline) speakDailyRecap(ctx context.Context) {
+	if p.state.Current() != StateIdle {
+		logger.Debug("[pipeline] 设备正在使用，跳过今日小结")
+		return
+	}
+	counts, err := p.usage.Day(time.Now().Format("2006-01-02"))
+	if err != nil {
+		logger.Warnf("[pipeline] 读取使用统计失败: %v", err)
+		return
+	}
+	text := tools.SummarizeUsage("今天", counts)
+	if text == "" {
+		return
+	}
+	logger.Infof("[pipeline] 今日小结: %s", text)
+	p.announce(ctx, speechLow, text)
+}
@@ -0,0 +1,365 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+const (
+	enrollSamples        = 5               // 远程注册录制的样本数
+	enrollMinSamples     = 3               // 有效样本少于此数时注册失败
+	enrollSampleDuration = 4 * time.Second // 每个样本的录制时长
+)
+
+// EnrollStatus 远程声纹注册的进度。
+type EnrollStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"` // recording、registering、done、failed
+	Sample    int       `json:"sample"`
+	Total     int       `json:"total"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// enrollSession 一次远程声纹注册：设备依次提示并录制样本，录制期间麦克风帧不做唤醒检测。
+type enrollSession struct {
+	frames    chan []float32
+	recording atomic.Bool
+
+	mu     sync.Mutex
+	status EnrollStatus
+}
+
+// feed 录制期间接收麦克风帧，通道满时丢弃。
+func (e *enrollSession) feed(frame []float32) {
+	if !e.recording.Load() {
+		return
+	}
+	select {
+	case e.frames <- frame:
+	default:
+	}
+}
+
+// record 录制 d 时长的音频。
+func (e *enrollSession) record(ctx context.Context, d time.Duration) []float32 {
+	for len(e.frames) > 0 {
+		<-e.frames
+	}
+	e.recording.Store(true)
+	defer e.recording.Store(false)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	var all []float32
+	for {
+		select {
+		case <-ctx.Done():
+			return all
+		case <-timer.C:
+			return all
+		case frame := <-e.frames:
+			all = append(all, frame...)
+		}
+	}
+}
+
+func (e *enrollSession) setStatus(state string, sample int, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.State = state
+	e.status.Sample = sample
+	e.status.Message = message
+	e.status.UpdatedAt = time.Now()
+}
+
+func (e *enrollSession) Status() EnrollStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status
+}
+
+// activeEnrollment 返回正在进行的远程注册，没有时返回 nil。
+func (p *Pipeline) activeEnrollment() *enrollSession {
+	p.enrollMu.Lock()
+	defer p.enrollMu.Unlock()
+	if p.enroll == nil {
+		return nil
+	}
+	switch p.enroll.Status().State {
+	case "done", "failed":
+		return nil
+	}
+	return p.enroll
+}
+
+// startEnrollment 开始远程声纹注册，只能在空闲时进行。
+func (p *Pipeline) startEnrollment(name, preferences string) (*enrollSession, error) {
+	p.enrollMu.Lock()
+	defer p.enrollMu.Unlock()
+	if p.enroll != nil {
+		switch p.enroll.Status().State {
+		case "done", "failed":
+		default:
+			return nil, fmt.Errorf("正在为 %s 注册声纹", p.enroll.Status().Name)
+		}
+	}
+	if p.state.Current() != StateIdle || p.playback.Playing() {
+		return nil, fmt.Errorf("设备正忙，请稍后再试")
+	}
+
+	session := &enrollSession{
+		frames: make(chan []float32, 256),
+		status: EnrollStatus{Name: name, State: "recording", Total: enrollSamples, UpdatedAt: time.Now()},
+	}
+	p.enroll = session
+	go p.runEnrollment(context.Background(), session, name, preferences)
+	return session, nil
+}
+
+// runEnrollment 语音提示用户说话，录制样本后注册声纹。
+func (p *Pipeline) runEnrollment(ctx context.Context, session *enrollSession, name, preferences string) {
+	logger.Infof("[pipeline] 开始远程注册声纹: %s", name)
+	p.speakText(ctx, fmt.Sprintf("开始为%s录制声纹，听到提示音后请随便说几句话，一共%d次", name, enrollSamples))
+
+	var samples [][]float32
+	for i := 1; i <= enrollSamples; i++ {
+		if ctx.Err() != nil {
+			session.setStatus("failed", i, "已取消")
+			return
+		}
+		session.setStatus("recording", i, "")
+		p.playCue(ctx)
+		recorded := session.record(ctx, enrollSampleDuration)
+		if len(recorded) < p.cfg.Audio.SampleRate {
+			logger.Warnf("[pipeline] 第 %d 个样本录制数据不足，跳过", i)
+			continue
+		}
+		samples = append(samples, recorded)
+	}
+
+	if len(samples) < enrollMinSamples {
+		session.setStatus("failed", enrollSamples, "录制样本不足")
+		p.speakText(ctx, "录制的声音不够，声纹注册失败，请重试")
+		return
+	}
+
+	session.setStatus("registering", enrollSamples, "")
+	if err := p.voiceprintMgr.Register(name, samples); err != nil {
+		logger.Errorf("[pipeline] 远程注册声纹失败: %v", err)
+		session.setStatus("failed", enrollSamples, err.Error())
+		p.speakText(ctx, "声纹注册失败，请重试")
+		return
+	}
+	if preferences != "" {
+		if err := p.voiceprintMgr.SetPreferences(name, preferences); err != nil {
+			logger.Warnf("[pipeline] 设置偏好失败: %v", err)
+		}
+	}
+	// 与语音注册一致：第一个用户或配置的主人自动设为主人
+	if p.voiceprintMgr.NumSpeakers() == 1 || name == p.cfg.Voiceprint.OwnerName {
+		if err := p.voiceprintMgr.SetOwner(name); err != nil {
+			logger.Warnf("[pipeline] 设置主人失败: %v", err)
+		}
+	}
+
+	session.setStatus("done", enrollSamples, "")
+	logger.Infof("[pipeline] 远程注册声纹完成: %s (%d 个样本)", name, len(samples))
+	p.speakText(ctx, fmt.Sprintf("%s的声纹注册好了", name))
+}
+
+// userInfo 管理 API 返回的用户信息。
+type userInfo struct {
+	Name        string                      `json:"name"`
+	IsOwner     bool                        `json:"is_owner"`
+	Preferences *voiceprint.UserPreferences `json:"preferences,omitempty"`
+}
+
+// registerUserRoutes 注册声纹用户管理接口。
+func (p *Pipeline) registerUserRoutes() {
+	p.adminServer.Handle("GET /api/users", p.handleListUsers)
+	p.adminServer.HandleAuthRequired("PUT /api/users/{name}/preferences", p.handleSetPreferences)
+	p.adminServer.HandleAuthRequired("POST /api/users/{name}/owner", p.handleSetOwner)
+	p.adminServer.HandleAuthRequired("POST /api/users/{name}/enroll", p.handleEnroll)
+	p.adminServer.Handle("GET /api/users/enroll", p.handleEnrollStatus)
+}
+
+// handleListUsers 列出所有声纹用户及其偏好。
+func (p *Pipeline) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := p.voiceprintMgr.ListUsers()
+	if err != nil {
+		admin.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list := make([]userInfo, 0, len(users))
+	for _, u := range users {
+		info := userInfo{Name: u.Name, IsOwner: u.IsOwner()}
+		if prefs := u.GetPreferences(); prefs != "" {
+			var up voiceprint.UserPreferences
+			if err := json.Unmarshal([]byte(prefs), &up); err == nil {
+				info.Preferences = &up
+			}
+		}
+		list = append(list, info)
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"users":   list,
+	})
+}
+
+// maxUserNameRunes 远程注册的用户名最多多少字。
+const maxUserNameRunes = 20
+
+// validUserName 校验远程注册的用户名：不超过 maxUserNameRunes 个字，只能包含文字、数字、下划线和连字符。
+func validUserName(name string) error {
+	if name == "" {
+		return fmt.Errorf("用户名不能为空")
+	}
+	if utf8.RuneCountInString(name) > maxUserNameRunes {
+		return fmt.Errorf("用户名不能超过 %d 个字", maxUserNameRunes)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return fmt.Errorf("用户名只能包含文字、数字、下划线和连字符")
+		}
+	}
+	return nil
+}
+
+// enrollTarget 检查远程注册的目标用户：已注册的用户只有明确要求追加（append=true）时才继续录制，
+// 避免把别人的声音加进已有用户（如主人）的声纹。
+func enrollTarget(name string, existing *voiceprint.User, appendSamples bool) (status int, err error) {
+	if err := validUserName(name); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if existing != nil && !appendSamples {
+		return http.StatusConflict, fmt.Errorf("用户 %s 已注册，追加声纹样本请加参数 append=true", name)
+	}
+	return 0, nil
+}
+
+// readPreferences 读取并校验请求体中的偏好 JSON，合并到 current（用户已有的偏好 JSON）上，返回规范化后的 JSON。
+// 请求中没有出现的字段保持原值。
+func readPreferences(r *http.Request, current string) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("读取请求失败: %w", err)
+	}
+	var prefs voiceprint.UserPreferences
+	if current != "" {
+		if err := json.Unmarshal([]byte(current), &prefs); err != nil {
+			logger.Warnf("[pipeline] 已有偏好格式错误，将被覆盖: %v", err)
+			prefs = voiceprint.UserPreferences{}
+		}
+	}
+	if err := json.Unmarshal(body, &prefs); err != nil {
+		return "", fmt.Errorf("偏好格式错误: %w", err)
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// handleSetPreferences 修改用户偏好，请求体为偏好 JSON（style、nickname、interests、extra、home_city 等），
+// 只修改请求中出现的字段。
+func (p *Pipeline) handleSetPreferences(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	user, err := p.voiceprintMgr.GetUser(name)
+	if err != nil {
+		admin.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		admin.WriteError(w, http.StatusNotFound, fmt.Sprintf("用户 %s 不存在", name))
+		return
+	}
+	prefs, err := readPreferences(r, user.Preferences)
+	if err != nil {
+		admin.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := p.voiceprintMgr.SetPreferences(name, prefs); err != nil {
+		admin.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	logger.Infof("[pipeline] 通过管理 API 修改了 %s 的偏好", name)
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+// handleSetOwner 设置主人。
+func (p *Pipeline) handleSetOwner(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := p.voiceprintMgr.SetOwner(name); err != nil {
+		admin.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	logger.Infof("[pipeline] 通过管理 API 将 %s 设为主人", name)
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+}
+
+// handleEnroll 让设备开始录制并注册声纹，请求体可选，为该用户的偏好 JSON。
+// 用户已注册时需加参数 append=true 才会追加样本。注册在后台进行，进度通过 GET /api/users/enroll 查询。
+func (p *Pipeline) handleEnroll(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	appendSamples, _ := strconv.ParseBool(r.URL.Query().Get("append"))
+	existing, err := p.voiceprintMgr.GetUser(name)
+	if err != nil {
+		admin.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if status, err := enrollTarget(name, existing, appendSamples); err != nil {
+		admin.WriteError(w, status, err.Error())
+		return
+	}
+	var prefs string
+	if r.ContentLength != 0 {
+		var current string
+		if existing != nil {
+			current = existing.Preferences
+		}
+		if prefs, err = readPreferences(r, current); err != nil {
+			admin.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	session, err := p.startEnrollment(name, prefs)
+	if err != nil {
+		admin.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	admin.WriteJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"status":  session.Status(),
+	})
+}
+
+// handleEnrollStatus 返回最近一次远程注册的进度。
+func (p *Pipeline) handleEnrollStatus(w http.ResponseWriter, r *http.Request) {
+	p.enrollMu.Lock()
+	session := p.enroll
+	p.enrollMu.Unlock()
+	if session == nil {
+		admin.WriteError(w, http.StatusNotFound, "没有进行中的声纹注册")
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"status":  session.Status(),
+	})
+}
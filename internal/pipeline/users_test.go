@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+func TestEnrollSession_Record(t *testing.T) {
+	e := &enrollSession{frames: make(chan []float32, 16)}
+
+	// 未录制时的帧直接丢弃
+	e.feed([]float32{1, 1})
+	if len(e.frames) != 0 {
+		t.Fatal("未录制时不应接收帧")
+	}
+
+	done := make(chan []float32)
+	go func() { done <- e.record(context.Background(), 200*time.Millisecond) }()
+	for !e.recording.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	e.feed([]float32{1, 2})
+	e.feed([]float32{3})
+
+	got := <-done
+	if len(got) != 3 {
+		t.Errorf("录制了 %d 个采样点，want 3", len(got))
+	}
+	if e.recording.Load() {
+		t.Error("录制结束后应停止接收帧")
+	}
+}
+
+func TestReadPreferences(t *testing.T) {
+	r := httptest.NewRequest("PUT", "/api/users/a/preferences", strings.NewReader(`{"nickname":"小明","interests":["画画"],"unknown":1}`))
+	prefs, err := readPreferences(r, "")
+	if err != nil {
+		t.Fatalf("readPreferences failed: %v", err)
+	}
+	if prefs != `{"interests":["画画"],"nickname":"小明"}` {
+		t.Errorf("prefs = %s", prefs)
+	}
+
+	r = httptest.NewRequest("PUT", "/api/users/a/preferences", strings.NewReader(`not json`))
+	if _, err := readPreferences(r, ""); err == nil {
+		t.Error("非法 JSON 应返回错误")
+	}
+
+	// 只修改请求中出现的字段，其他字段保持原值
+	r = httptest.NewRequest("PUT", "/api/users/a/preferences", strings.NewReader(`{"home_city":"武汉"}`))
+	prefs, err = readPreferences(r, `{"interests":["画画"],"nickname":"小明"}`)
+	if err != nil {
+		t.Fatalf("readPreferences failed: %v", err)
+	}
+	if prefs != `{"interests":["画画"],"nickname":"小明","home_city":"武汉"}` {
+		t.Errorf("partial update should keep other fields, prefs = %s", prefs)
+	}
+}
+
+func TestEnrollTarget(t *testing.T) {
+	owner := &voiceprint.User{Name: "爸爸"}
+	tests := []struct {
+		name     string
+		existing *voiceprint.User
+		append   bool
+		want     int
+	}{
+		{"小红", nil, false, 0},
+		{"爸爸", owner, false, http.StatusConflict},
+		{"爸爸", owner, true, 0},
+		{"", nil, false, http.StatusBadRequest},
+		{"../etc", nil, false, http.StatusBadRequest},
+		{"a b", nil, false, http.StatusBadRequest},
+		{strings.Repeat("长", maxUserNameRunes+1), nil, false, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		status, err := enrollTarget(tt.name, tt.existing, tt.append)
+		if status != tt.want || (err != nil) != (tt.want != 0) {
+			t.Errorf("enrollTarget(%q, append=%v) = %d, %v, want %d", tt.name, tt.append, status, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// 二次确认唤醒（dialog.wake_confirm）：嘈杂环境（尤其是放音乐时）容易误唤醒。检测到唤醒词后先不打断，
+// 把音乐压低并响一声很短的提示音，提示音后 window 毫秒内听到说话才真正唤醒，否则恢复音量、悄悄回到原来的状态。
+// 等待期间的音频会补给语音识别，用户听到提示音直接说指令即可。
+
+const (
+	wakeConfirmDuck   = 30                     // 等待确认时音乐压低到的音量（百分比）
+	wakeConfirmSpeech = 200 * time.Millisecond // 累计听到这么长的说话才算确认
+)
+
+// wakeConfirmCue 确认提示音：cueSamples 的第一个音（约 80ms）。
+var wakeConfirmCue = cueSamples[:16000*80/1000]
+
+// wakeConfirmResult 等待确认的结果。
+type wakeConfirmResult int
+
+const (
+	wakeConfirmWaiting wakeConfirmResult = iota
+	wakeConfirmConfirmed
+	wakeConfirmRejected
+)
+
+// wakeConfirm 一次等待确认的唤醒，只在音频处理 goroutine 中访问。
+type wakeConfirm struct {
+	start    time.Time // 提示音播完，开始等待说话
+	deadline time.Time
+	voiced   int       // 听到说话的样本数
+	frames   []float32 // 提示音后的音频，确认后补给语音识别
+	ducked   bool      // 是否压低了音乐
+}
+
+// feed 处理一帧音频，speech 为 VAD 是否判断为说话。
+func (c *wakeConfirm) feed(frame []float32, speech bool, now time.Time, sampleRate int) wakeConfirmResult {
+	if now.Before(c.start) {
+		return wakeConfirmWaiting // 提示音还在播放，避免把提示音当成说话
+	}
+	c.frames = append(c.frames, frame...)
+	if speech {
+		c.voiced += len(frame)
+	}
+	if c.voiced >= int(wakeConfirmSpeech.Seconds()*float64(sampleRate)) {
+		return wakeConfirmConfirmed
+	}
+	if now.After(c.deadline) {
+		return wakeConfirmRejected
+	}
+	return wakeConfirmWaiting
+}
+
+// needWakeConfirm 判断这次唤醒是否需要二次确认。
+func (p *Pipeline) needWakeConfirm() bool {
+	cfg := p.cfg.Dialog.WakeConfirm
+	if !cfg.Enabled {
+		return false
+	}
+	return !cfg.MusicOnly || (p.playback != nil && p.playback.Playing())
+}
+
+// startWakeConfirm 检测到唤醒词后压低音乐、响提示音，开始等待用户开口。
+func (p *Pipeline) startWakeConfirm(ctx context.Context) {
+	p.wakeCooldownMu.Lock()
+	p.wakeCooldown = true
+	p.wakeCooldownMu.Unlock()
+	p.wakeDetector.Reset()
+	p.vadDetector.Reset()
+
+	c := &wakeConfirm{}
+	if p.playback != nil && p.playback.Playing() {
+		p.playback.Duck(wakeConfirmDuck)
+		c.ducked = true
+	}
+	cueDuration := time.Duration(len(wakeConfirmCue)) * time.Second / 16000
+	c.start = time.Now().Add(cueDuration)
+	c.deadline = c.start.Add(time.Duration(p.cfg.Dialog.WakeConfirm.Window) * time.Millisecond)
+	p.pendingWake = c
+
+	logger.Info("[pipeline] 检测到唤醒词，等待确认")
+	go p.playSamples(ctx, wakeConfirmCue, 16000)
+}
+
+// handleWakeConfirm 等待确认期间处理音频：听到说话开始聆听，超时按误唤醒处理。
+func (p *Pipeline) handleWakeConfirm(ctx context.Context, frame []float32) {
+	c := p.pendingWake
+	p.vadDetector.Feed(frame)
+	switch c.feed(frame, p.vadDetector.IsSpeech(), time.Now(), p.cfg.Audio.SampleRate) {
+	case wakeConfirmWaiting:
+		return
+	case wakeConfirmRejected:
+		p.pendingWake = nil
+		if c.ducked {
+			p.playback.Duck(100)
+		}
+		p.vadDetector.Reset()
+		p.wakeDetector.Reset()
+		logger.Info("[pipeline] 提示音后没有听到说话，按误唤醒处理")
+		time.AfterFunc(300*time.Millisecond, p.clearWakeCooldown)
+	case wakeConfirmConfirmed:
+		p.pendingWake = nil
+		logger.Info("[pipeline] 唤醒已确认，开始聆听")
+		p.listenAfterConfirm(c)
+	}
+}
+
+// listenAfterConfirm 确认唤醒后进入聆听：播放中唤醒时与打断一样停止播放，再补入提示音后的音频。
+func (p *Pipeline) listenAfterConfirm(c *wakeConfirm) {
+	p.usage.Add(tools.UsageWake, 1)
+	p.latency.markWake()
+	p.ackReminder()
+	if p.state.Current() == StateSpeaking {
+		p.stopCurrentTurn()
+	} else {
+		p.clearGuestContext()
+	}
+	if c.ducked {
+		// 音乐已暂停，恢复音量，继续播放时不会太小声
+		p.playback.Duck(100)
+	}
+
+	if p.voiceprintMgr != nil && p.voiceprintMgr.NumSpeakers() > 0 {
+		p.voiceprintBufMu.Lock()
+		p.voiceprintBuf = make([]float32, 0, p.voiceprintBufSize)
+		p.voiceprintBuf = append(p.voiceprintBuf, c.frames[:min(len(c.frames), p.voiceprintBufSize)]...)
+		p.voiceprintBufMu.Unlock()
+	}
+
+	p.vadDetector.Reset()
+	p.resetRecognizer()
+	p.state.SetState(StateListening)
+	p.vadDetector.Feed(c.frames)
+	p.recognizer.Feed(c.frames)
+	p.chargeASRBudget(len(c.frames))
+
+	if p.continuousTimeout() > 0 {
+		p.startContinuousTimer()
+	}
+	time.AfterFunc(300*time.Millisecond, p.clearWakeCooldown)
+}
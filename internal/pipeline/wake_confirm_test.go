@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWakeConfirmFeed(t *testing.T) {
+	const sampleRate = 16000
+	frame := make([]float32, sampleRate/50) // 20ms
+	start := time.Now()
+
+	c := &wakeConfirm{start: start.Add(80 * time.Millisecond), deadline: start.Add(1580 * time.Millisecond)}
+	// 提示音播放期间的音频不算说话，也不补给识别
+	if r := c.feed(frame, true, start, sampleRate); r != wakeConfirmWaiting || len(c.frames) != 0 {
+		t.Fatalf("frames during the cue should be ignored, got %v with %d samples", r, len(c.frames))
+	}
+
+	now := start.Add(100 * time.Millisecond)
+	for i := 0; i < 9; i++ {
+		if r := c.feed(frame, true, now, sampleRate); r != wakeConfirmWaiting {
+			t.Fatalf("frame %d: got %v before 200ms of speech", i, r)
+		}
+		now = now.Add(20 * time.Millisecond)
+	}
+	if r := c.feed(frame, true, now, sampleRate); r != wakeConfirmConfirmed {
+		t.Errorf("200ms of speech should confirm, got %v", r)
+	}
+	if len(c.frames) != 10*len(frame) {
+		t.Errorf("buffered %d samples, want %d", len(c.frames), 10*len(frame))
+	}
+
+	// 只有背景声音、没有说话时超时按误唤醒处理
+	c = &wakeConfirm{start: start, deadline: start.Add(1500 * time.Millisecond)}
+	if r := c.feed(frame, false, start.Add(time.Second), sampleRate); r != wakeConfirmWaiting {
+		t.Errorf("should keep waiting before the deadline, got %v", r)
+	}
+	if r := c.feed(frame, false, start.Add(1600*time.Millisecond), sampleRate); r != wakeConfirmRejected {
+		t.Errorf("no speech before the deadline should reject, got %v", r)
+	}
+}
+
+// 确认唤醒时正在回复，与打断一样取消正在进行的大模型和工具调用，旧的回复不会在新一轮对话中播出。
+func TestStopCurrentTurnCancelsQuery(t *testing.T) {
+	queryCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := &Pipeline{playback: NewPlaybackManager(newFakePlayer()), cancelQuery: cancel}
+
+	p.stopCurrentTurn()
+	if queryCtx.Err() == nil {
+		t.Error("stopCurrentTurn should cancel the running query")
+	}
+	if !p.interrupted.Load() {
+		t.Error("stopCurrentTurn should set the interrupted flag")
+	}
+}
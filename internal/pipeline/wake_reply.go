@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/tools"
+	"github.com/iabetor/pibuddy/internal/voiceprint"
+)
+
+// 唤醒回复语变体：wake_replies 随机选一条，timed_wake_replies 按时段代替，含 {name} 的变体在最近识别到
+// 说话人时优先使用。唤醒时还不知道是谁在说话，沿用 wakeSpeakerWindow 内最近识别到的说话人。
+
+// wakeSpeakerWindow 最近识别到的说话人在此时间内唤醒时，回复语可以带上昵称。
+const wakeSpeakerWindow = 5 * time.Minute
+
+// chooseWakeReply 选择一条唤醒回复语。fallback 为 wake_reply，为空时不播放；name 为最近识别到的说话人昵称。
+func chooseWakeReply(fallback string, variants []string, timed []config.TimedWakeReply, now time.Time, name string, rng *rand.Rand) string {
+	if fallback == "" {
+		return ""
+	}
+	pool := variants
+	for _, t := range timed {
+		if len(t.Replies) > 0 && tools.InQuietHours(now, t.Start, t.End) {
+			pool = t.Replies
+			break
+		}
+	}
+
+	var personal, general []string
+	for _, reply := range pool {
+		if strings.Contains(reply, "{name}") {
+			personal = append(personal, reply)
+		} else if reply != "" {
+			general = append(general, reply)
+		}
+	}
+	switch {
+	case name != "" && len(personal) > 0:
+		return strings.ReplaceAll(personal[rng.Intn(len(personal))], "{name}", name)
+	case len(general) > 0:
+		return general[rng.Intn(len(general))]
+	}
+	return fallback
+}
+
+// rememberWakeSpeaker 记下最近识别到的说话人，下次唤醒时回复语可以带上昵称。
+func (p *Pipeline) rememberWakeSpeaker(name string) {
+	p.langMu.Lock()
+	defer p.langMu.Unlock()
+	p.wakeSpeaker, p.wakeSpeakerAt = name, time.Now()
+}
+
+// recentWakeSpeaker 返回 wakeSpeakerWindow 内识别到的说话人昵称，没有时返回空。
+func (p *Pipeline) recentWakeSpeaker() string {
+	p.langMu.Lock()
+	name, at := p.wakeSpeaker, p.wakeSpeakerAt
+	p.langMu.Unlock()
+	if name == "" || time.Since(at) > wakeSpeakerWindow {
+		return ""
+	}
+	return p.userNickname(name)
+}
+
+// userNickname 声纹用户偏好中的昵称，没有设置时返回用户名。
+func (p *Pipeline) userNickname(name string) string {
+	if p.voiceprintMgr == nil {
+		return name
+	}
+	if user, err := p.voiceprintMgr.GetUser(name); err == nil && user.Preferences != "" {
+		var prefs voiceprint.UserPreferences
+		if json.Unmarshal([]byte(user.Preferences), &prefs) == nil && prefs.Nickname != "" {
+			return prefs.Nickname
+		}
+	}
+	return name
+}
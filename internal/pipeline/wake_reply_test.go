@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func TestChooseWakeReply(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	morning := time.Date(2026, 5, 1, 7, 30, 0, 0, time.Local)
+	noon := time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local)
+	variants := []string{"我在", "在呢", "{name}，我在"}
+	timed := []config.TimedWakeReply{{Start: "05:00", End: "10:00", Replies: []string{"早上好", "{name}，早上好"}}}
+
+	if got := chooseWakeReply("", variants, timed, noon, "小明", rng); got != "" {
+		t.Errorf("empty wake_reply should disable replies, got %q", got)
+	}
+	if got := chooseWakeReply("我在", nil, nil, noon, "小明", rng); got != "我在" {
+		t.Errorf("without variants got %q", got)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		seen[chooseWakeReply("我在", variants, timed, noon, "", rng)] = true
+	}
+	if len(seen) != 2 || !seen["我在"] || !seen["在呢"] {
+		t.Errorf("unknown speaker should pick from general variants, got %v", seen)
+	}
+
+	if got := chooseWakeReply("我在", variants, timed, noon, "小明", rng); got != "小明，我在" {
+		t.Errorf("known speaker should get the personal variant, got %q", got)
+	}
+	if got := chooseWakeReply("我在", variants, timed, morning, "小明", rng); got != "小明，早上好" {
+		t.Errorf("morning reply = %q", got)
+	}
+	if got := chooseWakeReply("我在", variants, timed, morning, "", rng); got != "早上好" {
+		t.Errorf("morning reply without speaker = %q", got)
+	}
+}
+
+func TestLocalizedWakeReplyUsesVoiceSetting(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Dialog.WakeReply = "我在"
+	cfg.Dialog.WakeReplies = []string{"在呢", "来啦"}
+	cfg.Dialog.TimedWakeReplies = []config.TimedWakeReply{{Start: "00:00", End: "23:59", Replies: []string{"你好"}}}
+	settings := newTestSettings(t)
+	p := &Pipeline{cfg: cfg, settings: settings}
+
+	if got := p.localizedWakeReply(); got == "我在" {
+		t.Errorf("没有语音设置时应从变体中选择, got %q", got)
+	}
+
+	// 用语音设置过回复语后，即使配置中仍有变体（例如重新加载了配置文件）也只使用设置的回复语
+	settings.SetString(database.SettingWakeReply, "主人请吩咐")
+	for i := 0; i < 10; i++ {
+		if got := p.localizedWakeReply(); got != "主人请吩咐" {
+			t.Fatalf("应使用语音设置的唤醒回复语, got %q", got)
+		}
+	}
+	settings.SetString(database.SettingWakeReply, "")
+	if got := p.localizedWakeReply(); got != "" {
+		t.Errorf("语音设置为空时不应播放回复语, got %q", got)
+	}
+}
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+
+	"github.com/iabetor/pibuddy/internal/admin"
+	"github.com/iabetor/pibuddy/internal/qrcode"
+	"github.com/iabetor/pibuddy/internal/tools"
+)
+
+// guestWiFiConfig 配置中的访客 Wi-Fi 信息。
+func (p *Pipeline) guestWiFiConfig() tools.GuestWiFiConfig {
+	c := p.cfg.Tools.GuestWiFi
+	return tools.GuestWiFiConfig{SSID: c.SSID, Password: c.Password, Security: c.Security, Hidden: c.Hidden}
+}
+
+// adminPageURL 管理 API 上某个页面在局域网内的地址，监听所有网卡时使用本机局域网 IP。
+func adminPageURL(listen, path string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = localIP()
+	}
+	return fmt.Sprintf("http://%s%s", net.JoinHostPort(host, port), path)
+}
+
+// localIP 获取本机局域网 IP。
+func localIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "localhost"
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String()
+		}
+	}
+	return "localhost"
+}
+
+// registerWiFiRoutes 注册访客 Wi-Fi 页面和接口。
+func (p *Pipeline) registerWiFiRoutes() {
+	p.adminServer.HandleAuthRequired("GET /wifi", p.handleWiFiPage)
+	p.adminServer.HandleAuthRequired("GET /api/wifi", p.handleWiFiInfo)
+}
+
+// handleWiFiInfo 返回访客 Wi-Fi 名称、密码和二维码内容。
+func (p *Pipeline) handleWiFiInfo(w http.ResponseWriter, r *http.Request) {
+	wifi := p.guestWiFiConfig()
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"ssid":     wifi.SSID,
+		"password": wifi.Password,
+		"qr":       wifi.QRPayload(),
+	})
+}
+
+// handleWiFiPage 显示访客 Wi-Fi 名称、密码和扫码加入的二维码。
+func (p *Pipeline) handleWiFiPage(w http.ResponseWriter, r *http.Request) {
+	wifi := p.guestWiFiConfig()
+	code, err := qrcode.Encode(wifi.QRPayload())
+	if err != nil {
+		admin.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	wifiPage.Execute(w, map[string]interface{}{
+		"SSID":     wifi.SSID,
+		"Password": wifi.Password,
+		"Open":     wifi.Security == "nopass",
+		"QR":       template.HTML(code.SVG()),
+	})
+}
+
+// wifiPage 访客 Wi-Fi 页面。
+var wifiPage = template.Must(template.New("wifi").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>访客 Wi-Fi - PiBuddy</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f5f5f7;
+         display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; padding: 20px; box-sizing: border-box; }
+  .card { background: white; border-radius: 20px; padding: 32px 28px; text-align: center;
+          box-shadow: 0 10px 40px rgba(0,0,0,0.1); max-width: 360px; width: 100%; }
+  h1 { font-size: 22px; color: #333; margin: 0 0 6px; }
+  .hint { font-size: 14px; color: #999; margin-bottom: 20px; }
+  .qr svg { width: 260px; height: 260px; }
+  .row { font-size: 18px; color: #333; margin-top: 12px; word-break: break-all; }
+  .label { color: #999; font-size: 14px; margin-right: 6px; }
+  .password { font-family: Menlo, Consolas, monospace; }
+</style>
+</head>
+<body>
+<div class="card">
+  <h1>访客 Wi-Fi</h1>
+  <div class="hint">用手机相机扫码即可连接</div>
+  <div class="qr">{{.QR}}</div>
+  <div class="row"><span class="label">名称</span>{{.SSID}}</div>
+  {{if .Open}}<div class="row"><span class="label">密码</span>无</div>{{else}}<div class="row"><span class="label">密码</span><span class="password">{{.Password}}</span></div>{{end}}
+</div>
+</body>
+</html>
+`))
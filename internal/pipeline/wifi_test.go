@@ -0,0 +1,15 @@
+package pipeline
+
+import "testing"
+
+func TestAdminPageURL(t *testing.T) {
+	if got := adminPageURL("192.168.1.5:8090", "/wifi"); got != "http://192.168.1.5:8090/wifi" {
+		t.Errorf("adminPageURL = %q", got)
+	}
+	if got := adminPageURL(":8090", "/wifi"); got != "http://"+localIP()+":8090/wifi" {
+		t.Errorf("adminPageURL = %q", got)
+	}
+	if got := adminPageURL("bad", "/wifi"); got != "" {
+		t.Errorf("无效的监听地址应返回空, got %q", got)
+	}
+}
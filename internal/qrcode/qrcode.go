@@ -0,0 +1,451 @@
+// Package qrcode 生成二维码（字节模式，纠错等级 M，版本 1-10，最多 213 字节），
+// 输出 SVG，用于在管理页面上显示访客 Wi-Fi 等二维码，不依赖第三方库。
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooLong 内容超过版本 10 的容量。
+var ErrTooLong = errors.New("二维码内容过长")
+
+// versionInfo 纠错等级 M 下各版本的分块信息。
+type versionInfo struct {
+	eccPerBlock int   // 每块的纠错码字数
+	blocks      []int // 每块的数据码字数，短块在前
+}
+
+var versions = [...]versionInfo{
+	1:  {10, []int{16}},
+	2:  {16, []int{28}},
+	3:  {26, []int{44}},
+	4:  {18, []int{32, 32}},
+	5:  {24, []int{43, 43}},
+	6:  {16, []int{27, 27, 27, 27}},
+	7:  {18, []int{31, 31, 31, 31}},
+	8:  {22, []int{38, 38, 39, 39}},
+	9:  {22, []int{36, 36, 36, 37, 37}},
+	10: {26, []int{43, 43, 43, 43, 44}},
+}
+
+// alignmentPositions 各版本校正图形的中心坐标。
+var alignmentPositions = [...][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+// Code 生成的二维码。
+type Code struct {
+	Version int
+	Size    int // 每边的模块数
+	modules [][]bool
+	isFunc  [][]bool // 定位、时序、校正和格式信息等功能图形，不参与掩码
+}
+
+// Dark 返回 (x, y) 处是否为深色模块。
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode 以字节模式编码 text，自动选择能容纳内容的最小版本。
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v < len(versions); v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*dataCodewords(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d 字节", ErrTooLong, len(data))
+	}
+
+	size := version*4 + 17
+	c := &Code{Version: version, Size: size, modules: newGrid(size), isFunc: newGrid(size)}
+	c.drawFunctionPatterns()
+	c.drawCodewords(addECC(version, encodeData(version, data)))
+
+	// 选择惩罚分最低的掩码
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // 再异或一次即撤销
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	return c, nil
+}
+
+// SVG 渲染为 SVG，四周留出 4 个模块的空白。
+func (c *Code) SVG() string {
+	const quiet = 4
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+quiet, y+quiet)
+			}
+		}
+	}
+	n := c.Size + quiet*2
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path d="%s" fill="#000"/></svg>`, n, n, n, n, path.String())
+}
+
+func newGrid(size int) [][]bool {
+	grid := make([][]bool, size)
+	for i := range grid {
+		grid[i] = make([]bool, size)
+	}
+	return grid
+}
+
+func dataCodewords(version int) int {
+	total := 0
+	for _, n := range versions[version].blocks {
+		total += n
+	}
+	return total
+}
+
+// encodeData 生成数据码字：模式指示符、字符计数、数据、终止符和填充。
+func encodeData(version int, data []byte) []byte {
+	var bits []bool
+	appendBits := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, (v>>i)&1 == 1)
+		}
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	appendBits(0b0100, 4) // 字节模式
+	appendBits(len(data), countBits)
+	for _, b := range data {
+		appendBits(int(b), 8)
+	}
+
+	capacity := 8 * dataCodewords(version)
+	appendBits(0, min(4, capacity-len(bits)))
+	appendBits(0, (8-len(bits)%8)%8)
+
+	result := make([]byte, 0, capacity/8)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		result = append(result, b)
+	}
+	for pad := byte(0xEC); len(result) < capacity/8; pad ^= 0xEC ^ 0x11 {
+		result = append(result, pad)
+	}
+	return result
+}
+
+// addECC 分块计算纠错码并交错排列。
+func addECC(version int, data []byte) []byte {
+	info := versions[version]
+	divisor := rsDivisor(info.eccPerBlock)
+
+	var dataBlocks, eccBlocks [][]byte
+	offset := 0
+	for _, n := range info.blocks {
+		block := data[offset : offset+n]
+		offset += n
+		dataBlocks = append(dataBlocks, block)
+		eccBlocks = append(eccBlocks, rsRemainder(block, divisor))
+	}
+
+	var result []byte
+	longest := info.blocks[len(info.blocks)-1]
+	for i := 0; i < longest; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.eccPerBlock; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// gfMultiply GF(2^8) 乘法，本原多项式 0x11D。
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor Reed-Solomon 生成多项式（不含最高次项的系数）。
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder 计算 data 的纠错码字。
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+func (c *Code) setFunc(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunc[y][x] = true
+}
+
+// drawFunctionPatterns 绘制定位、时序、校正图形和版本信息，并为格式信息占位。
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunc(6, i, i%2 == 0)
+		c.setFunc(i, 6, i%2 == 0)
+	}
+
+	for _, p := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := p[0]+dx, p[1]+dy
+				if x < 0 || x >= c.Size || y < 0 || y >= c.Size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				c.setFunc(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	if c.Version >= 2 {
+		pos := alignmentPositions[c.Version]
+		last := len(pos) - 1
+		for i := range pos {
+			for j := range pos {
+				// 与定位图形重叠的三个角跳过
+				if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+					continue
+				}
+				for dy := -2; dy <= 2; dy++ {
+					for dx := -2; dx <= 2; dx++ {
+						c.setFunc(pos[i]+dx, pos[j]+dy, max(abs(dx), abs(dy)) != 1)
+					}
+				}
+			}
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// formatBits 纠错等级 M（00）与掩码的 15 位格式信息。
+func formatBits(mask int) int {
+	data := mask // 纠错等级 M 的指示符为 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits 在两处写入格式信息。
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunc(8, i, bit(i))
+	}
+	c.setFunc(8, 7, bit(6))
+	c.setFunc(8, 8, bit(7))
+	c.setFunc(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunc(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunc(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunc(8, c.Size-15+i, bit(i))
+	}
+	c.setFunc(8, c.Size-8, true) // 固定的深色模块
+}
+
+// versionBits 18 位版本信息（版本 7 及以上）。
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawVersion 版本 7 及以上在右上角和左下角写入版本信息。
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, b := c.Size-11+i%3, i/3
+		c.setFunc(a, b, dark)
+		c.setFunc(b, a, dark)
+	}
+}
+
+// drawCodewords 按之字形从右下角开始填入码字，跳过功能图形。
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // 跳过竖直时序图形所在的列
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.isFunc[y][x] && i < len(data)*8 {
+					c.modules[y][x] = (data[i>>3]>>(7-i&7))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask 对数据区域异或掩码，重复调用可撤销。
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunc[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			c.modules[y][x] = c.modules[y][x] != invert
+		}
+	}
+}
+
+// penalty 评估掩码效果：同色连续模块、2x2 同色块和深浅比例（省略了类定位图形规则，不影响识别）。
+func (c *Code) penalty() int {
+	result := 0
+	for i := 0; i < c.Size; i++ {
+		runRow, runCol := 1, 1
+		for j := 1; j < c.Size; j++ {
+			if c.modules[i][j] == c.modules[i][j-1] {
+				runRow++
+			} else {
+				runRow = 1
+			}
+			if runRow == 5 {
+				result += 3
+			} else if runRow > 5 {
+				result++
+			}
+			if c.modules[j][i] == c.modules[j-1][i] {
+				runCol++
+			} else {
+				runCol = 1
+			}
+			if runCol == 5 {
+				result += 3
+			} else if runCol > 5 {
+				result++
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				v := c.modules[y][x]
+				if v == c.modules[y][x+1] && v == c.modules[y+1][x] && v == c.modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10) + total - 1) / total
+	return result + (k-1)*10
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
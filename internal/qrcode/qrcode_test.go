@@ -0,0 +1,111 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" 1-M 的数据码字及其纠错码字
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	if got := formatBits(0); got != 0b101010000010010 {
+		t.Errorf("formatBits(0) = %015b", got)
+	}
+	if got := formatBits(7); got != 0b100101010100000 {
+		t.Errorf("formatBits(7) = %015b", got)
+	}
+	if got := versionBits(7); got != 0x07C94 {
+		t.Errorf("versionBits(7) = %#x", got)
+	}
+}
+
+// readCodewords 按格式信息撤销掩码后读回码字，用于校验编码结果。
+func readCodewords(c *Code) []byte {
+	var bits int
+	for i := 0; i <= 5; i++ {
+		if c.Dark(8, i) {
+			bits |= 1 << i
+		}
+	}
+	if c.Dark(8, 7) {
+		bits |= 1 << 6
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m)&0x7F == bits {
+			mask = m
+		}
+	}
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+
+	var data []byte
+	var cur byte
+	n := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if c.isFunc[y][right-j] {
+					continue
+				}
+				cur <<= 1
+				if c.modules[y][right-j] {
+					cur |= 1
+				}
+				if n++; n%8 == 0 {
+					data = append(data, cur)
+				}
+			}
+		}
+	}
+	return data
+}
+
+func TestEncode(t *testing.T) {
+	for _, text := range []string{
+		"WIFI:T:WPA;S:PiBuddy-Guest;P:welcome2024;;",
+		strings.Repeat("访客网络", 10),
+		strings.Repeat("x", 200),
+	} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode(%d 字节) failed: %v", len(text), err)
+		}
+		want := addECC(c.Version, encodeData(c.Version, []byte(text)))
+		got := readCodewords(c)
+		if !bytes.Equal(got[:len(want)], want) {
+			t.Errorf("版本 %d 读回的码字与编码不一致", c.Version)
+		}
+		// 三个定位图形的中心都是深色，外圈第二层是浅色
+		for _, p := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+			if !c.Dark(p[0], p[1]) || c.Dark(p[0]+2, p[1]) {
+				t.Errorf("版本 %d 定位图形错误", c.Version)
+			}
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", 300)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("超长内容应返回 ErrTooLong, got %v", err)
+	}
+	c, _ := Encode("hi")
+	if c.Version != 1 || c.Size != 21 || !strings.HasPrefix(c.SVG(), "<svg") {
+		t.Errorf("version = %d, size = %d", c.Version, c.Size)
+	}
+}
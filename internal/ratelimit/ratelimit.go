@@ -0,0 +1,254 @@
+// Package ratelimit 统一的外部 API 限流与退避：按 host 分配请求预算，
+// 服务端限流（429/503）和重试时使用带抖动的指数退避，避免频繁请求非官方音乐 API 被封。
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// Budget 单个 host 的请求预算（令牌桶）。Rate <= 0 表示不限流。
+type Budget struct {
+	Rate  float64 `yaml:"rate"`  // 每秒平均请求数
+	Burst int     `yaml:"burst"` // 允许的突发请求数，默认与 Rate 相同（至少 1）
+}
+
+// Config 限流配置。
+type Config struct {
+	Default     Budget            // 未单独配置的 host 使用的预算
+	Hosts       map[string]Budget // 按 host（如 "localhost:3300"、"api.deepseek.com"）单独配置
+	MaxRetries  int               // 服务端返回 429/503 时最多重试几次，0 表示不重试
+	BackoffBase time.Duration     // 第一次退避的时长
+	BackoffMax  time.Duration     // 退避时长上限
+}
+
+// bucket 单个 host 的令牌桶和退避状态。
+type bucket struct {
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time // 服务端限流后暂停请求到此时间
+}
+
+// Limiter 按 host 限流，所有使用它的客户端共享同一份预算。
+type Limiter struct {
+	mu      sync.Mutex
+	cfg     Config
+	buckets map[string]*bucket
+	rnd     *rand.Rand
+}
+
+// New 创建限流器。
+func New(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		buckets: make(map[string]*bucket),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Configure 更新限流配置，已有的令牌桶按新预算继续计算。
+func (l *Limiter) Configure(cfg Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+}
+
+// budgetLocked 返回 host 的预算。
+func (l *Limiter) budgetLocked(host string) Budget {
+	b, ok := l.cfg.Hosts[host]
+	if !ok {
+		b = l.cfg.Default
+	}
+	if b.Burst <= 0 {
+		b.Burst = max(1, int(b.Rate))
+	}
+	return b
+}
+
+// reserve 占用 host 的一次请求预算，返回需要等待的时长（0 表示可以立即请求）。
+func (l *Limiter) reserve(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	budget := l.budgetLocked(host)
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(budget.Burst), last: now}
+		l.buckets[host] = b
+	}
+
+	var wait time.Duration
+	if now.Before(b.blockedUntil) {
+		wait = b.blockedUntil.Sub(now)
+	}
+	if budget.Rate <= 0 {
+		return wait
+	}
+
+	// 补充令牌，再扣除本次请求（可以为负，表示需要等待补充）
+	b.tokens += now.Sub(b.last).Seconds() * budget.Rate
+	if b.tokens > float64(budget.Burst) {
+		b.tokens = float64(budget.Burst)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens < 0 {
+		wait = max(wait, time.Duration(-b.tokens/budget.Rate*float64(time.Second)))
+	}
+	return wait
+}
+
+// Wait 等到 host 有请求预算，ctx 取消时返回错误。
+func (l *Limiter) Wait(ctx context.Context, host string) error {
+	wait := l.reserve(host, time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	logger.Debugf("[ratelimit] %s 请求过于频繁，等待 %s", host, wait.Round(time.Millisecond))
+	return sleep(ctx, wait)
+}
+
+// Penalize 服务端限流后暂停 host 的请求 d 时长，所有共享该限流器的客户端都会等待。
+// d 不超过退避时长上限，避免一个异常的 Retry-After 让大模型等服务几个小时不可用。
+func (l *Limiter) Penalize(host string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	d = min(d, l.backoffMaxLocked())
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(l.budgetLocked(host).Burst), last: time.Now()}
+		l.buckets[host] = b
+	}
+	if until := time.Now().Add(d); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// Backoff 第 attempt 次（从 0 开始）重试前的退避时长：指数增长，在 [d/2, d) 之间随机抖动，
+// 避免多个客户端同时重试。
+func (l *Limiter) Backoff(attempt int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	base, maxDelay := l.backoffBaseLocked(), l.backoffMaxLocked()
+	d := base
+	for i := 0; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+	return d/2 + time.Duration(l.rnd.Int63n(int64(d/2)+1))
+}
+
+// backoffBaseLocked 退避的初始时长，默认 1 秒。调用方需持有 mu。
+func (l *Limiter) backoffBaseLocked() time.Duration {
+	if l.cfg.BackoffBase <= 0 {
+		return time.Second
+	}
+	return l.cfg.BackoffBase
+}
+
+// backoffMaxLocked 退避和暂停时长的上限，不小于初始时长。调用方需持有 mu。
+func (l *Limiter) backoffMaxLocked() time.Duration {
+	return max(l.cfg.BackoffMax, l.backoffBaseLocked())
+}
+
+// maxRetries 服务端限流时的最大重试次数。
+func (l *Limiter) maxRetries() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cfg.MaxRetries
+}
+
+// Transport 返回按请求 host 限流的 http.RoundTripper，base 为 nil 时使用 http.DefaultTransport。
+// 服务端返回 429/503 时按 Retry-After 或退避时长暂停该 host，可重放的请求会自动重试。
+func (l *Limiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{limiter: l, base: base}
+}
+
+type transport struct {
+	limiter *Limiter
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(req.Context(), host); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+			return resp, err
+		}
+
+		delay := t.limiter.Backoff(attempt)
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			delay = max(delay, d)
+		}
+		t.limiter.Penalize(host, delay)
+		logger.Warnf("[ratelimit] %s 返回 %d，暂停请求 %s", host, resp.StatusCode, delay.Round(time.Millisecond))
+
+		// 请求体无法重放或重试次数用完时，把限流响应交给调用方处理
+		if attempt >= t.limiter.maxRetries() || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter 解析 Retry-After 头（秒数或 HTTP 日期）。
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleep 等待 d，ctx 取消时提前返回错误。
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// shared 所有外部 API 客户端共享的限流器，启动时由 Configure 设置预算。
+var shared = New(Config{MaxRetries: 2, BackoffBase: time.Second, BackoffMax: 30 * time.Second})
+
+// Configure 设置共享限流器的配置。
+func Configure(cfg Config) { shared.Configure(cfg) }
+
+// NewTransport 返回使用共享限流器的 http.RoundTripper，base 为 nil 时使用 http.DefaultTransport。
+func NewTransport(base http.RoundTripper) http.RoundTripper { return shared.Transport(base) }
+
+// Wait 等到 host 在共享限流器中有请求预算。
+func Wait(ctx context.Context, host string) error { return shared.Wait(ctx, host) }
+
+// Backoff 共享限流器第 attempt 次（从 0 开始）重试前的退避时长。
+func Backoff(attempt int) time.Duration { return shared.Backoff(attempt) }
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := New(Config{
+		Default:    Budget{Rate: 10, Burst: 2},
+		Hosts:      map[string]Budget{"free.example.com": {Rate: 0}},
+		BackoffMax: 2 * time.Minute,
+	})
+	now := time.Now()
+	if w := l.reserve("a.example.com", now); w != 0 {
+		t.Errorf("第 1 次请求不应等待, got %v", w)
+	}
+	if w := l.reserve("a.example.com", now); w != 0 {
+		t.Errorf("突发范围内不应等待, got %v", w)
+	}
+	if w := l.reserve("a.example.com", now); w < 90*time.Millisecond || w > 110*time.Millisecond {
+		t.Errorf("超出突发后应等待约 100ms, got %v", w)
+	}
+	// 其他 host 有独立的预算
+	if w := l.reserve("b.example.com", now); w != 0 {
+		t.Errorf("其他 host 不应等待, got %v", w)
+	}
+	// 一秒后令牌补满
+	if w := l.reserve("a.example.com", now.Add(time.Second)); w != 0 {
+		t.Errorf("令牌补充后不应等待, got %v", w)
+	}
+	for i := 0; i < 100; i++ {
+		if w := l.reserve("free.example.com", now); w != 0 {
+			t.Fatalf("不限流的 host 不应等待, got %v", w)
+		}
+	}
+
+	l.Penalize("free.example.com", time.Minute)
+	if w := l.reserve("free.example.com", time.Now()); w < 59*time.Second {
+		t.Errorf("服务端限流后应暂停请求, got %v", w)
+	}
+}
+
+func TestLimiter_PenalizeClamped(t *testing.T) {
+	l := New(Config{BackoffMax: 30 * time.Second})
+	l.Penalize("llm.example.com", 3*time.Hour)
+	if w := l.reserve("llm.example.com", time.Now()); w > 30*time.Second {
+		t.Errorf("暂停时长应不超过 BackoffMax, got %v", w)
+	}
+}
+
+func TestLimiter_Backoff(t *testing.T) {
+	l := New(Config{BackoffBase: 100 * time.Millisecond, BackoffMax: time.Second})
+	for attempt, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := l.Backoff(attempt); d < want/2 || d > want {
+				t.Fatalf("Backoff(%d) = %v, want [%v, %v]", attempt, d, want/2, want)
+			}
+		}
+	}
+}
+
+func TestTransport_RetryOnTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	l := New(Config{MaxRetries: 1, BackoffBase: 10 * time.Millisecond, BackoffMax: 20 * time.Millisecond})
+	client := &http.Client{Transport: l.Transport(nil)}
+
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "hello" || calls.Load() != 2 {
+		t.Errorf("status = %d, body = %q, calls = %d", resp.StatusCode, body, calls.Load())
+	}
+}
+
+func TestTransport_GivesUpAndPausesHost(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	l := New(Config{MaxRetries: 0})
+	client := &http.Client{Transport: l.Transport(nil)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("status = %d, calls = %d", resp.StatusCode, calls.Load())
+	}
+
+	// 该 host 暂停期间的请求会等待，直到 ctx 取消
+	u, _ := url.Parse(srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, u.Host); err == nil {
+		t.Error("暂停期间 Wait 应等待到 ctx 取消")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("暂停期间不应再请求, calls = %d", calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("120"); !ok || d != 2*time.Minute {
+		t.Errorf("retryAfter(120) = %v, %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("无效的 Retry-After 应返回 false")
+	}
+	if d, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d < 59*time.Minute {
+		t.Errorf("日期格式 = %v, %v", d, ok)
+	}
+}
@@ -10,12 +10,25 @@ type Feed struct {
 	URL         string    `json:"url"`
 	AddedAt     time.Time `json:"added_at"`
 	LastFetched time.Time `json:"last_fetched,omitempty"`
+
+	// 以下为单个订阅源的播报设置，零值表示使用默认值
+	MaxItems   int  `json:"max_items,omitempty"`   // 每次最多读取的条目数
+	SummaryLen int  `json:"summary_len,omitempty"` // 摘要最大字数
+	Muted      bool `json:"muted,omitempty"`       // 静音：不参与"有什么新消息"，只在点名时读取
+}
+
+// FeedSettings 订阅源设置的增量更新，nil 字段表示不修改。
+type FeedSettings struct {
+	MaxItems   *int
+	SummaryLen *int
+	Muted      *bool
 }
 
 // FeedItem 订阅源条目。
 type FeedItem struct {
 	Title     string    `json:"title"`
 	Summary   string    `json:"summary"`
+	Content   string    `json:"content,omitempty"` // 较完整的正文，用于"详细说说这条"
 	Link      string    `json:"link"`
 	Published time.Time `json:"published"`
 	FeedName  string    `json:"feed_name"`
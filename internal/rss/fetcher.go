@@ -15,6 +15,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"github.com/mmcdole/gofeed"
 )
 
@@ -23,6 +24,7 @@ const (
 	defaultMaxItems     = 20 // 每个 Feed 缓存的最大条目数
 	defaultFetchTimeout = 10 * time.Second
 	maxSummaryLen       = 200 // 摘要最大字符数
+	maxContentLen       = 800 // 正文最大字符数（详细播报用）
 )
 
 // Fetcher 负责抓取和缓存 RSS 内容。
@@ -55,7 +57,7 @@ func NewFetcher(store *FeedStore, dataDir string, cacheTTLMinutes int) *Fetcher
 		cache:     make(map[string]cachedFeed),
 		cacheTTL:  ttl,
 		parser:    gofeed.NewParser(),
-		client:    &http.Client{Timeout: defaultFetchTimeout},
+		client:    &http.Client{Timeout: defaultFetchTimeout, Transport: ratelimit.NewTransport(nil)},
 	}
 
 	// 加载缓存
@@ -80,7 +82,8 @@ func (f *Fetcher) FetchAndValidate(ctx context.Context, url string) (string, err
 }
 
 // GetNews 获取订阅源的最新内容。
-// source 为空则获取所有源，keyword 为空则不过滤。
+// source 为空则获取所有未静音的源，keyword 为空则不过滤。
+// 每个源的条目数和摘要长度受该源的 MaxItems、SummaryLen 设置限制。
 func (f *Fetcher) GetNews(ctx context.Context, source string, keyword string, limit int) ([]FeedItem, error) {
 	if limit <= 0 {
 		limit = 5
@@ -104,6 +107,18 @@ func (f *Fetcher) GetNews(ctx context.Context, source string, keyword string, li
 			return nil, fmt.Errorf("未找到名为 %q 的订阅源", source)
 		}
 		feeds = filtered
+	} else {
+		// 未指定来源时跳过静音的源
+		var unmuted []Feed
+		for _, fd := range feeds {
+			if !fd.Muted {
+				unmuted = append(unmuted, fd)
+			}
+		}
+		if len(unmuted) == 0 {
+			return nil, nil
+		}
+		feeds = unmuted
 	}
 
 	var allItems []FeedItem
@@ -113,7 +128,7 @@ func (f *Fetcher) GetNews(ctx context.Context, source string, keyword string, li
 			logger.Warnf("[rss] 获取 %s 失败: %v", fd.Name, err)
 			continue
 		}
-		allItems = append(allItems, items...)
+		allItems = append(allItems, applyFeedSettings(items, fd)...)
 	}
 
 	// 按发布时间倒序
@@ -140,6 +155,28 @@ func (f *Fetcher) GetNews(ctx context.Context, source string, keyword string, li
 	return allItems, nil
 }
 
+// Refresh 重新抓取缓存已过期的订阅源，让下次收听时直接命中缓存。ctx 取消时停止，返回刷新的源数。
+func (f *Fetcher) Refresh(ctx context.Context) int {
+	refreshed := 0
+	for _, fd := range f.store.List() {
+		if ctx.Err() != nil {
+			break
+		}
+		f.mu.RLock()
+		cached, ok := f.cache[fd.ID]
+		f.mu.RUnlock()
+		if ok && time.Since(cached.FetchedAt) < f.cacheTTL {
+			continue
+		}
+		if _, err := f.getFeedItems(ctx, fd); err != nil {
+			logger.Debugf("[rss] 刷新 %s 失败: %v", fd.Name, err)
+			continue
+		}
+		refreshed++
+	}
+	return refreshed
+}
+
 // getFeedItems 获取单个 Feed 的条目（优先使用缓存）。
 func (f *Fetcher) getFeedItems(ctx context.Context, fd Feed) ([]FeedItem, error) {
 	f.mu.RLock()
@@ -217,6 +254,13 @@ func (f *Fetcher) convertItems(feed *gofeed.Feed, feedName string) []FeedItem {
 			summary = gItem.Content
 		}
 		summary = stripHTML(summary)
+
+		// 正文优先使用 content:encoded，没有则退回描述
+		content := stripHTML(gItem.Content)
+		if content == "" {
+			content = summary
+		}
+		content = truncate(content, maxContentLen)
 		summary = truncate(summary, maxSummaryLen)
 
 		published := time.Now()
@@ -229,6 +273,7 @@ func (f *Fetcher) convertItems(feed *gofeed.Feed, feedName string) []FeedItem {
 		items = append(items, FeedItem{
 			Title:     gItem.Title,
 			Summary:   summary,
+			Content:   content,
 			Link:      gItem.Link,
 			Published: published,
 			FeedName:  feedName,
@@ -237,6 +282,28 @@ func (f *Fetcher) convertItems(feed *gofeed.Feed, feedName string) []FeedItem {
 	return items
 }
 
+// applyFeedSettings 按订阅源设置限制条目数和摘要长度，不修改缓存中的原始数据。
+func applyFeedSettings(items []FeedItem, fd Feed) []FeedItem {
+	if fd.MaxItems > 0 && len(items) > fd.MaxItems {
+		items = items[:fd.MaxItems]
+	}
+	if fd.SummaryLen <= 0 {
+		return items
+	}
+
+	result := make([]FeedItem, len(items))
+	for i, item := range items {
+		// 摘要缓存时已截断到 maxSummaryLen，需要更长时从正文截取
+		source := item.Summary
+		if fd.SummaryLen > maxSummaryLen && item.Content != "" {
+			source = item.Content
+		}
+		item.Summary = truncate(strings.TrimSuffix(source, "..."), fd.SummaryLen)
+		result[i] = item
+	}
+	return result
+}
+
 func (f *Fetcher) loadCache() error {
 	data, err := os.ReadFile(f.cachePath)
 	if err != nil {
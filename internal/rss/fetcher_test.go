@@ -314,3 +314,38 @@ func TestHTMLContentInFeed(t *testing.T) {
 		t.Errorf("HTML 应被剥离，实际: %s", items[0].Summary)
 	}
 }
+
+func TestGetNewsFeedSettings(t *testing.T) {
+	srv := setupTestServer(testRSSFeed)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	store, _ := NewFeedStore(dir)
+	_ = store.Add(Feed{ID: "rss_001", Name: "Test Blog", URL: srv.URL, MaxItems: 2, SummaryLen: 4})
+
+	fetcher := NewFetcher(store, dir, 30)
+	items, err := fetcher.GetNews(context.Background(), "", "", 5)
+	if err != nil {
+		t.Fatalf("GetNews 失败: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("max_items=2 时期望 2 条，得到 %d 条", len(items))
+	}
+	if items[1].Summary != "人工智能..." {
+		t.Errorf("摘要应截断到 4 个字: %q", items[1].Summary)
+	}
+
+	// 静音后不参与全部来源的获取，但点名时仍可读取
+	muted := true
+	if _, err := store.UpdateSettings("Test Blog", FeedSettings{Muted: &muted}); err != nil {
+		t.Fatalf("UpdateSettings 失败: %v", err)
+	}
+	items, _ = fetcher.GetNews(context.Background(), "", "", 5)
+	if len(items) != 0 {
+		t.Errorf("静音源不应被读取，得到 %d 条", len(items))
+	}
+	items, _ = fetcher.GetNews(context.Background(), "Test", "", 5)
+	if len(items) != 2 {
+		t.Errorf("点名读取静音源期望 2 条，得到 %d 条", len(items))
+	}
+}
@@ -0,0 +1,139 @@
+package rss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// opmlDoc OPML 文档结构（兼容 1.0/2.0）。
+type opmlDoc struct {
+	XMLName xml.Name    `xml:"opml"`
+	Version string      `xml:"version,attr"`
+	Title   string      `xml:"head>title"`
+	Created string      `xml:"head>dateCreated,omitempty"`
+	Body    []opmlEntry `xml:"body>outline"`
+}
+
+// opmlEntry OPML 中的 outline 节点，可以是订阅源或分类（嵌套 outline）。
+// maxItems/summaryLen/muted 为 PiBuddy 扩展属性，用于导出后再导入时保留播报设置。
+type opmlEntry struct {
+	Text       string      `xml:"text,attr"`
+	Title      string      `xml:"title,attr,omitempty"`
+	Type       string      `xml:"type,attr,omitempty"`
+	XMLURL     string      `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL    string      `xml:"htmlUrl,attr,omitempty"`
+	MaxItems   int         `xml:"maxItems,attr,omitempty"`
+	SummaryLen int         `xml:"summaryLen,attr,omitempty"`
+	Muted      bool        `xml:"muted,attr,omitempty"`
+	Children   []opmlEntry `xml:"outline"`
+}
+
+// ImportResult OPML 导入结果。
+type ImportResult struct {
+	Added   []string // 新增的订阅源名称
+	Skipped []string // 已存在而跳过的订阅源名称
+}
+
+// ExportOPML 将所有订阅源导出为 OPML 2.0。
+func (s *FeedStore) ExportOPML(w io.Writer) error {
+	feeds := s.List()
+
+	doc := opmlDoc{
+		Version: "2.0",
+		Title:   "PiBuddy RSS 订阅",
+		Created: time.Now().Format(time.RFC1123Z),
+	}
+	for _, f := range feeds {
+		doc.Body = append(doc.Body, opmlEntry{
+			Text:       f.Name,
+			Title:      f.Name,
+			Type:       "rss",
+			XMLURL:     f.URL,
+			MaxItems:   f.MaxItems,
+			SummaryLen: f.SummaryLen,
+			Muted:      f.Muted,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("生成 OPML 失败: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ImportOPML 从 OPML 导入订阅源，已存在的 URL 会被跳过。分类中的订阅源会被展开。
+func (s *FeedStore) ImportOPML(r io.Reader) (*ImportResult, error) {
+	var doc opmlDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析 OPML 失败: %w", err)
+	}
+
+	var entries []opmlEntry
+	flattenOPML(doc.Body, &entries)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("OPML 中没有找到订阅源")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[string]bool, len(s.feeds))
+	for _, f := range s.feeds {
+		existing[f.URL] = true
+	}
+
+	result := &ImportResult{}
+	now := time.Now()
+	for _, e := range entries {
+		name := strings.TrimSpace(e.Title)
+		if name == "" {
+			name = strings.TrimSpace(e.Text)
+		}
+		if name == "" {
+			name = e.XMLURL
+		}
+		if existing[e.XMLURL] {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+		existing[e.XMLURL] = true
+
+		s.feeds = append(s.feeds, Feed{
+			// 批量导入时毫秒时间戳会重复，加上序号保证 ID 唯一
+			ID:         fmt.Sprintf("rss_%d_%d", now.UnixMilli(), len(result.Added)),
+			Name:       name,
+			URL:        e.XMLURL,
+			AddedAt:    now,
+			MaxItems:   e.MaxItems,
+			SummaryLen: e.SummaryLen,
+			Muted:      e.Muted,
+		})
+		result.Added = append(result.Added, name)
+	}
+
+	if len(result.Added) > 0 {
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// flattenOPML 递归展开 outline，收集所有带 xmlUrl 的订阅源。
+func flattenOPML(outlines []opmlEntry, out *[]opmlEntry) {
+	for _, o := range outlines {
+		if o.XMLURL != "" {
+			*out = append(*out, o)
+		}
+		flattenOPML(o.Children, out)
+	}
+}
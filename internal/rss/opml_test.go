@@ -0,0 +1,88 @@
+package rss
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>My Feeds</title></head>
+  <body>
+    <outline text="少数派" type="rss" xmlUrl="https://sspai.com/feed"/>
+    <outline text="科技">
+      <outline text="36氪" title="36氪" type="rss" xmlUrl="https://36kr.com/feed"/>
+      <outline text="Hacker News" type="rss" xmlUrl="https://hnrss.org/frontpage" muted="true" maxItems="3"/>
+    </outline>
+  </body>
+</opml>`
+
+func TestImportOPML(t *testing.T) {
+	store, err := NewFeedStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFeedStore 失败: %v", err)
+	}
+	_ = store.Add(Feed{Name: "少数派", URL: "https://sspai.com/feed"})
+
+	result, err := store.ImportOPML(strings.NewReader(testOPML))
+	if err != nil {
+		t.Fatalf("ImportOPML 失败: %v", err)
+	}
+	if len(result.Added) != 2 || len(result.Skipped) != 1 {
+		t.Fatalf("期望新增 2 个、跳过 1 个，得到 %v / %v", result.Added, result.Skipped)
+	}
+
+	feeds := store.List()
+	if len(feeds) != 3 {
+		t.Fatalf("期望 3 个订阅源，得到 %d 个", len(feeds))
+	}
+	ids := make(map[string]bool)
+	for _, f := range feeds {
+		if ids[f.ID] {
+			t.Errorf("ID 重复: %s", f.ID)
+		}
+		ids[f.ID] = true
+	}
+	hn := store.FindByName("Hacker News")
+	if hn == nil || !hn.Muted || hn.MaxItems != 3 {
+		t.Errorf("应保留扩展属性: %+v", hn)
+	}
+}
+
+func TestImportOPMLInvalid(t *testing.T) {
+	store, _ := NewFeedStore(t.TempDir())
+	if _, err := store.ImportOPML(strings.NewReader("not xml")); err == nil {
+		t.Error("无效 OPML 应返回错误")
+	}
+	if _, err := store.ImportOPML(strings.NewReader(`<opml version="2.0"><body></body></opml>`)); err == nil {
+		t.Error("空 OPML 应返回错误")
+	}
+}
+
+func TestExportOPMLRoundTrip(t *testing.T) {
+	src, _ := NewFeedStore(t.TempDir())
+	_ = src.Add(Feed{Name: "A & B", URL: "https://example.com/a?x=1&y=2", SummaryLen: 80})
+	_ = src.Add(Feed{Name: "C", URL: "https://example.com/c", Muted: true})
+
+	var buf bytes.Buffer
+	if err := src.ExportOPML(&buf); err != nil {
+		t.Fatalf("ExportOPML 失败: %v", err)
+	}
+
+	dst, _ := NewFeedStore(t.TempDir())
+	result, err := dst.ImportOPML(&buf)
+	if err != nil {
+		t.Fatalf("导入导出结果失败: %v", err)
+	}
+	if len(result.Added) != 2 {
+		t.Fatalf("期望导入 2 个，得到 %d 个", len(result.Added))
+	}
+	a := dst.FindByName("A & B")
+	if a == nil || a.URL != "https://example.com/a?x=1&y=2" || a.SummaryLen != 80 {
+		t.Errorf("往返后数据不一致: %+v", a)
+	}
+	if c := dst.FindByName("C"); c == nil || !c.Muted {
+		t.Errorf("往返后应保留静音设置: %+v", c)
+	}
+}
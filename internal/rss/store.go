@@ -129,3 +129,54 @@ func (s *FeedStore) UpdateLastFetched(id string, t time.Time) {
 		}
 	}
 }
+
+// UpdateSettings 根据 ID 或名称（模糊匹配）更新订阅源设置，返回更新后的订阅源。
+func (s *FeedStore) UpdateSettings(idOrName string, settings FeedSettings) (*Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.indexLocked(idOrName)
+	if idx < 0 {
+		return nil, fmt.Errorf("未找到订阅源 %s", idOrName)
+	}
+
+	// 先校验全部字段再修改，避免部分生效
+	if settings.MaxItems != nil && *settings.MaxItems < 0 {
+		return nil, fmt.Errorf("条目数不能为负数")
+	}
+	if settings.SummaryLen != nil && *settings.SummaryLen < 0 {
+		return nil, fmt.Errorf("摘要长度不能为负数")
+	}
+
+	f := &s.feeds[idx]
+	if settings.MaxItems != nil {
+		f.MaxItems = *settings.MaxItems
+	}
+	if settings.SummaryLen != nil {
+		f.SummaryLen = *settings.SummaryLen
+	}
+	if settings.Muted != nil {
+		f.Muted = *settings.Muted
+	}
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	result := *f
+	return &result, nil
+}
+
+// indexLocked 按 ID、名称精确匹配，再按名称模糊匹配查找订阅源下标，调用方需持有锁。
+func (s *FeedStore) indexLocked(idOrName string) int {
+	lower := strings.ToLower(idOrName)
+	for i, f := range s.feeds {
+		if f.ID == idOrName || strings.ToLower(f.Name) == lower {
+			return i
+		}
+	}
+	for i, f := range s.feeds {
+		if strings.Contains(strings.ToLower(f.Name), lower) {
+			return i
+		}
+	}
+	return -1
+}
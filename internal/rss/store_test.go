@@ -190,3 +190,22 @@ func TestFeedStoreConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestFeedStoreUpdateSettingsAtomic(t *testing.T) {
+	store, err := NewFeedStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFeedStore 失败: %v", err)
+	}
+	if err := store.Add(Feed{Name: "Test Feed", URL: "https://example.com/feed.xml"}); err != nil {
+		t.Fatalf("Add 失败: %v", err)
+	}
+
+	// 有字段不合法时整个修改都不生效
+	maxItems, summaryLen := 5, -1
+	if _, err := store.UpdateSettings("Test Feed", FeedSettings{MaxItems: &maxItems, SummaryLen: &summaryLen}); err == nil {
+		t.Fatal("摘要长度为负数时应返回错误")
+	}
+	if f := store.List()[0]; f.MaxItems != 0 {
+		t.Errorf("校验失败时不应修改条目数, got %d", f.MaxItems)
+	}
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 决定任务的下一次运行时间。
+type Schedule interface {
+	// Next 返回 t 之后的下一次运行时间。
+	Next(t time.Time) time.Time
+	// String 返回可读的调度描述，用于管理接口展示。
+	String() string
+}
+
+// intervalSchedule 固定间隔调度。
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every 返回固定间隔的调度，间隔最小为 1 秒。
+func Every(d time.Duration) Schedule {
+	if d < time.Second {
+		d = time.Second
+	}
+	return intervalSchedule{interval: d}
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time { return t.Add(s.interval) }
+
+func (s intervalSchedule) String() string { return "@every " + s.interval.String() }
+
+// cronSchedule 标准 5 段 cron 调度（分 时 日 月 周），每个字段用位图表示。
+type cronSchedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// cronField 描述 cron 字段的取值范围。
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"星期", 0, 7}, // 0 和 7 都表示周日
+}
+
+// Parse 解析调度表达式。支持：
+//   - 5 段 cron 表达式，如 "0 * * * *"（每小时整点）、"*/5 8-22 * * 1-5"
+//   - "@every 30s" 固定间隔
+//   - "@hourly"、"@daily"、"@weekly" 简写
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("解析间隔 %q 失败: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("间隔必须大于 0: %q", spec)
+		}
+		return Every(d), nil
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron 表达式需要 5 个字段，实际为 %d: %q", len(parts), spec)
+	}
+
+	bits := make([]uint64, 5)
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("解析 cron 表达式 %q 失败: %w", spec, err)
+		}
+		bits[i] = b
+	}
+
+	// 周日同时用 0 和 7 表示，统一到 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+		bits[4] &^= 1 << 7
+	}
+
+	return &cronSchedule{
+		spec:    spec,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: parts[2] == "*",
+		dowStar: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField 解析单个 cron 字段，支持 *、数字、列表(,)、范围(-)和步长(/)。
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			s, err := strconv.Atoi(item[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("%s字段步长无效: %q", f.name, item)
+			}
+			step = s
+			item = item[:idx]
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			bounds := strings.SplitN(item, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("%s字段范围无效: %q", f.name, item)
+			}
+		default:
+			v, err := strconv.Atoi(item)
+			if err != nil {
+				return 0, fmt.Errorf("%s字段取值无效: %q", f.name, item)
+			}
+			lo, hi = v, v
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s字段超出范围 %d-%d: %q", f.name, f.min, f.max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 返回 t 之后（不含 t 所在分钟）第一个满足 cron 表达式的时间点。
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// 最多向后搜索 5 年，防止表达式永远无法满足（如 2 月 30 日）时死循环
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 按 cron 语义判断日期是否匹配：日和星期都被限定时满足任一即可。
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+func (s *cronSchedule) String() string { return s.spec }
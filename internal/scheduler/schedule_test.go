@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"5-1 * * * *",
+		"@every abc",
+		"@every -1s",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+}
+
+func TestParse_Every(t *testing.T) {
+	s, err := Parse("@every 30s")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	if got := s.Next(base); !got.Equal(base.Add(30 * time.Second)) {
+		t.Errorf("Next = %v, want %v", got, base.Add(30*time.Second))
+	}
+	if s.String() != "@every 30s" {
+		t.Errorf("String = %q", s.String())
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 17, 30, 0, time.Local) // 周六
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 1, 10, 18, 0, 0, time.Local)},
+		{"@hourly", time.Date(2025, 3, 1, 11, 0, 0, 0, time.Local)},
+		{"15 9,12 * * *", time.Date(2025, 3, 1, 12, 15, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2025, 3, 1, 10, 30, 0, 0, time.Local)},
+		{"0 8 * * *", time.Date(2025, 3, 2, 8, 0, 0, 0, time.Local)},
+		{"0 8 * * 1-5", time.Date(2025, 3, 3, 8, 0, 0, 0, time.Local)},
+		{"0 9 * * 7", time.Date(2025, 3, 2, 9, 0, 0, 0, time.Local)},
+		{"30 10,12 * * *", time.Date(2025, 3, 1, 10, 30, 0, 0, time.Local)},
+		{"0 0 1 * *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCronNext_Unsatisfiable(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time for 2月30日, got %v", got)
+	}
+}
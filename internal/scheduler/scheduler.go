@@ -0,0 +1,271 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// defaultBusyRetry 对话进行中时，可暂停任务的重试间隔。
+const defaultBusyRetry = 5 * time.Second
+
+// Job 描述一个定时任务。
+type Job struct {
+	Name     string        // 任务名称，全局唯一
+	Schedule Schedule      // 调度规则
+	Jitter   time.Duration // 每次运行随机延后 [0, Jitter)，避免多个任务同时触发
+	// PauseWhileBusy 为 true 时，对话进行中（用户说话、LLM 处理、TTS 播报）推迟运行，
+	// 直到对话结束，避免定时播报打断正在进行的对话。
+	PauseWhileBusy bool
+	Run            func(ctx context.Context)
+}
+
+// JobInfo 任务运行状态，供管理接口查询。
+type JobInfo struct {
+	Name           string    `json:"name"`
+	Schedule       string    `json:"schedule"`
+	Jitter         string    `json:"jitter,omitempty"`
+	PauseWhileBusy bool      `json:"pause_while_busy"`
+	NextRun        time.Time `json:"next_run"`
+	LastRun        time.Time `json:"last_run,omitempty"`
+	LastDuration   string    `json:"last_duration,omitempty"`
+	Runs           int       `json:"runs"`
+	Deferred       int       `json:"deferred"` // 因对话进行中被推迟的次数
+	Skipped        int       `json:"skipped"`  // 因上一次运行未结束而跳过的次数
+	Running        bool      `json:"running"`
+}
+
+// entry 调度器内部的任务记录。
+type entry struct {
+	job      Job
+	nextRun  time.Time
+	lastRun  time.Time
+	lastDur  time.Duration
+	runs     int
+	deferred int
+	skipped  int
+	running  bool
+}
+
+// Scheduler 统一管理后台定时任务，取代各模块各自创建的 ticker。
+type Scheduler struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	busy      func() bool
+	busyRetry time.Duration
+	wake      chan struct{}
+	rnd       *rand.Rand
+}
+
+// New 创建调度器。
+func New() *Scheduler {
+	return &Scheduler{
+		entries:   make(map[string]*entry),
+		busyRetry: defaultBusyRetry,
+		wake:      make(chan struct{}, 1),
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetBusyFunc 设置对话忙碌判断函数，返回 true 时暂停 PauseWhileBusy 任务。
+func (s *Scheduler) SetBusyFunc(fn func() bool) {
+	s.mu.Lock()
+	s.busy = fn
+	s.mu.Unlock()
+}
+
+// Add 注册定时任务。名称重复时返回错误。
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("任务名称不能为空")
+	}
+	if job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("任务 %s 缺少调度规则或执行函数", job.Name)
+	}
+
+	s.mu.Lock()
+	if _, exists := s.entries[job.Name]; exists {
+		s.mu.Unlock()
+		return fmt.Errorf("任务 %s 已存在", job.Name)
+	}
+	e := &entry{job: job}
+	e.nextRun = s.nextTimeLocked(e, time.Now())
+	s.entries[job.Name] = e
+	s.mu.Unlock()
+
+	logger.Debugf("[scheduler] 已注册任务 %s (%s)，下次运行: %s", job.Name, job.Schedule, e.nextRun.Format("2006-01-02 15:04:05"))
+	s.notify()
+	return nil
+}
+
+// AddFunc 使用调度表达式注册任务，表达式格式见 Parse。
+func (s *Scheduler) AddFunc(name, spec string, fn func(ctx context.Context)) error {
+	sched, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	return s.Add(Job{Name: name, Schedule: sched, Run: fn})
+}
+
+// Remove 移除任务。正在运行的实例不会被中断。
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	_, ok := s.entries[name]
+	delete(s.entries, name)
+	s.mu.Unlock()
+	if ok {
+		s.notify()
+	}
+	return ok
+}
+
+// Jobs 返回所有任务的运行状态（按名称排序）。
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		info := JobInfo{
+			Name:           e.job.Name,
+			Schedule:       e.job.Schedule.String(),
+			PauseWhileBusy: e.job.PauseWhileBusy,
+			NextRun:        e.nextRun,
+			LastRun:        e.lastRun,
+			Runs:           e.runs,
+			Deferred:       e.deferred,
+			Skipped:        e.skipped,
+			Running:        e.running,
+		}
+		if e.job.Jitter > 0 {
+			info.Jitter = e.job.Jitter.String()
+		}
+		if !e.lastRun.IsZero() {
+			info.LastDuration = e.lastDur.String()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Run 启动调度循环，阻塞直到 ctx 被取消。
+func (s *Scheduler) Run(ctx context.Context) {
+	logger.Infof("[scheduler] 调度器已启动，共 %d 个任务", len(s.Jobs()))
+
+	for {
+		var timerC <-chan time.Time
+		var timer *time.Timer
+		if next, ok := s.earliest(); ok {
+			timer = time.NewTimer(time.Until(next))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			logger.Info("[scheduler] 调度器已停止")
+			return
+		case <-s.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		case now := <-timerC:
+			s.runDue(ctx, now)
+		}
+	}
+}
+
+// earliest 返回最早的下一次运行时间。
+func (s *Scheduler) earliest() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, e := range s.entries {
+		if e.nextRun.IsZero() {
+			continue // 调度规则永远无法满足
+		}
+		if next.IsZero() || e.nextRun.Before(next) {
+			next = e.nextRun
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// runDue 运行所有已到期的任务。
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	busyFn := s.busy
+	s.mu.Unlock()
+
+	// 在锁外判断忙碌状态，避免与调用方的锁产生嵌套
+	busy := busyFn != nil && busyFn()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.nextRun.IsZero() || e.nextRun.After(now) {
+			continue
+		}
+		if e.running {
+			e.skipped++
+			e.nextRun = s.nextTimeLocked(e, now)
+			logger.Debugf("[scheduler] 任务 %s 上一次运行尚未结束，跳过本次", e.job.Name)
+			continue
+		}
+		if e.job.PauseWhileBusy && busy {
+			e.deferred++
+			e.nextRun = now.Add(s.busyRetry)
+			logger.Debugf("[scheduler] 对话进行中，任务 %s 推迟 %s", e.job.Name, s.busyRetry)
+			continue
+		}
+
+		e.running = true
+		e.nextRun = s.nextTimeLocked(e, now)
+		go s.execute(ctx, e)
+	}
+}
+
+// execute 运行单个任务并记录耗时。
+func (s *Scheduler) execute(ctx context.Context, e *entry) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("[scheduler] 任务 %s 异常: %v", e.job.Name, r)
+		}
+		s.mu.Lock()
+		e.running = false
+		e.lastRun = start
+		e.lastDur = time.Since(start)
+		e.runs++
+		s.mu.Unlock()
+	}()
+
+	e.job.Run(ctx)
+}
+
+// nextTimeLocked 计算任务的下一次运行时间（含随机抖动），调用方需持有锁。
+func (s *Scheduler) nextTimeLocked(e *entry, from time.Time) time.Time {
+	next := e.job.Schedule.Next(from)
+	if e.job.Jitter > 0 {
+		next = next.Add(time.Duration(s.rnd.Int63n(int64(e.job.Jitter))))
+	}
+	return next
+}
+
+// notify 唤醒调度循环重新计算等待时间。
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_AddValidation(t *testing.T) {
+	s := New()
+	noop := func(ctx context.Context) {}
+
+	if err := s.Add(Job{Schedule: Every(time.Second), Run: noop}); err == nil {
+		t.Error("empty name should fail")
+	}
+	if err := s.Add(Job{Name: "a", Run: noop}); err == nil {
+		t.Error("nil schedule should fail")
+	}
+	if err := s.Add(Job{Name: "a", Schedule: Every(time.Second), Run: noop}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Add(Job{Name: "a", Schedule: Every(time.Second), Run: noop}); err == nil {
+		t.Error("duplicate name should fail")
+	}
+	if err := s.AddFunc("b", "bad spec", noop); err == nil {
+		t.Error("invalid spec should fail")
+	}
+
+	if !s.Remove("a") {
+		t.Error("Remove should report existing job")
+	}
+	if len(s.Jobs()) != 0 {
+		t.Errorf("expected no jobs after remove, got %d", len(s.Jobs()))
+	}
+}
+
+func TestScheduler_RunsDueJobs(t *testing.T) {
+	s := New()
+	var count atomic.Int32
+	s.Add(Job{Name: "tick", Schedule: Every(time.Second), Run: func(ctx context.Context) { count.Add(1) }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	if n := count.Load(); n < 2 {
+		t.Errorf("expected at least 2 runs, got %d", n)
+	}
+	jobs := s.Jobs()
+	if len(jobs) != 1 || jobs[0].Runs < 2 || jobs[0].Schedule != "@every 1s" {
+		t.Errorf("unexpected job info: %+v", jobs)
+	}
+}
+
+func TestScheduler_PauseWhileBusy(t *testing.T) {
+	s := New()
+	s.busyRetry = 200 * time.Millisecond
+
+	var busy atomic.Bool
+	busy.Store(true)
+	s.SetBusyFunc(busy.Load)
+
+	var paused, normal atomic.Int32
+	s.Add(Job{Name: "paused", Schedule: Every(time.Second), PauseWhileBusy: true, Run: func(ctx context.Context) { paused.Add(1) }})
+	s.Add(Job{Name: "normal", Schedule: Every(time.Second), Run: func(ctx context.Context) { normal.Add(1) }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(1300 * time.Millisecond)
+		busy.Store(false)
+	}()
+	s.Run(ctx)
+
+	if normal.Load() == 0 {
+		t.Error("job without PauseWhileBusy should run while busy")
+	}
+	for _, j := range s.Jobs() {
+		if j.Name == "paused" && j.Deferred == 0 {
+			t.Errorf("paused job should be deferred while busy: %+v", j)
+		}
+	}
+	if paused.Load() == 0 {
+		t.Error("paused job should run once no longer busy")
+	}
+}
@@ -0,0 +1,206 @@
+package sound
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// DefaultRules 内置的声音事件：宝宝哭声、玻璃破碎、烟雾/火灾报警。
+var DefaultRules = []config.SoundEventRule{
+	{Name: "宝宝哭声", Labels: []string{"Baby cry", "Crying, sobbing"}, Threshold: 0.3},
+	{Name: "玻璃破碎", Labels: []string{"Shatter", "Breaking"}, Threshold: 0.3},
+	{Name: "烟雾报警", Labels: []string{"Smoke detector", "Fire alarm"}, Threshold: 0.3},
+}
+
+const (
+	defaultRuleThreshold = 0.3
+	defaultRuleCooldown  = 300 // 秒
+)
+
+// Event 检测到的声音事件。
+type Event struct {
+	Name     string    `json:"name"`  // 事件名（规则名）
+	Label    string    `json:"label"` // 命中的 AudioSet 标签
+	Score    float32   `json:"score"` // 置信度
+	Time     time.Time `json:"time"`  // 检测时间
+	Announce string    `json:"-"`     // 播报内容，为空表示不播报
+}
+
+// Monitor 持续接收麦克风音频，每隔一段时间用 Tagger 分析最近的音频，命中规则时回调。
+// 分析在后台 goroutine 中进行，上一次分析未完成时跳过本次，不阻塞音频处理。
+type Monitor struct {
+	tagger   Tagger
+	rules    []config.SoundEventRule
+	minLevel float64
+	onEvent  func(Event)
+
+	mu       sync.Mutex
+	buf      []float32 // 最近 window 长度的音频
+	window   int
+	interval int
+	pending  int // 距上次分析新增的样本数
+	busy     bool
+	lastFire map[string]time.Time
+
+	now func() time.Time
+}
+
+// NewMonitor 创建声音事件监测器。rules 为空时使用 DefaultRules。
+func NewMonitor(tagger Tagger, cfg config.SoundEventsConfig, sampleRate int) *Monitor {
+	rules := cfg.Events
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	normalized := make([]config.SoundEventRule, len(rules))
+	for i, r := range rules {
+		if r.Threshold <= 0 {
+			r.Threshold = defaultRuleThreshold
+		}
+		if r.Cooldown <= 0 {
+			r.Cooldown = defaultRuleCooldown
+		}
+		normalized[i] = r
+	}
+
+	return &Monitor{
+		tagger:   tagger,
+		rules:    normalized,
+		minLevel: cfg.MinLevelDB,
+		window:   int(cfg.Window * float64(sampleRate)),
+		interval: int(cfg.Interval * float64(sampleRate)),
+		lastFire: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// SetHandler 设置事件回调，在分析 goroutine 中调用。
+func (m *Monitor) SetHandler(fn func(Event)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onEvent = fn
+}
+
+// Feed 写入音频帧，攒够分析间隔后异步分析。
+func (m *Monitor) Feed(frame []float32) {
+	m.mu.Lock()
+	m.buf = append(m.buf, frame...)
+	if over := len(m.buf) - m.window; over > 0 {
+		m.buf = m.buf[over:]
+	}
+	m.pending += len(frame)
+	if m.pending < m.interval || len(m.buf) < m.window || m.busy {
+		m.mu.Unlock()
+		return
+	}
+	m.pending = 0
+	samples := append([]float32(nil), m.buf...)
+	m.busy = true
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			m.busy = false
+			m.mu.Unlock()
+		}()
+		m.analyze(samples)
+	}()
+}
+
+// Reset 清空缓冲区（离开空闲状态时调用，避免把对话声音和之后的环境音拼在一起分析）。
+func (m *Monitor) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buf = m.buf[:0]
+	m.pending = 0
+}
+
+// analyze 分析一段音频，命中规则且不在冷却期时回调。
+func (m *Monitor) analyze(samples []float32) {
+	if levelDB(samples) < m.minLevel {
+		return
+	}
+	tags := m.tagger.Tag(samples)
+	if len(tags) == 0 {
+		return
+	}
+	logger.Debugf("[sound] 音频标注: %v", tags)
+
+	for _, ev := range m.match(tags) {
+		logger.Infof("[sound] 检测到声音事件: %s (%s %.2f)", ev.Name, ev.Label, ev.Score)
+		m.mu.Lock()
+		fn := m.onEvent
+		m.mu.Unlock()
+		if fn != nil {
+			fn(ev)
+		}
+	}
+}
+
+// match 按规则匹配标注结果，同一规则冷却期内只触发一次。
+func (m *Monitor) match(tags []Tag) []Event {
+	now := m.now()
+	var events []Event
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rule := range m.rules {
+		tag, ok := matchRule(rule, tags)
+		if !ok {
+			continue
+		}
+		if last, fired := m.lastFire[rule.Name]; fired && now.Sub(last) < time.Duration(rule.Cooldown)*time.Second {
+			continue
+		}
+		m.lastFire[rule.Name] = now
+
+		announce := rule.Announce
+		if announce == "" {
+			announce = "注意，检测到" + rule.Name
+		} else if announce == "-" {
+			announce = ""
+		}
+		events = append(events, Event{Name: rule.Name, Label: tag.Label, Score: tag.Score, Time: now, Announce: announce})
+	}
+	return events
+}
+
+// matchRule 返回满足规则的置信度最高的标签。
+func matchRule(rule config.SoundEventRule, tags []Tag) (Tag, bool) {
+	var best Tag
+	found := false
+	for _, tag := range tags {
+		if tag.Score < rule.Threshold || (found && tag.Score <= best.Score) {
+			continue
+		}
+		label := strings.ToLower(tag.Label)
+		for _, want := range rule.Labels {
+			if strings.Contains(label, strings.ToLower(want)) {
+				best, found = tag, true
+				break
+			}
+		}
+	}
+	return best, found
+}
+
+// levelDB 计算音频的 RMS 电平（dBFS）。
+func levelDB(samples []float32) float64 {
+	if len(samples) == 0 {
+		return -120
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	energy := sum / float64(len(samples))
+	if energy < 1e-12 {
+		return -120
+	}
+	return 10 * math.Log10(energy)
+}
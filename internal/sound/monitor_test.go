@@ -0,0 +1,123 @@
+package sound
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/config"
+)
+
+// fakeTagger 返回固定的标注结果并记录调用次数。
+type fakeTagger struct {
+	mu    sync.Mutex
+	tags  []Tag
+	calls int
+}
+
+func (f *fakeTagger) Tag(samples []float32) []Tag {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.tags
+}
+
+func (f *fakeTagger) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func loudFrame(n int) []float32 {
+	frame := make([]float32, n)
+	for i := range frame {
+		if i%2 == 0 {
+			frame[i] = 0.3
+		} else {
+			frame[i] = -0.3
+		}
+	}
+	return frame
+}
+
+func TestMatchRule(t *testing.T) {
+	rule := config.SoundEventRule{Name: "宝宝哭声", Labels: []string{"baby cry"}, Threshold: 0.3}
+	tags := []Tag{
+		{Label: "Speech", Score: 0.8},
+		{Label: "Baby cry, infant cry", Score: 0.5},
+	}
+	tag, ok := matchRule(rule, tags)
+	if !ok || tag.Label != "Baby cry, infant cry" {
+		t.Errorf("应忽略大小写匹配哭声标签，实际 %+v %v", tag, ok)
+	}
+
+	if _, ok := matchRule(rule, []Tag{{Label: "Baby cry, infant cry", Score: 0.1}}); ok {
+		t.Error("低于阈值不应匹配")
+	}
+}
+
+func TestMonitor_Cooldown(t *testing.T) {
+	m := NewMonitor(&fakeTagger{}, config.SoundEventsConfig{Window: 1, Interval: 1, MinLevelDB: -50}, 16000)
+	now := time.Date(2026, 1, 1, 3, 0, 0, 0, time.Local)
+	m.now = func() time.Time { return now }
+
+	tags := []Tag{{Label: "Smoke detector, smoke alarm", Score: 0.9}}
+	events := m.match(tags)
+	if len(events) != 1 || events[0].Name != "烟雾报警" || events[0].Announce != "注意，检测到烟雾报警" {
+		t.Fatalf("应使用内置规则检测到烟雾报警: %+v", events)
+	}
+
+	now = now.Add(time.Minute)
+	if events := m.match(tags); len(events) != 0 {
+		t.Errorf("冷却期内不应重复触发: %+v", events)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if events := m.match(tags); len(events) != 1 {
+		t.Errorf("冷却期过后应再次触发: %+v", events)
+	}
+}
+
+func TestMonitor_Announce(t *testing.T) {
+	m := NewMonitor(&fakeTagger{}, config.SoundEventsConfig{Events: []config.SoundEventRule{
+		{Name: "门铃", Labels: []string{"Doorbell"}, Announce: "有人按门铃"},
+		{Name: "狗叫", Labels: []string{"Bark"}, Announce: "-"},
+	}}, 16000)
+
+	events := m.match([]Tag{{Label: "Doorbell", Score: 0.6}, {Label: "Bark", Score: 0.7}})
+	if len(events) != 2 {
+		t.Fatalf("期望 2 个事件，实际 %+v", events)
+	}
+	if events[0].Announce != "有人按门铃" || events[1].Announce != "" {
+		t.Errorf("播报内容不正确: %+v", events)
+	}
+}
+
+func TestMonitor_Feed(t *testing.T) {
+	tagger := &fakeTagger{tags: []Tag{{Label: "Baby cry, infant cry", Score: 0.7}}}
+	m := NewMonitor(tagger, config.SoundEventsConfig{Window: 0.5, Interval: 0.5, MinLevelDB: -50}, 16000)
+
+	got := make(chan Event, 4)
+	m.SetHandler(func(ev Event) { got <- ev })
+
+	// 安静的音频不分析
+	for i := 0; i < 20; i++ {
+		m.Feed(make([]float32, 512))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if tagger.Calls() != 0 {
+		t.Errorf("静音时不应调用标注模型，实际 %d 次", tagger.Calls())
+	}
+
+	for i := 0; i < 20; i++ {
+		m.Feed(loudFrame(512))
+	}
+	select {
+	case ev := <-got:
+		if ev.Name != "宝宝哭声" {
+			t.Errorf("事件不正确: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("应检测到宝宝哭声")
+	}
+}
@@ -0,0 +1,79 @@
+package sound
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Tag 音频标注结果中的一个类别。
+type Tag struct {
+	Label string
+	Score float32
+}
+
+// Tagger 对一段音频打标签，返回置信度最高的若干类别。
+type Tagger interface {
+	Tag(samples []float32) []Tag
+}
+
+// tagTopK 每次分析返回的类别数。
+const tagTopK = 5
+
+// SherpaTagger 封装 sherpa-onnx 音频标注（AudioSet 527 类）。
+type SherpaTagger struct {
+	mu         sync.Mutex
+	tagging    *sherpa.AudioTagging
+	sampleRate int
+}
+
+// NewSherpaTagger 创建音频标注器。modelPath 为 zipformer 音频标注模型，labelsPath 为 class_labels_indices.csv。
+func NewSherpaTagger(modelPath, labelsPath string, numThreads, sampleRate int) (*SherpaTagger, error) {
+	config := sherpa.AudioTaggingConfig{
+		Model: sherpa.AudioTaggingModelConfig{
+			Zipformer:  sherpa.OfflineZipformerAudioTaggingModelConfig{Model: modelPath},
+			NumThreads: int32(numThreads),
+			Provider:   "cpu",
+		},
+		Labels: labelsPath,
+		TopK:   tagTopK,
+	}
+	tagging := sherpa.NewAudioTagging(&config)
+	if tagging == nil {
+		return nil, fmt.Errorf("创建音频标注器失败，模型: %s", modelPath)
+	}
+	logger.Infof("[sound] 音频标注器已创建: model=%s", modelPath)
+	return &SherpaTagger{tagging: tagging, sampleRate: sampleRate}, nil
+}
+
+// Tag 分析一段音频。
+func (t *SherpaTagger) Tag(samples []float32) []Tag {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tagging == nil {
+		return nil
+	}
+
+	stream := sherpa.NewAudioTaggingStream(t.tagging)
+	defer sherpa.DeleteOfflineStream(stream)
+	stream.AcceptWaveform(t.sampleRate, samples)
+
+	events := t.tagging.Compute(stream, tagTopK)
+	tags := make([]Tag, 0, len(events))
+	for _, e := range events {
+		tags = append(tags, Tag{Label: e.Name, Score: e.Prob})
+	}
+	return tags
+}
+
+// Close 释放模型资源。
+func (t *SherpaTagger) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tagging != nil {
+		sherpa.DeleteAudioTagging(t.tagging)
+		t.tagging = nil
+	}
+}
@@ -0,0 +1,41 @@
+package sound
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookClient 发送 Webhook 的 HTTP 客户端。
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// PostWebhook 将事件以 JSON POST 到 url（如 Home Assistant 的 webhook 自动化、Server 酱等推送服务）。
+func PostWebhook(ctx context.Context, url string, ev Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event": ev.Name,
+		"label": ev.Label,
+		"score": ev.Score,
+		"time":  ev.Time.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 Webhook 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Webhook 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -13,10 +13,14 @@ import (
 
 // AlarmEntry 闹钟条目。
 type AlarmEntry struct {
-	ID      string `json:"id"`
-	Time    string `json:"time"`
-	Message string `json:"message"`
-	Created string `json:"created"`
+	ID        string `json:"id"`
+	Time      string `json:"time"`
+	Message   string `json:"message"`
+	Created   string `json:"created"`
+	Context   string `json:"context,omitempty"`   // 跟进提醒关联的对话总结，普通闹钟为空
+	Gentle    bool   `json:"gentle,omitempty"`    // 渐进唤醒：到点后灯光逐渐调亮、音乐逐渐变响
+	Challenge bool   `json:"challenge,omitempty"` // 答题关闹钟：答对一道口算或常识题才能关掉
+	Yearly    bool   `json:"yearly,omitempty"`    // 每年重复（纪念日），响过后自动顺延到明年
 }
 
 // AlarmStore 闹钟持久化存储。
@@ -89,7 +93,7 @@ func (s *AlarmStore) Delete(id string) bool {
 	return false
 }
 
-// PopDueAlarms 弹出所有到期闹钟。
+// PopDueAlarms 弹出所有到期闹钟，每年重复的闹钟顺延到明年。
 func (s *AlarmStore) PopDueAlarms() []AlarmEntry {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -104,6 +108,14 @@ func (s *AlarmStore) PopDueAlarms() []AlarmEntry {
 		}
 		if now.After(t) {
 			due = append(due, a)
+			if a.Yearly {
+				for !t.After(now) {
+					t = t.AddDate(1, 0, 0)
+				}
+				next := a
+				next.Time = t.Format("2006-01-02 15:04")
+				remaining = append(remaining, next)
+			}
 		} else {
 			remaining = append(remaining, a)
 		}
@@ -127,7 +139,8 @@ func NewSetAlarmTool(store *AlarmStore) *SetAlarmTool {
 
 func (t *SetAlarmTool) Name() string { return "set_alarm" }
 func (t *SetAlarmTool) Description() string {
-	return "设置闹钟或提醒。当用户说'提醒我'、'设个闹钟'等时使用。"
+	return "设置闹钟或提醒。当用户说'提醒我'、'设个闹钟'等时使用。" +
+		"用户说'温柔地叫我'、'慢慢叫醒我'、'用灯光和音乐叫我起床'时设置 gentle。"
 }
 func (t *SetAlarmTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -140,6 +153,14 @@ func (t *SetAlarmTool) Parameters() json.RawMessage {
 			"message": {
 				"type": "string",
 				"description": "提醒内容"
+			},
+			"gentle": {
+				"type": "boolean",
+				"description": "渐进唤醒：到点后灯光逐渐调亮、音乐逐渐变响，最后再语音提醒"
+			},
+			"challenge": {
+				"type": "boolean",
+				"description": "答题关闹钟：用户说'答对题才能关'、'怕起不来'时设置，响起时要答对一道题才能关掉"
 			}
 		},
 		"required": ["time", "message"]
@@ -147,8 +168,10 @@ func (t *SetAlarmTool) Parameters() json.RawMessage {
 }
 
 type setAlarmArgs struct {
-	Time    string `json:"time"`
-	Message string `json:"message"`
+	Time      string `json:"time"`
+	Message   string `json:"message"`
+	Gentle    bool   `json:"gentle"`
+	Challenge bool   `json:"challenge"`
 }
 
 func (t *SetAlarmTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
@@ -169,15 +192,23 @@ func (t *SetAlarmTool) Execute(ctx context.Context, args json.RawMessage) (strin
 
 	id := fmt.Sprintf("alarm_%d", time.Now().UnixMilli())
 	entry := AlarmEntry{
-		ID:      id,
-		Time:    a.Time,
-		Message: a.Message,
-		Created: time.Now().Format("2006-01-02 15:04:05"),
+		ID:        id,
+		Time:      a.Time,
+		Message:   a.Message,
+		Created:   time.Now().Format("2006-01-02 15:04:05"),
+		Gentle:    a.Gentle,
+		Challenge: a.Challenge,
 	}
 
 	if err := t.store.Add(entry); err != nil {
 		return "", fmt.Errorf("保存闹钟失败: %w", err)
 	}
+	if a.Gentle {
+		return fmt.Sprintf("闹钟已设置: %s, 提醒内容: %s（到点后灯光和音乐会慢慢叫醒你）", a.Time, a.Message), nil
+	}
+	if a.Challenge {
+		return fmt.Sprintf("闹钟已设置: %s, 提醒内容: %s（响起时要答对一道题才能关掉）", a.Time, a.Message), nil
+	}
 
 	return fmt.Sprintf("闹钟已设置: %s, 提醒内容: %s", a.Time, a.Message), nil
 }
@@ -207,6 +238,22 @@ func (t *ListAlarmsTool) Execute(ctx context.Context, args json.RawMessage) (str
 	}
 	result := fmt.Sprintf("当前有 %d 个闹钟:\n", len(alarms))
 	for i, a := range alarms {
+		if a.Context != "" {
+			result += fmt.Sprintf("%d. [%s] %s - 跟进: %s（%s）\n", i+1, a.ID, a.Time, a.Message, a.Context)
+			continue
+		}
+		if a.Gentle {
+			result += fmt.Sprintf("%d. [%s] %s - %s（渐进唤醒）\n", i+1, a.ID, a.Time, a.Message)
+			continue
+		}
+		if a.Challenge {
+			result += fmt.Sprintf("%d. [%s] %s - %s（答题关闹钟）\n", i+1, a.ID, a.Time, a.Message)
+			continue
+		}
+		if a.Yearly {
+			result += fmt.Sprintf("%d. [%s] %s - %s（每年）\n", i+1, a.ID, a.Time, a.Message)
+			continue
+		}
 		result += fmt.Sprintf("%d. [%s] %s - %s\n", i+1, a.ID, a.Time, a.Message)
 	}
 	return result, nil
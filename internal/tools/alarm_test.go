@@ -165,6 +165,16 @@ func TestSetAlarmTool_Execute(t *testing.T) {
 	if len(alarms) != 1 {
 		t.Errorf("expected 1 alarm stored, got %d", len(alarms))
 	}
+
+	// Gentle alarm
+	args, _ = json.Marshal(setAlarmArgs{Time: futureTime, Message: "起床", Gentle: true})
+	if _, err := tool.Execute(context.Background(), args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alarms = store.List()
+	if len(alarms) != 2 || !alarms[1].Gentle {
+		t.Errorf("expected gentle alarm stored, got %+v", alarms)
+	}
 }
 
 func TestSetAlarmTool_PastTime(t *testing.T) {
@@ -259,3 +269,35 @@ func TestDeleteAlarmTool_Execute(t *testing.T) {
 		t.Errorf("should say not found, got %q", result)
 	}
 }
+
+func TestAddFollowUpTool(t *testing.T) {
+	store, err := NewAlarmStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create alarm store: %v", err)
+	}
+	tool := NewAddFollowUpTool(store)
+
+	when := time.Now().Add(48 * time.Hour).Format("2006-01-02 15:04")
+	args := `{"time":"` + when + `","topic":"装修报价","summary":"对比了两家报价，等对方周五回复是否含主材"}`
+	result, err := tool.Execute(context.Background(), json.RawMessage(args))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(result, "装修报价") {
+		t.Errorf("unexpected result: %s", result)
+	}
+
+	alarms := store.List()
+	if len(alarms) != 1 || alarms[0].Context != "对比了两家报价，等对方周五回复是否含主材" || !strings.HasPrefix(alarms[0].ID, "followup_") {
+		t.Fatalf("unexpected alarms: %+v", alarms)
+	}
+	want := "跟进提醒: 该跟进装修报价了。上次聊到: 对比了两家报价，等对方周五回复是否含主材"
+	if got := FollowUpAnnouncement(alarms[0]); got != want {
+		t.Errorf("FollowUpAnnouncement = %q, want %q", got, want)
+	}
+
+	past := `{"time":"2000-01-01 09:00","topic":"x","summary":"y"}`
+	if _, err := tool.Execute(context.Background(), json.RawMessage(past)); err == nil {
+		t.Error("过去的时间应返回错误")
+	}
+}
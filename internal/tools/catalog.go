@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// CatalogEntry 工具目录中的一项：名称、描述、参数 JSON Schema 和所属分组，用于生成能力说明文档。
+type CatalogEntry struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+	Groups      []string        `json:"groups,omitempty"`     // 所属的意图分组（tools.groups），未分组的工具始终发送给大模型
+	OwnerOnly   bool            `json:"owner_only,omitempty"` // 只有主人可用（由调用方填写）
+}
+
+// Catalog 返回所有已注册工具的目录，按名称排序。
+func (r *Registry) Catalog() []CatalogEntry {
+	groups := DefaultToolGroups
+	if r.grouping != nil {
+		groups = r.grouping.groups
+	}
+	memberOf := make(map[string][]string)
+	for _, g := range groups {
+		for _, name := range g.Tools {
+			memberOf[name] = append(memberOf[name], g.Name)
+		}
+	}
+
+	entries := make([]CatalogEntry, 0, len(r.tools))
+	for _, t := range r.tools {
+		params := t.Parameters()
+		if len(params) == 0 {
+			params = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		entries = append(entries, CatalogEntry{
+			Name:        t.Name(),
+			Description: t.Description(),
+			Parameters:  params,
+			Groups:      memberOf[t.Name()],
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// CatalogOpenAPI 把工具目录转换为 OpenAPI 3 文档：每个工具是一个 POST /tools/{name} 操作，
+// 请求体为工具参数，按分组打标签，便于用现成的文档工具生成页面。
+func CatalogOpenAPI(entries []CatalogEntry, title, version string) map[string]interface{} {
+	paths := make(map[string]interface{}, len(entries))
+	for _, e := range entries {
+		op := map[string]interface{}{
+			"operationId": e.Name,
+			"summary":     e.Description,
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": e.Parameters},
+				},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "工具返回给大模型的结果"},
+			},
+		}
+		if len(e.Groups) > 0 {
+			op["tags"] = e.Groups
+		}
+		if e.OwnerOnly {
+			op["x-owner-only"] = true
+		}
+		paths["/tools/"+e.Name] = map[string]interface{}{"post": op}
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": title, "version": version},
+		"paths":   paths,
+	}
+}
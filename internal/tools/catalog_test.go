@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"play_music", "get_weather", "my_custom_tool"} {
+		r.Register(namedTool{name: name})
+	}
+
+	entries := r.Catalog()
+	if len(entries) != 3 {
+		t.Fatalf("catalog has %d entries, want 3", len(entries))
+	}
+	if entries[0].Name != "get_weather" || entries[2].Name != "play_music" {
+		t.Errorf("catalog should be sorted by name: %v", entries)
+	}
+	if len(entries[0].Groups) != 1 || entries[0].Groups[0] != "info" {
+		t.Errorf("get_weather groups = %v, want [info]", entries[0].Groups)
+	}
+	if len(entries[1].Groups) != 0 {
+		t.Errorf("custom tool should not belong to any group: %v", entries[1].Groups)
+	}
+
+	entries[2].OwnerOnly = true
+	doc := CatalogOpenAPI(entries, "PiBuddy 工具", "dev")
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Post struct {
+				OperationID string   `json:"operationId"`
+				Tags        []string `json:"tags"`
+				OwnerOnly   bool     `json:"x-owner-only"`
+			} `json:"post"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	op := parsed.Paths["/tools/play_music"].Post
+	if parsed.OpenAPI == "" || op.OperationID != "play_music" || len(op.Tags) != 1 || op.Tags[0] != "music" || !op.OwnerOnly {
+		t.Errorf("unexpected OpenAPI operation: %+v", op)
+	}
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// defaultCityAliases 常见城市别称，和风天气的城市搜索不认识这些叫法。
+var defaultCityAliases = map[string]string{
+	"帝都": "北京",
+	"京城": "北京",
+	"魔都": "上海",
+	"申城": "上海",
+	"羊城": "广州",
+	"花城": "广州",
+	"鹏城": "深圳",
+	"江城": "武汉",
+	"山城": "重庆",
+	"雾都": "重庆",
+	"蓉城": "成都",
+	"春城": "昆明",
+	"泉城": "济南",
+	"冰城": "哈尔滨",
+	"榕城": "福州",
+	"星城": "长沙",
+	"金陵": "南京",
+	"津门": "天津",
+	"鹭岛": "厦门",
+	"杭城": "杭州",
+}
+
+// hereCityWords 指代"当前所在地"的说法，LLM 经常把"这里的天气"中的"这里"原样传入。
+var hereCityWords = map[string]bool{
+	"这里":      true,
+	"这儿":      true,
+	"这边":      true,
+	"本地":      true,
+	"当地":      true,
+	"我家":      true,
+	"家里":      true,
+	"我们这里":    true,
+	"我们这儿":    true,
+	"我们这边":    true,
+	"current": true,
+	"home":    true,
+	"local":   true,
+}
+
+// errNoHomeCity 用户说"这里"但没有可用的所在城市。
+var errNoHomeCity = errors.New("未设置所在城市")
+
+// cityResolver 城市名解析：先按别称表转换，再查数据库缓存的 LocationID，减少 Geo API 调用。
+type cityResolver struct {
+	db      *database.DB // 可为 nil，此时不缓存
+	aliases map[string]string
+
+	// "这里""我家"的解析：优先当前说话人偏好中的所在城市，其次设备默认城市
+	homeCity    func() string
+	defaultCity string
+}
+
+// newCityResolver 创建城市解析器，extra 为配置文件中的自定义别称（覆盖内置别称）。
+func newCityResolver(db *database.DB, extra map[string]string) *cityResolver {
+	aliases := make(map[string]string, len(defaultCityAliases)+len(extra))
+	for k, v := range defaultCityAliases {
+		aliases[k] = v
+	}
+	for k, v := range extra {
+		aliases[normalizeCityName(k)] = v
+	}
+	return &cityResolver{db: db, aliases: aliases}
+}
+
+// normalizeCityName 规范化城市名："北京市"、"北京的"、" 北京 " 都视为"北京"。
+func normalizeCityName(city string) string {
+	city = strings.TrimSpace(city)
+	city = strings.TrimSuffix(city, "的")
+	if len([]rune(city)) > 2 {
+		city = strings.TrimSuffix(city, "市")
+	}
+	return city
+}
+
+// isHereCity 判断城市参数是否指代当前所在地。
+func isHereCity(city string) bool {
+	return hereCityWords[strings.ToLower(normalizeCityName(city))]
+}
+
+// Home 返回"这里"对应的城市：当前说话人的所在城市，否则设备默认城市，都没有时返回空。
+func (r *cityResolver) Home() string {
+	if r == nil {
+		return ""
+	}
+	if r.homeCity != nil {
+		if city := r.homeCity(); city != "" {
+			return city
+		}
+	}
+	return r.defaultCity
+}
+
+// Resolve 返回用于查询的城市名（别称已转换，"这里"已替换为所在城市）。
+func (r *cityResolver) Resolve(city string) (string, error) {
+	if isHereCity(city) {
+		home := r.Home()
+		if home == "" {
+			return "", errNoHomeCity
+		}
+		logger.Debugf("[tools] 城市 %q 按所在城市查询: %s", city, home)
+		city = home
+	}
+	city = normalizeCityName(city)
+	if r == nil {
+		return city, nil
+	}
+	if real, ok := r.aliases[city]; ok {
+		logger.Debugf("[tools] 城市别称: %s → %s", city, real)
+		return real, nil
+	}
+	return city, nil
+}
+
+// Get 从数据库读取缓存的城市信息。
+func (r *cityResolver) Get(query string) (*cityInfo, bool) {
+	if r == nil || r.db == nil {
+		return nil, false
+	}
+	var c cityInfo
+	err := r.db.QueryRow(`SELECT location_id, name, latitude, longitude FROM city_cache WHERE query = ?`, query).
+		Scan(&c.ID, &c.Name, &c.Latitude, &c.Longitude)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("[tools] 读取城市缓存失败: %v", err)
+		}
+		return nil, false
+	}
+	return &c, true
+}
+
+// Put 缓存城市信息。城市的 LocationID 基本不会变化，不设过期时间。
+func (r *cityResolver) Put(query string, c *cityInfo) {
+	if r == nil || r.db == nil {
+		return
+	}
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO city_cache (query, location_id, name, latitude, longitude, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`, query, c.ID, c.Name, c.Latitude, c.Longitude)
+	if err != nil {
+		logger.Warnf("[tools] 保存城市缓存失败: %v", err)
+	}
+}
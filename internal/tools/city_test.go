@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func TestCityResolver_Resolve(t *testing.T) {
+	r := newCityResolver(nil, map[string]string{"老家": "襄阳", "魔都": "上海浦东"})
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"帝都", "北京"},
+		{"羊城", "广州"},
+		{"老家", "襄阳"},
+		{"魔都", "上海浦东"}, // 配置覆盖内置别称
+		{"北京市", "北京"},
+		{" 武汉的", "武汉"},
+		{"沙市", "沙市"}, // 两个字的不去掉"市"
+		{"襄阳", "襄阳"},
+	}
+	for _, tt := range tests {
+		if got, err := r.Resolve(tt.input); err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCityResolver_Home(t *testing.T) {
+	r := newCityResolver(nil, nil)
+	if _, err := r.Resolve("这里"); !errors.Is(err, errNoHomeCity) {
+		t.Errorf("未设置所在城市时应返回 errNoHomeCity，实际 %v", err)
+	}
+
+	r.defaultCity = "武汉"
+	for _, input := range []string{"这里", "我家", "我们这边", "current", "Home"} {
+		if got, _ := r.Resolve(input); got != "武汉" {
+			t.Errorf("Resolve(%q) = %q, want 武汉（设备默认城市）", input, got)
+		}
+	}
+
+	// 说话人设置了所在城市时优先使用，同样支持别称
+	speakerCity := "魔都"
+	r.homeCity = func() string { return speakerCity }
+	if got, _ := r.Resolve("这里"); got != "上海" {
+		t.Errorf("Resolve(这里) = %q, want 上海", got)
+	}
+	speakerCity = ""
+	if got, _ := r.Resolve("这儿"); got != "武汉" {
+		t.Errorf("说话人未设置时应回退到默认城市，实际 %q", got)
+	}
+}
+
+func TestWeatherTool_NoHomeCity(t *testing.T) {
+	tool := NewWeatherTool(WeatherConfig{APIKey: "k"})
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"city":"这里"}`))
+	if err != nil {
+		t.Fatalf("Execute 不应返回错误: %v", err)
+	}
+	if result != noHomeCityMsg {
+		t.Errorf("应提示用户说明城市，实际: %s", result)
+	}
+
+	aq := NewAirQualityTool(tool)
+	result, err = aq.Execute(context.Background(), json.RawMessage(`{"city":"我家"}`))
+	if err != nil || result != noHomeCityMsg {
+		t.Errorf("空气质量工具应提示用户说明城市，实际: %s, %v", result, err)
+	}
+}
+
+func TestWeatherTool_CityCache(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	var geoHits int
+	var lastQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/v2/city/lookup", func(w http.ResponseWriter, r *http.Request) {
+		geoHits++
+		lastQuery = r.URL.Query().Get("location")
+		fmt.Fprint(w, `{"code":"200","location":[{"name":"北京","id":"101010100","lat":"39.90","lon":"116.40"}]}`)
+	})
+	mux.HandleFunc("/v7/weather/now", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":"200","now":{"temp":"5","text":"晴"}}`)
+	})
+	mux.HandleFunc("/v7/weather/3d", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":"200","daily":[]}`)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	newTool := func() *WeatherTool {
+		tool := NewWeatherTool(WeatherConfig{APIKey: "k", APIHost: strings.TrimPrefix(server.URL, "https://"), DB: db})
+		tool.client = server.Client()
+		return tool
+	}
+
+	result, err := newTool().Execute(context.Background(), json.RawMessage(`{"city":"帝都"}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if lastQuery != "北京" {
+		t.Errorf("别称应转换后再查询，实际查询 %q", lastQuery)
+	}
+	if !strings.Contains(result, "北京") {
+		t.Errorf("结果应包含城市名: %s", result)
+	}
+
+	// 新建工具（模拟重启）后，城市信息从数据库读取
+	city, err := newTool().lookupCity(context.Background(), "北京市")
+	if err != nil {
+		t.Fatalf("lookupCity 失败: %v", err)
+	}
+	if geoHits != 1 {
+		t.Errorf("城市应命中数据库缓存，Geo API 调用 %d 次", geoHits)
+	}
+	if city.ID != "101010100" || city.Latitude != "39.90" {
+		t.Errorf("缓存的城市信息不正确: %+v", city)
+	}
+}
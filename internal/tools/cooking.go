@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cookingUnit 厨房常用单位。质量以克为基准，体积以毫升为基准，温度单独换算。
+type cookingUnit struct {
+	name   string  // 播报用的名称
+	kind   string  // mass、volume 或 temperature
+	factor float64 // 换算成基准单位的倍数
+}
+
+// cookingUnits 单位及其常见说法。
+var cookingUnits = func() map[string]cookingUnit {
+	units := make(map[string]cookingUnit)
+	add := func(u cookingUnit, aliases ...string) {
+		units[u.name] = u
+		for _, a := range aliases {
+			units[a] = u
+		}
+	}
+	add(cookingUnit{"克", "mass", 1}, "g", "gram", "grams")
+	add(cookingUnit{"千克", "mass", 1000}, "公斤", "kg")
+	add(cookingUnit{"斤", "mass", 500}, "市斤")
+	add(cookingUnit{"两", "mass", 50})
+	add(cookingUnit{"钱", "mass", 5})
+	add(cookingUnit{"磅", "mass", 453.592}, "lb", "lbs", "pound")
+	add(cookingUnit{"盎司", "mass", 28.3495}, "oz", "ounce")
+	add(cookingUnit{"毫升", "volume", 1}, "ml", "cc")
+	add(cookingUnit{"升", "volume", 1000}, "l", "公升")
+	add(cookingUnit{"杯", "volume", 240}, "cup", "cups", "量杯")
+	add(cookingUnit{"汤匙", "volume", 15}, "大勺", "大匙", "tbsp", "tablespoon")
+	add(cookingUnit{"茶匙", "volume", 5}, "小勺", "小匙", "tsp", "teaspoon")
+	add(cookingUnit{"液量盎司", "volume", 29.5735}, "fl oz", "floz")
+	add(cookingUnit{"摄氏度", "temperature", 0}, "℃", "c", "摄氏", "度")
+	add(cookingUnit{"华氏度", "temperature", 0}, "℉", "f", "华氏")
+	return units
+}()
+
+// cookingDensity 常见食材的密度（克/毫升），用于杯、勺与克之间的换算。
+var cookingDensity = map[string]float64{
+	"水":   1.0,
+	"牛奶":  1.03,
+	"面粉":  0.53,
+	"白糖":  0.85,
+	"砂糖":  0.85,
+	"糖":   0.85,
+	"糖粉":  0.56,
+	"盐":   1.2,
+	"油":   0.92,
+	"食用油": 0.92,
+	"黄油":  0.96,
+	"大米":  0.85,
+	"米":   0.85,
+	"蜂蜜":  1.42,
+	"酱油":  1.15,
+	"可可粉": 0.42,
+}
+
+// lookupCookingUnit 按名称或常见说法查找单位。
+func lookupCookingUnit(name string) (cookingUnit, bool) {
+	u, ok := cookingUnits[strings.ToLower(strings.TrimSpace(name))]
+	return u, ok
+}
+
+// defaultCookingTarget 未指定目标单位时的默认换算：华氏↔摄氏，其他质量换成克（克换成斤），体积换成毫升。
+func defaultCookingTarget(from cookingUnit) cookingUnit {
+	switch {
+	case from.name == "华氏度":
+		return cookingUnits["摄氏度"]
+	case from.name == "摄氏度":
+		return cookingUnits["华氏度"]
+	case from.name == "克":
+		return cookingUnits["斤"]
+	case from.kind == "mass":
+		return cookingUnits["克"]
+	}
+	if from.name == "毫升" {
+		return cookingUnits["杯"]
+	}
+	return cookingUnits["毫升"]
+}
+
+// convertCooking 单位换算。质量与体积之间的换算需要食材密度。
+func convertCooking(value float64, from, to cookingUnit, ingredient string) (float64, error) {
+	if from.kind == "temperature" || to.kind == "temperature" {
+		if from.kind != to.kind {
+			return 0, fmt.Errorf("温度不能换算成%s", to.name)
+		}
+		switch {
+		case from.name == to.name:
+			return value, nil
+		case from.name == "华氏度":
+			return (value - 32) * 5 / 9, nil
+		default:
+			return value*9/5 + 32, nil
+		}
+	}
+
+	base := value * from.factor
+	if from.kind != to.kind {
+		density, ok := cookingDensity[strings.TrimSpace(ingredient)]
+		if !ok {
+			return 0, fmt.Errorf("%s和%s之间的换算需要知道是什么食材", from.name, to.name)
+		}
+		if from.kind == "volume" {
+			base *= density
+		} else {
+			base /= density
+		}
+	}
+	return base / to.factor, nil
+}
+
+// formatCookingValue 换算结果保留合适的精度：温度取整，其余最多一位小数。
+func formatCookingValue(v float64, unit cookingUnit) string {
+	if unit.kind == "temperature" {
+		return strconv.Itoa(int(math.Round(v)))
+	}
+	if math.Abs(v) >= 10 {
+		v = math.Round(v)
+	} else {
+		v = math.Round(v*10) / 10
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// CookingConvertTool 厨房单位换算，可顺便定一个倒计时（"烤箱华氏350度是多少摄氏度，顺便帮我定25分钟"）。
+type CookingConvertTool struct {
+	timers *TimerStore
+}
+
+// NewCookingConvertTool 创建厨房单位换算工具，timers 为 nil 时不支持顺便定时。
+func NewCookingConvertTool(timers *TimerStore) *CookingConvertTool {
+	return &CookingConvertTool{timers: timers}
+}
+
+func (t *CookingConvertTool) Name() string { return "convert_cooking_unit" }
+
+func (t *CookingConvertTool) Description() string {
+	return "厨房单位换算：斤、两、克、磅、盎司、杯、汤匙、茶匙、毫升，华氏度与摄氏度。杯、勺与克之间的换算需提供食材。" +
+		"当用户问'半斤是多少克'、'一杯面粉多少克'、'烤箱华氏350度是多少摄氏度'时使用；" +
+		"如果用户同时要求定时（如'顺便帮我定25分钟'），填写 timer_minutes，本工具会一并设置倒计时，不要再调用 set_timer。"
+}
+
+func (t *CookingConvertTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"value": {
+				"type": "number",
+				"description": "数值，如半斤为 0.5"
+			},
+			"from": {
+				"type": "string",
+				"description": "原单位，如 斤、两、克、磅、杯、汤匙、茶匙、毫升、华氏度、摄氏度"
+			},
+			"to": {
+				"type": "string",
+				"description": "目标单位，不填则按常用方式换算（华氏↔摄氏，质量换成克，体积换成毫升）"
+			},
+			"ingredient": {
+				"type": "string",
+				"description": "食材，如 面粉、白糖、水、牛奶、黄油；杯、勺与克之间换算时需要"
+			},
+			"timer_minutes": {
+				"type": "number",
+				"description": "顺便设置的倒计时（分钟），不需要定时则不填"
+			},
+			"timer_label": {
+				"type": "string",
+				"description": "倒计时的提醒内容，如'烤箱'、'关火'"
+			}
+		},
+		"required": ["value", "from"]
+	}`)
+}
+
+type cookingArgs struct {
+	Value        float64 `json:"value"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Ingredient   string  `json:"ingredient"`
+	TimerMinutes float64 `json:"timer_minutes"`
+	TimerLabel   string  `json:"timer_label"`
+}
+
+func (t *CookingConvertTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a cookingArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	from, ok := lookupCookingUnit(a.From)
+	if !ok {
+		return toJSON(map[string]interface{}{"success": false, "message": fmt.Sprintf("不认识的单位：%s", a.From)}), nil
+	}
+	to := defaultCookingTarget(from)
+	if strings.TrimSpace(a.To) != "" {
+		if to, ok = lookupCookingUnit(a.To); !ok {
+			return toJSON(map[string]interface{}{"success": false, "message": fmt.Sprintf("不认识的单位：%s", a.To)}), nil
+		}
+	}
+
+	value, err := convertCooking(a.Value, from, to, a.Ingredient)
+	if err != nil {
+		return toJSON(map[string]interface{}{"success": false, "message": err.Error()}), nil
+	}
+	text := fmt.Sprintf("%s%s%s约等于%s%s", formatCookingValue(a.Value, from), from.name, a.Ingredient,
+		formatCookingValue(value, to), to.name)
+	result := map[string]interface{}{
+		"success": true,
+		"result":  text,
+	}
+
+	// 顺便定时：在同一次调用中设置倒计时，不依赖大模型再调用 set_timer
+	if a.TimerMinutes > 0 {
+		if t.timers == nil {
+			result["timer"] = "倒计时功能不可用"
+		} else if msg, err := startTimer(t.timers, int(math.Round(a.TimerMinutes*60)), a.TimerLabel); err != nil {
+			result["timer"] = err.Error()
+		} else {
+			result["timer"] = msg
+		}
+	}
+	return toJSON(result), nil
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestCookingConvertTool_Execute(t *testing.T) {
+	tool := NewCookingConvertTool(nil)
+	tests := []struct {
+		args string
+		want string
+	}{
+		{`{"value":0.5,"from":"斤"}`, "0.5斤约等于250克"},
+		{`{"value":350,"from":"华氏度"}`, "350华氏度约等于177摄氏度"},
+		{`{"value":180,"from":"℃","to":"华氏"}`, "180摄氏度约等于356华氏度"},
+		{`{"value":1,"from":"杯","ingredient":"面粉","to":"克"}`, "1杯面粉约等于127克"},
+		{`{"value":2,"from":"tbsp"}`, "2汤匙约等于30毫升"},
+		{`{"value":1,"from":"lb","to":"两"}`, "1磅约等于9.1两"},
+	}
+	for _, tt := range tests {
+		out, err := tool.Execute(context.Background(), json.RawMessage(tt.args))
+		if err != nil {
+			t.Fatalf("Execute(%s) failed: %v", tt.args, err)
+		}
+		var r struct {
+			Success bool   `json:"success"`
+			Result  string `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(out), &r); err != nil {
+			t.Fatalf("解析结果失败: %v", err)
+		}
+		if !r.Success || r.Result != tt.want {
+			t.Errorf("Execute(%s) = %s, want %q", tt.args, out, tt.want)
+		}
+	}
+
+	for _, args := range []string{
+		`{"value":1,"from":"杯","to":"克"}`,   // 缺少食材
+		`{"value":1,"from":"华氏度","to":"克"}`, // 温度不能换算成质量
+		`{"value":1,"from":"桶"}`,            // 不认识的单位
+	} {
+		out, err := tool.Execute(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("Execute(%s) failed: %v", args, err)
+		}
+		var r struct {
+			Success bool `json:"success"`
+		}
+		if err := json.Unmarshal([]byte(out), &r); err != nil || r.Success {
+			t.Errorf("Execute(%s) 应失败, got %s", args, out)
+		}
+	}
+}
+
+func TestCookingConvertTool_WithTimer(t *testing.T) {
+	store, err := NewTimerStore(t.TempDir(), func(entry TimerEntry) {})
+	if err != nil {
+		t.Fatalf("创建 TimerStore 失败: %v", err)
+	}
+	tool := NewCookingConvertTool(store)
+
+	out, err := tool.Execute(context.Background(),
+		json.RawMessage(`{"value":350,"from":"华氏度","timer_minutes":25,"timer_label":"烤箱"}`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var r struct {
+		Timer string `json:"timer"`
+	}
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if r.Timer != "已设置25分钟倒计时，提醒内容：烤箱" {
+		t.Errorf("timer = %q", r.Timer)
+	}
+	timers := store.List()
+	if len(timers) != 1 || timers[0].Duration != 25*60 || timers[0].Label != "烤箱" {
+		t.Errorf("timers = %+v", timers)
+	}
+	for _, e := range timers {
+		store.Cancel(e.ID)
+	}
+}
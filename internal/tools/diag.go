@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/diag"
+)
+
+// DiagBundleTool 生成诊断包（最近的日志、隐去密钥的配置、版本信息、数据库统计），
+// 配置了上传地址时自动上传，方便报告问题。
+type DiagBundleTool struct {
+	create func(ctx context.Context) (path string, uploaded bool, err error)
+}
+
+// NewDiagBundleTool 创建诊断包工具。create 生成诊断包，返回文件路径和是否已上传。
+func NewDiagBundleTool(create func(ctx context.Context) (string, bool, error)) *DiagBundleTool {
+	return &DiagBundleTool{create: create}
+}
+
+func (t *DiagBundleTool) Name() string { return "create_diag_bundle" }
+
+func (t *DiagBundleTool) Description() string {
+	return "生成诊断包，打包最近的日志、配置（已隐去密钥）和版本信息，用于报告问题。" +
+		"当用户说'生成诊断包'、'打包日志'、'导出日志发给开发者'时使用。"
+}
+
+func (t *DiagBundleTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {}
+	}`)
+}
+
+// Timeout 打包后还要上传，大文件或网络慢时超过注册表的默认超时，留出打包的时间。
+func (t *DiagBundleTool) Timeout() time.Duration {
+	return diag.UploadTimeout + 30*time.Second
+}
+
+func (t *DiagBundleTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	path, uploaded, err := t.create(ctx)
+	if err != nil {
+		return "", err
+	}
+	message := fmt.Sprintf("诊断包已生成，保存在 %s", path)
+	if uploaded {
+		message = "诊断包已生成并上传"
+	}
+	return toJSON(map[string]interface{}{
+		"success":  true,
+		"path":     path,
+		"uploaded": uploaded,
+		"message":  message,
+	}), nil
+}
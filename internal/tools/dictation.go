@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DictationResult 开始听写的结果，供 Pipeline 解析后进入听写模式。
+type DictationResult struct {
+	Success   bool   `json:"success"`
+	Action    string `json:"action"` // start
+	Title     string `json:"title,omitempty"`
+	Summarize bool   `json:"summarize"`
+	Message   string `json:"message"`
+}
+
+// DictationStore 听写记录存储，每次记录保存为一个 Markdown 文件。
+type DictationStore struct {
+	dir string
+}
+
+// NewDictationStore 创建听写记录存储，文件保存在 dataDir/dictations 下。
+func NewDictationStore(dataDir string) (*DictationStore, error) {
+	dir := filepath.Join(dataDir, "dictations")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建听写目录失败: %w", err)
+	}
+	return &DictationStore{dir: dir}, nil
+}
+
+// Dir 返回听写文件所在目录。
+func (s *DictationStore) Dir() string {
+	return s.dir
+}
+
+// Save 保存一次听写记录，返回文件路径。summary 为空时不写摘要段落。
+func (s *DictationStore) Save(title, transcript, summary string, started, ended time.Time) (string, error) {
+	if title == "" {
+		title = "语音记录"
+	}
+	name := started.Format("20060102-150405")
+	if t := sanitizeFileName(title); t != "" {
+		name += "-" + t
+	}
+	path := filepath.Join(s.dir, name+".md")
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "- 开始: %s\n", started.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "- 结束: %s\n", ended.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "- 字数: %d\n\n", len([]rune(strings.Join(strings.Fields(transcript), ""))))
+	if summary != "" {
+		fmt.Fprintf(&sb, "## 摘要\n\n%s\n\n", strings.TrimSpace(summary))
+	}
+	fmt.Fprintf(&sb, "## 原文\n\n%s\n", transcript)
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return "", fmt.Errorf("保存听写记录失败: %w", err)
+	}
+	return path, nil
+}
+
+// sanitizeFileName 去掉文件名中的路径分隔符等特殊字符，并限制长度。
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), r < 0x20:
+			return -1
+		case r == ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, ".")
+	if runes := []rune(name); len(runes) > 20 {
+		name = string(runes[:20])
+	}
+	return name
+}
+
+// ---- StartDictationTool ----
+
+// StartDictationTool 开始听写：之后的语音不再交给大模型，而是持续转写，直到用户说"结束记录"。
+type StartDictationTool struct{}
+
+func NewStartDictationTool() *StartDictationTool {
+	return &StartDictationTool{}
+}
+
+func (t *StartDictationTool) Name() string { return "start_dictation" }
+func (t *StartDictationTool) Description() string {
+	return "开始长时间语音记录（会议记录、灵感速记）。当用户说'开始记录'、'帮我记会议'、'我说你记'时使用。" +
+		"开始后用户说的话会被逐句记下，直到用户说'结束记录'，全文保存为文件。不要用于一两句话的简短备忘（用 add_memo）。"
+}
+func (t *StartDictationTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"title": {
+				"type": "string",
+				"description": "记录标题，如'周会'、'产品想法'，用户没说时留空"
+			},
+			"summarize": {
+				"type": "boolean",
+				"description": "结束后是否生成要点摘要，用户要求'整理一下'、'总结要点'时为 true"
+			}
+		}
+	}`)
+}
+
+func (t *StartDictationTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Title     string `json:"title"`
+		Summarize bool   `json:"summarize"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &a); err != nil {
+			return "", fmt.Errorf("参数解析失败: %w", err)
+		}
+	}
+
+	result := DictationResult{
+		Success:   true,
+		Action:    "start",
+		Title:     strings.TrimSpace(a.Title),
+		Summarize: a.Summarize,
+		Message:   "已开始记录，说'结束记录'停止。",
+	}
+	data, _ := json.Marshal(result)
+	return string(data), nil
+}
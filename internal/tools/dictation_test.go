@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDictationStore_Save(t *testing.T) {
+	store, err := NewDictationStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("创建听写存储失败: %v", err)
+	}
+
+	started := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	path, err := store.Save("周会/复盘", "第一项是预算\n第二项是招聘", "- 预算\n- 招聘", started, started.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	if filepath.Dir(path) != store.Dir() {
+		t.Errorf("文件应保存在听写目录下: %s", path)
+	}
+	if got := filepath.Base(path); got != "20260301-093000-周会复盘.md" {
+		t.Errorf("文件名不正确: %s", got)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"# 周会/复盘", "## 摘要", "- 预算", "## 原文", "第二项是招聘", "字数: 12"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("文件内容应包含 %q:\n%s", want, content)
+		}
+	}
+
+	// 无标题、无摘要
+	path, err = store.Save("", "随便记点", "", started.Add(time.Hour), started.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Save 失败: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "## 摘要") {
+		t.Error("没有摘要时不应写摘要段落")
+	}
+}
+
+func TestStartDictationTool(t *testing.T) {
+	tool := NewStartDictationTool()
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"title":" 产品想法 ","summarize":true}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	var result DictationResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("结果应为 JSON: %v", err)
+	}
+	if !result.Success || result.Action != "start" || result.Title != "产品想法" || !result.Summarize {
+		t.Errorf("结果不正确: %+v", result)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // EnglishWordTool 单词查询工具（有道词典）。
@@ -21,7 +23,7 @@ type EnglishWordTool struct {
 // NewEnglishWordTool 创建单词查询工具。
 func NewEnglishWordTool() *EnglishWordTool {
 	return &EnglishWordTool{
-		client: &http.Client{Timeout: 10 * time.Second},
+		client: &http.Client{Timeout: 10 * time.Second, Transport: ratelimit.NewTransport(nil)},
 	}
 }
 
@@ -64,15 +66,19 @@ func (t *EnglishWordTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return "", fmt.Errorf("请提供要查询的单词")
 	}
 
-	return t.queryWord(params.Word)
+	return t.queryWord(ctx, params.Word)
 }
 
 // queryWord 查询单词。
-func (t *EnglishWordTool) queryWord(word string) (string, error) {
+func (t *EnglishWordTool) queryWord(ctx context.Context, word string) (string, error) {
 	// 使用有道词典 suggest API
 	apiURL := fmt.Sprintf("http://dict.youdao.com/suggest?doctype=json&q=%s", url.QueryEscape(word))
 
-	resp, err := t.client.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("查询失败: %w", err)
 	}
@@ -119,7 +125,7 @@ type EnglishDailyTool struct {
 // NewEnglishDailyTool 创建每日一句工具。
 func NewEnglishDailyTool() *EnglishDailyTool {
 	return &EnglishDailyTool{
-		client: &http.Client{Timeout: 10 * time.Second},
+		client: &http.Client{Timeout: 10 * time.Second, Transport: ratelimit.NewTransport(nil)},
 	}
 }
 
@@ -143,15 +149,19 @@ func (t *EnglishDailyTool) Parameters() json.RawMessage {
 
 // Execute 执行工具。
 func (t *EnglishDailyTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	return t.getDailyQuote()
+	return t.getDailyQuote(ctx)
 }
 
 // getDailyQuote 获取每日一句。
-func (t *EnglishDailyTool) getDailyQuote() (string, error) {
+func (t *EnglishDailyTool) getDailyQuote(ctx context.Context) (string, error) {
 	// 金山词霸每日一句 API
 	apiURL := "http://open.iciba.com/dsapi/"
 
-	resp, err := t.client.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("获取每日一句失败: %w", err)
 	}
@@ -249,7 +259,7 @@ func (t *VocabularyTool) Execute(ctx context.Context, args json.RawMessage) (str
 		if meaning == "" {
 			// 自动查询单词释义
 			wordTool := NewEnglishWordTool()
-			result, err := wordTool.queryWord(params.Word)
+			result, err := wordTool.queryWord(ctx, params.Word)
 			if err != nil {
 				meaning = "（释义获取失败）"
 			} else {
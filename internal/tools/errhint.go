@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ToolFailure 一次可识别的工具失败：给用户播报的具体提示和诊断信息。
+type ToolFailure struct {
+	Time   time.Time `json:"time"`
+	Tool   string    `json:"tool"`
+	Code   string    `json:"code"`   // 诊断代码，如 music_cookie_expired
+	Hint   string    `json:"hint"`   // 播报给用户的提示
+	Detail string    `json:"detail"` // 原始错误
+}
+
+// errorRule 错误映射规则：工具名前缀匹配且错误文本包含任一特征串时命中。
+type errorRule struct {
+	code     string
+	tools    []string // 工具名前缀，为空表示所有工具
+	patterns []string // 错误文本特征（不区分大小写）
+	hint     string
+}
+
+// errorRules 常见失败的映射，按顺序匹配，具体的规则放在通用规则前面。
+var errorRules = []errorRule{
+	{
+		code:     "qq_music_cookie_expired",
+		tools:    []string{"play_music", "search_music", "next_music", "resume_music"},
+		patterns: []string{"qq login"},
+		hint:     "QQ音乐登录过期了，请运行 pibuddy-music qq login 在手机上重新扫码",
+	},
+	{
+		code:     "music_cookie_expired",
+		tools:    []string{"play_music", "search_music", "next_music", "resume_music"},
+		patterns: []string{"cookie 可能已过期", "尚未登录", "需要登录"},
+		hint:     "音乐账号登录过期了，请运行 pibuddy-music 重新登录",
+	},
+	{
+		code:     "music_api_down",
+		tools:    []string{"play_music", "search_music", "next_music", "resume_music"},
+		patterns: []string{"服务未运行", "connection refused", "音乐服务不可用"},
+		hint:     "音乐服务没有启动，请检查音乐 API 服务是否在运行",
+	},
+	{
+		code:     "ha_unauthorized",
+		tools:    []string{"ha_"},
+		patterns: []string{"状态码 401", "状态码 403"},
+		hint:     "Home Assistant 的访问令牌无效了，请在配置里更新 token",
+	},
+	{
+		code:     "ha_unreachable",
+		tools:    []string{"ha_"},
+		patterns: []string{"connection refused", "no such host", "i/o timeout", "状态码 502", "状态码 503", "请求失败"},
+		hint:     "连不上 Home Assistant，请检查它是否在运行、网络是否正常",
+	},
+	{
+		code:     "weather_quota_exceeded",
+		tools:    []string{"get_weather", "get_air_quality"},
+		patterns: []string{"code=402", "code=429"},
+		hint:     "天气接口的调用额度用完了，请稍后再试，或者在和风天气控制台升级套餐",
+	},
+	{
+		code:     "weather_auth_failed",
+		tools:    []string{"get_weather", "get_air_quality"},
+		patterns: []string{"code=401", "code=403", "生成 jwt token 失败"},
+		hint:     "天气接口的密钥无效，请检查天气的 api_key 或 JWT 配置",
+	},
+	{
+		code:     "network_down",
+		patterns: []string{"no such host", "network is unreachable"},
+		hint:     "网络好像断了，请检查设备的网络连接",
+	},
+}
+
+// failureMarkers 工具正常返回（err 为空）时，结果中出现这些内容才视为失败，避免误判正常结果。
+var failureMarkers = []string{`"success":false`, "失败", "错误", "error"}
+
+// MapToolError 把常见的工具失败转换为具体的提示。err 非空时按错误匹配，
+// 否则只在 result 表示失败时匹配。无法识别时返回 false，交给大模型按原样处理。
+func MapToolError(tool, result string, err error) (ToolFailure, bool) {
+	text := result
+	if err != nil {
+		text = err.Error()
+	} else if !containsAny(strings.ToLower(result), failureMarkers) {
+		return ToolFailure{}, false
+	}
+	lower := strings.ToLower(text)
+
+	for _, rule := range errorRules {
+		if len(rule.tools) > 0 && !hasAnyPrefix(tool, rule.tools) {
+			continue
+		}
+		if !containsAny(lower, rule.patterns) {
+			continue
+		}
+		return ToolFailure{
+			Time:   time.Now(),
+			Tool:   tool,
+			Code:   rule.code,
+			Hint:   rule.hint,
+			Detail: text,
+		}, true
+	}
+	return ToolFailure{}, false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxToolFailures 诊断记录保留的条数。
+const maxToolFailures = 50
+
+// ToolFailureLog 最近的工具失败记录，供管理 API 诊断。
+type ToolFailureLog struct {
+	mu       sync.Mutex
+	failures []ToolFailure
+}
+
+// NewToolFailureLog 创建工具失败记录。
+func NewToolFailureLog() *ToolFailureLog {
+	return &ToolFailureLog{}
+}
+
+// Add 记录一次失败，超出上限时丢弃最早的。
+func (l *ToolFailureLog) Add(f ToolFailure) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = append(l.failures, f)
+	if over := len(l.failures) - maxToolFailures; over > 0 {
+		l.failures = l.failures[over:]
+	}
+}
+
+// Recent 返回最近的失败记录，最新的在前。
+func (l *ToolFailureLog) Recent() []ToolFailure {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]ToolFailure, len(l.failures))
+	for i, f := range l.failures {
+		out[len(out)-1-i] = f
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMapToolError(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		result   string
+		err      error
+		wantCode string
+	}{
+		{
+			name:     "QQ 音乐 cookie 过期",
+			tool:     "play_music",
+			result:   `{"success":false,"error":"搜索失败: 获取播放地址失败（cookie 可能已过期，请运行 pibuddy-music qq login --web 重新登录）"}`,
+			wantCode: "qq_music_cookie_expired",
+		},
+		{
+			name:     "音乐服务未启动",
+			tool:     "search_music",
+			result:   `{"success":false,"error":"搜索失败: QQ音乐服务未运行，请先启动音乐 API 服务: dial tcp 127.0.0.1:3300: connect: connection refused"}`,
+			wantCode: "music_api_down",
+		},
+		{
+			name:     "HA 连不上",
+			tool:     "ha_control_device",
+			err:      fmt.Errorf("控制设备失败: %w", errors.New("请求失败: dial tcp 192.168.1.2:8123: i/o timeout")),
+			wantCode: "ha_unreachable",
+		},
+		{
+			name:     "HA token 无效",
+			tool:     "ha_list_devices",
+			err:      errors.New("获取设备列表失败: API 错误 (状态码 401): 401: Unauthorized"),
+			wantCode: "ha_unauthorized",
+		},
+		{
+			name:     "天气额度用完",
+			tool:     "get_weather",
+			err:      errors.New("天气API错误 code=402"),
+			wantCode: "weather_quota_exceeded",
+		},
+		{
+			name:     "断网",
+			tool:     "get_stock",
+			err:      errors.New(`Get "https://qt.gtimg.cn": dial tcp: lookup qt.gtimg.cn: no such host`),
+			wantCode: "network_down",
+		},
+		{
+			name:   "正常结果中的关键词不算失败",
+			tool:   "play_music",
+			result: `{"success":true,"song_name":"服务未运行"}`,
+		},
+		{
+			name: "无法识别的错误交给大模型",
+			tool: "get_weather",
+			err:  errors.New("城市名称不能为空"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure, ok := MapToolError(tt.tool, tt.result, tt.err)
+			if ok != (tt.wantCode != "") || failure.Code != tt.wantCode {
+				t.Fatalf("MapToolError() = %q, %v, want %q", failure.Code, ok, tt.wantCode)
+			}
+			if ok && (failure.Hint == "" || failure.Tool != tt.tool || failure.Detail == "") {
+				t.Errorf("失败记录不完整: %+v", failure)
+			}
+		})
+	}
+}
+
+func TestToolFailureLog(t *testing.T) {
+	log := NewToolFailureLog()
+	for i := 0; i < maxToolFailures+5; i++ {
+		log.Add(ToolFailure{Code: fmt.Sprintf("c%d", i)})
+	}
+	recent := log.Recent()
+	if len(recent) != maxToolFailures {
+		t.Fatalf("记录数 = %d, want %d", len(recent), maxToolFailures)
+	}
+	if recent[0].Code != fmt.Sprintf("c%d", maxToolFailures+4) || recent[len(recent)-1].Code != "c5" {
+		t.Errorf("顺序错误: 第一条 %s, 最后一条 %s", recent[0].Code, recent[len(recent)-1].Code)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // EzvizClient 萤石开放平台 API 客户端。
@@ -31,7 +32,8 @@ func NewEzvizClient(appKey, appSecret string) *EzvizClient {
 		appKey:    appKey,
 		appSecret: appSecret,
 		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
 	}
 }
@@ -417,3 +419,13 @@ func (t *EzvizOpenDoorTool) Execute(ctx context.Context, args json.RawMessage) (
 	logger.Infof("[ezviz] 远程开锁成功: %s (%s)", serial, info.DeviceName)
 	return fmt.Sprintf("门锁 %s 已远程开锁。", info.DeviceName), nil
 }
+
+// MockResult 模拟模式下不访问萤石云，仍然要求确认，确认后直接返回开锁成功。
+func (t *EzvizOpenDoorTool) MockResult(args json.RawMessage) string {
+	var a ezvizOpenDoorArgs
+	json.Unmarshal(args, &a)
+	if !a.Confirm {
+		return "开锁操作需要确认。请再次说「确认开锁」来执行。"
+	}
+	return "门锁已远程开锁。"
+}
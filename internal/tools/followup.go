@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AddFollowUpTool 跟进提醒：把当前对话的主题和要点记下来，到时间后连同总结一起播报。
+// 与 set_alarm 共用闹钟存储，到期时由闹钟检查统一播报。
+type AddFollowUpTool struct {
+	store *AlarmStore
+}
+
+// NewAddFollowUpTool 创建跟进提醒工具。
+func NewAddFollowUpTool(store *AlarmStore) *AddFollowUpTool {
+	return &AddFollowUpTool{store: store}
+}
+
+func (t *AddFollowUpTool) Name() string { return "add_follow_up" }
+
+func (t *AddFollowUpTool) Description() string {
+	return "创建跟进提醒，把正在聊的事情记下来，到时间后提醒用户并复述要点。当用户说'记一下，周五跟进'、'提醒我跟进这件事'、'过两天再提醒我这个'时使用。"
+}
+
+func (t *AddFollowUpTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"time": {
+				"type": "string",
+				"description": "提醒时间，格式为 YYYY-MM-DD HH:MM。用户只说了日期（如'周五'）时用当天 09:00"
+			},
+			"topic": {
+				"type": "string",
+				"description": "要跟进的事情，10 个字以内，如'装修报价'"
+			},
+			"summary": {
+				"type": "string",
+				"description": "根据当前对话生成的简短总结（1-3 句），包括讨论到哪一步、需要跟进什么，到时会原样播报给用户"
+			}
+		},
+		"required": ["time", "topic", "summary"]
+	}`)
+}
+
+func (t *AddFollowUpTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Time    string `json:"time"`
+		Topic   string `json:"topic"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	a.Topic, a.Summary = strings.TrimSpace(a.Topic), strings.TrimSpace(a.Summary)
+	if a.Topic == "" {
+		return "", fmt.Errorf("缺少要跟进的事情")
+	}
+
+	parsedTime, err := time.ParseInLocation("2006-01-02 15:04", a.Time, time.Local)
+	if err != nil {
+		return "", fmt.Errorf("时间格式错误，应为 YYYY-MM-DD HH:MM: %w", err)
+	}
+	if time.Now().After(parsedTime) {
+		return "", fmt.Errorf("跟进时间不能是过去的时间")
+	}
+
+	entry := AlarmEntry{
+		ID:      fmt.Sprintf("followup_%d", time.Now().UnixMilli()),
+		Time:    a.Time,
+		Message: a.Topic,
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Context: a.Summary,
+	}
+	if entry.Context == "" {
+		entry.Context = a.Topic
+	}
+	if err := t.store.Add(entry); err != nil {
+		return "", fmt.Errorf("保存跟进提醒失败: %w", err)
+	}
+
+	return fmt.Sprintf("跟进提醒已设置: %s 跟进「%s」，到时会提醒要点: %s", a.Time, a.Topic, entry.Context), nil
+}
+
+// FollowUpAnnouncement 跟进提醒到期时的播报内容。
+func FollowUpAnnouncement(a AlarmEntry) string {
+	return fmt.Sprintf("跟进提醒: 该跟进%s了。上次聊到: %s", a.Message, a.Context)
+}
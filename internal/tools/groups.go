@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// ToolGroup 一组相关的工具，用户的话包含任一关键词时才发送给大模型。
+type ToolGroup struct {
+	Name     string
+	Keywords []string
+	Tools    []string
+}
+
+// DefaultToolGroups 内置的工具分组。
+var DefaultToolGroups = []ToolGroup{
+	{
+		Name:     "music",
+		Keywords: []string{"歌", "音乐", "播放", "放一首", "来一首", "听", "唱", "下一首", "上一首", "切歌", "收藏", "暂停", "继续放", "接着放", "循环", "随机播放", "缓存", "电台", "广播", "在放什么"},
+		Tools: []string{"search_music", "play_music", "list_music_history", "next_music", "set_play_mode", "list_music_cache",
+			"delete_music_cache", "music_account", "add_favorite", "remove_favorite", "list_favorites", "play_favorites", "resume_music", "stop_music",
+			"play_radio_station", "get_now_playing"},
+	},
+	{
+		Name:     "reminder",
+		Keywords: []string{"闹钟", "提醒", "叫我", "倒计时", "定时", "计时", "分钟后", "小时后", "喝水", "久坐", "吃药", "回头问"},
+		Tools:    []string{"set_alarm", "list_alarms", "delete_alarm", "set_timer", "list_timers", "cancel_timer", "set_health_reminder", "list_health_reminders", "add_follow_up"},
+	},
+	{
+		Name:     "memo",
+		Keywords: []string{"备忘", "记一下", "记下", "记住", "帮我记", "听写", "记录", "待办", "清单", "列一下", "加一项"},
+		Tools:    []string{"add_memo", "list_memos", "delete_memo", "start_dictation", "scratchpad"},
+	},
+	{
+		Name:     "info",
+		Keywords: []string{"天气", "下雨", "下雪", "气温", "温度", "冷不冷", "热不热", "带伞", "空气", "雾霾", "新闻", "股票", "股价", "大盘", "农历", "黄历", "宜", "忌", "节气"},
+		Tools:    []string{"get_weather", "get_air_quality", "get_news", "navigate_news", "get_stock", "get_lunar_date"},
+	},
+	{
+		Name:     "rss",
+		Keywords: []string{"订阅", "rss", "RSS", "OPML", "opml"},
+		Tools:    []string{"add_rss_feed", "list_rss_feeds", "delete_rss_feed", "get_rss_news", "rss_opml", "rss_feed_settings"},
+	},
+	{
+		Name:     "story",
+		Keywords: []string{"故事", "讲一个", "接着讲", "下一集", "下一章"},
+		Tools:    []string{"tell_story", "save_story", "list_stories", "delete_story", "continue_story", "save_story_chapter"},
+	},
+	{
+		Name:     "learning",
+		Keywords: []string{"英语", "英文", "单词", "翻译", "怎么说", "拼音", "汉字", "怎么写", "笔画", "部首", "组词", "诗", "词典", "字典", "背单词", "考考我", "学习", "作业"},
+		Tools: []string{"english_word", "english_daily", "vocabulary", "english_quiz", "lookup_hanzi", "pinyin_query",
+			"poetry_daily", "poetry_search", "poetry_game", "translate", "study_mode"},
+	},
+	{
+		Name:     "home",
+		Keywords: []string{"灯", "空调", "窗帘", "插座", "开关", "风扇", "加湿器", "扫地", "门锁", "开门", "门开", "家电", "设备", "智能家居"},
+		Tools:    []string{"ha_list_devices", "ha_get_device_state", "ha_control_device", "ezviz_list_devices", "ezviz_lock_status", "ezviz_open_door"},
+	},
+	{
+		Name:     "calc",
+		Keywords: []string{"等于", "多少", "加", "减", "乘", "除", "算", "克", "毫升", "勺", "杯", "换算"},
+		Tools:    []string{"calculate", "convert_cooking_unit"},
+	},
+	{
+		Name: "system",
+		Keywords: []string{"音量", "大声", "小声", "声音", "系统", "状态", "运行多久", "运行了多久", "内存", "磁盘", "CPU", "cpu", "诊断", "日志", "wifi", "WiFi", "Wi-Fi", "无线", "网络密码",
+			"声纹", "我是谁", "认识我", "注册", "偏好", "回复风格", "说话方式", "连续聊天", "不用叫", "总结", "今天用了",
+			"设置", "改成", "连续对话", "唤醒回复", "延迟", "访客", "客人", "演示", "模式", "人设", "切换", "扮演"},
+		Tools: []string{"set_volume", "get_volume", "get_system_status", "get_self_status", "create_diag_bundle", "get_guest_wifi", "register_voiceprint", "delete_voiceprint",
+			"set_user_preferences", "whoami", "list_voiceprint_users", "set_reply_style", "set_open_mic", "get_daily_summary", "manage_settings",
+			"set_guest_mode", "switch_persona"},
+	},
+}
+
+// DefaultAlwaysTools 按分组选择工具时始终发送的工具。
+var DefaultAlwaysTools = []string{"get_datetime", "go_to_sleep", "stop_music", "set_volume"}
+
+// toolGrouping 按意图分组暴露工具的设置。
+type toolGrouping struct {
+	groups  []ToolGroup
+	always  map[string]bool
+	grouped map[string]bool // 属于某个分组的工具，未分组的工具（如新增工具）始终发送
+}
+
+// SetGroups 开启按意图分组暴露工具。custom 中与内置分组同名的分组覆盖内置分组；always 为空时使用 DefaultAlwaysTools。
+func (r *Registry) SetGroups(custom []ToolGroup, always []string) {
+	groups := make([]ToolGroup, 0, len(DefaultToolGroups)+len(custom))
+	overridden := make(map[string]bool)
+	for _, g := range custom {
+		overridden[g.Name] = true
+	}
+	for _, g := range DefaultToolGroups {
+		if !overridden[g.Name] {
+			groups = append(groups, g)
+		}
+	}
+	groups = append(groups, custom...)
+
+	if len(always) == 0 {
+		always = DefaultAlwaysTools
+	}
+	g := &toolGrouping{groups: groups, always: make(map[string]bool), grouped: make(map[string]bool)}
+	for _, name := range always {
+		g.always[name] = true
+	}
+	for _, group := range groups {
+		for _, name := range group.Tools {
+			g.grouped[name] = true
+		}
+	}
+	r.grouping = g
+}
+
+// matchGroups 返回用户的话匹配到的分组名。
+func (g *toolGrouping) matchGroups(query string) (names []string, tools map[string]bool) {
+	tools = make(map[string]bool)
+	for _, group := range g.groups {
+		for _, kw := range group.Keywords {
+			if strings.Contains(query, kw) {
+				names = append(names, group.Name)
+				for _, name := range group.Tools {
+					tools[name] = true
+				}
+				break
+			}
+		}
+	}
+	return names, tools
+}
+
+// DefinitionsFor 返回与用户的话相关的工具定义。未开启分组、或没有匹配到任何分组（拿不准）时返回全部工具。
+func (r *Registry) DefinitionsFor(query string) []llm.ToolDefinition {
+	if r.grouping == nil {
+		return r.Definitions()
+	}
+	groups, selected := r.grouping.matchGroups(query)
+	if len(groups) == 0 {
+		logger.Debugf("[tools] 未匹配到工具分组，发送全部 %d 个工具", len(r.tools))
+		return r.Definitions()
+	}
+	defs := r.definitions(func(name string) bool {
+		return selected[name] || r.grouping.always[name] || !r.grouping.grouped[name]
+	})
+	logger.Debugf("[tools] 匹配工具分组 %v，发送 %d/%d 个工具", groups, len(defs), len(r.tools))
+	return defs
+}
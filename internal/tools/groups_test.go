@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+type namedTool struct{ name string }
+
+func (t namedTool) Name() string                { return t.name }
+func (t namedTool) Description() string         { return t.name }
+func (t namedTool) Parameters() json.RawMessage { return json.RawMessage(`{"type":"object"}`) }
+func (t namedTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	return "", nil
+}
+
+func definitionNames(r *Registry, query string) []string {
+	var names []string
+	for _, d := range r.DefinitionsFor(query) {
+		names = append(names, d.Function.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newGroupedRegistry() *Registry {
+	r := NewRegistry()
+	for _, name := range []string{"get_datetime", "get_weather", "play_music", "set_alarm", "custom_tool"} {
+		r.Register(namedTool{name})
+	}
+	return r
+}
+
+func TestDefinitionsFor_Disabled(t *testing.T) {
+	r := newGroupedRegistry()
+	if got := definitionNames(r, "明天天气怎么样"); len(got) != 5 {
+		t.Errorf("未开启分组时应发送全部工具: %v", got)
+	}
+}
+
+func TestDefinitionsFor_MatchesGroup(t *testing.T) {
+	r := newGroupedRegistry()
+	r.SetGroups(nil, []string{"get_datetime"})
+	got := definitionNames(r, "明天天气怎么样")
+	// 匹配分组的工具 + 始终发送的工具 + 未分组的工具
+	want := []string{"custom_tool", "get_datetime", "get_weather"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	// 一句话里有多个意图时合并分组
+	if got := definitionNames(r, "放首歌，再定个明早七点的闹钟"); len(got) != 4 {
+		t.Errorf("多个意图应合并分组: %v", got)
+	}
+}
+
+func TestDefinitionsFor_FallbackWhenUnsure(t *testing.T) {
+	r := newGroupedRegistry()
+	r.SetGroups(nil, nil)
+	if got := definitionNames(r, "那明天呢"); len(got) != 5 {
+		t.Errorf("没有匹配的分组时应发送全部工具: %v", got)
+	}
+}
+
+func TestSetGroups_CustomOverridesBuiltin(t *testing.T) {
+	r := newGroupedRegistry()
+	r.SetGroups([]ToolGroup{{Name: "music", Keywords: []string{"嗨"}, Tools: []string{"play_music", "custom_tool"}}}, []string{"get_datetime"})
+	if got := definitionNames(r, "嗨"); len(got) != 3 || got[0] != "custom_tool" || got[1] != "get_datetime" || got[2] != "play_music" {
+		t.Errorf("自定义分组: %v", got)
+	}
+	// 内置 music 分组被覆盖，"歌"不再匹配
+	if got := definitionNames(r, "放首歌"); len(got) != 5 {
+		t.Errorf("被覆盖的内置分组不应再匹配: %v", got)
+	}
+}
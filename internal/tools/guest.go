@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GuestModeTool 开关访客模式：开启后不写入家里的数据，只开放查询、听歌、讲故事等工具，每次对话后清空上下文。
+type GuestModeTool struct {
+	start func()
+	stop  func() bool
+}
+
+// NewGuestModeTool 创建访客模式工具。start 开启访客模式，stop 退出，未开启时返回 false。
+func NewGuestModeTool(start func(), stop func() bool) *GuestModeTool {
+	return &GuestModeTool{start: start, stop: stop}
+}
+
+func (t *GuestModeTool) Name() string { return "set_guest_mode" }
+
+func (t *GuestModeTool) Description() string {
+	return "开启或退出访客模式（演示模式）：开启后客人可以查天气、听歌、听故事，但不会记录播放历史、备忘、收藏等家庭数据，" +
+		"也不能控制家电、开门、修改设置，每次对话后自动忘记聊天内容。当用户说'开启访客模式'、'家里来客人了'、'退出访客模式'时使用。"
+}
+
+func (t *GuestModeTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"enable": {
+				"type": "boolean",
+				"description": "true 开启，false 退出"
+			}
+		},
+		"required": ["enable"]
+	}`)
+}
+
+func (t *GuestModeTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Enable bool `json:"enable"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	if !a.Enable {
+		if !t.stop() {
+			return toJSON(map[string]interface{}{"success": true, "message": "现在没有开启访客模式"}), nil
+		}
+		return toJSON(map[string]interface{}{"success": true, "message": "已退出访客模式，恢复正常使用"}), nil
+	}
+	t.start()
+	return toJSON(map[string]interface{}{
+		"success": true,
+		"message": "已开启访客模式：不会记录任何家庭数据，家电控制、开门和设置暂时关闭，每次对话后我会忘掉聊天内容；说'退出访客模式'恢复",
+	}), nil
+}
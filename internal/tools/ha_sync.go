@@ -0,0 +1,345 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// haSyncLookahead 从 Home Assistant 日历导入未来多少天内的日程。
+const haSyncLookahead = 7 * 24 * time.Hour
+
+// haEventDuration 闹钟导出为日程时的时长。
+const haEventDuration = 15 * time.Minute
+
+// haLink 本地条目与 Home Assistant 中条目的对应关系。
+type haLink struct {
+	Kind   string `json:"kind"`   // memo 或 alarm
+	Key    string `json:"key"`    // Home Assistant 中的标识：待办为内容，日程为"内容|开始时间"
+	Origin string `json:"origin"` // local（从小派导出）或 ha（从 Home Assistant 导入）
+	Time   string `json:"time,omitempty"`
+}
+
+// HASync 闹钟、备忘录与 Home Assistant 日历、待办列表的双向同步。
+// 小派里新建的闹钟写入日历、备忘录写入待办；手机上在 Home Assistant 里新建的日程和
+// 带时间的待办变成小派的闹钟（到时播报），不带时间的待办变成备忘录。
+// 备忘录的删除（完成）双向同步；日历日程无法通过 REST API 删除，闹钟删除不同步。
+type HASync struct {
+	client         *HomeAssistantClient
+	alarms         *AlarmStore
+	memos          *MemoStore
+	todoEntity     string
+	calendarEntity string
+
+	mu       sync.Mutex
+	filePath string
+	links    map[string]haLink // 本地 ID → 对应关系
+}
+
+// NewHASync 创建同步器。todoEntity、calendarEntity 为空时不同步对应内容。
+func NewHASync(client *HomeAssistantClient, alarms *AlarmStore, memos *MemoStore, dataDir, todoEntity, calendarEntity string) *HASync {
+	s := &HASync{
+		client:         client,
+		alarms:         alarms,
+		memos:          memos,
+		todoEntity:     todoEntity,
+		calendarEntity: calendarEntity,
+		filePath:       filepath.Join(dataDir, "ha_sync.json"),
+		links:          make(map[string]haLink),
+	}
+	if data, err := os.ReadFile(s.filePath); err == nil {
+		if err := json.Unmarshal(data, &s.links); err != nil {
+			logger.Warnf("[tools] 加载 Home Assistant 同步记录失败: %v", err)
+			s.links = make(map[string]haLink)
+		}
+	}
+	return s
+}
+
+func (s *HASync) save() error {
+	data, err := json.MarshalIndent(s.links, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filePath, data, 0644)
+}
+
+// Sync 执行一次双向同步。
+func (s *HASync) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []string
+	if s.todoEntity != "" {
+		if err := s.syncTodo(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if s.calendarEntity != "" {
+		if err := s.syncCalendar(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	} else {
+		// 带时间的待办导入的闹钟同样有对应关系，没有同步日历时也要清理
+		s.pruneAlarmLinks(time.Now())
+	}
+	if err := s.save(); err != nil {
+		errs = append(errs, fmt.Sprintf("保存同步记录失败: %v", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("同步 Home Assistant 失败: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// haTodoItem Home Assistant 待办条目。
+type haTodoItem struct {
+	Summary string `json:"summary"`
+	UID     string `json:"uid"`
+	Status  string `json:"status"`
+	Due     string `json:"due"`
+}
+
+// getTodoItems 获取待办列表中未完成的条目。
+func (s *HASync) getTodoItems(ctx context.Context) ([]haTodoItem, error) {
+	data, err := s.client.doRequest(ctx, "POST", "/api/services/todo/get_items?return_response", map[string]interface{}{
+		"entity_id": s.todoEntity,
+		"status":    "needs_action",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		ServiceResponse map[string]struct {
+			Items []haTodoItem `json:"items"`
+		} `json:"service_response"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析待办列表失败: %w", err)
+	}
+	list, ok := resp.ServiceResponse[s.todoEntity]
+	if !ok {
+		return nil, fmt.Errorf("待办列表响应中没有 %s，请检查实体 ID", s.todoEntity)
+	}
+	return list.Items, nil
+}
+
+// syncTodo 同步备忘录与待办列表，并处理带时间的待办（导入为闹钟）。
+func (s *HASync) syncTodo(ctx context.Context) error {
+	items, err := s.getTodoItems(ctx)
+	if err != nil {
+		return fmt.Errorf("获取待办失败: %w", err)
+	}
+	remote := make(map[string]haTodoItem, len(items))
+	for _, item := range items {
+		remote[item.Summary] = item
+	}
+	// 待办列表为空时可能是实体配错或 Home Assistant 临时异常，不据此删除本地备忘录，
+	// 否则一次异常就会删光所有已同步的备忘录
+	prune := len(items) > 0
+	known := s.knownKeys()
+
+	local := make(map[string]bool)
+	for _, m := range s.memos.List() {
+		local[m.ID] = true
+		if _, linked := s.links[m.ID]; linked {
+			continue
+		}
+		// 小派新建的备忘录 → 待办（带上截止时间）
+		data := map[string]interface{}{
+			"entity_id": s.todoEntity,
+			"item":      m.Content,
+		}
+		if due, hasTime, err := parseMemoDue(m.Due); m.Due != "" && err == nil {
+			if hasTime {
+				data["due_datetime"] = due.Format("2006-01-02 15:04:05")
+			} else {
+				data["due_date"] = m.Due
+			}
+		}
+		if err := s.client.CallService(ctx, "todo", "add_item", data); err != nil {
+			return fmt.Errorf("添加待办失败: %w", err)
+		}
+		s.links[m.ID] = haLink{Kind: "memo", Key: m.Content, Origin: "local"}
+		remote[m.Content] = haTodoItem{Summary: m.Content, Status: "needs_action"}
+		known[m.Content] = true
+		logger.Infof("[tools] 备忘录已同步到 Home Assistant: %s", m.Content)
+	}
+
+	for id, link := range s.links {
+		if link.Kind != "memo" {
+			continue
+		}
+		_, inHA := remote[link.Key]
+		switch {
+		case !local[id] && inHA:
+			// 小派里删除了 → 从待办中移除
+			if err := s.client.CallService(ctx, "todo", "remove_item", map[string]interface{}{
+				"entity_id": s.todoEntity,
+				"item":      link.Key,
+			}); err != nil {
+				logger.Warnf("[tools] 移除待办失败: %v", err)
+				continue
+			}
+			delete(s.links, id)
+		case local[id] && !inHA && prune:
+			// 在 Home Assistant 中完成或删除了 → 删除备忘录
+			s.memos.Delete(id)
+			delete(s.links, id)
+			logger.Infof("[tools] 待办已在 Home Assistant 中完成，删除备忘录: %s", link.Key)
+		case !local[id] && !inHA:
+			delete(s.links, id)
+		}
+	}
+
+	// Home Assistant 中新建的待办 → 带时间的变成闹钟，其余变成备忘录
+	now := time.Now()
+	for _, item := range items {
+		if item.Summary == "" || known[item.Summary] {
+			continue
+		}
+		if due, ok := parseHATime(item.Due); ok {
+			if due.Before(now) {
+				continue
+			}
+			s.importAlarm(item.Summary, due, item.Summary)
+			continue
+		}
+		id := fmt.Sprintf("ha_%d", time.Now().UnixNano())
+		if err := s.memos.Add(MemoEntry{ID: id, Content: item.Summary, Created: now.Format("2006-01-02 15:04:05")}); err != nil {
+			return fmt.Errorf("保存备忘录失败: %w", err)
+		}
+		s.links[id] = haLink{Kind: "memo", Key: item.Summary, Origin: "ha"}
+		logger.Infof("[tools] 从 Home Assistant 导入备忘录: %s", item.Summary)
+	}
+	return nil
+}
+
+// haCalendarEvent Home Assistant 日历日程。
+type haCalendarEvent struct {
+	Summary string `json:"summary"`
+	Start   struct {
+		DateTime string `json:"dateTime"`
+		Date     string `json:"date"`
+	} `json:"start"`
+}
+
+// syncCalendar 同步闹钟与日历：小派的闹钟导出为日程，日历中未来的日程导入为闹钟（全天日程不导入）。
+func (s *HASync) syncCalendar(ctx context.Context) error {
+	now := time.Now()
+	for _, a := range s.alarms.List() {
+		if _, linked := s.links[a.ID]; linked {
+			continue
+		}
+		start, err := time.ParseInLocation("2006-01-02 15:04", a.Time, time.Local)
+		if err != nil {
+			continue
+		}
+		data := map[string]interface{}{
+			"entity_id":       s.calendarEntity,
+			"summary":         a.Message,
+			"start_date_time": start.Format("2006-01-02 15:04:05"),
+			"end_date_time":   start.Add(haEventDuration).Format("2006-01-02 15:04:05"),
+		}
+		if a.Context != "" {
+			data["description"] = a.Context
+		}
+		if err := s.client.CallService(ctx, "calendar", "create_event", data); err != nil {
+			return fmt.Errorf("添加日程失败: %w", err)
+		}
+		s.links[a.ID] = haLink{Kind: "alarm", Key: eventKey(a.Message, start), Origin: "local", Time: a.Time}
+		logger.Infof("[tools] 闹钟已同步到 Home Assistant 日历: %s %s", a.Time, a.Message)
+	}
+
+	s.pruneAlarmLinks(now)
+
+	path := fmt.Sprintf("/api/calendars/%s?start=%s&end=%s", s.calendarEntity,
+		url.QueryEscape(now.Format(time.RFC3339)), url.QueryEscape(now.Add(haSyncLookahead).Format(time.RFC3339)))
+	data, err := s.client.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return fmt.Errorf("获取日程失败: %w", err)
+	}
+	var events []haCalendarEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return fmt.Errorf("解析日程失败: %w", err)
+	}
+
+	known := s.knownKeys()
+	for _, ev := range events {
+		start, ok := parseHATime(ev.Start.DateTime)
+		if !ok || ev.Summary == "" || start.Before(now) {
+			continue
+		}
+		if key := eventKey(ev.Summary, start); !known[key] {
+			s.importAlarm(ev.Summary, start, key)
+		}
+	}
+	return nil
+}
+
+// pruneAlarmLinks 本地闹钟已到期或删除、且时间已过的对应关系不再需要。
+func (s *HASync) pruneAlarmLinks(now time.Time) {
+	local := make(map[string]bool)
+	for _, a := range s.alarms.List() {
+		local[a.ID] = true
+	}
+	for id, link := range s.links {
+		if link.Kind != "alarm" || local[id] {
+			continue
+		}
+		if t, err := time.ParseInLocation("2006-01-02 15:04", link.Time, time.Local); err != nil || t.Before(now) {
+			delete(s.links, id)
+		}
+	}
+}
+
+// importAlarm 把 Home Assistant 中的日程或带时间的待办导入为闹钟。
+func (s *HASync) importAlarm(message string, at time.Time, key string) {
+	entry := AlarmEntry{
+		ID:      fmt.Sprintf("ha_%d", time.Now().UnixNano()),
+		Time:    at.Format("2006-01-02 15:04"),
+		Message: message,
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := s.alarms.Add(entry); err != nil {
+		logger.Warnf("[tools] 保存闹钟失败: %v", err)
+		return
+	}
+	s.links[entry.ID] = haLink{Kind: "alarm", Key: key, Origin: "ha", Time: entry.Time}
+	logger.Infof("[tools] 从 Home Assistant 导入提醒: %s %s", entry.Time, message)
+}
+
+// knownKeys 已同步过的 Home Assistant 条目标识，避免导出的条目再被导入。
+func (s *HASync) knownKeys() map[string]bool {
+	keys := make(map[string]bool, len(s.links))
+	for _, link := range s.links {
+		keys[link.Key] = true
+	}
+	return keys
+}
+
+// eventKey 日程的标识：Home Assistant 创建日程不返回 uid，用内容和开始时间识别。
+func eventKey(summary string, start time.Time) string {
+	return summary + "|" + start.Format("2006-01-02 15:04")
+}
+
+// parseHATime 解析 Home Assistant 返回的时间，只有日期（全天）时返回 false。
+func parseHATime(s string) (time.Time, bool) {
+	if len(s) <= len("2006-01-02") {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Local(), true
+		}
+	}
+	return time.Time{}, false
+}
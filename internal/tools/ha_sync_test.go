@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeHA 模拟 Home Assistant 的待办和日历接口。
+type fakeHA struct {
+	mu     sync.Mutex
+	todos  []haTodoItem
+	events []map[string]interface{}
+	calls  []string
+}
+
+func (f *fakeHA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	var data map[string]interface{}
+	json.Unmarshal(body, &data)
+
+	switch {
+	case r.URL.Path == "/api/services/todo/get_items":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service_response": map[string]interface{}{
+				"todo.pibuddy": map[string]interface{}{"items": f.todos},
+			},
+		})
+	case r.URL.Path == "/api/services/todo/add_item":
+		f.calls = append(f.calls, "add_item:"+data["item"].(string))
+		f.todos = append(f.todos, haTodoItem{Summary: data["item"].(string), Status: "needs_action"})
+		w.Write([]byte("[]"))
+	case r.URL.Path == "/api/services/todo/remove_item":
+		f.calls = append(f.calls, "remove_item:"+data["item"].(string))
+		w.Write([]byte("[]"))
+	case r.URL.Path == "/api/services/calendar/create_event":
+		f.calls = append(f.calls, "create_event:"+data["summary"].(string))
+		w.Write([]byte("[]"))
+	case strings.HasPrefix(r.URL.Path, "/api/calendars/"):
+		json.NewEncoder(w).Encode(f.events)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestHASync(t *testing.T) {
+	dir := t.TempDir()
+	alarms, _ := NewAlarmStore(dir)
+	memos, _ := NewMemoStore(dir)
+
+	later := time.Now().Add(2 * time.Hour).Truncate(time.Minute)
+	fake := &fakeHA{
+		todos: []haTodoItem{
+			{Summary: "买牛奶", Status: "needs_action"},
+			{Summary: "交水费", Status: "needs_action", Due: later.Format(time.RFC3339)},
+		},
+		events: []map[string]interface{}{
+			{"summary": "接孩子", "start": map[string]string{"dateTime": later.Format(time.RFC3339)}},
+			{"summary": "国庆节", "start": map[string]string{"date": later.Format("2006-01-02")}},
+		},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	memos.Add(MemoEntry{ID: "memo_1", Content: "带伞"})
+	alarms.Add(AlarmEntry{ID: "alarm_1", Time: later.Format("2006-01-02 15:04"), Message: "开会"})
+
+	s := NewHASync(NewHomeAssistantClient(srv.URL, "token"), alarms, memos, dir, "todo.pibuddy", "calendar.family")
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if got := strings.Join(fake.calls, ","); got != "add_item:带伞,create_event:开会" {
+		t.Errorf("calls = %s", got)
+	}
+	if n := len(memos.List()); n != 2 {
+		t.Errorf("备忘录数量 = %d, want 2（带伞 + 买牛奶）", n)
+	}
+	var messages []string
+	for _, a := range alarms.List() {
+		messages = append(messages, a.Message)
+	}
+	if got := strings.Join(messages, ","); got != "开会,交水费,接孩子" {
+		t.Errorf("闹钟 = %s（全天日程不应导入）", got)
+	}
+
+	// 再次同步不应重复导出或导入
+	fake.calls = nil
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("second Sync failed: %v", err)
+	}
+	if len(fake.calls) != 0 || len(memos.List()) != 2 || len(alarms.List()) != 3 {
+		t.Errorf("重复同步: calls=%v memos=%d alarms=%d", fake.calls, len(memos.List()), len(alarms.List()))
+	}
+
+	// HA 中完成了"买牛奶"，本地删除了"带伞"
+	fake.todos = fake.todos[1:]
+	memos.Delete("memo_1")
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("third Sync failed: %v", err)
+	}
+	if len(memos.List()) != 0 {
+		t.Errorf("HA 中完成的待办应删除对应备忘录: %v", memos.List())
+	}
+	if got := strings.Join(fake.calls, ","); got != "remove_item:带伞" {
+		t.Errorf("calls = %s", got)
+	}
+
+	// 同步记录持久化
+	s2 := NewHASync(NewHomeAssistantClient(srv.URL, "token"), alarms, memos, dir, "todo.pibuddy", "calendar.family")
+	if len(s2.links) != len(s.links) {
+		t.Errorf("同步记录未持久化: %d != %d", len(s2.links), len(s.links))
+	}
+}
+
+func TestHASyncEmptyTodoListKeepsMemos(t *testing.T) {
+	dir := t.TempDir()
+	alarms, _ := NewAlarmStore(dir)
+	memos, _ := NewMemoStore(dir)
+	fake := &fakeHA{todos: []haTodoItem{{Summary: "买牛奶", Status: "needs_action"}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s := NewHASync(NewHomeAssistantClient(srv.URL, "token"), alarms, memos, dir, "todo.pibuddy", "")
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(memos.List()) != 1 {
+		t.Fatalf("应导入待办, memos=%v", memos.List())
+	}
+
+	// 待办列表突然变空（实体配错、HA 异常）时不删除已同步的备忘录
+	fake.todos = nil
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(memos.List()) != 1 {
+		t.Errorf("待办列表为空时不应删除备忘录, memos=%v", memos.List())
+	}
+
+	// 响应中没有配置的实体时报错，同样不删除
+	s.todoEntity = "todo.wrong"
+	if err := s.Sync(context.Background()); err == nil {
+		t.Error("响应中没有配置的实体时应返回错误")
+	}
+	if len(memos.List()) != 1 {
+		t.Errorf("实体配错时不应删除备忘录, memos=%v", memos.List())
+	}
+}
+
+func TestHASyncPrunesAlarmLinksWithoutCalendar(t *testing.T) {
+	dir := t.TempDir()
+	alarms, _ := NewAlarmStore(dir)
+	memos, _ := NewMemoStore(dir)
+	srv := httptest.NewServer(&fakeHA{todos: []haTodoItem{{Summary: "买牛奶", Status: "needs_action"}}})
+	defer srv.Close()
+
+	s := NewHASync(NewHomeAssistantClient(srv.URL, "token"), alarms, memos, dir, "todo.pibuddy", "")
+	past := time.Now().Add(-time.Hour).Format("2006-01-02 15:04")
+	s.links["ha_gone"] = haLink{Kind: "alarm", Key: "交水费", Origin: "ha", Time: past}
+	if err := s.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if _, ok := s.links["ha_gone"]; ok {
+		t.Error("没有同步日历时也应清理过期闹钟的对应关系")
+	}
+}
@@ -0,0 +1,331 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/mozillazg/go-pinyin"
+)
+
+// maxHanziWords 每个字最多保留的组词数。
+const maxHanziWords = 6
+
+// HanziInfo 一个汉字的查询结果。
+type HanziInfo struct {
+	Char        string   `json:"char"`
+	Pinyin      []string `json:"pinyin"`
+	Strokes     int      `json:"strokes,omitempty"`
+	Radical     string   `json:"radical,omitempty"`
+	Definitions []string `json:"definitions,omitempty"` // 新华字典释义
+	English     []string `json:"english,omitempty"`     // CC-CEDICT 英文释义
+	Words       []string `json:"words,omitempty"`       // 组词
+}
+
+// xinhuaWord 新华字典 word.json 中的一条（chinese-xinhua 项目的格式）。
+type xinhuaWord struct {
+	Word        string `json:"word"`
+	Strokes     string `json:"strokes"`
+	Pinyin      string `json:"pinyin"`
+	Radicals    string `json:"radicals"`
+	Explanation string `json:"explanation"`
+}
+
+// HanziDict 本地汉字字典：新华字典（笔画、部首、释义）和 CC-CEDICT（读音、英文释义、组词），
+// 首次查询时加载，数据文件不存在时只能查拼音。
+type HanziDict struct {
+	cedictPath string
+	xinhuaPath string
+
+	once  sync.Once
+	chars map[rune]*HanziInfo
+}
+
+// NewHanziDict 创建字典，路径为空或文件不存在的数据源会被跳过。
+func NewHanziDict(cedictPath, xinhuaPath string) *HanziDict {
+	return &HanziDict{cedictPath: cedictPath, xinhuaPath: xinhuaPath}
+}
+
+// load 加载字典数据。
+func (d *HanziDict) load() {
+	start := time.Now()
+	d.chars = make(map[rune]*HanziInfo)
+	if err := d.loadXinhua(); err != nil {
+		logger.Warnf("[tools] 加载新华字典失败: %v", err)
+	}
+	if err := d.loadCEDICT(); err != nil {
+		logger.Warnf("[tools] 加载 CC-CEDICT 失败: %v", err)
+	}
+	logger.Infof("[tools] 汉字字典已加载: %d 个字，耗时 %s", len(d.chars), time.Since(start).Round(time.Millisecond))
+}
+
+func (d *HanziDict) entry(r rune) *HanziInfo {
+	info, ok := d.chars[r]
+	if !ok {
+		info = &HanziInfo{Char: string(r)}
+		d.chars[r] = info
+	}
+	return info
+}
+
+func (d *HanziDict) loadXinhua() error {
+	if d.xinhuaPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(d.xinhuaPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var words []xinhuaWord
+	if err := json.Unmarshal(data, &words); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", d.xinhuaPath, err)
+	}
+	for _, w := range words {
+		runes := []rune(w.Word)
+		if len(runes) != 1 {
+			continue
+		}
+		info := d.entry(runes[0])
+		info.Strokes, _ = strconv.Atoi(strings.TrimSpace(w.Strokes))
+		info.Radical = strings.TrimSpace(w.Radicals)
+		for _, py := range strings.Split(w.Pinyin, ",") {
+			info.Pinyin = appendUnique(info.Pinyin, strings.TrimSpace(py))
+		}
+		if exp := strings.TrimSpace(w.Explanation); exp != "" {
+			info.Definitions = append(info.Definitions, truncateRunes(exp, 200))
+		}
+	}
+	return nil
+}
+
+// loadCEDICT 解析 CC-CEDICT：每行 "繁体 简体 [pin1 yin1] /释义1/释义2/"。
+func (d *HanziDict) loadCEDICT() error {
+	if d.cedictPath == "" {
+		return nil
+	}
+	f, err := os.Open(d.cedictPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		simplified, py, defs, ok := parseCEDICTLine(line)
+		if !ok {
+			continue
+		}
+		runes := []rune(simplified)
+		if len(runes) == 1 {
+			info := d.entry(runes[0])
+			info.Pinyin = appendUnique(info.Pinyin, numberedToToneMarks(py))
+			for _, def := range defs {
+				if len(info.English) < 5 {
+					info.English = append(info.English, def)
+				}
+			}
+			continue
+		}
+		// 两个字的词最适合用来组词
+		if len(runes) != 2 {
+			continue
+		}
+		for _, r := range runes {
+			if !unicode.Is(unicode.Han, r) {
+				break
+			}
+			if info := d.entry(r); len(info.Words) < maxHanziWords {
+				info.Words = appendUnique(info.Words, simplified)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// parseCEDICTLine 解析一行 CC-CEDICT，返回简体、带数字声调的拼音和释义。
+func parseCEDICTLine(line string) (simplified, py string, defs []string, ok bool) {
+	open, close := strings.Index(line, "["), strings.Index(line, "]")
+	if open < 0 || close < open {
+		return "", "", nil, false
+	}
+	fields := strings.Fields(line[:open])
+	if len(fields) < 2 {
+		return "", "", nil, false
+	}
+	for _, def := range strings.Split(strings.Trim(strings.TrimSpace(line[close+1:]), "/"), "/") {
+		if def = strings.TrimSpace(def); def != "" {
+			defs = append(defs, def)
+		}
+	}
+	return fields[1], line[open+1 : close], defs, true
+}
+
+// toneMarks 带声调的韵母，下标为声调 1-4。
+var toneMarks = map[rune][]rune{
+	'a': []rune("āáǎà"),
+	'e': []rune("ēéěè"),
+	'i': []rune("īíǐì"),
+	'o': []rune("ōóǒò"),
+	'u': []rune("ūúǔù"),
+	'ü': []rune("ǖǘǚǜ"),
+}
+
+// numberedToToneMarks 把 "tao1"、"lu:4" 这样的数字声调拼音转换为 "tāo"、"lǜ"。
+func numberedToToneMarks(py string) string {
+	syllables := strings.Fields(py)
+	for i, s := range syllables {
+		s = strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(s, "u:", "ü"), "v", "ü"))
+		if s == "" || s[len(s)-1] < '0' || s[len(s)-1] > '9' {
+			syllables[i] = s
+			continue
+		}
+		tone := int(s[len(s)-1] - '0')
+		runes := []rune(s[:len(s)-1])
+		if tone < 1 || tone > 4 {
+			syllables[i] = string(runes)
+			continue
+		}
+		// 声调标在 a、e 上，ou 标在 o 上，否则标在最后一个元音上
+		pos := -1
+		for j, r := range runes {
+			if r == 'a' || r == 'e' {
+				pos = j
+				break
+			}
+			if r == 'o' && j+1 < len(runes) && runes[j+1] == 'u' {
+				pos = j
+				break
+			}
+			if _, ok := toneMarks[r]; ok {
+				pos = j
+			}
+		}
+		if pos >= 0 {
+			runes[pos] = toneMarks[runes[pos]][tone-1]
+		}
+		syllables[i] = string(runes)
+	}
+	return strings.Join(syllables, " ")
+}
+
+// Lookup 查询一个汉字，字典中没有时只返回拼音。
+func (d *HanziDict) Lookup(r rune) HanziInfo {
+	d.once.Do(d.load)
+	if info, ok := d.chars[r]; ok {
+		return *info
+	}
+	args := pinyin.NewArgs()
+	args.Style = pinyin.Tone
+	args.Heteronym = true
+	info := HanziInfo{Char: string(r)}
+	if pys := pinyin.Pinyin(string(r), args); len(pys) > 0 {
+		info.Pinyin = pys[0]
+	}
+	return info
+}
+
+// Available 是否加载到了字典数据。
+func (d *HanziDict) Available() bool {
+	d.once.Do(d.load)
+	return len(d.chars) > 0
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// HanziLookupTool 查字典：读音、笔画、部首、释义和组词，方便孩子写作业时查生字。
+type HanziLookupTool struct {
+	dict *HanziDict
+}
+
+// NewHanziLookupTool 创建查字典工具。
+func NewHanziLookupTool(dict *HanziDict) *HanziLookupTool {
+	return &HanziLookupTool{dict: dict}
+}
+
+func (t *HanziLookupTool) Name() string { return "lookup_hanzi" }
+
+func (t *HanziLookupTool) Description() string {
+	return "查字典：查询汉字的读音、笔画数、部首、释义和组词。" +
+		"当用户问'饕字怎么读'、'这个字几画'、'这个字是什么意思'、'用这个字组词'时使用；只问整句拼音时用 pinyin_query。"
+}
+
+func (t *HanziLookupTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"chars": {
+				"type": "string",
+				"description": "要查的字，一次最多 4 个，如 '饕' 或 '饕餮'"
+			}
+		},
+		"required": ["chars"]
+	}`)
+}
+
+func (t *HanziLookupTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Chars string `json:"chars"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	var results []HanziInfo
+	for _, r := range a.Chars {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+		results = append(results, t.dict.Lookup(r))
+		if len(results) == 4 {
+			break
+		}
+	}
+	if len(results) == 0 {
+		return toJSON(map[string]interface{}{"success": false, "message": "请告诉我要查哪个汉字"}), nil
+	}
+	result := map[string]interface{}{
+		"success": true,
+		"results": results,
+	}
+	if !t.dict.Available() {
+		result["message"] = "未安装字典数据，只能查到拼音"
+	}
+	return toJSON(result), nil
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNumberedToToneMarks(t *testing.T) {
+	tests := map[string]string{
+		"tao1":      "tāo",
+		"hao3":      "hǎo",
+		"lu:4":      "lǜ",
+		"gui4":      "guì",
+		"liu2":      "liú",
+		"dou1":      "dōu",
+		"zi5":       "zi",
+		"Zhong1":    "zhōng",
+		"tao1 tie4": "tāo tiè",
+	}
+	for in, want := range tests {
+		if got := numberedToToneMarks(in); got != want {
+			t.Errorf("numberedToToneMarks(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseCEDICTLine(t *testing.T) {
+	simplified, py, defs, ok := parseCEDICTLine("饕餮 饕餮 [tao1 tie4] /taotie, mythological animal/glutton/")
+	if !ok || simplified != "饕餮" || py != "tao1 tie4" {
+		t.Fatalf("parseCEDICTLine = %q %q %v", simplified, py, ok)
+	}
+	if want := []string{"taotie, mythological animal", "glutton"}; !reflect.DeepEqual(defs, want) {
+		t.Errorf("defs = %v, want %v", defs, want)
+	}
+	if _, _, _, ok := parseCEDICTLine("# CC-CEDICT"); ok {
+		t.Error("comment line should not parse")
+	}
+}
+
+func writeDictFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cedict := filepath.Join(dir, "cedict_ts.u8")
+	xinhua := filepath.Join(dir, "word.json")
+	os.WriteFile(cedict, []byte(`# CC-CEDICT
+饕 饕 [tao1] /gluttonous/
+饕餮 饕餮 [tao1 tie4] /taotie, mythological animal/glutton/
+老饕 老饕 [lao3 tao1] /glutton/
+饕餮大餐 饕餮大餐 [tao1 tie4 da4 can1] /feast/
+`), 0644)
+	os.WriteFile(xinhua, []byte(`[
+{"word": "饕", "oldword": "饕", "strokes": "22", "pinyin": "tāo", "radicals": "食", "explanation": "贪财，贪食：老～（贪吃的人）。"},
+{"word": "一", "oldword": "一", "strokes": "1", "pinyin": "yī", "radicals": "一", "explanation": "最小的正整数。"}
+]`), 0644)
+	return cedict, xinhua
+}
+
+func TestHanziDict_Lookup(t *testing.T) {
+	dict := NewHanziDict(writeDictFiles(t))
+
+	info := dict.Lookup('饕')
+	if info.Strokes != 22 || info.Radical != "食" {
+		t.Errorf("strokes/radical = %d/%q", info.Strokes, info.Radical)
+	}
+	if !reflect.DeepEqual(info.Pinyin, []string{"tāo"}) {
+		t.Errorf("pinyin = %v", info.Pinyin)
+	}
+	if !reflect.DeepEqual(info.Words, []string{"饕餮", "老饕"}) {
+		t.Errorf("words = %v", info.Words)
+	}
+	if len(info.Definitions) != 1 || len(info.English) != 1 {
+		t.Errorf("definitions = %v, english = %v", info.Definitions, info.English)
+	}
+
+	// 字典中没有的字退回到拼音库
+	if info := dict.Lookup('好'); info.Char != "好" || info.Strokes != 0 || len(info.Words) != 0 {
+		t.Errorf("fallback lookup = %+v", info)
+	}
+}
+
+func TestHanziDict_MissingData(t *testing.T) {
+	dict := NewHanziDict(filepath.Join(t.TempDir(), "none.u8"), "")
+	if info := dict.Lookup('饕'); info.Char != "饕" || info.Strokes != 0 || len(info.Definitions) != 0 {
+		t.Errorf("lookup without data = %+v", info)
+	}
+}
+
+func TestHanziLookupTool_Execute(t *testing.T) {
+	tool := NewHanziLookupTool(NewHanziDict(writeDictFiles(t)))
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"chars":"'饕'字怎么读"}`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var result struct {
+		Success bool        `json:"success"`
+		Results []HanziInfo `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !result.Success || len(result.Results) != 4 || result.Results[0].Char != "饕" {
+		t.Errorf("result = %s", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"chars":"abc"}`))
+	if err := json.Unmarshal([]byte(out), &result); err != nil || result.Success {
+		t.Errorf("non-hanzi input should fail: %s", out)
+	}
+}
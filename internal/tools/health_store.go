@@ -118,15 +118,15 @@ func (s *HealthStore) save() error {
 
 // IsQuietHours 检查当前是否在静音时段。
 func (s *HealthStore) IsQuietHours() bool {
-	now := time.Now()
-	currentTime := now.Format("15:04")
-
-	start := s.QuietHours.Start
-	end := s.QuietHours.End
+	return InQuietHours(time.Now(), s.QuietHours.Start, s.QuietHours.End)
+}
 
+// InQuietHours 判断 t 是否在 start-end（如 "23:00"-"07:00"）的静音时段内，任一为空时返回 false。
+func InQuietHours(t time.Time, start, end string) bool {
 	if start == "" || end == "" {
 		return false
 	}
+	currentTime := t.Format("15:04")
 
 	// 跨天情况：如 23:00 - 07:00
 	if start > end {
@@ -47,7 +47,7 @@ type DeviceState struct {
 }
 
 // doRequest 执行 HTTP 请求。
-func (c *HomeAssistantClient) doRequest(method, path string, body interface{}) ([]byte, error) {
+func (c *HomeAssistantClient) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
 	var reqBody io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -57,7 +57,7 @@ func (c *HomeAssistantClient) doRequest(method, path string, body interface{}) (
 		reqBody = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -84,8 +84,8 @@ func (c *HomeAssistantClient) doRequest(method, path string, body interface{}) (
 }
 
 // GetStates 获取所有设备状态。
-func (c *HomeAssistantClient) GetStates() ([]DeviceState, error) {
-	data, err := c.doRequest("GET", "/api/states", nil)
+func (c *HomeAssistantClient) GetStates(ctx context.Context) ([]DeviceState, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/states", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -99,8 +99,8 @@ func (c *HomeAssistantClient) GetStates() ([]DeviceState, error) {
 }
 
 // GetState 获取单个设备状态。
-func (c *HomeAssistantClient) GetState(entityID string) (*DeviceState, error) {
-	data, err := c.doRequest("GET", "/api/states/"+entityID, nil)
+func (c *HomeAssistantClient) GetState(ctx context.Context, entityID string) (*DeviceState, error) {
+	data, err := c.doRequest(ctx, "GET", "/api/states/"+entityID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -114,8 +114,8 @@ func (c *HomeAssistantClient) GetState(entityID string) (*DeviceState, error) {
 }
 
 // CallService 调用服务。
-func (c *HomeAssistantClient) CallService(domain, service string, data map[string]interface{}) error {
-	_, err := c.doRequest("POST", fmt.Sprintf("/api/services/%s/%s", domain, service), data)
+func (c *HomeAssistantClient) CallService(ctx context.Context, domain, service string, data map[string]interface{}) error {
+	_, err := c.doRequest(ctx, "POST", fmt.Sprintf("/api/services/%s/%s", domain, service), data)
 	return err
 }
 
@@ -161,7 +161,7 @@ func (t *HAListDevicesTool) Execute(ctx context.Context, args json.RawMessage) (
 		return "", fmt.Errorf("解析参数失败: %w", err)
 	}
 
-	states, err := t.client.GetStates()
+	states, err := t.client.GetStates(ctx)
 	if err != nil {
 		return "", fmt.Errorf("获取设备列表失败: %w", err)
 	}
@@ -274,7 +274,7 @@ func (t *HAGetDeviceStateTool) Execute(ctx context.Context, args json.RawMessage
 		return "", fmt.Errorf("解析参数失败: %w", err)
 	}
 
-	state, err := t.client.GetState(a.EntityID)
+	state, err := t.client.GetState(ctx, a.EntityID)
 	if err != nil {
 		return "", fmt.Errorf("获取设备状态失败: %w", err)
 	}
@@ -390,7 +390,7 @@ func (t *HAControlDeviceTool) Execute(ctx context.Context, args json.RawMessage)
 	}
 
 	// 获取设备名称
-	state, err := t.client.GetState(a.EntityID)
+	state, err := t.client.GetState(ctx, a.EntityID)
 	if err != nil {
 		return "", fmt.Errorf("设备不存在或无法访问: %w", err)
 	}
@@ -408,61 +408,79 @@ func (t *HAControlDeviceTool) Execute(ctx context.Context, args json.RawMessage)
 	domain := parts[0]
 
 	// 执行操作
-	var actionName string
 	switch a.Action {
 	case "turn_on":
-		if err := t.client.CallService(domain, "turn_on", map[string]interface{}{
+		if err := t.client.CallService(ctx, domain, "turn_on", map[string]interface{}{
 			"entity_id": a.EntityID,
 		}); err != nil {
 			return "", fmt.Errorf("操作失败: %w", err)
 		}
-		actionName = "已开启"
 
 	case "turn_off":
-		if err := t.client.CallService(domain, "turn_off", map[string]interface{}{
+		if err := t.client.CallService(ctx, domain, "turn_off", map[string]interface{}{
 			"entity_id": a.EntityID,
 		}); err != nil {
 			return "", fmt.Errorf("操作失败: %w", err)
 		}
-		actionName = "已关闭"
 
 	case "toggle":
-		if err := t.client.CallService(domain, "toggle", map[string]interface{}{
+		if err := t.client.CallService(ctx, domain, "toggle", map[string]interface{}{
 			"entity_id": a.EntityID,
 		}); err != nil {
 			return "", fmt.Errorf("操作失败: %w", err)
 		}
-		actionName = "已切换"
 
 	case "set_brightness":
 		if domain != "light" {
 			return "", fmt.Errorf("只有灯光设备支持调节亮度")
 		}
 		brightness := int(a.Value * 255 / 100)
-		if err := t.client.CallService(domain, "turn_on", map[string]interface{}{
+		if err := t.client.CallService(ctx, domain, "turn_on", map[string]interface{}{
 			"entity_id":  a.EntityID,
 			"brightness": brightness,
 		}); err != nil {
 			return "", fmt.Errorf("操作失败: %w", err)
 		}
-		actionName = fmt.Sprintf("亮度已设为 %.0f%%", a.Value)
 
 	case "set_temperature":
 		if domain != "climate" {
 			return "", fmt.Errorf("只有空调设备支持设置温度")
 		}
-		if err := t.client.CallService(domain, "set_temperature", map[string]interface{}{
+		if err := t.client.CallService(ctx, domain, "set_temperature", map[string]interface{}{
 			"entity_id":   a.EntityID,
 			"temperature": a.Value,
 		}); err != nil {
 			return "", fmt.Errorf("操作失败: %w", err)
 		}
-		actionName = fmt.Sprintf("温度已设为 %.0f摄氏度", a.Value)
 
 	default:
 		return "", fmt.Errorf("不支持的操作: %s", a.Action)
 	}
 
 	logger.Infof("[tools] 控制设备: %s -> %s", a.EntityID, a.Action)
-	return fmt.Sprintf("%s %s", name, actionName), nil
+	return fmt.Sprintf("%s %s", name, haActionName(a)), nil
+}
+
+// MockResult 模拟模式下不访问 Home Assistant，直接返回操作成功。
+func (t *HAControlDeviceTool) MockResult(args json.RawMessage) string {
+	var a haControlDeviceArgs
+	json.Unmarshal(args, &a)
+	return fmt.Sprintf("%s %s", a.EntityID, haActionName(a))
+}
+
+// haActionName 操作结果的说法，如"已开启"、"亮度已设为 50%"。
+func haActionName(a haControlDeviceArgs) string {
+	switch a.Action {
+	case "turn_on":
+		return "已开启"
+	case "turn_off":
+		return "已关闭"
+	case "toggle":
+		return "已切换"
+	case "set_brightness":
+		return fmt.Sprintf("亮度已设为 %.0f%%", a.Value)
+	case "set_temperature":
+		return fmt.Sprintf("温度已设为 %.0f摄氏度", a.Value)
+	}
+	return "操作成功"
 }
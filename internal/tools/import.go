@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 批量导入的文件格式。
+const (
+	ImportYAML = "yaml"
+	ImportCSV  = "csv"
+)
+
+// anniversaryRemindTime 纪念日没有写时间时当天几点提醒。
+const anniversaryRemindTime = "09:00"
+
+// ImportBatch 批量导入的闹钟、备忘和纪念日，用于新设备初始化时一次性录入，不用逐条口述。
+//
+// YAML 格式：
+//
+//	alarms:
+//	  - time: "2026-11-02 07:30"
+//	    message: 起床
+//	    gentle: true
+//	memos:
+//	  - content: 交水电费
+//	    due: "2026-11-05"
+//	anniversaries:
+//	  - date: "05-20"
+//	    name: 结婚纪念日
+//
+// CSV 格式每行一条：类型,时间,内容[,选项]，类型为 alarm/闹钟、memo/备忘、anniversary/纪念日，
+// 选项为 gentle、challenge（空格分隔），第一行是表头时跳过。
+type ImportBatch struct {
+	Alarms        []ImportAlarm       `yaml:"alarms"`
+	Memos         []ImportMemo        `yaml:"memos"`
+	Anniversaries []ImportAnniversary `yaml:"anniversaries"`
+}
+
+// ImportAlarm 导入的闹钟。
+type ImportAlarm struct {
+	Time      string `yaml:"time"` // YYYY-MM-DD HH:MM
+	Message   string `yaml:"message"`
+	Gentle    bool   `yaml:"gentle"`
+	Challenge bool   `yaml:"challenge"`
+}
+
+// ImportMemo 导入的备忘，有截止时间时到期自动提醒。
+type ImportMemo struct {
+	Content string `yaml:"content"`
+	Due     string `yaml:"due"` // YYYY-MM-DD HH:MM 或 YYYY-MM-DD，可为空
+}
+
+// ImportAnniversary 导入的纪念日，每年当天提醒。
+type ImportAnniversary struct {
+	Date string `yaml:"date"` // MM-DD 或 YYYY-MM-DD
+	Name string `yaml:"name"`
+	Time string `yaml:"time"` // 当天几点提醒 HH:MM，默认 09:00
+}
+
+// ParseImport 按 format（yaml 或 csv）解析批量导入文件。
+func ParseImport(r io.Reader, format string) (*ImportBatch, error) {
+	switch format {
+	case ImportYAML:
+		var b ImportBatch
+		if err := yaml.NewDecoder(r).Decode(&b); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("解析 YAML 失败: %w", err)
+		}
+		return &b, nil
+	case ImportCSV:
+		return parseImportCSV(r)
+	default:
+		return nil, fmt.Errorf("不支持的导入格式 %s，可选: yaml、csv", format)
+	}
+}
+
+func parseImportCSV(r io.Reader) (*ImportBatch, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("解析 CSV 失败: %w", err)
+	}
+
+	var b ImportBatch
+	for i, rec := range records {
+		if len(rec) == 0 || strings.HasPrefix(rec[0], "#") {
+			continue
+		}
+		kind := strings.ToLower(strings.TrimSpace(rec[0]))
+		if i == 0 && (kind == "type" || kind == "类型") {
+			continue
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("CSV 第 %d 行至少需要 类型,时间,内容 三列", i+1)
+		}
+		when, content := strings.TrimSpace(rec[1]), strings.TrimSpace(rec[2])
+		var options string
+		if len(rec) > 3 {
+			options = rec[3]
+		}
+		switch kind {
+		case "alarm", "闹钟":
+			a := ImportAlarm{Time: when, Message: content}
+			for _, opt := range strings.Fields(options) {
+				switch opt {
+				case "gentle":
+					a.Gentle = true
+				case "challenge":
+					a.Challenge = true
+				}
+			}
+			b.Alarms = append(b.Alarms, a)
+		case "memo", "备忘":
+			b.Memos = append(b.Memos, ImportMemo{Content: content, Due: when})
+		case "anniversary", "纪念日":
+			date, at, _ := strings.Cut(when, " ")
+			b.Anniversaries = append(b.Anniversaries, ImportAnniversary{Date: date, Name: content, Time: at})
+		default:
+			return nil, fmt.Errorf("CSV 第 %d 行类型 %q 无效，可选: alarm、memo、anniversary", i+1, rec[0])
+		}
+	}
+	return &b, nil
+}
+
+// ImportResult 批量导入的结果。
+type ImportResult struct {
+	Alarms        int      `json:"alarms"`
+	Memos         int      `json:"memos"`
+	Anniversaries int      `json:"anniversaries"`
+	Skipped       []string `json:"skipped,omitempty"` // 跳过的条目及原因
+}
+
+// String 导入结果摘要。
+func (r ImportResult) String() string {
+	s := fmt.Sprintf("导入闹钟 %d 个、备忘 %d 条、纪念日 %d 个", r.Alarms, r.Memos, r.Anniversaries)
+	if len(r.Skipped) > 0 {
+		s += fmt.Sprintf("，跳过 %d 条", len(r.Skipped))
+	}
+	return s
+}
+
+// ImportReminders 把批量导入的条目写入闹钟和备忘存储。格式错误、时间已过和已存在的条目会跳过，
+// 重复导入同一个文件不会产生重复提醒。memos 设置了闹钟存储时，有截止时间的备忘同样会创建到期提醒。
+func ImportReminders(b *ImportBatch, alarms *AlarmStore, memos *MemoStore, now time.Time) ImportResult {
+	var res ImportResult
+	seq := 0
+	nextID := func(prefix string) string {
+		seq++
+		return fmt.Sprintf("%s_%d_%d", prefix, now.UnixMilli(), seq)
+	}
+	skip := func(item, reason string) {
+		res.Skipped = append(res.Skipped, item+": "+reason)
+	}
+
+	existing := make(map[string]bool)
+	for _, a := range alarms.List() {
+		existing[a.Time+"|"+a.Message] = true
+	}
+	for _, a := range b.Alarms {
+		item := fmt.Sprintf("闹钟 %s %s", a.Time, a.Message)
+		at, err := time.ParseInLocation("2006-01-02 15:04", a.Time, time.Local)
+		switch {
+		case a.Message == "":
+			skip(item, "缺少提醒内容")
+			continue
+		case err != nil:
+			skip(item, "时间格式应为 YYYY-MM-DD HH:MM")
+			continue
+		case !at.After(now):
+			skip(item, "时间已过")
+			continue
+		case existing[a.Time+"|"+a.Message]:
+			skip(item, "已存在")
+			continue
+		}
+		entry := AlarmEntry{
+			ID:        nextID("alarm"),
+			Time:      a.Time,
+			Message:   a.Message,
+			Created:   now.Format("2006-01-02 15:04:05"),
+			Gentle:    a.Gentle,
+			Challenge: a.Challenge,
+		}
+		if err := alarms.Add(entry); err != nil {
+			skip(item, err.Error())
+			continue
+		}
+		existing[a.Time+"|"+a.Message] = true
+		res.Alarms++
+	}
+
+	for _, a := range b.Anniversaries {
+		item := fmt.Sprintf("纪念日 %s %s", a.Date, a.Name)
+		if a.Name == "" {
+			skip(item, "缺少名称")
+			continue
+		}
+		at, err := nextAnniversary(a.Date, a.Time, now)
+		if err != nil {
+			skip(item, err.Error())
+			continue
+		}
+		message := "纪念日：" + a.Name
+		key := at.Format("2006-01-02 15:04") + "|" + message
+		if existing[key] {
+			skip(item, "已存在")
+			continue
+		}
+		entry := AlarmEntry{
+			ID:      nextID("anniversary"),
+			Time:    at.Format("2006-01-02 15:04"),
+			Message: message,
+			Created: now.Format("2006-01-02 15:04:05"),
+			Yearly:  true,
+		}
+		if err := alarms.Add(entry); err != nil {
+			skip(item, err.Error())
+			continue
+		}
+		existing[key] = true
+		res.Anniversaries++
+	}
+
+	existingMemos := make(map[string]bool)
+	for _, m := range memos.List() {
+		existingMemos[m.Content+"|"+m.Due] = true
+	}
+	for _, m := range b.Memos {
+		item := "备忘 " + m.Content
+		if m.Content == "" {
+			skip(item, "缺少内容")
+			continue
+		}
+		if m.Due != "" {
+			if _, _, err := parseMemoDue(m.Due); err != nil {
+				skip(item, err.Error())
+				continue
+			}
+		}
+		if existingMemos[m.Content+"|"+m.Due] {
+			skip(item, "已存在")
+			continue
+		}
+		entry := MemoEntry{
+			ID:      nextID("memo"),
+			Content: m.Content,
+			Created: now.Format("2006-01-02 15:04:05"),
+			Due:     m.Due,
+		}
+		alarmID, _, err := memos.scheduleReminder(entry, now)
+		if err != nil {
+			skip(item, err.Error())
+			continue
+		}
+		entry.AlarmID = alarmID
+		if err := memos.Add(entry); err != nil {
+			skip(item, err.Error())
+			continue
+		}
+		existingMemos[m.Content+"|"+m.Due] = true
+		res.Memos++
+	}
+	return res
+}
+
+// nextAnniversary 纪念日下一次提醒的时间：今年的这一天还没过就是今年，否则是明年。
+func nextAnniversary(date, at string, now time.Time) (time.Time, error) {
+	// 带年份时只取月日
+	if len(date) == len("2006-01-02") {
+		date = date[len("2006-"):]
+	}
+	if at == "" {
+		at = anniversaryRemindTime
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", fmt.Sprintf("%d-%s %s", now.Year(), date, at), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式应为 MM-DD 或 YYYY-MM-DD，时间格式应为 HH:MM")
+	}
+	if !t.After(now) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, nil
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	data := `类型,时间,内容,选项
+闹钟,2026-11-02 07:30,起床,gentle
+# 注释行
+memo,2026-11-05,交水电费
+anniversary,05-20 08:00,结婚纪念日
+`
+	b, err := ParseImport(strings.NewReader(data), ImportCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Alarms) != 1 || !b.Alarms[0].Gentle || b.Alarms[0].Message != "起床" {
+		t.Errorf("alarms = %+v", b.Alarms)
+	}
+	if len(b.Memos) != 1 || b.Memos[0].Due != "2026-11-05" {
+		t.Errorf("memos = %+v", b.Memos)
+	}
+	if len(b.Anniversaries) != 1 || b.Anniversaries[0].Date != "05-20" || b.Anniversaries[0].Time != "08:00" {
+		t.Errorf("anniversaries = %+v", b.Anniversaries)
+	}
+
+	if _, err := ParseImport(strings.NewReader("生日,05-20,小明"), ImportCSV); err == nil {
+		t.Error("unknown type should fail")
+	}
+}
+
+func TestImportReminders(t *testing.T) {
+	dir := t.TempDir()
+	alarms, _ := NewAlarmStore(dir)
+	memos, _ := NewMemoStore(dir)
+	memos.SetAlarmStore(alarms)
+
+	data := `
+alarms:
+  - time: "2026-11-02 07:30"
+    message: 起床
+  - time: "2026-01-01 07:30"
+    message: 已经过了
+  - time: "明天早上"
+    message: 格式不对
+memos:
+  - content: 交水电费
+    due: "2026-11-05"
+  - content: 买牛奶
+anniversaries:
+  - date: "2015-05-20"
+    name: 结婚纪念日
+`
+	b, err := ParseImport(strings.NewReader(data), ImportYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	res := ImportReminders(b, alarms, memos, now)
+	if res.Alarms != 1 || res.Memos != 2 || res.Anniversaries != 1 || len(res.Skipped) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	// 起床、纪念日、交水电费的到期提醒
+	list := alarms.List()
+	if len(list) != 3 {
+		t.Fatalf("expected 3 alarms, got %+v", list)
+	}
+	var anniversary AlarmEntry
+	for _, a := range list {
+		if a.Yearly {
+			anniversary = a
+		}
+	}
+	if anniversary.Time != "2027-05-20 09:00" || anniversary.Message != "纪念日：结婚纪念日" {
+		t.Errorf("anniversary = %+v", anniversary)
+	}
+
+	// 重复导入不产生重复条目
+	res = ImportReminders(b, alarms, memos, now)
+	if res.Alarms+res.Memos+res.Anniversaries != 0 {
+		t.Errorf("re-import should skip existing entries, got %+v", res)
+	}
+	if len(alarms.List()) != 3 || len(memos.List()) != 2 {
+		t.Errorf("re-import duplicated entries: %d alarms, %d memos", len(alarms.List()), len(memos.List()))
+	}
+}
+
+func TestAlarmStore_PopDueAlarmsYearly(t *testing.T) {
+	store, _ := NewAlarmStore(t.TempDir())
+	store.Add(AlarmEntry{ID: "a1", Time: "2020-05-20 09:00", Message: "纪念日：结婚纪念日", Yearly: true})
+
+	due := store.PopDueAlarms()
+	if len(due) != 1 {
+		t.Fatalf("expected 1 due alarm, got %d", len(due))
+	}
+	remaining := store.List()
+	if len(remaining) != 1 {
+		t.Fatalf("yearly alarm should be kept, got %+v", remaining)
+	}
+	next, err := time.ParseInLocation("2006-01-02 15:04", remaining[0].Time, time.Local)
+	if err != nil || !next.After(time.Now()) || next.After(time.Now().AddDate(1, 0, 0)) || next.Format("01-02 15:04") != "05-20 09:00" {
+		t.Errorf("next occurrence = %s", remaining[0].Time)
+	}
+}
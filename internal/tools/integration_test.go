@@ -73,7 +73,7 @@ func TestIntegration_Stock(t *testing.T) {
 }
 
 func TestIntegration_News(t *testing.T) {
-	tool := NewNewsTool()
+	tool := NewNewsTool(NewNewsSession())
 	result, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
 	if err != nil {
 		t.Fatalf("news query failed: %v", err)
@@ -1,9 +1,11 @@
 package tools
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/6tail/lunar-go/calendar"
@@ -19,7 +21,8 @@ func NewLunarDateTool() *LunarDateTool {
 func (t *LunarDateTool) Name() string { return "get_lunar_date" }
 
 func (t *LunarDateTool) Description() string {
-	return "查询指定日期的农历日期和传统历法信息。当用户询问农历日期、干支纪年、生肖、节气、传统节日、黄历宜忌等问题时使用。支持查询今天、明天、任意日期。"
+	return "查询指定日期的农历日期和传统历法信息。当用户询问农历日期、干支纪年、生肖、节气、传统节日、黄历宜忌等问题时使用。支持查询今天、明天、任意日期。" +
+		"用户问'这个月哪天适合搬家'、'下个月哪天宜嫁娶'时传 activity 和日期范围，按黄历宜忌查找合适的日子。"
 }
 
 func (t *LunarDateTool) Parameters() json.RawMessage {
@@ -33,6 +36,14 @@ func (t *LunarDateTool) Parameters() json.RawMessage {
 			"include_huangli": {
 				"type": "boolean",
 				"description": "是否包含黄历信息（宜忌、冲煞等），默认false"
+			},
+			"activity": {
+				"type": "string",
+				"description": "查找适合做某事的日子时传入要做的事，如 搬家、结婚、开业、出行、装修。此时 date 为范围开始日期"
+			},
+			"end_date": {
+				"type": "string",
+				"description": "查找日子的范围结束日期，格式 YYYY-MM-DD，默认从开始日期起 30 天，最多 92 天"
 			}
 		},
 		"required": []
@@ -67,6 +78,8 @@ func (t *LunarDateTool) Execute(ctx context.Context, args json.RawMessage) (stri
 	var params struct {
 		Date           string `json:"date"`
 		IncludeHuangli bool   `json:"include_huangli"`
+		Activity       string `json:"activity"`
+		EndDate        string `json:"end_date"`
 	}
 	if len(args) > 0 {
 		if err := json.Unmarshal(args, &params); err != nil {
@@ -86,6 +99,10 @@ func (t *LunarDateTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		targetDate = time.Now()
 	}
 
+	if activity := strings.TrimSpace(params.Activity); activity != "" {
+		return t.searchHuangli(activity, targetDate, params.EndDate)
+	}
+
 	solar := calendar.NewSolarFromDate(targetDate)
 	lunar := solar.GetLunar()
 
@@ -141,25 +158,8 @@ func (t *LunarDateTool) Execute(ctx context.Context, args json.RawMessage) (stri
 	// 可选：获取黄历信息
 	if params.IncludeHuangli {
 		// 获取宜忌
-		yiList := lunar.GetDayYi()
-		var yi []string
-		if yiList != nil {
-			for e := yiList.Front(); e != nil; e = e.Next() {
-				if str, ok := e.Value.(string); ok {
-					yi = append(yi, str)
-				}
-			}
-		}
-
-		jiList := lunar.GetDayJi()
-		var ji []string
-		if jiList != nil {
-			for e := jiList.Front(); e != nil; e = e.Next() {
-				if str, ok := e.Value.(string); ok {
-					ji = append(ji, str)
-				}
-			}
-		}
+		yi := stringList(lunar.GetDayYi())
+		ji := stringList(lunar.GetDayJi())
 
 		huangli := &HuangliInfo{
 			Yi:    yi,
@@ -178,3 +178,169 @@ func (t *LunarDateTool) Execute(ctx context.Context, args json.RawMessage) (stri
 
 	return string(data), nil
 }
+
+
+// stringList 把 lunar-go 返回的字符串列表转为切片。
+func stringList(l *list.List) []string {
+	if l == nil {
+		return nil
+	}
+	var out []string
+	for e := l.Front(); e != nil; e = e.Next() {
+		if str, ok := e.Value.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+const (
+	huangliSearchDays    = 30 // 未指定结束日期时查找的天数
+	huangliSearchMaxDays = 92 // 最多查找的天数
+	huangliSearchTop     = 5  // 最多返回几个日子
+)
+
+// huangliActivityTerms 口语中的事情对应的黄历宜忌用语，没有列出的直接按原词匹配。
+var huangliActivityTerms = map[string][]string{
+	"搬家":  {"入宅", "移徙"},
+	"乔迁":  {"入宅", "移徙"},
+	"入住":  {"入宅"},
+	"结婚":  {"嫁娶"},
+	"婚礼":  {"嫁娶"},
+	"领证":  {"嫁娶"},
+	"订婚":  {"订盟", "纳采"},
+	"开业":  {"开市"},
+	"开张":  {"开市"},
+	"开店":  {"开市"},
+	"签约":  {"立券", "交易"},
+	"签合同": {"立券", "交易"},
+	"买房":  {"交易", "纳财"},
+	"装修":  {"修造", "动土"},
+	"动工":  {"动土", "修造"},
+	"出行":  {"出行"},
+	"出门":  {"出行"},
+	"旅游":  {"出行"},
+	"远行":  {"出行"},
+	"剪头发": {"理发"},
+	"看病":  {"求医", "治病"},
+	"手术":  {"求医", "治病"},
+	"扫墓":  {"祭祀"},
+	"祭祖":  {"祭祀"},
+	"上坟":  {"祭祀"},
+}
+
+// activityTerms 返回事情对应的黄历用语。
+func activityTerms(activity string) []string {
+	if terms, ok := huangliActivityTerms[activity]; ok {
+		return terms
+	}
+	for word, terms := range huangliActivityTerms {
+		if strings.Contains(activity, word) {
+			return terms
+		}
+	}
+	return []string{activity}
+}
+
+// HuangliDay 适合做某事的一天。
+type HuangliDay struct {
+	SolarDate string   `json:"solar_date"`
+	Weekday   string   `json:"weekday"`
+	LunarDate string   `json:"lunar_date"`
+	Matched   []string `json:"matched"` // 宜中匹配到的用语
+	Chong     string   `json:"chong,omitempty"`
+}
+
+// HuangliSearchResult 黄历择日结果。
+type HuangliSearchResult struct {
+	Activity  string       `json:"activity"`
+	Terms     []string     `json:"terms"`
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Total     int          `json:"total"` // 范围内符合的天数
+	Days      []HuangliDay `json:"days"`  // 最早的几天
+	Message   string       `json:"message"`
+}
+
+// searchHuangli 在日期范围内查找宜做某事（且不忌）的日子，返回最早的几天。
+func (t *LunarDateTool) searchHuangli(activity string, start time.Time, endDate string) (string, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, huangliSearchDays-1)
+	if endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return "", fmt.Errorf("日期格式错误，请使用 YYYY-MM-DD 格式: %w", err)
+		}
+		end = parsed
+	}
+	if end.Before(start) {
+		return "", fmt.Errorf("结束日期 %s 早于开始日期 %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+	if last := start.AddDate(0, 0, huangliSearchMaxDays-1); end.After(last) {
+		end = last
+	}
+
+	terms := activityTerms(activity)
+	result := HuangliSearchResult{
+		Activity:  activity,
+		Terms:     terms,
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+	}
+	weekdays := []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		lunar := calendar.NewSolarFromDate(d).GetLunar()
+		matched := matchTerms(stringList(lunar.GetDayYi()), terms)
+		if len(matched) == 0 || len(matchTerms(stringList(lunar.GetDayJi()), terms)) > 0 {
+			continue
+		}
+		result.Total++
+		if len(result.Days) < huangliSearchTop {
+			result.Days = append(result.Days, HuangliDay{
+				SolarDate: d.Format("2006-01-02"),
+				Weekday:   weekdays[d.Weekday()],
+				LunarDate: "农历" + lunar.GetMonthInChinese() + "月" + lunar.GetDayInChinese(),
+				Matched:   matched,
+				Chong:     lunar.GetDayChongDesc(),
+			})
+		}
+	}
+	result.Message = huangliSearchMessage(result)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("序列化结果失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// matchTerms 返回 list 中出现的 terms。
+func matchTerms(list, terms []string) []string {
+	var matched []string
+	for _, term := range terms {
+		for _, item := range list {
+			if item == term {
+				matched = append(matched, term)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// huangliSearchMessage 一句话播报择日结果。
+func huangliSearchMessage(r HuangliSearchResult) string {
+	if r.Total == 0 {
+		return fmt.Sprintf("%s到%s之间黄历上没有宜%s的日子", r.StartDate, r.EndDate, strings.Join(r.Terms, "、"))
+	}
+	var days []string
+	for _, d := range r.Days {
+		date, _ := time.Parse("2006-01-02", d.SolarDate)
+		days = append(days, fmt.Sprintf("%d月%d日%s（%s）", int(date.Month()), date.Day(), d.Weekday, d.LunarDate))
+	}
+	msg := fmt.Sprintf("适合%s的日子有：%s", r.Activity, strings.Join(days, "、"))
+	if r.Total > len(r.Days) {
+		msg += fmt.Sprintf("，范围内一共 %d 天", r.Total)
+	}
+	return msg
+}
@@ -340,3 +340,81 @@ func TestLunarDateTool_SolarDateFormat(t *testing.T) {
 		t.Errorf("SolarDate = %s, want %s", lunarResult.SolarDate, expectedFormat)
 	}
 }
+
+func TestActivityTerms(t *testing.T) {
+	tests := []struct {
+		activity string
+		want     string
+	}{
+		{"搬家", "入宅"},
+		{"下个月搬家", "入宅"},
+		{"结婚", "嫁娶"},
+		{"祈福", "祈福"},
+	}
+	for _, tt := range tests {
+		if terms := activityTerms(tt.activity); len(terms) == 0 || terms[0] != tt.want {
+			t.Errorf("activityTerms(%q) = %v, want first %q", tt.activity, terms, tt.want)
+		}
+	}
+}
+
+func TestLunarDateTool_SearchHuangli_Range(t *testing.T) {
+	tool := NewLunarDateTool()
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"activity":"搬家","date":"2025-06-10","end_date":"2025-06-01"}`)); err == nil {
+		t.Error("结束日期早于开始日期应返回错误")
+	}
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"activity":"搬家","date":"2025-01-01","end_date":"2026-01-01"}`))
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	var r HuangliSearchResult
+	if err := json.Unmarshal([]byte(result), &r); err != nil {
+		t.Fatalf("结果不是有效的 JSON: %v", err)
+	}
+	if r.StartDate != "2025-01-01" || r.EndDate != "2025-04-02" {
+		t.Errorf("范围 = %s ~ %s, want 2025-01-01 ~ 2025-04-02", r.StartDate, r.EndDate)
+	}
+	if len(r.Days) > huangliSearchTop || r.Total < len(r.Days) {
+		t.Errorf("返回 %d 天，共 %d 天", len(r.Days), r.Total)
+	}
+}
+
+func TestLunarDateTool_SearchHuangli_Matches(t *testing.T) {
+	tool := NewLunarDateTool()
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"activity":"搬家","date":"2025-03-01","end_date":"2025-03-31"}`))
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	var r HuangliSearchResult
+	if err := json.Unmarshal([]byte(result), &r); err != nil {
+		t.Fatalf("结果不是有效的 JSON: %v", err)
+	}
+	if len(r.Days) == 0 {
+		t.Fatalf("一个月内应有宜入宅或移徙的日子: %s", result)
+	}
+	for _, d := range r.Days {
+		if len(d.Matched) == 0 || d.LunarDate == "" {
+			t.Errorf("日子信息不完整: %+v", d)
+		}
+	}
+	t.Logf("择日结果: %s", r.Message)
+}
+
+func TestHuangliSearchMessage(t *testing.T) {
+	msg := huangliSearchMessage(HuangliSearchResult{Activity: "搬家", Terms: []string{"入宅", "移徙"}, StartDate: "2025-03-01", EndDate: "2025-03-31"})
+	if msg != "2025-03-01到2025-03-31之间黄历上没有宜入宅、移徙的日子" {
+		t.Errorf("无结果 = %s", msg)
+	}
+
+	msg = huangliSearchMessage(HuangliSearchResult{
+		Activity: "搬家",
+		Total:    7,
+		Days:     []HuangliDay{{SolarDate: "2025-03-02", Weekday: "星期日", LunarDate: "农历二月初三"}},
+	})
+	if msg != "适合搬家的日子有：3月2日星期日（农历二月初三），范围内一共 7 天" {
+		t.Errorf("有结果 = %s", msg)
+	}
+}
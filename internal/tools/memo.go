@@ -7,6 +7,7 @@ import (
 	"github.com/iabetor/pibuddy/internal/logger"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -16,6 +17,38 @@ type MemoEntry struct {
 	ID      string `json:"id"`
 	Content string `json:"content"`
 	Created string `json:"created"`
+	Due     string `json:"due,omitempty"`      // 截止时间，格式 YYYY-MM-DD HH:MM 或 YYYY-MM-DD，没有时为空
+	AlarmID string `json:"alarm_id,omitempty"` // 到期提醒关联的闹钟，删除备忘时一起删除
+}
+
+// memoDefaultRemindHour 只给了日期的备忘（"周五交水电费"）当天几点提醒。
+const memoDefaultRemindHour = 9
+
+// parseMemoDue 解析截止时间，只有日期时 hasTime 为 false。
+func parseMemoDue(due string) (t time.Time, hasTime bool, err error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", due, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", due, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("截止时间格式错误，应为 YYYY-MM-DD HH:MM 或 YYYY-MM-DD")
+	}
+	return t, false, nil
+}
+
+// dueTime 截止时间，只有日期时按当天结束计算（用于排序和判断是否过期）。
+func (m MemoEntry) dueTime() (time.Time, bool) {
+	if m.Due == "" {
+		return time.Time{}, false
+	}
+	t, hasTime, err := parseMemoDue(m.Due)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if !hasTime {
+		t = t.Add(24*time.Hour - time.Minute)
+	}
+	return t, true
 }
 
 // MemoStore 备忘录持久化存储。
@@ -23,6 +56,7 @@ type MemoStore struct {
 	mu       sync.RWMutex
 	filePath string
 	memos    []MemoEntry
+	alarms   *AlarmStore // 到期提醒，可为 nil（不自动提醒）
 }
 
 // NewMemoStore 创建备忘录存储。
@@ -60,6 +94,13 @@ func (s *MemoStore) save() error {
 	return os.WriteFile(s.filePath, data, 0644)
 }
 
+// SetAlarmStore 设置闹钟存储，设置后有截止时间的备忘会自动创建到期提醒。
+func (s *MemoStore) SetAlarmStore(alarms *AlarmStore) {
+	s.mu.Lock()
+	s.alarms = alarms
+	s.mu.Unlock()
+}
+
 func (s *MemoStore) Add(entry MemoEntry) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -75,6 +116,20 @@ func (s *MemoStore) List() []MemoEntry {
 	return result
 }
 
+// ListByDue 按截止时间排序列出备忘：有截止时间的在前（最早到期的最先），其余按创建顺序。
+func (s *MemoStore) ListByDue() []MemoEntry {
+	memos := s.List()
+	sort.SliceStable(memos, func(i, j int) bool {
+		ti, oki := memos[i].dueTime()
+		tj, okj := memos[j].dueTime()
+		if oki != okj {
+			return oki
+		}
+		return oki && ti.Before(tj)
+	})
+	return memos
+}
+
 func (s *MemoStore) Delete(id string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -82,12 +137,46 @@ func (s *MemoStore) Delete(id string) bool {
 		if m.ID == id {
 			s.memos = append(s.memos[:i], s.memos[i+1:]...)
 			_ = s.save()
+			if m.AlarmID != "" && s.alarms != nil {
+				s.alarms.Delete(m.AlarmID)
+			}
 			return true
 		}
 	}
 	return false
 }
 
+// scheduleReminder 为有截止时间的备忘创建到期提醒，返回闹钟 ID 和提醒时间。
+// 没有设置闹钟存储或提醒时间已过时返回空。
+func (s *MemoStore) scheduleReminder(entry MemoEntry, now time.Time) (string, time.Time, error) {
+	s.mu.RLock()
+	alarms := s.alarms
+	s.mu.RUnlock()
+	if alarms == nil || entry.Due == "" {
+		return "", time.Time{}, nil
+	}
+	at, hasTime, err := parseMemoDue(entry.Due)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !hasTime {
+		at = at.Add(memoDefaultRemindHour * time.Hour)
+	}
+	if !at.After(now) {
+		return "", time.Time{}, nil
+	}
+	alarm := AlarmEntry{
+		ID:      "alarm_" + entry.ID,
+		Time:    at.Format("2006-01-02 15:04"),
+		Message: "备忘到期：" + entry.Content,
+		Created: now.Format("2006-01-02 15:04:05"),
+	}
+	if err := alarms.Add(alarm); err != nil {
+		return "", time.Time{}, fmt.Errorf("创建到期提醒失败: %w", err)
+	}
+	return alarm.ID, at, nil
+}
+
 // ---- AddMemoTool ----
 
 type AddMemoTool struct {
@@ -100,7 +189,8 @@ func NewAddMemoTool(store *MemoStore) *AddMemoTool {
 
 func (t *AddMemoTool) Name() string { return "add_memo" }
 func (t *AddMemoTool) Description() string {
-	return "添加备忘录。当用户说'记一下'、'帮我备忘'等时使用。"
+	return "添加备忘录。当用户说'记一下'、'帮我备忘'等时使用。" +
+		"内容里有截止日期时（如'记一下周五交水电费'）填写 due，到期会自动提醒。"
 }
 func (t *AddMemoTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -108,7 +198,11 @@ func (t *AddMemoTool) Parameters() json.RawMessage {
 		"properties": {
 			"content": {
 				"type": "string",
-				"description": "备忘内容"
+				"description": "备忘内容（不含日期），如'交水电费'"
+			},
+			"due": {
+				"type": "string",
+				"description": "截止时间，格式 YYYY-MM-DD HH:MM；只说了哪天时用 YYYY-MM-DD（当天早上提醒）；没有截止时间时留空"
 			}
 		},
 		"required": ["content"]
@@ -117,6 +211,7 @@ func (t *AddMemoTool) Parameters() json.RawMessage {
 
 type addMemoArgs struct {
 	Content string `json:"content"`
+	Due     string `json:"due,omitempty"`
 }
 
 func (t *AddMemoTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
@@ -128,17 +223,37 @@ func (t *AddMemoTool) Execute(ctx context.Context, args json.RawMessage) (string
 		return "", fmt.Errorf("备忘内容不能为空")
 	}
 
-	id := fmt.Sprintf("memo_%d", time.Now().UnixMilli())
+	if a.Due != "" {
+		if _, _, err := parseMemoDue(a.Due); err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
+	id := fmt.Sprintf("memo_%d", now.UnixMilli())
 	entry := MemoEntry{
 		ID:      id,
 		Content: a.Content,
-		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Created: now.Format("2006-01-02 15:04:05"),
+		Due:     a.Due,
 	}
 
+	alarmID, remindAt, err := t.store.scheduleReminder(entry, now)
+	if err != nil {
+		logger.Warnf("[tools] %v", err)
+	}
+	entry.AlarmID = alarmID
+
 	if err := t.store.Add(entry); err != nil {
 		return "", fmt.Errorf("保存备忘录失败: %w", err)
 	}
 
+	if alarmID != "" {
+		return fmt.Sprintf("已记录备忘: %s，截止 %s，会在 %s 提醒", a.Content, a.Due, remindAt.Format("01月02日 15:04")), nil
+	}
+	if a.Due != "" {
+		return fmt.Sprintf("已记录备忘: %s，截止 %s", a.Content, a.Due), nil
+	}
 	return fmt.Sprintf("已记录备忘: %s", a.Content), nil
 }
 
@@ -154,22 +269,44 @@ func NewListMemosTool(store *MemoStore) *ListMemosTool {
 
 func (t *ListMemosTool) Name() string { return "list_memos" }
 func (t *ListMemosTool) Description() string {
-	return "查看所有备忘录。当用户说'看看备忘'、'有哪些备忘'等时使用。"
+	return "查看所有备忘录。当用户说'看看备忘'、'有哪些备忘'等时使用。" +
+		"结果按截止时间排序，朗读时先提醒已过期和今天到期的事项。"
 }
 func (t *ListMemosTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{"type":"object","properties":{},"required":[]}`)
 }
 
 func (t *ListMemosTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	memos := t.store.List()
+	memos := t.store.ListByDue()
 	if len(memos) == 0 {
 		return "当前没有任何备忘录。", nil
 	}
+	return formatMemoList(memos, time.Now()), nil
+}
+
+// formatMemoList 列出备忘，标注已过期和今天到期的事项。
+func formatMemoList(memos []MemoEntry, now time.Time) string {
+	var overdue int
+	today := now.Format("2006-01-02")
 	result := fmt.Sprintf("当前有 %d 条备忘:\n", len(memos))
 	for i, m := range memos {
-		result += fmt.Sprintf("%d. [%s] %s (创建于 %s)\n", i+1, m.ID, m.Content, m.Created)
+		var mark, due string
+		if t, ok := m.dueTime(); ok {
+			due = fmt.Sprintf("，截止 %s", m.Due)
+			switch {
+			case t.Before(now):
+				mark = "【已过期】"
+				overdue++
+			case t.Format("2006-01-02") == today:
+				mark = "【今天到期】"
+			}
+		}
+		result += fmt.Sprintf("%d. [%s] %s%s%s (创建于 %s)\n", i+1, m.ID, mark, m.Content, due, m.Created)
 	}
-	return result, nil
+	if overdue > 0 {
+		result += fmt.Sprintf("其中 %d 条已过期，请先提醒用户。\n", overdue)
+	}
+	return result
 }
 
 // ---- DeleteMemoTool ----
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMemoStore_CRUD(t *testing.T) {
@@ -160,3 +161,67 @@ func TestDeleteMemoTool_Execute(t *testing.T) {
 		t.Errorf("should say not found, got %q", result)
 	}
 }
+
+func TestAddMemoTool_DueDateReminder(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewMemoStore(tmpDir)
+	alarms, _ := NewAlarmStore(tmpDir)
+	store.SetAlarmStore(alarms)
+	tool := NewAddMemoTool(store)
+
+	due := time.Now().AddDate(0, 0, 3).Format("2006-01-02")
+	args, _ := json.Marshal(addMemoArgs{Content: "交水电费", Due: due})
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "提醒") {
+		t.Errorf("result should mention reminder, got %q", result)
+	}
+
+	// 只给日期时在当天早上提醒，备忘和闹钟互相关联
+	list := alarms.List()
+	if len(list) != 1 || list[0].Time != due+" 09:00" || !strings.Contains(list[0].Message, "交水电费") {
+		t.Fatalf("alarms = %+v", list)
+	}
+	memos := store.List()
+	if len(memos) != 1 || memos[0].AlarmID != list[0].ID {
+		t.Fatalf("memos = %+v", memos)
+	}
+
+	// 删除备忘时一起删除提醒
+	store.Delete(memos[0].ID)
+	if len(alarms.List()) != 0 {
+		t.Error("linked alarm should be deleted with memo")
+	}
+
+	// 格式错误
+	args, _ = json.Marshal(addMemoArgs{Content: "交水电费", Due: "周五"})
+	if _, err := tool.Execute(context.Background(), args); err == nil {
+		t.Error("expected error for invalid due")
+	}
+}
+
+func TestListMemosTool_SortByDue(t *testing.T) {
+	store, _ := NewMemoStore(t.TempDir())
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.Local)
+	store.Add(MemoEntry{ID: "m1", Content: "没有截止", Created: "2026-03-01"})
+	store.Add(MemoEntry{ID: "m2", Content: "下周", Due: "2026-03-10", Created: "2026-03-01"})
+	store.Add(MemoEntry{ID: "m3", Content: "今天", Due: "2026-03-05", Created: "2026-03-01"})
+	store.Add(MemoEntry{ID: "m4", Content: "昨天", Due: "2026-03-04 18:00", Created: "2026-03-01"})
+
+	memos := store.ListByDue()
+	var ids []string
+	for _, m := range memos {
+		ids = append(ids, m.ID)
+	}
+	if strings.Join(ids, ",") != "m4,m3,m2,m1" {
+		t.Errorf("order = %v", ids)
+	}
+
+	result := formatMemoList(memos, now)
+	if !strings.Contains(result, "【已过期】昨天") || !strings.Contains(result, "【今天到期】今天") ||
+		strings.Contains(result, "【已过期】今天") || !strings.Contains(result, "1 条已过期") {
+		t.Errorf("result = %q", result)
+	}
+}
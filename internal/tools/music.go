@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
+	"strings"
 
 	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
 	"github.com/iabetor/pibuddy/internal/logger"
 	"github.com/iabetor/pibuddy/internal/music"
 )
@@ -16,6 +20,8 @@ type MusicConfig struct {
 	History  *music.HistoryStore
 	Playlist *music.Playlist
 	Cache    *audio.MusicCache
+	Server   *music.APIServer    // 音乐 API 服务健康检查/托管，可为 nil
+	Context  *llm.ContextManager // 最近播放的歌曲，用于补全"换成现场版"等追问，可为 nil
 	Enabled  bool
 }
 
@@ -23,12 +29,14 @@ type MusicConfig struct {
 
 type SearchMusicTool struct {
 	provider music.Provider
+	server   *music.APIServer
 	enabled  bool
 }
 
 func NewSearchMusicTool(cfg MusicConfig) *SearchMusicTool {
 	return &SearchMusicTool{
 		provider: cfg.Provider,
+		server:   cfg.Server,
 		enabled:  cfg.Enabled,
 	}
 }
@@ -89,7 +97,7 @@ func (t *SearchMusicTool) Execute(ctx context.Context, args json.RawMessage) (st
 	}
 
 	// 搜索歌曲
-	songs, err := t.provider.Search(ctx, params.Keyword, 5)
+	songs, err := searchWithRecovery(ctx, t.provider, t.server, params.Keyword, 5)
 	if err != nil {
 		result := SearchResult{
 			Success: false,
@@ -133,23 +141,34 @@ type PlayMusicTool struct {
 	history  *music.HistoryStore
 	playlist *music.Playlist
 	cache    *audio.MusicCache
+	server   *music.APIServer
 	enabled  bool
+	rewriter *musicQueryRewriter // 纠正 ASR 误识别的歌名
+	recent   *llm.ContextManager // 最近播放的歌曲，补全点歌追问
 }
 
 func NewPlayMusicTool(cfg MusicConfig) *PlayMusicTool {
-	return &PlayMusicTool{
+	t := &PlayMusicTool{
 		provider: cfg.Provider,
 		history:  cfg.History,
 		playlist: cfg.Playlist,
 		cache:    cfg.Cache,
+		server:   cfg.Server,
 		enabled:  cfg.Enabled,
+		recent:   cfg.Context,
 	}
+	if cfg.Provider != nil {
+		t.rewriter = newMusicQueryRewriter(cfg.Provider)
+	}
+	return t
 }
 
 func (t *PlayMusicTool) Name() string { return "play_music" }
 
 func (t *PlayMusicTool) Description() string {
-	return "播放音乐。当用户想听歌时直接调用此工具，只需提供关键词（歌名、歌手名等），会自动搜索并播放最匹配的歌曲。如果第一首因版权限制无法播放，会自动尝试下一首。"
+	return "播放音乐。当用户想听歌时直接调用此工具，只需提供关键词（歌名、歌手名等），会自动搜索并播放最匹配的歌曲。如果第一首因版权限制无法播放，会自动尝试下一首。" +
+		"用户按心情或风格点歌（如'放点轻松的歌'、'来点助眠音乐'、'周杰伦的伤感情歌'）时，把心情填入 mood，歌手等其他限定词填入 keyword。" +
+		"用户要听着音乐入睡（如'放点音乐哄我睡觉'、'听着歌睡，半小时后关'）时设置 sleep_aid=true，音量会逐渐降低直到停止。"
 }
 
 func (t *PlayMusicTool) Parameters() json.RawMessage {
@@ -158,10 +177,21 @@ func (t *PlayMusicTool) Parameters() json.RawMessage {
 		"properties": {
 			"keyword": {
 				"type": "string",
-				"description": "歌曲名、歌手名或其组合，例如'周杰伦晴天'"
+				"description": "歌曲名、歌手名或其组合，例如'周杰伦晴天'；按心情点歌且没有指定歌手时留空"
+			},
+			"mood": {
+				"type": "string",
+				"description": "心情或风格，如'轻松'、'欢快'、'伤感'、'助眠'、'专注'、'运动'、'纯音乐'、'儿歌'、'摇滚'、'古风'，没有时留空"
+			},
+			"sleep_aid": {
+				"type": "boolean",
+				"description": "睡前模式：音量逐渐降低，到时间后停止播放"
+			},
+			"sleep_minutes": {
+				"type": "integer",
+				"description": "睡前模式的时长（分钟），用户没说时留空使用默认值"
 			}
-		},
-		"required": ["keyword"]
+		}
 	}`)
 }
 
@@ -177,8 +207,16 @@ type MusicResult struct {
 	PlaylistSize int     `json:"playlist_size,omitempty"` // 播放列表中的总歌曲数
 	Message      string  `json:"message,omitempty"`       // 附加消息（如恢复播放信息）
 	PositionSec  float64 `json:"position_sec,omitempty"`  // 从指定位置开始播放（秒）
+	SleepAid     bool    `json:"sleep_aid,omitempty"`     // 睡前模式，Pipeline 播放时逐渐降低音量
+	SleepMinutes int     `json:"sleep_minutes,omitempty"` // 睡前模式时长（分钟），0 表示使用配置的默认值
+	Live         bool    `json:"live,omitempty"`          // 网络电台等直播流，不缓存、不自动下一首
 }
 
+const (
+	moodPlaylistSize = 20 // 按心情播放时列表的最大歌曲数
+	moodCacheMin     = 5  // 缓存中带该标签的歌曲达到此数量时直接离线播放
+)
+
 func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
 	if !t.enabled || t.provider == nil {
 		result := MusicResult{
@@ -189,64 +227,76 @@ func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 	}
 
 	var params struct {
-		Keyword string `json:"keyword"`
+		Keyword      string `json:"keyword"`
+		Mood         string `json:"mood"`
+		SleepAid     bool   `json:"sleep_aid"`
+		SleepMinutes int    `json:"sleep_minutes"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return "", fmt.Errorf("解析参数失败: %w", err)
 	}
+	keyword := strings.TrimSpace(params.Keyword)
+	if t.recent != nil {
+		if resolved := resolveMusicFollowUp(keyword, t.recent.LastMusic()); resolved != keyword {
+			logger.Infof("[tools] 点歌追问补全: %q → %q", keyword, resolved)
+			keyword = resolved
+		}
+	}
+	if params.SleepAid && keyword == "" && params.Mood == "" {
+		// 没指定放什么时，睡前模式默认放助眠音乐
+		params.Mood = "助眠"
+	}
 
-	if params.Keyword == "" {
+	out, err := t.play(ctx, keyword, params.Mood)
+	if err != nil || !params.SleepAid {
+		return out, err
+	}
+	var result MusicResult
+	if json.Unmarshal([]byte(out), &result) != nil || !result.Success {
+		return out, nil
+	}
+	result.SleepAid = true
+	if params.SleepMinutes > 0 {
+		result.SleepMinutes = params.SleepMinutes
+	}
+	return marshalResult(result)
+}
+
+// play 按关键词或心情搜索并开始播放列表。
+func (t *PlayMusicTool) play(ctx context.Context, keyword, moodName string) (string, error) {
+	// 按心情/风格点歌：模型给出 mood，或关键词本身就是"轻松的歌"这类说法
+	if mood, ok := music.FindMood(moodName); ok {
+		return t.playMood(ctx, mood, keyword)
+	}
+	if mood, rest, ok := music.ParseMood(keyword); ok {
+		return t.playMood(ctx, mood, rest)
+	}
+	if keyword == "" {
+		// 不认识的风格词当作普通关键词搜索
+		keyword = strings.TrimSpace(moodName)
+	}
+
+	if keyword == "" {
 		return "", fmt.Errorf("缺少 keyword 参数")
 	}
 
 	// 1. 先查本地缓存（离线优先）
 	if t.cache != nil && t.cache.Enabled() {
-		cachedItems := t.cache.Search(params.Keyword)
+		cachedItems := t.cache.Search(keyword)
 		if len(cachedItems) > 0 {
-			logger.Infof("[music] 缓存命中 %d 首: %s", len(cachedItems), params.Keyword)
+			logger.Infof("[music] 缓存命中 %d 首: %s", len(cachedItems), keyword)
 
 			var playlistItems []music.PlaylistItem
 			for _, ci := range cachedItems {
 				cacheKey := fmt.Sprintf("%s_%d", ci.Provider, ci.ID)
-				playlistItems = append(playlistItems, music.PlaylistItem{
-					Song: music.Song{
-						ID:     ci.ID,
-						Name:   ci.Name,
-						Artist: ci.Artist,
-						Album:  ci.Album,
-					},
-					URL:      "", // 从缓存播放不需要 URL
-					CacheKey: cacheKey,
-				})
+				playlistItems = append(playlistItems, cachedPlaylistItem(ci, cacheKey))
 			}
-
-			firstItem := playlistItems[0]
-			firstCacheKey := firstItem.CacheKey
-
-			if t.playlist != nil && len(playlistItems) > 0 {
-				t.playlist.Replace(playlistItems)
-				t.playlist.Next(ctx)
-			}
-
-			if t.history != nil {
-				if addErr := t.history.Add(firstItem.Song); addErr != nil {
-					logger.Debugf("[music] 保存播放历史失败: %v", addErr)
-				}
-			}
-
-			result := MusicResult{
-				Success:      true,
-				SongName:     firstItem.Song.Name,
-				Artist:       firstItem.Song.Artist,
-				CacheKey:     firstCacheKey,
-				PlaylistSize: len(playlistItems),
-			}
-			return marshalResult(result)
+			return t.startPlaylist(ctx, playlistItems, "")
 		}
 	}
 
 	// 2. 缓存未命中，走原有的网络搜索流程
-	songs, err := t.provider.Search(ctx, params.Keyword, 10)
+	songs, err := searchWithRecovery(ctx, t.provider, t.server, keyword, 10)
 	if err != nil {
 		result := MusicResult{
 			Success: false,
@@ -255,6 +305,18 @@ func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		return marshalResult(result)
 	}
 
+	// 3. 搜索结果与关键词对不上时，尝试纠正被误识别的歌名（如中英混杂的英文歌名）
+	if t.rewriter != nil {
+		if rewritten, ok := t.rewriter.Rewrite(ctx, keyword, songs); ok {
+			retry, retryErr := t.provider.Search(ctx, rewritten, 10)
+			if retryErr != nil {
+				logger.Debugf("[music] 按纠正后的关键词搜索失败: %v", retryErr)
+			} else if len(retry) > 0 {
+				songs = retry
+			}
+		}
+	}
+
 	if len(songs) == 0 {
 		result := MusicResult{
 			Success: false,
@@ -263,16 +325,128 @@ func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		return marshalResult(result)
 	}
 
-	providerName := t.provider.ProviderName()
+	playlistItems := t.resolvePlayable(ctx, songs)
+	if len(playlistItems) == 0 {
+		result := MusicResult{
+			Success: false,
+			Error:   fmt.Sprintf("搜索到 %d 首歌曲，但均因版权限制无法播放", len(songs)),
+		}
+		return marshalResult(result)
+	}
+	return t.startPlaylist(ctx, playlistItems, "")
+}
+
+// playMood 按心情/风格播放：缓存里带该标签的歌够多时离线播放，
+// 否则用风格关键词（歌单名、风格词）在音乐平台搜索，合并成一个列表。
+// rest 为心情之外的限定词（如歌手名），可为空。
+func (t *PlayMusicTool) playMood(ctx context.Context, mood music.Mood, rest string) (string, error) {
+	message := fmt.Sprintf("按「%s」风格播放", mood.Tag)
+	logger.Infof("[music] 按心情播放: %s (限定词: %q)", mood.Tag, rest)
+
+	// 1. 缓存中已打过该标签的歌
+	if t.cache != nil && t.cache.Enabled() {
+		var playlistItems []music.PlaylistItem
+		for _, ci := range t.cache.SearchByTag(mood.Tag) {
+			if rest != "" && !strings.Contains(strings.ToLower(ci.Artist+" "+ci.Name), strings.ToLower(rest)) {
+				continue
+			}
+			playlistItems = append(playlistItems, cachedPlaylistItem(ci, fmt.Sprintf("%s_%d", ci.Provider, ci.ProviderID)))
+		}
+		if len(playlistItems) >= moodCacheMin {
+			logger.Infof("[music] 缓存中有 %d 首「%s」歌曲", len(playlistItems), mood.Tag)
+			rand.Shuffle(len(playlistItems), func(i, j int) {
+				playlistItems[i], playlistItems[j] = playlistItems[j], playlistItems[i]
+			})
+			if len(playlistItems) > moodPlaylistSize {
+				playlistItems = playlistItems[:moodPlaylistSize]
+			}
+			return t.startPlaylist(ctx, playlistItems, message)
+		}
+	}
+
+	// 2. 按风格关键词搜索，各关键词的结果交替合并，避免整张列表都来自同一个歌单
+	var lists [][]music.Song
+	var lastErr error
+	for _, q := range mood.Queries {
+		songs, err := searchWithRecovery(ctx, t.provider, t.server, strings.TrimSpace(rest+" "+q), 10)
+		if err != nil {
+			logger.Debugf("[music] 搜索 %q 失败: %v", q, err)
+			lastErr = err
+			continue
+		}
+		lists = append(lists, songs)
+	}
+	songs := interleaveSongs(lists, moodPlaylistSize)
+	if len(songs) == 0 {
+		result := MusicResult{Success: false, Error: "没有找到相关歌曲"}
+		if lastErr != nil {
+			result.Error = fmt.Sprintf("搜索失败: %v", lastErr)
+		}
+		return marshalResult(result)
+	}
+
+	playlistItems := t.resolvePlayable(ctx, songs)
+	if len(playlistItems) == 0 {
+		result := MusicResult{
+			Success: false,
+			Error:   fmt.Sprintf("搜索到 %d 首歌曲，但均因版权限制无法播放", len(songs)),
+		}
+		return marshalResult(result)
+	}
+	if t.cache != nil {
+		for _, item := range playlistItems {
+			t.cache.AddTags(item.CacheKey, []string{mood.Tag}, "search")
+		}
+	}
+	return t.startPlaylist(ctx, playlistItems, message)
+}
+
+// interleaveSongs 轮流从各列表取歌，按 ID 去重，最多取 limit 首。
+func interleaveSongs(lists [][]music.Song, limit int) []music.Song {
+	seen := make(map[int64]bool)
+	var songs []music.Song
+	for i := 0; len(songs) < limit; i++ {
+		added := false
+		for _, list := range lists {
+			if i >= len(list) {
+				continue
+			}
+			added = true
+			if song := list[i]; !seen[song.ID] {
+				seen[song.ID] = true
+				songs = append(songs, song)
+				if len(songs) >= limit {
+					break
+				}
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return songs
+}
 
-	// 依次尝试获取播放 URL，跳过无版权 / VIP 歌曲
+// cachedPlaylistItem 把缓存条目转换为播放列表项，从缓存播放不需要 URL。
+func cachedPlaylistItem(ci audio.CacheEntry, cacheKey string) music.PlaylistItem {
+	return music.PlaylistItem{
+		Song: music.Song{
+			ID:     ci.ID,
+			Name:   ci.Name,
+			Artist: ci.Artist,
+			Album:  ci.Album,
+		},
+		CacheKey: cacheKey,
+	}
+}
+
+// resolvePlayable 依次获取播放 URL，跳过无版权 / VIP 歌曲，返回可播放的列表项。
+// 同时根据歌名、专辑推断风格标签，供之后按心情点歌时使用。
+func (t *PlayMusicTool) resolvePlayable(ctx context.Context, songs []music.Song) []music.PlaylistItem {
+	providerName := t.provider.ProviderName()
 	qqProvider, isQQ := t.provider.(music.QQProvider)
 
-	var firstURL string
-	var firstSong music.Song
-	var firstCacheKey string
 	var playlistItems []music.PlaylistItem
-
 	for i, song := range songs {
 		var songURL string
 		var urlErr error
@@ -299,30 +473,25 @@ func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		}
 
 		cacheKey := fmt.Sprintf("%s_%d", providerName, song.ID)
+		if t.cache != nil {
+			t.cache.AddTags(cacheKey, music.ClassifySong(song), "metadata")
+		}
 
 		playlistItems = append(playlistItems, music.PlaylistItem{
 			Song:     song,
 			URL:      songURL,
 			CacheKey: cacheKey,
 		})
-
-		if firstURL == "" {
-			firstURL = songURL
-			firstSong = song
-			firstCacheKey = cacheKey
-		}
 	}
+	return playlistItems
+}
 
-	if firstURL == "" {
-		result := MusicResult{
-			Success: false,
-			Error:   fmt.Sprintf("搜索到 %d 首歌曲，但均因版权限制无法播放", len(songs)),
-		}
-		return marshalResult(result)
-	}
+// startPlaylist 用 items 替换播放列表并返回第一首的播放结果。
+func (t *PlayMusicTool) startPlaylist(ctx context.Context, playlistItems []music.PlaylistItem, message string) (string, error) {
+	first := playlistItems[0]
 
 	// 将所有可播放歌曲放入播放列表
-	if t.playlist != nil && len(playlistItems) > 0 {
+	if t.playlist != nil {
 		t.playlist.Replace(playlistItems)
 		t.playlist.Next(ctx)
 		logger.Infof("[music] 已将 %d 首歌曲加入播放列表", len(playlistItems))
@@ -330,25 +499,42 @@ func (t *PlayMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 
 	// 记录播放历史
 	if t.history != nil {
-		if addErr := t.history.Add(firstSong); addErr != nil {
+		if addErr := t.history.Add(first.Song); addErr != nil {
 			logger.Debugf("[music] 保存播放历史失败: %v", addErr)
 		}
 	}
 
 	result := MusicResult{
 		Success:      true,
-		SongName:     firstSong.Name,
-		Artist:       firstSong.Artist,
-		URL:          firstURL,
-		CacheKey:     firstCacheKey,
+		SongName:     first.Song.Name,
+		Artist:       first.Song.Artist,
+		URL:          first.URL,
+		CacheKey:     first.CacheKey,
 		PlaylistSize: len(playlistItems),
+		Message:      message,
 	}
 	if len(playlistItems) > 1 {
-		logger.Infof("[music] 第一首: %s - %s，列表共 %d 首", firstSong.Name, firstSong.Artist, len(playlistItems))
+		logger.Infof("[music] 第一首: %s - %s，列表共 %d 首", first.Song.Name, first.Song.Artist, len(playlistItems))
 	}
 	return marshalResult(result)
 }
 
+// searchWithRecovery 搜索歌曲；失败时检查音乐 API 服务，托管模式下自动拉起服务后重试一次。
+// 服务未运行时返回明确的提示，而不是底层的连接错误。
+func searchWithRecovery(ctx context.Context, provider music.Provider, server *music.APIServer, keyword string, limit int) ([]music.Song, error) {
+	songs, err := provider.Search(ctx, keyword, limit)
+	if err == nil || server == nil {
+		return songs, err
+	}
+
+	if healthErr := server.EnsureRunning(ctx); healthErr != nil {
+		logger.Warnf("[music] 音乐服务不可用: %v", healthErr)
+		return nil, healthErr
+	}
+	// 服务正常（或已重新拉起），重试一次
+	return provider.Search(ctx, keyword, limit)
+}
+
 func marshalResult(result MusicResult) (string, error) {
 	data, err := json.Marshal(result)
 	if err != nil {
@@ -478,10 +664,12 @@ func (t *NextMusicTool) Execute(ctx context.Context, args json.RawMessage) (stri
 
 type SetPlayModeTool struct {
 	playlist *music.Playlist
+	settings *database.Settings
 }
 
-func NewSetPlayModeTool(playlist *music.Playlist) *SetPlayModeTool {
-	return &SetPlayModeTool{playlist: playlist}
+// NewSetPlayModeTool 创建播放模式工具。settings 用于保存播放模式，重启后恢复，可为 nil。
+func NewSetPlayModeTool(playlist *music.Playlist, settings *database.Settings) *SetPlayModeTool {
+	return &SetPlayModeTool{playlist: playlist, settings: settings}
 }
 
 func (t *SetPlayModeTool) Name() string { return "set_play_mode" }
@@ -516,19 +704,17 @@ func (t *SetPlayModeTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return "", fmt.Errorf("解析参数失败: %w", err)
 	}
 
-	var mode music.PlayMode
-	switch params.Mode {
-	case "sequence":
-		mode = music.PlayModeSequence
-	case "loop":
-		mode = music.PlayModeLoop
-	case "single":
-		mode = music.PlayModeSingle
-	default:
+	mode, ok := music.ParsePlayMode(params.Mode)
+	if !ok {
 		return `{"success":false,"message":"无效的播放模式，请选择 sequence/loop/single"}`, nil
 	}
 
 	t.playlist.SetMode(mode)
+	if t.settings != nil {
+		if err := t.settings.SetString(database.SettingPlayMode, params.Mode); err != nil {
+			logger.Warnf("[music] 保存播放模式失败: %v", err)
+		}
+	}
 	return fmt.Sprintf(`{"success":true,"message":"已切换为%s模式"}`, mode), nil
 }
 
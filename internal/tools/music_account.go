@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// ---- MusicAccountTool 音乐账号管理 ----
+
+// MusicAccountTool 查看和切换音乐平台的登录账号（账号通过 pibuddy-music login --account 登录）。
+type MusicAccountTool struct {
+	store    *music.AccountStore
+	provider string
+}
+
+// NewMusicAccountTool 创建音乐账号工具。provider 为当前音乐平台（qq 或 netease）。
+func NewMusicAccountTool(store *music.AccountStore, provider string) *MusicAccountTool {
+	return &MusicAccountTool{store: store, provider: provider}
+}
+
+func (t *MusicAccountTool) Name() string { return "music_account" }
+func (t *MusicAccountTool) Description() string {
+	return "查看或切换音乐账号。当用户说'现在用的哪个音乐账号'、'有哪些音乐账号'、'切换到小明的音乐账号'时使用。"
+}
+func (t *MusicAccountTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["list", "switch"],
+				"description": "list: 列出账号和登录状态；switch: 切换账号"
+			},
+			"account": {
+				"type": "string",
+				"description": "要切换到的账号名（switch 时必填）"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *MusicAccountTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action  string `json:"action"`
+		Account string `json:"account"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	infos := t.store.List(t.provider)
+	switch params.Action {
+	case "list":
+		if len(infos) == 0 {
+			return "还没有登录任何音乐账号，请先运行 pibuddy-music login 登录。", nil
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("共 %d 个音乐账号:\n", len(infos)))
+		for _, info := range infos {
+			sb.WriteString("- " + info.Name)
+			if info.Active {
+				sb.WriteString("（当前）")
+			}
+			switch {
+			case info.Err != nil:
+				sb.WriteString(": cookie 已损坏，需要重新登录")
+			case !info.ExpiresAt.IsZero() && time.Now().After(info.ExpiresAt):
+				sb.WriteString(": 登录可能已过期")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+
+	case "switch":
+		name := strings.TrimSpace(params.Account)
+		if name == "" {
+			return "请告诉我要切换到哪个账号。", nil
+		}
+		// 容忍 LLM 传入带"的账号"等后缀或大小写不同的名称
+		for _, info := range infos {
+			if strings.EqualFold(info.Name, name) || strings.Contains(name, info.Name) {
+				if info.Active {
+					return fmt.Sprintf("当前已经在使用账号 %s。", info.Name), nil
+				}
+				if info.Err != nil {
+					return fmt.Sprintf("账号 %s 的登录信息已损坏，请重新运行 pibuddy-music login --account %s 登录。", info.Name, info.Name), nil
+				}
+				if err := t.store.Switch(t.provider, info.Name); err != nil {
+					return fmt.Sprintf("切换失败: %v", err), nil
+				}
+				return fmt.Sprintf("已切换到音乐账号 %s。", info.Name), nil
+			}
+		}
+		return fmt.Sprintf("没有找到账号 %s，可以先说'有哪些音乐账号'查看。", name), nil
+
+	default:
+		return fmt.Sprintf("不支持的操作: %s", params.Action), nil
+	}
+}
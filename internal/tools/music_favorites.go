@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/iabetor/pibuddy/internal/audio"
 	"github.com/iabetor/pibuddy/internal/llm"
 	"github.com/iabetor/pibuddy/internal/music"
 )
@@ -16,6 +17,7 @@ type FavoritesConfig struct {
 	Store          *music.FavoritesStore
 	Playlist       *music.Playlist
 	ContextManager *llm.ContextManager
+	Cache          *audio.MusicCache // 可选，已缓存（含空闲时预下载）的收藏直接从本地播放
 }
 
 // AddFavoriteTool 收藏歌曲工具。
@@ -265,6 +267,7 @@ type PlayFavoritesTool struct {
 	playlist       *music.Playlist
 	contextManager *llm.ContextManager
 	provider       music.Provider
+	cache          *audio.MusicCache
 }
 
 // NewPlayFavoritesTool 创建播放收藏工具。
@@ -274,6 +277,7 @@ func NewPlayFavoritesTool(cfg FavoritesConfig, provider music.Provider) *PlayFav
 		playlist:       cfg.Playlist,
 		contextManager: cfg.ContextManager,
 		provider:       provider,
+		cache:          cfg.Cache,
 	}
 }
 
@@ -338,26 +342,9 @@ func (t *PlayFavoritesTool) Execute(ctx context.Context, args json.RawMessage) (
 	// 转换为播放列表项
 	items := make([]music.PlaylistItem, len(songs))
 	for i, s := range songs {
-		// 构造 Extra 字段
-		extra := make(map[string]interface{})
-		if s.MID != "" {
-			extra["mid"] = s.MID
-		}
-		if s.MediaMID != "" {
-			extra["media_mid"] = s.MediaMID
-		}
-		if s.Provider != "" {
-			extra["provider"] = s.Provider
-		}
-
-		items[i] = music.PlaylistItem{
-			Song: music.Song{
-				ID:     s.ID,
-				Name:   s.Name,
-				Artist: s.Artist,
-				Album:  s.Album,
-				Extra:  extra,
-			},
+		items[i] = music.PlaylistItem{Song: s.Song()}
+		if key := FavoriteCacheKey(t.provider, s); key != "" && t.cache != nil && t.cache.Enabled() && t.cache.Has(key) {
+			items[i].CacheKey = key
 		}
 	}
 
@@ -367,6 +354,14 @@ func (t *PlayFavoritesTool) Execute(ctx context.Context, args json.RawMessage) (
 	return fmt.Sprintf(`{"success":true,"message":"正在播放你的收藏歌单，共%d首歌","count":%d}`, len(songs), len(songs)), nil
 }
 
+// FavoriteCacheKey 返回收藏歌曲的缓存标识，收藏来自其他音乐平台时返回空。
+func FavoriteCacheKey(provider music.Provider, s music.FavoriteSong) string {
+	if provider == nil || (s.Provider != "" && s.Provider != provider.ProviderName()) {
+		return ""
+	}
+	return fmt.Sprintf("%s_%d", provider.ProviderName(), s.ID)
+}
+
 // shuffleSongs 随机打乱歌曲顺序。
 func shuffleSongs(songs []music.FavoriteSong) []music.FavoriteSong {
 	result := make([]music.FavoriteSong, len(songs))
@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+// 点歌追问的指代词。大模型经常把"放她别的歌"原样写进 keyword，或只传"现场版"，
+// 这里按最近播放的歌曲在本地补全，不依赖大模型记住上一首歌。
+var (
+	// musicArtistRefs 指代上一首歌的歌手
+	musicArtistRefs = []string{"这个歌手的", "这个歌手", "这位歌手的", "这位歌手", "同一个歌手的", "她的", "他的", "TA的", "ta的"}
+	// musicSongRefs 指代上一首歌
+	musicSongRefs = []string{"刚才那首歌", "刚才那首", "这首歌", "那首歌", "这首", "那首", "这歌"}
+	// musicOtherRefs 同一歌手的其他歌
+	musicOtherRefs = []string{"别的歌", "其他的歌", "其它的歌", "其他歌", "其它歌", "另外的歌", "别的", "其他的", "其它的"}
+	// musicVersions 同一首歌的其他版本
+	musicVersions = []string{"现场版", "live版", "Live版", "LIVE版", "live", "Live", "LIVE", "伴奏版", "伴奏", "DJ版", "dj版",
+		"纯音乐版", "钢琴版", "吉他版", "原唱", "翻唱", "粤语版", "国语版", "英文版", "女声版", "男声版", "完整版", "慢速版", "加速版"}
+	// musicFillers 只剩这些词时说明没有指定新的歌名
+	musicFillers = []string{"换成", "换一个", "换个", "来一首", "来一个", "来个", "放一首", "放", "听", "唱的", "唱", "版本", "一下", "的", "歌"}
+)
+
+// resolveMusicFollowUp 把点歌关键词里的指代补全为最近播放的歌手、歌名：
+//   - "她别的歌"、"这个歌手的" → 歌手名
+//   - "这首歌 现场版"、"现场版" → 歌手 歌名 现场版
+//
+// 没有指代或没有播放过歌曲时原样返回。
+func resolveMusicFollowUp(keyword string, last llm.MusicSlots) string {
+	if last.Song == "" && last.Artist == "" {
+		return keyword
+	}
+	k := strings.TrimSpace(keyword)
+	for _, prefix := range []string{"换成", "放", "听", "来个", "来首"} {
+		k = strings.TrimPrefix(k, prefix)
+	}
+
+	artist := false
+	for _, ref := range musicArtistRefs {
+		if strings.Contains(k, ref) {
+			k = strings.Replace(k, ref, "", 1)
+			artist = true
+			break
+		}
+	}
+	// "她别的歌"、"他唱的"：句首单独的人称代词
+	for _, ref := range []string{"她", "他"} {
+		if strings.HasPrefix(k, ref) {
+			k = strings.TrimPrefix(k, ref)
+			artist = true
+		}
+	}
+	other := false
+	for _, ref := range musicOtherRefs {
+		if strings.Contains(k, ref) {
+			k = strings.Replace(k, ref, "", 1)
+			other = true
+			break
+		}
+	}
+	song := false
+	for _, ref := range musicSongRefs {
+		if strings.Contains(k, ref) {
+			k = strings.Replace(k, ref, "", 1)
+			song = true
+			break
+		}
+	}
+	var version string
+	for _, v := range musicVersions {
+		if strings.Contains(k, v) {
+			version = v
+			k = strings.Replace(k, v, "", 1)
+			break
+		}
+	}
+
+	// 去掉指代后还有别的内容（如"她的晴天"里的"晴天"），只替换指代部分
+	rest := k
+	for _, f := range musicFillers {
+		rest = strings.ReplaceAll(rest, f, "")
+	}
+	rest = strings.TrimSpace(rest)
+
+	// 没有指代：用户点的是新歌（"晴天 现场版"也按原样搜索）
+	if !artist && !other && !song && (version == "" || rest != "") {
+		return keyword
+	}
+
+	var parts []string
+	switch {
+	case rest != "":
+		if artist || other {
+			parts = append(parts, last.Artist)
+		}
+		if song {
+			parts = append(parts, last.Song)
+		}
+		parts = append(parts, rest)
+		if version != "" {
+			parts = append(parts, version)
+		}
+	case other || (artist && !song && version == ""):
+		// 同一歌手的其他歌
+		parts = append(parts, last.Artist)
+	case song || version != "":
+		// 同一首歌（换个版本）
+		parts = append(parts, last.Artist, last.Song)
+		if version != "" {
+			parts = append(parts, version)
+		}
+	default:
+		return keyword
+	}
+
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 0 {
+		return keyword
+	}
+	return strings.Join(out, " ")
+}
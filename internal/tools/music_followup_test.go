@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+func TestResolveMusicFollowUp(t *testing.T) {
+	last := llm.MusicSlots{Song: "红豆", Artist: "王菲"}
+	tests := []struct {
+		keyword string
+		want    string
+	}{
+		{"现场版", "王菲 红豆 现场版"},
+		{"换成现场版", "王菲 红豆 现场版"},
+		{"这首歌的伴奏", "王菲 红豆 伴奏"},
+		{"她别的歌", "王菲"},
+		{"放她的歌", "王菲"},
+		{"这个歌手的其他歌", "王菲"},
+		{"她的传奇", "王菲 传奇"},
+		{"别的", "王菲"},
+		// 没有指代：原样返回
+		{"周杰伦 晴天", "周杰伦 晴天"},
+		{"晴天 live", "晴天 live"},
+		{"吉他版晴天", "吉他版晴天"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveMusicFollowUp(tt.keyword, last); got != tt.want {
+			t.Errorf("resolveMusicFollowUp(%q) = %q, want %q", tt.keyword, got, tt.want)
+		}
+	}
+
+	// 没有播放过歌曲时不处理
+	if got := resolveMusicFollowUp("现场版", llm.MusicSlots{}); got != "现场版" {
+		t.Errorf("without last music = %q", got)
+	}
+}
@@ -46,13 +46,18 @@ func (t *ResumeMusicTool) Parameters() json.RawMessage {
 
 // Execute 执行工具。
 func (t *ResumeMusicTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	paused := t.pausedStore.Get()
+	return marshalResult(RestorePausedMusic(t.playlist, t.pausedStore, t.musicCache))
+}
+
+// RestorePausedMusic 把暂停时保存的播放列表恢复到 playlist，返回当前歌曲。
+// 暂停不超过 1 分钟且有缓存时带上恢复位置，由调用方从该位置播放。
+func RestorePausedMusic(playlist *music.Playlist, pausedStore *music.PausedMusicStore, musicCache *audio.MusicCache) MusicResult {
+	paused := pausedStore.Get()
 	if paused == nil || len(paused.Items) == 0 {
-		result := MusicResult{
+		return MusicResult{
 			Success: false,
 			Error:   "没有暂停的音乐",
 		}
-		return marshalResult(result)
 	}
 
 	// 检查时间间隔：超过 1 分钟就不恢复位置
@@ -60,21 +65,20 @@ func (t *ResumeMusicTool) Execute(ctx context.Context, args json.RawMessage) (st
 	fromPosition := timeSincePaused <= time.Minute
 
 	// 恢复播放列表和当前索引
-	t.playlist.ReplaceWithIndex(paused.Items, paused.Index)
-	t.playlist.SetMode(paused.Mode)
+	playlist.ReplaceWithIndex(paused.Items, paused.Index)
+	playlist.SetMode(paused.Mode)
 
 	// 获取当前歌曲
-	item := t.playlist.Current()
+	item := playlist.Current()
 	if item == nil {
-		result := MusicResult{
+		return MusicResult{
 			Success: false,
 			Error:   "无法获取当前歌曲",
 		}
-		return marshalResult(result)
 	}
 
 	// 清除暂停状态
-	t.pausedStore.Clear()
+	pausedStore.Clear()
 
 	// 返回当前歌曲的信息
 	result := MusicResult{
@@ -87,8 +91,8 @@ func (t *ResumeMusicTool) Execute(ctx context.Context, args json.RawMessage) (st
 	}
 
 	// 检查是否可以从位置恢复
-	if fromPosition && paused.PositionSec > 0 && paused.CacheKey != "" && t.musicCache != nil {
-		if _, ok := t.musicCache.Lookup(paused.CacheKey); ok {
+	if fromPosition && paused.PositionSec > 0 && paused.CacheKey != "" && musicCache != nil {
+		if _, ok := musicCache.Lookup(paused.CacheKey); ok {
 			// 缓存存在，返回位置信息让 Pipeline 处理
 			result.PositionSec = paused.PositionSec
 			result.Message = fmt.Sprintf("从 %.0f 秒处恢复播放", paused.PositionSec)
@@ -99,7 +103,7 @@ func (t *ResumeMusicTool) Execute(ctx context.Context, args json.RawMessage) (st
 		result.Message = fmt.Sprintf("暂停已超过 %.0f 分钟，从头播放", timeSincePaused.Minutes())
 	}
 
-	return marshalResult(result)
+	return result
 }
 
 // StopMusicTool 停止播放工具（清除暂停状态）。
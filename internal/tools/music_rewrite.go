@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/music"
+	"github.com/mozillazg/go-pinyin"
+)
+
+// musicRewriteThreshold 音近匹配的最低得分，低于该值不改写关键词。
+const musicRewriteThreshold = 0.8
+
+// musicQueryRewriter 纠正被中文 ASR 误识别的歌名（如"夏披 of 有" → "Shape of You"）。
+// 搜索结果与关键词对不上时，把关键词转成拼音，再与搜索联想、拼音搜索的结果做音近模糊匹配。
+type musicQueryRewriter struct {
+	provider music.Provider
+	args     pinyin.Args
+}
+
+// newMusicQueryRewriter 创建歌名纠正器。
+func newMusicQueryRewriter(provider music.Provider) *musicQueryRewriter {
+	return &musicQueryRewriter{
+		provider: provider,
+		args:     pinyin.NewArgs(),
+	}
+}
+
+// Rewrite 在搜索结果与关键词不匹配时尝试纠正关键词。
+// 返回纠正后的搜索关键词（歌名 + 歌手），无法纠正时 ok 为 false。
+func (r *musicQueryRewriter) Rewrite(ctx context.Context, keyword string, results []music.Song) (string, bool) {
+	if songsMatchKeyword(keyword, results) {
+		return "", false
+	}
+
+	candidates := append([]music.Song(nil), results...)
+
+	// 拼音转写后的关键词，如 "xiapi of you"
+	translit := r.transliterate(keyword)
+	if suggester, ok := r.provider.(music.Suggester); ok {
+		queries := []string{keyword}
+		if translit != keyword {
+			queries = append(queries, translit)
+		}
+		for _, q := range queries {
+			songs, err := suggester.Suggest(ctx, q)
+			if err != nil {
+				logger.Debugf("[music] 搜索联想 %q 失败: %v", q, err)
+				continue
+			}
+			candidates = append(candidates, songs...)
+		}
+	}
+	if translit != keyword {
+		if songs, err := r.provider.Search(ctx, translit, 10); err == nil {
+			candidates = append(candidates, songs...)
+		}
+	}
+
+	keyKey := r.phoneticKey(keyword)
+	var best music.Song
+	bestScore := 0.0
+	for _, song := range candidates {
+		score := phoneticSimilarity(keyKey, r.phoneticKey(song.Name))
+		if song.Artist != "" {
+			// 关键词里可能带了歌手名
+			if s := phoneticSimilarity(keyKey, r.phoneticKey(song.Artist+" "+song.Name)); s > score {
+				score = s
+			}
+			if s := phoneticSimilarity(keyKey, r.phoneticKey(song.Name+" "+song.Artist)); s > score {
+				score = s
+			}
+		}
+		if score > bestScore {
+			best, bestScore = song, score
+		}
+	}
+
+	if bestScore < musicRewriteThreshold {
+		logger.Debugf("[music] 未找到音近歌名: %q (最高 %.2f)", keyword, bestScore)
+		return "", false
+	}
+
+	rewritten := best.Name
+	if best.Artist != "" {
+		rewritten += " " + strings.ReplaceAll(best.Artist, "/", " ")
+	}
+	logger.Infof("[music] 歌名纠正: %q → %q (得分 %.2f)", keyword, rewritten, bestScore)
+	return rewritten, true
+}
+
+// songsMatchKeyword 判断搜索结果中是否有歌名或歌手与关键词直接对应（包含关系），此时无需纠正。
+func songsMatchKeyword(keyword string, songs []music.Song) bool {
+	kw := normalizeTitle(keyword)
+	if kw == "" {
+		return true
+	}
+	for _, s := range songs {
+		for _, field := range []string{s.Name, s.Artist} {
+			v := normalizeTitle(field)
+			if v != "" && (strings.Contains(kw, v) || strings.Contains(v, kw)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeTitle 去掉空白和标点并转小写，只保留字母、数字和汉字。
+func normalizeTitle(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// transliterate 将汉字转写为拼音（连续汉字拼接为一个词），英文单词保持原样（小写）。
+func (r *musicQueryRewriter) transliterate(s string) string {
+	var words []string
+	var cur strings.Builder
+	lastHan := false
+	flush := func() {
+		if cur.Len() > 0 {
+			words = append(words, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, c := range s {
+		switch {
+		case unicode.Is(unicode.Han, c):
+			if !lastHan {
+				flush()
+			}
+			if py := pinyin.LazyPinyin(string(c), r.args); len(py) > 0 {
+				cur.WriteString(py[0])
+			}
+			lastHan = true
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)):
+			if lastHan {
+				flush()
+			}
+			cur.WriteRune(unicode.ToLower(c))
+			lastHan = false
+		default:
+			flush()
+			lastHan = false
+		}
+	}
+	flush()
+
+	result := strings.Join(words, " ")
+	if result == strings.ToLower(strings.Join(strings.Fields(s), " ")) {
+		return s
+	}
+	return result
+}
+
+// phoneticKey 把文本转成小写字母读音串（汉字转拼音，单词间以空格分隔），用于音近比较。
+func (r *musicQueryRewriter) phoneticKey(s string) string {
+	return r.transliterate(strings.ToLower(s))
+}
+
+// phoneticSimilarity 计算两个读音串的相似度（0~1）。
+// 以辅音骨架为主（中文 ASR 往往保留辅音、改变元音），完整读音为辅。
+func phoneticSimilarity(a, b string) float64 {
+	sa, sb := consonantSkeleton(a), consonantSkeleton(b)
+	if len(sa) < 2 || len(sb) < 2 {
+		return 0
+	}
+	fa, fb := strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", "")
+	return 0.7*editSimilarity(sa, sb) + 0.3*editSimilarity(fa, fb)
+}
+
+// skeletonReplacer 先合并发音相同的字母组合。
+var skeletonReplacer = strings.NewReplacer(
+	"sch", "s", "sh", "s", "ch", "z", "zh", "z", "th", "s",
+	"ph", "f", "ck", "k", "gh", "", "ng", "n",
+	"ce", "se", "ci", "si", "cy", "sy", // 软音 c
+)
+
+// consonantClass 把辅音归并到中英文都容易混淆的音类，元音和 h、y 返回 0（丢弃）。
+func consonantClass(c byte) byte {
+	switch c {
+	case 'b', 'p':
+		return 'b'
+	case 'd', 't':
+		return 'd'
+	case 'g', 'k', 'c':
+		return 'k'
+	case 'j', 'q', 'z':
+		return 'z'
+	case 'x', 's':
+		return 's'
+	case 'f', 'v', 'w':
+		return 'f'
+	case 'l', 'r':
+		return 'l'
+	case 'm':
+		return 'm'
+	case 'n':
+		return 'n'
+	}
+	return 0
+}
+
+// consonantSkeleton 提取读音串的辅音骨架，相邻重复音类合并。
+// 英文词尾的 er 在中文里通常读作"儿/尔"或被省略，统一去掉 r。
+func consonantSkeleton(s string) string {
+	var sb strings.Builder
+	var last byte
+	for _, word := range strings.Fields(s) {
+		if len(word) > 2 && strings.HasSuffix(word, "er") {
+			word = word[:len(word)-1]
+		}
+		word = skeletonReplacer.Replace(word)
+		for i := 0; i < len(word); i++ {
+			c := consonantClass(word[i])
+			if c == 0 || c == last {
+				continue
+			}
+			sb.WriteByte(c)
+			last = c
+		}
+	}
+	return sb.String()
+}
+
+// editSimilarity 基于编辑距离的相似度：1 - 距离/较长串长度。
+func editSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	maxLen := len(a)
+	if len(b) > maxLen {
+		maxLen = len(b)
+	}
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(maxLen)
+}
+
+// levenshtein 计算两个字节串的编辑距离。
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/music"
+)
+
+// suggestProvider 按关键词返回不同搜索结果，并支持搜索联想。
+type suggestProvider struct {
+	MockProvider
+	byKeyword   map[string][]music.Song
+	suggestions []music.Song
+	searched    []string
+}
+
+func (p *suggestProvider) Search(ctx context.Context, keyword string, limit int) ([]music.Song, error) {
+	p.searched = append(p.searched, keyword)
+	return p.byKeyword[keyword], nil
+}
+
+func (p *suggestProvider) Suggest(ctx context.Context, keyword string) ([]music.Song, error) {
+	return p.suggestions, nil
+}
+
+func TestPhoneticSimilarity(t *testing.T) {
+	r := newMusicQueryRewriter(&MockProvider{})
+
+	tests := []struct {
+		query, title string
+		match        bool
+	}{
+		{"夏披 of 有", "Shape of You", true},
+		{"比利我", "Believer", true},
+		{"迪斯帕西托", "Despacito", true},
+		{"青天", "晴天", true},
+		{"夏披 of 有", "Shape of My Heart", false},
+		{"夏披 of 有", "夜曲", false},
+	}
+	for _, tt := range tests {
+		score := phoneticSimilarity(r.phoneticKey(tt.query), r.phoneticKey(tt.title))
+		if got := score >= musicRewriteThreshold; got != tt.match {
+			t.Errorf("%q vs %q: score=%.2f, match=%v, want %v", tt.query, tt.title, score, got, tt.match)
+		}
+	}
+}
+
+func TestMusicQueryRewriter_Rewrite(t *testing.T) {
+	provider := &suggestProvider{
+		suggestions: []music.Song{
+			{Name: "Shape of My Heart", Artist: "Sting"},
+			{Name: "Shape of You", Artist: "Ed Sheeran"},
+		},
+	}
+	r := newMusicQueryRewriter(provider)
+
+	// 搜索结果与关键词不匹配时纠正
+	rewritten, ok := r.Rewrite(context.Background(), "夏披 of 有", []music.Song{{Name: "夏天", Artist: "某歌手"}})
+	if !ok || rewritten != "Shape of You Ed Sheeran" {
+		t.Errorf("Rewrite = %q, %v", rewritten, ok)
+	}
+
+	// 结果已匹配时不纠正
+	if _, ok := r.Rewrite(context.Background(), "周杰伦晴天", []music.Song{{Name: "晴天", Artist: "周杰伦"}}); ok {
+		t.Error("结果已匹配时不应纠正")
+	}
+	if _, ok := r.Rewrite(context.Background(), "周杰伦", []music.Song{{Name: "夜曲", Artist: "周杰伦"}}); ok {
+		t.Error("按歌手搜索时不应纠正")
+	}
+}
+
+func TestPlayMusicTool_RewriteMangledTitle(t *testing.T) {
+	provider := &suggestProvider{
+		MockProvider: MockProvider{urlResult: "http://example.com/shape.mp3"},
+		byKeyword: map[string][]music.Song{
+			"Shape of You Ed Sheeran": {{ID: 42, Name: "Shape of You", Artist: "Ed Sheeran"}},
+		},
+		suggestions: []music.Song{{Name: "Shape of You", Artist: "Ed Sheeran"}},
+	}
+	tool := NewPlayMusicTool(MusicConfig{Provider: provider, Enabled: true})
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"keyword": "夏披 of 有"}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	var musicResult MusicResult
+	if err := json.Unmarshal([]byte(result), &musicResult); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if !musicResult.Success || musicResult.SongName != "Shape of You" {
+		t.Errorf("应播放纠正后的歌曲: %+v (搜索记录 %v)", musicResult, provider.searched)
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/iabetor/pibuddy/internal/audio"
+	"github.com/iabetor/pibuddy/internal/database"
 	"github.com/iabetor/pibuddy/internal/music"
 )
 
@@ -284,3 +290,160 @@ func TestMusicTool_Metadata(t *testing.T) {
 		}
 	})
 }
+
+func TestMusicAccountTool_Execute(t *testing.T) {
+	store := music.NewAccountStore(t.TempDir())
+	tool := NewMusicAccountTool(store, "qq")
+	ctx := context.Background()
+
+	result, _ := tool.Execute(ctx, json.RawMessage(`{"action":"list"}`))
+	if !strings.Contains(result, "还没有登录") {
+		t.Errorf("无账号时应提示登录: %s", result)
+	}
+
+	cookies := []http.Cookie{{Name: "uin", Value: "1"}}
+	store.Save("qq", "", &music.CookieData{Cookies: cookies})
+	store.Save("qq", "kid", &music.CookieData{Cookies: cookies})
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"action":"switch","account":"kid的账号"}`))
+	if !strings.Contains(result, "已切换到音乐账号 kid") {
+		t.Errorf("switch 结果 = %s", result)
+	}
+	if store.Active("qq") != "kid" {
+		t.Errorf("当前账号 = %s, want kid", store.Active("qq"))
+	}
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"action":"list"}`))
+	if !strings.Contains(result, "kid（当前）") {
+		t.Errorf("list 应标记当前账号: %s", result)
+	}
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"action":"switch","account":"mom"}`))
+	if !strings.Contains(result, "没有找到账号") {
+		t.Errorf("未知账号应提示: %s", result)
+	}
+}
+
+// queryProvider 按搜索词返回不同的歌曲，记录收到的搜索词
+type queryProvider struct {
+	queries []string
+}
+
+func (p *queryProvider) Search(ctx context.Context, keyword string, limit int) ([]music.Song, error) {
+	p.queries = append(p.queries, keyword)
+	base := int64(len(p.queries) * 100)
+	return []music.Song{
+		{ID: base + 1, Name: keyword + " 1", Artist: "歌手"},
+		{ID: base + 2, Name: keyword + " 2 (钢琴版)", Artist: "歌手"},
+	}, nil
+}
+
+func (p *queryProvider) GetSongURL(ctx context.Context, songID int64) (string, error) {
+	return fmt.Sprintf("http://example.com/%d.mp3", songID), nil
+}
+
+func (p *queryProvider) ProviderName() string { return "mock" }
+
+func TestPlayMusicTool_Mood(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	cache, err := audio.NewMusicCache(db, filepath.Join(t.TempDir(), "cache"), 10)
+	if err != nil {
+		t.Fatalf("创建缓存失败: %v", err)
+	}
+
+	provider := &queryProvider{}
+	tool := NewPlayMusicTool(MusicConfig{Provider: provider, Cache: cache, Enabled: true})
+	ctx := context.Background()
+
+	// 关键词里的心情说法不应当作歌名搜索
+	result, err := tool.Execute(ctx, json.RawMessage(`{"keyword": "放点轻松的歌"}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var r MusicResult
+	json.Unmarshal([]byte(result), &r)
+	if !r.Success || !strings.Contains(r.Message, "轻松") {
+		t.Fatalf("结果 = %s", result)
+	}
+	mood, _ := music.FindMood("轻松")
+	if len(provider.queries) != len(mood.Queries) || provider.queries[0] != mood.Queries[0] {
+		t.Errorf("搜索词 = %v, want %v", provider.queries, mood.Queries)
+	}
+	if r.PlaylistSize != 2*len(mood.Queries) {
+		t.Errorf("列表长度 = %d", r.PlaylistSize)
+	}
+
+	tags := cache.Tags("mock_102")
+	if len(tags) != 2 || tags[0] != "纯音乐" || tags[1] != "轻松" {
+		t.Errorf("mock_102 标签 = %v, want [纯音乐 轻松]", tags)
+	}
+
+	// mood 参数 + 歌手限定词
+	provider.queries = nil
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"keyword": "周杰伦", "mood": "伤感"}`))
+	if !strings.Contains(result, "伤感") || !strings.HasPrefix(provider.queries[0], "周杰伦 ") {
+		t.Errorf("结果 = %s, 搜索词 = %v", result, provider.queries)
+	}
+
+	// 缓存里带标签的歌足够多时直接离线播放
+	for i := int64(1); i <= moodCacheMin; i++ {
+		key := fmt.Sprintf("mock_%d", 900+i)
+		os.WriteFile(cache.FilePath(key), []byte("mp3"), 0644)
+		cache.Store(key, audio.CacheEntry{Name: fmt.Sprintf("歌%d", i), Provider: "mock", ProviderID: 900 + i})
+		cache.AddTags(key, []string{"助眠"}, "search")
+	}
+	provider.queries = nil
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"mood": "睡前"}`))
+	r = MusicResult{}
+	json.Unmarshal([]byte(result), &r)
+	if !r.Success || r.URL != "" || !strings.HasPrefix(r.CacheKey, "mock_90") || r.PlaylistSize != moodCacheMin {
+		t.Errorf("应从缓存播放: %s", result)
+	}
+	if len(provider.queries) != 0 {
+		t.Errorf("缓存足够时不应搜索: %v", provider.queries)
+	}
+}
+
+func TestPlayMusicTool_SleepAid(t *testing.T) {
+	tool := NewPlayMusicTool(MusicConfig{Provider: &queryProvider{}, Enabled: true})
+
+	// 没说放什么时默认放助眠音乐
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"sleep_aid": true, "sleep_minutes": 20}`))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var r MusicResult
+	json.Unmarshal([]byte(result), &r)
+	if !r.Success || !r.SleepAid || r.SleepMinutes != 20 || !strings.Contains(r.Message, "助眠") {
+		t.Errorf("结果 = %s", result)
+	}
+
+	r = MusicResult{}
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{"keyword": "晴天"}`))
+	json.Unmarshal([]byte(result), &r)
+	if r.SleepAid {
+		t.Errorf("未要求睡前模式: %s", result)
+	}
+}
+
+func TestInterleaveSongs(t *testing.T) {
+	a := []music.Song{{ID: 1}, {ID: 2}, {ID: 3}}
+	b := []music.Song{{ID: 2}, {ID: 4}}
+	got := interleaveSongs([][]music.Song{a, b}, 4)
+	want := []int64{1, 2, 4, 3}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i, s := range got {
+		if s.ID != want[i] {
+			t.Errorf("第 %d 首 ID = %d, want %d", i, s.ID, want[i])
+		}
+	}
+}
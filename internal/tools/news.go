@@ -7,18 +7,24 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // NewsTool 查询热点新闻。
 type NewsTool struct {
-	client *http.Client
+	client  *http.Client
+	session *NewsSession
 }
 
-func NewNewsTool() *NewsTool {
+// NewNewsTool 创建热点新闻工具，读取的标题会保存到 session 中供逐条导航。
+func NewNewsTool(session *NewsSession) *NewsTool {
 	return &NewsTool{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
+		session: session,
 	}
 }
 
@@ -90,21 +96,25 @@ func (t *NewsTool) Execute(ctx context.Context, args json.RawMessage) (string, e
 
 	// 取前 10 条有效新闻（跳过 articletype=560 的标题项）
 	result := "今日热搜新闻:\n"
-	count := 0
+	var entries []NewsEntry
 	for _, item := range newsList {
 		if item.ArticleType == "560" || item.Title == "" {
 			continue
 		}
-		count++
-		result += fmt.Sprintf("%d. %s\n", count, item.Title)
-		if count >= 10 {
+		entries = append(entries, NewsEntry{Source: "热榜", Title: item.Title})
+		result += fmt.Sprintf("%d. %s\n", len(entries), item.Title)
+		if len(entries) >= 10 {
 			break
 		}
 	}
 
-	if count == 0 {
+	if len(entries) == 0 {
 		return "暂时无法获取新闻，请稍后再试。", nil
 	}
 
+	if t.session != nil {
+		t.session.Set(entries, -1)
+		result += "（用户可以说\"详细说说第几条\"或\"下一条\"逐条了解）"
+	}
 	return result, nil
 }
@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// newsSessionTTL 新闻会话有效期，超时后"下一条"等导航不再生效。
+const newsSessionTTL = 30 * time.Minute
+
+// NewsEntry 新闻会话中的单条内容。
+type NewsEntry struct {
+	Source    string    // 来源（订阅源名称或"热榜"）
+	Title     string    // 标题
+	Summary   string    // 摘要
+	Content   string    // 详细内容，为空时使用摘要
+	Link      string    // 原文链接
+	Published time.Time // 发布时间
+}
+
+// NewsSession 保存最近一次读取的新闻列表和当前位置，
+// 支持"下一条"、"上一条"、"详细说说这条"等逐条导航，避免一次性播报所有内容。
+type NewsSession struct {
+	mu        sync.Mutex
+	entries   []NewsEntry
+	cursor    int // 当前条目下标，-1 表示刚读完标题列表、尚未开始逐条阅读
+	updatedAt time.Time
+}
+
+// NewNewsSession 创建新闻会话。
+func NewNewsSession() *NewsSession {
+	return &NewsSession{cursor: -1}
+}
+
+// Set 替换会话中的新闻列表。cursor 为初始位置（-1 表示从标题列表开始）。
+func (s *NewsSession) Set(entries []NewsEntry, cursor int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+	s.cursor = cursor
+	s.updatedAt = time.Now()
+}
+
+// Move 按 delta 移动当前位置并返回新位置的条目。
+// 越界时位置不变，返回 ok=false。
+func (s *NewsSession) Move(delta int) (entry NewsEntry, index, total int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.activeLocked() {
+		return NewsEntry{}, 0, 0, false
+	}
+	next := s.cursor + delta
+	if next < 0 || next >= len(s.entries) {
+		return NewsEntry{}, s.cursor, len(s.entries), false
+	}
+	s.cursor = next
+	s.updatedAt = time.Now()
+	return s.entries[next], next, len(s.entries), true
+}
+
+// Seek 跳转到指定下标（从 0 开始）的条目。
+// 会话无效时 total 为 0，下标越界时位置不变，返回 ok=false。
+func (s *NewsSession) Seek(index int) (entry NewsEntry, total int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.activeLocked() {
+		return NewsEntry{}, 0, false
+	}
+	if index < 0 || index >= len(s.entries) {
+		return NewsEntry{}, len(s.entries), false
+	}
+	s.cursor = index
+	s.updatedAt = time.Now()
+	return s.entries[index], len(s.entries), true
+}
+
+// Current 返回当前条目。刚读完标题列表时视为第一条。
+func (s *NewsSession) Current() (entry NewsEntry, index, total int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.activeLocked() {
+		return NewsEntry{}, 0, 0, false
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	s.updatedAt = time.Now()
+	return s.entries[s.cursor], s.cursor, len(s.entries), true
+}
+
+// activeLocked 判断会话是否有效，调用方需持有锁。
+func (s *NewsSession) activeLocked() bool {
+	return len(s.entries) > 0 && time.Since(s.updatedAt) < newsSessionTTL
+}
+
+// formatNewsEntry 格式化单条新闻，detail 为 true 时输出详细内容。
+func formatNewsEntry(e NewsEntry, index, total int, detail bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("第 %d/%d 条", index+1, total))
+	if e.Source != "" {
+		sb.WriteString(fmt.Sprintf(" [%s]", e.Source))
+	}
+	sb.WriteString(" " + e.Title)
+	if !e.Published.IsZero() {
+		sb.WriteString(fmt.Sprintf(" (%s)", e.Published.Format(time.DateOnly)))
+	}
+	sb.WriteString("\n")
+
+	body := e.Summary
+	if detail && e.Content != "" {
+		body = e.Content
+	}
+	if body != "" {
+		sb.WriteString(body + "\n")
+	} else if detail {
+		sb.WriteString("（该条只有标题，没有正文，请根据标题简要说明，不要编造细节）\n")
+	}
+	return sb.String()
+}
+
+// newsNavigationHint 提示 LLM 只播报当前条目，并告知用户可用的导航方式。
+func newsNavigationHint(index, total int) string {
+	if index+1 < total {
+		return fmt.Sprintf("（只播报这一条，然后告诉用户还有 %d 条，可以说\"下一条\"、\"上一条\"或\"详细说说这条\"）", total-index-1)
+	}
+	return "（这是最后一条，可以说\"上一条\"回看或\"详细说说这条\"）"
+}
+
+// ---- NavigateNewsTool ----
+
+// NavigateNewsTool 在最近读取的新闻/RSS 列表中逐条导航。
+type NavigateNewsTool struct {
+	session *NewsSession
+}
+
+// NewNavigateNewsTool 创建新闻导航工具。
+func NewNavigateNewsTool(session *NewsSession) *NavigateNewsTool {
+	return &NavigateNewsTool{session: session}
+}
+
+func (t *NavigateNewsTool) Name() string { return "navigate_news" }
+func (t *NavigateNewsTool) Description() string {
+	return "在刚才播报的新闻或 RSS 列表中逐条导航。当用户说'下一条'、'上一条'、'详细说说这条'、'再说一遍'、'第三条讲的什么'时使用。注意：切换歌曲的'下一首'不要用这个工具。"
+}
+func (t *NavigateNewsTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["next", "prev", "detail", "repeat"],
+				"description": "next: 下一条；prev: 上一条；detail: 详细说说当前条（或 index 指定的条目）；repeat: 重复当前条"
+			},
+			"index": {
+				"type": "integer",
+				"description": "条目序号（从 1 开始），用于'第三条'这类指定，可选"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *NavigateNewsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action string `json:"action"`
+		Index  int    `json:"index"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	var (
+		entry NewsEntry
+		index int
+		total int
+		ok    bool
+	)
+
+	if params.Index > 0 {
+		index = params.Index - 1
+		entry, total, ok = t.session.Seek(index)
+		if !ok {
+			if total == 0 {
+				return "现在没有正在阅读的新闻，可以先让我读读新闻或订阅内容。", nil
+			}
+			return fmt.Sprintf("只有 %d 条内容，没有第 %d 条。", total, params.Index), nil
+		}
+		return formatNewsEntry(entry, index, total, params.Action == "detail") + newsNavigationHint(index, total), nil
+	}
+
+	switch params.Action {
+	case "next":
+		entry, index, total, ok = t.session.Move(1)
+		if !ok && total > 0 {
+			return "已经是最后一条了。", nil
+		}
+	case "prev":
+		entry, index, total, ok = t.session.Move(-1)
+		if !ok && total > 0 {
+			return "已经是第一条了。", nil
+		}
+	case "detail", "repeat":
+		entry, index, total, ok = t.session.Current()
+	default:
+		return fmt.Sprintf("不支持的操作: %s", params.Action), nil
+	}
+
+	if !ok {
+		return "现在没有正在阅读的新闻，可以先让我读读新闻或订阅内容。", nil
+	}
+	return formatNewsEntry(entry, index, total, params.Action == "detail") + newsNavigationHint(index, total), nil
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestNewsTool_Name(t *testing.T) {
-	tool := NewNewsTool()
+	tool := NewNewsTool(NewNewsSession())
 	if tool.Name() != "get_news" {
 		t.Errorf("expected name 'get_news', got %q", tool.Name())
 	}
@@ -96,7 +96,7 @@ func TestNewsTool_EmptyResponse(t *testing.T) {
 }
 
 func TestNewsTool_Parameters(t *testing.T) {
-	tool := NewNewsTool()
+	tool := NewNewsTool(NewNewsSession())
 	params := tool.Parameters()
 	if !strings.Contains(string(params), "category") {
 		t.Errorf("parameters should mention category, got %s", string(params))
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OpenMicTool 开关连续聊天模式：开启后一段时间内不用说唤醒词，检测到说话就直接识别，
+// 适合做饭等腾不出手的时候。
+type OpenMicTool struct {
+	defaultMinutes int
+	maxMinutes     int
+	start          func(time.Duration)
+	stop           func() bool
+}
+
+// NewOpenMicTool 创建连续聊天模式工具。start 开启指定时长的连续聊天，stop 提前结束，未开启时返回 false。
+func NewOpenMicTool(defaultMinutes, maxMinutes int, start func(time.Duration), stop func() bool) *OpenMicTool {
+	return &OpenMicTool{defaultMinutes: defaultMinutes, maxMinutes: maxMinutes, start: start, stop: stop}
+}
+
+func (t *OpenMicTool) Name() string { return "set_open_mic" }
+
+func (t *OpenMicTool) Description() string {
+	return "开启或退出连续聊天模式（免唤醒词）：开启后一段时间内不用叫名字，直接说话即可。" +
+		"当用户说'开启连续聊天模式'、'接下来半小时不用叫你'、'我在做饭，直接跟你说话'、'退出连续聊天'时使用。"
+}
+
+func (t *OpenMicTool) Parameters() json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"enable": {
+				"type": "boolean",
+				"description": "true 开启，false 退出"
+			},
+			"minutes": {
+				"type": "integer",
+				"description": "持续多少分钟，默认 %d，最多 %d"
+			}
+		},
+		"required": ["enable"]
+	}`, t.defaultMinutes, t.maxMinutes))
+}
+
+func (t *OpenMicTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Enable  bool `json:"enable"`
+		Minutes int  `json:"minutes"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	if !a.Enable {
+		if !t.stop() {
+			return toJSON(map[string]interface{}{"success": true, "message": "现在没有开启连续聊天模式"}), nil
+		}
+		return toJSON(map[string]interface{}{"success": true, "message": "已退出连续聊天模式，需要时再叫我的名字"}), nil
+	}
+
+	minutes := a.Minutes
+	if minutes <= 0 {
+		minutes = t.defaultMinutes
+	}
+	if minutes > t.maxMinutes {
+		minutes = t.maxMinutes
+	}
+	t.start(time.Duration(minutes) * time.Minute)
+	return toJSON(map[string]interface{}{
+		"success": true,
+		"minutes": minutes,
+		"message": fmt.Sprintf("已开启连续聊天模式，接下来 %d 分钟不用叫我的名字，直接说话就行；说'退出连续聊天'可以提前结束", minutes),
+	}), nil
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenMicTool_Execute(t *testing.T) {
+	var started time.Duration
+	active := false
+	tool := NewOpenMicTool(10, 60,
+		func(d time.Duration) { started, active = d, true },
+		func() bool { was := active; active = false; return was })
+
+	tests := []struct {
+		args string
+		want time.Duration
+	}{
+		{`{"enable":true}`, 10 * time.Minute},
+		{`{"enable":true,"minutes":30}`, 30 * time.Minute},
+		{`{"enable":true,"minutes":600}`, 60 * time.Minute},
+	}
+	for _, tt := range tests {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(tt.args)); err != nil {
+			t.Fatalf("Execute(%s) failed: %v", tt.args, err)
+		}
+		if started != tt.want {
+			t.Errorf("Execute(%s) started %v, want %v", tt.args, started, tt.want)
+		}
+	}
+
+	out, _ := tool.Execute(context.Background(), json.RawMessage(`{"enable":false}`))
+	if active || !strings.Contains(out, "已退出") {
+		t.Errorf("disable = %s", out)
+	}
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"enable":false}`))
+	if !strings.Contains(out, "没有开启") {
+		t.Errorf("disable when inactive = %s", out)
+	}
+}
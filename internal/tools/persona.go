@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// Persona 可切换的人设。具体的 system prompt、发音人和回复详略由 Pipeline 负责应用。
+type Persona struct {
+	Name    string
+	Aliases []string // 其他叫法，如 "助教"、"老师"
+}
+
+// PersonaDefault 恢复默认人设时 switch_persona 使用的名称。
+const PersonaDefault = "default"
+
+// MatchPersona 按名称或别名查找人设：先精确匹配，再互相包含（"助教" 匹配 "助教模式"）。
+func MatchPersona(personas []Persona, name string) (Persona, bool) {
+	key := normalizePersonaName(name)
+	if key == "" {
+		return Persona{}, false
+	}
+	for _, p := range personas {
+		for _, n := range append([]string{p.Name}, p.Aliases...) {
+			if normalizePersonaName(n) == key {
+				return p, true
+			}
+		}
+	}
+	for _, p := range personas {
+		for _, n := range append([]string{p.Name}, p.Aliases...) {
+			if n := normalizePersonaName(n); n != "" && (strings.Contains(n, key) || strings.Contains(key, n)) {
+				return p, true
+			}
+		}
+	}
+	return Persona{}, false
+}
+
+// normalizePersonaName 去掉"模式"、"人设"等后缀和空格，便于匹配。
+func normalizePersonaName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	for _, suffix := range []string{"模式", "人设", "风格"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
+
+// isDefaultPersona 判断是否要求恢复默认人设。
+func isDefaultPersona(name string) bool {
+	switch normalizePersonaName(name) {
+	case "", PersonaDefault, "默认", "正常", "原来", "原来的", "普通":
+		return true
+	}
+	return false
+}
+
+// ---- SwitchPersonaTool ----
+
+// SwitchPersonaTool 切换人设，选择保存在设备设置中，重启后保留。
+type SwitchPersonaTool struct {
+	personas []Persona
+	settings *database.Settings
+	current  func() string     // 当前人设名称，默认人设为空
+	switchTo func(name string) // 立即切换，name 为空表示恢复默认
+}
+
+// NewSwitchPersonaTool 创建切换人设工具。
+func NewSwitchPersonaTool(personas []Persona, settings *database.Settings, current func() string, switchTo func(name string)) *SwitchPersonaTool {
+	return &SwitchPersonaTool{personas: personas, settings: settings, current: current, switchTo: switchTo}
+}
+
+func (t *SwitchPersonaTool) Name() string { return "switch_persona" }
+
+func (t *SwitchPersonaTool) Description() string {
+	names := make([]string, len(t.personas))
+	for i, p := range t.personas {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf(`切换助手的人设（说话风格、声音），如"切换到助教模式"、"换成段子手模式"、"恢复正常模式"、"你有哪些模式"。可用的人设：%s。
+只调整详略或语速用 set_reply_style。`, strings.Join(names, "、"))
+}
+
+func (t *SwitchPersonaTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["switch", "list"],
+				"description": "switch 切换人设，list 查看可用人设和当前人设"
+			},
+			"persona": {
+				"type": "string",
+				"description": "人设名称（switch 时必需），恢复默认填 default"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *SwitchPersonaTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action  string `json:"action"`
+		Persona string `json:"persona"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+
+	switch params.Action {
+	case "list":
+		return t.list(), nil
+	case "switch":
+		name := strings.TrimSpace(params.Persona)
+		if isDefaultPersona(name) {
+			t.apply("")
+			return "已恢复默认人设。", nil
+		}
+		persona, ok := MatchPersona(t.personas, name)
+		if !ok {
+			return fmt.Sprintf("没有「%s」这个人设。%s", name, t.list()), nil
+		}
+		t.apply(persona.Name)
+		return fmt.Sprintf("已切换到%s。", persona.Name), nil
+	default:
+		return "", fmt.Errorf("不支持的操作: %s", params.Action)
+	}
+}
+
+// apply 切换人设并保存到设备设置。
+func (t *SwitchPersonaTool) apply(name string) {
+	if t.switchTo != nil {
+		t.switchTo(name)
+	}
+	if t.settings == nil {
+		return
+	}
+	if err := t.settings.SetString(database.SettingPersona, name); err != nil {
+		logger.Warnf("[tools] 保存人设失败: %v", err)
+	}
+}
+
+// list 返回可用人设和当前人设。
+func (t *SwitchPersonaTool) list() string {
+	names := make([]string, len(t.personas))
+	for i, p := range t.personas {
+		names[i] = p.Name
+	}
+	current := "默认人设"
+	if t.current != nil {
+		if name := t.current(); name != "" {
+			current = name
+		}
+	}
+	return fmt.Sprintf("可用的人设：%s。当前是%s。", strings.Join(names, "、"), current)
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func TestSwitchPersonaTool(t *testing.T) {
+	settings := newTestSettings(t)
+	current := ""
+	tool := NewSwitchPersonaTool([]Persona{
+		{Name: "助教模式", Aliases: []string{"老师"}},
+		{Name: "段子手模式"},
+	}, settings, func() string { return current }, func(name string) { current = name })
+	ctx := context.Background()
+
+	tests := []struct {
+		persona string
+		want    string
+	}{
+		{"段子手", "段子手模式"},
+		{"老师", "助教模式"},
+		{"default", ""},
+		{"助教模式", "助教模式"},
+	}
+	for _, tt := range tests {
+		args, _ := json.Marshal(map[string]string{"action": "switch", "persona": tt.persona})
+		if _, err := tool.Execute(ctx, args); err != nil {
+			t.Fatalf("Execute(%q) failed: %v", tt.persona, err)
+		}
+		if current != tt.want {
+			t.Errorf("switch %q: current = %q, want %q", tt.persona, current, tt.want)
+		}
+	}
+	if got := settings.GetString(database.SettingPersona, ""); got != "助教模式" {
+		t.Errorf("saved persona = %q, want 助教模式", got)
+	}
+
+	out, _ := tool.Execute(ctx, json.RawMessage(`{"action":"switch","persona":"海盗"}`))
+	if !strings.Contains(out, "没有") || current != "助教模式" {
+		t.Errorf("unknown persona should not switch: %s", out)
+	}
+	out, _ = tool.Execute(ctx, json.RawMessage(`{"action":"list"}`))
+	if !strings.Contains(out, "段子手模式") || !strings.Contains(out, "当前是助教模式") {
+		t.Errorf("list = %s", out)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // PoetryClient 诗词 API 客户端。
@@ -20,7 +22,7 @@ type PoetryClient struct {
 // NewPoetryClient 创建诗词 API 客户端。
 func NewPoetryClient(apiKey string) *PoetryClient {
 	return &PoetryClient{
-		client:  &http.Client{Timeout: 10 * time.Second},
+		client:  &http.Client{Timeout: 10 * time.Second, Transport: ratelimit.NewTransport(nil)},
 		apiKey:  apiKey,
 		baseURL: "https://api.66mz8.com",
 	}
@@ -58,18 +60,22 @@ func (t *PoetryDailyTool) Parameters() json.RawMessage {
 
 // Execute 执行工具。
 func (t *PoetryDailyTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	return t.getDailyPoetry()
+	return t.getDailyPoetry(ctx)
 }
 
 // getDailyPoetry 获取每日一诗。
-func (t *PoetryDailyTool) getDailyPoetry() (string, error) {
+func (t *PoetryDailyTool) getDailyPoetry(ctx context.Context) (string, error) {
 	// 诗词六六六 API - 每日推荐
 	apiURL := fmt.Sprintf("%s/api/poetry/daily", t.client.baseURL)
 	if t.client.apiKey != "" {
 		apiURL += "?key=" + t.client.apiKey
 	}
 
-	resp, err := t.client.client.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return t.getFallbackPoetry(), nil
+	}
+	resp, err := t.client.client.Do(req)
 	if err != nil {
 		// API 失败时返回内置诗词
 		return t.getFallbackPoetry(), nil
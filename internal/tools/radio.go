@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RadioStation 一个网络电台：名称、MP3 直播流地址和别名。
+type RadioStation struct {
+	Name    string
+	URL     string
+	Aliases []string // 其他叫法，如 "新闻台"、"中国之声"
+}
+
+// PlayRadioStationTool 收听配置好的网络电台。电台是不会结束的直播流，播放交给 Pipeline，
+// 不缓存、不加入播放列表，电台停止推流后也不会自动播放下一首。
+type PlayRadioStationTool struct {
+	stations []RadioStation
+}
+
+// NewPlayRadioStationTool 创建收听电台工具。
+func NewPlayRadioStationTool(stations []RadioStation) *PlayRadioStationTool {
+	return &PlayRadioStationTool{stations: stations}
+}
+
+func (t *PlayRadioStationTool) Name() string { return "play_radio_station" }
+
+func (t *PlayRadioStationTool) Description() string {
+	names := make([]string, len(t.stations))
+	for i, s := range t.stations {
+		names[i] = s.Name
+	}
+	return fmt.Sprintf(`收听网络电台（直播），如"放新闻台"、"听一会儿爵士电台"。可收听的电台：%s。点播具体歌曲用 play_music。`,
+		strings.Join(names, "、"))
+}
+
+func (t *PlayRadioStationTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"station": {
+				"type": "string",
+				"description": "电台名称，只有一个电台或用户没说时留空"
+			}
+		}
+	}`)
+}
+
+func (t *PlayRadioStationTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Station string `json:"station"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+
+	station, ok := t.find(strings.TrimSpace(params.Station))
+	if !ok {
+		names := make([]string, len(t.stations))
+		for i, s := range t.stations {
+			names[i] = s.Name
+		}
+		return marshalResult(MusicResult{
+			Success: false,
+			Error:   fmt.Sprintf("没有找到电台「%s」，可以收听：%s", params.Station, strings.Join(names, "、")),
+		})
+	}
+	return marshalResult(MusicResult{
+		Success:  true,
+		SongName: station.Name,
+		Artist:   "网络电台",
+		URL:      station.URL,
+		Live:     true,
+	})
+}
+
+// find 按名称或别名查找电台：先精确匹配，再互相包含（"新闻台" 匹配 "中国之声新闻台"）。
+// 名称为空时使用第一个电台。
+func (t *PlayRadioStationTool) find(name string) (RadioStation, bool) {
+	if len(t.stations) == 0 {
+		return RadioStation{}, false
+	}
+	if name == "" {
+		return t.stations[0], true
+	}
+	key := normalizeStationName(name)
+	for _, s := range t.stations {
+		for _, n := range append([]string{s.Name}, s.Aliases...) {
+			if normalizeStationName(n) == key {
+				return s, true
+			}
+		}
+	}
+	for _, s := range t.stations {
+		for _, n := range append([]string{s.Name}, s.Aliases...) {
+			if n := normalizeStationName(n); n != "" && (strings.Contains(n, key) || strings.Contains(key, n)) {
+				return s, true
+			}
+		}
+	}
+	return RadioStation{}, false
+}
+
+// normalizeStationName 去掉"电台"、"频道"等后缀和空格，便于匹配。
+func normalizeStationName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	for _, suffix := range []string{"电台", "广播", "频道", "radio"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
+
+// NowPlaying 正在播放（或唤醒前正在播放）的内容。
+type NowPlaying struct {
+	Playing     bool
+	Song        string
+	Artist      string
+	Live        bool   // 网络电台
+	StreamTitle string // 电台推送的当前节目/歌曲名，可能为空
+}
+
+// NowPlayingTool 回答"现在在放什么"：歌曲名，或电台名和电台推送的当前节目。
+type NowPlayingTool struct {
+	status func() NowPlaying
+}
+
+// NewNowPlayingTool 创建查询正在播放内容的工具，status 返回当前播放状态。
+func NewNowPlayingTool(status func() NowPlaying) *NowPlayingTool {
+	return &NowPlayingTool{status: status}
+}
+
+func (t *NowPlayingTool) Name() string { return "get_now_playing" }
+
+func (t *NowPlayingTool) Description() string {
+	return `查询正在播放的歌曲或电台节目，如"现在在放什么"、"这是什么歌"、"电台在播什么节目"。`
+}
+
+func (t *NowPlayingTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{"type": "object", "properties": {}}`)
+}
+
+func (t *NowPlayingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	st := t.status()
+	if st.Song == "" {
+		return "现在没有在播放音乐或电台", nil
+	}
+	prefix := "正在播放"
+	if !st.Playing {
+		prefix = "刚才播放的是"
+	}
+	if !st.Live {
+		if st.Artist != "" {
+			return fmt.Sprintf("%s %s 的《%s》", prefix, st.Artist, st.Song), nil
+		}
+		return fmt.Sprintf("%s《%s》", prefix, st.Song), nil
+	}
+	if st.StreamTitle == "" {
+		return fmt.Sprintf("%s电台「%s」，电台没有提供当前节目的信息", prefix, st.Song), nil
+	}
+	return fmt.Sprintf("%s电台「%s」，当前节目：%s", prefix, st.Song, st.StreamTitle), nil
+}
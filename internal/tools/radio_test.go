@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPlayRadioStationTool(t *testing.T) {
+	tool := NewPlayRadioStationTool([]RadioStation{
+		{Name: "中国之声", URL: "http://radio.example.com/news.mp3", Aliases: []string{"新闻台"}},
+		{Name: "爵士电台", URL: "http://radio.example.com/jazz.mp3"},
+	})
+
+	tests := []struct {
+		station string
+		want    string
+	}{
+		{"", "中国之声"},
+		{"新闻台", "中国之声"},
+		{"爵士", "爵士电台"},
+		{"Jazz 爵士电台", "爵士电台"},
+	}
+	for _, tt := range tests {
+		args, _ := json.Marshal(map[string]string{"station": tt.station})
+		out, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatalf("Execute(%q) failed: %v", tt.station, err)
+		}
+		var result MusicResult
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatal(err)
+		}
+		if !result.Success || result.SongName != tt.want || !result.Live || result.URL == "" {
+			t.Errorf("Execute(%q) = %+v, want live station %s", tt.station, result, tt.want)
+		}
+	}
+
+	out, _ := tool.Execute(context.Background(), json.RawMessage(`{"station":"交通台"}`))
+	var result MusicResult
+	json.Unmarshal([]byte(out), &result)
+	if result.Success || !strings.Contains(result.Error, "爵士电台") {
+		t.Errorf("unknown station should fail and list the stations: %+v", result)
+	}
+}
+
+func TestNowPlayingTool(t *testing.T) {
+	status := NowPlaying{}
+	tool := NewNowPlayingTool(func() NowPlaying { return status })
+	ctx := context.Background()
+
+	if out, _ := tool.Execute(ctx, nil); !strings.Contains(out, "没有在播放") {
+		t.Errorf("idle: %s", out)
+	}
+
+	status = NowPlaying{Playing: true, Song: "中国之声", Live: true, StreamTitle: "新闻和报纸摘要"}
+	if out, _ := tool.Execute(ctx, nil); !strings.Contains(out, "新闻和报纸摘要") || !strings.Contains(out, "中国之声") {
+		t.Errorf("radio: %s", out)
+	}
+
+	status = NowPlaying{Song: "晴天", Artist: "周杰伦"}
+	if out, _ := tool.Execute(ctx, nil); !strings.Contains(out, "刚才") || !strings.Contains(out, "晴天") {
+		t.Errorf("interrupted song: %s", out)
+	}
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// rainPopThreshold 逐小时预报中降水概率达到多少（%）算会下雨。
+const rainPopThreshold = 50
+
+// RainForecast 短时降水预报。
+type RainForecast struct {
+	City    string `json:"city"`
+	Rain    bool   `json:"rain"`              // 时间范围内是否有降水
+	Snow    bool   `json:"snow,omitempty"`    // 降水是雪
+	Minutes int    `json:"minutes,omitempty"` // 多少分钟后开始（已经在下时为 0）
+	Summary string `json:"summary,omitempty"` // 和风天气的分钟级降水描述，如"95分钟后雨就停了"
+}
+
+// qweatherMinutelyResp 和风天气分钟级降水响应（未来 2 小时，每 5 分钟一条）。
+type qweatherMinutelyResp struct {
+	Code     string `json:"code"`
+	Summary  string `json:"summary"`
+	Minutely []struct {
+		FxTime string `json:"fxTime"`
+		Precip string `json:"precip"`
+		Type   string `json:"type"` // rain 或 snow
+	} `json:"minutely"`
+}
+
+// qweatherHourlyResp 和风天气逐小时预报响应。
+type qweatherHourlyResp struct {
+	Code   string `json:"code"`
+	Hourly []struct {
+		FxTime string `json:"fxTime"`
+		Text   string `json:"text"`
+		Pop    string `json:"pop"`
+		Precip string `json:"precip"`
+	} `json:"hourly"`
+}
+
+// RainWithin 查询城市在 within 时间内会不会下雨。优先使用分钟级降水（需要城市经纬度），
+// 不可用时退回逐小时预报的降水概率。
+func (t *WeatherTool) RainWithin(ctx context.Context, city string, within time.Duration) (*RainForecast, error) {
+	info, err := t.lookupCity(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	if info.Latitude != "" && info.Longitude != "" {
+		f, err := t.minutelyRain(ctx, info, within)
+		if err == nil {
+			return f, nil
+		}
+		logger.Debugf("[tools] 分钟级降水查询失败，改用逐小时预报: %v", err)
+	}
+	return t.hourlyRain(ctx, info, within)
+}
+
+func (t *WeatherTool) minutelyRain(ctx context.Context, info *cityInfo, within time.Duration) (*RainForecast, error) {
+	u := fmt.Sprintf("https://%s/v7/minutely/5m?location=%s,%s", t.apiHost, info.Longitude, info.Latitude)
+	body, err := t.doGet(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("分钟级降水查询失败: %w", err)
+	}
+	var resp qweatherMinutelyResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析降水数据失败: %w", err)
+	}
+	if resp.Code != "200" {
+		return nil, fmt.Errorf("降水API错误 code=%s", resp.Code)
+	}
+
+	f := &RainForecast{City: info.Name, Summary: resp.Summary}
+	now := time.Now()
+	for _, m := range resp.Minutely {
+		at, err := time.Parse("2006-01-02T15:04-07:00", m.FxTime)
+		if err != nil || at.Sub(now) > within {
+			continue
+		}
+		if precip, _ := strconv.ParseFloat(m.Precip, 64); precip > 0 {
+			f.Rain, f.Snow = true, m.Type == "snow"
+			f.Minutes = rainMinutes(at, now)
+			break
+		}
+	}
+	return f, nil
+}
+
+func (t *WeatherTool) hourlyRain(ctx context.Context, info *cityInfo, within time.Duration) (*RainForecast, error) {
+	u := fmt.Sprintf("https://%s/v7/weather/24h?location=%s", t.apiHost, info.ID)
+	body, err := t.doGet(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("逐小时预报查询失败: %w", err)
+	}
+	var resp qweatherHourlyResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析逐小时预报失败: %w", err)
+	}
+	if resp.Code != "200" {
+		return nil, fmt.Errorf("逐小时预报API错误 code=%s", resp.Code)
+	}
+
+	f := &RainForecast{City: info.Name}
+	now := time.Now()
+	for _, h := range resp.Hourly {
+		at, err := time.Parse("2006-01-02T15:04-07:00", h.FxTime)
+		if err != nil || at.Sub(now) > within {
+			continue
+		}
+		pop, _ := strconv.Atoi(h.Pop)
+		precip, _ := strconv.ParseFloat(h.Precip, 64)
+		if pop >= rainPopThreshold || precip > 0 || strings.Contains(h.Text, "雨") || strings.Contains(h.Text, "雪") {
+			f.Rain, f.Snow = true, strings.Contains(h.Text, "雪")
+			f.Minutes = rainMinutes(at, now)
+			break
+		}
+	}
+	return f, nil
+}
+
+// rainMinutes 距离开始降水还有多少分钟，已经开始时为 0。
+func rainMinutes(at, now time.Time) int {
+	if d := at.Sub(now); d > 0 {
+		return int(d.Minutes())
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRainTestTool(t *testing.T, geo string, minutely, hourly func() string) *WeatherTool {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/v2/city/lookup", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, geo)
+	})
+	mux.HandleFunc("/v7/minutely/5m", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("location") != "116.41,39.92" {
+			t.Errorf("minutely location = %q, want lon,lat", r.URL.Query().Get("location"))
+		}
+		fmt.Fprint(w, minutely())
+	})
+	mux.HandleFunc("/v7/weather/24h", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, hourly())
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return &WeatherTool{apiKey: "testkey", apiHost: strings.TrimPrefix(server.URL, "https://"), client: server.Client()}
+}
+
+func fxTime(d time.Duration) string {
+	return time.Now().Add(d).Format("2006-01-02T15:04-07:00")
+}
+
+func TestRainWithin_Minutely(t *testing.T) {
+	geo := `{"code":"200","location":[{"name":"北京","id":"101010100","lat":"39.92","lon":"116.41"}]}`
+	minutely := func() string {
+		return fmt.Sprintf(`{"code":"200","summary":"40分钟后开始下雨","minutely":[
+			{"fxTime":"%s","precip":"0.00","type":"rain"},
+			{"fxTime":"%s","precip":"0.12","type":"rain"}]}`, fxTime(5*time.Minute), fxTime(41*time.Minute))
+	}
+	tool := newRainTestTool(t, geo, minutely, func() string { return `{"code":"500"}` })
+
+	f, err := tool.RainWithin(context.Background(), "北京", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("RainWithin() failed: %v", err)
+	}
+	if !f.Rain || f.Snow || f.Minutes < 39 || f.Minutes > 41 || f.Summary != "40分钟后开始下雨" {
+		t.Errorf("RainWithin() = %+v", f)
+	}
+
+	// 降水在时间范围之外不提醒
+	f, err = tool.RainWithin(context.Background(), "北京", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("RainWithin() failed: %v", err)
+	}
+	if f.Rain {
+		t.Errorf("rain after the window should be ignored: %+v", f)
+	}
+}
+
+func TestRainWithin_HourlyFallback(t *testing.T) {
+	geo := `{"code":"200","location":[{"name":"杭州","id":"101210101"}]}`
+	hourly := func() string {
+		return fmt.Sprintf(`{"code":"200","hourly":[
+			{"fxTime":"%s","text":"阴","pop":"20","precip":"0.0"},
+			{"fxTime":"%s","text":"小雨","pop":"70","precip":"0.5"}]}`, fxTime(30*time.Minute), fxTime(90*time.Minute))
+	}
+	tool := newRainTestTool(t, geo, func() string { return `{"code":"500"}` }, hourly)
+
+	f, err := tool.RainWithin(context.Background(), "杭州", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("RainWithin() failed: %v", err)
+	}
+	if !f.Rain || f.City != "杭州" || f.Minutes < 88 {
+		t.Errorf("RainWithin() = %+v", f)
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/iabetor/pibuddy/internal/logger"
+	"sort"
+	"time"
 
 	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
 )
 
+// DefaultToolTimeout 工具执行的默认超时。
+const DefaultToolTimeout = 30 * time.Second
+
 // Tool 定义工具接口，每个工具必须自描述。
 type Tool interface {
 	Name() string
@@ -17,34 +22,104 @@ type Tool interface {
 	Execute(ctx context.Context, args json.RawMessage) (string, error)
 }
 
+// TimeoutTool 执行时间较长的工具（如录音）可实现此接口，覆盖注册表的默认超时。
+type TimeoutTool interface {
+	Timeout() time.Duration
+}
+
+// LocalOnlyTool 结果含有不能发给云端大模型的信息（如 Wi-Fi 密码）的工具可实现此接口。
+// 启用隐私过滤时结果不经过大模型，由 LocalReply 在本地整理成要朗读的文字。
+type LocalOnlyTool interface {
+	LocalReply(result string) string
+}
+
+// MockableTool 模拟模式下返回更贴切的结果（如"门锁已远程开锁"）的工具可实现此接口，
+// 未实现时返回通用的成功结果。
+type MockableTool interface {
+	MockResult(args json.RawMessage) string
+}
+
+// mockSuccess 模拟模式下的通用成功结果。
+const mockSuccess = `{"success":true,"message":"操作成功"}`
+
 // Registry 管理所有已注册工具。
 type Registry struct {
-	tools map[string]Tool
+	tools    map[string]Tool
+	timeout  time.Duration
+	grouping *toolGrouping   // 按意图分组暴露工具，未开启时为 nil
+	mock     map[string]bool // 模拟模式的工具：不真正执行，直接返回成功
 }
 
 // NewRegistry 创建工具注册表。
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:   make(map[string]Tool),
+		timeout: DefaultToolTimeout,
 	}
 }
 
+// SetTimeout 设置工具执行的默认超时，0 表示不限制（仍随 ctx 取消）。
+func (r *Registry) SetTimeout(d time.Duration) {
+	r.timeout = d
+}
+
 // Register 注册一个工具。
 func (r *Registry) Register(t Tool) {
 	r.tools[t.Name()] = t
 	logger.Infof("[tools] 已注册工具: %s", t.Name())
 }
 
+// SetMock 把指定工具切换为模拟模式（演示、开发时使用）：不真正执行、不产生实际操作，直接返回成功。
+// 需要在工具注册之后调用，未注册的工具名称会被忽略并记录警告。
+func (r *Registry) SetMock(names []string) {
+	r.mock = make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := r.tools[name]; !ok {
+			logger.Warnf("[tools] 模拟模式: 工具 %s 未注册，已忽略", name)
+			continue
+		}
+		r.mock[name] = true
+	}
+	if len(r.mock) > 0 {
+		mocked := make([]string, 0, len(r.mock))
+		for name := range r.mock {
+			mocked = append(mocked, name)
+		}
+		sort.Strings(mocked)
+		logger.Warnf("[tools] 以下工具处于模拟模式，不会真正执行: %v", mocked)
+	}
+}
+
 // Get 获取指定名称的工具。
 func (r *Registry) Get(name string) (Tool, bool) {
 	t, ok := r.tools[name]
 	return t, ok
 }
 
+// LocalOnly 返回实现了 LocalOnlyTool 的工具名称。
+func (r *Registry) LocalOnly() []string {
+	var names []string
+	for name, t := range r.tools {
+		if _, ok := t.(LocalOnlyTool); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Definitions 返回所有工具的定义，用于发送给 LLM。
 func (r *Registry) Definitions() []llm.ToolDefinition {
+	return r.definitions(func(string) bool { return true })
+}
+
+// definitions 返回 include 为 true 的工具定义。
+func (r *Registry) definitions(include func(name string) bool) []llm.ToolDefinition {
 	defs := make([]llm.ToolDefinition, 0, len(r.tools))
 	for _, t := range r.tools {
+		if !include(t.Name()) {
+			continue
+		}
 		defs = append(defs, llm.ToolDefinition{
 			Type: "function",
 			Function: llm.FunctionDefinition{
@@ -57,20 +132,73 @@ func (r *Registry) Definitions() []llm.ToolDefinition {
 	return defs
 }
 
+// toolResult 工具在后台执行的结果。
+type toolResult struct {
+	result string
+	err    error
+}
+
 // Execute 执行指定工具并返回结果。
+// 工具在超时或 ctx 取消（如用户打断）时立即返回错误，即使工具本身没有处理 ctx；
+// 之后才返回的结果会被丢弃，不会带入已经结束的对话。
 func (r *Registry) Execute(ctx context.Context, name string, args json.RawMessage) (string, error) {
 	t, ok := r.tools[name]
 	if !ok {
 		return "", fmt.Errorf("未知工具: %s", name)
 	}
+	if r.mock[name] {
+		logger.Infof("[tools] 模拟执行工具: %s, 参数: %s", name, string(args))
+		if m, ok := t.(MockableTool); ok {
+			return m.MockResult(args), nil
+		}
+		return mockSuccess, nil
+	}
 	logger.Debugf("[tools] 执行工具: %s, 参数: %s", name, string(args))
-	result, err := t.Execute(ctx, args)
-	if err != nil {
-		logger.Errorf("[tools] 工具 %s 执行失败: %v", name, err)
-		return "", err
+
+	timeout := r.timeout
+	if tt, ok := t.(TimeoutTool); ok {
+		timeout = tt.Timeout()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan toolResult, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- toolResult{err: fmt.Errorf("工具 %s 异常: %v", name, rec)}
+			}
+		}()
+		result, err := t.Execute(ctx, args)
+		done <- toolResult{result, err}
+	}()
+
+	var res toolResult
+	select {
+	case res = <-done:
+		if ctx.Err() != nil {
+			res = toolResult{err: ctx.Err()}
+		}
+	case <-ctx.Done():
+		go func() {
+			<-done
+			logger.Debugf("[tools] 工具 %s 在取消后才返回，结果已丢弃", name)
+		}()
+		res = toolResult{err: ctx.Err()}
+	}
+
+	if res.err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			res.err = fmt.Errorf("工具 %s 执行超时: %w", name, res.err)
+		}
+		logger.Errorf("[tools] 工具 %s 执行失败: %v", name, res.err)
+		return "", res.err
 	}
 	logger.Debugf("[tools] 工具 %s 执行成功", name)
-	return result, nil
+	return res.result, nil
 }
 
 // Count 返回已注册工具数量。
@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestRegistry_RegisterAndGet(t *testing.T) {
@@ -73,3 +75,76 @@ func TestRegistry_ExecuteUnknown(t *testing.T) {
 		t.Error("expected error for unknown tool")
 	}
 }
+
+// blockingTool 忽略 ctx、一直阻塞到 release 关闭的工具。
+type blockingTool struct {
+	release chan struct{}
+}
+
+func (t *blockingTool) Name() string                { return "blocking" }
+func (t *blockingTool) Description() string         { return "阻塞测试工具" }
+func (t *blockingTool) Parameters() json.RawMessage { return json.RawMessage(`{}`) }
+func (t *blockingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	<-t.release
+	return "迟到的结果", nil
+}
+
+func TestRegistry_ExecuteCancel(t *testing.T) {
+	reg := NewRegistry()
+	tool := &blockingTool{release: make(chan struct{})}
+	defer close(tool.release)
+	reg.Register(tool)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	result, err := reg.Execute(ctx, "blocking", json.RawMessage(`{}`))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if result != "" {
+		t.Errorf("取消后不应返回结果, got %q", result)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("取消后应立即返回")
+	}
+}
+
+func TestRegistry_ExecuteTimeout(t *testing.T) {
+	reg := NewRegistry()
+	reg.SetTimeout(20 * time.Millisecond)
+	tool := &blockingTool{release: make(chan struct{})}
+	defer close(tool.release)
+	reg.Register(tool)
+
+	_, err := reg.Execute(context.Background(), "blocking", json.RawMessage(`{}`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRegistry_Mock(t *testing.T) {
+	reg := NewRegistry()
+	blocking := &blockingTool{release: make(chan struct{})}
+	reg.Register(blocking)
+	reg.Register(NewEzvizOpenDoorTool(nil, "lock-1"))
+	reg.SetMock([]string{"blocking", "ezviz_open_door", "not_registered"})
+
+	// 模拟模式下不调用真正的工具，直接返回成功
+	result, err := reg.Execute(context.Background(), "blocking", json.RawMessage(`{}`))
+	if err != nil || result != mockSuccess {
+		t.Errorf("result = %q, err = %v", result, err)
+	}
+
+	// 实现了 MockableTool 的工具返回自己的模拟结果，不访问萤石云
+	result, err = reg.Execute(context.Background(), "ezviz_open_door", json.RawMessage(`{"confirm":true}`))
+	if err != nil || result != "门锁已远程开锁。" {
+		t.Errorf("result = %q, err = %v", result, err)
+	}
+	if reg.mock["not_registered"] {
+		t.Error("unregistered tools should be ignored")
+	}
+}
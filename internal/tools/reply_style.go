@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 语速档位对应的倍率。
+var speechRates = map[string]float64{
+	"slow":   0.8,
+	"normal": 1.0,
+	"fast":   1.2,
+}
+
+var verbosityNames = map[string]string{
+	llm.VerbosityBrief:    "简洁",
+	llm.VerbosityNormal:   "正常",
+	llm.VerbosityDetailed: "详细",
+}
+
+var speechRateNames = map[string]string{
+	"slow":   "慢一些",
+	"normal": "正常",
+	"fast":   "快一些",
+}
+
+// ---- ReplyStyleTool 回复风格（详略、语速） ----
+
+// ReplyStyleTool 调整回复的详略程度和语速，设置保存在设备设置中，重启后保留。
+type ReplyStyleTool struct {
+	settings      *database.Settings
+	setVerbosity  func(string)
+	setSpeechRate func(float64)
+}
+
+// NewReplyStyleTool 创建回复风格工具。setVerbosity/setSpeechRate 负责让设置立即生效。
+func NewReplyStyleTool(settings *database.Settings, setVerbosity func(string), setSpeechRate func(float64)) *ReplyStyleTool {
+	return &ReplyStyleTool{settings: settings, setVerbosity: setVerbosity, setSpeechRate: setSpeechRate}
+}
+
+func (t *ReplyStyleTool) Name() string { return "set_reply_style" }
+func (t *ReplyStyleTool) Description() string {
+	return "调整回复的详略和语速。当用户说'说简单点'、'回答详细一些'、'说慢一点'、'说话快一点'、'恢复正常语速'时使用。"
+}
+func (t *ReplyStyleTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"verbosity": {
+				"type": "string",
+				"enum": ["brief", "normal", "detailed"],
+				"description": "回复详略：brief 简洁，normal 正常，detailed 详细（不调整则不传）"
+			},
+			"speech_rate": {
+				"type": "string",
+				"enum": ["slow", "normal", "fast"],
+				"description": "语速：slow 慢，normal 正常，fast 快（不调整则不传）"
+			}
+		}
+	}`)
+}
+
+func (t *ReplyStyleTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Verbosity  string `json:"verbosity"`
+		SpeechRate string `json:"speech_rate"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	if params.Verbosity == "" && params.SpeechRate == "" {
+		return "请告诉我要调整详略还是语速。", nil
+	}
+
+	var changed []string
+	if params.Verbosity != "" {
+		if !llm.ValidVerbosity(params.Verbosity) {
+			return fmt.Sprintf("不支持的详略程度: %s", params.Verbosity), nil
+		}
+		if t.setVerbosity != nil {
+			t.setVerbosity(params.Verbosity)
+		}
+		if err := t.settings.SetString(database.SettingVerbosity, params.Verbosity); err != nil {
+			logger.Warnf("[tools] 保存回复详略失败: %v", err)
+		}
+		changed = append(changed, "已把回复改为"+verbosityNames[params.Verbosity])
+	}
+	if params.SpeechRate != "" {
+		rate, ok := speechRates[params.SpeechRate]
+		if !ok {
+			return fmt.Sprintf("不支持的语速: %s", params.SpeechRate), nil
+		}
+		if t.setSpeechRate == nil {
+			changed = append(changed, "当前语音引擎不支持调整语速")
+		} else {
+			t.setSpeechRate(rate)
+			if err := t.settings.SetFloat(database.SettingSpeechRate, rate); err != nil {
+				logger.Warnf("[tools] 保存语速失败: %v", err)
+			}
+			changed = append(changed, "已把语速改为"+speechRateNames[params.SpeechRate])
+		}
+	}
+	return strings.Join(changed, "，") + "。", nil
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+func newTestSettings(t *testing.T) *database.Settings {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return database.NewSettings(db)
+}
+
+func TestReplyStyleTool_Execute(t *testing.T) {
+	settings := newTestSettings(t)
+	var verbosity string
+	var rate float64
+	tool := NewReplyStyleTool(settings, func(v string) { verbosity = v }, func(r float64) { rate = r })
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"verbosity":"brief","speech_rate":"slow"}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if !strings.Contains(result, "简洁") || !strings.Contains(result, "慢一些") {
+		t.Errorf("结果应说明调整内容: %s", result)
+	}
+	if verbosity != llm.VerbosityBrief || rate != 0.8 {
+		t.Errorf("设置未生效: verbosity=%s rate=%v", verbosity, rate)
+	}
+	if got := settings.GetString(database.SettingVerbosity, ""); got != llm.VerbosityBrief {
+		t.Errorf("详略未保存: %s", got)
+	}
+	if got := settings.GetFloat(database.SettingSpeechRate, 0); got != 0.8 {
+		t.Errorf("语速未保存: %v", got)
+	}
+
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{"verbosity":"chatty"}`))
+	if !strings.Contains(result, "不支持") {
+		t.Errorf("无效参数应提示不支持: %s", result)
+	}
+}
+
+func TestReplyStyleTool_NoRateSupport(t *testing.T) {
+	settings := newTestSettings(t)
+	tool := NewReplyStyleTool(settings, nil, nil)
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"speech_rate":"fast"}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if !strings.Contains(result, "不支持调整语速") {
+		t.Errorf("引擎不支持时应提示: %s", result)
+	}
+	if settings.Has(database.SettingSpeechRate) {
+		t.Error("不支持时不应保存语速")
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"github.com/iabetor/pibuddy/internal/rss"
 )
 
@@ -111,6 +116,9 @@ func (t *ListRSSFeedsTool) Execute(ctx context.Context, args json.RawMessage) (s
 		if !f.LastFetched.IsZero() {
 			sb.WriteString(fmt.Sprintf(" [上次更新: %s]", f.LastFetched.Format("01-02 15:04")))
 		}
+		if f.Muted {
+			sb.WriteString(" [已静音]")
+		}
 		sb.WriteString("\n")
 	}
 	return sb.String(), nil
@@ -165,19 +173,21 @@ func (t *DeleteRSSFeedTool) Execute(ctx context.Context, args json.RawMessage) (
 // ---- GetRSSNewsTool ----
 
 // GetRSSNewsTool 获取 RSS 最新内容。
+// 内容保存到 session 中，每次只播报一条，用户可以通过 navigate_news 逐条收听。
 type GetRSSNewsTool struct {
 	store   *rss.FeedStore
 	fetcher *rss.Fetcher
+	session *NewsSession
 }
 
 // NewGetRSSNewsTool 创建获取 RSS 内容工具。
-func NewGetRSSNewsTool(store *rss.FeedStore, fetcher *rss.Fetcher) *GetRSSNewsTool {
-	return &GetRSSNewsTool{store: store, fetcher: fetcher}
+func NewGetRSSNewsTool(store *rss.FeedStore, fetcher *rss.Fetcher, session *NewsSession) *GetRSSNewsTool {
+	return &GetRSSNewsTool{store: store, fetcher: fetcher, session: session}
 }
 
 func (t *GetRSSNewsTool) Name() string { return "get_rss_news" }
 func (t *GetRSSNewsTool) Description() string {
-	return "获取 RSS 订阅源的最新内容。当用户说'有什么新消息'、'看看RSS'、'读读订阅'等时使用。支持按来源和关键词过滤。"
+	return "获取 RSS 订阅源的最新内容。当用户说'有什么新消息'、'看看RSS'、'读读订阅'等时使用。支持按来源和关键词过滤。每次只返回第一条，后续用 navigate_news 逐条导航。"
 }
 func (t *GetRSSNewsTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -228,17 +238,256 @@ func (t *GetRSSNewsTool) Execute(ctx context.Context, args json.RawMessage) (str
 		return msg, nil
 	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("最新 %d 条内容:\n", len(items)))
+	entries := make([]NewsEntry, len(items))
 	for i, item := range items {
-		sb.WriteString(fmt.Sprintf("%d. [%s] %s", i+1, item.FeedName, item.Title))
-		if !item.Published.IsZero() {
-			sb.WriteString(fmt.Sprintf(" (%s)", item.Published.Format(time.DateOnly)))
+		entries[i] = NewsEntry{
+			Source:    item.FeedName,
+			Title:     item.Title,
+			Summary:   item.Summary,
+			Content:   item.Content,
+			Link:      item.Link,
+			Published: item.Published,
 		}
-		sb.WriteString("\n")
-		if item.Summary != "" {
-			sb.WriteString(fmt.Sprintf("   %s\n", item.Summary))
+	}
+	result := fmt.Sprintf("共找到 %d 条内容。\n", len(entries)) + formatNewsEntry(entries[0], 0, len(entries), false)
+	if t.session != nil {
+		t.session.Set(entries, 0)
+		result += newsNavigationHint(0, len(entries))
+	}
+	return result, nil
+}
+
+// ---- RSSOPMLTool ----
+
+// RSSOPMLTool 通过 OPML 批量导入/导出 RSS 订阅源。
+type RSSOPMLTool struct {
+	store   *rss.FeedStore
+	dataDir string
+	client  *http.Client
+}
+
+// NewRSSOPMLTool 创建 OPML 导入导出工具。
+func NewRSSOPMLTool(store *rss.FeedStore, dataDir string) *RSSOPMLTool {
+	return &RSSOPMLTool{
+		store:   store,
+		dataDir: dataDir,
+		client:  &http.Client{Timeout: 15 * time.Second, Transport: ratelimit.NewTransport(nil)},
+	}
+}
+
+func (t *RSSOPMLTool) Name() string { return "rss_opml" }
+func (t *RSSOPMLTool) Description() string {
+	return "通过 OPML 文件批量导入或导出 RSS 订阅源。当用户说'从某某地址导入订阅'、'导出我的订阅列表'时使用。导入需要提供 OPML 文件的 URL，或数据目录下的文件名。"
+}
+func (t *RSSOPMLTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["import", "export"],
+				"description": "import: 导入；export: 导出到数据目录"
+			},
+			"source": {
+				"type": "string",
+				"description": "OPML 文件的 URL 或数据目录下的文件名（import 时必需）"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *RSSOPMLTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action string `json:"action"`
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	switch params.Action {
+	case "import":
+		if params.Source == "" {
+			return "请提供 OPML 文件的地址。", nil
+		}
+		return t.importOPML(ctx, params.Source)
+	case "export":
+		path := filepath.Join(t.dataDir, "rss_feeds.opml")
+		f, err := os.Create(path)
+		if err != nil {
+			return "", fmt.Errorf("创建 OPML 文件失败: %w", err)
 		}
+		defer f.Close()
+		if err := t.store.ExportOPML(f); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("已导出 %d 个订阅源到 %s", len(t.store.List()), path), nil
+	default:
+		return fmt.Sprintf("不支持的操作: %s", params.Action), nil
 	}
-	return sb.String(), nil
+}
+
+// importOPML 从 URL 或本地文件导入。
+func (t *RSSOPMLTool) importOPML(ctx context.Context, source string) (string, error) {
+	var r io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return "", fmt.Errorf("创建请求失败: %w", err)
+		}
+		resp, err := t.client.Do(req)
+		if err != nil {
+			return fmt.Sprintf("下载 OPML 失败: %v", err), nil
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Sprintf("下载 OPML 失败: HTTP %d", resp.StatusCode), nil
+		}
+		r = resp.Body
+	} else {
+		path, ok := t.localOPMLPath(source)
+		if !ok {
+			return "只能导入数据目录下的 OPML 文件，其他位置的文件请用 URL 或 pibuddy-rss import 导入。", nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Sprintf("无法打开文件 %s", source), nil
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := t.store.ImportOPML(r)
+	if err != nil {
+		return fmt.Sprintf("导入失败: %v", err), nil
+	}
+	return formatImportResult(result), nil
+}
+
+// localOPMLPath 把本地 OPML 路径解析到数据目录下，相对路径相对于数据目录；
+// 路径不在数据目录内（包括经符号链接指向目录外）时 ok 为 false，避免大模型读取任意文件。
+func (t *RSSOPMLTool) localOPMLPath(source string) (string, bool) {
+	dir, err := filepath.Abs(t.dataDir)
+	if err != nil {
+		return "", false
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", false
+	}
+	path := source
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if os.IsNotExist(err) {
+		// 文件不存在时照常返回，由调用方报告打不开
+		path = filepath.Clean(path)
+	} else {
+		return "", false
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return path, true
+}
+
+// formatImportResult 格式化导入结果。
+func formatImportResult(result *rss.ImportResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("导入完成，新增 %d 个订阅源", len(result.Added)))
+	if len(result.Skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("，%d 个已存在已跳过", len(result.Skipped)))
+	}
+	if len(result.Added) > 0 {
+		sb.WriteString(": " + strings.Join(result.Added, "、"))
+	}
+	return sb.String()
+}
+
+// ---- RSSFeedSettingsTool ----
+
+// RSSFeedSettingsTool 设置单个订阅源的播报条数、摘要长度和静音。
+type RSSFeedSettingsTool struct {
+	store *rss.FeedStore
+}
+
+// NewRSSFeedSettingsTool 创建订阅源设置工具。
+func NewRSSFeedSettingsTool(store *rss.FeedStore) *RSSFeedSettingsTool {
+	return &RSSFeedSettingsTool{store: store}
+}
+
+func (t *RSSFeedSettingsTool) Name() string { return "rss_feed_settings" }
+func (t *RSSFeedSettingsTool) Description() string {
+	return "设置某个 RSS 订阅源的播报方式。当用户说'某某只读三条'、'某某摘要说短一点'、'先别读某某了'、'恢复某某'时使用。"
+}
+func (t *RSSFeedSettingsTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"feed": {
+				"type": "string",
+				"description": "订阅源名称或 ID"
+			},
+			"max_items": {
+				"type": "integer",
+				"description": "每次最多读取的条目数，0 表示不限制"
+			},
+			"summary_len": {
+				"type": "integer",
+				"description": "摘要最大字数，0 表示使用默认长度"
+			},
+			"muted": {
+				"type": "boolean",
+				"description": "是否静音，静音后不参与'有什么新消息'，只在点名时读取"
+			}
+		},
+		"required": ["feed"]
+	}`)
+}
+
+func (t *RSSFeedSettingsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Feed       string `json:"feed"`
+		MaxItems   *int   `json:"max_items"`
+		SummaryLen *int   `json:"summary_len"`
+		Muted      *bool  `json:"muted"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+	if params.Feed == "" {
+		return "", fmt.Errorf("缺少 feed 参数")
+	}
+
+	feed, err := t.store.UpdateSettings(params.Feed, rss.FeedSettings{
+		MaxItems:   params.MaxItems,
+		SummaryLen: params.SummaryLen,
+		Muted:      params.Muted,
+	})
+	if err != nil {
+		return err.Error(), nil
+	}
+	return fmt.Sprintf("已更新 %s: %s", feed.Name, describeFeedSettings(*feed)), nil
+}
+
+// describeFeedSettings 描述订阅源的当前设置。
+func describeFeedSettings(f rss.Feed) string {
+	var parts []string
+	if f.MaxItems > 0 {
+		parts = append(parts, fmt.Sprintf("每次最多 %d 条", f.MaxItems))
+	} else {
+		parts = append(parts, "条数不限")
+	}
+	if f.SummaryLen > 0 {
+		parts = append(parts, fmt.Sprintf("摘要 %d 字以内", f.SummaryLen))
+	} else {
+		parts = append(parts, "默认摘要长度")
+	}
+	if f.Muted {
+		parts = append(parts, "已静音")
+	}
+	return strings.Join(parts, "，")
 }
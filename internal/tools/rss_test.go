@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/iabetor/pibuddy/internal/rss"
 )
@@ -58,7 +61,7 @@ func setupRSSTools(t *testing.T) (
 	return NewAddRSSFeedTool(store, fetcher),
 		NewListRSSFeedsTool(store),
 		NewDeleteRSSFeedTool(store),
-		NewGetRSSNewsTool(store, fetcher),
+		NewGetRSSNewsTool(store, fetcher, NewNewsSession()),
 		srv
 }
 
@@ -198,6 +201,28 @@ func TestGetRSSNewsTool(t *testing.T) {
 	}
 }
 
+func TestGetRSSNewsToolNilSession(t *testing.T) {
+	srv := setupRSSServer()
+	defer srv.Close()
+	dir := t.TempDir()
+	store, err := rss.NewFeedStore(dir)
+	if err != nil {
+		t.Fatalf("NewFeedStore 失败: %v", err)
+	}
+	fetcher := rss.NewFetcher(store, dir, 30)
+	args, _ := json.Marshal(map[string]string{"url": srv.URL})
+	_, _ = NewAddRSSFeedTool(store, fetcher).Execute(context.Background(), args)
+
+	// 没有新闻会话时只返回第一条，不能 panic
+	result, err := NewGetRSSNewsTool(store, fetcher, nil).Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if !contains(result, "测试文章一") {
+		t.Errorf("结果应包含文章标题: %s", result)
+	}
+}
+
 func TestGetRSSNewsToolNoFeeds(t *testing.T) {
 	_, _, _, getNewsTool, srv := setupRSSTools(t)
 	defer srv.Close()
@@ -237,3 +262,131 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGetRSSNewsToolPagination(t *testing.T) {
+	srv := setupRSSServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	store, err := rss.NewFeedStore(dir)
+	if err != nil {
+		t.Fatalf("NewFeedStore 失败: %v", err)
+	}
+	fetcher := rss.NewFetcher(store, dir, 30)
+	session := NewNewsSession()
+	addTool := NewAddRSSFeedTool(store, fetcher)
+	getNewsTool := NewGetRSSNewsTool(store, fetcher, session)
+	navTool := NewNavigateNewsTool(session)
+
+	args, _ := json.Marshal(map[string]string{"url": srv.URL})
+	_, _ = addTool.Execute(context.Background(), args)
+
+	// 首次只播报第一条
+	result, _ := getNewsTool.Execute(context.Background(), json.RawMessage(`{}`))
+	if !contains(result, "测试文章一") || contains(result, "AI新闻") {
+		t.Errorf("应只返回第一条: %s", result)
+	}
+
+	nav := func(action string) string {
+		t.Helper()
+		args, _ := json.Marshal(map[string]string{"action": action})
+		r, err := navTool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatalf("navigate %s 失败: %v", action, err)
+		}
+		return r
+	}
+
+	if r := nav("next"); !contains(r, "AI新闻") || !contains(r, "第 2/2 条") {
+		t.Errorf("下一条结果不匹配: %s", r)
+	}
+	if r := nav("next"); r != "已经是最后一条了。" {
+		t.Errorf("越界结果不匹配: %s", r)
+	}
+	if r := nav("detail"); !contains(r, "AI相关内容") {
+		t.Errorf("详细内容应包含正文: %s", r)
+	}
+	if r := nav("prev"); !contains(r, "测试文章一") {
+		t.Errorf("上一条结果不匹配: %s", r)
+	}
+	if r := nav("prev"); r != "已经是第一条了。" {
+		t.Errorf("越界结果不匹配: %s", r)
+	}
+
+	// 按序号跳转
+	r, _ := navTool.Execute(context.Background(), json.RawMessage(`{"action":"detail","index":2}`))
+	if !contains(r, "AI新闻") {
+		t.Errorf("按序号跳转结果不匹配: %s", r)
+	}
+	r, _ = navTool.Execute(context.Background(), json.RawMessage(`{"action":"detail","index":5}`))
+	if r != "只有 2 条内容，没有第 5 条。" {
+		t.Errorf("序号越界结果不匹配: %s", r)
+	}
+}
+
+func TestNavigateNewsToolEmpty(t *testing.T) {
+	navTool := NewNavigateNewsTool(NewNewsSession())
+	result, _ := navTool.Execute(context.Background(), json.RawMessage(`{"action":"next"}`))
+	if result != "现在没有正在阅读的新闻，可以先让我读读新闻或订阅内容。" {
+		t.Errorf("空会话结果不匹配: %s", result)
+	}
+	result, _ = navTool.Execute(context.Background(), json.RawMessage(`{"action":"detail","index":2}`))
+	if result != "现在没有正在阅读的新闻，可以先让我读读新闻或订阅内容。" {
+		t.Errorf("空会话按序号跳转结果不匹配: %s", result)
+	}
+
+	// 会话过期后按序号跳转同样提示没有正在阅读的新闻，而不是报条数
+	session := NewNewsSession()
+	session.Set([]NewsEntry{{Title: "一"}, {Title: "二"}, {Title: "三"}}, -1)
+	session.updatedAt = time.Now().Add(-newsSessionTTL - time.Minute)
+	result, _ = NewNavigateNewsTool(session).Execute(context.Background(), json.RawMessage(`{"action":"detail","index":5}`))
+	if result != "现在没有正在阅读的新闻，可以先让我读读新闻或订阅内容。" {
+		t.Errorf("过期会话按序号跳转结果不匹配: %s", result)
+	}
+}
+
+func TestRSSOPMLToolImportLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	store, err := rss.NewFeedStore(dir)
+	if err != nil {
+		t.Fatalf("NewFeedStore 失败: %v", err)
+	}
+	opml := `<?xml version="1.0"?><opml version="2.0"><body><outline text="测试" type="rss" xmlUrl="https://example.com/feed.xml"/></body></opml>`
+	if err := os.WriteFile(filepath.Join(dir, "feeds.opml"), []byte(opml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "outside.opml")
+	if err := os.WriteFile(outside, []byte(opml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewRSSOPMLTool(store, dir)
+
+	// 数据目录内指向目录外的符号链接
+	if err := os.Symlink(outside, filepath.Join(dir, "link.opml")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 数据目录外的文件一律拒绝
+	for _, source := range []string{outside, "../outside.opml", "/etc/passwd", "link.opml"} {
+		args, _ := json.Marshal(map[string]string{"action": "import", "source": source})
+		result, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatalf("Execute(%s) 失败: %v", source, err)
+		}
+		if !contains(result, "只能导入数据目录下") {
+			t.Errorf("Execute(%s) 应拒绝数据目录外的文件，got: %s", source, result)
+		}
+	}
+	if len(store.List()) != 0 {
+		t.Fatalf("不应导入任何订阅源，got %d", len(store.List()))
+	}
+
+	args, _ := json.Marshal(map[string]string{"action": "import", "source": "feeds.opml"})
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if !contains(result, "新增 1 个") {
+		t.Errorf("应导入数据目录下的文件，got: %s", result)
+	}
+}
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scratchpadMaxItems 草稿最多保存的条目数，防止大模型反复追加。
+const scratchpadMaxItems = 50
+
+// Scratchpad 一次对话内的草稿：多步任务中逐项累积的内容（如用户一条条口述的旅行清单）。
+// 只保存在内存里，会话结束时由 Pipeline 清空；用户要求保存时整理成一条备忘录。
+type Scratchpad struct {
+	mu    sync.Mutex
+	title string
+	items []string
+}
+
+// NewScratchpad 创建草稿。
+func NewScratchpad() *Scratchpad {
+	return &Scratchpad{}
+}
+
+// Clear 清空草稿，返回清掉的条目数。
+func (s *Scratchpad) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.items)
+	s.title, s.items = "", nil
+	return n
+}
+
+// Snapshot 返回草稿的标题和条目。
+func (s *Scratchpad) Snapshot() (string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.title, append([]string(nil), s.items...)
+}
+
+// add 追加条目，返回实际追加的数量。
+func (s *Scratchpad) add(title string, items []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if title != "" {
+		s.title = title
+	}
+	added := 0
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || len(s.items) >= scratchpadMaxItems {
+			continue
+		}
+		s.items = append(s.items, item)
+		added++
+	}
+	return added
+}
+
+// remove 删除第 index 项（从 1 开始）。
+func (s *Scratchpad) remove(index int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index < 1 || index > len(s.items) {
+		return "", false
+	}
+	item := s.items[index-1]
+	s.items = append(s.items[:index-1], s.items[index:]...)
+	return item, true
+}
+
+// formatScratchpad 把草稿整理成一段文字，如"旅行清单：1. 护照；2. 充电器"。
+func formatScratchpad(title string, items []string) string {
+	if title == "" {
+		title = "清单"
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprintf("%d. %s", i+1, item)
+	}
+	return title + "：" + strings.Join(parts, "；")
+}
+
+// ---- ScratchpadTool ----
+
+// ScratchpadTool 读写本次对话的草稿。
+type ScratchpadTool struct {
+	pad   *Scratchpad
+	memos *MemoStore // 保存为备忘录，为 nil 时不支持保存
+}
+
+// NewScratchpadTool 创建草稿工具。
+func NewScratchpadTool(pad *Scratchpad, memos *MemoStore) *ScratchpadTool {
+	return &ScratchpadTool{pad: pad, memos: memos}
+}
+
+func (t *ScratchpadTool) Name() string { return "scratchpad" }
+func (t *ScratchpadTool) Description() string {
+	return `本次对话的草稿，用于需要多轮逐项累积的任务，如"帮我列一下旅行清单，第一项是护照"、"再加一项充电器"、"念一下清单"、"删掉第二项"。
+操作：
+- add: 追加一项或多项（可同时设置标题）
+- list: 查看当前草稿
+- remove: 删除第 index 项
+- clear: 清空草稿
+- save: 用户说"保存下来"、"记到备忘录"时，把整个草稿保存为一条备忘录
+草稿只在本次对话中有效，对话结束后自动清空，除非保存到备忘录。`
+}
+func (t *ScratchpadTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["add", "list", "remove", "clear", "save"],
+				"description": "操作类型"
+			},
+			"title": {
+				"type": "string",
+				"description": "草稿标题，如'旅行清单'，add 时用户提到才填写"
+			},
+			"items": {
+				"type": "array",
+				"items": {"type": "string"},
+				"description": "要追加的条目（add 时必需），每项一个字符串"
+			},
+			"index": {
+				"type": "integer",
+				"description": "要删除的条目序号，从 1 开始（remove 时必需）"
+			}
+		},
+		"required": ["action"]
+	}`)
+}
+
+func (t *ScratchpadTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action string   `json:"action"`
+		Title  string   `json:"title"`
+		Items  []string `json:"items"`
+		Index  int      `json:"index"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+
+	switch params.Action {
+	case "add":
+		added := t.pad.add(strings.TrimSpace(params.Title), params.Items)
+		if added == 0 && len(params.Items) > 0 {
+			return fmt.Sprintf("草稿已满（最多 %d 项），没有追加", scratchpadMaxItems), nil
+		}
+		title, items := t.pad.Snapshot()
+		return fmt.Sprintf("已追加 %d 项，当前共 %d 项。%s", added, len(items), formatScratchpad(title, items)), nil
+	case "list":
+		title, items := t.pad.Snapshot()
+		if len(items) == 0 {
+			return "草稿是空的", nil
+		}
+		return formatScratchpad(title, items), nil
+	case "remove":
+		item, ok := t.pad.remove(params.Index)
+		if !ok {
+			return "", fmt.Errorf("没有第 %d 项", params.Index)
+		}
+		return fmt.Sprintf("已删除第 %d 项: %s", params.Index, item), nil
+	case "clear":
+		return fmt.Sprintf("已清空草稿（%d 项）", t.pad.Clear()), nil
+	case "save":
+		return t.save()
+	default:
+		return "", fmt.Errorf("不支持的操作: %s", params.Action)
+	}
+}
+
+// save 把草稿保存为一条备忘录并清空草稿。
+func (t *ScratchpadTool) save() (string, error) {
+	if t.memos == nil {
+		return "", fmt.Errorf("备忘录不可用，无法保存")
+	}
+	title, items := t.pad.Snapshot()
+	if len(items) == 0 {
+		return "草稿是空的，没有可保存的内容", nil
+	}
+	now := time.Now()
+	content := formatScratchpad(title, items)
+	entry := MemoEntry{
+		ID:      fmt.Sprintf("memo_%d", now.UnixMilli()),
+		Content: content,
+		Created: now.Format("2006-01-02 15:04:05"),
+	}
+	if err := t.memos.Add(entry); err != nil {
+		return "", fmt.Errorf("保存备忘录失败: %w", err)
+	}
+	t.pad.Clear()
+	return fmt.Sprintf("已保存到备忘录: %s", content), nil
+}
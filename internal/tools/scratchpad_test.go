@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScratchpadTool(t *testing.T) {
+	memos, err := NewMemoStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pad := NewScratchpad()
+	tool := NewScratchpadTool(pad, memos)
+	ctx := context.Background()
+	run := func(args string) string {
+		t.Helper()
+		out, err := tool.Execute(ctx, json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("Execute(%s) failed: %v", args, err)
+		}
+		return out
+	}
+
+	run(`{"action":"add","title":"旅行清单","items":["护照"]}`)
+	run(`{"action":"add","items":["充电器","防晒霜"]}`)
+	run(`{"action":"remove","index":2}`)
+	if out := run(`{"action":"list"}`); out != "旅行清单：1. 护照；2. 防晒霜" {
+		t.Errorf("list = %q", out)
+	}
+	if _, err := tool.Execute(ctx, json.RawMessage(`{"action":"remove","index":5}`)); err == nil {
+		t.Error("removing a missing item should fail")
+	}
+
+	if out := run(`{"action":"save"}`); !strings.Contains(out, "已保存") {
+		t.Errorf("save = %q", out)
+	}
+	saved := memos.List()
+	if len(saved) != 1 || saved[0].Content != "旅行清单：1. 护照；2. 防晒霜" {
+		t.Errorf("memos = %+v", saved)
+	}
+	if _, items := pad.Snapshot(); len(items) != 0 {
+		t.Error("scratchpad should be cleared after saving")
+	}
+
+	// 会话结束时清空
+	run(`{"action":"add","items":["牙刷"]}`)
+	if n := pad.Clear(); n != 1 {
+		t.Errorf("Clear() = %d, want 1", n)
+	}
+	if out := run(`{"action":"list"}`); out != "草稿是空的" {
+		t.Errorf("list after clear = %q", out)
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ComponentCheck 一个组件的检查结果。
+type ComponentCheck struct {
+	Name   string `json:"name"`  // audio、models、database、network
+	Label  string `json:"label"` // 朗读用的名称，如"音频设备"
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"` // 异常原因或补充说明
+}
+
+// HealthReport PiBuddy 自身的健康报告，管理 API /healthz 和语音查询共用。
+type HealthReport struct {
+	Healthy bool             `json:"healthy"` // 所有组件都正常
+	Uptime  string           `json:"uptime"`  // PiBuddy 已运行多久
+	Checks  []ComponentCheck `json:"checks"`
+	System  SystemStatus     `json:"system"`
+}
+
+// Summary 把健康报告整理成适合朗读的文字。
+func (r HealthReport) Summary() string {
+	var ok, bad []string
+	for _, c := range r.Checks {
+		if c.OK {
+			ok = append(ok, c.Label)
+			continue
+		}
+		item := c.Label + "异常"
+		if c.Detail != "" {
+			item += "（" + c.Detail + "）"
+		}
+		bad = append(bad, item)
+	}
+
+	var parts []string
+	if r.Uptime != "" {
+		parts = append(parts, "已运行"+r.Uptime)
+	}
+	if len(bad) == 0 {
+		parts = append(parts, strings.Join(ok, "、")+"都正常")
+	} else {
+		parts = append(parts, strings.Join(bad, "，"))
+		if len(ok) > 0 {
+			parts = append(parts, strings.Join(ok, "、")+"正常")
+		}
+	}
+	if sys := r.System.String(); sys != "" {
+		parts = append(parts, sys)
+	}
+	return strings.Join(parts, "；")
+}
+
+// SelfStatusTool 查询 PiBuddy 自身的运行状况。
+type SelfStatusTool struct {
+	check func(ctx context.Context) HealthReport
+}
+
+// NewSelfStatusTool 创建自身状态工具。check 生成健康报告。
+func NewSelfStatusTool(check func(ctx context.Context) HealthReport) *SelfStatusTool {
+	return &SelfStatusTool{check: check}
+}
+
+func (t *SelfStatusTool) Name() string { return "get_self_status" }
+
+func (t *SelfStatusTool) Description() string {
+	return "查询 PiBuddy 自身的运行状况：音频设备、语音模型、数据库、网络是否正常，已经运行了多久，以及 CPU 温度、内存等。" +
+		"当用户问'你自己状态怎么样'、'你运行多久了'、'你还好吗'时使用。"
+}
+
+func (t *SelfStatusTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {}
+	}`)
+}
+
+func (t *SelfStatusTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	report := t.check(ctx)
+	message := report.Summary()
+	if !report.Healthy {
+		message = fmt.Sprintf("有组件异常。%s", message)
+	}
+	return toJSON(map[string]interface{}{
+		"success": true,
+		"healthy": report.Healthy,
+		"message": message,
+	}), nil
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHealthReportSummary(t *testing.T) {
+	report := HealthReport{
+		Healthy: true,
+		Uptime:  "3小时20分钟",
+		Checks: []ComponentCheck{
+			{Name: "audio", Label: "音频设备", OK: true},
+			{Name: "database", Label: "数据库", OK: true},
+		},
+		System: SystemStatus{CPUTemperature: "45.0摄氏度"},
+	}
+	if got, want := report.Summary(), "已运行3小时20分钟；音频设备、数据库都正常；CPU温度: 45.0摄氏度"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	report.Healthy = false
+	report.Checks = append(report.Checks, ComponentCheck{Name: "network", Label: "网络", Detail: "无法连接云端服务"})
+	report.System = SystemStatus{}
+	if got, want := report.Summary(), "已运行3小时20分钟；网络异常（无法连接云端服务）；音频设备、数据库正常"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	tool := NewSelfStatusTool(func(ctx context.Context) HealthReport { return report })
+	result, err := tool.Execute(context.Background(), json.RawMessage("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, `"healthy":false`) || !strings.Contains(result, "网络异常") {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// maxWakeReplyRunes 唤醒回复语最多字数，太长会拖慢每次唤醒。
+const maxWakeReplyRunes = 20
+
+// 可通过语音修改的设置名。
+const (
+	SettingNameContinuousTimeout = "continuous_timeout"
+	SettingNameListenDelay       = "listen_delay"
+	SettingNameVerbosity         = "verbosity"
+	SettingNameWakeReply         = "wake_reply"
+)
+
+// settingSpec 一项可修改的设置：说法、保存的键名和取值范围。
+type settingSpec struct {
+	label    string
+	key      string
+	min, max int // 整数设置的取值范围
+	unit     string
+	integer  bool
+}
+
+var settingSpecs = map[string]settingSpec{
+	SettingNameContinuousTimeout: {label: "连续对话等待时间", key: database.SettingContinuousTimeout, min: 0, max: 120, unit: "秒", integer: true},
+	SettingNameListenDelay:       {label: "回复后开始聆听的延迟", key: database.SettingListenDelay, min: 0, max: 2000, unit: "毫秒", integer: true},
+	SettingNameVerbosity:         {label: "回复详略", key: database.SettingVerbosity},
+	SettingNameWakeReply:         {label: "唤醒回复语", key: database.SettingWakeReply},
+}
+
+// settingOrder 列出全部设置时的顺序。
+var settingOrder = []string{SettingNameContinuousTimeout, SettingNameListenDelay, SettingNameVerbosity, SettingNameWakeReply}
+
+// ManageSettingsTool 用语音查看和修改一部分运行时设置（连续对话超时、聆听延迟、回复详略、唤醒回复语），
+// 修改保存到设备设置中，立即生效，重启后保留。只开放不会让设备无法使用的设置。
+type ManageSettingsTool struct {
+	settings *database.Settings
+	current  func(name string) string
+	apply    func(name, value string)
+}
+
+// NewManageSettingsTool 创建设置工具。current 返回设置的当前值，apply 让校验后的新值立即生效。
+func NewManageSettingsTool(settings *database.Settings, current func(name string) string, apply func(name, value string)) *ManageSettingsTool {
+	return &ManageSettingsTool{settings: settings, current: current, apply: apply}
+}
+
+func (t *ManageSettingsTool) Name() string { return "manage_settings" }
+
+func (t *ManageSettingsTool) Description() string {
+	return "查看或修改设备设置：连续对话等待时间、回复后开始聆听的延迟、回复详略、唤醒回复语。" +
+		"当用户说'把连续对话改成15秒'、'唤醒后回答改成来啦'、'不要唤醒回复'、'现在连续对话是多久'、'看看当前设置'时使用。"
+}
+
+func (t *ManageSettingsTool) Parameters() json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["get", "set"],
+				"description": "get 查看，set 修改"
+			},
+			"name": {
+				"type": "string",
+				"enum": ["continuous_timeout", "listen_delay", "verbosity", "wake_reply"],
+				"description": "continuous_timeout 连续对话等待时间（秒，0 表示回复后不再等待）；listen_delay 回复后开始聆听的延迟（毫秒）；verbosity 回复详略（brief/normal/detailed）；wake_reply 唤醒回复语（最多 %d 字，空字符串表示不播放）。查看全部设置时不传"
+			},
+			"value": {
+				"type": "string",
+				"description": "新的值，修改时必填，如 \"15\"、\"brief\"、\"来啦\""
+			}
+		},
+		"required": ["action"]
+	}`, maxWakeReplyRunes))
+}
+
+func (t *ManageSettingsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Action string          `json:"action"`
+		Name   string          `json:"name"`
+		Value  json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	if params.Action != "set" {
+		if params.Name == "" {
+			var parts []string
+			for _, name := range settingOrder {
+				parts = append(parts, t.describe(name))
+			}
+			return "当前设置：" + strings.Join(parts, "；") + "。", nil
+		}
+		if _, ok := settingSpecs[params.Name]; !ok {
+			return fmt.Sprintf("不支持的设置: %s", params.Name), nil
+		}
+		return t.describe(params.Name) + "。", nil
+	}
+
+	spec, ok := settingSpecs[params.Name]
+	if !ok {
+		return fmt.Sprintf("不支持修改设置: %s", params.Name), nil
+	}
+	if params.Value == nil {
+		return "请告诉我要改成多少。", nil
+	}
+	value, msg := normalizeSettingValue(params.Name, spec, settingValueString(params.Value))
+	if msg != "" {
+		return msg, nil
+	}
+
+	if err := t.settings.SetString(spec.key, value); err != nil {
+		logger.Warnf("[tools] 保存设置 %s 失败: %v", params.Name, err)
+	}
+	t.apply(params.Name, value)
+	logger.Infof("[tools] 设置 %s 已改为 %q", params.Name, value)
+	return "好的，" + t.describe(params.Name) + "。", nil
+}
+
+// describe 设置的当前值，如"连续对话等待时间 15 秒"。
+func (t *ManageSettingsTool) describe(name string) string {
+	spec := settingSpecs[name]
+	value := t.current(name)
+	switch {
+	case name == SettingNameVerbosity:
+		return spec.label + verbosityNames[value]
+	case name == SettingNameWakeReply && value == "":
+		return "唤醒后不播放回复语"
+	case name == SettingNameWakeReply:
+		return fmt.Sprintf("%s是\"%s\"", spec.label, value)
+	case name == SettingNameContinuousTimeout && value == "0":
+		return "回复后不等待继续对话"
+	}
+	return fmt.Sprintf("%s %s %s", spec.label, value, spec.unit)
+}
+
+// settingValueString 兼容大模型把数字直接作为 JSON 数字传入。
+func settingValueString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// normalizeSettingValue 校验并规范化设置值，不合法时返回提示。
+func normalizeSettingValue(name string, spec settingSpec, value string) (string, string) {
+	switch {
+	case spec.integer:
+		value = strings.TrimSuffix(strings.TrimSuffix(value, spec.unit), "s")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Sprintf("%s需要是整数（%s）", spec.label, spec.unit)
+		}
+		if n < spec.min || n > spec.max {
+			return "", fmt.Sprintf("%s只能设置在 %d 到 %d %s之间", spec.label, spec.min, spec.max, spec.unit)
+		}
+		return strconv.Itoa(n), ""
+	case name == SettingNameVerbosity:
+		if !llm.ValidVerbosity(value) {
+			return "", fmt.Sprintf("不支持的详略程度: %s", value)
+		}
+		return value, ""
+	case name == SettingNameWakeReply:
+		value = strings.Trim(value, "\"“”'")
+		if utf8.RuneCountInString(value) > maxWakeReplyRunes {
+			return "", fmt.Sprintf("唤醒回复语最多 %d 个字", maxWakeReplyRunes)
+		}
+		return value, ""
+	}
+	return value, ""
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func newTestSettingsTool(t *testing.T) (*ManageSettingsTool, *database.Settings, map[string]string) {
+	settings := newTestSettings(t)
+	values := map[string]string{
+		SettingNameContinuousTimeout: "8",
+		SettingNameListenDelay:       "500",
+		SettingNameVerbosity:         "normal",
+		SettingNameWakeReply:         "我在",
+	}
+	tool := NewManageSettingsTool(settings,
+		func(name string) string { return values[name] },
+		func(name, value string) { values[name] = value })
+	return tool, settings, values
+}
+
+func TestManageSettingsTool_Set(t *testing.T) {
+	tool, settings, values := newTestSettingsTool(t)
+
+	// 大模型可能直接传数字
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"action":"set","name":"continuous_timeout","value":15}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[SettingNameContinuousTimeout] != "15" || !strings.Contains(result, "15 秒") {
+		t.Errorf("修改后 = %q, 结果 %s", values[SettingNameContinuousTimeout], result)
+	}
+	if got := settings.GetInt(database.SettingContinuousTimeout, 0); got != 15 {
+		t.Errorf("应保存到设备设置: %d", got)
+	}
+
+	tool.Execute(context.Background(), json.RawMessage(`{"action":"set","name":"wake_reply","value":"来啦"}`))
+	if values[SettingNameWakeReply] != "来啦" || settings.GetString(database.SettingWakeReply, "") != "来啦" {
+		t.Errorf("唤醒回复语 = %q", values[SettingNameWakeReply])
+	}
+
+	// 空字符串表示不播放唤醒回复语
+	result, _ = tool.Execute(context.Background(), json.RawMessage(`{"action":"set","name":"wake_reply","value":""}`))
+	if values[SettingNameWakeReply] != "" || !strings.Contains(result, "不播放") {
+		t.Errorf("关闭唤醒回复语: %q, %s", values[SettingNameWakeReply], result)
+	}
+}
+
+func TestManageSettingsTool_Validate(t *testing.T) {
+	tool, _, values := newTestSettingsTool(t)
+	for _, args := range []string{
+		`{"action":"set","name":"continuous_timeout","value":"600"}`,
+		`{"action":"set","name":"listen_delay","value":"很久"}`,
+		`{"action":"set","name":"verbosity","value":"chatty"}`,
+		`{"action":"set","name":"wake_reply","value":"这是一句非常非常非常非常非常非常长的唤醒回复语"}`,
+		`{"action":"set","name":"llm_model","value":"x"}`,
+	} {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(args)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if values[SettingNameContinuousTimeout] != "8" || values[SettingNameListenDelay] != "500" ||
+		values[SettingNameVerbosity] != "normal" || values[SettingNameWakeReply] != "我在" {
+		t.Errorf("不合法的值不应生效: %v", values)
+	}
+}
+
+func TestManageSettingsTool_Get(t *testing.T) {
+	tool, _, _ := newTestSettingsTool(t)
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"action":"get"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"8 秒", "500 毫秒", "正常", "我在"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("当前设置应包含 %q: %s", want, result)
+		}
+	}
+}
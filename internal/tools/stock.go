@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // StockTool 查询 A 股实时行情。
@@ -18,7 +20,8 @@ type StockTool struct {
 func NewStockTool() *StockTool {
 	return &StockTool{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
 	}
 }
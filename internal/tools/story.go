@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/iabetor/pibuddy/internal/llm"
 	"github.com/iabetor/pibuddy/internal/logger"
 )
 
@@ -56,7 +57,7 @@ func (t *TellStoryTool) Description() string {
 - "讲个童话故事" - 讲童话故事
 - "讲个小马过河的故事" - 讲指定标题的故事
 - "奥特曼的故事你会讲吗" - 讲奥特曼相关的故事
-注意：这是播放故事给用户听，不是保存故事！`
+注意：这是播放故事给用户听，不是保存故事！继续讲连载故事请使用 continue_story。`
 }
 
 // Parameters 返回参数定义
@@ -233,12 +234,14 @@ func (t *SaveStoryTool) Execute(ctx context.Context, args json.RawMessage) (stri
 
 // ListStoriesTool 列出故事工具
 type ListStoriesTool struct {
-	store *StoryStore
+	store          *StoryStore
+	series         *StorySeriesStore
+	contextManager *llm.ContextManager
 }
 
-// NewListStoriesTool 创建列出故事工具
-func NewListStoriesTool(store *StoryStore) *ListStoriesTool {
-	return &ListStoriesTool{store: store}
+// NewListStoriesTool 创建列出故事工具，series 不为 nil 时同时列出当前孩子的连载故事
+func NewListStoriesTool(store *StoryStore, series *StorySeriesStore, cm *llm.ContextManager) *ListStoriesTool {
+	return &ListStoriesTool{store: store, series: series, contextManager: cm}
 }
 
 // Name 返回工具名称
@@ -248,7 +251,7 @@ func (t *ListStoriesTool) Name() string {
 
 // Description 返回工具描述
 func (t *ListStoriesTool) Description() string {
-	return "列出可用的故事分类或某个分类下的故事列表"
+	return "列出可用的故事分类、某个分类下的故事列表，以及正在连载的故事（如'我们在讲哪些故事'）"
 }
 
 // Parameters 返回参数定义
@@ -263,6 +266,10 @@ func (t *ListStoriesTool) Parameters() json.RawMessage {
 			"limit": {
 				"type": "integer",
 				"description": "返回数量限制（默认10）"
+			},
+			"series": {
+				"type": "boolean",
+				"description": "只列出正在连载的故事"
 			}
 		}
 	}`)
@@ -272,6 +279,7 @@ func (t *ListStoriesTool) Parameters() json.RawMessage {
 type ListStoriesParams struct {
 	Category string `json:"category"`
 	Limit    int    `json:"limit"`
+	Series   bool   `json:"series"`
 }
 
 // Execute 执行列出故事
@@ -285,6 +293,10 @@ func (t *ListStoriesTool) Execute(ctx context.Context, args json.RawMessage) (st
 		params.Limit = 10
 	}
 
+	if params.Series {
+		return t.listSeries(), nil
+	}
+
 	if params.Category == "" {
 		// 列出所有分类
 		categories := t.store.ListCategories()
@@ -297,6 +309,9 @@ func (t *ListStoriesTool) Execute(ctx context.Context, args json.RawMessage) (st
 		if llmCount > 0 {
 			result += fmt.Sprintf("\n用户保存的故事: %d 个\n", llmCount)
 		}
+		if t.series != nil {
+			result += "\n" + t.listSeries()
+		}
 		return result, nil
 	}
 
@@ -313,16 +328,37 @@ func (t *ListStoriesTool) Execute(ctx context.Context, args json.RawMessage) (st
 	return result, nil
 }
 
+// listSeries 列出当前孩子的连载故事
+func (t *ListStoriesTool) listSeries() string {
+	if t.series == nil {
+		return "连载故事功能未启用"
+	}
+	list, err := t.series.List(storyListener(t.contextManager))
+	if err != nil {
+		return err.Error()
+	}
+	if len(list) == 0 {
+		return "还没有正在连载的故事"
+	}
+	result := "正在连载的故事：\n"
+	for _, s := range list {
+		result += fmt.Sprintf("- 《%s》 讲到第 %d 章（%s）\n", s.Title, s.Chapter, s.UpdatedAt.Format("1月2日"))
+	}
+	return result
+}
+
 // ---- DeleteStoryTool 删除故事 ----
 
 // DeleteStoryTool 删除故事工具
 type DeleteStoryTool struct {
-	store *StoryStore
+	store          *StoryStore
+	series         *StorySeriesStore
+	contextManager *llm.ContextManager
 }
 
-// NewDeleteStoryTool 创建删除故事工具
-func NewDeleteStoryTool(store *StoryStore) *DeleteStoryTool {
-	return &DeleteStoryTool{store: store}
+// NewDeleteStoryTool 创建删除故事工具，series 不为 nil 时可以删除当前孩子的连载故事
+func NewDeleteStoryTool(store *StoryStore, series *StorySeriesStore, cm *llm.ContextManager) *DeleteStoryTool {
+	return &DeleteStoryTool{store: store, series: series, contextManager: cm}
 }
 
 // Name 返回工具名称
@@ -336,7 +372,8 @@ func (t *DeleteStoryTool) Description() string {
 示例：
 - "删除荆轲刺秦王这个故事" - 删除指定故事
 - "删除所有用户保存的故事" - 删除所有 source=llm 的故事
-- "清空故事缓存" - 删除所有 API 缓存的故事`
+- "清空故事缓存" - 删除所有 API 缓存的故事
+- "小兔子的连载故事不讲了" - 删除连载故事（series）`
 }
 
 // Parameters 返回参数定义
@@ -355,6 +392,10 @@ func (t *DeleteStoryTool) Parameters() json.RawMessage {
 			"clear_cache": {
 				"type": "boolean",
 				"description": "清空所有 API 缓存的故事（source=api）"
+			},
+			"series": {
+				"type": "string",
+				"description": "要删除的连载故事名"
 			}
 		}
 	}`)
@@ -362,9 +403,10 @@ func (t *DeleteStoryTool) Parameters() json.RawMessage {
 
 // DeleteStoryParams 删除故事参数
 type DeleteStoryParams struct {
-	Keyword        string `json:"keyword"`
-	DeleteAllUser  bool   `json:"delete_all_user"`
-	ClearCache     bool   `json:"clear_cache"`
+	Keyword       string `json:"keyword"`
+	DeleteAllUser bool   `json:"delete_all_user"`
+	ClearCache    bool   `json:"clear_cache"`
+	Series        string `json:"series"`
 }
 
 // Execute 执行删除故事
@@ -374,6 +416,22 @@ func (t *DeleteStoryTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return "", fmt.Errorf("解析参数失败: %w", err)
 	}
 
+	// 删除连载故事
+	if params.Series != "" && t.series != nil {
+		user := storyListener(t.contextManager)
+		series, err := t.series.Get(user, params.Series)
+		if err != nil {
+			return "", err
+		}
+		if series == nil {
+			return fmt.Sprintf("没有找到连载故事\"%s\"", params.Series), nil
+		}
+		if _, err := t.series.Delete(user, series.Title); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("已删除连载故事《%s》", series.Title), nil
+	}
+
 	// 删除所有用户保存的故事
 	if params.DeleteAllUser {
 		count, err := t.store.DeleteAllUserStories()
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 )
 
 // StoryAPI 外部故事 API 客户端
@@ -34,7 +35,7 @@ type cachedStory struct {
 func NewStoryAPI(baseURL, appID, appSecret string) *StoryAPI {
 	return &StoryAPI{
 		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		httpClient: &http.Client{Timeout: 10 * time.Second, Transport: ratelimit.NewTransport(nil)},
 		appID:      appID,
 		appSecret:  appSecret,
 		cache:      make(map[string]*cachedStory),
@@ -252,7 +253,11 @@ func (a *StoryAPI) FindStory(ctx context.Context, keyword string) (*Story, error
 		logger.Debugf("[story] 匹配成功: %s", item.Title)
 
 		// 等待 1.1 秒避免 QPS 限制（普通会员 QPS=1）
-		time.Sleep(1100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(1100 * time.Millisecond):
+		}
 
 		return a.GetContent(ctx, item.ID)
 	}
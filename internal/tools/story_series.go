@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+// StorySeries 一个连载故事的进度：每个孩子各自一份，记录人物和上一章梗概，
+// 下次"继续昨天的故事"时接着往下讲。
+type StorySeries struct {
+	User       string    `json:"user"`
+	Title      string    `json:"title"`
+	Characters string    `json:"characters"`
+	Summary    string    `json:"summary"` // 上一章梗概
+	Chapter    int       `json:"chapter"` // 已讲到第几章
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// StorySeriesStore 连载故事进度存储（SQLite）。
+type StorySeriesStore struct {
+	db *database.DB
+}
+
+// NewStorySeriesStore 创建连载故事进度存储。
+func NewStorySeriesStore(db *database.DB) *StorySeriesStore {
+	return &StorySeriesStore{db: db}
+}
+
+const storySeriesColumns = `user, title, characters, summary, chapter, updated_at`
+
+func scanStorySeries(row interface{ Scan(...interface{}) error }) (*StorySeries, error) {
+	var s StorySeries
+	var updatedAt sql.NullTime
+	if err := row.Scan(&s.User, &s.Title, &s.Characters, &s.Summary, &s.Chapter, &updatedAt); err != nil {
+		return nil, err
+	}
+	if updatedAt.Valid {
+		s.UpdatedAt = updatedAt.Time
+	}
+	return &s, nil
+}
+
+// Get 查找用户的连载故事，title 为空时返回最近讲过的一个，支持模糊匹配标题。
+// 没有找到时返回 nil。
+func (s *StorySeriesStore) Get(user, title string) (*StorySeries, error) {
+	title = strings.TrimSpace(title)
+	var row *sql.Row
+	if title == "" {
+		row = s.db.QueryRow(`SELECT `+storySeriesColumns+` FROM story_series
+			WHERE user = ? ORDER BY updated_at DESC LIMIT 1`, user)
+	} else {
+		row = s.db.QueryRow(`SELECT `+storySeriesColumns+` FROM story_series
+			WHERE user = ? AND (title = ? OR title LIKE ?)
+			ORDER BY title = ? DESC, updated_at DESC LIMIT 1`, user, title, "%"+title+"%", title)
+	}
+	series, err := scanStorySeries(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询连载故事失败: %w", err)
+	}
+	return series, nil
+}
+
+// SaveChapter 记录新讲完的一章，不存在时新建连载。characters 为空时保留原来的人物。
+func (s *StorySeriesStore) SaveChapter(user, title, characters, summary string) (*StorySeries, error) {
+	now := time.Now()
+	_, err := s.db.Exec(`INSERT INTO story_series (user, title, characters, summary, chapter, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT(user, title) DO UPDATE SET
+			characters = CASE WHEN excluded.characters = '' THEN characters ELSE excluded.characters END,
+			summary = excluded.summary,
+			chapter = chapter + 1,
+			updated_at = excluded.updated_at`,
+		user, title, characters, summary, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("保存连载故事失败: %w", err)
+	}
+	return s.Get(user, title)
+}
+
+// Restart 让连载从头开始讲，保留故事名和人物。
+func (s *StorySeriesStore) Restart(user, title string) error {
+	_, err := s.db.Exec(`UPDATE story_series SET chapter = 0, summary = '', updated_at = ?
+		WHERE user = ? AND title = ?`, time.Now(), user, title)
+	if err != nil {
+		return fmt.Errorf("重置连载故事失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除用户的一个连载故事，返回是否删除。
+func (s *StorySeriesStore) Delete(user, title string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM story_series WHERE user = ? AND title = ?`, user, title)
+	if err != nil {
+		return false, fmt.Errorf("删除连载故事失败: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// List 列出用户的连载故事，最近讲过的在前。
+func (s *StorySeriesStore) List(user string) ([]StorySeries, error) {
+	rows, err := s.db.Query(`SELECT `+storySeriesColumns+` FROM story_series
+		WHERE user = ? ORDER BY updated_at DESC`, user)
+	if err != nil {
+		return nil, fmt.Errorf("查询连载故事失败: %w", err)
+	}
+	defer rows.Close()
+
+	var list []StorySeries
+	for rows.Next() {
+		series, err := scanStorySeries(rows)
+		if err != nil {
+			return nil, fmt.Errorf("读取连载故事失败: %w", err)
+		}
+		list = append(list, *series)
+	}
+	return list, rows.Err()
+}
+
+// storyListener 当前听故事的孩子，未识别出说话人时使用 guest。
+func storyListener(cm *llm.ContextManager) string {
+	if cm != nil {
+		if name := cm.GetCurrentSpeaker(); name != "" {
+			return name
+		}
+	}
+	return "guest"
+}
+
+// ---- ContinueStoryTool 继续连载故事 ----
+
+// ContinueStoryTool 取出连载故事的进度，让 LLM 接着上一章往下讲。
+type ContinueStoryTool struct {
+	series         *StorySeriesStore
+	contextManager *llm.ContextManager
+}
+
+// NewContinueStoryTool 创建继续连载故事工具。
+func NewContinueStoryTool(series *StorySeriesStore, cm *llm.ContextManager) *ContinueStoryTool {
+	return &ContinueStoryTool{series: series, contextManager: cm}
+}
+
+// Name 返回工具名称
+func (t *ContinueStoryTool) Name() string {
+	return "continue_story"
+}
+
+// Description 返回工具描述
+func (t *ContinueStoryTool) Description() string {
+	return `继续讲连载故事。当用户说"继续昨天的故事"、"接着讲小兔子的故事"、"那个故事从头讲"时使用。
+返回故事名、人物和上一章梗概，请据此创作下一章，先调用 save_story_chapter 记录本章，再把故事讲出来。`
+}
+
+// Parameters 返回参数定义
+func (t *ContinueStoryTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"title": {
+				"type": "string",
+				"description": "连载故事名（可选，不填则继续最近讲的那个）"
+			},
+			"restart": {
+				"type": "boolean",
+				"description": "是否从第一章重新开始讲"
+			}
+		}
+	}`)
+}
+
+// Execute 执行继续连载故事
+func (t *ContinueStoryTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Title   string `json:"title"`
+		Restart bool   `json:"restart"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+
+	user := storyListener(t.contextManager)
+	series, err := t.series.Get(user, params.Title)
+	if err != nil {
+		return "", err
+	}
+	if series == nil {
+		return toJSON(map[string]interface{}{
+			"success": false,
+			"message": "还没有讲过这个连载故事。可以创作一个新的连载故事的第一章，并调用 save_story_chapter 记录下来",
+		}), nil
+	}
+
+	if params.Restart {
+		if err := t.series.Restart(user, series.Title); err != nil {
+			return "", err
+		}
+		return toJSON(map[string]interface{}{
+			"success":    true,
+			"title":      series.Title,
+			"characters": series.Characters,
+			"chapter":    1,
+			"message":    fmt.Sprintf("《%s》从头开始讲，请用这些人物重新创作第一章", series.Title),
+		}), nil
+	}
+
+	return toJSON(map[string]interface{}{
+		"success":      true,
+		"title":        series.Title,
+		"characters":   series.Characters,
+		"last_summary": series.Summary,
+		"chapter":      series.Chapter + 1,
+		"message": fmt.Sprintf("上次讲到《%s》第 %d 章，请先用一两句话回顾上一章，再接着讲第 %d 章",
+			series.Title, series.Chapter, series.Chapter+1),
+	}), nil
+}
+
+// ---- SaveStoryChapterTool 记录连载进度 ----
+
+// SaveStoryChapterTool 记录连载故事刚创作的一章，供下次继续。
+type SaveStoryChapterTool struct {
+	series         *StorySeriesStore
+	contextManager *llm.ContextManager
+}
+
+// NewSaveStoryChapterTool 创建记录连载进度工具。
+func NewSaveStoryChapterTool(series *StorySeriesStore, cm *llm.ContextManager) *SaveStoryChapterTool {
+	return &SaveStoryChapterTool{series: series, contextManager: cm}
+}
+
+// Name 返回工具名称
+func (t *SaveStoryChapterTool) Name() string {
+	return "save_story_chapter"
+}
+
+// Description 返回工具描述
+func (t *SaveStoryChapterTool) Description() string {
+	return `记录连载故事的进度。用户说"讲一个连载故事"、"每天讲一章"开始新连载，或继续讲连载故事时，
+创作好本章后先调用本工具记录故事名、主要人物和本章梗概，再把本章讲出来。`
+}
+
+// Parameters 返回参数定义
+func (t *SaveStoryChapterTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"title": {
+				"type": "string",
+				"description": "连载故事名"
+			},
+			"characters": {
+				"type": "string",
+				"description": "主要人物及特点，如'小兔子跳跳（勇敢）、乌龟慢慢（聪明）'"
+			},
+			"summary": {
+				"type": "string",
+				"description": "本章梗概（100 字以内），包括结尾停在哪里"
+			}
+		},
+		"required": ["title", "summary"]
+	}`)
+}
+
+// Execute 执行记录连载进度
+func (t *SaveStoryChapterTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var params struct {
+		Title      string `json:"title"`
+		Characters string `json:"characters"`
+		Summary    string `json:"summary"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", fmt.Errorf("解析参数失败: %w", err)
+	}
+	params.Title = strings.Trim(strings.TrimSpace(params.Title), "《》")
+	if params.Title == "" || params.Summary == "" {
+		return "", fmt.Errorf("故事名和梗概不能为空")
+	}
+
+	series, err := t.series.SaveChapter(storyListener(t.contextManager), params.Title, params.Characters, params.Summary)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(map[string]interface{}{
+		"success": true,
+		"chapter": series.Chapter,
+		"message": fmt.Sprintf("已记录《%s》第 %d 章，现在把这一章讲出来", series.Title, series.Chapter),
+	}), nil
+}
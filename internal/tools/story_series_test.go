@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/llm"
+)
+
+func newTestStorySeriesStore(t *testing.T) *StorySeriesStore {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return NewStorySeriesStore(db)
+}
+
+func TestStorySeriesStore_SaveChapter(t *testing.T) {
+	s := newTestStorySeriesStore(t)
+
+	if got, err := s.Get("小明", ""); err != nil || got != nil {
+		t.Fatalf("Get on empty store = %v, %v", got, err)
+	}
+
+	s.SaveChapter("小明", "小兔子历险记", "小兔子跳跳", "跳跳离开了家")
+	got, err := s.SaveChapter("小明", "小兔子历险记", "", "跳跳遇到了乌龟")
+	if err != nil {
+		t.Fatalf("SaveChapter failed: %v", err)
+	}
+	if got.Chapter != 2 || got.Characters != "小兔子跳跳" || got.Summary != "跳跳遇到了乌龟" {
+		t.Errorf("series = %+v", got)
+	}
+
+	// 不同孩子的进度分开
+	s.SaveChapter("小红", "公主和龙", "公主", "公主出发了")
+	if got, _ := s.Get("小明", ""); got == nil || got.Title != "小兔子历险记" {
+		t.Errorf("latest for 小明 = %+v", got)
+	}
+	if got, _ := s.Get("小红", "小兔子"); got != nil {
+		t.Errorf("小红 should not see 小明's series: %+v", got)
+	}
+
+	// 模糊匹配标题
+	if got, _ := s.Get("小明", "小兔子"); got == nil || got.Chapter != 2 {
+		t.Errorf("fuzzy get = %+v", got)
+	}
+
+	if err := s.Restart("小明", "小兔子历险记"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if got, _ := s.SaveChapter("小明", "小兔子历险记", "", "重新开始"); got.Chapter != 1 || got.Characters != "小兔子跳跳" {
+		t.Errorf("after restart = %+v", got)
+	}
+
+	if ok, _ := s.Delete("小明", "小兔子历险记"); !ok {
+		t.Error("Delete should succeed")
+	}
+	if list, _ := s.List("小明"); len(list) != 0 {
+		t.Errorf("list after delete = %v", list)
+	}
+}
+
+func TestContinueStoryTool_Execute(t *testing.T) {
+	s := newTestStorySeriesStore(t)
+	cm := llm.NewContextManager("", 10)
+	cm.SetCurrentSpeaker("小明", nil)
+	save := NewSaveStoryChapterTool(s, cm)
+	cont := NewContinueStoryTool(s, cm)
+
+	out, _ := cont.Execute(context.Background(), json.RawMessage(`{}`))
+	if !strings.Contains(out, `"success":false`) {
+		t.Errorf("continue without series = %s", out)
+	}
+
+	if _, err := save.Execute(context.Background(), json.RawMessage(`{"title":"《小兔子历险记》","characters":"跳跳","summary":"跳跳离开了家"}`)); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	out, err := cont.Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("continue failed: %v", err)
+	}
+	var result struct {
+		Title       string `json:"title"`
+		Chapter     int    `json:"chapter"`
+		LastSummary string `json:"last_summary"`
+	}
+	json.Unmarshal([]byte(out), &result)
+	if result.Title != "小兔子历险记" || result.Chapter != 2 || result.LastSummary != "跳跳离开了家" {
+		t.Errorf("continue = %s", out)
+	}
+
+	// 其他孩子看不到这个连载
+	cm.SetCurrentSpeaker("小红", nil)
+	out, _ = cont.Execute(context.Background(), json.RawMessage(`{}`))
+	if !strings.Contains(out, `"success":false`) {
+		t.Errorf("other child continue = %s", out)
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// studyMaxMinutes 一次学习时间最长多少分钟。
+const studyMaxMinutes = 180
+
+// StudyModeTool 学习时间：家长为孩子开启后，时间内孩子点歌、听故事、玩游戏会被温和地拒绝，
+// 查字典、学英语等学习工具照常可用，结束时响铃并表扬。孩子通过声纹识别。
+type StudyModeTool struct {
+	defaultMinutes int
+	start          func(child string, d time.Duration) error
+	stop           func() (child string, err error)
+	status         func() (child string, remaining time.Duration, ok bool)
+}
+
+// NewStudyModeTool 创建学习时间工具。start 为孩子开启学习时间，stop 提前结束，status 查询进行中的学习时间。
+func NewStudyModeTool(defaultMinutes int, start func(string, time.Duration) error, stop func() (string, error),
+	status func() (string, time.Duration, bool)) *StudyModeTool {
+	return &StudyModeTool{defaultMinutes: defaultMinutes, start: start, stop: stop, status: status}
+}
+
+func (t *StudyModeTool) Name() string { return "study_mode" }
+
+func (t *StudyModeTool) Description() string {
+	return "家长为孩子开启学习时间：时间内孩子不能点歌、听故事、玩游戏，查字典、学英语照常可用，结束时响铃表扬。" +
+		"当家长说'让小明学习40分钟'、'开始写作业时间'、'结束学习时间'、'还要学多久'时使用。孩子必须是已注册声纹的用户。"
+}
+
+func (t *StudyModeTool) Parameters() json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"action": {
+				"type": "string",
+				"enum": ["start", "stop", "status"],
+				"description": "start 开始，stop 提前结束，status 查询剩余时间"
+			},
+			"child": {
+				"type": "string",
+				"description": "孩子的声纹用户名，开始时必填"
+			},
+			"minutes": {
+				"type": "integer",
+				"description": "学习多少分钟，默认 %d，最多 %d"
+			}
+		},
+		"required": ["action"]
+	}`, t.defaultMinutes, studyMaxMinutes))
+}
+
+func (t *StudyModeTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Action  string `json:"action"`
+		Child   string `json:"child"`
+		Minutes int    `json:"minutes"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("参数解析失败: %w", err)
+	}
+
+	switch a.Action {
+	case "start":
+		if a.Child == "" {
+			return toJSON(map[string]interface{}{"success": false, "message": "请告诉我是哪个孩子要学习"}), nil
+		}
+		minutes := a.Minutes
+		if minutes <= 0 {
+			minutes = t.defaultMinutes
+		}
+		if minutes > studyMaxMinutes {
+			minutes = studyMaxMinutes
+		}
+		if err := t.start(a.Child, time.Duration(minutes)*time.Minute); err != nil {
+			return toJSON(map[string]interface{}{"success": false, "message": err.Error()}), nil
+		}
+		return toJSON(map[string]interface{}{
+			"success": true,
+			"child":   a.Child,
+			"minutes": minutes,
+			"message": fmt.Sprintf("已为%s开启 %d 分钟学习时间，期间不能听歌、听故事、玩游戏，查字典和学英语照常可以", a.Child, minutes),
+		}), nil
+	case "stop":
+		child, err := t.stop()
+		if err != nil {
+			return toJSON(map[string]interface{}{"success": false, "message": err.Error()}), nil
+		}
+		return toJSON(map[string]interface{}{"success": true, "message": fmt.Sprintf("已结束%s的学习时间", child)}), nil
+	default:
+		child, remaining, ok := t.status()
+		if !ok {
+			return toJSON(map[string]interface{}{"success": true, "message": "现在没有进行中的学习时间"}), nil
+		}
+		minutes := int(remaining.Minutes() + 0.5)
+		if minutes < 1 {
+			minutes = 1
+		}
+		return toJSON(map[string]interface{}{
+			"success":           true,
+			"child":             child,
+			"remaining_minutes": minutes,
+			"message":           fmt.Sprintf("%s的学习时间还剩大约 %d 分钟", child, minutes),
+		}), nil
+	}
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStudyModeTool_Execute(t *testing.T) {
+	var child string
+	var until time.Duration
+	tool := NewStudyModeTool(30,
+		func(c string, d time.Duration) error {
+			if c == "小红" {
+				return errors.New("没有找到叫小红的声纹用户")
+			}
+			child, until = c, d
+			return nil
+		},
+		func() (string, error) {
+			if child == "" {
+				return "", errors.New("现在没有进行中的学习时间")
+			}
+			c := child
+			child = ""
+			return c, nil
+		},
+		func() (string, time.Duration, bool) { return child, until, child != "" })
+
+	tests := []struct {
+		args string
+		want time.Duration
+	}{
+		{`{"action":"start","child":"小明"}`, 30 * time.Minute},
+		{`{"action":"start","child":"小明","minutes":45}`, 45 * time.Minute},
+		{`{"action":"start","child":"小明","minutes":1000}`, studyMaxMinutes * time.Minute},
+	}
+	for _, tt := range tests {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(tt.args)); err != nil {
+			t.Fatalf("Execute(%s) failed: %v", tt.args, err)
+		}
+		if child != "小明" || until != tt.want {
+			t.Errorf("Execute(%s) started %s for %v, want 小明 for %v", tt.args, child, until, tt.want)
+		}
+	}
+
+	out, _ := tool.Execute(context.Background(), json.RawMessage(`{"action":"status"}`))
+	if !strings.Contains(out, "小明") || !strings.Contains(out, "180") {
+		t.Errorf("status = %s", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"action":"start"}`))
+	if !strings.Contains(out, `"success":false`) {
+		t.Errorf("start without child = %s", out)
+	}
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"action":"start","child":"小红"}`))
+	if !strings.Contains(out, "没有找到") {
+		t.Errorf("start unknown child = %s", out)
+	}
+
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"action":"stop"}`))
+	if child != "" || !strings.Contains(out, "已结束小明") {
+		t.Errorf("stop = %s", out)
+	}
+	out, _ = tool.Execute(context.Background(), json.RawMessage(`{"action":"status"}`))
+	if !strings.Contains(out, "没有进行中") {
+		t.Errorf("status after stop = %s", out)
+	}
+}
@@ -37,43 +37,51 @@ func (t *SystemStatusTool) Parameters() json.RawMessage {
 }
 
 func (t *SystemStatusTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
-	var results []string
-
-	// CPU 温度
-	if temp := t.getCPUTemperature(); temp != "" {
-		results = append(results, fmt.Sprintf("CPU温度: %s", temp))
-	}
-
-	// 内存使用
-	if mem := t.getMemoryUsage(); mem != "" {
-		results = append(results, fmt.Sprintf("内存: %s", mem))
-	}
-
-	// 磁盘空间
-	if disk := t.getDiskUsage(); disk != "" {
-		results = append(results, fmt.Sprintf("磁盘: %s", disk))
-	}
-
-	// 电池电量
-	if battery := t.getBatteryLevel(); battery != "" {
-		results = append(results, fmt.Sprintf("电池: %s", battery))
+	text := t.Snapshot().String()
+	if text == "" {
+		return "无法获取系统状态", nil
 	}
+	return text, nil
+}
 
-	// CPU 使用率
-	if cpu := t.getCPUUsage(); cpu != "" {
-		results = append(results, fmt.Sprintf("CPU: %s", cpu))
-	}
+// SystemStatus 系统状态，获取不到的项为空。
+type SystemStatus struct {
+	CPUTemperature string `json:"cpu_temperature,omitempty"`
+	Memory         string `json:"memory,omitempty"`
+	Disk           string `json:"disk,omitempty"`
+	Battery        string `json:"battery,omitempty"`
+	CPU            string `json:"cpu,omitempty"`
+	Uptime         string `json:"uptime,omitempty"` // 系统运行时间
+}
 
-	// 运行时间
-	if uptime := t.getUptime(); uptime != "" {
-		results = append(results, fmt.Sprintf("运行时间: %s", uptime))
+// Snapshot 获取当前系统状态，供健康检查等复用。
+func (t *SystemStatusTool) Snapshot() SystemStatus {
+	return SystemStatus{
+		CPUTemperature: t.getCPUTemperature(),
+		Memory:         t.getMemoryUsage(),
+		Disk:           t.getDiskUsage(),
+		Battery:        t.getBatteryLevel(),
+		CPU:            t.getCPUUsage(),
+		Uptime:         t.getUptime(),
 	}
+}
 
-	if len(results) == 0 {
-		return "无法获取系统状态", nil
+// String 把系统状态整理成一句话，如"CPU温度: 45.0摄氏度；内存: 30% (1200/4000 MB)"。
+func (s SystemStatus) String() string {
+	var results []string
+	for _, item := range []struct{ name, value string }{
+		{"CPU温度", s.CPUTemperature},
+		{"内存", s.Memory},
+		{"磁盘", s.Disk},
+		{"电池", s.Battery},
+		{"CPU", s.CPU},
+		{"运行时间", s.Uptime},
+	} {
+		if item.value != "" {
+			results = append(results, fmt.Sprintf("%s: %s", item.name, item.value))
+		}
 	}
-
-	return strings.Join(results, "；"), nil
+	return strings.Join(results, "；")
 }
 
 // getCPUTemperature 获取 CPU 温度。
@@ -351,7 +359,7 @@ func (t *SystemStatusTool) getUptime() string {
 			fields := strings.Fields(string(data))
 			if len(fields) >= 1 {
 				uptime, _ := strconv.ParseFloat(fields[0], 64)
-				return formatUptime(uptime)
+				return FormatUptime(uptime)
 			}
 		}
 	}
@@ -377,8 +385,8 @@ func (t *SystemStatusTool) getUptime() string {
 	return ""
 }
 
-// formatUptime 格式化运行时间。
-func formatUptime(seconds float64) string {
+// FormatUptime 格式化运行时间，如"3天2小时"、"5小时20分钟"。
+func FormatUptime(seconds float64) string {
 	days := int(seconds) / 86400
 	hours := (int(seconds) % 86400) / 3600
 	mins := (int(seconds) % 3600) / 60
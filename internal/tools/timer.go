@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -184,6 +186,65 @@ func (s *TimerStore) Get(id string) (*TimerEntry, bool) {
 	return entry, ok
 }
 
+// timerLabelNoise 用户说标签时常带的多余词，匹配前去掉。
+var timerLabelNoise = []string{"的计时器", "计时器", "的倒计时", "倒计时", "的定时器", "定时器", "的计时", "计时", "的提醒", "提醒"}
+
+// normalizeTimerLabel 去掉"的计时器"等多余词，"面条的计时器"→"面条"。
+func normalizeTimerLabel(label string) string {
+	label = strings.TrimSpace(label)
+	for _, noise := range timerLabelNoise {
+		label = strings.ReplaceAll(label, noise, "")
+	}
+	return strings.TrimSpace(label)
+}
+
+// timerLabelScore 标签与用户说法的匹配程度：2 表示互相包含，1 表示至少两个字且一半以上的字相同，0 表示不匹配。
+func timerLabelScore(label, query string) int {
+	label, query = normalizeTimerLabel(label), normalizeTimerLabel(query)
+	if label == "" || query == "" {
+		return 0
+	}
+	if strings.Contains(label, query) || strings.Contains(query, label) {
+		return 2
+	}
+	shared := 0
+	for _, r := range query {
+		if strings.ContainsRune(label, r) {
+			shared++
+		}
+	}
+	if shared >= 2 && shared*2 >= len([]rune(query)) {
+		return 1
+	}
+	return 0
+}
+
+// Match 按标签模糊查找倒计时（"面条"能匹配"煮面条"），只返回匹配程度最高的一组，按剩余时间排序。
+func (s *TimerStore) Match(query string) []TimerEntry {
+	best := 0
+	var matched []TimerEntry
+	for _, e := range s.List() {
+		score := timerLabelScore(e.Label, query)
+		if score == 0 || score < best {
+			continue
+		}
+		if score > best {
+			best, matched = score, nil
+		}
+		matched = append(matched, e)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Remaining < matched[j].Remaining })
+	return matched
+}
+
+// TimerAnnouncement 倒计时到期时的播报内容，带上标签和原始时长，同时有多个倒计时时能分清是哪一个。
+func TimerAnnouncement(e TimerEntry) string {
+	if label := normalizeTimerLabel(e.Label); label != "" {
+		return fmt.Sprintf("%s的%s倒计时到了", label, formatDuration(e.Duration))
+	}
+	return fmt.Sprintf("%s倒计时结束了", formatDuration(e.Duration))
+}
+
 // ---- SetTimerTool ----
 
 type SetTimerTool struct {
@@ -196,7 +257,7 @@ func NewSetTimerTool(store *TimerStore) *SetTimerTool {
 
 func (t *SetTimerTool) Name() string { return "set_timer" }
 func (t *SetTimerTool) Description() string {
-	return "设置倒计时器。当用户说'设个倒计时'、'提醒我'、'定时'等时使用。时间由LLM解析为秒数。"
+	return "设置倒计时器。当用户说'设个倒计时'、'提醒我'、'定时'等时使用。时间由LLM解析为秒数。可以同时设置多个，用 label 区分（如'面条'、'烤箱'）。"
 }
 func (t *SetTimerTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -208,7 +269,7 @@ func (t *SetTimerTool) Parameters() json.RawMessage {
 			},
 			"label": {
 				"type": "string",
-				"description": "提醒标签，如'关火'、'休息'"
+				"description": "提醒标签，如'面条'、'烤箱'、'关火'，同时有多个倒计时时用来区分"
 			}
 		},
 		"required": ["duration_seconds"]
@@ -230,25 +291,30 @@ func (t *SetTimerTool) Execute(ctx context.Context, args json.RawMessage) (strin
 		return "", fmt.Errorf("倒计时长必须大于0秒")
 	}
 
+	return startTimer(t.store, a.DurationSeconds, a.Label)
+}
+
+// startTimer 创建并启动倒计时，返回给用户的确认文本。
+func startTimer(store *TimerStore, seconds int, label string) (string, error) {
 	now := time.Now()
 	id := fmt.Sprintf("timer_%d", now.UnixMilli())
 	entry := &TimerEntry{
 		ID:        id,
-		Duration:  a.DurationSeconds,
-		Remaining: a.DurationSeconds,
-		Label:     a.Label,
+		Duration:  seconds,
+		Remaining: seconds,
+		Label:     label,
 		StartTime: now.Format(time.RFC3339),
-		ExpiresAt: now.Add(time.Duration(a.DurationSeconds) * time.Second).Format(time.RFC3339),
+		ExpiresAt: now.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339),
 	}
 
-	if err := t.store.Add(entry); err != nil {
+	if err := store.Add(entry); err != nil {
 		return "", fmt.Errorf("保存倒计时失败: %w", err)
 	}
 
 	// 格式化时长
-	durationStr := formatDuration(a.DurationSeconds)
-	if a.Label != "" {
-		return fmt.Sprintf("已设置%s倒计时，提醒内容：%s", durationStr, a.Label), nil
+	durationStr := formatDuration(seconds)
+	if label != "" {
+		return fmt.Sprintf("已设置%s倒计时，提醒内容：%s", durationStr, label), nil
 	}
 	return fmt.Sprintf("已设置%s倒计时", durationStr), nil
 }
@@ -286,18 +352,50 @@ func NewListTimersTool(store *TimerStore) *ListTimersTool {
 
 func (t *ListTimersTool) Name() string { return "list_timers" }
 func (t *ListTimersTool) Description() string {
-	return "查看当前正在进行的倒计时。当用户说'有哪些倒计时'、'查看定时器'等时使用。"
+	return "查看当前正在进行的倒计时。当用户说'有哪些倒计时'、'查看定时器'、'面条的计时器还有多久'等时使用。问某个倒计时时传 label。"
 }
 func (t *ListTimersTool) Parameters() json.RawMessage {
-	return json.RawMessage(`{"type":"object","properties":{},"required":[]}`)
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"label": {
+				"type": "string",
+				"description": "要查询的倒计时标签（可选，模糊匹配），如'面条'"
+			}
+		},
+		"required": []
+	}`)
+}
+
+type listTimersArgs struct {
+	Label string `json:"label"`
 }
 
 func (t *ListTimersTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a listTimersArgs
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &a); err != nil {
+			return "", fmt.Errorf("参数解析失败: %w", err)
+		}
+	}
+
 	timers := t.store.List()
 	if len(timers) == 0 {
 		return "当前没有正在进行的倒计时。", nil
 	}
 
+	if a.Label != "" {
+		matched := t.store.Match(a.Label)
+		if len(matched) == 0 {
+			return fmt.Sprintf("没有找到'%s'的倒计时，当前的倒计时有：%s", a.Label, timerLabels(timers)), nil
+		}
+		parts := make([]string, len(matched))
+		for i, e := range matched {
+			parts[i] = fmt.Sprintf("%s还剩%s（共%s）", timerName(e), formatDuration(e.Remaining), formatDuration(e.Duration))
+		}
+		return strings.Join(parts, "；"), nil
+	}
+
 	result := fmt.Sprintf("当前有 %d 个倒计时:\n", len(timers))
 	for i, e := range timers {
 		remaining := formatDuration(e.Remaining)
@@ -334,7 +432,7 @@ func (t *CancelTimerTool) Parameters() json.RawMessage {
 			},
 			"label": {
 				"type": "string",
-				"description": "倒计时标签（可选，用于模糊匹配）"
+				"description": "倒计时标签（可选，模糊匹配），如'烤箱'"
 			}
 		},
 		"required": []
@@ -360,14 +458,18 @@ func (t *CancelTimerTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return fmt.Sprintf("未找到倒计时 %s", a.ID), nil
 	}
 
-	// 如果提供了标签，尝试模糊匹配
+	// 如果提供了标签，模糊匹配（"烤箱的计时器"能匹配"烤箱"）
 	if a.Label != "" {
-		timers := t.store.List()
-		for _, e := range timers {
-			if e.Label == a.Label {
-				t.store.Cancel(e.ID)
-				return fmt.Sprintf("已取消倒计时：%s", e.Label), nil
-			}
+		matched := t.store.Match(a.Label)
+		switch {
+		case len(matched) == 1:
+			t.store.Cancel(matched[0].ID)
+			return fmt.Sprintf("已取消%s", timerName(matched[0])), nil
+		case len(matched) > 1:
+			return fmt.Sprintf("有 %d 个倒计时都像'%s'：%s，请问要取消哪一个？", len(matched), a.Label, timerLabels(matched)), nil
+		}
+		if timers := t.store.List(); len(timers) > 0 {
+			return fmt.Sprintf("未找到标签为'%s'的倒计时，当前的倒计时有：%s", a.Label, timerLabels(timers)), nil
 		}
 		return fmt.Sprintf("未找到标签为'%s'的倒计时", a.Label), nil
 	}
@@ -395,3 +497,20 @@ func (t *CancelTimerTool) Execute(ctx context.Context, args json.RawMessage) (st
 
 	return "取消失败", nil
 }
+
+// timerName 倒计时的称呼，没有标签时用时长。
+func timerName(e TimerEntry) string {
+	if label := normalizeTimerLabel(e.Label); label != "" {
+		return label + "的倒计时"
+	}
+	return formatDuration(e.Duration) + "的倒计时"
+}
+
+// timerLabels 列出所有倒计时的称呼。
+func timerLabels(timers []TimerEntry) string {
+	names := make([]string, len(timers))
+	for i, e := range timers {
+		names[i] = timerName(e)
+	}
+	return strings.Join(names, "、")
+}
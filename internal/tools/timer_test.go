@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -135,3 +136,96 @@ func TestFormatDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestTimerStore_Match(t *testing.T) {
+	store, err := NewTimerStore(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("创建 TimerStore 失败: %v", err)
+	}
+	now := time.Now()
+	for i, label := range []string{"煮面条", "烤箱", "烤红薯", ""} {
+		store.Add(&TimerEntry{
+			ID:        "timer_" + label,
+			Duration:  600 + i*60,
+			Remaining: 600 + i*60,
+			Label:     label,
+			StartTime: now.Format(time.RFC3339),
+			ExpiresAt: now.Add(time.Duration(600+i*60) * time.Second).Format(time.RFC3339),
+		})
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"面条的计时器", []string{"煮面条"}},
+		{"烤箱的倒计时", []string{"烤箱"}},
+		{"烤", []string{"烤箱", "烤红薯"}},
+		{"红薯烤", []string{"烤红薯"}},
+		{"米饭", nil},
+	}
+	for _, tt := range tests {
+		matched := store.Match(tt.query)
+		var got []string
+		for _, e := range matched {
+			got = append(got, e.Label)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("Match(%q) = %v, 期望 %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Match(%q) = %v, 期望 %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCancelTimerTool_FuzzyLabel(t *testing.T) {
+	store, err := NewTimerStore(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("创建 TimerStore 失败: %v", err)
+	}
+	now := time.Now()
+	for _, label := range []string{"面条", "烤箱"} {
+		store.Add(&TimerEntry{
+			ID:        "timer_" + label,
+			Duration:  300,
+			Remaining: 300,
+			Label:     label,
+			StartTime: now.Format(time.RFC3339),
+			ExpiresAt: now.Add(300 * time.Second).Format(time.RFC3339),
+		})
+	}
+
+	tool := NewCancelTimerTool(store)
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"label":"烤箱的计时器"}`))
+	if err != nil {
+		t.Fatalf("执行失败: %v", err)
+	}
+	if result != "已取消烤箱的倒计时" {
+		t.Errorf("结果 = %s", result)
+	}
+	if timers := store.List(); len(timers) != 1 || timers[0].Label != "面条" {
+		t.Errorf("应只剩面条的倒计时，实际 %+v", timers)
+	}
+
+	list, err := NewListTimersTool(store).Execute(context.Background(), json.RawMessage(`{"label":"面条"}`))
+	if err != nil {
+		t.Fatalf("执行失败: %v", err)
+	}
+	if !strings.HasPrefix(list, "面条的倒计时还剩") || !strings.HasSuffix(list, "（共5分钟）") {
+		t.Errorf("查询结果 = %s", list)
+	}
+}
+
+func TestTimerAnnouncement(t *testing.T) {
+	if got := TimerAnnouncement(TimerEntry{Label: "面条", Duration: 480}); got != "面条的8分钟倒计时到了" {
+		t.Errorf("got %s", got)
+	}
+	if got := TimerAnnouncement(TimerEntry{Duration: 90}); got != "1分30秒倒计时结束了" {
+		t.Errorf("got %s", got)
+	}
+}
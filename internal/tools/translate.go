@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 	tmt "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tmt/v20180321"
@@ -26,6 +27,8 @@ func NewTranslateTool(secretID, secretKey, region string) (*TranslateTool, error
 	if err != nil {
 		return nil, fmt.Errorf("创建翻译客户端失败: %w", err)
 	}
+	// 与其他外部 API 共享按 host 的限流和退避
+	client.WithHttpTransport(ratelimit.NewTransport(nil))
 
 	logger.Info("[tools] 翻译工具已初始化")
 	return &TranslateTool{client: client}, nil
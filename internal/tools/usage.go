@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+// 使用统计的指标名。工具调用按 "tool:<工具名>" 记录。
+const (
+	UsageWake         = "wake"          // 唤醒次数
+	UsageQuery        = "query"         // 提问次数
+	UsageMusicSeconds = "music_seconds" // 听音乐的时长（秒）
+	UsageASRFallback  = "asr_fallback"  // 云端识别自动切换到离线识别的次数
+	usageToolPrefix   = "tool:"
+)
+
+// UsageToolMetric 工具调用次数的指标名。
+func UsageToolMetric(tool string) string {
+	return usageToolPrefix + tool
+}
+
+// usageToolPhrases 每日小结中常用工具的说法，%d 为次数。未列出的工具不单独播报。
+var usageToolPhrases = map[string]string{
+	"get_weather":       "问了%d次天气",
+	"get_air_quality":   "问了%d次空气质量",
+	"get_news":          "听了%d次新闻",
+	"play_music":        "点了%d次歌",
+	"tell_story":        "听了%d个故事",
+	"set_timer":         "定了%d个倒计时",
+	"set_alarm":         "定了%d个闹钟",
+	"translate":         "翻译了%d次",
+	"calculate":         "算了%d道题",
+	"add_memo":          "记了%d条备忘",
+	"get_stock":         "查了%d次股票",
+	"ha_control_device": "控制了%d次家电",
+}
+
+// UsageStats 每日使用统计（usage_stats 表），按日期和指标累计次数。
+type UsageStats struct {
+	db     *database.DB
+	paused atomic.Bool
+}
+
+// NewUsageStats 创建使用统计，数据库需已完成迁移。
+func NewUsageStats(db *database.DB) *UsageStats {
+	return &UsageStats{db: db}
+}
+
+// SetPaused 暂停或恢复统计（访客模式下不统计）。
+func (s *UsageStats) SetPaused(paused bool) {
+	if s != nil {
+		s.paused.Store(paused)
+	}
+}
+
+// Add 为今天的指标累加 n。统计失败不影响正常功能，只记录日志。
+func (s *UsageStats) Add(metric string, n int) {
+	if s == nil || s.db == nil || n <= 0 || s.paused.Load() {
+		return
+	}
+	date := time.Now().Format("2006-01-02")
+	_, err := s.db.Exec(`INSERT INTO usage_stats (date, metric, count) VALUES (?, ?, ?)
+		ON CONFLICT(date, metric) DO UPDATE SET count = count + excluded.count`, date, metric, n)
+	if err != nil {
+		logger.Debugf("[tools] 记录使用统计 %s 失败: %v", metric, err)
+	}
+}
+
+// Day 返回某天（格式 2006-01-02）所有指标的累计值。
+func (s *UsageStats) Day(date string) (map[string]int, error) {
+	rows, err := s.db.Query("SELECT metric, count FROM usage_stats WHERE date = ?", date)
+	if err != nil {
+		return nil, fmt.Errorf("查询使用统计失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var metric string
+		var n int
+		if err := rows.Scan(&metric, &n); err != nil {
+			return nil, fmt.Errorf("读取使用统计失败: %w", err)
+		}
+		counts[metric] = n
+	}
+	return counts, rows.Err()
+}
+
+// UsageDay 一天的使用概况，用于趋势查询。
+type UsageDay struct {
+	Date         string `json:"date"`
+	Wakes        int    `json:"wakes"`
+	Queries      int    `json:"queries"`
+	ToolCalls    int    `json:"tool_calls"`
+	MusicMinutes int    `json:"music_minutes"`
+}
+
+// Range 返回 [from, to] 日期范围内每天的概况（没有记录的日期不返回），按日期升序。
+func (s *UsageStats) Range(from, to string) ([]UsageDay, error) {
+	rows, err := s.db.Query(`SELECT date, metric, count FROM usage_stats
+		WHERE date >= ? AND date <= ? ORDER BY date`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("查询使用统计失败: %w", err)
+	}
+	defer rows.Close()
+
+	var days []UsageDay
+	for rows.Next() {
+		var date, metric string
+		var n int
+		if err := rows.Scan(&date, &metric, &n); err != nil {
+			return nil, fmt.Errorf("读取使用统计失败: %w", err)
+		}
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, UsageDay{Date: date})
+		}
+		d := &days[len(days)-1]
+		switch {
+		case metric == UsageWake:
+			d.Wakes += n
+		case metric == UsageQuery:
+			d.Queries += n
+		case metric == UsageMusicSeconds:
+			d.MusicMinutes += n / 60
+		case strings.HasPrefix(metric, usageToolPrefix):
+			d.ToolCalls += n
+		}
+	}
+	return days, rows.Err()
+}
+
+// SummarizeUsage 把一天的统计整理成口语小结，如"今天你听了47分钟音乐，问了6次天气"。
+// who 为称呼的时间，如"今天"、"昨天"。没有任何记录时返回空。
+func SummarizeUsage(who string, counts map[string]int) string {
+	var parts []string
+	if minutes := counts[UsageMusicSeconds] / 60; minutes > 0 {
+		parts = append(parts, fmt.Sprintf("听了%d分钟音乐", minutes))
+	}
+
+	type toolCount struct {
+		tool  string
+		count int
+	}
+	var tools []toolCount
+	for metric, n := range counts {
+		tool := strings.TrimPrefix(metric, usageToolPrefix)
+		if tool == metric || usageToolPhrases[tool] == "" || n == 0 {
+			continue
+		}
+		tools = append(tools, toolCount{tool, n})
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].count != tools[j].count {
+			return tools[i].count > tools[j].count
+		}
+		return tools[i].tool < tools[j].tool
+	})
+	for i, tc := range tools {
+		if i >= 3 {
+			break
+		}
+		parts = append(parts, fmt.Sprintf(usageToolPhrases[tc.tool], tc.count))
+	}
+
+	if n := counts[UsageQuery]; n > 0 {
+		parts = append(parts, fmt.Sprintf("一共和我说了%d次话", n))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return who + "你" + strings.Join(parts, "，") + "。"
+}
+
+// DailySummaryTool 查询每日使用小结和近期趋势。
+type DailySummaryTool struct {
+	stats *UsageStats
+}
+
+// NewDailySummaryTool 创建每日小结工具。
+func NewDailySummaryTool(stats *UsageStats) *DailySummaryTool {
+	return &DailySummaryTool{stats: stats}
+}
+
+func (t *DailySummaryTool) Name() string { return "get_daily_summary" }
+
+func (t *DailySummaryTool) Description() string {
+	return "查询使用小结：某天唤醒了几次、问了几次、用了哪些功能、听了多久音乐；也可查询最近几天的趋势。当用户问'今天我都干了什么'、'我今天听了多久歌'、'这周用了多少次'时使用。"
+}
+
+func (t *DailySummaryTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"date": {
+				"type": "string",
+				"description": "日期：今天、昨天或 YYYY-MM-DD，默认今天"
+			},
+			"days": {
+				"type": "integer",
+				"description": "查询最近几天的趋势（含今天），如 7 表示最近一周；不填则只查 date 这一天"
+			}
+		}
+	}`)
+}
+
+func (t *DailySummaryTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Date string `json:"date"`
+		Days int    `json:"days"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &a); err != nil {
+			return "", fmt.Errorf("解析参数失败: %w", err)
+		}
+	}
+
+	now := time.Now()
+	if a.Days > 1 {
+		if a.Days > 90 {
+			a.Days = 90
+		}
+		from := now.AddDate(0, 0, -(a.Days - 1)).Format("2006-01-02")
+		days, err := t.stats.Range(from, now.Format("2006-01-02"))
+		if err != nil {
+			return "", err
+		}
+		var total UsageDay
+		for _, d := range days {
+			total.Wakes += d.Wakes
+			total.Queries += d.Queries
+			total.ToolCalls += d.ToolCalls
+			total.MusicMinutes += d.MusicMinutes
+		}
+		return toJSON(map[string]interface{}{
+			"from":          from,
+			"days":          days,
+			"total_wakes":   total.Wakes,
+			"total_queries": total.Queries,
+			"total_tools":   total.ToolCalls,
+			"music_minutes": total.MusicMinutes,
+		}), nil
+	}
+
+	date, who := now.Format("2006-01-02"), "今天"
+	switch strings.TrimSpace(a.Date) {
+	case "", "今天":
+	case "昨天":
+		date, who = now.AddDate(0, 0, -1).Format("2006-01-02"), "昨天"
+	default:
+		d, err := time.ParseInLocation("2006-01-02", a.Date, time.Local)
+		if err != nil {
+			return toJSON(map[string]interface{}{"success": false, "message": "日期格式应为 YYYY-MM-DD"}), nil
+		}
+		date, who = d.Format("2006-01-02"), d.Format("1月2日")
+	}
+
+	counts, err := t.stats.Day(date)
+	if err != nil {
+		return "", err
+	}
+	summary := SummarizeUsage(who, counts)
+	if summary == "" {
+		summary = who + "还没有使用记录"
+	}
+	toolCounts := make(map[string]int)
+	for metric, n := range counts {
+		if tool := strings.TrimPrefix(metric, usageToolPrefix); tool != metric {
+			toolCounts[tool] = n
+		}
+	}
+	return toJSON(map[string]interface{}{
+		"date":          date,
+		"wakes":         counts[UsageWake],
+		"queries":       counts[UsageQuery],
+		"music_minutes": counts[UsageMusicSeconds] / 60,
+		"tools":         toolCounts,
+		"summary":       summary,
+	}), nil
+}
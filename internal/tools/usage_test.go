@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/database"
+)
+
+func newTestUsageStats(t *testing.T) *UsageStats {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	return NewUsageStats(db)
+}
+
+func TestUsageStats_AddAndDay(t *testing.T) {
+	s := newTestUsageStats(t)
+	s.Add(UsageWake, 1)
+	s.Add(UsageWake, 1)
+	s.Add(UsageToolMetric("get_weather"), 1)
+	s.Add(UsageMusicSeconds, 90)
+	s.Add(UsageMusicSeconds, 0) // 忽略
+
+	today := time.Now().Format("2006-01-02")
+	counts, err := s.Day(today)
+	if err != nil {
+		t.Fatalf("Day failed: %v", err)
+	}
+	if counts[UsageWake] != 2 || counts["tool:get_weather"] != 1 || counts[UsageMusicSeconds] != 90 {
+		t.Errorf("counts = %v", counts)
+	}
+
+	days, err := s.Range(today, today)
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	if len(days) != 1 || days[0].Wakes != 2 || days[0].ToolCalls != 1 || days[0].MusicMinutes != 1 {
+		t.Errorf("days = %+v", days)
+	}
+
+	var nilStats *UsageStats
+	nilStats.Add(UsageWake, 1) // 未启用时不应 panic
+}
+
+func TestSummarizeUsage(t *testing.T) {
+	counts := map[string]int{
+		UsageMusicSeconds:               47*60 + 30,
+		UsageQuery:                      12,
+		UsageToolMetric("get_weather"):  6,
+		UsageToolMetric("set_timer"):    2,
+		UsageToolMetric("get_datetime"): 3, // 没有说法的工具不单独播报
+	}
+	want := "今天你听了47分钟音乐，问了6次天气，定了2个倒计时，一共和我说了12次话。"
+	if got := SummarizeUsage("今天", counts); got != want {
+		t.Errorf("SummarizeUsage = %q, want %q", got, want)
+	}
+	if got := SummarizeUsage("今天", map[string]int{}); got != "" {
+		t.Errorf("没有记录时应返回空, got %q", got)
+	}
+}
+
+func TestDailySummaryTool(t *testing.T) {
+	s := newTestUsageStats(t)
+	s.Add(UsageQuery, 3)
+	s.Add(UsageToolMetric("get_weather"), 2)
+	tool := NewDailySummaryTool(s)
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var r struct {
+		Queries int            `json:"queries"`
+		Tools   map[string]int `json:"tools"`
+		Summary string         `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if r.Queries != 3 || r.Tools["get_weather"] != 2 || r.Summary != "今天你问了2次天气，一共和我说了3次话。" {
+		t.Errorf("unexpected result: %s", out)
+	}
+
+	out, err = tool.Execute(context.Background(), json.RawMessage(`{"days":7}`))
+	if err != nil {
+		t.Fatalf("Execute(days) failed: %v", err)
+	}
+	var trend struct {
+		Days         []UsageDay `json:"days"`
+		TotalQueries int        `json:"total_queries"`
+	}
+	if err := json.Unmarshal([]byte(out), &trend); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if len(trend.Days) != 1 || trend.TotalQueries != 3 {
+		t.Errorf("unexpected trend: %s", out)
+	}
+}
@@ -76,6 +76,11 @@ func (t *RegisterVoiceprintTool) Parameters() json.RawMessage {
 	}`)
 }
 
+// Timeout 录制 5 个样本约需 20 秒，超过注册表的默认超时。
+func (t *RegisterVoiceprintTool) Timeout() time.Duration {
+	return 2 * time.Minute
+}
+
 // Execute 执行注册声纹。
 // 注意：此工具需要用户配合说话，会阻塞一段时间。
 func (t *RegisterVoiceprintTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
@@ -239,7 +244,7 @@ func (t *SetPreferencesTool) Parameters() json.RawMessage {
 			},
 			"preferences": {
 				"type": "string",
-				"description": "用户偏好JSON，如 {\"style\":\"简洁直接\",\"interests\":[\"编程\"],\"nickname\":\"程序员\"}"
+				"description": "用户偏好JSON，如 {\"style\":\"简洁直接\",\"interests\":[\"编程\"],\"nickname\":\"程序员\",\"home_city\":\"杭州\",\"birthday\":\"05-20\"}；language 设为 en 时用英语回复；persona 为该用户的默认人设名称"
 			}
 		},
 		"required": ["name", "preferences"]
@@ -294,16 +299,35 @@ func (t *WhoAmITool) Name() string {
 }
 
 func (t *WhoAmITool) Description() string {
-	return "识别当前说话人是谁。当用户问'我是谁'或'听听我是谁'时调用此工具。"
+	return "识别当前说话人是谁，并返回识别置信度。当用户问'我是谁'或'听听我是谁'时调用此工具。当用户抱怨认错人（如'为什么总把我认成我弟'）时，设置 include_history=true 查看最近的识别记录。"
 }
 
 func (t *WhoAmITool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
 		"type": "object",
-		"properties": {}
+		"properties": {
+			"include_history": {
+				"type": "boolean",
+				"description": "是否返回最近的识别记录（含每次的候选人和相似度），用于排查误识别"
+			},
+			"history_limit": {
+				"type": "integer",
+				"description": "返回的识别记录条数，默认5"
+			}
+		}
 	}`)
 }
 
+// identifyRecordInfo 识别记录的 JSON 表示。
+type identifyRecordInfo struct {
+	Time          string  `json:"time"`
+	Name          string  `json:"name,omitempty"`
+	BestMatch     string  `json:"best_match,omitempty"`
+	Score         float32 `json:"score"`
+	RunnerUp      string  `json:"runner_up,omitempty"`
+	RunnerUpScore float32 `json:"runner_up_score,omitempty"`
+}
+
 func (t *WhoAmITool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
 	// 检查是否有声纹管理器
 	logger.Debugf("[whoami] manager=%v, contextManager=%v", t.manager != nil, t.contextManager != nil)
@@ -311,29 +335,70 @@ func (t *WhoAmITool) Execute(ctx context.Context, args json.RawMessage) (string,
 		return `{"success":false,"message":"声纹识别未启用，请先在配置中启用声纹识别功能"}`, nil
 	}
 
+	var params struct {
+		IncludeHistory bool `json:"include_history"`
+		HistoryLimit   int  `json:"history_limit"`
+	}
+	if len(args) > 0 {
+		json.Unmarshal(args, &params)
+	}
+	if params.HistoryLimit <= 0 {
+		params.HistoryLimit = 5
+	}
+
 	// 获取当前说话人
 	speakerName := t.contextManager.GetCurrentSpeaker()
 	logger.Debugf("[whoami] currentSpeaker=%q", speakerName)
-	if speakerName == "" {
-		return `{"success":true,"identified":false,"message":"抱歉，我没有识别出你是谁。可能的原因：1) 你还没有注册声纹；2) 声纹识别置信度不够。请说'注册声纹'来添加你的声音。"}`, nil
+
+	result := map[string]interface{}{
+		"success":    true,
+		"identified": speakerName != "",
 	}
 
-	// 获取用户信息
-	user, err := t.manager.GetUser(speakerName)
-	if err != nil {
-		return fmt.Sprintf(`{"success":true,"identified":true,"name":"%s","message":"识别到你是 %s"}`, speakerName, speakerName), nil
+	// 最近一次识别的置信度
+	if rec := t.manager.LastIdentification(); rec != nil {
+		result["confidence"] = rec.Score
+		result["threshold"] = rec.Threshold
+		if rec.BestMatch != "" && rec.BestMatch != speakerName {
+			result["best_match"] = rec.BestMatch
+		}
+		if rec.RunnerUp != "" {
+			result["runner_up"] = rec.RunnerUp
+			result["runner_up_score"] = rec.RunnerUpScore
+		}
 	}
 
-	// 返回识别结果
-	result := map[string]interface{}{
-		"success":    true,
-		"identified": true,
-		"name":       speakerName,
-		"is_owner":   t.manager.IsOwner(speakerName),
+	if params.IncludeHistory {
+		records, err := t.manager.RecentIdentifications(params.HistoryLimit)
+		if err != nil {
+			logger.Warnf("[whoami] 查询识别记录失败: %v", err)
+		} else {
+			history := make([]identifyRecordInfo, 0, len(records))
+			for _, r := range records {
+				history = append(history, identifyRecordInfo{
+					Time:          r.CreatedAt,
+					Name:          r.Name,
+					BestMatch:     r.BestMatch,
+					Score:         r.Score,
+					RunnerUp:      r.RunnerUp,
+					RunnerUpScore: r.RunnerUpScore,
+				})
+			}
+			result["history"] = history
+		}
 	}
 
+	if speakerName == "" {
+		result["message"] = "抱歉，我没有识别出你是谁。可能的原因：1) 你还没有注册声纹；2) 声纹识别置信度不够。请说'注册声纹'来添加你的声音。"
+		data, _ := json.Marshal(result)
+		return string(data), nil
+	}
+
+	result["name"] = speakerName
+	result["is_owner"] = t.manager.IsOwner(speakerName)
+
 	// 如果有偏好，也返回
-	if user.Preferences != "" {
+	if user, err := t.manager.GetUser(speakerName); err == nil && user != nil && user.Preferences != "" {
 		result["preferences"] = user.Preferences
 	}
 
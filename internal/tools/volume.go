@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os/exec"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/iabetor/pibuddy/internal/database"
 	"github.com/iabetor/pibuddy/internal/logger"
 )
 
@@ -266,15 +269,79 @@ func NewVolumeController() (VolumeController, error) {
 	}
 }
 
+// volumeHistorySize 最多记住的调整前音量个数。
+const volumeHistorySize = 10
+
+// VolumeStack 包装音量控制器：记住用户每次调整前的音量，支持"调回刚才的音量"、"调到刚才的一半"；
+// 播报时可以临时设置音量，播完后恢复。SetVolume 不记录（提醒加大音量、睡前渐弱等自动调整不算）。
+type VolumeStack struct {
+	VolumeController
+	mu       sync.Mutex
+	previous []int // 用户调整前的音量，最近的在最后
+}
+
+// NewVolumeStack 包装音量控制器。
+func NewVolumeStack(controller VolumeController) *VolumeStack {
+	return &VolumeStack{VolumeController: controller}
+}
+
+// Remember 记下用户调整前的音量，超过 volumeHistorySize 时丢弃最早的。
+func (s *VolumeStack) Remember(volume int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.previous = append(s.previous, volume)
+	if len(s.previous) > volumeHistorySize {
+		s.previous = s.previous[len(s.previous)-volumeHistorySize:]
+	}
+}
+
+// PopPrevious 取出最近一次调整前的音量。
+func (s *VolumeStack) PopPrevious() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.previous) == 0 {
+		return 0, false
+	}
+	volume := s.previous[len(s.previous)-1]
+	s.previous = s.previous[:len(s.previous)-1]
+	return volume, true
+}
+
+// SetTemporary 临时设置音量（如闹钟、通知播报），返回恢复原音量的函数。
+// 音量不变或读取当前音量失败时不调整，恢复函数为空操作。
+func (s *VolumeStack) SetTemporary(volume int) func() {
+	current, err := s.GetVolume()
+	if err != nil || current == volume {
+		return func() {}
+	}
+	if err := s.SetVolume(volume); err != nil {
+		logger.Debugf("[tools] 设置临时音量失败: %v", err)
+		return func() {}
+	}
+	return func() {
+		if err := s.SetVolume(current); err != nil {
+			logger.Warnf("[tools] 恢复音量失败: %v", err)
+		}
+	}
+}
+
+// volumeHistory 记住调整前音量的控制器（VolumeStack）。
+type volumeHistory interface {
+	Remember(volume int)
+	PopPrevious() (int, bool)
+}
+
 // ---- SetVolumeTool ----
 
 type SetVolumeTool struct {
 	controller VolumeController
 	step       int // 相对调节步长
+	settings   *database.Settings
 }
 
 type VolumeConfig struct {
-	Step int // 相对调节步长，默认 10
+	Step     int                // 相对调节步长，默认 10
+	Settings *database.Settings // 保存音量，重启后恢复，可为 nil
 }
 
 func NewSetVolumeTool(controller VolumeController, cfg VolumeConfig) *SetVolumeTool {
@@ -282,12 +349,12 @@ func NewSetVolumeTool(controller VolumeController, cfg VolumeConfig) *SetVolumeT
 	if step <= 0 {
 		step = 10
 	}
-	return &SetVolumeTool{controller: controller, step: step}
+	return &SetVolumeTool{controller: controller, step: step, settings: cfg.Settings}
 }
 
 func (t *SetVolumeTool) Name() string { return "set_volume" }
 func (t *SetVolumeTool) Description() string {
-	return "设置播放音量。当用户说'音量设为X'、'调大音量'、'调小音量'、'静音'时使用。"
+	return "设置播放音量。当用户说'音量设为X'、'调大音量'、'调小音量'、'静音'、'音量调回刚才那样'、'音量调到刚才的一半'时使用。"
 }
 func (t *SetVolumeTool) Parameters() json.RawMessage {
 	return json.RawMessage(`{
@@ -300,6 +367,10 @@ func (t *SetVolumeTool) Parameters() json.RawMessage {
 			"relative": {
 				"type": "boolean",
 				"description": "是否相对调节（true时volume为增量，可为负数）"
+			},
+			"previous_ratio": {
+				"type": "number",
+				"description": "以上一次调整前的音量为基准的倍数，'调回刚才的音量'为1，'调到刚才的一半'为0.5；大于0时忽略volume（填0）"
 			}
 		},
 		"required": ["volume"]
@@ -307,8 +378,9 @@ func (t *SetVolumeTool) Parameters() json.RawMessage {
 }
 
 type setVolumeArgs struct {
-	Volume   int  `json:"volume"`
-	Relative bool `json:"relative"`
+	Volume        int     `json:"volume"`
+	Relative      bool    `json:"relative"`
+	PreviousRatio float64 `json:"previous_ratio"`
 }
 
 func (t *SetVolumeTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
@@ -332,8 +404,19 @@ func (t *SetVolumeTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		return "已取消静音", nil
 	}
 
+	history, _ := t.controller.(volumeHistory)
 	var newVolume int
-	if a.Relative {
+	if a.PreviousRatio > 0 {
+		// 以上一次调整前的音量为基准
+		if history == nil {
+			return "不记得之前的音量", nil
+		}
+		previous, ok := history.PopPrevious()
+		if !ok {
+			return "没有记录到之前的音量", nil
+		}
+		newVolume = int(math.Round(float64(previous) * a.PreviousRatio))
+	} else if a.Relative {
 		// 相对调节
 		current, err := t.controller.GetVolume()
 		if err != nil {
@@ -352,9 +435,19 @@ func (t *SetVolumeTool) Execute(ctx context.Context, args json.RawMessage) (stri
 		newVolume = 100
 	}
 
+	if history != nil {
+		if current, err := t.controller.GetVolume(); err == nil && current != newVolume {
+			history.Remember(current)
+		}
+	}
 	if err := t.controller.SetVolume(newVolume); err != nil {
 		return "", err
 	}
+	if t.settings != nil {
+		if err := t.settings.SetInt(database.SettingVolume, newVolume); err != nil {
+			logger.Warnf("[tools] 保存音量失败: %v", err)
+		}
+	}
 
 	// 检查静音状态
 	muted, _ := t.controller.IsMuted()
@@ -128,3 +128,50 @@ func TestVolumeBounds(t *testing.T) {
 		t.Errorf("期望音量 0，实际 %d", mock.volume)
 	}
 }
+
+func TestVolumeStack_PreviousRatio(t *testing.T) {
+	mock := &mockVolumeController{volume: 40}
+	stack := NewVolumeStack(mock)
+	tool := NewSetVolumeTool(stack, VolumeConfig{Step: 10})
+	set := func(args string) string {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("执行失败: %v", err)
+		}
+		return result
+	}
+
+	if result := set(`{"volume":0,"previous_ratio":1}`); result != "没有记录到之前的音量" {
+		t.Errorf("没有历史时结果 = %s", result)
+	}
+	set(`{"volume":80}`)
+	// 调到刚才（40）的一半
+	set(`{"volume":0,"previous_ratio":0.5}`)
+	if mock.volume != 20 {
+		t.Errorf("期望音量 20，实际 %d", mock.volume)
+	}
+	// 调回刚才的音量（80）
+	set(`{"volume":0,"previous_ratio":1}`)
+	if mock.volume != 80 {
+		t.Errorf("期望音量 80，实际 %d", mock.volume)
+	}
+}
+
+func TestVolumeStack_SetTemporary(t *testing.T) {
+	mock := &mockVolumeController{volume: 30}
+	stack := NewVolumeStack(mock)
+
+	restore := stack.SetTemporary(70)
+	if mock.volume != 70 {
+		t.Errorf("临时音量应为 70，实际 %d", mock.volume)
+	}
+	restore()
+	if mock.volume != 30 {
+		t.Errorf("应恢复为 30，实际 %d", mock.volume)
+	}
+	// 临时音量不记入"刚才的音量"
+	if _, ok := stack.PopPrevious(); ok {
+		t.Error("临时音量不应记录历史")
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"github.com/iabetor/pibuddy/internal/database"
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"net/http"
 	"net/url"
 	"os"
@@ -26,6 +29,29 @@ type WeatherConfig struct {
 	CredentialID   string // 凭据 ID（kid）
 	ProjectID      string // 项目 ID（sub）
 	PrivateKeyPath string // Ed25519 私钥文件路径
+	// 缓存有效期，0 使用默认值，负数禁用缓存
+	NowTTL      time.Duration // 实时天气，默认 10 分钟
+	ForecastTTL time.Duration // 天气预报，默认 1 小时
+	// 城市解析
+	DB          *database.DB      // 缓存城市 LocationID，可为 nil
+	CityAliases map[string]string // 自定义城市别称，如 "老家": "襄阳"
+	// "这里""我家"等说法对应的城市：HomeCity 返回当前说话人的所在城市（可为 nil），为空时用 DefaultCity
+	HomeCity    func() string
+	DefaultCity string
+}
+
+// noHomeCityMsg 用户说"这里"但没有设置所在城市时的提示。
+const noHomeCityMsg = "还不知道你在哪个城市，请告诉我要查哪个城市。"
+
+const (
+	defaultWeatherNowTTL      = 10 * time.Minute
+	defaultWeatherForecastTTL = time.Hour
+)
+
+// weatherCacheEntry 天气缓存项。
+type weatherCacheEntry struct {
+	data      interface{}
+	expiresAt time.Time
 }
 
 // WeatherTool 查询天气信息。
@@ -44,6 +70,14 @@ type WeatherTool struct {
 	mu          sync.Mutex
 	cachedToken string
 	tokenExpiry time.Time
+
+	// 按城市缓存实时天气和预报，节省 API 配额
+	cacheMu     sync.Mutex
+	cache       map[string]weatherCacheEntry
+	nowTTL      time.Duration
+	forecastTTL time.Duration
+
+	cities *cityResolver
 }
 
 func NewWeatherTool(cfg WeatherConfig) *WeatherTool {
@@ -51,13 +85,27 @@ func NewWeatherTool(cfg WeatherConfig) *WeatherTool {
 	if host == "" {
 		host = "devapi.qweather.com"
 	}
+	nowTTL := cfg.NowTTL
+	if nowTTL == 0 {
+		nowTTL = defaultWeatherNowTTL
+	}
+	forecastTTL := cfg.ForecastTTL
+	if forecastTTL == 0 {
+		forecastTTL = defaultWeatherForecastTTL
+	}
 	t := &WeatherTool{
 		apiKey:  cfg.APIKey,
 		apiHost: host,
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: ratelimit.NewTransport(nil),
 		},
+		nowTTL:      nowTTL,
+		forecastTTL: forecastTTL,
+		cities:      newCityResolver(cfg.DB, cfg.CityAliases),
 	}
+	t.cities.homeCity = cfg.HomeCity
+	t.cities.defaultCity = strings.TrimSpace(cfg.DefaultCity)
 
 	// 如果提供了 JWT 配置，加载私钥
 	if cfg.CredentialID != "" && cfg.ProjectID != "" && cfg.PrivateKeyPath != "" {
@@ -142,7 +190,7 @@ func (t *WeatherTool) getToken() (string, error) {
 func (t *WeatherTool) Name() string { return "get_weather" }
 
 func (t *WeatherTool) Description() string {
-	return "查询指定城市的实时天气和未来天气预报。当用户询问天气相关问题时使用。支持3天、7天、15天预报，默认3天。"
+	return "查询指定城市的实时天气和未来天气预报。当用户询问天气相关问题时使用。支持3天、7天、15天预报，默认3天。结果会缓存几分钟，用户明确要求刷新时设置 refresh。"
 }
 
 func (t *WeatherTool) Parameters() json.RawMessage {
@@ -151,12 +199,16 @@ func (t *WeatherTool) Parameters() json.RawMessage {
 		"properties": {
 			"city": {
 				"type": "string",
-				"description": "城市名称，例如 北京、上海、武汉"
+				"description": "城市名称，例如 北京、上海、武汉。用户说'这里''我家'或没说城市时传 这里"
 			},
 			"days": {
 				"type": "integer",
 				"description": "预报天数，可选值：3、7、15，默认为3",
 				"enum": [3, 7, 15]
+			},
+			"refresh": {
+				"type": "boolean",
+				"description": "是否跳过缓存重新查询，仅在用户说'刷新一下'、'重新查一下天气'等时设为 true"
 			}
 		},
 		"required": ["city"]
@@ -164,8 +216,9 @@ func (t *WeatherTool) Parameters() json.RawMessage {
 }
 
 type weatherArgs struct {
-	City string `json:"city"`
-	Days int    `json:"days"`
+	City    string `json:"city"`
+	Days    int    `json:"days"`
+	Refresh bool   `json:"refresh"`
 }
 
 // cityInfo 城市信息，包含经纬度。
@@ -205,18 +258,21 @@ type qweatherNowResp struct {
 	} `json:"now"`
 }
 
+// forecastDaily 预报接口返回的每日数据。
+type forecastDaily []struct {
+	FxDate       string `json:"fxDate"`
+	TempMax      string `json:"tempMax"`
+	TempMin      string `json:"tempMin"`
+	TextDay      string `json:"textDay"`
+	TextNight    string `json:"textNight"`
+	WindDirDay   string `json:"windDirDay"`
+	WindScaleDay string `json:"windScaleDay"`
+}
+
 // qweatherForecastResp 天气预报响应。
 type qweatherForecastResp struct {
-	Code  string `json:"code"`
-	Daily []struct {
-		FxDate    string `json:"fxDate"`
-		TempMax   string `json:"tempMax"`
-		TempMin   string `json:"tempMin"`
-		TextDay   string `json:"textDay"`
-		TextNight string `json:"textNight"`
-		WindDirDay   string `json:"windDirDay"`
-		WindScaleDay string `json:"windScaleDay"`
-	} `json:"daily"`
+	Code  string        `json:"code"`
+	Daily forecastDaily `json:"daily"`
 }
 
 // WeatherResult 天气查询结果，返回结构化数据让 LLM 组织语言
@@ -267,6 +323,9 @@ func (t *WeatherTool) Execute(ctx context.Context, args json.RawMessage) (string
 
 	// 1. 查询城市信息
 	city, err := t.lookupCity(ctx, a.City)
+	if errors.Is(err, errNoHomeCity) {
+		return noHomeCityMsg, nil
+	}
 	if err != nil {
 		return "", err
 	}
@@ -285,11 +344,11 @@ func (t *WeatherTool) Execute(ctx context.Context, args json.RawMessage) (string
 	fcCh := make(chan forecastResult, 1)
 
 	go func() {
-		data, err := t.getNowData(ctx, city.ID)
+		data, err := t.getNowData(ctx, city.ID, a.Refresh)
 		nowCh <- nowResult{data, err}
 	}()
 	go func() {
-		data, err := t.getForecastData(ctx, city.ID, days)
+		data, err := t.getForecastData(ctx, city.ID, days, a.Refresh)
 		fcCh <- forecastResult{data, err}
 	}()
 
@@ -318,7 +377,18 @@ func (t *WeatherTool) Execute(ctx context.Context, args json.RawMessage) (string
 	return string(jsonData), nil
 }
 
+// lookupCity 查询城市信息：支持"帝都"等别称和"这里""我家"，结果缓存在数据库中。
+// "这里"无法确定城市时返回 errNoHomeCity。
 func (t *WeatherTool) lookupCity(ctx context.Context, city string) (*cityInfo, error) {
+	city, err := t.cities.Resolve(city)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := t.cities.Get(city); ok {
+		logger.Debugf("[tools] 城市命中缓存: %s → %s (%s)", city, cached.Name, cached.ID)
+		return cached, nil
+	}
+
 	u := fmt.Sprintf("https://%s/geo/v2/city/lookup?location=%s&number=1",
 		t.geoHost(), url.QueryEscape(city))
 
@@ -338,16 +408,56 @@ func (t *WeatherTool) lookupCity(ctx context.Context, city string) (*cityInfo, e
 
 	loc := resp.Location[0]
 	logger.Debugf("[tools] 天气查询城市: %s (%s, %s) 经纬度: %s,%s", loc.Name, loc.Adm2, loc.Adm1, loc.Lat, loc.Lon)
-	return &cityInfo{
+	info := &cityInfo{
 		ID:        loc.ID,
 		Name:      loc.Name,
 		Latitude:  loc.Lat,
 		Longitude: loc.Lon,
-	}, nil
+	}
+	t.cities.Put(city, info)
+	return info, nil
 }
 
-// getNowData 获取实时天气结构化数据
-func (t *WeatherTool) getNowData(ctx context.Context, locationID string) (*NowWeather, error) {
+// cacheGet 读取未过期的缓存。
+func (t *WeatherTool) cacheGet(key string) (interface{}, bool) {
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	entry, ok := t.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// cachePut 写入缓存，ttl <= 0 时不缓存。顺带清理过期项，避免查过的城市越积越多。
+func (t *WeatherTool) cachePut(key string, data interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	now := time.Now()
+	if t.cache == nil {
+		t.cache = make(map[string]weatherCacheEntry)
+	}
+	for k, e := range t.cache {
+		if now.After(e.expiresAt) {
+			delete(t.cache, k)
+		}
+	}
+	t.cache[key] = weatherCacheEntry{data: data, expiresAt: now.Add(ttl)}
+}
+
+// getNowData 获取实时天气结构化数据，refresh 为 true 时跳过缓存。
+func (t *WeatherTool) getNowData(ctx context.Context, locationID string, refresh bool) (*NowWeather, error) {
+	key := "now:" + locationID
+	if !refresh {
+		if data, ok := t.cacheGet(key); ok {
+			logger.Debugf("[tools] 实时天气命中缓存: %s", locationID)
+			return data.(*NowWeather), nil
+		}
+	}
+
 	u := fmt.Sprintf("https://%s/v7/weather/now?location=%s",
 		t.apiHost, locationID)
 
@@ -366,18 +476,29 @@ func (t *WeatherTool) getNowData(ctx context.Context, locationID string) (*NowWe
 	}
 
 	n := resp.Now
-	return &NowWeather{
+	now := &NowWeather{
 		Text:      n.Text,
 		Temp:      n.Temp,
 		FeelsLike: n.FeelsLike,
 		WindDir:   n.WindDir,
 		WindScale: n.WindScale,
 		Humidity:  n.Humidity,
-	}, nil
+	}
+	t.cachePut(key, now, t.nowTTL)
+	return now, nil
 }
 
-// getForecastData 获取天气预报结构化数据
-func (t *WeatherTool) getForecastData(ctx context.Context, locationID string, days int) ([]DayForecast, error) {
+// getForecastData 获取天气预报结构化数据，refresh 为 true 时跳过缓存。
+// 缓存的是接口原始数据，"今天/明天"等相对时间每次重新计算，跨零点也不会说错。
+func (t *WeatherTool) getForecastData(ctx context.Context, locationID string, days int, refresh bool) ([]DayForecast, error) {
+	key := fmt.Sprintf("forecast:%s:%d", locationID, days)
+	if !refresh {
+		if data, ok := t.cacheGet(key); ok {
+			logger.Debugf("[tools] 天气预报命中缓存: %s (%d天)", locationID, days)
+			return buildForecast(data.(forecastDaily)), nil
+		}
+	}
+
 	// 构建预报 API 路径：3d, 7d, 15d
 	daysPath := fmt.Sprintf("%dd", days)
 	u := fmt.Sprintf("https://%s/v7/weather/%s?location=%s",
@@ -397,12 +518,18 @@ func (t *WeatherTool) getForecastData(ctx context.Context, locationID string, da
 		return nil, fmt.Errorf("预报API错误 code=%s", resp.Code)
 	}
 
-	// 获取今天日期用于计算相对时间
-	today := time.Now().Format("2006-01-02")
+	t.cachePut(key, resp.Daily, t.forecastTTL)
+	return buildForecast(resp.Daily), nil
+}
+
+// buildForecast 将预报原始数据转换为日预报，按当前日期计算相对时间。
+func buildForecast(daily forecastDaily) []DayForecast {
+	// 获取今天日期用于计算相对时间（按日期差计算，缓存的数据跨零点后仍然正确）
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
 	weekdays := []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
 
 	var result []DayForecast
-	for i, d := range resp.Daily {
+	for _, d := range daily {
 		// 解析日期获取星期
 		t, err := time.Parse("2006-01-02", d.FxDate)
 		if err != nil {
@@ -411,11 +538,12 @@ func (t *WeatherTool) getForecastData(ctx context.Context, locationID string, da
 
 		// 计算相对时间标签
 		var relative string
-		if d.FxDate == today {
+		switch int(t.Sub(today).Hours() / 24) {
+		case 0:
 			relative = "今天"
-		} else if i == 1 {
+		case 1:
 			relative = "明天"
-		} else if i == 2 {
+		case 2:
 			relative = "后天"
 		}
 
@@ -436,7 +564,7 @@ func (t *WeatherTool) getForecastData(ctx context.Context, locationID string, da
 			WindScale: d.WindScaleDay,
 		})
 	}
-	return result, nil
+	return result
 }
 
 // geoHost 返回 Geo API 的 host。
@@ -497,7 +625,7 @@ func (t *AirQualityTool) Parameters() json.RawMessage {
 		"properties": {
 			"city": {
 				"type": "string",
-				"description": "城市名称，例如 北京、上海、武汉"
+				"description": "城市名称，例如 北京、上海、武汉。用户说'这里''我家'或没说城市时传 这里"
 			}
 		},
 		"required": ["city"]
@@ -551,6 +679,9 @@ func (t *AirQualityTool) Execute(ctx context.Context, args json.RawMessage) (str
 
 	// 1. 查询城市信息（获取经纬度）
 	city, err := t.weather.lookupCity(ctx, a.City)
+	if errors.Is(err, errNoHomeCity) {
+		return noHomeCityMsg, nil
+	}
 	if err != nil {
 		return "", err
 	}
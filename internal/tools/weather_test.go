@@ -13,6 +13,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWeatherTool_Name(t *testing.T) {
@@ -379,3 +380,92 @@ func TestGenerateJWT(t *testing.T) {
 func base64URLDecode(s string) ([]byte, error) {
 	return base64.RawURLEncoding.DecodeString(s)
 }
+
+// newCountingWeatherServer 模拟和风天气 API，并统计实时天气和预报接口的请求次数。
+func newCountingWeatherServer(t *testing.T, nowHits, forecastHits *int) *httptest.Server {
+	t.Helper()
+	today := time.Now().Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/v2/city/lookup", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"code":"200","location":[{"name":"北京","id":"101010100","adm1":"北京","adm2":"北京","country":"中国"}]}`)
+	})
+	mux.HandleFunc("/v7/weather/now", func(w http.ResponseWriter, r *http.Request) {
+		*nowHits++
+		fmt.Fprint(w, `{"code":"200","now":{"temp":"5","text":"晴"}}`)
+	})
+	mux.HandleFunc("/v7/weather/3d", func(w http.ResponseWriter, r *http.Request) {
+		*forecastHits++
+		fmt.Fprintf(w, `{"code":"200","daily":[{"fxDate":"%s","textDay":"晴"},{"fxDate":"%s","textDay":"多云"}]}`, today, tomorrow)
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWeatherTool_Cache(t *testing.T) {
+	var nowHits, forecastHits int
+	server := newCountingWeatherServer(t, &nowHits, &forecastHits)
+
+	tool := NewWeatherTool(WeatherConfig{APIKey: "testkey", APIHost: strings.TrimPrefix(server.URL, "https://")})
+	tool.client = server.Client()
+
+	args := json.RawMessage(`{"city":"北京"}`)
+	for i := 0; i < 3; i++ {
+		result, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatalf("Execute 失败: %v", err)
+		}
+		if !strings.Contains(result, `"relative":"今天"`) || !strings.Contains(result, `"relative":"明天"`) {
+			t.Errorf("缓存结果应包含相对日期: %s", result)
+		}
+	}
+	if nowHits != 1 || forecastHits != 1 {
+		t.Errorf("重复查询应命中缓存: now=%d forecast=%d", nowHits, forecastHits)
+	}
+
+	// refresh 跳过缓存
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"city":"北京","refresh":true}`)); err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if nowHits != 2 || forecastHits != 2 {
+		t.Errorf("refresh 应重新请求: now=%d forecast=%d", nowHits, forecastHits)
+	}
+
+	// 过期后重新请求
+	tool.cacheMu.Lock()
+	for k, e := range tool.cache {
+		e.expiresAt = time.Now().Add(-time.Second)
+		tool.cache[k] = e
+	}
+	tool.cacheMu.Unlock()
+	if _, err := tool.Execute(context.Background(), args); err != nil {
+		t.Fatalf("Execute 失败: %v", err)
+	}
+	if nowHits != 3 || forecastHits != 3 {
+		t.Errorf("缓存过期后应重新请求: now=%d forecast=%d", nowHits, forecastHits)
+	}
+}
+
+func TestWeatherTool_CacheDisabled(t *testing.T) {
+	var nowHits, forecastHits int
+	server := newCountingWeatherServer(t, &nowHits, &forecastHits)
+
+	tool := NewWeatherTool(WeatherConfig{
+		APIKey:      "testkey",
+		APIHost:     strings.TrimPrefix(server.URL, "https://"),
+		NowTTL:      -1,
+		ForecastTTL: -1,
+	})
+	tool.client = server.Client()
+
+	for i := 0; i < 2; i++ {
+		if _, err := tool.Execute(context.Background(), json.RawMessage(`{"city":"北京"}`)); err != nil {
+			t.Fatalf("Execute 失败: %v", err)
+		}
+	}
+	if nowHits != 2 || forecastHits != 2 {
+		t.Errorf("禁用缓存时每次都应请求: now=%d forecast=%d", nowHits, forecastHits)
+	}
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// GuestWiFiConfig 访客 Wi-Fi 信息。
+type GuestWiFiConfig struct {
+	SSID     string
+	Password string
+	Security string // WPA、WEP 或 nopass（开放网络），默认 WPA
+	Hidden   bool
+}
+
+// QRPayload 手机相机扫码即可加入的 Wi-Fi 二维码内容，如 "WIFI:T:WPA;S:Guest;P:12345678;;"。
+func (c GuestWiFiConfig) QRPayload() string {
+	escape := strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+	security := c.Security
+	if security == "" {
+		security = "WPA"
+	}
+	var b strings.Builder
+	b.WriteString("WIFI:T:" + security + ";S:" + escape.Replace(c.SSID) + ";")
+	if security != "nopass" {
+		b.WriteString("P:" + escape.Replace(c.Password) + ";")
+	}
+	if c.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// spellPassword 把密码逐个字符念出来，标明字母大小写，避免"l"和"1"、"O"和"0"听错。
+func spellPassword(password string) string {
+	var parts []string
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			parts = append(parts, "大写"+string(r))
+		case unicode.IsLower(r):
+			parts = append(parts, "小写"+string(unicode.ToUpper(r)))
+		case r == ' ':
+			parts = append(parts, "空格")
+		default:
+			parts = append(parts, string(r))
+		}
+	}
+	return strings.Join(parts, "，")
+}
+
+// GuestWiFiTool 告诉访客 Wi-Fi 名称和密码，配置了管理 API 时还能在网页上扫码加入。
+type GuestWiFiTool struct {
+	cfg     GuestWiFiConfig
+	pageURL string
+}
+
+// NewGuestWiFiTool 创建访客 Wi-Fi 工具，pageURL 为显示二维码的网页地址，为空表示不可用。
+func NewGuestWiFiTool(cfg GuestWiFiConfig, pageURL string) *GuestWiFiTool {
+	return &GuestWiFiTool{cfg: cfg, pageURL: pageURL}
+}
+
+func (t *GuestWiFiTool) Name() string { return "get_guest_wifi" }
+
+func (t *GuestWiFiTool) Description() string {
+	return "获取访客 Wi-Fi 的名称和密码。当用户问'Wi-Fi 密码是多少'、'客人怎么连网'、'无线网密码'时使用。" +
+		"回复时先说名称，再按 password_spelled 逐个字符念出密码；有 qr_page 时告诉用户可以打开该网页扫码连接。"
+}
+
+func (t *GuestWiFiTool) Parameters() json.RawMessage {
+	return json.RawMessage(`{"type":"object","properties":{},"required":[]}`)
+}
+
+func (t *GuestWiFiTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	result := map[string]interface{}{
+		"success": true,
+		"ssid":    t.cfg.SSID,
+	}
+	if t.cfg.Security == "nopass" {
+		result["message"] = "访客网络没有密码，直接连接即可"
+	} else {
+		result["password"] = t.cfg.Password
+		result["password_spelled"] = spellPassword(t.cfg.Password)
+	}
+	if t.pageURL != "" {
+		result["qr_page"] = t.pageURL
+	}
+	return toJSON(result), nil
+}
+
+// LocalReply 启用隐私过滤时 Wi-Fi 密码不发给云端大模型，直接念给用户。
+func (t *GuestWiFiTool) LocalReply(result string) string {
+	reply := "访客 Wi-Fi 的名称是" + t.cfg.SSID + "，"
+	if t.cfg.Security == "nopass" {
+		reply += "没有密码，直接连接就行。"
+	} else {
+		reply += "密码是：" + spellPassword(t.cfg.Password) + "。"
+	}
+	if t.pageURL != "" {
+		reply += "也可以打开管理页面上的访客 Wi-Fi 二维码扫码连接。"
+	}
+	return reply
+}
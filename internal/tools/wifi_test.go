@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestGuestWiFiConfig_QRPayload(t *testing.T) {
+	tests := []struct {
+		cfg  GuestWiFiConfig
+		want string
+	}{
+		{GuestWiFiConfig{SSID: "Guest", Password: "12345678"}, "WIFI:T:WPA;S:Guest;P:12345678;;"},
+		{GuestWiFiConfig{SSID: "My;Net", Password: `a:b\c`, Hidden: true}, `WIFI:T:WPA;S:My\;Net;P:a\:b\\c;H:true;;`},
+		{GuestWiFiConfig{SSID: "Cafe", Password: "ignored", Security: "nopass"}, "WIFI:T:nopass;S:Cafe;;"},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.QRPayload(); got != tt.want {
+			t.Errorf("QRPayload() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGuestWiFiTool_Execute(t *testing.T) {
+	tool := NewGuestWiFiTool(GuestWiFiConfig{SSID: "Guest", Password: "aB1 x"}, "http://192.168.1.2:8090/wifi")
+	out, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var r map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &r); err != nil {
+		t.Fatalf("解析结果失败: %v", err)
+	}
+	if r["ssid"] != "Guest" || r["password"] != "aB1 x" || r["qr_page"] != "http://192.168.1.2:8090/wifi" {
+		t.Errorf("result = %s", out)
+	}
+	if r["password_spelled"] != "小写A，大写B，1，空格，小写X" {
+		t.Errorf("password_spelled = %v", r["password_spelled"])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sync"
 	"github.com/iabetor/pibuddy/internal/logger"
 
 	"github.com/hajimehoshi/go-mp3"
@@ -15,21 +16,64 @@ import (
 // EdgeEngine 使用微软 Edge TTS 实现语音合成，
 // 通过 edge-tts-go 获取 MP3 音频，再用 go-mp3 解码为 PCM。
 type EdgeEngine struct {
-	voice string
+	mu           sync.Mutex
+	voice        string
+	defaultVoice string
+	baseVoice    string            // 配置的发音人
+	lang         string            // 当前回复语言
+	voices       map[string]string // 各回复语言的发音人
 }
 
 // NewEdgeEngine 创建指定语音的 Edge TTS 引擎。
 func NewEdgeEngine(voice string) *EdgeEngine {
-	return &EdgeEngine{voice: voice}
+	return &EdgeEngine{voice: voice, defaultVoice: voice, baseVoice: voice, voices: make(map[string]string)}
+}
+
+// SetLanguageVoice 设置某个回复语言使用的发音人，如 ("en", "en-US-AriaNeural")。
+func (e *EdgeEngine) SetLanguageVoice(lang, voice string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.voices[lang] = voice
+}
+
+// SetLanguage 切换到该语言的发音人，没有配置时使用默认发音人。
+func (e *EdgeEngine) SetLanguage(lang string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lang = lang
+	e.updateVoice()
+}
+
+// SetVoice 更换默认发音人（如切换人设），为空时恢复配置的发音人。
+func (e *EdgeEngine) SetVoice(voice string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if voice == "" {
+		voice = e.baseVoice
+	}
+	e.defaultVoice = voice
+	e.updateVoice()
+}
+
+// updateVoice 按当前回复语言选择发音人，调用方需持有 mu。
+func (e *EdgeEngine) updateVoice() {
+	if voice, ok := e.voices[e.lang]; ok && voice != "" {
+		e.voice = voice
+	} else {
+		e.voice = e.defaultVoice
+	}
 }
 
 // Synthesize 将文本合成为单声道 float32 音频样本。
 // 返回样本数据、采样率和错误。
 func (e *EdgeEngine) Synthesize(ctx context.Context, text string) ([]float32, int, error) {
-	logger.Debugf("[tts] edge-tts: 正在合成 %d 个字符，语音=%s", len([]rune(text)), e.voice)
+	e.mu.Lock()
+	voice := e.voice
+	e.mu.Unlock()
+	logger.Debugf("[tts] edge-tts: 正在合成 %d 个字符，语音=%s", len([]rune(text)), voice)
 
 	// 创建 Communicate 实例并通过 Stream() 获取 MP3 音频块
-	comm, err := edge.NewCommunicate(text, edge.WithVoice(e.voice))
+	comm, err := edge.NewCommunicate(text, edge.WithVoice(voice))
 	if err != nil {
 		return nil, 0, fmt.Errorf("[tts] edge-tts 创建实例失败: %w", err)
 	}
@@ -12,6 +12,24 @@ type Engine interface {
 	Synthesize(ctx context.Context, text string) ([]float32, int, error)
 }
 
+// RateAdjustable 支持运行时调整语速的引擎。
+type RateAdjustable interface {
+	// SetSpeechRate 设置相对语速倍率，1.0 为配置的默认语速。
+	SetSpeechRate(rate float64)
+}
+
+// VoiceSwitchable 支持按回复语言切换发音人的引擎。
+type VoiceSwitchable interface {
+	// SetLanguage 切换到该语言的发音人（如 "en"），为空或没有配置该语言时使用默认发音人。
+	SetLanguage(lang string)
+}
+
+// VoiceSelectable 支持运行时更换默认发音人的引擎，用于切换人设时换一个声音。
+type VoiceSelectable interface {
+	// SetVoice 更换默认发音人，为空时恢复配置的发音人。按回复语言配置的发音人不受影响。
+	SetVoice(voice string)
+}
+
 // PreprocessText 预处理文本，删除不适合朗读的字符。
 // 所有 TTS 引擎调用前应先使用此函数处理文本。
 func PreprocessText(text string) string {
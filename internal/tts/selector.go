@@ -0,0 +1,269 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+const (
+	selectorAlpha      = 0.3             // 成功率、延迟的指数滑动平均系数
+	selectorHalfLife   = 5 * time.Minute // 失败记录的半衰期：一段时间没出错后慢慢恢复信任
+	selectorRateMargin = 0.1             // 成功率高出这么多才切换引擎
+	selectorFasterBy   = 0.7             // 成功率相当时，单字延迟低于当前引擎的 70% 才切换
+)
+
+// NamedEngine 带名称的 TTS 引擎，用于 Selector。
+type NamedEngine struct {
+	Name   string
+	Engine Engine
+}
+
+// EngineStats 单个引擎的健康统计，供管理 API 诊断。
+type EngineStats struct {
+	Name        string    `json:"name"`
+	Successes   int       `json:"successes"`
+	Failures    int       `json:"failures"`
+	SuccessRate float64   `json:"success_rate"` // 指数滑动平均，已按失败半衰期恢复
+	MsPerChar   float64   `json:"ms_per_char"`  // 每个字的合成耗时（毫秒，指数滑动平均）
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+type engineHealth struct {
+	NamedEngine
+	stats     EngineStats
+	rate      float64   // 成功率滑动平均（未恢复）
+	updatedAt time.Time // rate 最近一次更新时间
+}
+
+// Selector 在多个 TTS 引擎之间按最近的成功率和合成延迟自动选择：每次合成优先使用当前最健康的引擎，
+// 失败时依次尝试其他引擎。成功率或速度明显更好时才换引擎，避免声音来回变；
+// 出错的引擎一段时间后逐渐恢复信任，重新成为首选。
+// 可以固定（Pin）某个引擎，只在它失败时才临时换用其他引擎。
+type Selector struct {
+	mu      sync.Mutex
+	engines []*engineHealth // 按配置顺序，健康度相当时靠前的优先
+	current int             // 最近一次的首选引擎，用于记录切换日志
+	pinned  string
+	now     func() time.Time
+}
+
+// NewSelector 创建 TTS 引擎选择器，engines 至少一个，pin 为空表示自动选择。
+func NewSelector(engines []NamedEngine, pin string) *Selector {
+	s := &Selector{now: time.Now}
+	for _, e := range engines {
+		s.engines = append(s.engines, &engineHealth{NamedEngine: e, stats: EngineStats{Name: e.Name}, rate: 1})
+	}
+	if pin != "" {
+		if err := s.Pin(pin); err != nil {
+			logger.Warnf("[tts] %v，改为自动选择", err)
+		}
+	}
+	return s
+}
+
+// Pin 固定使用指定引擎，name 为空时恢复自动选择。
+func (s *Selector) Pin(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name != "" && s.indexLocked(name) < 0 {
+		return fmt.Errorf("未知的 TTS 引擎: %s", name)
+	}
+	s.pinned = name
+	return nil
+}
+
+// Pinned 返回固定使用的引擎，自动选择时为空。
+func (s *Selector) Pinned() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pinned
+}
+
+// Current 返回当前首选的引擎名。
+func (s *Selector) Current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order := s.orderLocked()
+	if len(order) == 0 {
+		return ""
+	}
+	return s.engines[order[0]].Name
+}
+
+// Stats 返回各引擎的健康统计（按配置顺序）。
+func (s *Selector) Stats() []EngineStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	stats := make([]EngineStats, len(s.engines))
+	for i, e := range s.engines {
+		stats[i] = e.stats
+		stats[i].SuccessRate = math.Round(e.effectiveRate(now)*1000) / 1000
+		stats[i].MsPerChar = math.Round(e.stats.MsPerChar*10) / 10
+	}
+	return stats
+}
+
+// Synthesize 按健康度依次尝试各引擎，返回第一个成功的结果。
+func (s *Selector) Synthesize(ctx context.Context, text string) ([]float32, int, error) {
+	s.mu.Lock()
+	order := s.orderLocked()
+	s.mu.Unlock()
+	if len(order) == 0 {
+		return nil, 0, fmt.Errorf("没有可用的 TTS 引擎")
+	}
+
+	var lastErr error
+	for _, i := range order {
+		e := s.engines[i]
+		start := s.now()
+		samples, rate, err := e.Engine.Synthesize(ctx, text)
+		if err == nil && len(samples) == 0 {
+			err = fmt.Errorf("合成返回空音频")
+		}
+		if ctx.Err() != nil {
+			// 被打断不算引擎的问题
+			return nil, 0, ctx.Err()
+		}
+		s.record(i, text, s.now().Sub(start), err)
+		if err == nil {
+			return samples, rate, nil
+		}
+		logger.Warnf("[tts] 引擎 %s 合成失败: %v", e.Name, err)
+		lastErr = err
+	}
+	return nil, 0, lastErr
+}
+
+// SetSpeechRate 调整所有支持的引擎的语速。
+func (s *Selector) SetSpeechRate(rate float64) {
+	for _, e := range s.engines {
+		if ra, ok := e.Engine.(RateAdjustable); ok {
+			ra.SetSpeechRate(rate)
+		}
+	}
+}
+
+// SetLanguage 切换所有支持的引擎的发音人。
+func (s *Selector) SetLanguage(lang string) {
+	for _, e := range s.engines {
+		if vs, ok := e.Engine.(VoiceSwitchable); ok {
+			vs.SetLanguage(lang)
+		}
+	}
+}
+
+// SetVoice 更换所有支持的引擎的默认发音人。
+func (s *Selector) SetVoice(voice string) {
+	for _, e := range s.engines {
+		if vs, ok := e.Engine.(VoiceSelectable); ok {
+			vs.SetVoice(voice)
+		}
+	}
+}
+
+// record 记录一次合成结果，首选引擎变化时记录日志。
+func (s *Selector) record(i int, text string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e := s.engines[i]
+	outcome := 0.0
+	if err == nil {
+		outcome = 1
+		e.stats.Successes++
+		if n := utf8.RuneCountInString(text); n > 0 {
+			ms := float64(d.Milliseconds()) / float64(n)
+			if e.stats.MsPerChar == 0 {
+				e.stats.MsPerChar = ms
+			} else {
+				e.stats.MsPerChar += selectorAlpha * (ms - e.stats.MsPerChar)
+			}
+		}
+	} else {
+		e.stats.Failures++
+		e.stats.LastError = err.Error()
+		e.stats.LastErrorAt = now
+	}
+	e.rate = e.effectiveRate(now) + selectorAlpha*(outcome-e.effectiveRate(now))
+	e.updatedAt = now
+
+	if best := s.orderLocked()[0]; best != s.current {
+		logger.Infof("[tts] 首选引擎切换: %s → %s", s.engines[s.current].Name, s.engines[best].Name)
+		s.current = best
+	}
+}
+
+// orderLocked 返回本次合成尝试引擎的顺序，调用方需持有锁。
+// 首选引擎：固定的引擎；否则在成功率与最好的相差不到 selectorRateMargin 的引擎中取配置顺序靠前的，
+// 其中有合成明显更快的则用更快的。其余引擎按成功率从高到低作为备选。
+func (s *Selector) orderLocked() []int {
+	if len(s.engines) == 0 {
+		return nil
+	}
+	now := s.now()
+	order := make([]int, len(s.engines))
+	bestRate := 0.0
+	for i, e := range s.engines {
+		order[i] = i
+		bestRate = max(bestRate, e.effectiveRate(now))
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return s.engines[order[a]].effectiveRate(now) > s.engines[order[b]].effectiveRate(now)
+	})
+
+	first := s.indexLocked(s.pinned)
+	if first < 0 {
+		for i, e := range s.engines {
+			if e.effectiveRate(now) < bestRate-selectorRateMargin {
+				continue
+			}
+			if first < 0 {
+				first = i
+			} else if cur := s.engines[first].stats.MsPerChar; e.stats.MsPerChar > 0 && cur > 0 && e.stats.MsPerChar < cur*selectorFasterBy {
+				first = i
+			}
+		}
+	}
+	return moveToFront(order, first)
+}
+
+func (s *Selector) indexLocked(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, e := range s.engines {
+		if e.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// effectiveRate 返回按失败半衰期恢复后的成功率：一段时间没有新的结果时逐渐回到 1。
+func (e *engineHealth) effectiveRate(now time.Time) float64 {
+	if e.updatedAt.IsZero() {
+		return e.rate
+	}
+	decay := math.Pow(0.5, float64(now.Sub(e.updatedAt))/float64(selectorHalfLife))
+	return 1 - (1-e.rate)*decay
+}
+
+func moveToFront(order []int, first int) []int {
+	result := []int{first}
+	for _, i := range order {
+		if i != first {
+			result = append(result, i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeEngine 可控制是否失败、合成耗时的测试引擎。
+type fakeEngine struct {
+	fail  bool
+	delay time.Duration
+	calls int
+	clock *time.Time
+}
+
+func (f *fakeEngine) Synthesize(ctx context.Context, text string) ([]float32, int, error) {
+	f.calls++
+	*f.clock = f.clock.Add(f.delay)
+	if f.fail {
+		return nil, 0, errors.New("region unavailable")
+	}
+	return []float32{0.1}, 16000, nil
+}
+
+func newTestSelector(pin string) (*Selector, *fakeEngine, *fakeEngine, *time.Time) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+	tencent := &fakeEngine{delay: 100 * time.Millisecond, clock: &now}
+	edge := &fakeEngine{delay: 100 * time.Millisecond, clock: &now}
+	s := NewSelector([]NamedEngine{{Name: "tencent", Engine: tencent}, {Name: "edge", Engine: edge}}, pin)
+	s.now = func() time.Time { return now }
+	return s, tencent, edge, &now
+}
+
+func TestSelector_PrefersHealthyEngine(t *testing.T) {
+	s, tencent, edge, now := newTestSelector("")
+	ctx := context.Background()
+
+	if _, _, err := s.Synthesize(ctx, "你好"); err != nil || tencent.calls != 1 || edge.calls != 0 {
+		t.Fatalf("healthy primary should be used first (err=%v, calls=%d/%d)", err, tencent.calls, edge.calls)
+	}
+
+	// 主引擎出错：本次回退到 edge，之后直接优先 edge
+	tencent.fail = true
+	if _, _, err := s.Synthesize(ctx, "你好"); err != nil {
+		t.Fatalf("should fall back to edge: %v", err)
+	}
+	if s.Current() != "edge" {
+		t.Fatalf("current = %s, want edge after tencent failed", s.Current())
+	}
+	s.Synthesize(ctx, "你好")
+	if tencent.calls != 2 {
+		t.Errorf("failing engine should not be tried first again, calls = %d", tencent.calls)
+	}
+
+	// 一段时间后恢复信任，重新优先主引擎
+	tencent.fail = false
+	*now = now.Add(30 * time.Minute)
+	if s.Current() != "tencent" {
+		t.Errorf("current = %s, want tencent after recovery", s.Current())
+	}
+}
+
+func TestSelector_PrefersFasterEngine(t *testing.T) {
+	s, tencent, _, _ := newTestSelector("")
+	ctx := context.Background()
+
+	tencent.delay = 2 * time.Second
+	s.Synthesize(ctx, "你好") // tencent 成功但很慢
+	tencent.fail = true
+	s.Synthesize(ctx, "你好") // 回退到 edge，记录 edge 的延迟
+	tencent.fail = false
+	if s.Current() != "edge" {
+		t.Fatalf("current = %s, want edge", s.Current())
+	}
+	// tencent 失败记录恢复后，edge 仍然明显更快
+	s.engines[0].updatedAt = time.Time{}
+	s.engines[0].rate = 1
+	if s.Current() != "edge" {
+		t.Errorf("current = %s, want the much faster edge", s.Current())
+	}
+}
+
+func TestSelector_Pin(t *testing.T) {
+	s, tencent, edge, _ := newTestSelector("edge")
+	ctx := context.Background()
+
+	s.Synthesize(ctx, "你好")
+	if edge.calls != 1 || tencent.calls != 0 {
+		t.Fatalf("pinned engine should be used (calls=%d/%d)", tencent.calls, edge.calls)
+	}
+	edge.fail = true
+	if _, _, err := s.Synthesize(ctx, "你好"); err != nil || tencent.calls != 1 {
+		t.Errorf("pinned engine failure should fall back (err=%v)", err)
+	}
+	if s.Current() != "edge" {
+		t.Errorf("pinned engine should stay preferred, got %s", s.Current())
+	}
+
+	if err := s.Pin("piper"); err == nil {
+		t.Error("pinning an unknown engine should fail")
+	}
+	if err := s.Pin(""); err != nil || s.Pinned() != "" {
+		t.Errorf("unpin failed: %v", err)
+	}
+}
+
+func TestSelector_AllFail(t *testing.T) {
+	s, tencent, edge, _ := newTestSelector("")
+	tencent.fail, edge.fail = true, true
+	if _, _, err := s.Synthesize(context.Background(), "你好"); err == nil {
+		t.Error("should return an error when every engine fails")
+	}
+}
@@ -3,6 +3,7 @@ package tts
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/iabetor/pibuddy/internal/logger"
 	"github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
@@ -10,8 +11,10 @@ import (
 
 // SherpaEngine 使用 sherpa-onnx 实现离线语音合成。
 type SherpaEngine struct {
-	tts   *sherpa_onnx.OfflineTts
-	speed float32
+	tts       *sherpa_onnx.OfflineTts
+	mu        sync.Mutex
+	speed     float32
+	baseSpeed float32 // 配置的语速
 }
 
 // SherpaConfig Sherpa TTS 配置。
@@ -74,7 +77,14 @@ func NewSherpaEngine(cfg SherpaConfig) (*SherpaEngine, error) {
 
 	logger.Infof("[tts] sherpa-onnx TTS 引擎已初始化，模型=%s", cfg.ModelPath)
 
-	return &SherpaEngine{tts: tts, speed: speed}, nil
+	return &SherpaEngine{tts: tts, speed: speed, baseSpeed: speed}, nil
+}
+
+// SetSpeechRate 按配置语速的倍率调整语速。
+func (e *SherpaEngine) SetSpeechRate(rate float64) {
+	e.mu.Lock()
+	e.speed = e.baseSpeed * float32(rate)
+	e.mu.Unlock()
 }
 
 // Synthesize 将文本转换为音频。
@@ -89,7 +99,10 @@ func (e *SherpaEngine) Synthesize(ctx context.Context, text string) ([]float32,
 	}
 
 	// 生成音频（sid=0, speed=1.0）
-	audio := e.tts.Generate(text, 0, e.speed)
+	e.mu.Lock()
+	speed := e.speed
+	e.mu.Unlock()
+	audio := e.tts.Generate(text, 0, speed)
 	if audio == nil || len(audio.Samples) == 0 {
 		return nil, 0, fmt.Errorf("[tts] sherpa-onnx: 未生成音频数据")
 	}
@@ -8,13 +8,16 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/hajimehoshi/go-mp3"
 	tts "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tts/v20190823"
 
 	"github.com/iabetor/pibuddy/internal/logger"
+	"github.com/iabetor/pibuddy/internal/ratelimit"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 )
@@ -32,7 +35,10 @@ func IsInsufficientBalance(err error) bool {
 type TencentEngine struct {
 	client    *tts.Client
 	voiceType int64
+	baseVoice int64 // 配置的音色
+	mu        sync.Mutex
 	speed     float64
+	baseSpeed float64 // 配置的语速
 }
 
 // TencentConfig 腾讯云 TTS 配置。
@@ -72,16 +78,48 @@ func NewTencentEngine(cfg TencentConfig) (*TencentEngine, error) {
 	if err != nil {
 		return nil, fmt.Errorf("[tts] 创建腾讯云 TTS 客户端失败: %w", err)
 	}
+	// 与其他外部 API 共享按 host 的限流和退避
+	client.WithHttpTransport(ratelimit.NewTransport(nil))
 
 	logger.Infof("[tts] 腾讯云 TTS 引擎已初始化 (voice=%d, region=%s, speed=%.1f)", cfg.VoiceType, cfg.Region, cfg.Speed)
 
 	return &TencentEngine{
 		client:    client,
 		voiceType: cfg.VoiceType,
+		baseVoice: cfg.VoiceType,
 		speed:     cfg.Speed,
+		baseSpeed: cfg.Speed,
 	}, nil
 }
 
+// SetSpeechRate 按配置语速的倍率调整语速。
+// 腾讯云语速取值 [-2, 6]，0 为正常语速，每档约 0.2 倍。
+func (e *TencentEngine) SetSpeechRate(rate float64) {
+	speed := e.baseSpeed + (rate-1)/0.2
+	if speed < -2 {
+		speed = -2
+	} else if speed > 6 {
+		speed = 6
+	}
+	e.mu.Lock()
+	e.speed = speed
+	e.mu.Unlock()
+}
+
+// SetVoice 按音色编号（如 "101001"）更换音色，为空或不是数字时恢复配置的音色。
+func (e *TencentEngine) SetVoice(voice string) {
+	voiceType, err := strconv.ParseInt(strings.TrimSpace(voice), 10, 64)
+	if err != nil || voiceType <= 0 {
+		if voice != "" {
+			logger.Warnf("[tts] 腾讯云 TTS 音色应为数字编号: %q，使用配置的音色", voice)
+		}
+		voiceType = e.baseVoice
+	}
+	e.mu.Lock()
+	e.voiceType = voiceType
+	e.mu.Unlock()
+}
+
 // reHanOrLetter 匹配至少包含一个中文字符或字母的文本。
 var reHanOrLetter = regexp.MustCompile(`[\p{Han}a-zA-Z]`)
 
@@ -125,14 +163,17 @@ func (e *TencentEngine) Synthesize(ctx context.Context, text string) ([]float32,
 		return nil, 0, nil
 	}
 
-	logger.Debugf("[tts] 腾讯云 TTS: 正在合成 %d 个字符，音色=%d", len([]rune(cleaned)), e.voiceType)
+	e.mu.Lock()
+	voiceType, speed := e.voiceType, e.speed
+	e.mu.Unlock()
+	logger.Debugf("[tts] 腾讯云 TTS: 正在合成 %d 个字符，音色=%d", len([]rune(cleaned)), voiceType)
 
 	request := tts.NewTextToVoiceRequest()
 	request.Text = common.StringPtr(cleaned)
 	request.SessionId = common.StringPtr(uuid.New().String())
-	request.VoiceType = common.Int64Ptr(e.voiceType)
+	request.VoiceType = common.Int64Ptr(voiceType)
 	request.Codec = common.StringPtr("mp3")
-	request.Speed = common.Float64Ptr(e.speed)
+	request.Speed = common.Float64Ptr(speed)
 	request.Volume = common.Float64Ptr(5.0)
 
 	response, err := e.client.TextToVoice(request)
@@ -0,0 +1,85 @@
+package voiceprint
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iabetor/pibuddy/internal/logger"
+)
+
+const (
+	identifyFlushDelay = time.Minute // 识别记录在内存中最多攒这么久再写入数据库
+	identifyFlushBatch = 20          // 攒够这么多条时立即写入
+)
+
+// identifyLog 缓冲识别记录并批量写入数据库。
+// 每次唤醒都会识别说话人，逐条写库会让 SD 卡上的 SQLite 频繁落盘，也会拖慢唤醒后的响应。
+type identifyLog struct {
+	store *Store
+
+	mu      sync.Mutex
+	pending []IdentifyRecord
+	timer   *time.Timer
+	closed  bool
+}
+
+func newIdentifyLog(store *Store) *identifyLog {
+	return &identifyLog{store: store}
+}
+
+// add 缓冲一条识别记录，识别时间取调用时刻。
+func (l *identifyLog) add(rec IdentifyRecord) {
+	if rec.CreatedAt == "" {
+		rec.CreatedAt = time.Now().Format("2006-01-02 15:04:05")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.pending = append(l.pending, rec)
+	if len(l.pending) >= identifyFlushBatch {
+		go l.flush()
+	} else if l.timer == nil {
+		l.timer = time.AfterFunc(identifyFlushDelay, l.flush)
+	}
+}
+
+// flush 把缓冲的识别记录写入数据库。
+func (l *identifyLog) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushLocked()
+}
+
+// flushLocked 写入缓冲的识别记录，调用方需持有锁。
+func (l *identifyLog) flushLocked() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if len(l.pending) == 0 {
+		return
+	}
+	if err := l.store.AddIdentifyRecords(l.pending); err != nil {
+		logger.Warnf("[voiceprint] 保存识别记录失败: %v", err)
+	}
+	l.pending = nil
+}
+
+// recent 先写入缓冲的记录，再返回最近的识别记录（最新的在前）。
+func (l *identifyLog) recent(limit int) ([]IdentifyRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushLocked()
+	return l.store.RecentIdentifyRecords(limit)
+}
+
+// close 写入剩余记录，之后的记录直接丢弃。需在关闭数据库之前调用。
+func (l *identifyLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushLocked()
+	l.closed = true
+}
@@ -2,7 +2,9 @@ package voiceprint
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/iabetor/pibuddy/internal/config"
 	"github.com/iabetor/pibuddy/internal/logger"
@@ -16,6 +18,11 @@ type Manager struct {
 	spkMgr    *sherpa.SpeakerEmbeddingManager
 	threshold float32
 	mu        sync.RWMutex
+
+	identifyLog *identifyLog // 识别记录批量写入
+
+	recordMu   sync.Mutex
+	lastRecord *IdentifyRecord // 最近一次识别记录
 }
 
 // NewManager 创建声纹识别管理器。
@@ -40,10 +47,11 @@ func NewManager(cfg config.VoiceprintConfig, dataDir string) (*Manager, error) {
 	}
 
 	m := &Manager{
-		extractor: extractor,
-		store:     store,
-		spkMgr:    spkMgr,
-		threshold: cfg.Threshold,
+		extractor:   extractor,
+		store:       store,
+		spkMgr:      spkMgr,
+		threshold:   cfg.Threshold,
+		identifyLog: newIdentifyLog(store),
 	}
 
 	// 从 DB 加载已注册用户到内存索引
@@ -81,6 +89,7 @@ func (m *Manager) loadFromDB() error {
 }
 
 // Identify 识别说话人。返回用户名，未识别时返回空字符串。
+// 每次识别都会估算各候选人的相似度并记入识别日志（批量写入数据库），便于排查误识别。
 func (m *Manager) Identify(samples []float32) (string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -95,22 +104,118 @@ func (m *Manager) Identify(samples []float32) (string, error) {
 	}
 
 	name := m.spkMgr.Search(embedding, m.threshold)
+	rec := m.scoreCandidates(embedding)
+	rec.Name = name
+
 	if name != "" {
-		logger.Infof("[voiceprint] 识别到用户: %s (阈值: %.2f)", name, m.threshold)
+		logger.Infof("[voiceprint] 识别到用户: %s (估算置信度: ~%.2f, 阈值: %.2f)", name, rec.Score, m.threshold)
+	} else if rec.BestMatch != "" {
+		logger.Infof("[voiceprint] 未达阈值，最接近: %s (估算置信度: ~%.2f, 阈值: %.2f)", rec.BestMatch, rec.Score, m.threshold)
 	} else {
-		// 尝试用最低阈值搜索，看看最接近谁（用于调试）
-		bestName := m.spkMgr.Search(embedding, 0.01)
-		if bestName != "" {
-			// 使用 Verify 在不同阈值下检测，粗略估算分数
-			score := m.estimateScore(bestName, embedding)
-			logger.Infof("[voiceprint] 未达阈值，最接近: %s (估算置信度: ~%.2f, 阈值: %.2f)", bestName, score, m.threshold)
-		} else {
-			logger.Infof("[voiceprint] 未识别到任何用户 (阈值: %.2f)", m.threshold)
-		}
+		logger.Infof("[voiceprint] 未识别到任何用户 (阈值: %.2f)", m.threshold)
 	}
+
+	m.identifyLog.add(rec)
+	m.recordMu.Lock()
+	m.lastRecord = &rec
+	m.recordMu.Unlock()
+
 	return name, nil
 }
 
+// scoreCandidates 估算 embedding 与每个已注册用户的相似度，返回前两名。
+func (m *Manager) scoreCandidates(embedding []float32) IdentifyRecord {
+	rec := IdentifyRecord{Threshold: m.threshold}
+	candidates := m.rankCandidates(embedding)
+	if len(candidates) > 0 {
+		rec.BestMatch = candidates[0].Name
+		rec.Score = candidates[0].Score
+	}
+	if len(candidates) > 1 {
+		rec.RunnerUp = candidates[1].Name
+		rec.RunnerUpScore = candidates[1].Score
+	}
+	return rec
+}
+
+// Candidate 识别候选人及其估算相似度。
+type Candidate struct {
+	Name  string
+	Score float32
+}
+
+// rankCandidates 估算 embedding 与每个已注册用户的相似度，按相似度从高到低排列。
+func (m *Manager) rankCandidates(embedding []float32) []Candidate {
+	users, err := m.store.ListUsers()
+	if err != nil {
+		logger.Debugf("[voiceprint] 获取候选用户失败: %v", err)
+		return nil
+	}
+
+	var candidates []Candidate
+	for _, u := range users {
+		if !m.spkMgr.Contains(u.Name) {
+			continue
+		}
+		candidates = append(candidates, Candidate{Name: u.Name, Score: m.estimateScore(u.Name, embedding)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// Score 估算一段语音与所有已注册用户的相似度（从高到低），用于测试识别效果，不写入识别日志。
+func (m *Manager) Score(samples []float32) ([]Candidate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	embedding, err := m.extractor.Extract(samples)
+	if err != nil {
+		return nil, fmt.Errorf("提取声纹失败: %w", err)
+	}
+	return m.rankCandidates(embedding), nil
+}
+
+// Verify 验证一段语音是否是指定用户本人（相似度达到 threshold），返回是否通过和估算的相似度。
+// 用于高风险操作前的复核，阈值通常比识别阈值更严格，不写入识别日志。
+func (m *Manager) Verify(name string, samples []float32, threshold float32) (bool, float32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.spkMgr.Contains(name) {
+		return false, 0, fmt.Errorf("用户 %s 未注册声纹", name)
+	}
+	embedding, err := m.extractor.Extract(samples)
+	if err != nil {
+		return false, 0, fmt.Errorf("提取声纹失败: %w", err)
+	}
+	return m.spkMgr.Verify(name, embedding, threshold), m.estimateScore(name, embedding), nil
+}
+
+// Threshold 返回识别阈值。
+func (m *Manager) Threshold() float32 {
+	return m.threshold
+}
+
+// LastIdentification 返回最近一次识别记录，尚未识别过时返回 nil。
+func (m *Manager) LastIdentification() *IdentifyRecord {
+	m.recordMu.Lock()
+	defer m.recordMu.Unlock()
+	if m.lastRecord == nil {
+		return nil
+	}
+	rec := *m.lastRecord
+	return &rec
+}
+
+// RecentIdentifications 返回最近的识别记录（最新的在前）。
+func (m *Manager) RecentIdentifications(limit int) ([]IdentifyRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.identifyLog.recent(limit)
+}
+
 // estimateScore 通过二分法 Verify 粗略估算匹配分数（sherpa API 不直接暴露分数）。
 func (m *Manager) estimateScore(name string, embedding []float32) float32 {
 	low, high := float32(0.0), float32(1.0)
@@ -245,6 +350,70 @@ func (m *Manager) GetUser(name string) (*User, error) {
 	return m.store.GetUser(name)
 }
 
+// exportVersion 导出文件格式版本。
+const exportVersion = 1
+
+// ExportData 声纹导出文件的内容，用于把用户迁移到新设备。
+type ExportData struct {
+	Version    int            `json:"version"`
+	Dim        int            `json:"dim"` // embedding 维度，导入时须与声纹模型一致
+	ExportedAt string         `json:"exported_at"`
+	Users      []ExportedUser `json:"users"`
+}
+
+// Export 导出所有用户的 embedding 和偏好。
+func (m *Manager) Export() (*ExportData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users, err := m.store.ExportUsers()
+	if err != nil {
+		return nil, fmt.Errorf("导出用户失败: %w", err)
+	}
+	return &ExportData{
+		Version:    exportVersion,
+		Dim:        m.extractor.Dim(),
+		ExportedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Users:      users,
+	}, nil
+}
+
+// Import 导入用户，同名用户会被替换。导出时使用的声纹模型须与当前一致（embedding 维度相同）。
+// 返回导入的用户数。
+func (m *Manager) Import(data *ExportData) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if data.Version != exportVersion {
+		return 0, fmt.Errorf("不支持的导出文件版本: %d", data.Version)
+	}
+	if dim := m.extractor.Dim(); data.Dim != dim {
+		return 0, fmt.Errorf("声纹模型不一致: 导出文件 embedding 维度 %d，当前模型 %d", data.Dim, dim)
+	}
+
+	for i, u := range data.Users {
+		if u.Name == "" || len(u.Embeddings) == 0 {
+			return i, fmt.Errorf("第 %d 个用户数据不完整", i+1)
+		}
+		for _, emb := range u.Embeddings {
+			if len(emb) != data.Dim {
+				return i, fmt.Errorf("用户 %s 的 embedding 维度错误: %d", u.Name, len(emb))
+			}
+		}
+		if err := m.store.ImportUser(u); err != nil {
+			return i, fmt.Errorf("导入用户 %s 失败: %w", u.Name, err)
+		}
+		if m.spkMgr.Contains(u.Name) {
+			m.spkMgr.Remove(u.Name)
+		}
+		if !m.spkMgr.RegisterV(u.Name, u.Embeddings) {
+			return i, fmt.Errorf("注册用户 %s 到内存索引失败", u.Name)
+		}
+		logger.Infof("[voiceprint] 已导入用户 %s (%d 个样本)", u.Name, len(u.Embeddings))
+	}
+	return len(data.Users), nil
+}
+
 // Close 释放所有资源。
 func (m *Manager) Close() {
 	m.mu.Lock()
@@ -254,6 +423,9 @@ func (m *Manager) Close() {
 		sherpa.DeleteSpeakerEmbeddingManager(m.spkMgr)
 		m.spkMgr = nil
 	}
+	if m.identifyLog != nil {
+		m.identifyLog.close()
+	}
 	if m.store != nil {
 		m.store.Close()
 	}
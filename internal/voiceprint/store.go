@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -32,10 +33,25 @@ func (u *User) IsOwner() bool {
 
 // UserPreferences 用户偏好结构。
 type UserPreferences struct {
-	Style      string   `json:"style,omitempty"`      // 回复风格，如"简洁直接"
-	Interests  []string `json:"interests,omitempty"`  // 兴趣爱好
-	Nickname   string   `json:"nickname,omitempty"`   // 昵称
-	Extra      string   `json:"extra,omitempty"`      // 额外描述
+	Style         string   `json:"style,omitempty"`          // 回复风格，如"简洁直接"
+	Interests     []string `json:"interests,omitempty"`      // 兴趣爱好
+	Nickname      string   `json:"nickname,omitempty"`       // 昵称
+	Extra         string   `json:"extra,omitempty"`          // 额外描述
+	HomeCity      string   `json:"home_city,omitempty"`      // 所在城市，查天气时"这里""我家"指代该城市
+	Birthday      string   `json:"birthday,omitempty"`       // 生日，MM-DD 或 YYYY-MM-DD，当天第一次对话时送上祝福
+	NoCelebration bool     `json:"no_celebration,omitempty"` // 不需要生日祝福
+	Language      string   `json:"language,omitempty"`       // 回复语言，"en" 为英语，默认中文
+	Persona       string   `json:"persona,omitempty"`        // 默认人设（llm.personas 中的名称），该用户说话时自动切换
+
+	Endpoint *EndpointPreferences `json:"endpoint,omitempty"` // 断句设置，说话慢、爱停顿或语速快的用户单独调整
+}
+
+// EndpointPreferences 用户的断句设置，覆盖全局的 vad.min_silence_ms 和 asr 端点规则，未设置（为 0）的字段使用全局值。
+type EndpointPreferences struct {
+	MinSilenceMs            int     `json:"min_silence_ms,omitempty"`             // 说完后停顿多久算一句话结束（毫秒），即毫秒写法的 rule2
+	Rule1MinTrailingSilence float64 `json:"rule1_min_trailing_silence,omitempty"` // 还没识别出文字时的静音阈值（秒）
+	Rule2MinTrailingSilence float64 `json:"rule2_min_trailing_silence,omitempty"` // 识别出文字后的静音阈值（秒），优先于 min_silence_ms
+	Rule3MinUtteranceLength float64 `json:"rule3_min_utterance_length,omitempty"` // 一句话最长多久（秒）
 }
 
 // UserEmbedding 表示用户的一条 embedding 记录。
@@ -44,6 +60,29 @@ type UserEmbedding struct {
 	Embedding []float32
 }
 
+// ExportedUser 导出的声纹用户，包含 embedding 和偏好。
+type ExportedUser struct {
+	Name        string      `json:"name"`
+	IsOwner     bool        `json:"is_owner,omitempty"`
+	Preferences string      `json:"preferences,omitempty"` // JSON 格式的用户偏好
+	Embeddings  [][]float32 `json:"embeddings"`
+}
+
+// maxIdentifyLogSize 识别日志最多保留的条数，超出后删除最早的记录。
+const maxIdentifyLogSize = 100
+
+// IdentifyRecord 一次声纹识别的记录，用于排查误识别。
+type IdentifyRecord struct {
+	ID            int64
+	Name          string  // 识别结果，未达阈值时为空
+	BestMatch     string  // 相似度最高的候选人
+	Score         float32 // 最高候选人的估算相似度
+	RunnerUp      string  // 相似度第二的候选人
+	RunnerUpScore float32 // 第二候选人的估算相似度
+	Threshold     float32 // 识别时使用的阈值
+	CreatedAt     string  // 识别时间，格式 2006-01-02 15:04:05
+}
+
 // Store 使用 SQLite 持久化声纹数据。
 type Store struct {
 	db *sql.DB
@@ -97,6 +136,16 @@ func createTables(db *sql.DB) error {
 			embedding BLOB NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
+		CREATE TABLE IF NOT EXISTS identify_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT DEFAULT '',
+			best_match TEXT DEFAULT '',
+			score REAL DEFAULT 0,
+			runner_up TEXT DEFAULT '',
+			runner_up_score REAL DEFAULT 0,
+			threshold REAL DEFAULT 0,
+			created_at TEXT NOT NULL
+		);
 	`)
 	if err != nil {
 		return fmt.Errorf("创建数据表失败: %w", err)
@@ -239,6 +288,67 @@ func (s *Store) SetPreferences(name string, preferences string) error {
 	return nil
 }
 
+// AddIdentifyRecord 追加一条识别记录，并只保留最近 maxIdentifyLogSize 条。
+func (s *Store) AddIdentifyRecord(rec IdentifyRecord) error {
+	return s.AddIdentifyRecords([]IdentifyRecord{rec})
+}
+
+// AddIdentifyRecords 在一个事务中追加多条识别记录，并只保留最近 maxIdentifyLogSize 条。
+func (s *Store) AddIdentifyRecords(recs []IdentifyRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for _, rec := range recs {
+		if rec.CreatedAt == "" {
+			rec.CreatedAt = now
+		}
+		_, err := tx.Exec(`INSERT INTO identify_log (name, best_match, score, runner_up, runner_up_score, threshold, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			rec.Name, rec.BestMatch, rec.Score, rec.RunnerUp, rec.RunnerUpScore, rec.Threshold, rec.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("添加识别记录失败: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM identify_log WHERE id NOT IN (
+		SELECT id FROM identify_log ORDER BY id DESC LIMIT ?
+	)`, maxIdentifyLogSize)
+	if err != nil {
+		return fmt.Errorf("清理识别记录失败: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RecentIdentifyRecords 返回最近的识别记录（最新的在前）。
+func (s *Store) RecentIdentifyRecords(limit int) ([]IdentifyRecord, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	rows, err := s.db.Query(`SELECT id, name, best_match, score, runner_up, runner_up_score, threshold, created_at
+		FROM identify_log ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询识别记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var records []IdentifyRecord
+	for rows.Next() {
+		var r IdentifyRecord
+		if err := rows.Scan(&r.ID, &r.Name, &r.BestMatch, &r.Score, &r.RunnerUp, &r.RunnerUpScore, &r.Threshold, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("读取识别记录失败: %w", err)
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
 // GetAllEmbeddings 获取所有用户的 embedding，用于启动时加载到内存索引。
 func (s *Store) GetAllEmbeddings() ([]UserEmbedding, error) {
 	rows, err := s.db.Query(`
@@ -265,6 +375,69 @@ func (s *Store) GetAllEmbeddings() ([]UserEmbedding, error) {
 	return result, rows.Err()
 }
 
+// ExportUsers 导出所有用户及其 embedding 和偏好，用于迁移到新设备。
+func (s *Store) ExportUsers() ([]ExportedUser, error) {
+	users, err := s.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	embeddings, err := s.GetAllEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[string][][]float32)
+	for _, ue := range embeddings {
+		grouped[ue.UserName] = append(grouped[ue.UserName], ue.Embedding)
+	}
+
+	exported := make([]ExportedUser, 0, len(users))
+	for _, u := range users {
+		exported = append(exported, ExportedUser{
+			Name:        u.Name,
+			IsOwner:     u.IsOwner(),
+			Preferences: u.Preferences,
+			Embeddings:  grouped[u.Name],
+		})
+	}
+	return exported, nil
+}
+
+// ImportUser 导入一个用户：已存在时替换其 embedding 和偏好。
+// 导入的用户是主人时会取消原来的主人。
+func (s *Store) ImportUser(u ExportedUser) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM users WHERE name = ?", u.Name); err != nil {
+		return fmt.Errorf("删除旧用户失败: %w", err)
+	}
+	if u.IsOwner {
+		if _, err := tx.Exec("UPDATE users SET is_owner = 0"); err != nil {
+			return fmt.Errorf("取消旧主人失败: %w", err)
+		}
+	}
+	result, err := tx.Exec("INSERT INTO users (name, is_owner, preferences) VALUES (?, ?, ?)", u.Name, u.IsOwner, u.Preferences)
+	if err != nil {
+		return fmt.Errorf("添加用户失败: %w", err)
+	}
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取用户 ID 失败: %w", err)
+	}
+	for _, emb := range u.Embeddings {
+		if _, err := tx.Exec("INSERT INTO embeddings (user_id, embedding) VALUES (?, ?)", userID, float32ToBytes(emb)); err != nil {
+			return fmt.Errorf("添加 embedding 失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
 // Close 关闭数据库连接。
 func (s *Store) Close() {
 	if s.db != nil {
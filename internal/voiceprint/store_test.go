@@ -119,6 +119,62 @@ func TestAddAndGetEmbeddings(t *testing.T) {
 	}
 }
 
+func TestExportImportUsers(t *testing.T) {
+	src := newTestStore(t)
+	defer src.Close()
+
+	id, _ := src.AddUser("alice")
+	src.AddEmbedding(id, []float32{0.1, 0.2})
+	src.AddEmbedding(id, []float32{0.3, 0.4})
+	src.SetOwner("alice")
+	src.SetPreferences("alice", `{"nickname":"小A"}`)
+	bobID, _ := src.AddUser("bob")
+	src.AddEmbedding(bobID, []float32{0.5, 0.6})
+
+	exported, err := src.ExportUsers()
+	if err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	if len(exported) != 2 || exported[0].Name != "alice" || !exported[0].IsOwner || len(exported[0].Embeddings) != 2 {
+		t.Fatalf("unexpected export: %+v", exported)
+	}
+
+	dst := newTestStore(t)
+	defer dst.Close()
+	// 新设备上已有同名用户和另一个主人
+	oldID, _ := dst.AddUser("alice")
+	dst.AddEmbedding(oldID, []float32{9, 9})
+	dst.AddUser("carol")
+	dst.SetOwner("carol")
+
+	for _, u := range exported {
+		if err := dst.ImportUser(u); err != nil {
+			t.Fatalf("ImportUser(%s) failed: %v", u.Name, err)
+		}
+	}
+
+	alice, _ := dst.GetUser("alice")
+	if alice == nil || !alice.IsOwner() || alice.Preferences != `{"nickname":"小A"}` {
+		t.Errorf("imported alice = %+v", alice)
+	}
+	carol, _ := dst.GetUser("carol")
+	if carol == nil || carol.IsOwner() {
+		t.Errorf("导入主人后原主人应被取消: %+v", carol)
+	}
+
+	all, _ := dst.GetAllEmbeddings()
+	counts := map[string]int{}
+	for _, ue := range all {
+		counts[ue.UserName]++
+		if ue.UserName == "alice" && ue.Embedding[0] == 9 {
+			t.Error("同名用户的旧 embedding 应被替换")
+		}
+	}
+	if counts["alice"] != 2 || counts["bob"] != 1 {
+		t.Errorf("embedding counts = %v", counts)
+	}
+}
+
 func TestDeleteUserCascade(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -221,3 +277,92 @@ func newTestStore(t *testing.T) *Store {
 	}
 	return store
 }
+
+func TestIdentifyRecords(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	records, err := store.RecentIdentifyRecords(5)
+	if err != nil {
+		t.Fatalf("RecentIdentifyRecords failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+
+	store.AddIdentifyRecord(IdentifyRecord{Name: "", BestMatch: "bob", Score: 0.4, RunnerUp: "alice", RunnerUpScore: 0.3, Threshold: 0.5})
+	store.AddIdentifyRecord(IdentifyRecord{Name: "alice", BestMatch: "alice", Score: 0.8, Threshold: 0.5})
+
+	records, err = store.RecentIdentifyRecords(5)
+	if err != nil {
+		t.Fatalf("RecentIdentifyRecords failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	// 最新的在前
+	if records[0].Name != "alice" {
+		t.Errorf("expected newest record first, got %+v", records[0])
+	}
+	if records[1].RunnerUp != "alice" || math.Abs(float64(records[1].Score-0.4)) > 1e-6 {
+		t.Errorf("record mismatch: %+v", records[1])
+	}
+	if records[1].CreatedAt == "" {
+		t.Error("expected created_at to be filled")
+	}
+}
+
+func TestIdentifyRecordsRolling(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	for i := 0; i < maxIdentifyLogSize+10; i++ {
+		if err := store.AddIdentifyRecord(IdentifyRecord{BestMatch: "alice"}); err != nil {
+			t.Fatalf("AddIdentifyRecord failed: %v", err)
+		}
+	}
+
+	records, err := store.RecentIdentifyRecords(maxIdentifyLogSize * 2)
+	if err != nil {
+		t.Fatalf("RecentIdentifyRecords failed: %v", err)
+	}
+	if len(records) != maxIdentifyLogSize {
+		t.Errorf("expected %d records after rolling, got %d", maxIdentifyLogSize, len(records))
+	}
+}
+
+func TestIdentifyLogBatches(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	log := newIdentifyLog(store)
+
+	log.add(IdentifyRecord{Name: "alice", BestMatch: "alice"})
+	log.add(IdentifyRecord{BestMatch: "bob"})
+
+	// 识别时只写入内存缓冲
+	records, err := store.RecentIdentifyRecords(5)
+	if err != nil {
+		t.Fatalf("RecentIdentifyRecords failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected records to be buffered, got %d in db", len(records))
+	}
+
+	// 查询前先写入缓冲
+	records, err = log.recent(5)
+	if err != nil {
+		t.Fatalf("recent failed: %v", err)
+	}
+	if len(records) != 2 || records[0].BestMatch != "bob" || records[1].CreatedAt == "" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+
+	// 关闭时写入剩余记录，之后的记录丢弃
+	log.add(IdentifyRecord{BestMatch: "carol"})
+	log.close()
+	log.add(IdentifyRecord{BestMatch: "dave"})
+	records, _ = store.RecentIdentifyRecords(5)
+	if len(records) != 3 || records[0].BestMatch != "carol" {
+		t.Errorf("expected pending record flushed on close, got %+v", records)
+	}
+}
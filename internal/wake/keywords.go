@@ -0,0 +1,32 @@
+package wake
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// LoadKeywords 读取关键词文件中每个唤醒词的显示文本（"@" 之后的部分），如 "你好小派"。
+// 关键词文件每行格式为 "拼音 token [:增益] [#阈值] @显示文本"，没有显示文本的行会被跳过。
+func LoadKeywords(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keywords []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.LastIndex(line, "@"); i >= 0 {
+			if kw := strings.TrimSpace(line[i+1:]); kw != "" {
+				keywords = append(keywords, kw)
+			}
+		}
+	}
+	return keywords, scanner.Err()
+}
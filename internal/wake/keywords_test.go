@@ -0,0 +1,31 @@
+package wake
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadKeywords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keywords.txt")
+	content := "x iǎo ài t óng x ué @小爱同学\n" +
+		"\n" +
+		"n ǐ h ǎo x iǎo p ài :2.0 #0.3 @你好小派\n" +
+		"x iǎo p ài\n" // 没有显示文本
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadKeywords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"小爱同学", "你好小派"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadKeywords = %v, want %v", got, want)
+	}
+
+	if _, err := LoadKeywords(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("文件不存在时应返回错误")
+	}
+}
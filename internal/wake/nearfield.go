@@ -0,0 +1,214 @@
+package wake
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"sync"
+)
+
+const (
+	nearFieldSampleRate = 16000
+	nearFieldWindow     = 24000 // 保留最近 1.5 秒音频，覆盖整个唤醒词
+	nearFieldFrame      = 512   // 分析帧长（32ms），同时用作 FFT 长度
+	nearFieldTopRatio   = 0.3   // 取能量最高的 30% 帧作为唤醒词语音段
+)
+
+// NearFieldScore 近场判定的各项指标。
+type NearFieldScore struct {
+	LevelDB    float64 // 语音段电平（dBFS）
+	ContrastDB float64 // 语音段与背景的电平差（dB）
+	TiltDB     float64 // 高频（2-6kHz）与低频（200-1000Hz）能量比（dB）
+	Score      float64 // 综合得分 0~1，越高越像近处说话
+}
+
+// NearFieldGate 近场门控：唤醒词检测通过后，根据唤醒词音频的电平、与背景的对比度和频谱倾斜度
+// 估计说话人距离，过滤电视等远处声源引起的误唤醒。
+// 远处声音经过空气吸收和房间混响，电平低、高频衰减明显，且与背景噪声（电视音乐）的对比度小。
+type NearFieldGate struct {
+	mu        sync.Mutex
+	buf       []float32 // 环形缓冲区
+	pos       int
+	filled    bool
+	threshold float64
+	minLevel  float64
+}
+
+// NewNearFieldGate 创建近场门控。
+// threshold: 综合得分阈值（0-1，越高越严格，越容易拒绝远处声音）
+// minLevelDB: 语音段最低电平（dBFS），低于该值得分为 0
+func NewNearFieldGate(threshold, minLevelDB float64) *NearFieldGate {
+	return &NearFieldGate{
+		buf:       make([]float32, nearFieldWindow),
+		threshold: threshold,
+		minLevel:  minLevelDB,
+	}
+}
+
+// Feed 写入音频帧，应在每次唤醒检测前调用。
+func (g *NearFieldGate) Feed(samples []float32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range samples {
+		g.buf[g.pos] = s
+		g.pos++
+		if g.pos == len(g.buf) {
+			g.pos = 0
+			g.filled = true
+		}
+	}
+}
+
+// Reset 清空缓冲区。
+func (g *NearFieldGate) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pos = 0
+	g.filled = false
+}
+
+// Accept 分析最近的音频，判断唤醒词是否来自近处。
+func (g *NearFieldGate) Accept() (NearFieldScore, bool) {
+	score := g.Analyze()
+	return score, score.Score >= g.threshold
+}
+
+// Analyze 计算最近音频的近场指标。
+func (g *NearFieldGate) Analyze() NearFieldScore {
+	samples := g.snapshot()
+	n := len(samples) / nearFieldFrame
+	if n < 4 {
+		// 数据太少无法判断，放行
+		return NearFieldScore{Score: 1}
+	}
+
+	type frameInfo struct {
+		start  int
+		energy float64
+	}
+	frames := make([]frameInfo, n)
+	for i := range frames {
+		start := i * nearFieldFrame
+		var sum float64
+		for _, s := range samples[start : start+nearFieldFrame] {
+			sum += float64(s) * float64(s)
+		}
+		frames[i] = frameInfo{start: start, energy: sum / nearFieldFrame}
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i].energy > frames[j].energy })
+
+	top := int(float64(n)*nearFieldTopRatio + 0.5)
+	if top < 1 {
+		top = 1
+	}
+
+	// 语音段电平与频谱倾斜度
+	var energy, low, high float64
+	for _, f := range frames[:top] {
+		energy += f.energy
+		l, h := bandEnergy(samples[f.start : f.start+nearFieldFrame])
+		low += l
+		high += h
+	}
+	level := toDB(energy / float64(top))
+	tilt := toDB(high) - toDB(low)
+
+	// 背景电平取能量最低的 10% 帧
+	bottom := n / 10
+	if bottom < 1 {
+		bottom = 1
+	}
+	var floor float64
+	for _, f := range frames[n-bottom:] {
+		floor += f.energy
+	}
+	contrast := level - toDB(floor/float64(bottom))
+
+	// 电平高出下限 20dB、对比度 26dB、高低频比 -10dB 时各项得满分
+	levelScore := clamp01((level - g.minLevel) / 20)
+	contrastScore := clamp01((contrast - 6) / 20)
+	tiltScore := clamp01((tilt + 30) / 20)
+
+	score := NearFieldScore{
+		LevelDB:    level,
+		ContrastDB: contrast,
+		TiltDB:     tilt,
+		Score:      0.4*levelScore + 0.3*contrastScore + 0.3*tiltScore,
+	}
+	if level < g.minLevel {
+		score.Score = 0
+	}
+	return score
+}
+
+// snapshot 按时间顺序复制缓冲区内容。
+func (g *NearFieldGate) snapshot() []float32 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.filled {
+		return append([]float32(nil), g.buf[:g.pos]...)
+	}
+	out := make([]float32, 0, len(g.buf))
+	out = append(out, g.buf[g.pos:]...)
+	return append(out, g.buf[:g.pos]...)
+}
+
+// bandEnergy 计算一帧（加 Hann 窗）在低频 200-1000Hz 和高频 2000-6000Hz 的能量。
+func bandEnergy(frame []float32) (low, high float64) {
+	x := make([]complex128, len(frame))
+	for i, s := range frame {
+		w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(len(frame)-1))
+		x[i] = complex(float64(s)*w, 0)
+	}
+	fft(x)
+
+	binHz := float64(nearFieldSampleRate) / float64(len(frame))
+	for k := 1; k < len(x)/2; k++ {
+		freq := float64(k) * binHz
+		p := real(x[k])*real(x[k]) + imag(x[k])*imag(x[k])
+		switch {
+		case freq >= 200 && freq < 1000:
+			low += p
+		case freq >= 2000 && freq < 6000:
+			high += p
+		}
+	}
+	return low, high
+}
+
+// fft 原地计算基 2 快速傅里叶变换，len(x) 必须是 2 的幂。
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+func toDB(energy float64) float64 {
+	if energy < 1e-12 {
+		return -120
+	}
+	return 10 * math.Log10(energy)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
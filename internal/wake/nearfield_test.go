@@ -0,0 +1,104 @@
+package wake
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// noise 生成指定幅度的白噪声。
+func noise(rng *rand.Rand, n int, amp float64) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = float32((rng.Float64()*2 - 1) * amp)
+	}
+	return out
+}
+
+// lowPass 简单一阶低通，模拟远处声音的高频衰减。
+func lowPass(in []float32, alpha float32) []float32 {
+	out := make([]float32, len(in))
+	var y float32
+	for i, x := range in {
+		y += alpha * (x - y)
+		out[i] = y
+	}
+	return out
+}
+
+func TestNearFieldGate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	t.Run("近处说话", func(t *testing.T) {
+		g := NewNearFieldGate(0.5, -40)
+		g.Feed(noise(rng, 16000, 0.002)) // 安静的背景
+		g.Feed(noise(rng, 8000, 0.3))    // 响亮的宽频语音
+		score, ok := g.Accept()
+		if !ok {
+			t.Errorf("近处说话应通过: %+v", score)
+		}
+	})
+
+	t.Run("远处电视", func(t *testing.T) {
+		g := NewNearFieldGate(0.5, -40)
+		// 持续的电视背景声中夹杂一段略响、高频衰减的语音，电平本身不低
+		g.Feed(lowPass(lowPass(noise(rng, 16000, 0.5), 0.05), 0.05))
+		g.Feed(lowPass(lowPass(noise(rng, 8000, 1), 0.05), 0.05))
+		score, ok := g.Accept()
+		if ok {
+			t.Errorf("远处声音应被拒绝: %+v", score)
+		}
+		if score.LevelDB < -40 {
+			t.Errorf("该用例应由对比度和频谱倾斜度拒绝，而不是电平: %+v", score)
+		}
+	})
+
+	t.Run("电平过低", func(t *testing.T) {
+		g := NewNearFieldGate(0.1, -40)
+		g.Feed(noise(rng, 24000, 0.001))
+		if score, ok := g.Accept(); ok || score.Score != 0 {
+			t.Errorf("低于最低电平应得 0 分: %+v", score)
+		}
+	})
+
+	t.Run("数据不足时放行", func(t *testing.T) {
+		g := NewNearFieldGate(0.9, -40)
+		g.Feed(noise(rng, 1000, 0.001))
+		if _, ok := g.Accept(); !ok {
+			t.Error("数据不足时应放行")
+		}
+	})
+}
+
+func TestNearFieldGateRingBuffer(t *testing.T) {
+	g := NewNearFieldGate(0.5, -40)
+	for i := 0; i < 3; i++ {
+		g.Feed(make([]float32, 10000))
+	}
+	if got := len(g.snapshot()); got != nearFieldWindow {
+		t.Errorf("缓冲区长度 = %d, want %d", got, nearFieldWindow)
+	}
+	g.Reset()
+	if got := len(g.snapshot()); got != 0 {
+		t.Errorf("Reset 后长度 = %d, want 0", got)
+	}
+}
+
+func TestBandEnergy(t *testing.T) {
+	frame := make([]float32, nearFieldFrame)
+	for i := range frame {
+		frame[i] = float32(math.Sin(2 * math.Pi * 500 * float64(i) / nearFieldSampleRate))
+	}
+	low, high := bandEnergy(frame)
+	if low <= high*1000 {
+		t.Errorf("500Hz 正弦波的能量应集中在低频: low=%g high=%g", low, high)
+	}
+
+	for i := range frame {
+		frame[i] = float32(math.Sin(2 * math.Pi * 3000 * float64(i) / nearFieldSampleRate))
+	}
+	low, high = bandEnergy(frame)
+	if high <= low*1000 {
+		t.Errorf("3000Hz 正弦波的能量应集中在高频: low=%g high=%g", low, high)
+	}
+}
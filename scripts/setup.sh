@@ -21,7 +21,7 @@ sudo apt-get install -y \
     unzip
 
 # --- Create directories ---
-mkdir -p "${MODELS_DIR}"/{kws,vad,asr,piper,voiceprint}
+mkdir -p "${MODELS_DIR}"/{kws,vad,asr,piper,voiceprint,audio-tagging}
 
 # --- Download sherpa-onnx models ---
 
@@ -102,6 +102,22 @@ else
     echo "Speaker recognition model already exists, skipping."
 fi
 
+# 6. Audio tagging model (sound event detection, optional)
+echo ""
+if [ ! -f "${MODELS_DIR}/audio-tagging/model.int8.onnx" ]; then
+    cd /tmp
+    wget -q --show-progress \
+        "https://github.com/k2-fsa/sherpa-onnx/releases/download/audio-tagging-models/sherpa-onnx-zipformer-small-audio-tagging-2024-04-15.tar.bz2" \
+        -O audio-tagging.tar.bz2
+    tar xjf audio-tagging.tar.bz2
+    cp sherpa-onnx-zipformer-small-audio-tagging-2024-04-15/model.int8.onnx "${MODELS_DIR}/audio-tagging/"
+    cp sherpa-onnx-zipformer-small-audio-tagging-2024-04-15/class_labels_indices.csv "${MODELS_DIR}/audio-tagging/"
+    rm -rf audio-tagging.tar.bz2 sherpa-onnx-zipformer-small-audio-tagging-2024-04-15
+    echo "Audio tagging model downloaded."
+else
+    echo "Audio tagging model already exists, skipping."
+fi
+
 # --- Audio test ---
 echo ""
 echo "--- Audio check ---"